@@ -0,0 +1,130 @@
+package lorawan
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ABPActivation contains the boot parameters of an ABP (activation by
+// personalization) device, as provisioned in the device and the
+// network-server.
+//
+// For LoRaWAN 1.0 devices, the NwkSKey must be set. For LoRaWAN 1.1 devices,
+// the FNwkSIntKey, SNwkSIntKey and NwkSEncKey must be set instead.
+type ABPActivation struct {
+	MACVersion MACVersion `json:"macVersion"`
+	DevAddr    DevAddr    `json:"devAddr"`
+
+	// NwkSKey is used for LoRaWAN 1.0 only.
+	NwkSKey AES128Key `json:"nwkSKey"`
+
+	// FNwkSIntKey, SNwkSIntKey and NwkSEncKey are used for LoRaWAN 1.1 only.
+	FNwkSIntKey AES128Key `json:"fNwkSIntKey"`
+	SNwkSIntKey AES128Key `json:"sNwkSIntKey"`
+	NwkSEncKey  AES128Key `json:"nwkSEncKey"`
+
+	AppSKey AES128Key `json:"appSKey"`
+
+	FCntUp    uint32 `json:"fCntUp"`
+	NFCntDown uint32 `json:"nFCntDown"`
+
+	// AFCntDown is used for LoRaWAN 1.1 only.
+	AFCntDown uint32 `json:"aFCntDown"`
+
+	RX1DROffset  uint8  `json:"rx1DROffset"`
+	RX2DataRate  uint8  `json:"rx2DataRate"`
+	RX2Frequency uint32 `json:"rx2Frequency"` // Hz
+
+	// RXDelay holds the RX1 delay in seconds (0 = 1 second).
+	RXDelay uint8 `json:"rxDelay"`
+}
+
+// Validate validates the ABP activation.
+func (a ABPActivation) Validate() error {
+	var zeroKey AES128Key
+
+	switch a.MACVersion {
+	case LoRaWAN1_0:
+		if a.NwkSKey == zeroKey {
+			return errors.New("lorawan: NwkSKey must be set for LoRaWAN 1.0")
+		}
+		if a.FNwkSIntKey != zeroKey || a.SNwkSIntKey != zeroKey || a.NwkSEncKey != zeroKey {
+			return errors.New("lorawan: FNwkSIntKey, SNwkSIntKey and NwkSEncKey must not be set for LoRaWAN 1.0")
+		}
+		if a.AFCntDown != 0 {
+			return errors.New("lorawan: AFCntDown must not be set for LoRaWAN 1.0")
+		}
+	case LoRaWAN1_1:
+		if a.FNwkSIntKey == zeroKey || a.SNwkSIntKey == zeroKey || a.NwkSEncKey == zeroKey {
+			return errors.New("lorawan: FNwkSIntKey, SNwkSIntKey and NwkSEncKey must be set for LoRaWAN 1.1")
+		}
+		if a.NwkSKey != zeroKey {
+			return errors.New("lorawan: NwkSKey must not be set for LoRaWAN 1.1")
+		}
+	default:
+		return fmt.Errorf("lorawan: unsupported MACVersion: %d", a.MACVersion)
+	}
+
+	if a.AppSKey == zeroKey {
+		return errors.New("lorawan: AppSKey must be set")
+	}
+	if a.RX1DROffset > 7 {
+		return errors.New("lorawan: max value of RX1DROffset is 7")
+	}
+	if a.RX2DataRate > 15 {
+		return errors.New("lorawan: max value of RX2DataRate is 15")
+	}
+	if a.RXDelay > 15 {
+		return errors.New("lorawan: max value of RXDelay is 15")
+	}
+
+	return nil
+}
+
+// SessionKeys returns the session keys. For LoRaWAN 1.0, the FNwkSIntKey,
+// SNwkSIntKey and NwkSEncKey are set to the NwkSKey.
+func (a ABPActivation) SessionKeys() SessionKeys {
+	if a.MACVersion == LoRaWAN1_0 {
+		return SessionKeys{
+			FNwkSIntKey: a.NwkSKey,
+			SNwkSIntKey: a.NwkSKey,
+			NwkSEncKey:  a.NwkSKey,
+			AppSKey:     a.AppSKey,
+		}
+	}
+
+	return SessionKeys{
+		FNwkSIntKey: a.FNwkSIntKey,
+		SNwkSIntKey: a.SNwkSIntKey,
+		NwkSEncKey:  a.NwkSEncKey,
+		AppSKey:     a.AppSKey,
+	}
+}
+
+// Session returns the Session for the ABP activation, e.g. to validate and
+// decrypt the frames of the device. As the security context of an ABP
+// device is not negotiated, no RekeyInd is expected.
+func (a ABPActivation) Session() (Session, error) {
+	if err := a.Validate(); err != nil {
+		return Session{}, err
+	}
+
+	return Session{
+		MACVersion: a.MACVersion,
+		DevAddr:    a.DevAddr,
+		Keys:       a.SessionKeys(),
+		FCntUp:     a.FCntUp,
+		NFCntDown:  a.NFCntDown,
+		AFCntDown:  a.AFCntDown,
+	}, nil
+}
+
+// DLSettings returns the DLSettings containing the RX1DROffset and
+// RX2DataRate of the ABP activation.
+func (a ABPActivation) DLSettings() DLSettings {
+	return DLSettings{
+		OptNeg:      a.MACVersion == LoRaWAN1_1,
+		RX1DROffset: a.RX1DROffset,
+		RX2DataRate: a.RX2DataRate,
+	}
+}
@@ -0,0 +1,206 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestABPActivation(t *testing.T) {
+	abp10 := ABPActivation{
+		MACVersion:   LoRaWAN1_0,
+		DevAddr:      DevAddr{1, 2, 3, 4},
+		NwkSKey:      AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8},
+		AppSKey:      AES128Key{8, 7, 6, 5, 4, 3, 2, 1, 8, 7, 6, 5, 4, 3, 2, 1},
+		FCntUp:       10,
+		NFCntDown:    5,
+		RX1DROffset:  1,
+		RX2DataRate:  3,
+		RX2Frequency: 869525000,
+		RXDelay:      1,
+	}
+
+	abp11 := ABPActivation{
+		MACVersion:  LoRaWAN1_1,
+		DevAddr:     DevAddr{1, 2, 3, 4},
+		FNwkSIntKey: AES128Key{1},
+		SNwkSIntKey: AES128Key{2},
+		NwkSEncKey:  AES128Key{3},
+		AppSKey:     AES128Key{4},
+		FCntUp:      10,
+		NFCntDown:   5,
+		AFCntDown:   6,
+	}
+
+	t.Run("Validate", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			abp   func(ABPActivation) ABPActivation
+			base  ABPActivation
+			error string
+		}{
+			{
+				name: "valid 1.0",
+				base: abp10,
+			},
+			{
+				name: "valid 1.1",
+				base: abp11,
+			},
+			{
+				name: "1.0 without NwkSKey",
+				base: abp10,
+				abp: func(a ABPActivation) ABPActivation {
+					a.NwkSKey = AES128Key{}
+					return a
+				},
+				error: "lorawan: NwkSKey must be set for LoRaWAN 1.0",
+			},
+			{
+				name: "1.0 with 1.1 keys",
+				base: abp10,
+				abp: func(a ABPActivation) ABPActivation {
+					a.FNwkSIntKey = AES128Key{1}
+					return a
+				},
+				error: "lorawan: FNwkSIntKey, SNwkSIntKey and NwkSEncKey must not be set for LoRaWAN 1.0",
+			},
+			{
+				name: "1.0 with AFCntDown",
+				base: abp10,
+				abp: func(a ABPActivation) ABPActivation {
+					a.AFCntDown = 1
+					return a
+				},
+				error: "lorawan: AFCntDown must not be set for LoRaWAN 1.0",
+			},
+			{
+				name: "1.1 without SNwkSIntKey",
+				base: abp11,
+				abp: func(a ABPActivation) ABPActivation {
+					a.SNwkSIntKey = AES128Key{}
+					return a
+				},
+				error: "lorawan: FNwkSIntKey, SNwkSIntKey and NwkSEncKey must be set for LoRaWAN 1.1",
+			},
+			{
+				name: "1.1 with NwkSKey",
+				base: abp11,
+				abp: func(a ABPActivation) ABPActivation {
+					a.NwkSKey = AES128Key{1}
+					return a
+				},
+				error: "lorawan: NwkSKey must not be set for LoRaWAN 1.1",
+			},
+			{
+				name: "invalid MACVersion",
+				base: abp11,
+				abp: func(a ABPActivation) ABPActivation {
+					a.MACVersion = 2
+					return a
+				},
+				error: "lorawan: unsupported MACVersion: 2",
+			},
+			{
+				name: "without AppSKey",
+				base: abp10,
+				abp: func(a ABPActivation) ABPActivation {
+					a.AppSKey = AES128Key{}
+					return a
+				},
+				error: "lorawan: AppSKey must be set",
+			},
+			{
+				name: "invalid RX1DROffset",
+				base: abp10,
+				abp: func(a ABPActivation) ABPActivation {
+					a.RX1DROffset = 8
+					return a
+				},
+				error: "lorawan: max value of RX1DROffset is 7",
+			},
+			{
+				name: "invalid RX2DataRate",
+				base: abp10,
+				abp: func(a ABPActivation) ABPActivation {
+					a.RX2DataRate = 16
+					return a
+				},
+				error: "lorawan: max value of RX2DataRate is 15",
+			},
+			{
+				name: "invalid RXDelay",
+				base: abp10,
+				abp: func(a ABPActivation) ABPActivation {
+					a.RXDelay = 16
+					return a
+				},
+				error: "lorawan: max value of RXDelay is 15",
+			},
+		}
+
+		for _, tst := range tests {
+			t.Run(tst.name, func(t *testing.T) {
+				assert := require.New(t)
+
+				abp := tst.base
+				if tst.abp != nil {
+					abp = tst.abp(abp)
+				}
+
+				err := abp.Validate()
+				if tst.error != "" {
+					assert.EqualError(err, tst.error)
+				} else {
+					assert.NoError(err)
+				}
+			})
+		}
+	})
+
+	t.Run("Session 1.0", func(t *testing.T) {
+		assert := require.New(t)
+
+		s, err := abp10.Session()
+		assert.NoError(err)
+		assert.Equal(Session{
+			MACVersion: LoRaWAN1_0,
+			DevAddr:    abp10.DevAddr,
+			Keys: SessionKeys{
+				FNwkSIntKey: abp10.NwkSKey,
+				SNwkSIntKey: abp10.NwkSKey,
+				NwkSEncKey:  abp10.NwkSKey,
+				AppSKey:     abp10.AppSKey,
+			},
+			FCntUp:    10,
+			NFCntDown: 5,
+		}, s)
+
+		assert.Equal(DLSettings{RX1DROffset: 1, RX2DataRate: 3}, abp10.DLSettings())
+	})
+
+	t.Run("Session 1.1", func(t *testing.T) {
+		assert := require.New(t)
+
+		s, err := abp11.Session()
+		assert.NoError(err)
+		assert.Equal(Session{
+			MACVersion: LoRaWAN1_1,
+			DevAddr:    abp11.DevAddr,
+			Keys: SessionKeys{
+				FNwkSIntKey: abp11.FNwkSIntKey,
+				SNwkSIntKey: abp11.SNwkSIntKey,
+				NwkSEncKey:  abp11.NwkSEncKey,
+				AppSKey:     abp11.AppSKey,
+			},
+			FCntUp:    10,
+			NFCntDown: 5,
+			AFCntDown: 6,
+		}, s)
+
+		assert.Equal(DLSettings{OptNeg: true}, abp11.DLSettings())
+
+		_, err = ABPActivation{}.Session()
+		assert.Error(err)
+	})
+}
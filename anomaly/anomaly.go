@@ -0,0 +1,173 @@
+// Package anomaly provides hooks for frame-level anomaly detection. The
+// Validator wraps the validation helpers of the lorawan package and reports
+// the anomalies (e.g. MIC failures and frame-counter resets) to an Observer,
+// so that security monitoring can be implemented without re-parsing the
+// frames. The Detector implements an in-memory Observer which raises alerts
+// when the number of events exceeds the configured thresholds.
+package anomaly
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/clock"
+)
+
+// EventType defines the anomaly event type.
+type EventType int
+
+// Available event types.
+const (
+	// MICFailure is reported when the MIC of a frame is invalid.
+	MICFailure EventType = iota
+
+	// FCntReset is reported when the frame-counter of an uplink is lower
+	// than the expected frame-counter (e.g. the device has been reset).
+	FCntReset
+
+	// JoinReplay is reported when a join-request contains an already used
+	// DevNonce.
+	JoinReplay
+
+	// DuplicateFrame is reported when the frame-counter of an uplink equals
+	// the frame-counter of the previous uplink.
+	DuplicateFrame
+)
+
+var eventTypeNames = map[EventType]string{
+	MICFailure:     "mic-failure",
+	FCntReset:      "fcnt-reset",
+	JoinReplay:     "join-replay",
+	DuplicateFrame: "duplicate-frame",
+}
+
+// String implements fmt.Stringer.
+func (t EventType) String() string {
+	if s, ok := eventTypeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event contains an anomaly event.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// DevEUI is set for join related events.
+	DevEUI lorawan.EUI64
+
+	// DevAddr and FCnt are set for data frame related events.
+	DevAddr lorawan.DevAddr
+	FCnt    uint32
+
+	// DevNonce is set for JoinReplay events.
+	DevNonce lorawan.DevNonce
+}
+
+// Observer defines the interface for observing anomaly events.
+type Observer interface {
+	Observe(Event)
+}
+
+// ObserverFunc implements Observer as function.
+type ObserverFunc func(Event)
+
+// Observe calls f(e).
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// Validator wraps the validation helpers of the lorawan package and reports
+// the anomalies to the Observer.
+type Validator struct {
+	// Observer receives the anomaly events. When nil, the events are
+	// discarded.
+	Observer Observer
+
+	// Clock is used to set the event time. When nil, clock.Real is used.
+	Clock clock.Clock
+}
+
+// ValidateUplinkDataMIC validates the uplink data MIC (see
+// lorawan.PHYPayload.ValidateUplinkDataMIC) and reports a MICFailure when
+// the MIC is invalid.
+func (v Validator) ValidateUplinkDataMIC(phy lorawan.PHYPayload, macVersion lorawan.MACVersion, confFCnt uint32, txDR, txCh uint8, fNwkSIntKey, sNwkSIntKey lorawan.AES128Key) (bool, error) {
+	ok, err := phy.ValidateUplinkDataMIC(macVersion, confFCnt, txDR, txCh, fNwkSIntKey, sNwkSIntKey)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	e := Event{Type: MICFailure}
+	if macPL, isMACPL := phy.MACPayload.(*lorawan.MACPayload); isMACPL {
+		e.DevAddr = macPL.FHDR.DevAddr
+		e.FCnt = macPL.FHDR.FCnt
+	}
+	v.observe(e)
+
+	return false, nil
+}
+
+// ValidateUplinkJoinMIC validates the join-request MIC (see
+// lorawan.PHYPayload.ValidateUplinkJoinMIC) and reports a MICFailure when
+// the MIC is invalid.
+func (v Validator) ValidateUplinkJoinMIC(phy lorawan.PHYPayload, key lorawan.AES128Key) (bool, error) {
+	ok, err := phy.ValidateUplinkJoinMIC(key)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	e := Event{Type: MICFailure}
+	if jrPL, isJRPL := phy.MACPayload.(*lorawan.JoinRequestPayload); isJRPL {
+		e.DevEUI = jrPL.DevEUI
+	}
+	v.observe(e)
+
+	return false, nil
+}
+
+// ValidateFCnt validates the uplink frame-counter against the expected
+// frame-counter (the frame-counter of the previous uplink + 1). It returns
+// false and reports a DuplicateFrame when the frame-counter equals the
+// previous frame-counter, or a FCntReset when it is lower.
+func (v Validator) ValidateFCnt(devAddr lorawan.DevAddr, expectedFCnt, fCnt uint32) bool {
+	if fCnt >= expectedFCnt {
+		return true
+	}
+
+	e := Event{Type: FCntReset, DevAddr: devAddr, FCnt: fCnt}
+	if fCnt == expectedFCnt-1 {
+		e.Type = DuplicateFrame
+	}
+	v.observe(e)
+
+	return false
+}
+
+// ValidateDevNonce validates that the DevNonce of a join-request has not
+// been used before. It returns false and reports a JoinReplay when the
+// DevNonce is in the list of used DevNonces.
+func (v Validator) ValidateDevNonce(devEUI lorawan.EUI64, devNonce lorawan.DevNonce, usedDevNonces []lorawan.DevNonce) bool {
+	for _, dn := range usedDevNonces {
+		if dn == devNonce {
+			v.observe(Event{Type: JoinReplay, DevEUI: devEUI, DevNonce: devNonce})
+			return false
+		}
+	}
+	return true
+}
+
+func (v Validator) observe(e Event) {
+	if v.Observer == nil {
+		return
+	}
+
+	c := v.Clock
+	if c == nil {
+		c = clock.Real
+	}
+	e.Time = c.Now()
+
+	v.Observer.Observe(e)
+}
@@ -0,0 +1,103 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/clock"
+)
+
+func TestEventTypeString(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("mic-failure", MICFailure.String())
+	assert.Equal("duplicate-frame", DuplicateFrame.String())
+	assert.Equal("EventType(10)", EventType(10).String())
+}
+
+func TestValidator(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	devAddr := lorawan.DevAddr{1, 2, 3, 4}
+	devEUI := lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+	key := lorawan.AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+
+	var events []Event
+	v := Validator{
+		Observer: ObserverFunc(func(e Event) {
+			events = append(events, e)
+		}),
+		Clock: clock.NewVirtual(now),
+	}
+
+	t.Run("ValidateUplinkDataMIC", func(t *testing.T) {
+		assert := require.New(t)
+		events = nil
+
+		fPort := uint8(1)
+		phy := lorawan.PHYPayload{
+			MHDR: lorawan.MHDR{MType: lorawan.UnconfirmedDataUp, Major: lorawan.LoRaWANR1},
+			MACPayload: &lorawan.MACPayload{
+				FHDR:  lorawan.FHDR{DevAddr: devAddr, FCnt: 10},
+				FPort: &fPort,
+			},
+		}
+		assert.NoError(phy.SetUplinkDataMIC(lorawan.LoRaWAN1_0, 0, 0, 0, key, key))
+
+		ok, err := v.ValidateUplinkDataMIC(phy, lorawan.LoRaWAN1_0, 0, 0, 0, key, key)
+		assert.NoError(err)
+		assert.True(ok)
+		assert.Len(events, 0)
+
+		ok, err = v.ValidateUplinkDataMIC(phy, lorawan.LoRaWAN1_0, 0, 0, 0, lorawan.AES128Key{}, lorawan.AES128Key{})
+		assert.NoError(err)
+		assert.False(ok)
+		assert.Equal([]Event{{Type: MICFailure, Time: now, DevAddr: devAddr, FCnt: 10}}, events)
+	})
+
+	t.Run("ValidateUplinkJoinMIC", func(t *testing.T) {
+		assert := require.New(t)
+		events = nil
+
+		phy := lorawan.PHYPayload{
+			MHDR:       lorawan.MHDR{MType: lorawan.JoinRequest, Major: lorawan.LoRaWANR1},
+			MACPayload: &lorawan.JoinRequestPayload{DevEUI: devEUI, DevNonce: 1},
+		}
+		assert.NoError(phy.SetUplinkJoinMIC(key))
+
+		ok, err := v.ValidateUplinkJoinMIC(phy, lorawan.AES128Key{})
+		assert.NoError(err)
+		assert.False(ok)
+		assert.Equal([]Event{{Type: MICFailure, Time: now, DevEUI: devEUI}}, events)
+	})
+
+	t.Run("ValidateFCnt", func(t *testing.T) {
+		assert := require.New(t)
+		events = nil
+
+		assert.True(v.ValidateFCnt(devAddr, 10, 10))
+		assert.True(v.ValidateFCnt(devAddr, 10, 12))
+		assert.False(v.ValidateFCnt(devAddr, 10, 9))
+		assert.False(v.ValidateFCnt(devAddr, 10, 2))
+		assert.Equal([]Event{
+			{Type: DuplicateFrame, Time: now, DevAddr: devAddr, FCnt: 9},
+			{Type: FCntReset, Time: now, DevAddr: devAddr, FCnt: 2},
+		}, events)
+	})
+
+	t.Run("ValidateDevNonce", func(t *testing.T) {
+		assert := require.New(t)
+		events = nil
+
+		assert.True(v.ValidateDevNonce(devEUI, 3, []lorawan.DevNonce{1, 2}))
+		assert.False(v.ValidateDevNonce(devEUI, 2, []lorawan.DevNonce{1, 2}))
+		assert.Equal([]Event{{Type: JoinReplay, Time: now, DevEUI: devEUI, DevNonce: 2}}, events)
+	})
+
+	t.Run("without Observer", func(t *testing.T) {
+		assert := require.New(t)
+		assert.False(Validator{}.ValidateFCnt(devAddr, 10, 2))
+	})
+}
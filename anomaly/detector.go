@@ -0,0 +1,140 @@
+package anomaly
+
+import (
+	"sync"
+	"time"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/clock"
+)
+
+// Alert contains the alert raised by the Detector.
+type Alert struct {
+	Type    EventType
+	DevEUI  lorawan.EUI64
+	DevAddr lorawan.DevAddr
+
+	// Count holds the number of events within the window.
+	Count int
+
+	// First and Last hold the time of the first and last event within the
+	// window.
+	First time.Time
+	Last  time.Time
+}
+
+// DetectorConfig holds the Detector configuration.
+type DetectorConfig struct {
+	// Thresholds holds per event type the number of events (per device)
+	// within the Window which raises an alert. Event types without (or with
+	// a 0) threshold never raise an alert.
+	Thresholds map[EventType]int
+
+	// Window holds the duration in which the events are counted. When 0,
+	// the events are counted until an alert is raised.
+	Window time.Duration
+
+	// OnAlert is called when an alert is raised.
+	OnAlert func(Alert)
+
+	// Clock is used for events without time. When nil, clock.Real is used.
+	Clock clock.Clock
+}
+
+type detectorKey struct {
+	Type    EventType
+	DevEUI  lorawan.EUI64
+	DevAddr lorawan.DevAddr
+}
+
+// Detector implements a simple in-memory anomaly detector. It counts the
+// events per event type and device (DevEUI or DevAddr) and raises an alert
+// when the configured threshold is reached within the window, after which
+// the count is reset. It implements the Observer interface and is safe for
+// concurrent use.
+type Detector struct {
+	config DetectorConfig
+
+	mu     sync.Mutex
+	events map[detectorKey][]time.Time
+}
+
+// NewDetector creates a new Detector.
+func NewDetector(config DetectorConfig) *Detector {
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
+
+	return &Detector{
+		config: config,
+		events: make(map[detectorKey][]time.Time),
+	}
+}
+
+// Observe implements the Observer interface.
+func (d *Detector) Observe(e Event) {
+	threshold := d.config.Thresholds[e.Type]
+	if threshold <= 0 {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = d.config.Clock.Now()
+	}
+
+	key := detectorKey{Type: e.Type, DevEUI: e.DevEUI, DevAddr: e.DevAddr}
+
+	d.mu.Lock()
+	events := append(d.events[key], e.Time)
+	if d.config.Window != 0 {
+		// remove the events outside the window
+		i := 0
+		for i < len(events) && e.Time.Sub(events[i]) >= d.config.Window {
+			i++
+		}
+		events = events[i:]
+	}
+
+	var alert *Alert
+	if len(events) >= threshold {
+		alert = &Alert{
+			Type:    e.Type,
+			DevEUI:  e.DevEUI,
+			DevAddr: e.DevAddr,
+			Count:   len(events),
+			First:   events[0],
+			Last:    events[len(events)-1],
+		}
+		delete(d.events, key)
+	} else {
+		d.events[key] = events
+	}
+	d.mu.Unlock()
+
+	// call the callback without holding the lock
+	if alert != nil && d.config.OnAlert != nil {
+		d.config.OnAlert(*alert)
+	}
+}
+
+// Count returns the current number of events of the given type for the
+// given device (DevEUI or DevAddr).
+func (d *Detector) Count(t EventType, devEUI lorawan.EUI64, devAddr lorawan.DevAddr) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.events[detectorKey{Type: t, DevEUI: devEUI, DevAddr: devAddr}])
+}
+
+// Reset removes all the events of the given device (DevEUI or DevAddr),
+// e.g. after a successful (re)join.
+func (d *Detector) Reset(devEUI lorawan.EUI64, devAddr lorawan.DevAddr) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for k := range d.events {
+		if k.DevEUI == devEUI && k.DevAddr == devAddr {
+			delete(d.events, k)
+		}
+	}
+}
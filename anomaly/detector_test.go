@@ -0,0 +1,85 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/clock"
+)
+
+func TestDetector(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := clock.NewVirtual(now)
+	devAddr := lorawan.DevAddr{1, 2, 3, 4}
+
+	var alerts []Alert
+	d := NewDetector(DetectorConfig{
+		Thresholds: map[EventType]int{
+			MICFailure: 3,
+		},
+		Window: time.Minute,
+		OnAlert: func(a Alert) {
+			alerts = append(alerts, a)
+		},
+		Clock: c,
+	})
+
+	// events without threshold are ignored
+	d.Observe(Event{Type: FCntReset, DevAddr: devAddr})
+	assert.Equal(0, d.Count(FCntReset, lorawan.EUI64{}, devAddr))
+
+	// the first event falls outside the window
+	d.Observe(Event{Type: MICFailure, DevAddr: devAddr})
+	c.Add(time.Minute)
+	d.Observe(Event{Type: MICFailure, DevAddr: devAddr})
+	d.Observe(Event{Type: MICFailure, DevAddr: devAddr})
+	assert.Equal(2, d.Count(MICFailure, lorawan.EUI64{}, devAddr))
+	assert.Len(alerts, 0)
+
+	// other devices are counted separately
+	d.Observe(Event{Type: MICFailure, DevAddr: lorawan.DevAddr{4, 3, 2, 1}})
+	assert.Len(alerts, 0)
+
+	c.Add(time.Second)
+	d.Observe(Event{Type: MICFailure, DevAddr: devAddr})
+	assert.Equal([]Alert{{
+		Type:    MICFailure,
+		DevAddr: devAddr,
+		Count:   3,
+		First:   now.Add(time.Minute),
+		Last:    now.Add(time.Minute + time.Second),
+	}}, alerts)
+	assert.Equal(0, d.Count(MICFailure, lorawan.EUI64{}, devAddr))
+
+	d.Observe(Event{Type: MICFailure, DevAddr: devAddr})
+	d.Reset(lorawan.EUI64{}, devAddr)
+	assert.Equal(0, d.Count(MICFailure, lorawan.EUI64{}, devAddr))
+	assert.Equal(1, d.Count(MICFailure, lorawan.EUI64{}, lorawan.DevAddr{4, 3, 2, 1}))
+}
+
+func TestDetectorWithValidator(t *testing.T) {
+	assert := require.New(t)
+
+	var alerts []Alert
+	d := NewDetector(DetectorConfig{
+		Thresholds: map[EventType]int{JoinReplay: 2},
+		OnAlert: func(a Alert) {
+			alerts = append(alerts, a)
+		},
+	})
+	v := Validator{Observer: d}
+
+	devEUI := lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+	v.ValidateDevNonce(devEUI, 1, []lorawan.DevNonce{1})
+	v.ValidateDevNonce(devEUI, 1, []lorawan.DevNonce{1})
+
+	assert.Len(alerts, 1)
+	assert.Equal(JoinReplay, alerts[0].Type)
+	assert.Equal(devEUI, alerts[0].DevEUI)
+	assert.Equal(2, alerts[0].Count)
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+
+	"github.com/brocaar/lorawan/registry"
 )
 
 // CID defines the command identifier.
@@ -31,28 +33,29 @@ var (
 	ErrNoPayloadForCID = errors.New("lorawan/applayer/clocksync: no payload for given CID")
 )
 
-// map[uplink]...
-var commandPayloadRegistry = map[bool]map[CID]func() CommandPayload{
-	true: map[CID]func() CommandPayload{
-		PackageVersionAns:           func() CommandPayload { return &PackageVersionAnsPayload{} },
-		AppTimeReq:                  func() CommandPayload { return &AppTimeReqPayload{} },
-		DeviceAppTimePeriodicityAns: func() CommandPayload { return &DeviceAppTimePeriodicityAnsPayload{} },
+var commandPayloadRegistry = registry.New(
+	// uplink
+	map[CID]registry.Command[CommandPayload]{
+		PackageVersionAns:           {New: func() CommandPayload { return &PackageVersionAnsPayload{} }},
+		AppTimeReq:                  {New: func() CommandPayload { return &AppTimeReqPayload{} }},
+		DeviceAppTimePeriodicityAns: {New: func() CommandPayload { return &DeviceAppTimePeriodicityAnsPayload{} }},
 	},
-	false: map[CID]func() CommandPayload{
-		AppTimeAns:                  func() CommandPayload { return &AppTimeAnsPayload{} },
-		DeviceAppTimePeriodicityReq: func() CommandPayload { return &DeviceAppTimePeriodicityReqPayload{} },
-		ForceDeviceResyncReq:        func() CommandPayload { return &ForceDeviceResyncReqPayload{} },
+	// downlink
+	map[CID]registry.Command[CommandPayload]{
+		AppTimeAns:                  {New: func() CommandPayload { return &AppTimeAnsPayload{} }},
+		DeviceAppTimePeriodicityReq: {New: func() CommandPayload { return &DeviceAppTimePeriodicityReqPayload{} }},
+		ForceDeviceResyncReq:        {New: func() CommandPayload { return &ForceDeviceResyncReqPayload{} }},
 	},
-}
+)
 
 // GetCommandPayload returns a new CommandPayload for the given CID.
 func GetCommandPayload(uplink bool, c CID) (CommandPayload, error) {
-	v, ok := commandPayloadRegistry[uplink][c]
+	p, _, ok := commandPayloadRegistry.Get(uplink, c)
 	if !ok {
 		return nil, ErrNoPayloadForCID
 	}
 
-	return v(), nil
+	return p, nil
 }
 
 // CommandPayload defines the interface that a command payload must implement.
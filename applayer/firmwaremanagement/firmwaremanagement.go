@@ -7,6 +7,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+
+	"github.com/brocaar/lorawan/registry"
 )
 
 // CID defines the command identifier.
@@ -36,33 +38,34 @@ var (
 	ErrNoPayloadForCID = errors.New("lorawan/applayer/firmwaremanagement: no payload for given CID")
 )
 
-// map[uplink]...
-var commandPayloadRegistry = map[bool]map[CID]func() CommandPayload{
-	true: map[CID]func() CommandPayload{
-		PackageVersionAns:     func() CommandPayload { return &PackageVersionAnsPayload{} },
-		DevVersionAns:         func() CommandPayload { return &DevVersionAnsPayload{} },
-		DevRebootTimeAns:      func() CommandPayload { return &DevRebootTimeAnsPayload{} },
-		DevRebootCountdownAns: func() CommandPayload { return &DevRebootCountdownAnsPayload{} },
-		DevUpgradeImageAns:    func() CommandPayload { return &DevUpgradeImageAnsPayload{} },
-		DevDeleteImageAns:     func() CommandPayload { return &DevDeleteImageAnsPayload{} },
+var commandPayloadRegistry = registry.New(
+	// uplink
+	map[CID]registry.Command[CommandPayload]{
+		PackageVersionAns:     {New: func() CommandPayload { return &PackageVersionAnsPayload{} }},
+		DevVersionAns:         {New: func() CommandPayload { return &DevVersionAnsPayload{} }},
+		DevRebootTimeAns:      {New: func() CommandPayload { return &DevRebootTimeAnsPayload{} }},
+		DevRebootCountdownAns: {New: func() CommandPayload { return &DevRebootCountdownAnsPayload{} }},
+		DevUpgradeImageAns:    {New: func() CommandPayload { return &DevUpgradeImageAnsPayload{} }},
+		DevDeleteImageAns:     {New: func() CommandPayload { return &DevDeleteImageAnsPayload{} }},
 	},
-	false: map[CID]func() CommandPayload{
-		DevVersionReq:         func() CommandPayload { return &DevVersionReqPayload{} },
-		DevRebootTimeReq:      func() CommandPayload { return &DevRebootTimeReqPayload{} },
-		DevRebootCountdownReq: func() CommandPayload { return &DevRebootCountdownReqPayload{} },
-		DevUpgradeImageReq:    func() CommandPayload { return &DevUpgradeImageReqPayload{} },
-		DevDeleteImageReq:     func() CommandPayload { return &DevDeleteImageReqPayload{} },
+	// downlink
+	map[CID]registry.Command[CommandPayload]{
+		DevVersionReq:         {New: func() CommandPayload { return &DevVersionReqPayload{} }},
+		DevRebootTimeReq:      {New: func() CommandPayload { return &DevRebootTimeReqPayload{} }},
+		DevRebootCountdownReq: {New: func() CommandPayload { return &DevRebootCountdownReqPayload{} }},
+		DevUpgradeImageReq:    {New: func() CommandPayload { return &DevUpgradeImageReqPayload{} }},
+		DevDeleteImageReq:     {New: func() CommandPayload { return &DevDeleteImageReqPayload{} }},
 	},
-}
+)
 
 // GetCommandPayload returns a new CommandPayload for the given CID.
 func GetCommandPayload(uplink bool, c CID) (CommandPayload, error) {
-	v, ok := commandPayloadRegistry[uplink][c]
+	p, _, ok := commandPayloadRegistry.Get(uplink, c)
 	if !ok {
 		return nil, ErrNoPayloadForCID
 	}
 
-	return v(), nil
+	return p, nil
 }
 
 // CommandPayload defines the interface that a command payload must implement.
@@ -0,0 +1,184 @@
+package fragmentation
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Decoder implements the decoder (reassembly) for the fragments generated by
+// Encode, including the recovery of lost fragments using the received
+// redundancy fragments. The fragments must be added using their fragment
+// index N (starting at 1), as sent in the DataFragment payload. The first
+// nbFrag fragments are the uncoded fragments, the fragments after that are
+// the redundancy fragments.
+type Decoder struct {
+	nbFrag       int
+	fragmentSize int
+	received     map[uint16]struct{}
+
+	// rows contains for each pivot (column) the reduced coefficients and
+	// data of the fragment with that pivot, or nil.
+	rows []*decoderRow
+	rank int
+}
+
+type decoderRow struct {
+	coefficients []uint64
+	data         []byte
+}
+
+// NewDecoder creates a new Decoder, given the number of (uncoded) fragments
+// and the fragment-size.
+func NewDecoder(nbFrag, fragmentSize int) (*Decoder, error) {
+	if nbFrag <= 0 {
+		return nil, errors.New("nbFrag must be greater than 0")
+	}
+	if fragmentSize <= 0 {
+		return nil, errors.New("fragment-size must be greater than 0")
+	}
+
+	return &Decoder{
+		nbFrag:       nbFrag,
+		fragmentSize: fragmentSize,
+		received:     make(map[uint16]struct{}),
+		rows:         make([]*decoderRow, nbFrag),
+	}, nil
+}
+
+// AddFragment adds the fragment with the given index N (starting at 1). It
+// returns true when all the data can be reconstructed. Fragments that have
+// already been received, or that do not contain new information, are
+// ignored.
+func (d *Decoder) AddFragment(n uint16, data []byte) (bool, error) {
+	if n == 0 {
+		return false, errors.New("fragment index N must be greater than 0")
+	}
+	if len(data) != d.fragmentSize {
+		return false, fmt.Errorf("fragment must be %d bytes", d.fragmentSize)
+	}
+
+	if _, ok := d.received[n]; ok || d.Complete() {
+		return d.Complete(), nil
+	}
+	d.received[n] = struct{}{}
+
+	row := decoderRow{
+		coefficients: make([]uint64, (d.nbFrag+63)/64),
+		data:         make([]byte, d.fragmentSize),
+	}
+	copy(row.data, data)
+
+	if int(n) <= d.nbFrag {
+		row.setCoefficient(int(n) - 1)
+	} else {
+		for i, v := range matrixLine(int(n)-d.nbFrag, d.nbFrag) {
+			if v == 1 {
+				row.setCoefficient(i)
+			}
+		}
+	}
+
+	// reduce the new row using the existing rows
+	for i, r := range d.rows {
+		if r != nil && row.coefficient(i) {
+			row.xor(r)
+		}
+	}
+
+	pivot := row.firstCoefficient()
+	if pivot == -1 {
+		// the fragment does not contain new information
+		return d.Complete(), nil
+	}
+
+	// eliminate the pivot from the existing rows, so that every pivot column
+	// is only set in its own row
+	for _, r := range d.rows {
+		if r != nil && r.coefficient(pivot) {
+			r.xor(&row)
+		}
+	}
+
+	d.rows[pivot] = &row
+	d.rank++
+
+	return d.Complete(), nil
+}
+
+// Complete returns true when all the data can be reconstructed.
+func (d *Decoder) Complete() bool {
+	return d.rank == d.nbFrag
+}
+
+// NbFragReceived returns the number of received fragments.
+func (d *Decoder) NbFragReceived() int {
+	return len(d.received)
+}
+
+// MissingFrag returns the (minimum) number of fragments that are still
+// needed to reconstruct the data.
+func (d *Decoder) MissingFrag() int {
+	return d.nbFrag - d.rank
+}
+
+// Data returns the reconstructed data. Note that this includes the padding
+// bytes that were added to the data to make it a multiple of the
+// fragment-size.
+func (d *Decoder) Data() ([]byte, error) {
+	if !d.Complete() {
+		return nil, fmt.Errorf("not enough fragments, %d fragments missing", d.MissingFrag())
+	}
+
+	out := make([]byte, 0, d.nbFrag*d.fragmentSize)
+	for _, r := range d.rows {
+		out = append(out, r.data...)
+	}
+	return out, nil
+}
+
+// Decode decodes the given fragments (indexed by the fragment index N,
+// starting at 1) into the original data, recovering the lost fragments
+// using the redundancy fragments. The nbFrag and fragmentSize must match
+// the values used by Encode. This is a convenience function around the
+// Decoder.
+func Decode(fragments map[uint16][]byte, nbFrag, fragmentSize int) ([]byte, error) {
+	d, err := NewDecoder(nbFrag, fragmentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for n, b := range fragments {
+		if _, err := d.AddFragment(n, b); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.Data()
+}
+
+func (r *decoderRow) setCoefficient(i int) {
+	r.coefficients[i/64] |= 1 << uint(i%64)
+}
+
+func (r *decoderRow) coefficient(i int) bool {
+	return r.coefficients[i/64]&(1<<uint(i%64)) != 0
+}
+
+func (r *decoderRow) firstCoefficient() int {
+	for i, v := range r.coefficients {
+		if v != 0 {
+			return i*64 + bits.TrailingZeros64(v)
+		}
+	}
+	return -1
+}
+
+func (r *decoderRow) xor(other *decoderRow) {
+	for i := range r.coefficients {
+		r.coefficients[i] ^= other.coefficients[i]
+	}
+	for i := range r.data {
+		r.data[i] ^= other.data[i]
+	}
+}
@@ -0,0 +1,128 @@
+package fragmentation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	fragments, err := Encode(data, 10, 10)
+	require.NoError(t, err)
+
+	// getFragments returns the encoded fragments by N, excluding the given
+	// (lost) fragment indices
+	getFragments := func(lost ...uint16) map[uint16][]byte {
+		out := make(map[uint16][]byte)
+		for i, f := range fragments {
+			out[uint16(i+1)] = f
+		}
+		for _, n := range lost {
+			delete(out, n)
+		}
+		return out
+	}
+
+	tests := []struct {
+		Name          string
+		Fragments     map[uint16][]byte
+		ExpectedData  []byte
+		ExpectedError error
+	}{
+		{
+			Name:         "no fragments lost",
+			Fragments:    getFragments(),
+			ExpectedData: data,
+		},
+		{
+			Name:         "uncoded fragments only",
+			Fragments:    getFragments(11, 12, 13, 14, 15, 16, 17, 18, 19, 20),
+			ExpectedData: data,
+		},
+		{
+			Name:         "uncoded fragments lost",
+			Fragments:    getFragments(1, 4, 5, 10),
+			ExpectedData: data,
+		},
+		{
+			Name:          "not enough fragments",
+			Fragments:     getFragments(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11),
+			ExpectedError: errors.New("not enough fragments, 1 fragments missing"),
+		},
+		{
+			Name:          "invalid fragment size",
+			Fragments:     map[uint16][]byte{1: {1, 2, 3}},
+			ExpectedError: errors.New("fragment must be 10 bytes"),
+		},
+		{
+			Name:          "invalid fragment index",
+			Fragments:     map[uint16][]byte{0: make([]byte, 10)},
+			ExpectedError: errors.New("fragment index N must be greater than 0"),
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := Decode(tst.Fragments, 10, 10)
+			if tst.ExpectedError != nil {
+				assert.Equal(tst.ExpectedError, err)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Equal(tst.ExpectedData, b)
+		})
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	assert := require.New(t)
+
+	data := make([]byte, 64*5)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	fragments, err := Encode(data, 5, 32)
+	assert.NoError(err)
+
+	d, err := NewDecoder(64, 5)
+	assert.NoError(err)
+
+	// every third uncoded fragment is lost
+	for i, f := range fragments {
+		if i < 64 && i%3 == 0 {
+			continue
+		}
+
+		complete, err := d.AddFragment(uint16(i+1), f)
+		assert.NoError(err)
+		if complete {
+			break
+		}
+	}
+
+	assert.True(d.Complete())
+	assert.Equal(0, d.MissingFrag())
+	assert.True(d.NbFragReceived() < len(fragments))
+
+	b, err := d.Data()
+	assert.NoError(err)
+	assert.Equal(data, b)
+
+	// duplicate fragments are ignored
+	complete, err := d.AddFragment(2, fragments[1])
+	assert.NoError(err)
+	assert.True(complete)
+
+	_, err = NewDecoder(0, 5)
+	assert.Error(err)
+}
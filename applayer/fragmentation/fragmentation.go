@@ -7,6 +7,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+
+	"github.com/brocaar/lorawan/registry"
 )
 
 // CID defines the command identifier.
@@ -33,29 +35,30 @@ var (
 	ErrNoPayloadForCID = errors.New("lorawan/applayer/fragmentation: no payload for given CID")
 )
 
-// map[uplink]...
-var commandPayloadRegistry = map[bool]map[CID]func() CommandPayload{
-	true: map[CID]func() CommandPayload{
-		PackageVersionAns:    func() CommandPayload { return &PackageVersionAnsPayload{} },
-		FragSessionSetupAns:  func() CommandPayload { return &FragSessionSetupAnsPayload{} },
-		FragSessionDeleteAns: func() CommandPayload { return &FragSessionDeleteAnsPayload{} },
-		FragSessionStatusAns: func() CommandPayload { return &FragSessionStatusAnsPayload{} },
+var commandPayloadRegistry = registry.New(
+	// uplink
+	map[CID]registry.Command[CommandPayload]{
+		PackageVersionAns:    {New: func() CommandPayload { return &PackageVersionAnsPayload{} }},
+		FragSessionSetupAns:  {New: func() CommandPayload { return &FragSessionSetupAnsPayload{} }},
+		FragSessionDeleteAns: {New: func() CommandPayload { return &FragSessionDeleteAnsPayload{} }},
+		FragSessionStatusAns: {New: func() CommandPayload { return &FragSessionStatusAnsPayload{} }},
 	},
-	false: map[CID]func() CommandPayload{
-		FragSessionSetupReq:  func() CommandPayload { return &FragSessionSetupReqPayload{} },
-		FragSessionDeleteReq: func() CommandPayload { return &FragSessionDeleteReqPayload{} },
-		DataFragment:         func() CommandPayload { return &DataFragmentPayload{} },
-		FragSessionStatusReq: func() CommandPayload { return &FragSessionStatusReqPayload{} },
+	// downlink
+	map[CID]registry.Command[CommandPayload]{
+		FragSessionSetupReq:  {New: func() CommandPayload { return &FragSessionSetupReqPayload{} }},
+		FragSessionDeleteReq: {New: func() CommandPayload { return &FragSessionDeleteReqPayload{} }},
+		DataFragment:         {New: func() CommandPayload { return &DataFragmentPayload{} }},
+		FragSessionStatusReq: {New: func() CommandPayload { return &FragSessionStatusReqPayload{} }},
 	},
-}
+)
 
 // GetCommandPayload returns a new CommandPayload for the given CID.
 func GetCommandPayload(uplink bool, c CID) (CommandPayload, error) {
-	v, ok := commandPayloadRegistry[uplink][c]
+	p, _, ok := commandPayloadRegistry.Get(uplink, c)
 	if !ok {
 		return nil, ErrNoPayloadForCID
 	}
-	return v(), nil
+	return p, nil
 }
 
 // CommandPayload defines the interface that a command payload must implement.
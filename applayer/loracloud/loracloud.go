@@ -0,0 +1,192 @@
+// Package loracloud implements the message wrappers for the LoRa Cloud
+// Device & Application Services (DAS). It provides the structures to
+// forward uplinks (e.g. the LoRa Basics Modem messages sent on FPort 199)
+// to the DAS API, to decode its response and to encode and decode the TLV
+// (tag, length, value) encoded modem payloads.
+package loracloud
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brocaar/lorawan"
+)
+
+// DefaultFPort defines the default fPort used by the LoRa Basics Modem for
+// modem messages.
+const DefaultFPort uint8 = 199
+
+// MsgType defines the DAS uplink message type.
+type MsgType string
+
+// Available message types.
+const (
+	MsgTypeUplink  MsgType = "updf"
+	MsgTypeJoining MsgType = "joining"
+	MsgTypeGNSS    MsgType = "gnss"
+	MsgTypeWiFi    MsgType = "wifi"
+)
+
+// Errors
+var (
+	ErrTLVTooShort      = errors.New("lorawan/applayer/loracloud: TLV is too short")
+	ErrTLVValueTooLarge = errors.New("lorawan/applayer/loracloud: max TLV value length is 255 bytes")
+)
+
+// DevEUI defines the DevEUI as used by the DAS API. It is formatted using
+// dashes, e.g. 01-02-03-04-05-06-07-08.
+type DevEUI lorawan.EUI64
+
+// String implements fmt.Stringer.
+func (e DevEUI) String() string {
+	parts := make([]string, len(e))
+	for i := range e {
+		parts[i] = fmt.Sprintf("%02x", e[i])
+	}
+	return strings.Join(parts, "-")
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (e DevEUI) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Both the dash
+// separated and the plain HEX formats are accepted.
+func (e *DevEUI) UnmarshalText(text []byte) error {
+	var eui lorawan.EUI64
+	if err := eui.UnmarshalText([]byte(strings.ReplaceAll(string(text), "-", ""))); err != nil {
+		return err
+	}
+	*e = DevEUI(eui)
+	return nil
+}
+
+// UplinkMessage defines the uplink message forwarded to the DAS.
+type UplinkMessage struct {
+	MsgType   MsgType          `json:"msgtype"`
+	FCnt      uint32           `json:"fcnt"`
+	Port      uint8            `json:"port"`
+	Payload   lorawan.HEXBytes `json:"payload"`
+	DR        int              `json:"dr"`
+	Freq      uint32           `json:"freq"`
+	Timestamp float64          `json:"timestamp"` // Unix timestamp in seconds
+}
+
+// NewUplinkMessage returns a new uplink message of type MsgTypeUplink.
+func NewUplinkMessage(fCnt uint32, fPort uint8, dr int, freq uint32, ts time.Time, payload []byte) UplinkMessage {
+	return UplinkMessage{
+		MsgType:   MsgTypeUplink,
+		FCnt:      fCnt,
+		Port:      fPort,
+		Payload:   lorawan.HEXBytes(payload),
+		DR:        dr,
+		Freq:      freq,
+		Timestamp: float64(ts.UnixNano()) / float64(time.Second),
+	}
+}
+
+// UplinkRequest defines the request body of the DAS uplink-send API,
+// containing the uplink messages per DevEUI.
+type UplinkRequest map[DevEUI]UplinkMessage
+
+// Downlink defines the downlink which must be sent to the device.
+type Downlink struct {
+	Port    uint8            `json:"port"`
+	Payload lorawan.HEXBytes `json:"payload"`
+}
+
+// StreamRecord defines a record of the reassembled stream.
+type StreamRecord struct {
+	Offset int
+	Data   lorawan.HEXBytes
+}
+
+// MarshalJSON implements json.Marshaler. A StreamRecord is encoded as
+// [offset, "data"].
+func (r StreamRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{r.Offset, r.Data})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *StreamRecord) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 2 {
+		return fmt.Errorf("lorawan/applayer/loracloud: stream record must contain 2 elements, got %d", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &r.Offset); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[1], &r.Data)
+}
+
+// UplinkResult defines the DAS result for a single uplink message.
+type UplinkResult struct {
+	Downlink          *Downlink       `json:"dnlink,omitempty"`
+	StreamRecords     []StreamRecord  `json:"stream_records,omitempty"`
+	PositionSolution  json.RawMessage `json:"position_solution,omitempty"`
+	InfoFields        json.RawMessage `json:"info_fields,omitempty"`
+	LogMessages       json.RawMessage `json:"log_messages,omitempty"`
+	FulfilledRequests json.RawMessage `json:"fulfilled_requests,omitempty"`
+	PendingRequests   json.RawMessage `json:"pending_requests,omitempty"`
+	File              json.RawMessage `json:"file,omitempty"`
+}
+
+// DeviceUplinkResult contains either the UplinkResult or the error.
+type DeviceUplinkResult struct {
+	Result *UplinkResult `json:"result,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// UplinkResponse defines the response body of the DAS uplink-send API.
+type UplinkResponse struct {
+	Result map[DevEUI]DeviceUplinkResult `json:"result"`
+}
+
+// TLV defines a tag, length, value encoded field. The length is implicit
+// and derived from the value.
+type TLV struct {
+	Tag   uint8
+	Value []byte
+}
+
+// MarshalTLVs encodes the given TLVs into a slice of bytes.
+func MarshalTLVs(tlvs []TLV) ([]byte, error) {
+	var out []byte
+	for _, tlv := range tlvs {
+		if len(tlv.Value) > 255 {
+			return nil, ErrTLVValueTooLarge
+		}
+		out = append(out, tlv.Tag, uint8(len(tlv.Value)))
+		out = append(out, tlv.Value...)
+	}
+	return out, nil
+}
+
+// UnmarshalTLVs decodes the given slice of bytes into TLVs.
+func UnmarshalTLVs(data []byte) ([]TLV, error) {
+	var out []TLV
+	for len(data) != 0 {
+		if len(data) < 2 {
+			return nil, ErrTLVTooShort
+		}
+
+		tag, l := data[0], int(data[1])
+		if len(data) < 2+l {
+			return nil, ErrTLVTooShort
+		}
+
+		out = append(out, TLV{
+			Tag:   tag,
+			Value: append([]byte{}, data[2:2+l]...),
+		})
+		data = data[2+l:]
+	}
+	return out, nil
+}
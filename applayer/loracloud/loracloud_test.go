@@ -0,0 +1,129 @@
+package loracloud
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestUplinkRequest(t *testing.T) {
+	assert := require.New(t)
+
+	req := UplinkRequest{
+		DevEUI{1, 2, 3, 4, 5, 6, 7, 8}: NewUplinkMessage(10, DefaultFPort, 5, 868100000, time.Unix(1600000000, 500000000), []byte{1, 2, 3}),
+	}
+
+	b, err := json.Marshal(req)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"01-02-03-04-05-06-07-08": {
+			"msgtype": "updf",
+			"fcnt": 10,
+			"port": 199,
+			"payload": "010203",
+			"dr": 5,
+			"freq": 868100000,
+			"timestamp": 1600000000.5
+		}
+	}`, string(b))
+
+	var decoded UplinkRequest
+	assert.NoError(json.Unmarshal(b, &decoded))
+	assert.Equal(req, decoded)
+}
+
+func TestUplinkResponse(t *testing.T) {
+	assert := require.New(t)
+
+	var resp UplinkResponse
+	assert.NoError(json.Unmarshal([]byte(`{
+		"result": {
+			"01-02-03-04-05-06-07-08": {
+				"result": {
+					"dnlink": {"port": 150, "payload": "0102"},
+					"stream_records": [[0, "aabb"], [2, "cc"]]
+				}
+			},
+			"0807060504030201": {
+				"error": "unknown device"
+			}
+		}
+	}`), &resp))
+
+	assert.Equal(UplinkResponse{
+		Result: map[DevEUI]DeviceUplinkResult{
+			{1, 2, 3, 4, 5, 6, 7, 8}: {
+				Result: &UplinkResult{
+					Downlink: &Downlink{Port: 150, Payload: lorawan.HEXBytes{1, 2}},
+					StreamRecords: []StreamRecord{
+						{Offset: 0, Data: lorawan.HEXBytes{0xaa, 0xbb}},
+						{Offset: 2, Data: lorawan.HEXBytes{0xcc}},
+					},
+				},
+			},
+			{8, 7, 6, 5, 4, 3, 2, 1}: {
+				Error: "unknown device",
+			},
+		},
+	}, resp)
+
+	var rec StreamRecord
+	assert.EqualError(json.Unmarshal([]byte(`[1]`), &rec), "lorawan/applayer/loracloud: stream record must contain 2 elements, got 1")
+}
+
+func TestTLV(t *testing.T) {
+	tests := []struct {
+		Name  string
+		TLVs  []TLV
+		Bytes []byte
+		Error error
+	}{
+		{
+			Name:  "two fields",
+			TLVs:  []TLV{{Tag: 0x01, Value: []byte{0x0a}}, {Tag: 0x02, Value: []byte{0x01, 0x02, 0x03}}},
+			Bytes: []byte{0x01, 0x01, 0x0a, 0x02, 0x03, 0x01, 0x02, 0x03},
+		},
+		{
+			Name:  "empty value",
+			TLVs:  []TLV{{Tag: 0x05, Value: []byte{}}},
+			Bytes: []byte{0x05, 0x00},
+		},
+		{
+			Name:  "missing length",
+			Bytes: []byte{0x01},
+			Error: ErrTLVTooShort,
+		},
+		{
+			Name:  "value too short",
+			Bytes: []byte{0x01, 0x03, 0x01},
+			Error: ErrTLVTooShort,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			tlvs, err := UnmarshalTLVs(tst.Bytes)
+			assert.Equal(tst.Error, err)
+			if tst.Error != nil {
+				return
+			}
+			assert.Equal(tst.TLVs, tlvs)
+
+			b, err := MarshalTLVs(tlvs)
+			assert.NoError(err)
+			assert.Equal(tst.Bytes, b)
+		})
+	}
+
+	t.Run("value too large", func(t *testing.T) {
+		assert := require.New(t)
+		_, err := MarshalTLVs([]TLV{{Value: make([]byte, 256)}})
+		assert.Equal(ErrTLVValueTooLarge, err)
+	})
+}
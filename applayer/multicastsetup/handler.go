@@ -0,0 +1,106 @@
+package multicastsetup
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Package identifier and version implemented by this package.
+const (
+	PackageIdentifier uint8 = 2
+	PackageVersion    uint8 = 1
+)
+
+// HandlerFunc handles a received command and returns the (optional) answer.
+type HandlerFunc func(cmd Command) (*Command, error)
+
+// Handler implements the end-device side handling of Remote Multicast Setup
+// commands. It decodes the payload received on the Remote Multicast Setup
+// fPort, answers the PackageVersionReq and dispatches all other commands to
+// the registered HandlerFuncs. It is safe for concurrent use.
+type Handler struct {
+	fPort uint8
+
+	mu       sync.RWMutex
+	handlers map[CID]HandlerFunc
+}
+
+// NewHandler creates a new Handler for the given fPort. When the fPort is 0,
+// DefaultFPort is used.
+func NewHandler(fPort uint8) *Handler {
+	if fPort == 0 {
+		fPort = DefaultFPort
+	}
+
+	return &Handler{
+		fPort:    fPort,
+		handlers: make(map[CID]HandlerFunc),
+	}
+}
+
+// FPort returns the fPort used by the handler.
+func (h *Handler) FPort() uint8 {
+	return h.fPort
+}
+
+// HandleFunc registers the HandlerFunc for the given CID. Registering a
+// HandlerFunc for PackageVersionReq overrides the default handling.
+func (h *Handler) HandleFunc(cid CID, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.handlers[cid] = fn
+}
+
+// HandleDownlink handles the payload received on the given fPort and returns
+// the uplink payload containing the answers (or nil when there is nothing to
+// answer). Payloads received on a different fPort are ignored.
+func (h *Handler) HandleDownlink(fPort uint8, data []byte) ([]byte, error) {
+	if fPort != h.fPort {
+		return nil, nil
+	}
+
+	var cmds Commands
+	if err := cmds.UnmarshalBinary(false, data); err != nil {
+		return nil, err
+	}
+
+	var answers Commands
+	for _, cmd := range cmds {
+		ans, err := h.handleCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+		if ans != nil {
+			answers = append(answers, *ans)
+		}
+	}
+
+	if len(answers) == 0 {
+		return nil, nil
+	}
+
+	return answers.MarshalBinary()
+}
+
+func (h *Handler) handleCommand(cmd Command) (*Command, error) {
+	h.mu.RLock()
+	fn, ok := h.handlers[cmd.CID]
+	h.mu.RUnlock()
+
+	if ok {
+		return fn(cmd)
+	}
+
+	if cmd.CID == PackageVersionReq {
+		return &Command{
+			CID: PackageVersionAns,
+			Payload: &PackageVersionAnsPayload{
+				PackageIdentifier: PackageIdentifier,
+				PackageVersion:    PackageVersion,
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("lorawan/applayer/multicastsetup: no handler for CID %s", cmd.CID)
+}
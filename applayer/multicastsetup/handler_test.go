@@ -0,0 +1,57 @@
+package multicastsetup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(0)
+	h.HandleFunc(McGroupDeleteReq, func(cmd Command) (*Command, error) {
+		pl := cmd.Payload.(*McGroupDeleteReqPayload)
+		return &Command{
+			CID: McGroupDeleteAns,
+			Payload: &McGroupDeleteAnsPayload{
+				McGroupIDHeader: McGroupDeleteAnsPayloadMcGroupIDHeader{
+					McGroupID: pl.McGroupIDHeader.McGroupID,
+				},
+			},
+		}, nil
+	})
+
+	t.Run("FPort", func(t *testing.T) {
+		require.Equal(t, DefaultFPort, h.FPort())
+	})
+
+	t.Run("PackageVersionReq", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := h.HandleDownlink(DefaultFPort, []byte{0x00})
+		assert.NoError(err)
+		assert.Equal([]byte{0x00, PackageIdentifier, PackageVersion}, b)
+	})
+
+	t.Run("PackageVersionReq and McGroupDeleteReq", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := h.HandleDownlink(DefaultFPort, []byte{0x00, 0x03, 0x02})
+		assert.NoError(err)
+		assert.Equal([]byte{0x00, PackageIdentifier, PackageVersion, 0x03, 0x02}, b)
+	})
+
+	t.Run("Other fPort", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := h.HandleDownlink(10, []byte{0x00})
+		assert.NoError(err)
+		assert.Nil(b)
+	})
+
+	t.Run("No handler", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := h.HandleDownlink(DefaultFPort, []byte{0x01, 0x01})
+		assert.EqualError(err, "lorawan/applayer/multicastsetup: no handler for CID McGroupStatusReq")
+	})
+}
@@ -1,10 +1,8 @@
 package multicastsetup
 
 import (
-	"crypto/aes"
-	"fmt"
-
 	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/crypto"
 )
 
 // GetMcRootKeyForGenAppKey returns the McRootKey given a GenAppKey.
@@ -51,16 +49,5 @@ func GetMcNetSKey(mcKey lorawan.AES128Key, mcAddr lorawan.DevAddr) (lorawan.AES1
 }
 
 func getKey(key lorawan.AES128Key, b [16]byte) (lorawan.AES128Key, error) {
-	var out lorawan.AES128Key
-
-	block, err := aes.NewCipher(key[:])
-	if err != nil {
-		return out, err
-	}
-	if block.BlockSize() != len(b) {
-		return out, fmt.Errorf("block-size of %d bytes is expected", len(b))
-	}
-
-	block.Encrypt(out[:], b[:])
-	return out, nil
+	return crypto.EncryptBlock(key, b)
 }
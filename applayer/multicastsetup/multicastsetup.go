@@ -7,8 +7,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/registry"
 )
 
 // CID defines the command identifier.
@@ -38,33 +40,34 @@ var (
 	ErrNoPayloadForCID = errors.New("lorawan/applayer/multicastsetup: no payload for given CID")
 )
 
-// map[uplink]...
-var commandPayloadRegistry = map[bool]map[CID]func() CommandPayload{
-	true: map[CID]func() CommandPayload{
-		PackageVersionAns:  func() CommandPayload { return &PackageVersionAnsPayload{} },
-		McGroupStatusAns:   func() CommandPayload { return &McGroupStatusAnsPayload{} },
-		McGroupSetupAns:    func() CommandPayload { return &McGroupSetupAnsPayload{} },
-		McGroupDeleteAns:   func() CommandPayload { return &McGroupDeleteAnsPayload{} },
-		McClassCSessionAns: func() CommandPayload { return &McClassCSessionAnsPayload{} },
-		McClassBSessionAns: func() CommandPayload { return &McClassBSessionAnsPayload{} },
+var commandPayloadRegistry = registry.New(
+	// uplink
+	map[CID]registry.Command[CommandPayload]{
+		PackageVersionAns:  {New: func() CommandPayload { return &PackageVersionAnsPayload{} }},
+		McGroupStatusAns:   {New: func() CommandPayload { return &McGroupStatusAnsPayload{} }},
+		McGroupSetupAns:    {New: func() CommandPayload { return &McGroupSetupAnsPayload{} }},
+		McGroupDeleteAns:   {New: func() CommandPayload { return &McGroupDeleteAnsPayload{} }},
+		McClassCSessionAns: {New: func() CommandPayload { return &McClassCSessionAnsPayload{} }},
+		McClassBSessionAns: {New: func() CommandPayload { return &McClassBSessionAnsPayload{} }},
 	},
-	false: map[CID]func() CommandPayload{
-		McGroupStatusReq:   func() CommandPayload { return &McGroupStatusReqPayload{} },
-		McGroupSetupReq:    func() CommandPayload { return &McGroupSetupReqPayload{} },
-		McGroupDeleteReq:   func() CommandPayload { return &McGroupDeleteReqPayload{} },
-		McClassCSessionReq: func() CommandPayload { return &McClassCSessionReqPayload{} },
-		McClassBSessionReq: func() CommandPayload { return &McClassBSessionReqPayload{} },
+	// downlink
+	map[CID]registry.Command[CommandPayload]{
+		McGroupStatusReq:   {New: func() CommandPayload { return &McGroupStatusReqPayload{} }},
+		McGroupSetupReq:    {New: func() CommandPayload { return &McGroupSetupReqPayload{} }},
+		McGroupDeleteReq:   {New: func() CommandPayload { return &McGroupDeleteReqPayload{} }},
+		McClassCSessionReq: {New: func() CommandPayload { return &McClassCSessionReqPayload{} }},
+		McClassBSessionReq: {New: func() CommandPayload { return &McClassBSessionReqPayload{} }},
 	},
-}
+)
 
 // GetCommandPayload returns a new CommandPayload for the given CID.
 func GetCommandPayload(uplink bool, c CID) (CommandPayload, error) {
-	v, ok := commandPayloadRegistry[uplink][c]
+	p, _, ok := commandPayloadRegistry.Get(uplink, c)
 	if !ok {
 		return nil, ErrNoPayloadForCID
 	}
 
-	return v(), nil
+	return p, nil
 }
 
 // CommandPayload defines the interface that a command payload must implement.
@@ -693,8 +696,34 @@ type McClassBSessionReqPayloadMcGroupIDHeader struct {
 
 // McClassBSessionReqPayloadTimeOutPeriodicity implements the McClassBSessionReq payload TimeOutPeriodicity field.
 type McClassBSessionReqPayloadTimeOutPeriodicity struct {
-	Periodicity uint8
-	TimeOut     uint8
+	Periodicity uint8 // 3 bits, the ping-slot period is 2^Periodicity * 0.96 seconds
+	TimeOut     uint8 // 4 bits, the session time-out is 2^TimeOut beacon periods
+}
+
+// Validate validates the Periodicity and TimeOut values.
+func (p McClassBSessionReqPayloadTimeOutPeriodicity) Validate() error {
+	if p.Periodicity > 7 {
+		return errors.New("lorawan/applayer/multicastsetup: max value of Periodicity is 7")
+	}
+	if p.TimeOut > 15 {
+		return errors.New("lorawan/applayer/multicastsetup: max value of TimeOut is 15")
+	}
+	return nil
+}
+
+// PingNb returns the number of ping-slots per beacon period.
+func (p McClassBSessionReqPayloadTimeOutPeriodicity) PingNb() int {
+	return 1 << (7 - (p.Periodicity & 0x07))
+}
+
+// PingPeriod returns the period between two ping-slots.
+func (p McClassBSessionReqPayloadTimeOutPeriodicity) PingPeriod() time.Duration {
+	return time.Duration(1<<(p.Periodicity&0x07)) * 960 * time.Millisecond
+}
+
+// SessionTimeOut returns the max. duration of the multicast session.
+func (p McClassBSessionReqPayloadTimeOutPeriodicity) SessionTimeOut() time.Duration {
+	return time.Duration(1<<(p.TimeOut&0x0f)) * 128 * time.Second
 }
 
 // Size returns the payload size in number of bytes.
@@ -704,6 +733,10 @@ func (p McClassBSessionReqPayload) Size() int {
 
 // MarshalBinary encodes the payload to a slice of bytes.
 func (p McClassBSessionReqPayload) MarshalBinary() ([]byte, error) {
+	if err := p.TimeOutPeriodicity.Validate(); err != nil {
+		return nil, err
+	}
+
 	b := make([]byte, p.Size())
 
 	// McGroupIDHeader
@@ -713,8 +746,8 @@ func (p McClassBSessionReqPayload) MarshalBinary() ([]byte, error) {
 	binary.LittleEndian.PutUint32(b[1:5], p.SessionTime)
 
 	// TimeOutPeriodicity
-	b[5] = p.TimeOutPeriodicity.TimeOut & 0x1f // first 4 bits
-	b[5] |= (p.TimeOutPeriodicity.Periodicity & 0x17) << 4
+	b[5] = p.TimeOutPeriodicity.TimeOut & 0x0f             // first 4 bits
+	b[5] |= (p.TimeOutPeriodicity.Periodicity & 0x07) << 4 // next 3 bits, last bit is RFU
 
 	// DLFrequency
 	if p.DLFrequency%100 != 0 {
@@ -743,8 +776,8 @@ func (p *McClassBSessionReqPayload) UnmarshalBinary(data []byte) error {
 	p.SessionTime = binary.LittleEndian.Uint32(data[1:5])
 
 	// TimeOutPeriodicity
-	p.TimeOutPeriodicity.TimeOut = data[5] & 0x1f
-	p.TimeOutPeriodicity.Periodicity = (data[5] >> 4) & 0x17
+	p.TimeOutPeriodicity.TimeOut = data[5] & 0x0f
+	p.TimeOutPeriodicity.Periodicity = (data[5] >> 4) & 0x07
 
 	// DLFrequency
 	dlFreqB := make([]byte, 4)
@@ -3,6 +3,7 @@ package multicastsetup
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/brocaar/lorawan"
 	"github.com/stretchr/testify/require"
@@ -296,6 +297,49 @@ func TestMulticastSetup(t *testing.T) {
 			},
 			Bytes: []byte{0x05, 0x03, 0x01, 0x02, 0x04, 0x08, 0x48, 0x28, 0x76, 0x84, 0x05},
 		},
+		{
+			Name: "McClassBSessionReq max TimeOut and Periodicity",
+			Command: Command{
+				CID: McClassBSessionReq,
+				Payload: &McClassBSessionReqPayload{
+					McGroupIDHeader: McClassBSessionReqPayloadMcGroupIDHeader{
+						McGroupID: 3,
+					},
+					SessionTime: 134480385,
+					TimeOutPeriodicity: McClassBSessionReqPayloadTimeOutPeriodicity{
+						TimeOut:     15,
+						Periodicity: 7,
+					},
+					DLFrequency: 868100000,
+					DR:          5,
+				},
+			},
+			Bytes: []byte{0x05, 0x03, 0x01, 0x02, 0x04, 0x08, 0x7f, 0x28, 0x76, 0x84, 0x05},
+		},
+		{
+			Name: "McClassBSessionReq invalid TimeOut",
+			Command: Command{
+				CID: McClassBSessionReq,
+				Payload: &McClassBSessionReqPayload{
+					TimeOutPeriodicity: McClassBSessionReqPayloadTimeOutPeriodicity{
+						TimeOut: 16,
+					},
+				},
+			},
+			ExpectedMarshalError: errors.New("lorawan/applayer/multicastsetup: max value of TimeOut is 15"),
+		},
+		{
+			Name: "McClassBSessionReq invalid Periodicity",
+			Command: Command{
+				CID: McClassBSessionReq,
+				Payload: &McClassBSessionReqPayload{
+					TimeOutPeriodicity: McClassBSessionReqPayloadTimeOutPeriodicity{
+						Periodicity: 8,
+					},
+				},
+			},
+			ExpectedMarshalError: errors.New("lorawan/applayer/multicastsetup: max value of Periodicity is 7"),
+		},
 		{
 			Name:                   "McClassBSessionReq invalid bytes",
 			Bytes:                  []byte{0x05, 0x03, 0x01, 0x02, 0x04, 0x08, 0x48, 0x28, 0x76, 0x84},
@@ -436,3 +480,22 @@ func TestUnmarshalCommands(t *testing.T) {
 	assert.NoError(cmds.UnmarshalBinary(true, b))
 	assert.Equal(commands, cmds)
 }
+
+func TestMcClassBSessionReqPayloadTimeOutPeriodicity(t *testing.T) {
+	assert := require.New(t)
+
+	// the RFU bit must be ignored
+	var pl McClassBSessionReqPayload
+	assert.NoError(pl.UnmarshalBinary([]byte{0x03, 0x01, 0x02, 0x04, 0x08, 0xff, 0x28, 0x76, 0x84, 0x05}))
+	assert.Equal(McClassBSessionReqPayloadTimeOutPeriodicity{TimeOut: 15, Periodicity: 7}, pl.TimeOutPeriodicity)
+
+	p := McClassBSessionReqPayloadTimeOutPeriodicity{TimeOut: 2, Periodicity: 0}
+	assert.Equal(128, p.PingNb())
+	assert.Equal(960*time.Millisecond, p.PingPeriod())
+	assert.Equal(512*time.Second, p.SessionTimeOut())
+
+	p = McClassBSessionReqPayloadTimeOutPeriodicity{TimeOut: 0, Periodicity: 7}
+	assert.Equal(1, p.PingNb())
+	assert.Equal(128*960*time.Millisecond, p.PingPeriod())
+	assert.Equal(128*time.Second, p.SessionTimeOut())
+}
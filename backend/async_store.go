@@ -0,0 +1,215 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// AsyncStore implements the correlation of requests and answers for the
+// async protocol scheme. A request claims its TransactionID, after which the
+// answer can be published (e.g. by the instance receiving the answer) and
+// read by the instance waiting for it.
+type AsyncStore interface {
+	// Claim claims the given TransactionID for the given duration. It must
+	// return ErrAsyncTransactionPending when the TransactionID has already
+	// been claimed. Answers left over from a previous transaction using the
+	// same TransactionID must be removed.
+	Claim(ctx context.Context, id uint32, ttl time.Duration) error
+
+	// Release releases the given TransactionID and removes its answer.
+	Release(ctx context.Context, id uint32) error
+
+	// Publish publishes the answer for the given TransactionID. It must
+	// return ErrAsyncUnknownTransaction when the TransactionID has not been
+	// claimed.
+	Publish(ctx context.Context, id uint32, answer []byte, ttl time.Duration) error
+
+	// Read blocks until the answer for the given TransactionID has been
+	// published. It returns ErrAsyncTimeout when no answer has been
+	// published within the given timeout and the context error when the
+	// context has been cancelled.
+	Read(ctx context.Context, id uint32, timeout time.Duration) ([]byte, error)
+}
+
+// redisAsyncStore implements AsyncStore using Redis. For each claimed
+// transaction, an ownership record is stored and the answer is routed
+// through a Redis stream, so that the answer can be published by any instance
+// sharing the same Redis database.
+type redisAsyncStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisAsyncStore returns an AsyncStore using the given Redis client.
+func NewRedisAsyncStore(client redis.UniversalClient) AsyncStore {
+	return &redisAsyncStore{
+		client: client,
+	}
+}
+
+func (s *redisAsyncStore) Claim(ctx context.Context, id uint32, ttl time.Duration) error {
+	ok, err := s.client.SetNX(ctx, s.getOwnerKey(id), 1, ttl).Result()
+	if err != nil {
+		return errors.Wrap(err, "claim transaction error")
+	}
+	if !ok {
+		return ErrAsyncTransactionPending
+	}
+
+	if err := s.client.Del(ctx, s.getKey(id)).Err(); err != nil {
+		return errors.Wrap(err, "delete answer stream error")
+	}
+
+	return nil
+}
+
+func (s *redisAsyncStore) Release(ctx context.Context, id uint32) error {
+	return s.client.Del(ctx, s.getOwnerKey(id), s.getKey(id)).Err()
+}
+
+func (s *redisAsyncStore) Publish(ctx context.Context, id uint32, answer []byte, ttl time.Duration) error {
+	n, err := s.client.Exists(ctx, s.getOwnerKey(id)).Result()
+	if err != nil {
+		return errors.Wrap(err, "read transaction owner error")
+	}
+	if n == 0 {
+		return ErrAsyncUnknownTransaction
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.getKey(id),
+		Values: map[string]interface{}{
+			"answer": answer,
+		},
+	})
+	pipe.PExpire(ctx, s.getKey(id), ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "publish answer error")
+	}
+
+	return nil
+}
+
+func (s *redisAsyncStore) Read(ctx context.Context, id uint32, timeout time.Duration) ([]byte, error) {
+	streams, err := s.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{s.getKey(id), "0"},
+		Count:   1,
+		Block:   timeout,
+	}).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrAsyncTimeout
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, errors.Wrap(err, "read answer error")
+	}
+
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			if answer, ok := msg.Values["answer"].(string); ok {
+				return []byte(answer), nil
+			}
+		}
+	}
+
+	return nil, ErrAsyncTimeout
+}
+
+func (s *redisAsyncStore) getKey(id uint32) string {
+	return fmt.Sprintf("lora:backend:async:%d", id)
+}
+
+func (s *redisAsyncStore) getOwnerKey(id uint32) string {
+	return fmt.Sprintf("lora:backend:async:%d:owner", id)
+}
+
+// memoryAsyncStore implements an in-memory AsyncStore.
+type memoryAsyncStore struct {
+	mu           sync.Mutex
+	transactions map[uint32]memoryAsyncTransaction
+}
+
+type memoryAsyncTransaction struct {
+	expires time.Time
+	answer  chan []byte
+}
+
+// NewMemoryAsyncStore returns an in-memory AsyncStore. As the answer must be
+// published to the same instance that made the request, this is intended for
+// single instance deployments and testing.
+func NewMemoryAsyncStore() AsyncStore {
+	return &memoryAsyncStore{
+		transactions: make(map[uint32]memoryAsyncTransaction),
+	}
+}
+
+func (s *memoryAsyncStore) Claim(ctx context.Context, id uint32, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.transactions[id]; ok && time.Now().Before(t.expires) {
+		return ErrAsyncTransactionPending
+	}
+
+	s.transactions[id] = memoryAsyncTransaction{
+		expires: time.Now().Add(ttl),
+		answer:  make(chan []byte, 1),
+	}
+
+	return nil
+}
+
+func (s *memoryAsyncStore) Release(ctx context.Context, id uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.transactions, id)
+	return nil
+}
+
+func (s *memoryAsyncStore) Publish(ctx context.Context, id uint32, answer []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transactions[id]
+	if !ok || !time.Now().Before(t.expires) {
+		return ErrAsyncUnknownTransaction
+	}
+
+	// Only the first answer is read, further answers are discarded.
+	select {
+	case t.answer <- answer:
+	default:
+	}
+
+	return nil
+}
+
+func (s *memoryAsyncStore) Read(ctx context.Context, id uint32, timeout time.Duration) ([]byte, error) {
+	s.mu.Lock()
+	t, ok := s.transactions[id]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, ErrAsyncUnknownTransaction
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, ErrAsyncTimeout
+	case answer := <-t.answer:
+		return answer, nil
+	}
+}
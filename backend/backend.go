@@ -3,10 +3,13 @@ package backend
 
 import (
 	"crypto/aes"
-	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	keywrap "github.com/NickBall/go-aes-key-wrap"
@@ -32,6 +35,28 @@ const (
 	Handover RoamingType = "Handover"
 )
 
+// ClassMode defines the ClassMode type.
+type ClassMode string
+
+// Available class modes. Note that the LoRaWAN Backend Interfaces
+// specification only supports Class-A and Class-C, Class-B is reserved for
+// future use.
+const (
+	ClassModeA ClassMode = "A"
+	ClassModeB ClassMode = "B"
+	ClassModeC ClassMode = "C"
+)
+
+// Validate returns an error in case of an unknown class mode.
+func (c ClassMode) Validate() error {
+	switch c {
+	case ClassModeA, ClassModeB, ClassModeC:
+		return nil
+	default:
+		return fmt.Errorf("invalid ClassMode: %s", string(c))
+	}
+}
+
 // Supported protocol versions.
 const (
 	ProtocolVersion1_0 = "1.0"
@@ -103,28 +128,8 @@ type Request interface {
 }
 
 // HEXBytes defines a type which represents bytes as HEX when marshaled to
-// text.
-type HEXBytes []byte
-
-// String implements fmt.Stringer.
-func (hb HEXBytes) String() string {
-	return hex.EncodeToString(hb[:])
-}
-
-// MarshalText implements encoding.TextMarshaler.
-func (hb HEXBytes) MarshalText() ([]byte, error) {
-	return []byte(hb.String()), nil
-}
-
-// UnmarshalText implements encoding.TextUnmarshaler.
-func (hb *HEXBytes) UnmarshalText(text []byte) error {
-	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
-	if err != nil {
-		return err
-	}
-	*hb = HEXBytes(b)
-	return nil
-}
+// text. It is an alias of lorawan.HEXBytes.
+type HEXBytes = lorawan.HEXBytes
 
 // ISO8601Time defines an ISO 8601 encoded timestamp.
 type ISO8601Time time.Time
@@ -147,21 +152,66 @@ func (t *ISO8601Time) UnmarshalText(text []byte) error {
 // Frequency defines the frequency type (in Hz).
 type Frequency int
 
+// frequencyDecimals holds the number of decimals used by Frequency.MarshalJSON.
+// A negative value means the minimal number of decimals.
+var frequencyDecimals int32 = -1
+
+// SetFrequencyDecimals sets the (fixed) number of decimals (0 - 6) used when
+// marshaling a Frequency to JSON. This can be used for peers which expect
+// a fixed number of decimals (e.g. 868.100). By default (or when set to a
+// negative value), the minimal number of decimals is used to represent the
+// exact frequency (e.g. 868.1). It is safe to call this at run-time.
+func SetFrequencyDecimals(decimals int) error {
+	if decimals > 6 {
+		return errors.New("max number of frequency decimals is 6")
+	}
+	if decimals < 0 {
+		decimals = -1
+	}
+	atomic.StoreInt32(&frequencyDecimals, int32(decimals))
+	return nil
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 // This returns the frequency value in MHz (e.g. 868.1) to be compatible
-// with the LoRaWAN Backend Interfaces specification.
+// with the LoRaWAN Backend Interfaces specification. The value is formatted
+// as decimal, to avoid floating-point artifacts (e.g. 868.0999999).
 func (f Frequency) MarshalJSON() ([]byte, error) {
-	return json.Marshal(float64(f) / 1000000)
+	mhz := big.NewRat(int64(f), 1000000)
+
+	decimals := atomic.LoadInt32(&frequencyDecimals)
+	if decimals >= 0 {
+		return []byte(mhz.FloatString(int(decimals))), nil
+	}
+
+	str := mhz.FloatString(6)
+	str = strings.TrimRight(str, "0")
+	str = strings.TrimSuffix(str, ".")
+	return []byte(str), nil
 }
 
+// frequencyRegexp matches a JSON number in decimal notation (e.g. 868.1).
+var frequencyRegexp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
-// This parses a frequency in MHz (float type) back to Hz (int).
+// This parses a frequency in MHz (JSON number in decimal notation) back to
+// Hz (int), rounded to the nearest Hz.
 func (f *Frequency) UnmarshalJSON(str []byte) error {
-	mhz, err := strconv.ParseFloat(string(str), 64)
+	if !frequencyRegexp.Match(str) {
+		return fmt.Errorf("parse frequency error: invalid value: %s", str)
+	}
+
+	mhz, ok := new(big.Rat).SetString(string(str))
+	if !ok {
+		return fmt.Errorf("parse frequency error: invalid value: %s", str)
+	}
+
+	hz := mhz.Mul(mhz, big.NewRat(1000000, 1))
+	rounded, err := strconv.ParseInt(hz.FloatString(0), 10, 64)
 	if err != nil {
-		return errors.Wrap(err, "parse float error")
+		return errors.Wrap(err, "parse frequency error")
 	}
-	*f = Frequency(mhz * 1000000)
+	*f = Frequency(rounded)
 	return nil
 }
 
@@ -187,6 +237,11 @@ func (p *Percentage) UnmarshalJSON(str []byte) error {
 }
 
 // BasePayload defines the base payload that is sent with every request.
+//
+// Note that the binary encoding (see MarshalBinaryPayload) follows the field
+// order of the payload structs. Adding, removing or reordering fields of
+// BasePayload or any of the request and answer payloads requires a
+// BinaryVersion bump.
 type BasePayload struct {
 	ProtocolVersion string      `json:"ProtocolVersion"` // Version of backend specification. E.g., "1.0"
 	SenderID        string      `json:"SenderID"`        // Hexadecimal representation in ASCII format in case of carrying NetID or JoinEUI, ASCII string in case of AS-ID
@@ -271,15 +326,15 @@ type VSExtension struct {
 
 // GWInfoElement defines the gateway info element.
 type GWInfoElement struct {
-	ID           HEXBytes `json:"ID,omitempty"`           // TODO: shouldn't this be the gateway MAC (64 bit)?
-	FineRecvTime *int     `json:"FineRecvTime,omitempty"` // Nanosec within RecvTime
-	RFRegion     string   `json:"RFRegion,omitempty"`
-	RSSI         *int     `json:"RSSI,omitempty"` // Signed integer, unit: dBm
-	SNR          *float64 `json:"SNR,omitempty"`  // Unit: dB
-	Lat          *float64 `json:"Lat,omitempty"`
-	Lon          *float64 `json:"Lon,omitempty"`
-	ULToken      HEXBytes `json:"ULToken,omitempty"`
-	DLAllowed    bool     `json:"DLAllowed,omitempty"`
+	ID           *lorawan.GatewayEUI `json:"ID,omitempty"`
+	FineRecvTime *int                `json:"FineRecvTime,omitempty"` // Nanosec within RecvTime
+	RFRegion     string              `json:"RFRegion,omitempty"`
+	RSSI         *int                `json:"RSSI,omitempty"` // Signed integer, unit: dBm
+	SNR          *float64            `json:"SNR,omitempty"`  // Unit: dB
+	Lat          *float64            `json:"Lat,omitempty"`
+	Lon          *float64            `json:"Lon,omitempty"`
+	ULToken      HEXBytes            `json:"ULToken,omitempty"`
+	DLAllowed    bool                `json:"DLAllowed,omitempty"`
 }
 
 // ULMetaData defines the uplink metadata.
@@ -310,7 +365,7 @@ type DLMetaData struct {
 	DLFreq1        *float64        `json:"DLFreq1,omitempty"` // TODO: In MHz? At least DLFreq1 or DLFreq2 SHALL be present.
 	DLFreq2        *float64        `json:"DLFreq2,omitempty"` // TODO: In Mhz? At least DLFreq1 or DLFreq2 SHALL be present.
 	RXDelay1       *int            `json:"RXDelay1,omitempty"`
-	ClassMode      *string         `json:"ClassMode,omitempty"` // Only "A" and "C" are supported
+	ClassMode      *ClassMode      `json:"ClassMode,omitempty"`
 	DataRate1      *int            `json:"DataRate1,omitempty"` // Present only if DLFreq1 is present
 	DataRate2      *int            `json:"DataRate2,omitempty"` // Present only if DLFreq2 is present
 	FNSULToken     HEXBytes        `json:"FNSULToken,omitempty"`
@@ -318,6 +373,62 @@ type DLMetaData struct {
 	HiPriorityFlag bool            `json:"HiPriorityFlag,omitempty"`
 }
 
+// Validate validates the consistency of the ClassMode, RXDelay1 and
+// DataRate fields:
+//
+//   - DataRate1 and DataRate2 must only be present if resp. DLFreq1 and
+//     DLFreq2 are present
+//   - RXDelay1 must be between 0 and 15
+//   - For Class-A, RXDelay1 must be present if DLFreq1 is present
+//   - For Class-B and Class-C, the RX1 parameters (DLFreq1, DataRate1 and
+//     RXDelay1) must not be present and DLFreq2 must be present
+func (m DLMetaData) Validate() error {
+	if m.DataRate1 != nil && m.DLFreq1 == nil {
+		return errors.New("DataRate1 must only be present if DLFreq1 is present")
+	}
+	if m.DataRate2 != nil && m.DLFreq2 == nil {
+		return errors.New("DataRate2 must only be present if DLFreq2 is present")
+	}
+	if m.RXDelay1 != nil && (*m.RXDelay1 < 0 || *m.RXDelay1 > 15) {
+		return errors.New("RXDelay1 must be between 0 and 15")
+	}
+
+	if m.ClassMode == nil {
+		return nil
+	}
+
+	if err := m.ClassMode.Validate(); err != nil {
+		return err
+	}
+
+	switch *m.ClassMode {
+	case ClassModeA:
+		if m.DLFreq1 != nil && m.RXDelay1 == nil {
+			return errors.New("RXDelay1 must be present for ClassMode A when DLFreq1 is present")
+		}
+	case ClassModeB, ClassModeC:
+		if m.DLFreq1 != nil || m.DataRate1 != nil || m.RXDelay1 != nil {
+			return fmt.Errorf("DLFreq1, DataRate1 and RXDelay1 must not be present for ClassMode %s", *m.ClassMode)
+		}
+		if m.DLFreq2 == nil {
+			return fmt.Errorf("DLFreq2 must be present for ClassMode %s", *m.ClassMode)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. It returns an error
+// when the DLMetaData is not valid (see Validate).
+func (m DLMetaData) MarshalJSON() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, errors.Wrap(err, "validate DLMetaData error")
+	}
+
+	type dlMetaData DLMetaData
+	return json.Marshal(dlMetaData(m))
+}
+
 // JoinReqPayload defines the JoinReq message payload.
 type JoinReqPayload struct {
 	BasePayload
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -49,6 +50,67 @@ func TestFrequency(t *testing.T) {
 			So(f.UnmarshalJSON([]byte("868.2")), ShouldBeNil)
 			So(f, ShouldEqual, Frequency(868200000))
 		})
+
+		Convey("Then UnmarshalJSON rounds to the nearest Hz", func() {
+			So(f.UnmarshalJSON([]byte("868.0999999999")), ShouldBeNil)
+			So(f, ShouldEqual, Frequency(868100000))
+		})
+
+		Convey("Then UnmarshalJSON returns an error for an invalid value", func() {
+			for _, str := range []string{
+				"foo",
+				"",
+				"null",
+				`"868.3"`,
+				`"868.3`,
+				`868.3"`,
+				"1/3",
+				"8.683e2",
+				"868.",
+				".5",
+				"0868.3",
+				"+868.3",
+			} {
+				So(f.UnmarshalJSON([]byte(str)), ShouldNotBeNil)
+			}
+		})
+
+		Convey("Then UnmarshalJSON returns an error when the frequency is out of range", func() {
+			So(f.UnmarshalJSON([]byte("99999999999999999")), ShouldBeError, `parse frequency error: strconv.ParseInt: parsing "99999999999999999000000": value out of range`)
+		})
+
+		Convey("Then MarshalJSON does not use more decimals than needed", func() {
+			for i, test := range []struct {
+				Frequency Frequency
+				Expected  string
+			}{
+				{868000000, "868"},
+				{868100000, "868.1"},
+				{868525000, "868.525"},
+				{433175001, "433.175001"},
+			} {
+				Convey(fmt.Sprintf("Testing: %d [%d]", test.Frequency, i), func() {
+					b, err := test.Frequency.MarshalJSON()
+					So(err, ShouldBeNil)
+					So(string(b), ShouldEqual, test.Expected)
+				})
+			}
+		})
+
+		Convey("When setting a fixed number of decimals", func() {
+			So(SetFrequencyDecimals(3), ShouldBeNil)
+			defer SetFrequencyDecimals(-1)
+
+			Convey("Then MarshalJSON returns the expected value", func() {
+				b, err := f.MarshalJSON()
+				So(err, ShouldBeNil)
+				So(string(b), ShouldEqual, "868.100")
+			})
+		})
+
+		Convey("Then SetFrequencyDecimals returns an error for more than 6 decimals", func() {
+			So(SetFrequencyDecimals(7), ShouldNotBeNil)
+		})
 	})
 }
 
@@ -121,3 +183,116 @@ func TestKeyEnvelope(t *testing.T) {
 		})
 	})
 }
+
+func TestClassMode(t *testing.T) {
+	assert := require.New(t)
+
+	assert.NoError(ClassModeA.Validate())
+	assert.NoError(ClassModeB.Validate())
+	assert.NoError(ClassModeC.Validate())
+	assert.EqualError(ClassMode("D").Validate(), "invalid ClassMode: D")
+}
+
+func TestDLMetaDataValidate(t *testing.T) {
+	classA := ClassModeA
+	classC := ClassModeC
+	invalid := ClassMode("D")
+	freq := 868.1
+	dr := 5
+	rxDelay := 1
+	invalidRXDelay := 16
+
+	tests := []struct {
+		name  string
+		dl    DLMetaData
+		json  string
+		error string
+	}{
+		{
+			name: "class a",
+			dl: DLMetaData{
+				ClassMode: &classA,
+				DLFreq1:   &freq,
+				DataRate1: &dr,
+				RXDelay1:  &rxDelay,
+			},
+			json: `{"DLFreq1":868.1,"RXDelay1":1,"ClassMode":"A","DataRate1":5,"GWInfo":null}`,
+		},
+		{
+			name: "class c",
+			dl: DLMetaData{
+				ClassMode: &classC,
+				DLFreq2:   &freq,
+				DataRate2: &dr,
+			},
+			json: `{"DLFreq2":868.1,"ClassMode":"C","DataRate2":5,"GWInfo":null}`,
+		},
+		{
+			name: "invalid class mode",
+			dl: DLMetaData{
+				ClassMode: &invalid,
+			},
+			error: "invalid ClassMode: D",
+		},
+		{
+			name: "class a without rx delay",
+			dl: DLMetaData{
+				ClassMode: &classA,
+				DLFreq1:   &freq,
+				DataRate1: &dr,
+			},
+			error: "RXDelay1 must be present for ClassMode A when DLFreq1 is present",
+		},
+		{
+			name: "class c with rx1 parameters",
+			dl: DLMetaData{
+				ClassMode: &classC,
+				DLFreq1:   &freq,
+				DLFreq2:   &freq,
+				RXDelay1:  &rxDelay,
+			},
+			error: "DLFreq1, DataRate1 and RXDelay1 must not be present for ClassMode C",
+		},
+		{
+			name: "class c without rx2 frequency",
+			dl: DLMetaData{
+				ClassMode: &classC,
+			},
+			error: "DLFreq2 must be present for ClassMode C",
+		},
+		{
+			name: "data rate without frequency",
+			dl: DLMetaData{
+				DataRate2: &dr,
+			},
+			error: "DataRate2 must only be present if DLFreq2 is present",
+		},
+		{
+			name: "invalid rx delay",
+			dl: DLMetaData{
+				RXDelay1: &invalidRXDelay,
+			},
+			error: "RXDelay1 must be between 0 and 15",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			err := tst.dl.Validate()
+			if tst.error != "" {
+				assert.EqualError(err, tst.error)
+
+				_, err = json.Marshal(tst.dl)
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+
+			b, err := json.Marshal(tst.dl)
+			assert.NoError(err)
+			assert.Equal(tst.json, string(b))
+		})
+	}
+}
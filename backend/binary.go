@@ -0,0 +1,618 @@
+package backend
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// This file contains the binary encoding of the backend payloads, which can
+// be used for transports (e.g. message queues) for which the JSON encoding
+// is too verbose. The encoding is deterministic and starts with a header
+// containing the encoding version and the MessageType, so that the receiver
+// can select the payload type before decoding the payload:
+//
+//	Version (1 byte) | len(MessageType) (uvarint) | MessageType | Payload
+//
+// The payload fields are encoded in the order in which they are declared:
+//
+//   - bool: 1 byte (0 or 1)
+//   - signed integers: zig-zag encoded varint
+//   - unsigned integers: uvarint
+//   - float64: IEEE 754 binary representation (8 bytes, little-endian)
+//   - strings, HEXBytes and json.RawMessage: length (uvarint) + bytes
+//   - byte arrays (e.g. EUI64, DevAddr): the bytes
+//   - time.Time and ISO8601Time: 1 byte (0 for zero time) + seconds since
+//     Unix epoch (varint) + nanoseconds (uvarint)
+//   - pointers: 1 byte (0 for nil) + value
+//   - slices: number of elements (uvarint) + elements
+//   - structs: fields in declared order
+//
+// Note that time values are decoded in UTC. As the encoding follows the
+// declaration order of the payload struct fields, adding, removing or
+// reordering fields of the payload structs (including the nested structs)
+// changes the encoding.
+
+// BinaryVersion defines the version of the binary encoding. It must be
+// incremented on every change of the encoding, including changes of the
+// fields of the payload structs.
+const BinaryVersion uint8 = 1
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	iso8601TimeType = reflect.TypeOf(ISO8601Time{})
+)
+
+// MarshalBinaryPayload marshals the given payload (request or answer) into
+// its binary form.
+func MarshalBinaryPayload(pl interface{}) ([]byte, error) {
+	var mt MessageType
+	switch v := pl.(type) {
+	case Request:
+		mt = v.GetBasePayload().MessageType
+	case Answer:
+		mt = v.GetBasePayload().MessageType
+	default:
+		return nil, errors.Errorf("unexpected payload type: %T", pl)
+	}
+
+	b := []byte{BinaryVersion}
+	b = appendUvarint(b, uint64(len(mt)))
+	b = append(b, mt...)
+
+	return appendBinaryValue(b, reflect.Indirect(reflect.ValueOf(pl)))
+}
+
+// UnmarshalBinaryPayload unmarshals the binary payload into the given
+// payload pointer. It returns an error when the encoding version is not
+// supported or when the MessageType of the header does not match the
+// MessageType of the decoded payload.
+func UnmarshalBinaryPayload(b []byte, pl interface{}) error {
+	mt, n, err := readBinaryHeader(b)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(pl)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.Errorf("expected non-nil pointer, got: %T", pl)
+	}
+
+	d := binaryDecoder{b: b[n:]}
+	if err := d.decode(v.Elem()); err != nil {
+		return err
+	}
+	if len(d.b) != 0 {
+		return errors.Errorf("%d trailing bytes", len(d.b))
+	}
+
+	var plMT MessageType
+	switch v := pl.(type) {
+	case Request:
+		plMT = v.GetBasePayload().MessageType
+	case Answer:
+		plMT = v.GetBasePayload().MessageType
+	default:
+		return errors.Errorf("unexpected payload type: %T", pl)
+	}
+
+	if plMT != mt {
+		return errors.Errorf("MessageType of header (%s) does not match payload (%s)", mt, plMT)
+	}
+
+	return nil
+}
+
+// GetBinaryMessageType returns the MessageType from the header of the given
+// binary payload. This can be used to select the payload type to pass to
+// UnmarshalBinaryPayload.
+func GetBinaryMessageType(b []byte) (MessageType, error) {
+	mt, _, err := readBinaryHeader(b)
+	return mt, err
+}
+
+func readBinaryHeader(b []byte) (MessageType, int, error) {
+	if len(b) == 0 {
+		return "", 0, errors.New("binary payload is empty")
+	}
+	if b[0] != BinaryVersion {
+		return "", 0, errors.Errorf("unsupported binary version: %d", b[0])
+	}
+
+	d := binaryDecoder{b: b[1:]}
+	mt, err := d.readBytes()
+	if err != nil {
+		return "", 0, errors.Wrap(err, "read MessageType error")
+	}
+
+	return MessageType(mt), len(b) - len(d.b), nil
+}
+
+func appendBinaryValue(b []byte, v reflect.Value) ([]byte, error) {
+	switch v.Type() {
+	case timeType, iso8601TimeType:
+		t := v.Convert(timeType).Interface().(time.Time)
+		if t.IsZero() {
+			return append(b, 0), nil
+		}
+		b = append(b, 1)
+		b = appendVarint(b, t.Unix())
+		return appendUvarint(b, uint64(t.Nanosecond())), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(b, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return appendUvarint(b, v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return appendUint64(b, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		b = appendUvarint(b, uint64(v.Len()))
+		return append(b, v.String()...), nil
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			for i := 0; i < v.Len(); i++ {
+				b = append(b, byte(v.Index(i).Uint()))
+			}
+			return b, nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			if b, err = appendBinaryValue(b, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Slice:
+		b = appendUvarint(b, uint64(v.Len()))
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return append(b, v.Bytes()...), nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			if b, err = appendBinaryValue(b, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return append(b, 0), nil
+		}
+		return appendBinaryValue(append(b, 1), v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			var err error
+			if b, err = appendBinaryValue(b, v.Field(i)); err != nil {
+				return nil, errors.Wrapf(err, "field %s", v.Type().Field(i).Name)
+			}
+		}
+		return b, nil
+	default:
+		return nil, errors.Errorf("unsupported type: %s", v.Type())
+	}
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+type binaryDecoder struct {
+	b []byte
+}
+
+func (d *binaryDecoder) readByte() (byte, error) {
+	if len(d.b) == 0 {
+		return 0, errors.New("unexpected end of data")
+	}
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c, nil
+}
+
+func (d *binaryDecoder) readN(n int) ([]byte, error) {
+	if n < 0 || len(d.b) < n {
+		return nil, errors.New("unexpected end of data")
+	}
+	out := d.b[:n]
+	d.b = d.b[n:]
+	return out, nil
+}
+
+func (d *binaryDecoder) readVarint() (int64, error) {
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		return 0, errors.New("invalid varint")
+	}
+	d.b = d.b[n:]
+	return v, nil
+}
+
+func (d *binaryDecoder) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		return 0, errors.New("invalid uvarint")
+	}
+	d.b = d.b[n:]
+	return v, nil
+}
+
+func (d *binaryDecoder) readLen() (int, error) {
+	l, err := d.readUvarint()
+	if err != nil {
+		return 0, err
+	}
+	if l > uint64(len(d.b)) {
+		return 0, errors.New("unexpected end of data")
+	}
+	return int(l), nil
+}
+
+func (d *binaryDecoder) readBytes() ([]byte, error) {
+	l, err := d.readLen()
+	if err != nil {
+		return nil, err
+	}
+	return d.readN(l)
+}
+
+func (d *binaryDecoder) decode(v reflect.Value) error {
+	switch v.Type() {
+	case timeType, iso8601TimeType:
+		c, err := d.readByte()
+		if err != nil {
+			return err
+		}
+		if c == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		sec, err := d.readVarint()
+		if err != nil {
+			return err
+		}
+		nsec, err := d.readUvarint()
+		if err != nil {
+			return err
+		}
+		if nsec >= uint64(time.Second) {
+			return errors.New("invalid nanoseconds")
+		}
+		v.Set(reflect.ValueOf(time.Unix(sec, int64(nsec)).UTC()).Convert(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		c, err := d.readByte()
+		if err != nil {
+			return err
+		}
+		if c > 1 {
+			return errors.Errorf("invalid bool value: %d", c)
+		}
+		v.SetBool(c == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := d.readVarint()
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(i) {
+			return errors.Errorf("value %d overflows %s", i, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := d.readUvarint()
+		if err != nil {
+			return err
+		}
+		if v.OverflowUint(i) {
+			return errors.Errorf("value %d overflows %s", i, v.Type())
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		b, err := d.readN(8)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	case reflect.String:
+		b, err := d.readBytes()
+		if err != nil {
+			return err
+		}
+		v.SetString(string(b))
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.readN(v.Len())
+			if err != nil {
+				return err
+			}
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		l, err := d.readLen()
+		if err != nil {
+			return err
+		}
+		if l == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.readN(l)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+		s := reflect.MakeSlice(v.Type(), l, l)
+		for i := 0; i < l; i++ {
+			if err := d.decode(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Ptr:
+		c, err := d.readByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case 0:
+			v.Set(reflect.Zero(v.Type()))
+		case 1:
+			p := reflect.New(v.Type().Elem())
+			if err := d.decode(p.Elem()); err != nil {
+				return err
+			}
+			v.Set(p)
+		default:
+			return errors.Errorf("invalid pointer flag: %d", c)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := d.decode(v.Field(i)); err != nil {
+				return errors.Wrapf(err, "field %s", v.Type().Field(i).Name)
+			}
+		}
+	default:
+		return errors.Errorf("unsupported type: %s", v.Type())
+	}
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p JoinReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *JoinReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p JoinAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *JoinAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p RejoinReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *RejoinReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p RejoinAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *RejoinAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p AppSKeyReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *AppSKeyReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p AppSKeyAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *AppSKeyAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p PRStartReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *PRStartReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p PRStartAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *PRStartAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p PRStopReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *PRStopReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p PRStopAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *PRStopAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p HRStartReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *HRStartReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p HRStartAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *HRStartAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p HRStopReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *HRStopReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p HRStopAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *HRStopAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p HomeNSReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *HomeNSReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p HomeNSAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *HomeNSAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p ProfileReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *ProfileReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p ProfileAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *ProfileAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p XmitDataReqPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *XmitDataReqPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p XmitDataAnsPayload) MarshalBinary() ([]byte, error) {
+	return MarshalBinaryPayload(p)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *XmitDataAnsPayload) UnmarshalBinary(b []byte) error {
+	return UnmarshalBinaryPayload(b, p)
+}
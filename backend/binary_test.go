@@ -0,0 +1,214 @@
+package backend
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestBinaryPayload(t *testing.T) {
+	lifetime := 300
+	fCntUp := uint32(10)
+	rssi := -120
+	snr := 5.5
+	freq := 868.1
+	dr := 3
+	rxDelay := 1
+	classA := ClassModeA
+	devEUI := lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+	gwID := lorawan.GatewayEUI{8, 7, 6, 5, 4, 3, 2, 1}
+	ts := ISO8601Time(time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC))
+
+	basePL := BasePayload{
+		ProtocolVersion: ProtocolVersion1_0,
+		SenderID:        "010203",
+		ReceiverID:      "0102030405060708",
+		TransactionID:   1234,
+		SenderToken:     HEXBytes{1, 2, 3},
+		VSExtension: VSExtension{
+			VendorID: HEXBytes{1, 2, 3},
+			Object:   json.RawMessage(`{"foo":"bar"}`),
+		},
+	}
+
+	t.Run("JoinReq", func(t *testing.T) {
+		assert := require.New(t)
+
+		pl := JoinReqPayload{
+			BasePayload: basePL,
+			MACVersion:  "1.0.3",
+			PHYPayload:  HEXBytes{1, 2, 3, 4},
+			DevEUI:      devEUI,
+			DevAddr:     lorawan.DevAddr{1, 2, 3, 4},
+			DLSettings: lorawan.DLSettings{
+				OptNeg:      true,
+				RX2DataRate: 5,
+				RX1DROffset: 1,
+			},
+			RxDelay: 1,
+		}
+		pl.MessageType = JoinReq
+
+		b, err := pl.MarshalBinary()
+		assert.NoError(err)
+
+		// A change of the encoding (e.g. by adding, removing or reordering
+		// fields) requires a BinaryVersion bump.
+		assert.Equal("01074a6f696e52657103312e30063031303230331030313032303330343035303630373038d209074a6f696e5265710301020300030102030d7b22666f6f223a22626172227d05312e302e3304010203040102030405060708010203040105010200", hex.EncodeToString(b))
+
+		mt, err := GetBinaryMessageType(b)
+		assert.NoError(err)
+		assert.Equal(JoinReq, mt)
+
+		var out JoinReqPayload
+		assert.NoError(out.UnmarshalBinary(b))
+		assert.Equal(pl, out)
+
+		// deterministic
+		b2, err := out.MarshalBinary()
+		assert.NoError(err)
+		assert.Equal(b, b2)
+	})
+
+	t.Run("PRStartAns", func(t *testing.T) {
+		assert := require.New(t)
+
+		pl := PRStartAnsPayload{
+			BasePayloadResult: BasePayloadResult{
+				BasePayload: basePL,
+				Result: Result{
+					ResultCode: Success,
+				},
+			},
+			DevEUI:   &devEUI,
+			Lifetime: &lifetime,
+			NwkSKey: &KeyEnvelope{
+				KEKLabel: "kek",
+				AESKey:   HEXBytes{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8},
+			},
+			FCntUp: &fCntUp,
+			DLMetaData: &DLMetaData{
+				DevEUI:    &devEUI,
+				DLFreq1:   &freq,
+				DataRate1: &dr,
+				RXDelay1:  &rxDelay,
+				ClassMode: &classA,
+				GWInfo: []GWInfoElement{
+					{
+						ID:        &gwID,
+						RSSI:      &rssi,
+						SNR:       &snr,
+						ULToken:   HEXBytes{1, 2},
+						DLAllowed: true,
+					},
+				},
+			},
+		}
+		pl.MessageType = PRStartAns
+
+		b, err := pl.MarshalBinary()
+		assert.NoError(err)
+
+		// A change of the encoding (e.g. by adding, removing or reordering
+		// fields) requires a BinaryVersion bump.
+		assert.Equal("010a50525374617274416e7303312e30063031303230331030313032303330343035303630373038d2090a50525374617274416e730301020300030102030d7b22666f6f223a22626172227d0753756363657373000001010203040506070801d8040001036b656b1001020304050607080102030405060708010a000101010203040506070800000001cdcccccccc208b400001020101410106000001010807060504030201000001ef010100000000000016400000020102010000", hex.EncodeToString(b))
+
+		var out PRStartAnsPayload
+		assert.NoError(out.UnmarshalBinary(b))
+		assert.Equal(pl, out)
+	})
+
+	t.Run("HRStartReq", func(t *testing.T) {
+		assert := require.New(t)
+
+		pl := HRStartReqPayload{
+			BasePayload: basePL,
+			MACVersion:  "1.0.3",
+			DevAddr:     lorawan.DevAddr{1, 2, 3, 4},
+			DeviceProfile: DeviceProfile{
+				DeviceProfileID:    "test",
+				RXFreq2:            869525000,
+				FactoryPresetFreqs: []Frequency{868100000, 868300000, 868500000},
+				MaxDutyCycle:       10,
+			},
+			ULMetaData: ULMetaData{
+				DevEUI:   &devEUI,
+				RecvTime: ts,
+			},
+			DeviceProfileTimestamp: ts,
+		}
+		pl.MessageType = HRStartReq
+
+		b, err := pl.MarshalBinary()
+		assert.NoError(err)
+
+		var out HRStartReqPayload
+		assert.NoError(out.UnmarshalBinary(b))
+		assert.Equal(pl, out)
+	})
+
+	t.Run("Encoding", func(t *testing.T) {
+		assert := require.New(t)
+
+		pl := HomeNSAnsPayload{
+			BasePayloadResult: BasePayloadResult{
+				BasePayload: BasePayload{
+					ProtocolVersion: ProtocolVersion1_0,
+					TransactionID:   1,
+					MessageType:     HomeNSAns,
+				},
+				Result: Result{
+					ResultCode: Success,
+				},
+			},
+			HNetID: lorawan.NetID{1, 2, 3},
+		}
+
+		b, err := pl.MarshalBinary()
+		assert.NoError(err)
+		assert.Equal([]byte{
+			0x01,                                              // version
+			0x09, 'H', 'o', 'm', 'e', 'N', 'S', 'A', 'n', 's', // message-type
+			0x03, '1', '.', '0', // ProtocolVersion
+			0x00,                                              // SenderID
+			0x00,                                              // ReceiverID
+			0x01,                                              // TransactionID
+			0x09, 'H', 'o', 'm', 'e', 'N', 'S', 'A', 'n', 's', // MessageType
+			0x00,       // SenderToken
+			0x00,       // ReceiverToken
+			0x00, 0x00, // VSExtension
+			0x07, 'S', 'u', 'c', 'c', 'e', 's', 's', // ResultCode
+			0x00,             // Description
+			0x01, 0x02, 0x03, // HNetID
+		}, b)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		assert := require.New(t)
+
+		pl := HomeNSReqPayload{
+			BasePayload: BasePayload{
+				MessageType: HomeNSReq,
+			},
+		}
+		b, err := pl.MarshalBinary()
+		assert.NoError(err)
+
+		var out HomeNSReqPayload
+		assert.EqualError(out.UnmarshalBinary(nil), "binary payload is empty")
+		assert.EqualError(out.UnmarshalBinary(append([]byte{2}, b[1:]...)), "unsupported binary version: 2")
+		assert.EqualError(out.UnmarshalBinary(b[:len(b)-1]), "field DevEUI: unexpected end of data")
+		assert.EqualError(out.UnmarshalBinary(append(b, 0)), "1 trailing bytes")
+
+		b = append([]byte{0x01, 0x03, 'F', 'o', 'o'}, b[11:]...)
+		assert.EqualError(out.UnmarshalBinary(b), "MessageType of header (Foo) does not match payload (HomeNSReq)")
+
+		_, err = MarshalBinaryPayload(struct{}{})
+		assert.EqualError(err, "unexpected payload type: struct {}")
+	})
+}
@@ -21,7 +21,9 @@ import (
 
 // Errors.
 var (
-	ErrAsyncTimeout = errors.New("async timeout")
+	ErrAsyncTimeout            = errors.New("async timeout")
+	ErrAsyncTransactionPending = errors.New("async transaction pending")
+	ErrAsyncUnknownTransaction = errors.New("async unknown transaction")
 )
 
 // Client defines the backend client interface.
@@ -66,53 +68,119 @@ type ClientConfig struct {
 	// include a prefix, like Bearer, Key or Basic.
 	Authorization string
 
-	// RedisClient holds the optional Redis database client. When set the client
-	// will use the aysnc protocol scheme. In this case the client will wait
+	// TLSConfig holds the optional base TLS configuration, e.g. with
+	// in-memory client certificates for mutual-TLS and a custom CA pool.
+	// The CACert, TLSCert and TLSKey options are applied on top of it.
+	TLSConfig *tls.Config
+
+	// AuthorizationFunc returns the value for the Authorization header of
+	// each request, e.g. to use short-lived bearer tokens. When set, it takes
+	// precedence over Authorization.
+	AuthorizationFunc func(ctx context.Context) (string, error)
+
+	// Headers holds optional headers which are added to each request, e.g.
+	// the API key required by a roaming hub.
+	Headers http.Header
+
+	// UserAgent holds the optional value of the User-Agent header.
+	UserAgent string
+
+	// HeaderFunc is called for each request (and async answer) after the
+	// above headers have been set, e.g. to add a correlation ID. An error
+	// aborts the request.
+	HeaderFunc func(ctx context.Context, pl BasePayload, header http.Header) error
+
+	// Endpoints holds the optional per message-type endpoints, e.g. for
+	// partners that host the JoinReq on a different URL than the
+	// XmitDataReq. Message-types without endpoint use the Server, TLS and
+	// Authorization options above.
+	Endpoints map[MessageType]Endpoint
+
+	// AsyncStore holds the optional AsyncStore. When set the client will use
+	// the async protocol scheme. In this case the client will wait
 	// AsyncTimeout before returning a timeout error.
+	AsyncStore AsyncStore
+
+	// RedisClient holds the optional Redis database client. When set and
+	// AsyncStore is not set, the AsyncStore returned by NewRedisAsyncStore
+	// is used.
+	//
+	// For each pending request, an ownership record is stored in Redis and
+	// the answer is routed through a Redis stream. This makes it possible
+	// to call HandleAnswer on any instance sharing the same Redis database,
+	// e.g. in a multi-instance deployment behind a load-balancer.
 	RedisClient redis.UniversalClient
 
-	// AsyncTimeout defines the async timeout. This must be set when RedisClient
-	// is set.
+	// AsyncTimeout defines the async timeout. This must be set when
+	// AsyncStore or RedisClient is set.
 	AsyncTimeout time.Duration
 
 	// Logger holds a Logger instance.
 	Logger *log.Logger
 }
 
+// Endpoint holds the configuration of a message-type specific endpoint.
+type Endpoint struct {
+	// Server holds the endpoint URL.
+	Server string
+
+	// CACert, TLSCert, TLSKey and TLSConfig hold the optional TLS
+	// configuration of the endpoint. When all are empty, the TLS
+	// configuration of the ClientConfig is used.
+	CACert    string
+	TLSCert   string
+	TLSKey    string
+	TLSConfig *tls.Config
+
+	// Authorization and AuthorizationFunc provide the value for the
+	// Authorization header. When both are empty, the Authorization and
+	// AuthorizationFunc of the ClientConfig are used.
+	Authorization     string
+	AuthorizationFunc func(ctx context.Context) (string, error)
+}
+
 // NewClient creates a new Client.
 func NewClient(config ClientConfig) (Client, error) {
-	httpClient := http.DefaultClient
-
-	if config.CACert != "" || config.TLSCert != "" || config.TLSKey != "" {
-		tlsConfig := &tls.Config{}
-
-		if config.CACert != "" {
-			rawCACert, err := ioutil.ReadFile(config.CACert)
-			if err != nil {
-				return nil, errors.Wrap(err, "read ca cert error")
-			}
+	httpClient, err := newHTTPClient(config.CACert, config.TLSCert, config.TLSKey, config.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
 
-			caCertPool := x509.NewCertPool()
-			if !caCertPool.AppendCertsFromPEM(rawCACert) {
-				return nil, errors.New("append ca cert to pool error")
-			}
+	defaultEndpoint := endpoint{
+		server:            config.Server,
+		authorization:     config.Authorization,
+		authorizationFunc: config.AuthorizationFunc,
+		httpClient:        httpClient,
+	}
 
-			tlsConfig.RootCAs = caCertPool
+	endpoints := make(map[MessageType]endpoint, len(config.Endpoints))
+	for mt, ep := range config.Endpoints {
+		if ep.Server == "" {
+			return nil, fmt.Errorf("server of %s endpoint must be set", mt)
 		}
 
-		if config.TLSCert != "" || config.TLSKey != "" {
-			cert, err := tls.LoadX509KeyPair(config.TLSCert, config.TLSKey)
+		e := endpoint{
+			server:            ep.Server,
+			authorization:     ep.Authorization,
+			authorizationFunc: ep.AuthorizationFunc,
+			httpClient:        httpClient,
+		}
+		if e.authorization == "" && e.authorizationFunc == nil {
+			e.authorization = config.Authorization
+			e.authorizationFunc = config.AuthorizationFunc
+		}
+		if ep.CACert != "" || ep.TLSCert != "" || ep.TLSKey != "" || ep.TLSConfig != nil {
+			e.httpClient, err = newHTTPClient(ep.CACert, ep.TLSCert, ep.TLSKey, ep.TLSConfig)
 			if err != nil {
-				return nil, errors.Wrap(err, "load x509 keypair error")
+				return nil, errors.Wrapf(err, "%s endpoint error", mt)
 			}
-			tlsConfig.Certificates = []tls.Certificate{cert}
 		}
 
-		httpClient = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: tlsConfig,
-			},
-		}
+		endpoints[mt] = e
+	}
+
+	if config.AsyncStore == nil && config.RedisClient != nil {
+		config.AsyncStore = NewRedisAsyncStore(config.RedisClient)
 	}
 
 	if config.Logger == nil {
@@ -129,32 +197,103 @@ func NewClient(config ClientConfig) (Client, error) {
 		"authorization": config.Authorization,
 		"sender_id":     config.SenderID,
 		"receiver_id":   config.ReceiverID,
+		"endpoints":     len(config.Endpoints),
 	}).Debug("lorawan/backend: new backend client")
 
 	return &client{
 		log:             config.Logger,
-		server:          config.Server,
-		httpClient:      httpClient,
-		authorization:   config.Authorization,
+		defaultEndpoint: defaultEndpoint,
+		endpoints:       endpoints,
 		senderID:        config.SenderID,
 		receiverID:      config.ReceiverID,
 		protocolVersion: ProtocolVersion1_0,
-		redisClient:     config.RedisClient,
+		asyncStore:      config.AsyncStore,
 		asyncTimeout:    config.AsyncTimeout,
+		headers:         config.Headers.Clone(),
+		userAgent:       config.UserAgent,
+		headerFunc:      config.HeaderFunc,
 	}, nil
 
 }
 
+// newHTTPClient returns the HTTP client for the given TLS configuration. It
+// returns the http.DefaultClient when no TLS configuration is given.
+func newHTTPClient(caCert, tlsCert, tlsKey string, baseTLSConfig *tls.Config) (*http.Client, error) {
+	if caCert == "" && tlsCert == "" && tlsKey == "" && baseTLSConfig == nil {
+		return http.DefaultClient, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if baseTLSConfig != nil {
+		tlsConfig = baseTLSConfig.Clone()
+	}
+
+	if caCert != "" {
+		rawCACert, err := ioutil.ReadFile(caCert)
+		if err != nil {
+			return nil, errors.Wrap(err, "read ca cert error")
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(rawCACert) {
+			return nil, errors.New("append ca cert to pool error")
+		}
+
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if tlsCert != "" || tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCert, tlsKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "load x509 keypair error")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// endpoint holds the resolved endpoint configuration.
+type endpoint struct {
+	server            string
+	authorization     string
+	authorizationFunc func(ctx context.Context) (string, error)
+	httpClient        *http.Client
+}
+
+// setAuthorization sets the Authorization header of the given request.
+func (e endpoint) setAuthorization(ctx context.Context, req *http.Request) error {
+	authorization := e.authorization
+	if e.authorizationFunc != nil {
+		var err error
+		authorization, err = e.authorizationFunc(ctx)
+		if err != nil {
+			return errors.Wrap(err, "get authorization error")
+		}
+	}
+
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return nil
+}
+
 type client struct {
 	log             *log.Logger
-	server          string
-	httpClient      *http.Client
-	authorization   string
+	defaultEndpoint endpoint
+	endpoints       map[MessageType]endpoint
 	protocolVersion string
 	senderID        string
 	receiverID      string
-	redisClient     redis.UniversalClient
+	asyncStore      AsyncStore
 	asyncTimeout    time.Duration
+	headers         http.Header
+	userAgent       string
+	headerFunc      func(ctx context.Context, pl BasePayload, header http.Header) error
 }
 
 func (c *client) GetSenderID() string {
@@ -166,7 +305,7 @@ func (c *client) GetReceiverID() string {
 }
 
 func (c *client) IsAsync() bool {
-	return c.redisClient != nil
+	return c.asyncStore != nil
 }
 
 func (c *client) JoinReq(ctx context.Context, pl JoinReqPayload) (JoinAnsPayload, error) {
@@ -332,39 +471,34 @@ func (c *client) request(ctx context.Context, pl Request, ans Answer) error {
 	responseChan := make(chan []byte, 1)
 	errorChan := make(chan error, 1)
 
-	// Setup async subscriber to receive response. Please note that we have to do
-	// this before making the request, as the response might come in, before the
-	// request has returned.
+	// Claim the transaction and setup the async reader to receive the
+	// response. As the response is routed through the AsyncStore, it will
+	// not get lost when it comes in before the request has returned.
 	if c.IsAsync() {
-		key := c.getAsyncKey(pl.GetBasePayload().TransactionID)
-		sub := c.redisClient.Subscribe(ctx, key)
-		if _, err := sub.Receive(ctx); err != nil {
-			return errors.Wrap(err, "async response subscription error")
+		id := pl.GetBasePayload().TransactionID
+		if err := c.claimAsyncTransaction(ctx, id); err != nil {
+			return err
 		}
-		ch := sub.Channel()
+		defer c.releaseAsyncTransaction(id)
 
 		go func() {
-			defer sub.Close()
-
-			select {
-			case msg := <-ch:
-				responseChan <- []byte(msg.Payload)
-			case <-time.After(c.asyncTimeout):
-				errorChan <- ErrAsyncTimeout
+			bb, err := c.readAsyncAnswer(ctx, id)
+			if err != nil {
+				errorChan <- err
+				return
 			}
+			responseChan <- bb
 		}()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.server, bytes.NewReader(b))
+	ep := c.getEndpoint(pl.GetBasePayload().MessageType)
+
+	req, err := c.newRequest(ctx, ep, pl.GetBasePayload(), b)
 	if err != nil {
-		return errors.Wrap(err, "new request error")
-	}
-	req.Header.Add("Content-Type", "application/json")
-	if c.authorization != "" {
-		req.Header.Add("Authorization", c.authorization)
+		return err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := ep.httpClient.Do(req)
 	if err != nil {
 		return errors.Wrap(err, "http post error")
 	}
@@ -384,6 +518,8 @@ func (c *client) request(ctx context.Context, pl Request, ans Answer) error {
 	}
 
 	select {
+	case <-ctx.Done():
+		return ctx.Err()
 	case err := <-errorChan:
 		return err
 	case bb := <-responseChan:
@@ -392,6 +528,10 @@ func (c *client) request(ctx context.Context, pl Request, ans Answer) error {
 		}
 	}
 
+	if err := validateAnswerIDs(pl.GetBasePayload(), ans.GetBasePayload().BasePayload); err != nil {
+		return err
+	}
+
 	c.log.WithFields(log.Fields{
 		"protocol_version": pl.GetBasePayload().ProtocolVersion,
 		"sender_id":        pl.GetBasePayload().SenderID,
@@ -404,6 +544,22 @@ func (c *client) request(ctx context.Context, pl Request, ans Answer) error {
 	return nil
 }
 
+// validateAnswerIDs validates that the SenderID and ReceiverID of the answer
+// match the ReceiverID and SenderID of the request. IDs which are not set in
+// the answer are not validated.
+func validateAnswerIDs(req, ans BasePayload) error {
+	if ans.SenderID != "" && !EqualIDs(ans.SenderID, req.ReceiverID) {
+		return fmt.Errorf("answer SenderID %s does not match request ReceiverID %s", ans.SenderID, req.ReceiverID)
+	}
+	if ans.ReceiverID != "" && !EqualIDs(ans.ReceiverID, req.SenderID) {
+		return fmt.Errorf("answer ReceiverID %s does not match request SenderID %s", ans.ReceiverID, req.SenderID)
+	}
+	return nil
+}
+
+// HandleAnswer routes the async answer to the instance waiting for it.
+// ErrAsyncUnknownTransaction is returned when no request is pending for
+// the TransactionID of the answer (e.g. the request has already timed out).
 func (c *client) HandleAnswer(ctx context.Context, pl Answer) error {
 	if !c.IsAsync() {
 		return errors.New("async is not configured")
@@ -414,12 +570,7 @@ func (c *client) HandleAnswer(ctx context.Context, pl Answer) error {
 		return errors.Wrap(err, "marshal answer error")
 	}
 
-	err = c.redisClient.Publish(ctx, c.getAsyncKey(pl.GetBasePayload().TransactionID), b).Err()
-	if err != nil {
-		return errors.Wrap(err, "publish answer error")
-	}
-
-	return nil
+	return c.asyncStore.Publish(ctx, pl.GetBasePayload().TransactionID, b, c.asyncTimeout)
 }
 
 func (c *client) SendAnswer(ctx context.Context, pl Answer) error {
@@ -428,16 +579,14 @@ func (c *client) SendAnswer(ctx context.Context, pl Answer) error {
 		return errors.Wrap(err, "json marshal error")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.server, bytes.NewReader(b))
+	ep := c.getEndpoint(pl.GetBasePayload().MessageType)
+
+	req, err := c.newRequest(ctx, ep, pl.GetBasePayload().BasePayload, b)
 	if err != nil {
-		return errors.Wrap(err, "new request error")
-	}
-	req.Header.Add("Content-Type", "application/json")
-	if c.authorization != "" {
-		req.Header.Add("Authorization", c.authorization)
+		return err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := ep.httpClient.Do(req)
 	if err != nil {
 		return errors.Wrap(err, "http post error")
 	}
@@ -454,12 +603,66 @@ func (c *client) SendAnswer(ctx context.Context, pl Answer) error {
 	return nil
 }
 
+// newRequest returns the POST request for the given endpoint and body, with
+// the configured headers set.
+func (c *client) newRequest(ctx context.Context, ep endpoint, pl BasePayload, b []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.server, bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.Wrap(err, "new request error")
+	}
+
+	for k, v := range c.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if err := ep.setAuthorization(ctx, req); err != nil {
+		return nil, err
+	}
+	if c.headerFunc != nil {
+		if err := c.headerFunc(ctx, pl, req.Header); err != nil {
+			return nil, errors.Wrap(err, "header func error")
+		}
+	}
+
+	return req, nil
+}
+
+// getEndpoint returns the endpoint for the given message-type, falling back
+// to the default endpoint.
+func (c *client) getEndpoint(mt MessageType) endpoint {
+	if ep, ok := c.endpoints[mt]; ok {
+		return ep
+	}
+	return c.defaultEndpoint
+}
+
 func (c *client) GetRandomTransactionID() uint32 {
 	b := make([]byte, 4)
 	rand.Read(b)
 	return binary.LittleEndian.Uint32(b)
 }
 
-func (c *client) getAsyncKey(id uint32) string {
-	return fmt.Sprintf("lora:backend:async:%d", id)
+// claimAsyncTransaction claims the given transaction. It fails with
+// ErrAsyncTransactionPending when there is already a pending request using
+// the same TransactionID.
+func (c *client) claimAsyncTransaction(ctx context.Context, id uint32) error {
+	return c.asyncStore.Claim(ctx, id, c.asyncTimeout)
+}
+
+// releaseAsyncTransaction releases the given transaction. The request context
+// is not used, as the transaction must also be released when the request has
+// been cancelled.
+func (c *client) releaseAsyncTransaction(id uint32) {
+	if err := c.asyncStore.Release(context.Background(), id); err != nil {
+		c.log.WithError(err).WithField("transaction_id", id).Error("lorawan/backend: release async transaction error")
+	}
+}
+
+// readAsyncAnswer blocks until the answer for the given transaction has been
+// received, the async timeout has expired or the context has been cancelled.
+func (c *client) readAsyncAnswer(ctx context.Context, id uint32) ([]byte, error) {
+	return c.asyncStore.Read(ctx, id, c.asyncTimeout)
 }
@@ -2,8 +2,16 @@ package backend
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -180,7 +188,7 @@ func (ts *SyncClientTestSuite) TestPRStartReq() {
 			GWCnt:    &gwCount,
 			GWInfo: []GWInfoElement{
 				{
-					ID:       []byte{1, 2, 3, 4},
+					ID:       &lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8},
 					RFRegion: string(band.EU868),
 					RSSI:     &rssi,
 					SNR:      &snr,
@@ -264,6 +272,75 @@ func (ts *SyncClientTestSuite) TestPRStopReq() {
 	assert.Equal(string(reqB), ts.apiRequest)
 }
 
+func (ts *SyncClientTestSuite) TestAnswerIDs() {
+	req := PRStopReqPayload{
+		BasePayload: BasePayload{
+			ProtocolVersion: ProtocolVersion1_0,
+			SenderID:        "010101",
+			ReceiverID:      "020202",
+			TransactionID:   123,
+			MessageType:     PRStopReq,
+		},
+		DevEUI: lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+	}
+
+	tests := []struct {
+		name          string
+		senderID      string
+		receiverID    string
+		expectedError string
+	}{
+		{
+			name:       "different case and padding",
+			senderID:   "20202",
+			receiverID: "0x010101",
+		},
+		{
+			name:          "unexpected SenderID",
+			senderID:      "030303",
+			receiverID:    "010101",
+			expectedError: "answer SenderID 030303 does not match request ReceiverID 020202",
+		},
+		{
+			name:          "unexpected ReceiverID",
+			senderID:      "020202",
+			receiverID:    "030303",
+			expectedError: "answer ReceiverID 030303 does not match request SenderID 010101",
+		},
+	}
+
+	for _, tst := range tests {
+		ts.T().Run(tst.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			resp := PRStopAnsPayload{
+				BasePayloadResult: BasePayloadResult{
+					BasePayload: BasePayload{
+						ProtocolVersion: ProtocolVersion1_0,
+						SenderID:        tst.senderID,
+						ReceiverID:      tst.receiverID,
+						TransactionID:   123,
+						MessageType:     PRStopAns,
+					},
+					Result: Result{
+						ResultCode: Success,
+					},
+				},
+			}
+			respB, err := json.Marshal(resp)
+			assert.NoError(err)
+			ts.apiResponse = string(respB)
+
+			_, err = ts.client.PRStopReq(context.Background(), req)
+			if tst.expectedError != "" {
+				assert.EqualError(err, tst.expectedError)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
 func (ts *SyncClientTestSuite) TestXmitDataReq() {
 	assert := require.New(ts.T())
 
@@ -294,7 +371,7 @@ func (ts *SyncClientTestSuite) TestXmitDataReq() {
 			GWCnt:    &gwCount,
 			GWInfo: []GWInfoElement{
 				{
-					ID:       []byte{1, 2, 3, 4},
+					ID:       &lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8},
 					RFRegion: string(band.EU868),
 					RSSI:     &rssi,
 					SNR:      &snr,
@@ -446,10 +523,233 @@ func TestSyncClient(t *testing.T) {
 	suite.Run(t, new(SyncClientTestSuite))
 }
 
+func TestClientEndpoints(t *testing.T) {
+	assert := require.New(t)
+
+	// newServer returns a server which responds with a successful answer of
+	// the given message-type and which records the Authorization header.
+	newServer := func(mt MessageType, authorization *string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*authorization = r.Header.Get("Authorization")
+			json.NewEncoder(w).Encode(BasePayloadResult{
+				BasePayload: BasePayload{MessageType: mt},
+				Result:      Result{ResultCode: Success},
+			})
+		}))
+	}
+
+	var defaultAuth, joinAuth string
+	defaultServer := newServer(XmitDataAns, &defaultAuth)
+	defer defaultServer.Close()
+	joinServer := newServer(JoinAns, &joinAuth)
+	defer joinServer.Close()
+
+	client, err := NewClient(ClientConfig{
+		SenderID:      "010101",
+		ReceiverID:    "020202",
+		Server:        defaultServer.URL,
+		Authorization: "Key secret",
+		Endpoints: map[MessageType]Endpoint{
+			JoinReq: {
+				Server:        joinServer.URL,
+				Authorization: "Bearer join",
+			},
+		},
+	})
+	assert.NoError(err)
+
+	t.Run("JoinReq uses JoinReq endpoint", func(t *testing.T) {
+		assert := require.New(t)
+
+		ans, err := client.JoinReq(context.Background(), JoinReqPayload{})
+		assert.NoError(err)
+		assert.Equal(JoinAns, ans.MessageType)
+		assert.Equal("Bearer join", joinAuth)
+	})
+
+	t.Run("XmitDataReq uses default endpoint", func(t *testing.T) {
+		assert := require.New(t)
+
+		ans, err := client.XmitDataReq(context.Background(), XmitDataReqPayload{})
+		assert.NoError(err)
+		assert.Equal(XmitDataAns, ans.MessageType)
+		assert.Equal("Key secret", defaultAuth)
+	})
+
+	t.Run("Endpoint without server", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := NewClient(ClientConfig{
+			Endpoints: map[MessageType]Endpoint{
+				JoinReq: {Authorization: "Bearer join"},
+			},
+		})
+		assert.EqualError(err, "server of JoinReq endpoint must be set")
+	})
+}
+
+func TestClientAuthentication(t *testing.T) {
+	assert := require.New(t)
+
+	// self-signed client certificate
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "010101"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	assert.NoError(err)
+	clientCert, err := x509.ParseCertificate(der)
+	assert.NoError(err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	var authorization, commonName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		commonName = r.TLS.PeerCertificates[0].Subject.CommonName
+		json.NewEncoder(w).Encode(BasePayloadResult{
+			BasePayload: BasePayload{MessageType: JoinAns},
+			Result:      Result{ResultCode: Success},
+		})
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	var tokens int
+	client, err := NewClient(ClientConfig{
+		SenderID:   "010101",
+		ReceiverID: "020202",
+		Server:     server.URL,
+		TLSConfig: &tls.Config{
+			RootCAs: rootCAs,
+			Certificates: []tls.Certificate{
+				{Certificate: [][]byte{der}, PrivateKey: key},
+			},
+		},
+		Authorization: "Key secret",
+		AuthorizationFunc: func(ctx context.Context) (string, error) {
+			tokens++
+			return fmt.Sprintf("Bearer token-%d", tokens), nil
+		},
+	})
+	assert.NoError(err)
+
+	t.Run("Mutual-TLS and token provider", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := client.JoinReq(context.Background(), JoinReqPayload{})
+		assert.NoError(err)
+		assert.Equal("010101", commonName)
+		assert.Equal("Bearer token-1", authorization)
+
+		_, err = client.JoinReq(context.Background(), JoinReqPayload{})
+		assert.NoError(err)
+		assert.Equal("Bearer token-2", authorization)
+	})
+
+	t.Run("Token provider error", func(t *testing.T) {
+		assert := require.New(t)
+
+		client, err := NewClient(ClientConfig{
+			Server: server.URL,
+			AuthorizationFunc: func(ctx context.Context) (string, error) {
+				return "", errors.New("token expired")
+			},
+		})
+		assert.NoError(err)
+
+		_, err = client.JoinReq(context.Background(), JoinReqPayload{})
+		assert.EqualError(err, "get authorization error: token expired")
+	})
+
+	t.Run("Without client certificate", func(t *testing.T) {
+		assert := require.New(t)
+
+		client, err := NewClient(ClientConfig{
+			Server:    server.URL,
+			TLSConfig: &tls.Config{RootCAs: rootCAs},
+		})
+		assert.NoError(err)
+
+		_, err = client.JoinReq(context.Background(), JoinReqPayload{})
+		assert.Error(err)
+	})
+}
+
+func TestClientHeaders(t *testing.T) {
+	assert := require.New(t)
+
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		json.NewEncoder(w).Encode(BasePayloadResult{
+			BasePayload: BasePayload{MessageType: JoinAns},
+			Result:      Result{ResultCode: Success},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{
+		SenderID:      "010101",
+		ReceiverID:    "020202",
+		Server:        server.URL,
+		Authorization: "Key secret",
+		Headers: http.Header{
+			"X-Api-Key": []string{"api-key"},
+		},
+		UserAgent: "test-client/1.0",
+		HeaderFunc: func(ctx context.Context, pl BasePayload, header http.Header) error {
+			if pl.TransactionID == 666 {
+				return errors.New("blocked transaction")
+			}
+			header.Set("X-Correlation-Id", fmt.Sprintf("%s-%d", pl.MessageType, pl.TransactionID))
+			return nil
+		},
+	})
+	assert.NoError(err)
+
+	t.Run("Headers are set", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := client.JoinReq(context.Background(), JoinReqPayload{
+			BasePayload: BasePayload{MessageType: JoinReq, TransactionID: 1234},
+		})
+		assert.NoError(err)
+		assert.Equal("api-key", header.Get("X-Api-Key"))
+		assert.Equal("test-client/1.0", header.Get("User-Agent"))
+		assert.Equal("Key secret", header.Get("Authorization"))
+		assert.Equal("application/json", header.Get("Content-Type"))
+		assert.Equal("JoinReq-1234", header.Get("X-Correlation-Id"))
+	})
+
+	t.Run("Header func error", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := client.JoinReq(context.Background(), JoinReqPayload{
+			BasePayload: BasePayload{TransactionID: 666},
+		})
+		assert.EqualError(err, "header func error: blocked transaction")
+	})
+}
+
 type AsyncClientTestSuite struct {
 	suite.Suite
 
 	client      Client
+	asyncStore  AsyncStore
 	redisClient *redis.Client
 
 	server      *httptest.Server
@@ -461,10 +761,13 @@ func (ts *AsyncClientTestSuite) SetupSuite() {
 	assert := require.New(ts.T())
 	var err error
 
-	ts.redisClient = redis.NewClient(&redis.Options{
-		Addr: "redis:6379",
-	})
-	assert.NoError(ts.redisClient.Ping(context.Background()).Err())
+	// Without AsyncStore, the suite is run using Redis.
+	if ts.asyncStore == nil {
+		ts.redisClient = redis.NewClient(&redis.Options{
+			Addr: "redis:6379",
+		})
+		assert.NoError(ts.redisClient.Ping(context.Background()).Err())
+	}
 
 	ts.server = httptest.NewServer(http.HandlerFunc(ts.apiHandler))
 	ts.client, err = NewClient(ClientConfig{
@@ -472,6 +775,7 @@ func (ts *AsyncClientTestSuite) SetupSuite() {
 		ReceiverID:    "020202",
 		Server:        ts.server.URL,
 		Authorization: "Key secret",
+		AsyncStore:    ts.asyncStore,
 		RedisClient:   ts.redisClient,
 		AsyncTimeout:  time.Millisecond * 100,
 	})
@@ -479,7 +783,9 @@ func (ts *AsyncClientTestSuite) SetupSuite() {
 }
 
 func (ts *AsyncClientTestSuite) TearDownSuite() {
-	ts.redisClient.Close()
+	if ts.redisClient != nil {
+		ts.redisClient.Close()
+	}
 }
 
 func (ts *AsyncClientTestSuite) TestRequestTimeout() {
@@ -529,13 +835,106 @@ func (ts *AsyncClientTestSuite) TestWrongTransactionID() {
 
 	go func() {
 		time.Sleep(time.Millisecond * 10)
-		assert.NoError(ts.client.HandleAnswer(context.Background(), ans))
+		assert.Equal(ErrAsyncUnknownTransaction, errors.Cause(ts.client.HandleAnswer(context.Background(), ans)))
+	}()
+
+	_, err := ts.client.PRStartReq(context.Background(), req)
+	assert.Equal(ErrAsyncTimeout, errors.Cause(err))
+}
+
+func (ts *AsyncClientTestSuite) TestRequestCancel() {
+	assert := require.New(ts.T())
+
+	req := PRStartReqPayload{
+		BasePayload: BasePayload{
+			ProtocolVersion: ProtocolVersion1_0,
+			SenderID:        "010101",
+			ReceiverID:      "020202",
+			TransactionID:   123,
+			MessageType:     PRStartReq,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	_, err := ts.client.PRStartReq(ctx, req)
+	assert.Equal(context.DeadlineExceeded, errors.Cause(err))
+}
+
+func (ts *AsyncClientTestSuite) TestTransactionPending() {
+	assert := require.New(ts.T())
+
+	req := PRStartReqPayload{
+		BasePayload: BasePayload{
+			ProtocolVersion: ProtocolVersion1_0,
+			SenderID:        "010101",
+			ReceiverID:      "020202",
+			TransactionID:   123,
+			MessageType:     PRStartReq,
+		},
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		_, err := ts.client.PRStartReq(context.Background(), req)
+		assert.Equal(ErrAsyncTransactionPending, errors.Cause(err))
 	}()
 
 	_, err := ts.client.PRStartReq(context.Background(), req)
 	assert.Equal(ErrAsyncTimeout, errors.Cause(err))
 }
 
+func (ts *AsyncClientTestSuite) TestHandleAnswerOtherInstance() {
+	assert := require.New(ts.T())
+
+	// The answer might be received by a different instance than the instance
+	// which made the request.
+	other, err := NewClient(ClientConfig{
+		SenderID:     "010101",
+		ReceiverID:   "020202",
+		Server:       ts.server.URL,
+		AsyncStore:   ts.asyncStore,
+		RedisClient:  ts.redisClient,
+		AsyncTimeout: time.Millisecond * 100,
+	})
+	assert.NoError(err)
+
+	req := PRStartReqPayload{
+		BasePayload: BasePayload{
+			ProtocolVersion: ProtocolVersion1_0,
+			SenderID:        "010101",
+			ReceiverID:      "020202",
+			TransactionID:   123,
+			MessageType:     PRStartReq,
+		},
+	}
+
+	ans := PRStartAnsPayload{
+		BasePayloadResult: BasePayloadResult{
+			BasePayload: BasePayload{
+				ProtocolVersion: ProtocolVersion1_0,
+				ReceiverID:      "010101",
+				SenderID:        "020202",
+				TransactionID:   123,
+				MessageType:     PRStartAns,
+			},
+			Result: Result{
+				ResultCode: Success,
+			},
+		},
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		assert.NoError(other.HandleAnswer(context.Background(), ans))
+	}()
+
+	resp, err := ts.client.PRStartReq(context.Background(), req)
+	assert.NoError(err)
+	assert.Equal(ans, resp)
+}
+
 func (ts *AsyncClientTestSuite) TestJoinReq() {
 	assert := require.New(ts.T())
 
@@ -845,3 +1244,7 @@ func (ts *AsyncClientTestSuite) apiHandler(w http.ResponseWriter, r *http.Reques
 func TestAsyncClient(t *testing.T) {
 	suite.Run(t, new(AsyncClientTestSuite))
 }
+
+func TestAsyncClientMemoryStore(t *testing.T) {
+	suite.Run(t, &AsyncClientTestSuite{asyncStore: NewMemoryAsyncStore()})
+}
@@ -0,0 +1,357 @@
+package backend
+
+// This file contains the Clone and Redacted helpers of the backend payloads.
+// Clone returns a deep copy, so that the copy can be modified without
+// affecting the original payload. Redacted returns a deep copy in which the
+// key material (the AESKey of each KeyEnvelope) and the uplink tokens are
+// removed, so that the payload can safely be logged.
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func cloneBytes(b HEXBytes) HEXBytes {
+	if b == nil {
+		return nil
+	}
+	return append(HEXBytes{}, b...)
+}
+
+// Clone returns a deep copy of the KeyEnvelope.
+func (k *KeyEnvelope) Clone() *KeyEnvelope {
+	if k == nil {
+		return nil
+	}
+	return &KeyEnvelope{
+		KEKLabel: k.KEKLabel,
+		AESKey:   cloneBytes(k.AESKey),
+	}
+}
+
+// Redacted returns a copy of the KeyEnvelope without the (wrapped) key. The
+// KEKLabel is retained.
+func (k *KeyEnvelope) Redacted() *KeyEnvelope {
+	if k == nil {
+		return nil
+	}
+	return &KeyEnvelope{
+		KEKLabel: k.KEKLabel,
+	}
+}
+
+// Clone returns a deep copy of the VSExtension.
+func (v VSExtension) Clone() VSExtension {
+	out := VSExtension{
+		VendorID: cloneBytes(v.VendorID),
+	}
+	if v.Object != nil {
+		out.Object = append(out.Object, v.Object...)
+	}
+	return out
+}
+
+// Clone returns a deep copy of the BasePayload.
+func (p BasePayload) Clone() BasePayload {
+	p.SenderToken = cloneBytes(p.SenderToken)
+	p.ReceiverToken = cloneBytes(p.ReceiverToken)
+	p.VSExtension = p.VSExtension.Clone()
+	return p
+}
+
+// Clone returns a deep copy of the BasePayloadResult.
+func (p BasePayloadResult) Clone() BasePayloadResult {
+	p.BasePayload = p.BasePayload.Clone()
+	return p
+}
+
+// Clone returns a deep copy of the GWInfoElement.
+func (e GWInfoElement) Clone() GWInfoElement {
+	e.ID = clonePtr(e.ID)
+	e.FineRecvTime = clonePtr(e.FineRecvTime)
+	e.RSSI = clonePtr(e.RSSI)
+	e.SNR = clonePtr(e.SNR)
+	e.Lat = clonePtr(e.Lat)
+	e.Lon = clonePtr(e.Lon)
+	e.ULToken = cloneBytes(e.ULToken)
+	return e
+}
+
+// Redacted returns a copy of the GWInfoElement without the ULToken.
+func (e GWInfoElement) Redacted() GWInfoElement {
+	e = e.Clone()
+	e.ULToken = nil
+	return e
+}
+
+func cloneGWInfo(gwInfo []GWInfoElement, redact bool) []GWInfoElement {
+	if gwInfo == nil {
+		return nil
+	}
+	out := make([]GWInfoElement, len(gwInfo))
+	for i := range gwInfo {
+		if redact {
+			out[i] = gwInfo[i].Redacted()
+		} else {
+			out[i] = gwInfo[i].Clone()
+		}
+	}
+	return out
+}
+
+// Clone returns a deep copy of the ULMetaData.
+func (m ULMetaData) Clone() ULMetaData {
+	m.DevEUI = clonePtr(m.DevEUI)
+	m.DevAddr = clonePtr(m.DevAddr)
+	m.FPort = clonePtr(m.FPort)
+	m.FCntDown = clonePtr(m.FCntDown)
+	m.FCntUp = clonePtr(m.FCntUp)
+	m.DataRate = clonePtr(m.DataRate)
+	m.ULFreq = clonePtr(m.ULFreq)
+	m.Margin = clonePtr(m.Margin)
+	m.Battery = clonePtr(m.Battery)
+	m.FNSULToken = cloneBytes(m.FNSULToken)
+	m.GWCnt = clonePtr(m.GWCnt)
+	m.GWInfo = cloneGWInfo(m.GWInfo, false)
+	return m
+}
+
+// Redacted returns a copy of the ULMetaData without the FNSULToken and
+// gateway ULTokens.
+func (m ULMetaData) Redacted() ULMetaData {
+	m = m.Clone()
+	m.FNSULToken = nil
+	m.GWInfo = cloneGWInfo(m.GWInfo, true)
+	return m
+}
+
+// Clone returns a deep copy of the DLMetaData.
+func (m *DLMetaData) Clone() *DLMetaData {
+	if m == nil {
+		return nil
+	}
+	out := *m
+	out.DevEUI = clonePtr(m.DevEUI)
+	out.FPort = clonePtr(m.FPort)
+	out.FCntDown = clonePtr(m.FCntDown)
+	out.DLFreq1 = clonePtr(m.DLFreq1)
+	out.DLFreq2 = clonePtr(m.DLFreq2)
+	out.RXDelay1 = clonePtr(m.RXDelay1)
+	out.ClassMode = clonePtr(m.ClassMode)
+	out.DataRate1 = clonePtr(m.DataRate1)
+	out.DataRate2 = clonePtr(m.DataRate2)
+	out.FNSULToken = cloneBytes(m.FNSULToken)
+	out.GWInfo = cloneGWInfo(m.GWInfo, false)
+	return &out
+}
+
+// Redacted returns a copy of the DLMetaData without the FNSULToken and
+// gateway ULTokens.
+func (m *DLMetaData) Redacted() *DLMetaData {
+	out := m.Clone()
+	if out == nil {
+		return nil
+	}
+	out.FNSULToken = nil
+	out.GWInfo = cloneGWInfo(out.GWInfo, true)
+	return out
+}
+
+// Clone returns a deep copy of the ServiceProfile.
+func (p *ServiceProfile) Clone() *ServiceProfile {
+	if p == nil {
+		return nil
+	}
+	out := *p
+	out.ChannelMask = cloneBytes(p.ChannelMask)
+	return &out
+}
+
+// Clone returns a deep copy of the DeviceProfile.
+func (p *DeviceProfile) Clone() *DeviceProfile {
+	if p == nil {
+		return nil
+	}
+	out := *p
+	if p.FactoryPresetFreqs != nil {
+		out.FactoryPresetFreqs = append([]Frequency{}, p.FactoryPresetFreqs...)
+	}
+	return &out
+}
+
+// Clone returns a deep copy of the JoinAnsPayload.
+func (p JoinAnsPayload) Clone() JoinAnsPayload {
+	p.BasePayloadResult = p.BasePayloadResult.Clone()
+	p.PHYPayload = cloneBytes(p.PHYPayload)
+	p.Lifetime = clonePtr(p.Lifetime)
+	p.SNwkSIntKey = p.SNwkSIntKey.Clone()
+	p.FNwkSIntKey = p.FNwkSIntKey.Clone()
+	p.NwkSEncKey = p.NwkSEncKey.Clone()
+	p.NwkSKey = p.NwkSKey.Clone()
+	p.AppSKey = p.AppSKey.Clone()
+	p.SessionKeyID = cloneBytes(p.SessionKeyID)
+	return p
+}
+
+// Redacted returns a copy of the JoinAnsPayload without key material.
+func (p JoinAnsPayload) Redacted() JoinAnsPayload {
+	p = p.Clone()
+	p.SNwkSIntKey = p.SNwkSIntKey.Redacted()
+	p.FNwkSIntKey = p.FNwkSIntKey.Redacted()
+	p.NwkSEncKey = p.NwkSEncKey.Redacted()
+	p.NwkSKey = p.NwkSKey.Redacted()
+	p.AppSKey = p.AppSKey.Redacted()
+	return p
+}
+
+// Clone returns a deep copy of the RejoinAnsPayload.
+func (p RejoinAnsPayload) Clone() RejoinAnsPayload {
+	p.BasePayloadResult = p.BasePayloadResult.Clone()
+	p.PHYPayload = cloneBytes(p.PHYPayload)
+	p.Lifetime = clonePtr(p.Lifetime)
+	p.SNwkSIntKey = p.SNwkSIntKey.Clone()
+	p.FNwkSIntKey = p.FNwkSIntKey.Clone()
+	p.NwkSEncKey = p.NwkSEncKey.Clone()
+	p.NwkSKey = p.NwkSKey.Clone()
+	p.AppSKey = p.AppSKey.Clone()
+	p.SessionKeyID = cloneBytes(p.SessionKeyID)
+	return p
+}
+
+// Redacted returns a copy of the RejoinAnsPayload without key material.
+func (p RejoinAnsPayload) Redacted() RejoinAnsPayload {
+	p = p.Clone()
+	p.SNwkSIntKey = p.SNwkSIntKey.Redacted()
+	p.FNwkSIntKey = p.FNwkSIntKey.Redacted()
+	p.NwkSEncKey = p.NwkSEncKey.Redacted()
+	p.NwkSKey = p.NwkSKey.Redacted()
+	p.AppSKey = p.AppSKey.Redacted()
+	return p
+}
+
+// Clone returns a deep copy of the AppSKeyAnsPayload.
+func (p AppSKeyAnsPayload) Clone() AppSKeyAnsPayload {
+	p.BasePayloadResult = p.BasePayloadResult.Clone()
+	p.AppSKey = p.AppSKey.Clone()
+	p.SessionKeyID = cloneBytes(p.SessionKeyID)
+	return p
+}
+
+// Redacted returns a copy of the AppSKeyAnsPayload without key material.
+func (p AppSKeyAnsPayload) Redacted() AppSKeyAnsPayload {
+	p = p.Clone()
+	p.AppSKey = p.AppSKey.Redacted()
+	return p
+}
+
+// Clone returns a deep copy of the PRStartReqPayload.
+func (p PRStartReqPayload) Clone() PRStartReqPayload {
+	p.BasePayload = p.BasePayload.Clone()
+	p.PHYPayload = cloneBytes(p.PHYPayload)
+	p.ULMetaData = p.ULMetaData.Clone()
+	return p
+}
+
+// Redacted returns a copy of the PRStartReqPayload without uplink tokens.
+func (p PRStartReqPayload) Redacted() PRStartReqPayload {
+	p = p.Clone()
+	p.ULMetaData = p.ULMetaData.Redacted()
+	return p
+}
+
+// Clone returns a deep copy of the PRStartAnsPayload.
+func (p PRStartAnsPayload) Clone() PRStartAnsPayload {
+	p.BasePayloadResult = p.BasePayloadResult.Clone()
+	p.PHYPayload = cloneBytes(p.PHYPayload)
+	p.DevEUI = clonePtr(p.DevEUI)
+	p.Lifetime = clonePtr(p.Lifetime)
+	p.FNwkSIntKey = p.FNwkSIntKey.Clone()
+	p.NwkSKey = p.NwkSKey.Clone()
+	p.FCntUp = clonePtr(p.FCntUp)
+	p.ServiceProfile = p.ServiceProfile.Clone()
+	p.DLMetaData = p.DLMetaData.Clone()
+	p.DevAddr = clonePtr(p.DevAddr)
+	return p
+}
+
+// Redacted returns a copy of the PRStartAnsPayload without key material and
+// uplink tokens.
+func (p PRStartAnsPayload) Redacted() PRStartAnsPayload {
+	p = p.Clone()
+	p.FNwkSIntKey = p.FNwkSIntKey.Redacted()
+	p.NwkSKey = p.NwkSKey.Redacted()
+	p.DLMetaData = p.DLMetaData.Redacted()
+	return p
+}
+
+// Clone returns a deep copy of the HRStartReqPayload.
+func (p HRStartReqPayload) Clone() HRStartReqPayload {
+	p.BasePayload = p.BasePayload.Clone()
+	p.PHYPayload = cloneBytes(p.PHYPayload)
+	p.DeviceProfile = *p.DeviceProfile.Clone()
+	p.ULMetaData = p.ULMetaData.Clone()
+	p.CFList = cloneBytes(p.CFList)
+	return p
+}
+
+// Redacted returns a copy of the HRStartReqPayload without uplink tokens.
+func (p HRStartReqPayload) Redacted() HRStartReqPayload {
+	p = p.Clone()
+	p.ULMetaData = p.ULMetaData.Redacted()
+	return p
+}
+
+// Clone returns a deep copy of the HRStartAnsPayload.
+func (p HRStartAnsPayload) Clone() HRStartAnsPayload {
+	p.BasePayloadResult = p.BasePayloadResult.Clone()
+	p.PHYPayload = cloneBytes(p.PHYPayload)
+	p.Lifetime = clonePtr(p.Lifetime)
+	p.SNwkSIntKey = p.SNwkSIntKey.Clone()
+	p.FNwkSIntKey = p.FNwkSIntKey.Clone()
+	p.NwkSEncKey = p.NwkSEncKey.Clone()
+	p.NwkSKey = p.NwkSKey.Clone()
+	p.DeviceProfile = p.DeviceProfile.Clone()
+	p.ServiceProfile = p.ServiceProfile.Clone()
+	p.DLMetaData = p.DLMetaData.Clone()
+	p.DeviceProfileTimestamp = clonePtr(p.DeviceProfileTimestamp)
+	return p
+}
+
+// Redacted returns a copy of the HRStartAnsPayload without key material and
+// uplink tokens.
+func (p HRStartAnsPayload) Redacted() HRStartAnsPayload {
+	p = p.Clone()
+	p.SNwkSIntKey = p.SNwkSIntKey.Redacted()
+	p.FNwkSIntKey = p.FNwkSIntKey.Redacted()
+	p.NwkSEncKey = p.NwkSEncKey.Redacted()
+	p.NwkSKey = p.NwkSKey.Redacted()
+	p.DLMetaData = p.DLMetaData.Redacted()
+	return p
+}
+
+// Clone returns a deep copy of the XmitDataReqPayload.
+func (p XmitDataReqPayload) Clone() XmitDataReqPayload {
+	p.BasePayload = p.BasePayload.Clone()
+	p.PHYPayload = cloneBytes(p.PHYPayload)
+	p.FRMPayload = cloneBytes(p.FRMPayload)
+	if p.ULMetaData != nil {
+		m := p.ULMetaData.Clone()
+		p.ULMetaData = &m
+	}
+	p.DLMetaData = p.DLMetaData.Clone()
+	return p
+}
+
+// Redacted returns a copy of the XmitDataReqPayload without uplink tokens.
+func (p XmitDataReqPayload) Redacted() XmitDataReqPayload {
+	p = p.Clone()
+	if p.ULMetaData != nil {
+		m := p.ULMetaData.Redacted()
+		p.ULMetaData = &m
+	}
+	p.DLMetaData = p.DLMetaData.Redacted()
+	return p
+}
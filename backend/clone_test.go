@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/brocaar/lorawan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	t.Run("JoinAnsPayload", func(t *testing.T) {
+		assert := require.New(t)
+
+		pl := JoinAnsPayload{
+			BasePayloadResult: BasePayloadResult{
+				BasePayload: BasePayload{
+					SenderID:    "0102030405060708",
+					SenderToken: HEXBytes{1, 2, 3},
+				},
+				Result: Result{ResultCode: Success},
+			},
+			PHYPayload: HEXBytes{1, 2, 3, 4},
+			Lifetime:   intPtr(10),
+			NwkSKey:    &KeyEnvelope{KEKLabel: "010203", AESKey: HEXBytes{1, 2, 3, 4}},
+			AppSKey:    &KeyEnvelope{AESKey: HEXBytes{5, 6, 7, 8}},
+		}
+
+		c := pl.Clone()
+		assert.Equal(pl, c)
+
+		c.NwkSKey.AESKey[0] = 0xff
+		c.BasePayload.SenderToken[0] = 0xff
+		*c.Lifetime = 20
+		assert.Equal(HEXBytes{1, 2, 3, 4}, pl.NwkSKey.AESKey)
+		assert.Equal(HEXBytes{1, 2, 3}, pl.BasePayload.SenderToken)
+		assert.Equal(10, *pl.Lifetime)
+
+		r := pl.Redacted()
+		assert.Equal(&KeyEnvelope{KEKLabel: "010203"}, r.NwkSKey)
+		assert.Equal(&KeyEnvelope{}, r.AppSKey)
+		assert.Nil(r.SNwkSIntKey)
+		assert.Equal(pl.PHYPayload, r.PHYPayload)
+		assert.Equal(HEXBytes{1, 2, 3, 4}, pl.NwkSKey.AESKey)
+	})
+
+	t.Run("PRStartReqPayload", func(t *testing.T) {
+		assert := require.New(t)
+
+		pl := PRStartReqPayload{
+			PHYPayload: HEXBytes{1, 2, 3},
+			ULMetaData: ULMetaData{
+				DevEUI:     &lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+				FNSULToken: HEXBytes{1},
+				GWInfo: []GWInfoElement{
+					{
+						ID:      &lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8},
+						RSSI:    intPtr(-10),
+						ULToken: HEXBytes{2},
+					},
+				},
+			},
+		}
+
+		c := pl.Clone()
+		assert.Equal(pl, c)
+
+		*c.ULMetaData.GWInfo[0].RSSI = -20
+		c.ULMetaData.GWInfo[0].ID[0] = 0xff
+		assert.Equal(-10, *pl.ULMetaData.GWInfo[0].RSSI)
+		assert.Equal(lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8}, *pl.ULMetaData.GWInfo[0].ID)
+
+		r := pl.Redacted()
+		assert.Nil(r.ULMetaData.FNSULToken)
+		assert.Nil(r.ULMetaData.GWInfo[0].ULToken)
+		assert.Equal(-10, *r.ULMetaData.GWInfo[0].RSSI)
+		assert.Equal(HEXBytes{1}, pl.ULMetaData.FNSULToken)
+		assert.Equal(HEXBytes{2}, pl.ULMetaData.GWInfo[0].ULToken)
+	})
+
+	t.Run("PRStartAnsPayload", func(t *testing.T) {
+		assert := require.New(t)
+
+		pl := PRStartAnsPayload{
+			FNwkSIntKey: &KeyEnvelope{AESKey: HEXBytes{1, 2, 3, 4}},
+			DLMetaData: &DLMetaData{
+				FNSULToken: HEXBytes{1},
+				GWInfo:     []GWInfoElement{{ULToken: HEXBytes{2}}},
+			},
+			ServiceProfile: &ServiceProfile{ChannelMask: HEXBytes{0xff}},
+		}
+
+		c := pl.Clone()
+		assert.Equal(pl, c)
+		assert.NotSame(pl.DLMetaData, c.DLMetaData)
+		assert.NotSame(pl.ServiceProfile, c.ServiceProfile)
+
+		r := pl.Redacted()
+		assert.Equal(&KeyEnvelope{}, r.FNwkSIntKey)
+		assert.Nil(r.DLMetaData.FNSULToken)
+		assert.Nil(r.DLMetaData.GWInfo[0].ULToken)
+		assert.Equal(HEXBytes{1}, pl.DLMetaData.FNSULToken)
+	})
+}
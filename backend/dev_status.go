@@ -0,0 +1,175 @@
+package backend
+
+import (
+	"math"
+	"time"
+
+	"github.com/brocaar/lorawan"
+)
+
+// GetDevStatusReqInterval returns the interval between two DevStatusReq
+// mac-commands, given the DevStatusReqFreq (requests per day) of the
+// ServiceProfile. It returns 0 when DevStatusReq is disabled.
+func GetDevStatusReqInterval(sp ServiceProfile) time.Duration {
+	if sp.DevStatusReqFreq <= 0 {
+		return 0
+	}
+	return 24 * time.Hour / time.Duration(sp.DevStatusReqFreq)
+}
+
+// GetNextDevStatusReq returns the time at which the next DevStatusReq must be
+// enqueued, given the ServiceProfile and the time of the last DevStatusReq
+// (the zero time when no DevStatusReq has been sent yet). It returns false
+// when DevStatusReq is disabled.
+func GetNextDevStatusReq(sp ServiceProfile, lastReq time.Time) (time.Time, bool) {
+	interval := GetDevStatusReqInterval(sp)
+	if interval == 0 {
+		return time.Time{}, false
+	}
+
+	if lastReq.IsZero() {
+		return lastReq, true
+	}
+	return lastReq.Add(interval), true
+}
+
+// DevStatusReqDue returns if a DevStatusReq must be enqueued at the given
+// time, given the ServiceProfile and the time of the last DevStatusReq.
+func DevStatusReqDue(sp ServiceProfile, lastReq, now time.Time) bool {
+	next, ok := GetNextDevStatusReq(sp, lastReq)
+	return ok && !now.Before(next)
+}
+
+// SetDevStatus sets the Battery and Margin fields to the values reported by
+// the device in the DevStatusAns. The fields are only set when reporting is
+// enabled by the ServiceProfile (ReportDevStatusBattery and
+// ReportDevStatusMargin), else they are cleared.
+func (m *ULMetaData) SetDevStatus(sp ServiceProfile, pl lorawan.DevStatusAnsPayload) {
+	m.Battery = nil
+	m.Margin = nil
+
+	if sp.ReportDevStatusBattery {
+		battery := int(pl.Battery)
+		m.Battery = &battery
+	}
+
+	if sp.ReportDevStatusMargin {
+		margin := int(pl.Margin)
+		m.Margin = &margin
+	}
+}
+
+// Special DevStatusAns battery values.
+const (
+	// DevStatusBatteryExternalPower indicates that the device is connected
+	// to an external power source.
+	DevStatusBatteryExternalPower = 0
+
+	// DevStatusBatteryUnknown indicates that the device was not able to
+	// measure the battery level.
+	DevStatusBatteryUnknown = 255
+)
+
+// DefaultDevStatusSmoothing defines the default smoothing factor of the
+// DevStatusAggregator.
+const DefaultDevStatusSmoothing = 0.5
+
+// DevStatusAggregator aggregates a sequence of DevStatusAns values of a device
+// into smoothed Battery and Margin values, using an exponential moving
+// average.
+//
+// The special battery values are not included in the average. When the device
+// reports DevStatusBatteryExternalPower, the battery average is reset and
+// DevStatusBatteryExternalPower is reported until the device reports a
+// battery level again. When the device reports DevStatusBatteryUnknown, the
+// last average is retained, or DevStatusBatteryUnknown is reported when there
+// is no average.
+type DevStatusAggregator struct {
+	// Smoothing defines the weight (0 < Smoothing <= 1) of a new value. A
+	// value of 1 disables smoothing. When 0, DefaultDevStatusSmoothing is
+	// used.
+	Smoothing float64
+
+	battery       float64
+	batteryValid  bool
+	externalPower bool
+	unknown       bool
+
+	margin      float64
+	marginValid bool
+}
+
+// Add adds the given DevStatusAns values to the aggregator.
+func (a *DevStatusAggregator) Add(pl lorawan.DevStatusAnsPayload) {
+	switch pl.Battery {
+	case DevStatusBatteryExternalPower:
+		a.battery = 0
+		a.batteryValid = false
+		a.externalPower = true
+		a.unknown = false
+	case DevStatusBatteryUnknown:
+		a.externalPower = false
+		a.unknown = true
+	default:
+		a.battery = a.smooth(a.battery, a.batteryValid, float64(pl.Battery))
+		a.batteryValid = true
+		a.externalPower = false
+		a.unknown = false
+	}
+
+	a.margin = a.smooth(a.margin, a.marginValid, float64(pl.Margin))
+	a.marginValid = true
+}
+
+// Battery returns the aggregated battery value, using the DevStatusAns
+// encoding (see DevStatusBatteryExternalPower and DevStatusBatteryUnknown).
+// It returns false when no values have been added.
+func (a DevStatusAggregator) Battery() (int, bool) {
+	switch {
+	case a.externalPower:
+		return DevStatusBatteryExternalPower, true
+	case a.batteryValid:
+		return int(math.Round(a.battery)), true
+	case a.unknown:
+		return DevStatusBatteryUnknown, true
+	default:
+		return 0, false
+	}
+}
+
+// Margin returns the aggregated margin value (dB). It returns false when no
+// values have been added.
+func (a DevStatusAggregator) Margin() (int, bool) {
+	if !a.marginValid {
+		return 0, false
+	}
+	return int(math.Round(a.margin)), true
+}
+
+// SetULMetaData sets the Battery and Margin fields of the given ULMetaData to
+// the aggregated values. As with ULMetaData.SetDevStatus, the fields are only
+// set when reporting is enabled by the ServiceProfile, else they are cleared.
+func (a DevStatusAggregator) SetULMetaData(sp ServiceProfile, m *ULMetaData) {
+	m.Battery = nil
+	m.Margin = nil
+
+	if battery, ok := a.Battery(); ok && sp.ReportDevStatusBattery {
+		m.Battery = &battery
+	}
+
+	if margin, ok := a.Margin(); ok && sp.ReportDevStatusMargin {
+		m.Margin = &margin
+	}
+}
+
+func (a DevStatusAggregator) smooth(avg float64, valid bool, v float64) float64 {
+	if !valid {
+		return v
+	}
+
+	alpha := a.Smoothing
+	if alpha <= 0 || alpha > 1 {
+		alpha = DefaultDevStatusSmoothing
+	}
+	return alpha*v + (1-alpha)*avg
+}
@@ -0,0 +1,210 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestDevStatusReq(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name             string
+		DevStatusReqFreq int
+		LastReq          time.Time
+		ExpectedInterval time.Duration
+		ExpectedNext     time.Time
+		ExpectedOK       bool
+		ExpectedDue      bool
+	}{
+		{
+			Name: "disabled",
+		},
+		{
+			Name:             "never requested",
+			DevStatusReqFreq: 4,
+			ExpectedInterval: 6 * time.Hour,
+			ExpectedOK:       true,
+			ExpectedDue:      true,
+		},
+		{
+			Name:             "not yet due",
+			DevStatusReqFreq: 4,
+			LastReq:          now.Add(-time.Hour),
+			ExpectedInterval: 6 * time.Hour,
+			ExpectedNext:     now.Add(5 * time.Hour),
+			ExpectedOK:       true,
+		},
+		{
+			Name:             "due",
+			DevStatusReqFreq: 24,
+			LastReq:          now.Add(-time.Hour),
+			ExpectedInterval: time.Hour,
+			ExpectedNext:     now,
+			ExpectedOK:       true,
+			ExpectedDue:      true,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+			sp := ServiceProfile{DevStatusReqFreq: tst.DevStatusReqFreq}
+
+			assert.Equal(tst.ExpectedInterval, GetDevStatusReqInterval(sp))
+
+			next, ok := GetNextDevStatusReq(sp, tst.LastReq)
+			assert.Equal(tst.ExpectedOK, ok)
+			assert.True(tst.ExpectedNext.Equal(next))
+
+			assert.Equal(tst.ExpectedDue, DevStatusReqDue(sp, tst.LastReq, now))
+		})
+	}
+}
+
+func TestULMetaDataSetDevStatus(t *testing.T) {
+	pl := lorawan.DevStatusAnsPayload{Battery: 127, Margin: -5}
+	battery := 127
+	margin := -5
+
+	tests := []struct {
+		Name           string
+		ServiceProfile ServiceProfile
+		Expected       ULMetaData
+	}{
+		{
+			Name: "reporting disabled",
+		},
+		{
+			Name:           "battery only",
+			ServiceProfile: ServiceProfile{ReportDevStatusBattery: true},
+			Expected:       ULMetaData{Battery: &battery},
+		},
+		{
+			Name:           "battery and margin",
+			ServiceProfile: ServiceProfile{ReportDevStatusBattery: true, ReportDevStatusMargin: true},
+			Expected:       ULMetaData{Battery: &battery, Margin: &margin},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			var md ULMetaData
+			md.SetDevStatus(tst.ServiceProfile, pl)
+			assert.Equal(tst.Expected, md)
+		})
+	}
+}
+
+func TestDevStatusAggregator(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	tests := []struct {
+		Name            string
+		Smoothing       float64
+		Values          []lorawan.DevStatusAnsPayload
+		ExpectedBattery *int
+		ExpectedMargin  *int
+	}{
+		{
+			Name: "no values",
+		},
+		{
+			Name:            "single value",
+			Values:          []lorawan.DevStatusAnsPayload{{Battery: 200, Margin: 10}},
+			ExpectedBattery: intPtr(200),
+			ExpectedMargin:  intPtr(10),
+		},
+		{
+			Name: "default smoothing",
+			Values: []lorawan.DevStatusAnsPayload{
+				{Battery: 200, Margin: 10},
+				{Battery: 100, Margin: -10},
+			},
+			ExpectedBattery: intPtr(150),
+			ExpectedMargin:  intPtr(0),
+		},
+		{
+			Name:      "custom smoothing",
+			Smoothing: 0.25,
+			Values: []lorawan.DevStatusAnsPayload{
+				{Battery: 200, Margin: 20},
+				{Battery: 100, Margin: 0},
+			},
+			ExpectedBattery: intPtr(175),
+			ExpectedMargin:  intPtr(15),
+		},
+		{
+			Name: "unknown battery retains average",
+			Values: []lorawan.DevStatusAnsPayload{
+				{Battery: 200, Margin: 10},
+				{Battery: 255, Margin: 10},
+			},
+			ExpectedBattery: intPtr(200),
+			ExpectedMargin:  intPtr(10),
+		},
+		{
+			Name: "unknown battery without average",
+			Values: []lorawan.DevStatusAnsPayload{
+				{Battery: 255, Margin: 5},
+			},
+			ExpectedBattery: intPtr(255),
+			ExpectedMargin:  intPtr(5),
+		},
+		{
+			Name: "external power",
+			Values: []lorawan.DevStatusAnsPayload{
+				{Battery: 200, Margin: 5},
+				{Battery: 0, Margin: 5},
+			},
+			ExpectedBattery: intPtr(0),
+			ExpectedMargin:  intPtr(5),
+		},
+		{
+			Name: "external power resets average",
+			Values: []lorawan.DevStatusAnsPayload{
+				{Battery: 200, Margin: 5},
+				{Battery: 0, Margin: 5},
+				{Battery: 50, Margin: 5},
+			},
+			ExpectedBattery: intPtr(50),
+			ExpectedMargin:  intPtr(5),
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			a := DevStatusAggregator{Smoothing: tst.Smoothing}
+			for _, pl := range tst.Values {
+				a.Add(pl)
+			}
+
+			battery, ok := a.Battery()
+			assert.Equal(tst.ExpectedBattery != nil, ok)
+			if tst.ExpectedBattery != nil {
+				assert.Equal(*tst.ExpectedBattery, battery)
+			}
+
+			margin, ok := a.Margin()
+			assert.Equal(tst.ExpectedMargin != nil, ok)
+			if tst.ExpectedMargin != nil {
+				assert.Equal(*tst.ExpectedMargin, margin)
+			}
+
+			var md ULMetaData
+			a.SetULMetaData(ServiceProfile{ReportDevStatusBattery: true, ReportDevStatusMargin: true}, &md)
+			assert.Equal(ULMetaData{Battery: tst.ExpectedBattery, Margin: tst.ExpectedMargin}, md)
+
+			a.SetULMetaData(ServiceProfile{ReportDevStatusMargin: true}, &md)
+			assert.Equal(ULMetaData{Margin: tst.ExpectedMargin}, md)
+		})
+	}
+}
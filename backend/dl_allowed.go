@@ -0,0 +1,55 @@
+package backend
+
+import "github.com/brocaar/lorawan"
+
+// DLAllowedPolicy contains the policy used to decide if downlink capability
+// can be offered for a roaming device.
+type DLAllowedPolicy struct {
+	// ServiceProfile contains the operator policy. In case of passive-roaming
+	// the PRAllowed flag is used, in case of handover-roaming the HRAllowed
+	// flag. When nil, the operator policy does not restrict downlink.
+	ServiceProfile *ServiceProfile
+
+	// Handover must be set to true in case of handover-roaming.
+	Handover bool
+
+	// GatewayAlive returns if the given gateway is alive (e.g. it has sent
+	// its stats within the expected interval). When nil, all gateways are
+	// considered to be alive. Gateways without ID are not considered to be
+	// alive when this is set, as their liveness can't be verified.
+	GatewayAlive func(id lorawan.GatewayEUI) bool
+}
+
+// GetDLAllowedGateways returns the gateways from the given gateway info
+// elements which can be used for downlink transmission, given the policy.
+// A gateway can be used when it has DLAllowed set and when it is alive.
+// It returns nil when the operator policy does not allow roaming.
+func GetDLAllowedGateways(gwInfo []GWInfoElement, policy DLAllowedPolicy) []GWInfoElement {
+	if sp := policy.ServiceProfile; sp != nil {
+		if (policy.Handover && !sp.HRAllowed) || (!policy.Handover && !sp.PRAllowed) {
+			return nil
+		}
+	}
+
+	var out []GWInfoElement
+	for _, gw := range gwInfo {
+		if !gw.DLAllowed {
+			continue
+		}
+
+		if policy.GatewayAlive != nil && (gw.ID == nil || !policy.GatewayAlive(*gw.ID)) {
+			continue
+		}
+
+		out = append(out, gw)
+	}
+
+	return out
+}
+
+// DLAllowed returns if downlink capability can be offered (e.g. in the
+// PRStartAns), meaning that at least one of the gateways which received the
+// uplink can be used for downlink transmission given the policy.
+func DLAllowed(gwInfo []GWInfoElement, policy DLAllowedPolicy) bool {
+	return len(GetDLAllowedGateways(gwInfo, policy)) != 0
+}
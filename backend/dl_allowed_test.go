@@ -0,0 +1,85 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestDLAllowed(t *testing.T) {
+	gw1 := lorawan.GatewayEUI{1}
+	gw2 := lorawan.GatewayEUI{2}
+
+	gwInfo := []GWInfoElement{
+		{ID: &gw1, DLAllowed: true},
+		{ID: &gw2, DLAllowed: false},
+		{DLAllowed: true},
+	}
+
+	alive := func(id lorawan.GatewayEUI) bool {
+		return id == gw2
+	}
+
+	tests := []struct {
+		Name     string
+		GWInfo   []GWInfoElement
+		Policy   DLAllowedPolicy
+		Expected []GWInfoElement
+	}{
+		{
+			Name:     "no policy",
+			GWInfo:   gwInfo,
+			Expected: []GWInfoElement{gwInfo[0], gwInfo[2]},
+		},
+		{
+			Name:     "no gateway info",
+			Expected: nil,
+		},
+		{
+			Name:     "passive-roaming allowed",
+			GWInfo:   gwInfo,
+			Policy:   DLAllowedPolicy{ServiceProfile: &ServiceProfile{PRAllowed: true}},
+			Expected: []GWInfoElement{gwInfo[0], gwInfo[2]},
+		},
+		{
+			Name:     "passive-roaming not allowed",
+			GWInfo:   gwInfo,
+			Policy:   DLAllowedPolicy{ServiceProfile: &ServiceProfile{HRAllowed: true}},
+			Expected: nil,
+		},
+		{
+			Name:     "handover-roaming not allowed",
+			GWInfo:   gwInfo,
+			Policy:   DLAllowedPolicy{ServiceProfile: &ServiceProfile{PRAllowed: true}, Handover: true},
+			Expected: nil,
+		},
+		{
+			Name:     "handover-roaming allowed",
+			GWInfo:   gwInfo,
+			Policy:   DLAllowedPolicy{ServiceProfile: &ServiceProfile{HRAllowed: true}, Handover: true},
+			Expected: []GWInfoElement{gwInfo[0], gwInfo[2]},
+		},
+		{
+			Name:     "gateway liveness",
+			GWInfo:   gwInfo,
+			Policy:   DLAllowedPolicy{GatewayAlive: func(lorawan.GatewayEUI) bool { return true }},
+			Expected: []GWInfoElement{gwInfo[0]},
+		},
+		{
+			Name:     "only gateway with DLAllowed=false is alive",
+			GWInfo:   gwInfo,
+			Policy:   DLAllowedPolicy{GatewayAlive: alive},
+			Expected: nil,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.Expected, GetDLAllowedGateways(tst.GWInfo, tst.Policy))
+			assert.Equal(len(tst.Expected) != 0, DLAllowed(tst.GWInfo, tst.Policy))
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package backend
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/brocaar/lorawan"
+	"github.com/pkg/errors"
+)
+
+// IDType defines the type of a SenderID or ReceiverID.
+type IDType int
+
+// Available ID types.
+const (
+	// IDTypeNetID is used for network-servers.
+	IDTypeNetID IDType = iota
+
+	// IDTypeEUI64 is used for join-servers (JoinEUI).
+	IDTypeEUI64
+
+	// IDTypeASID is used for application-servers.
+	IDTypeASID
+)
+
+// String implements fmt.Stringer.
+func (t IDType) String() string {
+	switch t {
+	case IDTypeNetID:
+		return "NetID"
+	case IDTypeEUI64:
+		return "EUI64"
+	case IDTypeASID:
+		return "AS-ID"
+	default:
+		return fmt.Sprintf("IDType(%d)", int(t))
+	}
+}
+
+// ID holds a SenderID or ReceiverID, which is either a NetID, an EUI64
+// (JoinEUI) or an AS-ID. Unlike the raw BasePayload strings, IDs can be
+// compared regardless of the case and zero-padding used by the peer.
+type ID struct {
+	Type IDType
+
+	NetID lorawan.NetID
+	EUI64 lorawan.EUI64
+	ASID  string
+}
+
+// NetIDToID returns the ID for the given NetID.
+func NetIDToID(netID lorawan.NetID) ID {
+	return ID{Type: IDTypeNetID, NetID: netID}
+}
+
+// EUI64ToID returns the ID for the given EUI64.
+func EUI64ToID(eui lorawan.EUI64) ID {
+	return ID{Type: IDTypeEUI64, EUI64: eui}
+}
+
+// ASIDToID returns the ID for the given AS-ID.
+func ASIDToID(asID string) ID {
+	return ID{Type: IDTypeASID, ASID: asID}
+}
+
+// ParseID parses the given SenderID or ReceiverID string as the given type.
+// NetIDs and EUI64s are parsed case-insensitive, with an optional 0x prefix
+// and with or without leading zeros.
+func ParseID(t IDType, s string) (ID, error) {
+	switch t {
+	case IDTypeNetID:
+		var netID lorawan.NetID
+		if err := decodeHexID(netID[:], s); err != nil {
+			return ID{}, errors.Wrapf(err, "invalid NetID %q", s)
+		}
+		return NetIDToID(netID), nil
+	case IDTypeEUI64:
+		var eui lorawan.EUI64
+		if err := decodeHexID(eui[:], s); err != nil {
+			return ID{}, errors.Wrapf(err, "invalid EUI64 %q", s)
+		}
+		return EUI64ToID(eui), nil
+	case IDTypeASID:
+		if s == "" {
+			return ID{}, errors.New("AS-ID must not be empty")
+		}
+		return ASIDToID(s), nil
+	default:
+		return ID{}, fmt.Errorf("invalid IDType: %d", int(t))
+	}
+}
+
+// String returns the canonical representation of the ID, as used for the
+// SenderID and ReceiverID fields: lowercase and zero-padded hex for NetIDs
+// and EUI64s.
+func (id ID) String() string {
+	switch id.Type {
+	case IDTypeNetID:
+		return id.NetID.String()
+	case IDTypeEUI64:
+		return id.EUI64.String()
+	default:
+		return id.ASID
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// Equal returns true when both IDs are of the same type and value.
+func (id ID) Equal(other ID) bool {
+	if id.Type != other.Type {
+		return false
+	}
+
+	switch id.Type {
+	case IDTypeNetID:
+		return id.NetID == other.NetID
+	case IDTypeEUI64:
+		return id.EUI64 == other.EUI64
+	default:
+		return id.ASID == other.ASID
+	}
+}
+
+// EqualIDs returns true when the given SenderID or ReceiverID strings
+// represent the same ID. Hex IDs are compared case-insensitive and
+// regardless of leading zeros. Other strings (AS-IDs) must be equal.
+func EqualIDs(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	a, aOK := normalizeHexID(a)
+	b, bOK := normalizeHexID(b)
+	return aOK && bOK && a == b
+}
+
+// ParseSenderID parses the SenderID as the given type.
+func (p BasePayload) ParseSenderID(t IDType) (ID, error) {
+	return ParseID(t, p.SenderID)
+}
+
+// ParseReceiverID parses the ReceiverID as the given type.
+func (p BasePayload) ParseReceiverID(t IDType) (ID, error) {
+	return ParseID(t, p.ReceiverID)
+}
+
+// decodeHexID decodes the given hex string into b, left-padding the string
+// with zeros.
+func decodeHexID(b []byte, s string) error {
+	s, ok := normalizeHexID(s)
+	if !ok {
+		return errors.New("invalid hex string")
+	}
+	if len(s) > len(b)*2 {
+		return fmt.Errorf("at most %d bytes are expected", len(b))
+	}
+
+	_, err := hex.Decode(b, []byte(strings.Repeat("0", len(b)*2-len(s))+s))
+	return err
+}
+
+// normalizeHexID returns the given hex string in lowercase, without 0x
+// prefix and leading zeros. It returns false when s is not a hex string.
+func normalizeHexID(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+	if s == "" {
+		return "", false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return "", false
+		}
+	}
+	return strings.TrimLeft(s, "0"), true
+}
@@ -0,0 +1,136 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/brocaar/lorawan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Type          IDType
+		String        string
+		Expected      ID
+		ExpectedStr   string
+		ExpectedError string
+	}{
+		{
+			Name:        "NetID",
+			Type:        IDTypeNetID,
+			String:      "010203",
+			Expected:    NetIDToID(lorawan.NetID{1, 2, 3}),
+			ExpectedStr: "010203",
+		},
+		{
+			Name:        "NetID uppercase without leading zeros",
+			Type:        IDTypeNetID,
+			String:      "0x1020A",
+			Expected:    NetIDToID(lorawan.NetID{1, 2, 10}),
+			ExpectedStr: "01020a",
+		},
+		{
+			Name:          "NetID too long",
+			Type:          IDTypeNetID,
+			String:        "01020304",
+			ExpectedError: "invalid NetID \"01020304\": at most 3 bytes are expected",
+		},
+		{
+			Name:        "EUI64",
+			Type:        IDTypeEUI64,
+			String:      "0102030405060708",
+			Expected:    EUI64ToID(lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8}),
+			ExpectedStr: "0102030405060708",
+		},
+		{
+			Name:        "EUI64 uppercase without leading zeros",
+			Type:        IDTypeEUI64,
+			String:      "102030405060A0B",
+			Expected:    EUI64ToID(lorawan.EUI64{1, 2, 3, 4, 5, 6, 10, 11}),
+			ExpectedStr: "0102030405060a0b",
+		},
+		{
+			Name:          "EUI64 invalid hex",
+			Type:          IDTypeEUI64,
+			String:        "foo",
+			ExpectedError: "invalid EUI64 \"foo\": invalid hex string",
+		},
+		{
+			Name:        "AS-ID",
+			Type:        IDTypeASID,
+			String:      "as.example.com",
+			Expected:    ASIDToID("as.example.com"),
+			ExpectedStr: "as.example.com",
+		},
+		{
+			Name:          "AS-ID empty",
+			Type:          IDTypeASID,
+			ExpectedError: "AS-ID must not be empty",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			id, err := ParseID(tst.Type, tst.String)
+			if tst.ExpectedError != "" {
+				assert.EqualError(err, tst.ExpectedError)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tst.Expected, id)
+			assert.True(id.Equal(tst.Expected))
+			assert.Equal(tst.ExpectedStr, id.String())
+		})
+	}
+}
+
+func TestIDEqual(t *testing.T) {
+	assert := require.New(t)
+
+	assert.True(NetIDToID(lorawan.NetID{1, 2, 3}).Equal(NetIDToID(lorawan.NetID{1, 2, 3})))
+	assert.False(NetIDToID(lorawan.NetID{1, 2, 3}).Equal(NetIDToID(lorawan.NetID{3, 2, 1})))
+	assert.False(NetIDToID(lorawan.NetID{0, 0, 1}).Equal(EUI64ToID(lorawan.EUI64{0, 0, 0, 0, 0, 0, 0, 1})))
+	assert.False(ASIDToID("as1").Equal(ASIDToID("as2")))
+}
+
+func TestEqualIDs(t *testing.T) {
+	tests := []struct {
+		A        string
+		B        string
+		Expected bool
+	}{
+		{"010203", "010203", true},
+		{"010203", "10203", true},
+		{"01020a", "0x01020A", true},
+		{"010203", "010204", false},
+		{"as.example.com", "as.example.com", true},
+		{"as.example.com", "AS.example.com", false},
+		{"", "000000", false},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.A+"-"+tst.B, func(t *testing.T) {
+			require.Equal(t, tst.Expected, EqualIDs(tst.A, tst.B))
+		})
+	}
+}
+
+func TestBasePayloadParseIDs(t *testing.T) {
+	assert := require.New(t)
+
+	pl := BasePayload{
+		SenderID:   "10203",
+		ReceiverID: "0102030405060708",
+	}
+
+	senderID, err := pl.ParseSenderID(IDTypeNetID)
+	assert.NoError(err)
+	assert.Equal(NetIDToID(lorawan.NetID{1, 2, 3}), senderID)
+
+	receiverID, err := pl.ParseReceiverID(IDTypeEUI64)
+	assert.NoError(err)
+	assert.Equal(EUI64ToID(lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8}), receiverID)
+}
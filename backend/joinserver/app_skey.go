@@ -0,0 +1,133 @@
+package joinserver
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/backend"
+)
+
+// SessionKeyIDLength defines the length (in bytes) of the generated
+// SessionKeyID.
+const SessionKeyIDLength = 16
+
+// ErrSessionKeyIDNotFound must be returned by GetAppSKeyFunc when no AppSKey
+// exists for the given DevEUI and SessionKeyID.
+var ErrSessionKeyIDNotFound = errors.New("session-key id does not exist")
+
+// setSessionKeyID generates the SessionKeyID of the session and stores it
+// together with the AppSKey, so that the AppSKey can be retrieved by the
+// application-server using the AppSKeyReq.
+func setSessionKeyID(ctx *context) error {
+	if ctx.storeAppSKeyFunc == nil {
+		return nil
+	}
+
+	ctx.sessionKeyID = make([]byte, SessionKeyIDLength)
+	if _, err := rand.Read(ctx.sessionKeyID); err != nil {
+		return err
+	}
+
+	return ctx.storeAppSKeyFunc(ctx.devEUI, ctx.sessionKeyID, ctx.appSKey)
+}
+
+func (h *handler) handleAppSKeyReq(w http.ResponseWriter, b []byte) {
+	var appSKeyReq backend.AppSKeyReqPayload
+	err := json.Unmarshal(b, &appSKeyReq)
+	if err != nil {
+		h.returnError(w, http.StatusBadRequest, backend.Other, err.Error())
+		return
+	}
+
+	// The SenderID is the AS-ID of the application-server, not a NetID.
+	if resErr := h.validateReceiver(appSKeyReq.BasePayload); resErr != nil {
+		h.returnAppSKeyReqError(w, appSKeyReq, resErr)
+		return
+	}
+
+	if err := h.validateEUI(lorawan.ValidateDevEUI(appSKeyReq.DevEUI)); err != nil {
+		h.returnAppSKeyReqError(w, appSKeyReq, &backend.ResultError{ResultCode: backend.MalformedRequest, HTTPStatus: http.StatusBadRequest, Err: err})
+		return
+	}
+
+	appSKey, err := h.config.GetAppSKeyFunc(appSKeyReq.DevEUI, appSKeyReq.SessionKeyID)
+	if err != nil {
+		h.returnAppSKeyReqError(w, appSKeyReq, getResultError(err, http.StatusBadRequest))
+		return
+	}
+
+	asKEKLabel, err := h.config.GetASKEKLabelFunc(appSKeyReq.SenderID, appSKeyReq.DevEUI)
+	if err != nil {
+		h.returnAppSKeyReqError(w, appSKeyReq, getResultError(err, http.StatusInternalServerError))
+		return
+	}
+
+	asKEK, err := h.getKEK(appSKeyReq.SenderID, asKEKLabel, appSKeyReq.DevEUI)
+	if err != nil {
+		h.returnAppSKeyReqError(w, appSKeyReq, getResultError(err, http.StatusInternalServerError))
+		return
+	}
+
+	ke, err := backend.NewKeyEnvelope(asKEKLabel, asKEK, appSKey)
+	if err != nil {
+		h.returnAppSKeyReqError(w, appSKeyReq, getResultError(err, http.StatusInternalServerError))
+		return
+	}
+
+	ans := backend.AppSKeyAnsPayload{
+		BasePayloadResult: backend.BasePayloadResult{
+			BasePayload: backend.BasePayload{
+				ProtocolVersion: backend.ProtocolVersion1_0,
+				SenderID:        appSKeyReq.ReceiverID,
+				ReceiverID:      appSKeyReq.SenderID,
+				TransactionID:   appSKeyReq.TransactionID,
+				MessageType:     backend.AppSKeyAns,
+			},
+			Result: backend.Result{
+				ResultCode: backend.Success,
+			},
+		},
+		DevEUI:       appSKeyReq.DevEUI,
+		AppSKey:      ke,
+		SessionKeyID: appSKeyReq.SessionKeyID,
+	}
+
+	h.log.WithFields(log.Fields{
+		"message_type":   ans.BasePayload.MessageType,
+		"sender_id":      ans.BasePayload.SenderID,
+		"receiver_id":    ans.BasePayload.ReceiverID,
+		"transaction_id": ans.BasePayload.TransactionID,
+		"result_code":    ans.Result.ResultCode,
+		"dev_eui":        appSKeyReq.DevEUI,
+	}).Info("backend/joinserver: sending response")
+
+	h.returnPayload(w, http.StatusOK, ans)
+}
+
+func (h *handler) returnAppSKeyReqError(w http.ResponseWriter, req backend.AppSKeyReqPayload, resErr *backend.ResultError) {
+	ans := backend.AppSKeyAnsPayload{
+		BasePayloadResult: backend.BasePayloadResult{
+			BasePayload: backend.BasePayload{
+				ProtocolVersion: backend.ProtocolVersion1_0,
+				SenderID:        req.ReceiverID,
+				ReceiverID:      req.SenderID,
+				TransactionID:   req.TransactionID,
+				MessageType:     backend.AppSKeyAns,
+				VSExtension:     resErr.VSExtension,
+			},
+			Result: backend.Result{
+				ResultCode:  resErr.ResultCode,
+				Description: resErr.Error(),
+			},
+		},
+		DevEUI:       req.DevEUI,
+		SessionKeyID: req.SessionKeyID,
+	}
+
+	h.returnPayload(w, resErr.HTTPStatus, ans)
+}
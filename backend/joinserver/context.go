@@ -15,6 +15,8 @@ type context struct {
 	deviceKeys       DeviceKeys
 	devNonce         lorawan.DevNonce
 	joinNonce        lorawan.JoinNonce
+	joinNoncePolicy  JoinNoncePolicy
+	lastJoinNonce    int
 	netID            lorawan.NetID
 	devEUI           lorawan.EUI64
 	joinEUI          lorawan.EUI64
@@ -26,4 +28,6 @@ type context struct {
 	nsKEK            []byte
 	asKEKLabel       string
 	asKEK            []byte
+	sessionKeyID     []byte
+	storeAppSKeyFunc func(devEUI lorawan.EUI64, sessionKeyID []byte, appSKey lorawan.AES128Key) error
 }
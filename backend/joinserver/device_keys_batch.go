@@ -0,0 +1,261 @@
+package joinserver
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/backend"
+)
+
+// BatchOptions holds the options for exporting and importing a batch of
+// DeviceKeys.
+type BatchOptions struct {
+	// KEKLabel and KEK are used on export to wrap the NwkKey and AppKey.
+	// When not set, the keys are exported as plain-text.
+	KEKLabel string
+	KEK      []byte
+
+	// GetKEKByLabelFunc is used on import to unwrap the NwkKey and AppKey.
+	// It must return an empty slice when no KEK exists for the given label.
+	GetKEKByLabelFunc func(label string) ([]byte, error)
+
+	// GetKEKsByLabelFunc is used on import (instead of GetKEKByLabelFunc)
+	// to unwrap the NwkKey and AppKey when set. Each of the returned KEKs
+	// is tried, so that keys wrapped by the old or new KEK of a KEK rotation
+	// are accepted.
+	GetKEKsByLabelFunc func(label string) ([]KEK, error)
+
+	// SigningKey holds the (optional) HMAC-SHA256 key. On export, a
+	// signature line is appended. On import, the signature is required and
+	// validated.
+	SigningKey []byte
+
+	// KeyDeriver is used on import to derive the NwkKey and AppKey of
+	// records which do not contain these keys.
+	KeyDeriver KeyDeriver
+}
+
+// deviceKeysRecord defines a single (JSON line) record of the batch.
+type deviceKeysRecord struct {
+	DevEUI    lorawan.EUI64        `json:"devEUI"`
+	NwkKey    *backend.KeyEnvelope `json:"nwkKey,omitempty"`
+	AppKey    *backend.KeyEnvelope `json:"appKey,omitempty"`
+	JoinNonce int                  `json:"joinNonce"`
+}
+
+// batchSignature defines the (last) signature line of the batch.
+type batchSignature struct {
+	Signature backend.HEXBytes `json:"signature"`
+}
+
+// ExportDeviceKeys writes the given DeviceKeys as batch to w. The batch is
+// encoded as JSON lines, one DeviceKeys record per line. The keys are
+// wrapped when a KEK is set and a HMAC-SHA256 signature line over all
+// preceding lines is appended when a SigningKey is set.
+func ExportDeviceKeys(w io.Writer, keys []DeviceKeys, opts BatchOptions) error {
+	mac := hmac.New(sha256.New, opts.SigningKey)
+
+	for _, dk := range keys {
+		if err := validateDeviceKeys(dk); err != nil {
+			return err
+		}
+
+		nwkKey, err := backend.NewKeyEnvelope(opts.KEKLabel, opts.KEK, dk.NwkKey)
+		if err != nil {
+			return err
+		}
+
+		appKey, err := backend.NewKeyEnvelope(opts.KEKLabel, opts.KEK, dk.AppKey)
+		if err != nil {
+			return err
+		}
+
+		b, err := json.Marshal(deviceKeysRecord{
+			DevEUI:    dk.DevEUI,
+			NwkKey:    nwkKey,
+			AppKey:    appKey,
+			JoinNonce: dk.JoinNonce,
+		})
+		if err != nil {
+			return err
+		}
+		b = append(b, '\n')
+
+		mac.Write(b)
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	if len(opts.SigningKey) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(batchSignature{Signature: mac.Sum(nil)})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// ImportDeviceKeys reads and validates the batch of DeviceKeys from r.
+// When a SigningKey is set, the batch must end with a valid signature line.
+// An error is returned when a record is invalid, a DevEUI is duplicated or
+// when a key can not be unwrapped.
+func ImportDeviceKeys(r io.Reader, opts BatchOptions) ([]DeviceKeys, error) {
+	mac := hmac.New(sha256.New, opts.SigningKey)
+	devEUIs := make(map[lorawan.EUI64]struct{})
+
+	var out []DeviceKeys
+	var signature []byte
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		b := scanner.Bytes()
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+
+		if signature != nil {
+			return nil, fmt.Errorf("line %d: unexpected data after signature", line)
+		}
+
+		if bytes.Contains(b, []byte(`"signature"`)) {
+			var sig batchSignature
+			if err := json.Unmarshal(b, &sig); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			signature = sig.Signature
+			continue
+		}
+
+		mac.Write(b)
+		mac.Write([]byte{'\n'})
+
+		var rec deviceKeysRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		dk, err := rec.deviceKeys(opts)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err := validateDeviceKeys(dk); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if _, ok := devEUIs[dk.DevEUI]; ok {
+			return nil, fmt.Errorf("line %d: duplicate DevEUI %s", line, dk.DevEUI)
+		}
+		devEUIs[dk.DevEUI] = struct{}{}
+
+		out = append(out, dk)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(opts.SigningKey) != 0 {
+		if signature == nil {
+			return nil, ErrBatchSignatureMissing
+		}
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, ErrBatchSignatureInvalid
+		}
+	}
+
+	return out, nil
+}
+
+func (r deviceKeysRecord) deviceKeys(opts BatchOptions) (DeviceKeys, error) {
+	dk := DeviceKeys{
+		DevEUI:    r.DevEUI,
+		JoinNonce: r.JoinNonce,
+	}
+
+	var err error
+
+	if r.NwkKey == nil && r.AppKey == nil && opts.KeyDeriver != nil {
+		if dk.NwkKey, dk.AppKey, err = opts.KeyDeriver.DeriveKeys(dk.DevEUI); err != nil {
+			return dk, fmt.Errorf("derive keys error: %w", err)
+		}
+		return dk, nil
+	}
+
+	if dk.NwkKey, err = unwrapKey(r.NwkKey, opts); err != nil {
+		return dk, fmt.Errorf("nwkKey: %w", err)
+	}
+	if dk.AppKey, err = unwrapKey(r.AppKey, opts); err != nil {
+		return dk, fmt.Errorf("appKey: %w", err)
+	}
+
+	return dk, nil
+}
+
+func unwrapKey(ke *backend.KeyEnvelope, opts BatchOptions) (lorawan.AES128Key, error) {
+	var key lorawan.AES128Key
+
+	if ke == nil {
+		return key, errors.New("key is missing")
+	}
+
+	if ke.KEKLabel == "" {
+		if len(ke.AESKey) != len(key) {
+			return key, fmt.Errorf("exactly %d bytes are expected", len(key))
+		}
+		copy(key[:], ke.AESKey)
+		return key, nil
+	}
+
+	if opts.GetKEKsByLabelFunc != nil {
+		keks, err := opts.GetKEKsByLabelFunc(ke.KEKLabel)
+		if err != nil {
+			return key, err
+		}
+		if len(keks) == 0 {
+			return key, fmt.Errorf("no KEK for label %s", ke.KEKLabel)
+		}
+
+		for _, kek := range keks {
+			key, err = ke.Unwrap(kek.KEK)
+			if err == nil {
+				return key, nil
+			}
+		}
+		return key, err
+	}
+
+	if opts.GetKEKByLabelFunc == nil {
+		return key, fmt.Errorf("no KEK for label %s", ke.KEKLabel)
+	}
+
+	kek, err := opts.GetKEKByLabelFunc(ke.KEKLabel)
+	if err != nil {
+		return key, err
+	}
+	if len(kek) == 0 {
+		return key, fmt.Errorf("no KEK for label %s", ke.KEKLabel)
+	}
+
+	return ke.Unwrap(kek)
+}
+
+func validateDeviceKeys(dk DeviceKeys) error {
+	if dk.DevEUI.IsZero() || dk.DevEUI.IsBroadcast() {
+		return fmt.Errorf("DevEUI %s is reserved", dk.DevEUI)
+	}
+	if dk.JoinNonce < 0 || dk.JoinNonce > int(lorawan.MaxJoinNonce) {
+		return fmt.Errorf("JoinNonce must be between 0 and %d", lorawan.MaxJoinNonce)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package joinserver
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestDeviceKeysBatch(t *testing.T) {
+	keys := []DeviceKeys{
+		{
+			DevEUI:    lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+			NwkKey:    lorawan.AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8},
+			AppKey:    lorawan.AES128Key{8, 7, 6, 5, 4, 3, 2, 1, 8, 7, 6, 5, 4, 3, 2, 1},
+			JoinNonce: 10,
+		},
+		{
+			DevEUI: lorawan.EUI64{8, 7, 6, 5, 4, 3, 2, 1},
+			NwkKey: lorawan.AES128Key{1},
+			AppKey: lorawan.AES128Key{2},
+		},
+	}
+
+	kek := []byte{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+	getKEK := func(label string) ([]byte, error) {
+		if label == "kek" {
+			return kek, nil
+		}
+		return nil, nil
+	}
+
+	t.Run("Plain-text", func(t *testing.T) {
+		assert := require.New(t)
+
+		var buf bytes.Buffer
+		assert.NoError(ExportDeviceKeys(&buf, keys, BatchOptions{}))
+		assert.Equal(2, strings.Count(buf.String(), "\n"))
+
+		out, err := ImportDeviceKeys(&buf, BatchOptions{})
+		assert.NoError(err)
+		assert.Equal(keys, out)
+	})
+
+	t.Run("KEK wrapped and signed", func(t *testing.T) {
+		assert := require.New(t)
+		opts := BatchOptions{
+			KEKLabel:          "kek",
+			KEK:               kek,
+			GetKEKByLabelFunc: getKEK,
+			SigningKey:        []byte("secret"),
+		}
+
+		var buf bytes.Buffer
+		assert.NoError(ExportDeviceKeys(&buf, keys, opts))
+		assert.Contains(buf.String(), `"KEKLabel":"kek"`)
+		b := buf.Bytes()
+
+		out, err := ImportDeviceKeys(bytes.NewReader(b), opts)
+		assert.NoError(err)
+		assert.Equal(keys, out)
+
+		t.Run("Unknown KEK", func(t *testing.T) {
+			assert := require.New(t)
+			_, err := ImportDeviceKeys(bytes.NewReader(b), BatchOptions{SigningKey: opts.SigningKey})
+			assert.EqualError(err, "line 1: nwkKey: no KEK for label kek")
+		})
+
+		t.Run("Invalid signature", func(t *testing.T) {
+			assert := require.New(t)
+			_, err := ImportDeviceKeys(bytes.NewReader(b), BatchOptions{GetKEKByLabelFunc: getKEK, SigningKey: []byte("other")})
+			assert.Equal(ErrBatchSignatureInvalid, err)
+		})
+
+		t.Run("Tampered batch", func(t *testing.T) {
+			assert := require.New(t)
+			lines := strings.SplitAfter(string(b), "\n")
+			_, err := ImportDeviceKeys(strings.NewReader(lines[0]+lines[2]), opts)
+			assert.Equal(ErrBatchSignatureInvalid, err)
+		})
+	})
+
+	t.Run("KEK rotation", func(t *testing.T) {
+		assert := require.New(t)
+
+		var buf bytes.Buffer
+		assert.NoError(ExportDeviceKeys(&buf, keys, BatchOptions{KEKLabel: "kek", KEK: kek}))
+
+		newKEK := []byte{8, 7, 6, 5, 4, 3, 2, 1, 8, 7, 6, 5, 4, 3, 2, 1}
+		out, err := ImportDeviceKeys(&buf, BatchOptions{
+			GetKEKsByLabelFunc: func(label string) ([]KEK, error) {
+				return []KEK{{Epoch: 2, KEK: newKEK}, {Epoch: 1, KEK: kek}}, nil
+			},
+		})
+		assert.NoError(err)
+		assert.Equal(keys, out)
+	})
+
+	t.Run("Signature missing", func(t *testing.T) {
+		assert := require.New(t)
+
+		var buf bytes.Buffer
+		assert.NoError(ExportDeviceKeys(&buf, keys, BatchOptions{}))
+
+		_, err := ImportDeviceKeys(&buf, BatchOptions{SigningKey: []byte("secret")})
+		assert.Equal(ErrBatchSignatureMissing, err)
+	})
+
+	t.Run("Duplicate DevEUI", func(t *testing.T) {
+		assert := require.New(t)
+
+		var buf bytes.Buffer
+		assert.NoError(ExportDeviceKeys(&buf, []DeviceKeys{keys[0], keys[0]}, BatchOptions{}))
+
+		_, err := ImportDeviceKeys(&buf, BatchOptions{})
+		assert.EqualError(err, "line 2: duplicate DevEUI 0102030405060708")
+	})
+
+	t.Run("Invalid JoinNonce", func(t *testing.T) {
+		assert := require.New(t)
+
+		dk := keys[0]
+		dk.JoinNonce = 1 << 24
+
+		var buf bytes.Buffer
+		assert.EqualError(ExportDeviceKeys(&buf, []DeviceKeys{dk}, BatchOptions{}), "JoinNonce must be between 0 and 16777215")
+	})
+}
@@ -1,9 +1,54 @@
 package joinserver
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/backend"
+)
 
 // Errors
 var (
 	ErrInvalidMIC     = errors.New("invalid mic")
 	ErrDevEUINotFound = errors.New("deveui does not exist")
+
+	ErrBatchSignatureMissing = errors.New("batch signature is missing")
+	ErrBatchSignatureInvalid = errors.New("batch signature is invalid")
 )
+
+// getResultError maps the given error to a ResultError. When the error does
+// not specify a HTTP status-code, the given status-code is used.
+func getResultError(err error, httpStatus int) *backend.ResultError {
+	var out backend.ResultError
+
+	var re *backend.ResultError
+	switch {
+	case errors.As(err, &re):
+		out = *re
+	case errors.Is(err, ErrDevEUINotFound):
+		out = backend.ResultError{ResultCode: backend.UnknownDevEUI, HTTPStatus: http.StatusBadRequest, Err: err}
+	case errors.Is(err, ErrSessionKeyIDNotFound):
+		out = backend.ResultError{ResultCode: backend.UnknownDevEUI, HTTPStatus: http.StatusBadRequest, Err: err}
+	case errors.Is(err, ErrInvalidMIC):
+		out = backend.ResultError{ResultCode: backend.MICFailed, Err: err}
+	case errors.Is(err, lorawan.ErrJoinNonceOverflow):
+		// the device must be re-provisioned before it can join again
+		out = backend.ResultError{ResultCode: backend.ActivationDisallowed, Err: err}
+	case errors.Is(err, ErrJoinNonceRegression):
+		out = backend.ResultError{ResultCode: backend.ActivationDisallowed, Err: err}
+	default:
+		out = backend.ResultError{ResultCode: backend.Other, Err: err}
+	}
+
+	if out.HTTPStatus == 0 {
+		out.HTTPStatus = httpStatus
+	}
+
+	// use the message of the (outer) error in case it has been wrapped
+	if out.Description == "" {
+		out.Description = err.Error()
+	}
+
+	return &out
+}
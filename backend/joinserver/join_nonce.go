@@ -0,0 +1,77 @@
+package joinserver
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/brocaar/lorawan"
+)
+
+// ErrJoinNonceRegression is returned when the JoinNonce of the DeviceKeys
+// is not greater than the last JoinNonce used for the device. Re-using a
+// JoinNonce with the same DevNonce results in the re-use of session-keys and
+// would allow an attacker to recover the keys of a previous session.
+var ErrJoinNonceRegression = errors.New("join-nonce must be greater than the last join-nonce")
+
+// JoinNoncePolicy defines how the JoinNonce (AppNonce in LoRaWAN 1.0.x) of
+// the join-accept is selected and validated.
+//
+// In LoRaWAN 1.1, the JoinNonce is a counter which must be incremented for
+// every join-accept, as the device rejects join-accepts of which the
+// JoinNonce is not greater than the last JoinNonce. In LoRaWAN 1.0.x, the
+// AppNonce is a random (or unique) value.
+type JoinNoncePolicy int
+
+// Available JoinNonce policies.
+const (
+	// JoinNonceMACVersion uses the JoinNonce of the DeviceKeys and enforces
+	// the counter for LoRaWAN 1.1 devices (OptNeg is set and rejoin-requests).
+	JoinNonceMACVersion JoinNoncePolicy = iota
+
+	// JoinNonceRandom generates a random AppNonce for LoRaWAN 1.0.x devices.
+	// For LoRaWAN 1.1 devices, the behavior equals JoinNonceMACVersion.
+	JoinNonceRandom
+
+	// JoinNonceCounter uses the JoinNonce of the DeviceKeys and enforces the
+	// counter for all devices.
+	JoinNonceCounter
+)
+
+// String implements fmt.Stringer.
+func (p JoinNoncePolicy) String() string {
+	switch p {
+	case JoinNonceMACVersion:
+		return "MACVersion"
+	case JoinNonceRandom:
+		return "Random"
+	case JoinNonceCounter:
+		return "Counter"
+	default:
+		return fmt.Sprintf("JoinNoncePolicy(%d)", int(p))
+	}
+}
+
+// validate returns an error when the given policy is not defined.
+func (p JoinNoncePolicy) validate() error {
+	switch p {
+	case JoinNonceMACVersion, JoinNonceRandom, JoinNonceCounter:
+		return nil
+	default:
+		return fmt.Errorf("backend/joinserver: invalid join-nonce policy: %s", p)
+	}
+}
+
+// isCounter returns true when the JoinNonce must be a counter.
+func (p JoinNoncePolicy) isCounter(optNeg bool) bool {
+	return optNeg || p == JoinNonceCounter
+}
+
+// getRandomJoinNonce returns a random JoinNonce.
+func getRandomJoinNonce() (lorawan.JoinNonce, error) {
+	var b [4]byte
+	if _, err := rand.Read(b[:3]); err != nil {
+		return 0, err
+	}
+	return lorawan.JoinNonce(uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16), nil
+}
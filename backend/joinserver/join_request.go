@@ -14,10 +14,11 @@ var joinTasks = []func(*context) error{
 	validateMIC,
 	setJoinNonce,
 	setSessionKeys,
+	setSessionKeyID,
 	createJoinAnsPayload,
 }
 
-func handleJoinRequestWrapper(joinReqPL backend.JoinReqPayload, dk DeviceKeys, asKEKLabel string, asKEK []byte, nsKEKLabel string, nsKEK []byte) backend.JoinAnsPayload {
+func handleJoinRequestWrapper(joinReqPL backend.JoinReqPayload, dk DeviceKeys, asKEKLabel string, asKEK []byte, nsKEKLabel string, nsKEK []byte, joinNoncePolicy JoinNoncePolicy, lastJoinNonce int, storeAppSKeyFunc func(devEUI lorawan.EUI64, sessionKeyID []byte, appSKey lorawan.AES128Key) error) backend.JoinAnsPayload {
 	basePayload := backend.BasePayload{
 		ProtocolVersion: backend.ProtocolVersion1_0,
 		SenderID:        joinReqPL.ReceiverID,
@@ -26,23 +27,16 @@ func handleJoinRequestWrapper(joinReqPL backend.JoinReqPayload, dk DeviceKeys, a
 		MessageType:     backend.JoinAns,
 	}
 
-	jaPL, err := handleJoinRequest(joinReqPL, dk, asKEKLabel, asKEK, nsKEKLabel, nsKEK)
+	jaPL, err := handleJoinRequest(joinReqPL, dk, asKEKLabel, asKEK, nsKEKLabel, nsKEK, joinNoncePolicy, lastJoinNonce, storeAppSKeyFunc)
 	if err != nil {
-		var resCode backend.ResultCode
-
-		switch errors.Cause(err) {
-		case ErrInvalidMIC:
-			resCode = backend.MICFailed
-		default:
-			resCode = backend.Other
-		}
+		resErr := getResultError(err, 0)
+		basePayload.VSExtension = resErr.VSExtension
 
 		jaPL = backend.JoinAnsPayload{
 			BasePayloadResult: backend.BasePayloadResult{
-				BasePayload: basePayload,
 				Result: backend.Result{
-					ResultCode:  resCode,
-					Description: err.Error(),
+					ResultCode:  resErr.ResultCode,
+					Description: resErr.Error(),
 				},
 			},
 		}
@@ -52,14 +46,17 @@ func handleJoinRequestWrapper(joinReqPL backend.JoinReqPayload, dk DeviceKeys, a
 	return jaPL
 }
 
-func handleJoinRequest(joinReqPL backend.JoinReqPayload, dk DeviceKeys, asKEKLabel string, asKEK []byte, nsKEKLabel string, nsKEK []byte) (backend.JoinAnsPayload, error) {
+func handleJoinRequest(joinReqPL backend.JoinReqPayload, dk DeviceKeys, asKEKLabel string, asKEK []byte, nsKEKLabel string, nsKEK []byte, joinNoncePolicy JoinNoncePolicy, lastJoinNonce int, storeAppSKeyFunc func(devEUI lorawan.EUI64, sessionKeyID []byte, appSKey lorawan.AES128Key) error) (backend.JoinAnsPayload, error) {
 	ctx := context{
-		joinReqPayload: joinReqPL,
-		deviceKeys:     dk,
-		asKEKLabel:     asKEKLabel,
-		asKEK:          asKEK,
-		nsKEKLabel:     nsKEKLabel,
-		nsKEK:          nsKEK,
+		joinReqPayload:   joinReqPL,
+		deviceKeys:       dk,
+		asKEKLabel:       asKEKLabel,
+		asKEK:            asKEK,
+		nsKEKLabel:       nsKEKLabel,
+		nsKEK:            nsKEK,
+		joinNoncePolicy:  joinNoncePolicy,
+		lastJoinNonce:    lastJoinNonce,
+		storeAppSKeyFunc: storeAppSKeyFunc,
 	}
 
 	for _, f := range joinTasks {
@@ -76,13 +73,17 @@ func setJoinContext(ctx *context) error {
 		return errors.Wrap(err, "unmarshal phypayload error")
 	}
 
-	if err := ctx.netID.UnmarshalText([]byte(ctx.joinReqPayload.SenderID)); err != nil {
+	netID, err := ctx.joinReqPayload.ParseSenderID(backend.IDTypeNetID)
+	if err != nil {
 		return errors.Wrap(err, "unmarshal netid error")
 	}
+	ctx.netID = netID.NetID
 
-	if err := ctx.joinEUI.UnmarshalText([]byte(ctx.joinReqPayload.ReceiverID)); err != nil {
+	joinEUI, err := ctx.joinReqPayload.ParseReceiverID(backend.IDTypeEUI64)
+	if err != nil {
 		return errors.Wrap(err, "unmarshal joineui error")
 	}
+	ctx.joinEUI = joinEUI.EUI64
 
 	ctx.devEUI = ctx.joinReqPayload.DevEUI
 	ctx.joinType = lorawan.JoinRequestType
@@ -109,8 +110,26 @@ func validateMIC(ctx *context) error {
 }
 
 func setJoinNonce(ctx *context) error {
-	if ctx.deviceKeys.JoinNonce > (1<<24)-1 {
-		return errors.New("join-nonce overflow")
+	// Rejoin-requests are only implemented by LoRaWAN 1.1 devices.
+	optNeg := ctx.joinType != lorawan.JoinRequestType || ctx.joinReqPayload.DLSettings.OptNeg
+
+	if ctx.joinNoncePolicy == JoinNonceRandom && !optNeg {
+		joinNonce, err := getRandomJoinNonce()
+		if err != nil {
+			return errors.Wrap(err, "get random join-nonce error")
+		}
+		ctx.joinNonce = joinNonce
+		return nil
+	}
+
+	if ctx.deviceKeys.JoinNonce < 0 {
+		return fmt.Errorf("invalid join-nonce: %d", ctx.deviceKeys.JoinNonce)
+	}
+	if ctx.deviceKeys.JoinNonce > int(lorawan.MaxJoinNonce) {
+		return lorawan.ErrJoinNonceOverflow
+	}
+	if ctx.joinNoncePolicy.isCounter(optNeg) && ctx.deviceKeys.JoinNonce <= ctx.lastJoinNonce {
+		return errors.Wrapf(ErrJoinNonceRegression, "join-nonce %d, last join-nonce %d", ctx.deviceKeys.JoinNonce, ctx.lastJoinNonce)
 	}
 	ctx.joinNonce = lorawan.JoinNonce(ctx.deviceKeys.JoinNonce)
 	return nil
@@ -202,7 +221,8 @@ func createJoinAnsPayload(ctx *context) error {
 				ResultCode: backend.Success,
 			},
 		},
-		PHYPayload: backend.HEXBytes(b),
+		PHYPayload:   backend.HEXBytes(b),
+		SessionKeyID: ctx.sessionKeyID,
 		// TODO add Lifetime?
 	}
 
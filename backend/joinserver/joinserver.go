@@ -3,6 +3,7 @@
 package joinserver
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,13 +26,110 @@ type DeviceKeys struct {
 	JoinNonce int // the join-nonce that must be used for the join-accept
 }
 
-// HandlerConfig holds the join-server handler configuration.
+// HandlerConfig holds the join-server handler configuration. The callback
+// functions can return (or wrap) a backend.ResultError to control the Result
+// of the answer. Note that answers created after the device-keys and KEKs
+// have been retrieved are always returned with HTTP status 200.
 type HandlerConfig struct {
 	Logger                    *log.Logger
 	GetDeviceKeysByDevEUIFunc func(devEUI lorawan.EUI64) (DeviceKeys, error)    // ErrDevEUINotFound must be returned when the device does not exist
 	GetKEKByLabelFunc         func(label string) ([]byte, error)                // must return an empty slice when no KEK exists for the given label
 	GetASKEKLabelByDevEUIFunc func(devEUI lorawan.EUI64) (string, error)        // must return an empty string when no label exists
 	GetHomeNetIDByDevEUIFunc  func(devEUI lorawan.EUI64) (lorawan.NetID, error) // ErrDevEUINotFound must be returned when the device does not exist
+
+	// GetNSKEKLabelFunc returns the KEK label used to wrap the network
+	// session-keys, given the SenderID (NetID) of the requesting
+	// network-server and the DevEUI. This makes it possible to use a
+	// different KEK per roaming partner. When not set, the SenderID is used
+	// as KEK label.
+	GetNSKEKLabelFunc func(senderID string, devEUI lorawan.EUI64) (string, error)
+
+	// GetASKEKLabelFunc returns the KEK label used to wrap the AppSKey, given
+	// the SenderID (NetID) of the requesting network-server and the DevEUI.
+	// This makes it possible to wrap the AppSKey for a third party, depending
+	// the roaming partner. When not set, GetASKEKLabelByDevEUIFunc is used.
+	GetASKEKLabelFunc func(senderID string, devEUI lorawan.EUI64) (string, error)
+
+	// GetKEKsByLabelFunc returns all KEKs for the given label, e.g. the old
+	// and the new KEK during a KEK rotation. When set, it is used instead of
+	// GetKEKByLabelFunc. It must return an empty slice when no KEK exists for
+	// the given label.
+	GetKEKsByLabelFunc func(label string) ([]KEK, error)
+
+	// GetKEKEpochFunc returns the epoch of the KEK used to wrap the
+	// session-keys, given the SenderID of the requesting network-server, the
+	// KEK label and the DevEUI. This makes it possible to switch each
+	// partner to the new KEK once it has installed it. When not set, the KEK
+	// with the highest epoch is used.
+	GetKEKEpochFunc func(senderID, label string, devEUI lorawan.EUI64) (int, error)
+
+	// KEKUsedFunc is called with the label and epoch of each KEK used to
+	// wrap the session-keys, e.g. to track the progress of a KEK rotation.
+	// The epoch is always 0 when GetKEKsByLabelFunc is not set.
+	KEKUsedFunc func(senderID, label string, devEUI lorawan.EUI64, epoch int)
+
+	// StrictEUIValidation rejects DevEUIs and JoinEUIs which have the
+	// multicast (group) bit set. All-zero and broadcast DevEUIs and
+	// broadcast JoinEUIs are always rejected.
+	StrictEUIValidation bool
+
+	// JoinEUIs holds the JoinEUI ranges served by the join-server. When set,
+	// requests of which the ReceiverID does not match one of the ranges are
+	// rejected with UnknownReceiver. When empty, any ReceiverID is accepted.
+	JoinEUIs []JoinEUIRange
+
+	// NetIDs holds the NetIDs of the known network-servers. When set,
+	// requests of which the SenderID does not match one of the NetIDs are
+	// rejected with UnknownSender. When empty, any SenderID is accepted.
+	NetIDs []lorawan.NetID
+
+	// JoinNoncePolicy defines how the JoinNonce of the join-accept is
+	// selected and validated. The default is JoinNonceMACVersion.
+	JoinNoncePolicy JoinNoncePolicy
+
+	// GetLastJoinNonceFunc returns the last JoinNonce used for a join-accept
+	// of the given device. It must return -1 when no JoinNonce has been used.
+	// When set, the JoinNonce of the DeviceKeys is validated against it
+	// when the JoinNoncePolicy enforces a counter. ErrJoinNonceRegression is
+	// returned when the JoinNonce would regress.
+	GetLastJoinNonceFunc func(devEUI lorawan.EUI64) (int, error)
+
+	// StoreAppSKeyFunc stores the AppSKey of a (re)join under the given
+	// DevEUI and SessionKeyID. When set, a random SessionKeyID is generated
+	// for each (re)join and included in the JoinAns and RejoinAns, so that
+	// the application-server can retrieve the AppSKey using the AppSKeyReq.
+	StoreAppSKeyFunc func(devEUI lorawan.EUI64, sessionKeyID []byte, appSKey lorawan.AES128Key) error
+
+	// GetAppSKeyFunc returns the AppSKey stored under the given DevEUI and
+	// SessionKeyID, for handling AppSKeyReq messages. ErrSessionKeyIDNotFound
+	// must be returned when no AppSKey exists. When not set, AppSKeyReq
+	// messages are rejected.
+	GetAppSKeyFunc func(devEUI lorawan.EUI64, sessionKeyID []byte) (lorawan.AES128Key, error)
+
+	// RequestReceivedFunc is called with the BasePayload and the HTTP
+	// headers of each received request, e.g. for auditing the API key or
+	// correlation ID set by the sender.
+	RequestReceivedFunc func(basePL backend.BasePayload, header http.Header)
+}
+
+// JoinEUIRange defines an (inclusive) range of JoinEUIs. For a single
+// JoinEUI, Start and End are equal.
+type JoinEUIRange struct {
+	Start lorawan.EUI64
+	End   lorawan.EUI64
+}
+
+// Contains returns true when the given JoinEUI is within the range.
+func (r JoinEUIRange) Contains(joinEUI lorawan.EUI64) bool {
+	return bytes.Compare(joinEUI[:], r.Start[:]) >= 0 && bytes.Compare(joinEUI[:], r.End[:]) <= 0
+}
+
+// keks holds the KEK labels and KEKs used to wrap the session-keys.
+type keks struct {
+	asKEKLabel string
+	asKEK      []byte
+	nsKEKLabel string
+	nsKEK      []byte
 }
 
 type handler struct {
@@ -45,6 +143,16 @@ func NewHandler(config HandlerConfig) (http.Handler, error) {
 		return nil, errors.New("backend/joinserver: GetDeviceKeysFunc must not be nil")
 	}
 
+	for _, r := range config.JoinEUIs {
+		if bytes.Compare(r.Start[:], r.End[:]) > 0 {
+			return nil, fmt.Errorf("backend/joinserver: start of JoinEUI range must be before end (%s - %s)", r.Start, r.End)
+		}
+	}
+
+	if err := config.JoinNoncePolicy.validate(); err != nil {
+		return nil, err
+	}
+
 	h := handler{
 		config: config,
 		log:    config.Logger,
@@ -57,7 +165,9 @@ func NewHandler(config HandlerConfig) (http.Handler, error) {
 	}
 
 	if h.config.GetKEKByLabelFunc == nil {
-		h.log.Warning("backend/joinserver: get kek by label function is not set")
+		if h.config.GetKEKsByLabelFunc == nil {
+			h.log.Warning("backend/joinserver: get kek by label function is not set")
+		}
 
 		h.config.GetKEKByLabelFunc = func(label string) ([]byte, error) {
 			return nil, nil
@@ -72,6 +182,18 @@ func NewHandler(config HandlerConfig) (http.Handler, error) {
 		}
 	}
 
+	if h.config.GetNSKEKLabelFunc == nil {
+		h.config.GetNSKEKLabelFunc = func(senderID string, devEUI lorawan.EUI64) (string, error) {
+			return senderID, nil
+		}
+	}
+
+	if h.config.GetASKEKLabelFunc == nil {
+		h.config.GetASKEKLabelFunc = func(senderID string, devEUI lorawan.EUI64) (string, error) {
+			return h.config.GetASKEKLabelByDevEUIFunc(devEUI)
+		}
+	}
+
 	if h.config.GetHomeNetIDByDevEUIFunc == nil {
 		h.log.Warning("backend/joinserver: get home netid by deveui function is not set")
 
@@ -80,6 +202,12 @@ func NewHandler(config HandlerConfig) (http.Handler, error) {
 		}
 	}
 
+	if h.config.GetAppSKeyFunc == nil {
+		h.config.GetAppSKeyFunc = func(devEUI lorawan.EUI64, sessionKeyID []byte) (lorawan.AES128Key, error) {
+			return lorawan.AES128Key{}, ErrSessionKeyIDNotFound
+		}
+	}
+
 	return &h, nil
 }
 
@@ -105,6 +233,10 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"transaction_id": basePL.TransactionID,
 	}).Info("backend/joinserver: request received")
 
+	if h.config.RequestReceivedFunc != nil {
+		h.config.RequestReceivedFunc(basePL, r.Header.Clone())
+	}
+
 	switch basePL.MessageType {
 	case backend.JoinReq:
 		h.handleJoinReq(w, b)
@@ -112,11 +244,112 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleRejoinReq(w, b)
 	case backend.HomeNSReq:
 		h.handleHomeNSReq(w, b)
+	case backend.AppSKeyReq:
+		h.handleAppSKeyReq(w, b)
 	default:
 		h.returnError(w, http.StatusBadRequest, backend.Other, fmt.Sprintf("invalid MessageType: %s", basePL.MessageType))
 	}
 }
 
+// getKEKs returns the KEK labels and KEKs for the given SenderID and DevEUI.
+func (h *handler) getKEKs(senderID string, devEUI lorawan.EUI64) (keks, error) {
+	var out keks
+	var err error
+
+	out.nsKEKLabel, err = h.config.GetNSKEKLabelFunc(senderID, devEUI)
+	if err != nil {
+		return out, err
+	}
+
+	out.nsKEK, err = h.getKEK(senderID, out.nsKEKLabel, devEUI)
+	if err != nil {
+		return out, err
+	}
+
+	out.asKEKLabel, err = h.config.GetASKEKLabelFunc(senderID, devEUI)
+	if err != nil {
+		return out, err
+	}
+
+	out.asKEK, err = h.getKEK(senderID, out.asKEKLabel, devEUI)
+	if err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
+
+// getLastJoinNonce returns the last JoinNonce used for the given device, or
+// -1 when unknown.
+func (h *handler) getLastJoinNonce(devEUI lorawan.EUI64) (int, error) {
+	if h.config.GetLastJoinNonceFunc == nil {
+		return -1, nil
+	}
+	return h.config.GetLastJoinNonceFunc(devEUI)
+}
+
+// validateSenderReceiver validates the SenderID (NetID) and ReceiverID
+// (JoinEUI) of the request against the configured NetIDs and JoinEUIs.
+func (h *handler) validateSenderReceiver(basePL backend.BasePayload) *backend.ResultError {
+	if resErr := h.validateReceiver(basePL); resErr != nil {
+		return resErr
+	}
+	return h.validateSender(basePL)
+}
+
+// validateReceiver validates the ReceiverID (JoinEUI) of the request against
+// the configured JoinEUIs.
+func (h *handler) validateReceiver(basePL backend.BasePayload) *backend.ResultError {
+	if len(h.config.JoinEUIs) == 0 {
+		return nil
+	}
+
+	if id, err := basePL.ParseReceiverID(backend.IDTypeEUI64); err == nil {
+		for _, r := range h.config.JoinEUIs {
+			if r.Contains(id.EUI64) {
+				return nil
+			}
+		}
+	}
+
+	return &backend.ResultError{
+		ResultCode: backend.UnknownReceiver,
+		HTTPStatus: http.StatusBadRequest,
+		Err:        fmt.Errorf("unknown ReceiverID: %s", basePL.ReceiverID),
+	}
+}
+
+// validateSender validates the SenderID (NetID) of the request against the
+// configured NetIDs.
+func (h *handler) validateSender(basePL backend.BasePayload) *backend.ResultError {
+	if len(h.config.NetIDs) == 0 {
+		return nil
+	}
+
+	if id, err := basePL.ParseSenderID(backend.IDTypeNetID); err == nil {
+		for _, netID := range h.config.NetIDs {
+			if backend.NetIDToID(netID).Equal(id) {
+				return nil
+			}
+		}
+	}
+
+	return &backend.ResultError{
+		ResultCode: backend.UnknownSender,
+		HTTPStatus: http.StatusBadRequest,
+		Err:        fmt.Errorf("unknown SenderID: %s", basePL.SenderID),
+	}
+}
+
+// validateEUI filters the given EUI validation error. ErrEUIMulticast is
+// only returned when StrictEUIValidation is enabled.
+func (h *handler) validateEUI(err error) error {
+	if err == lorawan.ErrEUIMulticast && !h.config.StrictEUIValidation {
+		return nil
+	}
+	return err
+}
+
 func (h *handler) returnError(w http.ResponseWriter, code int, resultCode backend.ResultCode, msg string) {
 	h.log.WithFields(log.Fields{
 		"error": msg,
@@ -137,7 +370,7 @@ func (h *handler) returnError(w http.ResponseWriter, code int, resultCode backen
 	w.Write(b)
 }
 
-func (h *handler) returnJoinReqError(w http.ResponseWriter, basePL backend.BasePayload, code int, resultCode backend.ResultCode, msg string) {
+func (h *handler) returnJoinReqError(w http.ResponseWriter, basePL backend.BasePayload, resErr *backend.ResultError) {
 	jaPL := backend.JoinAnsPayload{
 		BasePayloadResult: backend.BasePayloadResult{
 			BasePayload: backend.BasePayload{
@@ -146,18 +379,19 @@ func (h *handler) returnJoinReqError(w http.ResponseWriter, basePL backend.BaseP
 				ReceiverID:      basePL.SenderID,
 				TransactionID:   basePL.TransactionID,
 				MessageType:     backend.JoinAns,
+				VSExtension:     resErr.VSExtension,
 			},
 			Result: backend.Result{
-				ResultCode:  resultCode,
-				Description: msg,
+				ResultCode:  resErr.ResultCode,
+				Description: resErr.Error(),
 			},
 		},
 	}
 
-	h.returnPayload(w, code, jaPL)
+	h.returnPayload(w, resErr.HTTPStatus, jaPL)
 }
 
-func (h *handler) returnRejoinReqError(w http.ResponseWriter, basePL backend.BasePayload, code int, resultCode backend.ResultCode, msg string) {
+func (h *handler) returnRejoinReqError(w http.ResponseWriter, basePL backend.BasePayload, resErr *backend.ResultError) {
 	jaPL := backend.RejoinAnsPayload{
 		BasePayloadResult: backend.BasePayloadResult{
 			BasePayload: backend.BasePayload{
@@ -166,18 +400,19 @@ func (h *handler) returnRejoinReqError(w http.ResponseWriter, basePL backend.Bas
 				ReceiverID:      basePL.SenderID,
 				TransactionID:   basePL.TransactionID,
 				MessageType:     backend.RejoinAns,
+				VSExtension:     resErr.VSExtension,
 			},
 			Result: backend.Result{
-				ResultCode:  resultCode,
-				Description: msg,
+				ResultCode:  resErr.ResultCode,
+				Description: resErr.Error(),
 			},
 		},
 	}
 
-	h.returnPayload(w, code, jaPL)
+	h.returnPayload(w, resErr.HTTPStatus, jaPL)
 }
 
-func (h *handler) returnHomeNSReqError(w http.ResponseWriter, basePL backend.BasePayload, code int, resultCode backend.ResultCode, msg string) {
+func (h *handler) returnHomeNSReqError(w http.ResponseWriter, basePL backend.BasePayload, resErr *backend.ResultError) {
 	jaPL := backend.HomeNSAnsPayload{
 		BasePayloadResult: backend.BasePayloadResult{
 			BasePayload: backend.BasePayload{
@@ -186,15 +421,16 @@ func (h *handler) returnHomeNSReqError(w http.ResponseWriter, basePL backend.Bas
 				ReceiverID:      basePL.SenderID,
 				TransactionID:   basePL.TransactionID,
 				MessageType:     backend.HomeNSAns,
+				VSExtension:     resErr.VSExtension,
 			},
 			Result: backend.Result{
-				ResultCode:  resultCode,
-				Description: msg,
+				ResultCode:  resErr.ResultCode,
+				Description: resErr.Error(),
 			},
 		},
 	}
 
-	h.returnPayload(w, code, jaPL)
+	h.returnPayload(w, resErr.HTTPStatus, jaPL)
 }
 
 func (h *handler) returnPayload(w http.ResponseWriter, code int, pl interface{}) {
@@ -217,36 +453,43 @@ func (h *handler) handleJoinReq(w http.ResponseWriter, b []byte) {
 		return
 	}
 
-	dk, err := h.config.GetDeviceKeysByDevEUIFunc(joinReqPL.DevEUI)
-	if err != nil {
-		switch err {
-		case ErrDevEUINotFound:
-			h.returnJoinReqError(w, joinReqPL.BasePayload, http.StatusBadRequest, backend.UnknownDevEUI, err.Error())
-		default:
-			h.returnJoinReqError(w, joinReqPL.BasePayload, http.StatusBadRequest, backend.Other, err.Error())
+	if resErr := h.validateSenderReceiver(joinReqPL.BasePayload); resErr != nil {
+		h.returnJoinReqError(w, joinReqPL.BasePayload, resErr)
+		return
+	}
+
+	var joinEUI lorawan.EUI64
+	if err := joinEUI.UnmarshalText([]byte(joinReqPL.ReceiverID)); err == nil {
+		if err := h.validateEUI(lorawan.ValidateJoinEUI(joinEUI)); err != nil {
+			h.returnJoinReqError(w, joinReqPL.BasePayload, &backend.ResultError{ResultCode: backend.MalformedRequest, HTTPStatus: http.StatusBadRequest, Err: err})
+			return
 		}
+	}
+
+	if err := h.validateEUI(lorawan.ValidateDevEUI(joinReqPL.DevEUI)); err != nil {
+		h.returnJoinReqError(w, joinReqPL.BasePayload, &backend.ResultError{ResultCode: backend.MalformedRequest, HTTPStatus: http.StatusBadRequest, Err: err})
 		return
 	}
 
-	nsKEK, err := h.config.GetKEKByLabelFunc(joinReqPL.SenderID)
+	dk, err := h.config.GetDeviceKeysByDevEUIFunc(joinReqPL.DevEUI)
 	if err != nil {
-		h.returnJoinReqError(w, joinReqPL.BasePayload, http.StatusInternalServerError, backend.Other, err.Error())
+		h.returnJoinReqError(w, joinReqPL.BasePayload, getResultError(err, http.StatusBadRequest))
 		return
 	}
 
-	asKEKLabel, err := h.config.GetASKEKLabelByDevEUIFunc(joinReqPL.DevEUI)
+	k, err := h.getKEKs(joinReqPL.SenderID, joinReqPL.DevEUI)
 	if err != nil {
-		h.returnJoinReqError(w, joinReqPL.BasePayload, http.StatusInternalServerError, backend.Other, err.Error())
+		h.returnJoinReqError(w, joinReqPL.BasePayload, getResultError(err, http.StatusInternalServerError))
 		return
 	}
 
-	asKEK, err := h.config.GetKEKByLabelFunc(asKEKLabel)
+	lastJoinNonce, err := h.getLastJoinNonce(joinReqPL.DevEUI)
 	if err != nil {
-		h.returnJoinReqError(w, joinReqPL.BasePayload, http.StatusInternalServerError, backend.Other, err.Error())
+		h.returnJoinReqError(w, joinReqPL.BasePayload, getResultError(err, http.StatusInternalServerError))
 		return
 	}
 
-	ans := handleJoinRequestWrapper(joinReqPL, dk, asKEKLabel, asKEK, joinReqPL.SenderID, nsKEK)
+	ans := handleJoinRequestWrapper(joinReqPL, dk, k.asKEKLabel, k.asKEK, k.nsKEKLabel, k.nsKEK, h.config.JoinNoncePolicy, lastJoinNonce, h.config.StoreAppSKeyFunc)
 
 	h.log.WithFields(log.Fields{
 		"message_type":   ans.BasePayload.MessageType,
@@ -268,36 +511,35 @@ func (h *handler) handleRejoinReq(w http.ResponseWriter, b []byte) {
 		return
 	}
 
-	dk, err := h.config.GetDeviceKeysByDevEUIFunc(rejoinReqPL.DevEUI)
-	if err != nil {
-		switch err {
-		case ErrDevEUINotFound:
-			h.returnRejoinReqError(w, rejoinReqPL.BasePayload, http.StatusBadRequest, backend.UnknownDevEUI, err.Error())
-		default:
-			h.returnRejoinReqError(w, rejoinReqPL.BasePayload, http.StatusBadRequest, backend.Other, err.Error())
-		}
+	if resErr := h.validateSenderReceiver(rejoinReqPL.BasePayload); resErr != nil {
+		h.returnRejoinReqError(w, rejoinReqPL.BasePayload, resErr)
 		return
 	}
 
-	nsKEK, err := h.config.GetKEKByLabelFunc(rejoinReqPL.SenderID)
+	if err := h.validateEUI(lorawan.ValidateDevEUI(rejoinReqPL.DevEUI)); err != nil {
+		h.returnRejoinReqError(w, rejoinReqPL.BasePayload, &backend.ResultError{ResultCode: backend.MalformedRequest, HTTPStatus: http.StatusBadRequest, Err: err})
+		return
+	}
+
+	dk, err := h.config.GetDeviceKeysByDevEUIFunc(rejoinReqPL.DevEUI)
 	if err != nil {
-		h.returnRejoinReqError(w, rejoinReqPL.BasePayload, http.StatusInternalServerError, backend.Other, err.Error())
+		h.returnRejoinReqError(w, rejoinReqPL.BasePayload, getResultError(err, http.StatusBadRequest))
 		return
 	}
 
-	asKEKLabel, err := h.config.GetASKEKLabelByDevEUIFunc(rejoinReqPL.DevEUI)
+	k, err := h.getKEKs(rejoinReqPL.SenderID, rejoinReqPL.DevEUI)
 	if err != nil {
-		h.returnRejoinReqError(w, rejoinReqPL.BasePayload, http.StatusInternalServerError, backend.Other, err.Error())
+		h.returnRejoinReqError(w, rejoinReqPL.BasePayload, getResultError(err, http.StatusInternalServerError))
 		return
 	}
 
-	asKEK, err := h.config.GetKEKByLabelFunc(asKEKLabel)
+	lastJoinNonce, err := h.getLastJoinNonce(rejoinReqPL.DevEUI)
 	if err != nil {
-		h.returnRejoinReqError(w, rejoinReqPL.BasePayload, http.StatusInternalServerError, backend.Other, err.Error())
+		h.returnRejoinReqError(w, rejoinReqPL.BasePayload, getResultError(err, http.StatusInternalServerError))
 		return
 	}
 
-	ans := handleRejoinRequestWrapper(rejoinReqPL, dk, asKEKLabel, asKEK, rejoinReqPL.SenderID, nsKEK)
+	ans := handleRejoinRequestWrapper(rejoinReqPL, dk, k.asKEKLabel, k.asKEK, k.nsKEKLabel, k.nsKEK, h.config.JoinNoncePolicy, lastJoinNonce, h.config.StoreAppSKeyFunc)
 
 	h.log.WithFields(log.Fields{
 		"message_type":   ans.BasePayload.MessageType,
@@ -319,14 +561,19 @@ func (h *handler) handleHomeNSReq(w http.ResponseWriter, b []byte) {
 		return
 	}
 
+	if resErr := h.validateSenderReceiver(homeNSReq.BasePayload); resErr != nil {
+		h.returnHomeNSReqError(w, homeNSReq.BasePayload, resErr)
+		return
+	}
+
+	if err := h.validateEUI(lorawan.ValidateDevEUI(homeNSReq.DevEUI)); err != nil {
+		h.returnHomeNSReqError(w, homeNSReq.BasePayload, &backend.ResultError{ResultCode: backend.MalformedRequest, HTTPStatus: http.StatusBadRequest, Err: err})
+		return
+	}
+
 	netID, err := h.config.GetHomeNetIDByDevEUIFunc(homeNSReq.DevEUI)
 	if err != nil {
-		switch err {
-		case ErrDevEUINotFound:
-			h.returnHomeNSReqError(w, homeNSReq.BasePayload, http.StatusBadRequest, backend.UnknownDevEUI, err.Error())
-		default:
-			h.returnHomeNSReqError(w, homeNSReq.BasePayload, http.StatusInternalServerError, backend.Other, err.Error())
-		}
+		h.returnHomeNSReqError(w, homeNSReq.BasePayload, getResultError(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -3,6 +3,8 @@ package joinserver
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -424,6 +426,65 @@ func (ts *JoinServerTestSuite) TestJoinRequest() {
 				},
 			},
 		},
+		{
+			Name: "join-request with zero DevEUI",
+			RequestPayload: backend.JoinReqPayload{
+				BasePayload: backend.BasePayload{
+					ProtocolVersion: backend.ProtocolVersion1_0,
+					SenderID:        "010203",
+					ReceiverID:      "0807060504030201",
+					TransactionID:   1234,
+					MessageType:     backend.JoinReq,
+				},
+				MACVersion: "1.0.2",
+				PHYPayload: backend.HEXBytes(validJRPHYBytes),
+			},
+			ExpectedAnsPayload: backend.JoinAnsPayload{
+				BasePayloadResult: backend.BasePayloadResult{
+					BasePayload: backend.BasePayload{
+						ProtocolVersion: backend.ProtocolVersion1_0,
+						SenderID:        "0807060504030201",
+						ReceiverID:      "010203",
+						TransactionID:   1234,
+						MessageType:     backend.JoinAns,
+					},
+					Result: backend.Result{
+						ResultCode:  backend.MalformedRequest,
+						Description: lorawan.ErrEUIZero.Error(),
+					},
+				},
+			},
+		},
+		{
+			Name: "join-request with broadcast JoinEUI",
+			RequestPayload: backend.JoinReqPayload{
+				BasePayload: backend.BasePayload{
+					ProtocolVersion: backend.ProtocolVersion1_0,
+					SenderID:        "010203",
+					ReceiverID:      "ffffffffffffffff",
+					TransactionID:   1234,
+					MessageType:     backend.JoinReq,
+				},
+				MACVersion: "1.0.2",
+				PHYPayload: backend.HEXBytes(validJRPHYBytes),
+				DevEUI:     lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+			},
+			ExpectedAnsPayload: backend.JoinAnsPayload{
+				BasePayloadResult: backend.BasePayloadResult{
+					BasePayload: backend.BasePayload{
+						ProtocolVersion: backend.ProtocolVersion1_0,
+						SenderID:        "ffffffffffffffff",
+						ReceiverID:      "010203",
+						TransactionID:   1234,
+						MessageType:     backend.JoinAns,
+					},
+					Result: backend.Result{
+						ResultCode:  backend.MalformedRequest,
+						Description: lorawan.ErrEUIBroadcast.Error(),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tst := range tests {
@@ -862,6 +923,33 @@ func (ts *JoinServerTestSuite) TestHomeNSReq() {
 				},
 			},
 		},
+		{
+			Name: "invalid DevEUI",
+			RequestPayload: backend.HomeNSReqPayload{
+				BasePayload: backend.BasePayload{
+					ProtocolVersion: backend.ProtocolVersion1_0,
+					SenderID:        "010203",
+					ReceiverID:      "0807060504030201",
+					TransactionID:   1234,
+					MessageType:     backend.HomeNSReq,
+				},
+			},
+			ExpectedAnsPayload: backend.HomeNSAnsPayload{
+				BasePayloadResult: backend.BasePayloadResult{
+					BasePayload: backend.BasePayload{
+						ProtocolVersion: backend.ProtocolVersion1_0,
+						SenderID:        "0807060504030201",
+						ReceiverID:      "010203",
+						TransactionID:   1234,
+						MessageType:     backend.HomeNSAns,
+					},
+					Result: backend.Result{
+						ResultCode:  backend.MalformedRequest,
+						Description: "lorawan: EUI must not be 0",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tst := range tests {
@@ -888,3 +976,677 @@ func (ts *JoinServerTestSuite) TestHomeNSReq() {
 func TestJoinServer(t *testing.T) {
 	suite.Run(t, new(JoinServerTestSuite))
 }
+
+func TestGetKEKs(t *testing.T) {
+	devEUI := lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+	kekByLabel := map[string][]byte{
+		"010203":          {1},
+		"030201":          {2},
+		"partner-a-ns":    {3},
+		"partner-a-as":    {4},
+		"lora-app-server": {5},
+	}
+	getKEKByLabel := func(label string) ([]byte, error) {
+		return kekByLabel[label], nil
+	}
+	getASKEKLabelByDevEUI := func(devEUI lorawan.EUI64) (string, error) {
+		return "lora-app-server", nil
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		assert := require.New(t)
+
+		h, err := NewHandler(HandlerConfig{
+			GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) { return DeviceKeys{}, nil },
+			GetKEKByLabelFunc:         getKEKByLabel,
+			GetASKEKLabelByDevEUIFunc: getASKEKLabelByDevEUI,
+		})
+		assert.NoError(err)
+
+		k, err := h.(*handler).getKEKs("010203", devEUI)
+		assert.NoError(err)
+		assert.Equal(keks{
+			nsKEKLabel: "010203",
+			nsKEK:      []byte{1},
+			asKEKLabel: "lora-app-server",
+			asKEK:      []byte{5},
+		}, k)
+	})
+
+	t.Run("Per SenderID", func(t *testing.T) {
+		assert := require.New(t)
+
+		h, err := NewHandler(HandlerConfig{
+			GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) { return DeviceKeys{}, nil },
+			GetKEKByLabelFunc:         getKEKByLabel,
+			GetASKEKLabelByDevEUIFunc: getASKEKLabelByDevEUI,
+			GetNSKEKLabelFunc: func(senderID string, devEUI lorawan.EUI64) (string, error) {
+				if senderID == "030201" {
+					return "partner-a-ns", nil
+				}
+				return senderID, nil
+			},
+			GetASKEKLabelFunc: func(senderID string, devEUI lorawan.EUI64) (string, error) {
+				if senderID == "030201" {
+					return "partner-a-as", nil
+				}
+				return getASKEKLabelByDevEUI(devEUI)
+			},
+		})
+		assert.NoError(err)
+
+		k, err := h.(*handler).getKEKs("030201", devEUI)
+		assert.NoError(err)
+		assert.Equal(keks{
+			nsKEKLabel: "partner-a-ns",
+			nsKEK:      []byte{3},
+			asKEKLabel: "partner-a-as",
+			asKEK:      []byte{4},
+		}, k)
+
+		k, err = h.(*handler).getKEKs("010203", devEUI)
+		assert.NoError(err)
+		assert.Equal("010203", k.nsKEKLabel)
+		assert.Equal("lora-app-server", k.asKEKLabel)
+	})
+
+	t.Run("KEK rotation", func(t *testing.T) {
+		getKEKsByLabel := func(label string) ([]KEK, error) {
+			if label == "010203" {
+				return []KEK{{Epoch: 1, KEK: []byte{1}}, {Epoch: 2, KEK: []byte{2}}}, nil
+			}
+			return nil, nil
+		}
+
+		type kekUsed struct {
+			SenderID string
+			Label    string
+			Epoch    int
+		}
+
+		tests := []struct {
+			Name             string
+			GetKEKEpochFunc  func(senderID, label string, devEUI lorawan.EUI64) (int, error)
+			ExpectedKEKs     keks
+			ExpectedKEKsUsed []kekUsed
+			ExpectedError    string
+		}{
+			{
+				Name: "highest epoch",
+				ExpectedKEKs: keks{
+					nsKEKLabel: "010203",
+					nsKEK:      []byte{2},
+					asKEKLabel: "lora-app-server",
+				},
+				ExpectedKEKsUsed: []kekUsed{{SenderID: "010203", Label: "010203", Epoch: 2}},
+			},
+			{
+				Name: "epoch per request",
+				GetKEKEpochFunc: func(senderID, label string, devEUI lorawan.EUI64) (int, error) {
+					return 1, nil
+				},
+				ExpectedKEKs: keks{
+					nsKEKLabel: "010203",
+					nsKEK:      []byte{1},
+					asKEKLabel: "lora-app-server",
+				},
+				ExpectedKEKsUsed: []kekUsed{{SenderID: "010203", Label: "010203", Epoch: 1}},
+			},
+			{
+				Name: "unknown epoch",
+				GetKEKEpochFunc: func(senderID, label string, devEUI lorawan.EUI64) (int, error) {
+					return 3, nil
+				},
+				ExpectedError: "no KEK with epoch 3 for label 010203",
+			},
+		}
+
+		for _, tst := range tests {
+			t.Run(tst.Name, func(t *testing.T) {
+				assert := require.New(t)
+
+				var used []kekUsed
+				h, err := NewHandler(HandlerConfig{
+					GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) { return DeviceKeys{}, nil },
+					GetKEKsByLabelFunc:        getKEKsByLabel,
+					GetKEKEpochFunc:           tst.GetKEKEpochFunc,
+					GetASKEKLabelByDevEUIFunc: getASKEKLabelByDevEUI,
+					KEKUsedFunc: func(senderID, label string, devEUI lorawan.EUI64, epoch int) {
+						used = append(used, kekUsed{SenderID: senderID, Label: label, Epoch: epoch})
+					},
+				})
+				assert.NoError(err)
+
+				k, err := h.(*handler).getKEKs("010203", devEUI)
+				if tst.ExpectedError != "" {
+					assert.EqualError(err, tst.ExpectedError)
+					return
+				}
+				assert.NoError(err)
+				assert.Equal(tst.ExpectedKEKs, k)
+				assert.Equal(tst.ExpectedKEKsUsed, used)
+			})
+		}
+	})
+}
+
+func TestResultError(t *testing.T) {
+	t.Run("getResultError", func(t *testing.T) {
+		tests := []struct {
+			Name     string
+			Error    error
+			Expected backend.ResultError
+		}{
+			{
+				Name:     "ErrDevEUINotFound",
+				Error:    ErrDevEUINotFound,
+				Expected: backend.ResultError{ResultCode: backend.UnknownDevEUI, HTTPStatus: http.StatusBadRequest, Description: "deveui does not exist", Err: ErrDevEUINotFound},
+			},
+			{
+				Name:     "wrapped ErrInvalidMIC",
+				Error:    fmt.Errorf("validate mic error: %w", ErrInvalidMIC),
+				Expected: backend.ResultError{ResultCode: backend.MICFailed, HTTPStatus: http.StatusInternalServerError, Description: "validate mic error: invalid mic", Err: fmt.Errorf("validate mic error: %w", ErrInvalidMIC)},
+			},
+			{
+				Name:     "wrapped ErrJoinNonceOverflow",
+				Error:    fmt.Errorf("set join-nonce error: %w", lorawan.ErrJoinNonceOverflow),
+				Expected: backend.ResultError{ResultCode: backend.ActivationDisallowed, HTTPStatus: http.StatusInternalServerError, Description: "set join-nonce error: lorawan: JoinNonce overflow, the device must be re-provisioned", Err: fmt.Errorf("set join-nonce error: %w", lorawan.ErrJoinNonceOverflow)},
+			},
+			{
+				Name:     "other error",
+				Error:    errors.New("boom"),
+				Expected: backend.ResultError{ResultCode: backend.Other, HTTPStatus: http.StatusInternalServerError, Description: "boom", Err: errors.New("boom")},
+			},
+			{
+				Name:     "backend.ResultError",
+				Error:    &backend.ResultError{ResultCode: backend.ActivationDisallowed, HTTPStatus: http.StatusForbidden, Description: "device is suspended"},
+				Expected: backend.ResultError{ResultCode: backend.ActivationDisallowed, HTTPStatus: http.StatusForbidden, Description: "device is suspended"},
+			},
+		}
+
+		for _, tst := range tests {
+			t.Run(tst.Name, func(t *testing.T) {
+				assert := require.New(t)
+				assert.Equal(tst.Expected, *getResultError(tst.Error, http.StatusInternalServerError))
+			})
+		}
+	})
+
+	t.Run("Callback returns backend.ResultError", func(t *testing.T) {
+		assert := require.New(t)
+
+		vsExtension := backend.VSExtension{
+			VendorID: backend.HEXBytes{1, 2, 3},
+			Object:   json.RawMessage(`{"reason":"suspended"}`),
+		}
+
+		h, err := NewHandler(HandlerConfig{
+			GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) {
+				return DeviceKeys{}, fmt.Errorf("get device-keys error: %w", &backend.ResultError{
+					ResultCode:  backend.ActivationDisallowed,
+					Description: "device is suspended",
+					HTTPStatus:  http.StatusForbidden,
+					VSExtension: vsExtension,
+				})
+			},
+		})
+		assert.NoError(err)
+
+		server := httptest.NewServer(h)
+		defer server.Close()
+
+		b, err := json.Marshal(backend.JoinReqPayload{
+			BasePayload: backend.BasePayload{
+				ProtocolVersion: backend.ProtocolVersion1_0,
+				SenderID:        "010203",
+				ReceiverID:      "0807060504030201",
+				TransactionID:   1234,
+				MessageType:     backend.JoinReq,
+			},
+			DevEUI: lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+		})
+		assert.NoError(err)
+
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+		assert.NoError(err)
+		defer resp.Body.Close()
+
+		assert.Equal(http.StatusForbidden, resp.StatusCode)
+
+		var ansPayload backend.JoinAnsPayload
+		assert.NoError(json.NewDecoder(resp.Body).Decode(&ansPayload))
+		assert.Equal(backend.Result{
+			ResultCode:  backend.ActivationDisallowed,
+			Description: "device is suspended",
+		}, ansPayload.Result)
+		assert.Equal(vsExtension, ansPayload.VSExtension)
+	})
+}
+
+func TestSenderReceiverValidation(t *testing.T) {
+	assert := require.New(t)
+
+	_, err := NewHandler(HandlerConfig{
+		GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) {
+			return DeviceKeys{}, ErrDevEUINotFound
+		},
+		JoinEUIs: []JoinEUIRange{
+			{Start: lorawan.EUI64{2}, End: lorawan.EUI64{1}},
+		},
+	})
+	assert.EqualError(err, "backend/joinserver: start of JoinEUI range must be before end (0200000000000000 - 0100000000000000)")
+
+	h, err := NewHandler(HandlerConfig{
+		GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) {
+			return DeviceKeys{}, ErrDevEUINotFound
+		},
+		JoinEUIs: []JoinEUIRange{
+			{Start: lorawan.EUI64{8, 7, 6, 5, 4, 3, 2, 1}, End: lorawan.EUI64{8, 7, 6, 5, 4, 3, 2, 1}},
+			{Start: lorawan.EUI64{1, 0, 0, 0, 0, 0, 0, 0}, End: lorawan.EUI64{1, 0, 0, 0, 0, 0, 0, 255}},
+		},
+		NetIDs: []lorawan.NetID{{1, 2, 3}},
+	})
+	assert.NoError(err)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		messageType backend.MessageType
+		senderID    string
+		receiverID  string
+		resultCode  backend.ResultCode
+		description string
+	}{
+		{
+			name:        "JoinReq known sender and receiver",
+			messageType: backend.JoinReq,
+			senderID:    "010203",
+			receiverID:  "0807060504030201",
+			resultCode:  backend.UnknownDevEUI,
+			description: "deveui does not exist",
+		},
+		{
+			name:        "JoinReq receiver within range",
+			messageType: backend.JoinReq,
+			senderID:    "010203",
+			receiverID:  "01000000000000ff",
+			resultCode:  backend.UnknownDevEUI,
+			description: "deveui does not exist",
+		},
+		{
+			name:        "JoinReq uppercase and unpadded sender and receiver",
+			messageType: backend.JoinReq,
+			senderID:    "10203",
+			receiverID:  "0x10000000000000A",
+			resultCode:  backend.UnknownDevEUI,
+			description: "deveui does not exist",
+		},
+		{
+			name:        "JoinReq unknown receiver",
+			messageType: backend.JoinReq,
+			senderID:    "010203",
+			receiverID:  "0100000000000100",
+			resultCode:  backend.UnknownReceiver,
+			description: "unknown ReceiverID: 0100000000000100",
+		},
+		{
+			name:        "JoinReq invalid receiver",
+			messageType: backend.JoinReq,
+			senderID:    "010203",
+			receiverID:  "foo",
+			resultCode:  backend.UnknownReceiver,
+			description: "unknown ReceiverID: foo",
+		},
+		{
+			name:        "JoinReq unknown sender",
+			messageType: backend.JoinReq,
+			senderID:    "030201",
+			receiverID:  "0807060504030201",
+			resultCode:  backend.UnknownSender,
+			description: "unknown SenderID: 030201",
+		},
+		{
+			name:        "RejoinReq unknown receiver",
+			messageType: backend.RejoinReq,
+			senderID:    "010203",
+			receiverID:  "0102030405060708",
+			resultCode:  backend.UnknownReceiver,
+			description: "unknown ReceiverID: 0102030405060708",
+		},
+		{
+			name:        "HomeNSReq unknown sender",
+			messageType: backend.HomeNSReq,
+			senderID:    "030201",
+			receiverID:  "0807060504030201",
+			resultCode:  backend.UnknownSender,
+			description: "unknown SenderID: 030201",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := json.Marshal(backend.JoinReqPayload{
+				BasePayload: backend.BasePayload{
+					ProtocolVersion: backend.ProtocolVersion1_0,
+					SenderID:        tst.senderID,
+					ReceiverID:      tst.receiverID,
+					TransactionID:   1234,
+					MessageType:     tst.messageType,
+				},
+				DevEUI: lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+			})
+			assert.NoError(err)
+
+			resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+			assert.NoError(err)
+			defer resp.Body.Close()
+
+			assert.Equal(http.StatusBadRequest, resp.StatusCode)
+
+			var ans backend.BasePayloadResult
+			assert.NoError(json.NewDecoder(resp.Body).Decode(&ans))
+			assert.Equal(backend.Result{
+				ResultCode:  tst.resultCode,
+				Description: tst.description,
+			}, ans.Result)
+		})
+	}
+}
+
+func TestRequestReceivedFunc(t *testing.T) {
+	assert := require.New(t)
+
+	var basePL backend.BasePayload
+	var header http.Header
+
+	h, err := NewHandler(HandlerConfig{
+		GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) {
+			return DeviceKeys{}, ErrDevEUINotFound
+		},
+		RequestReceivedFunc: func(pl backend.BasePayload, h http.Header) {
+			basePL = pl
+			header = h
+		},
+	})
+	assert.NoError(err)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	b, err := json.Marshal(backend.JoinReqPayload{
+		BasePayload: backend.BasePayload{
+			ProtocolVersion: backend.ProtocolVersion1_0,
+			SenderID:        "010203",
+			ReceiverID:      "0807060504030201",
+			TransactionID:   1234,
+			MessageType:     backend.JoinReq,
+		},
+		DevEUI: lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+	})
+	assert.NoError(err)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(b))
+	assert.NoError(err)
+	req.Header.Set("X-Api-Key", "api-key")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(err)
+	resp.Body.Close()
+
+	assert.Equal(backend.JoinReq, basePL.MessageType)
+	assert.Equal(uint32(1234), basePL.TransactionID)
+	assert.Equal("api-key", header.Get("X-Api-Key"))
+}
+
+func TestSetJoinNonce(t *testing.T) {
+	tests := []struct {
+		name              string
+		policy            JoinNoncePolicy
+		rejoin            bool
+		optNeg            bool
+		joinNonce         int
+		lastJoinNonce     int
+		expectedJoinNonce lorawan.JoinNonce
+		expectedRandom    bool
+		expectedError     error
+	}{
+		{
+			name:              "1.1 counter",
+			optNeg:            true,
+			joinNonce:         11,
+			lastJoinNonce:     10,
+			expectedJoinNonce: 11,
+		},
+		{
+			name:          "1.1 counter regression",
+			optNeg:        true,
+			joinNonce:     10,
+			lastJoinNonce: 10,
+			expectedError: ErrJoinNonceRegression,
+		},
+		{
+			name:          "1.1 rejoin-request regression",
+			rejoin:        true,
+			joinNonce:     9,
+			lastJoinNonce: 10,
+			expectedError: ErrJoinNonceRegression,
+		},
+		{
+			name:              "1.1 unknown last join-nonce",
+			optNeg:            true,
+			lastJoinNonce:     -1,
+			expectedJoinNonce: 0,
+		},
+		{
+			name:              "1.0 not validated",
+			joinNonce:         5,
+			lastJoinNonce:     10,
+			expectedJoinNonce: 5,
+		},
+		{
+			name:          "1.0 counter policy regression",
+			policy:        JoinNonceCounter,
+			joinNonce:     5,
+			lastJoinNonce: 10,
+			expectedError: ErrJoinNonceRegression,
+		},
+		{
+			name:           "1.0 random policy",
+			policy:         JoinNonceRandom,
+			joinNonce:      5,
+			lastJoinNonce:  10,
+			expectedRandom: true,
+		},
+		{
+			name:          "1.1 random policy regression",
+			policy:        JoinNonceRandom,
+			optNeg:        true,
+			joinNonce:     5,
+			lastJoinNonce: 10,
+			expectedError: ErrJoinNonceRegression,
+		},
+		{
+			name:          "overflow",
+			optNeg:        true,
+			joinNonce:     1 << 24,
+			lastJoinNonce: -1,
+			expectedError: lorawan.ErrJoinNonceOverflow,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			ctx := context{
+				joinType:        lorawan.JoinRequestType,
+				deviceKeys:      DeviceKeys{JoinNonce: tst.joinNonce},
+				joinNoncePolicy: tst.policy,
+				lastJoinNonce:   tst.lastJoinNonce,
+			}
+			ctx.joinReqPayload.DLSettings.OptNeg = tst.optNeg
+			if tst.rejoin {
+				ctx.joinType = lorawan.RejoinRequestType0
+			}
+
+			err := setJoinNonce(&ctx)
+			if tst.expectedError != nil {
+				assert.True(errors.Is(err, tst.expectedError))
+				assert.Equal(backend.ActivationDisallowed, getResultError(err, 0).ResultCode)
+				return
+			}
+			assert.NoError(err)
+			assert.LessOrEqual(ctx.joinNonce, lorawan.MaxJoinNonce)
+			if !tst.expectedRandom {
+				assert.Equal(tst.expectedJoinNonce, ctx.joinNonce)
+			}
+		})
+	}
+
+	t.Run("invalid policy", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := NewHandler(HandlerConfig{
+			GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) {
+				return DeviceKeys{}, nil
+			},
+			JoinNoncePolicy: 3,
+		})
+		assert.EqualError(err, "backend/joinserver: invalid join-nonce policy: JoinNoncePolicy(3)")
+	})
+}
+
+func TestAppSKeyReq(t *testing.T) {
+	assert := require.New(t)
+
+	devEUI := lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+	appSKey := lorawan.AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+	kek := []byte{8, 7, 6, 5, 4, 3, 2, 1, 8, 7, 6, 5, 4, 3, 2, 1}
+	appSKeys := make(map[string]lorawan.AES128Key)
+
+	config := HandlerConfig{
+		GetDeviceKeysByDevEUIFunc: func(devEUI lorawan.EUI64) (DeviceKeys, error) {
+			return DeviceKeys{}, ErrDevEUINotFound
+		},
+		GetKEKByLabelFunc: func(label string) ([]byte, error) {
+			if label == "as-kek" {
+				return kek, nil
+			}
+			return nil, nil
+		},
+		GetASKEKLabelFunc: func(senderID string, devEUI lorawan.EUI64) (string, error) {
+			return "as-kek", nil
+		},
+		StoreAppSKeyFunc: func(devEUI lorawan.EUI64, sessionKeyID []byte, appSKey lorawan.AES128Key) error {
+			appSKeys[devEUI.String()+fmt.Sprintf("%x", sessionKeyID)] = appSKey
+			return nil
+		},
+		GetAppSKeyFunc: func(devEUI lorawan.EUI64, sessionKeyID []byte) (lorawan.AES128Key, error) {
+			key, ok := appSKeys[devEUI.String()+fmt.Sprintf("%x", sessionKeyID)]
+			if !ok {
+				return key, ErrSessionKeyIDNotFound
+			}
+			return key, nil
+		},
+		NetIDs: []lorawan.NetID{{1, 2, 3}},
+	}
+
+	// store the AppSKey as done on join
+	ctx := context{
+		devEUI:           devEUI,
+		appSKey:          appSKey,
+		storeAppSKeyFunc: config.StoreAppSKeyFunc,
+	}
+	assert.NoError(setSessionKeyID(&ctx))
+	assert.Len(ctx.sessionKeyID, SessionKeyIDLength)
+	assert.Len(appSKeys, 1)
+
+	h, err := NewHandler(config)
+	assert.NoError(err)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		devEUI       lorawan.EUI64
+		sessionKeyID backend.HEXBytes
+		resultCode   backend.ResultCode
+		description  string
+		httpStatus   int
+	}{
+		{
+			name:         "valid SessionKeyID",
+			devEUI:       devEUI,
+			sessionKeyID: ctx.sessionKeyID,
+			resultCode:   backend.Success,
+			httpStatus:   http.StatusOK,
+		},
+		{
+			name:         "unknown SessionKeyID",
+			devEUI:       devEUI,
+			sessionKeyID: backend.HEXBytes{1, 2, 3},
+			resultCode:   backend.UnknownDevEUI,
+			description:  "session-key id does not exist",
+			httpStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "invalid DevEUI",
+			sessionKeyID: ctx.sessionKeyID,
+			resultCode:   backend.MalformedRequest,
+			description:  "lorawan: EUI must not be 0",
+			httpStatus:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := json.Marshal(backend.AppSKeyReqPayload{
+				BasePayload: backend.BasePayload{
+					ProtocolVersion: backend.ProtocolVersion1_0,
+					SenderID:        "as.example.com",
+					ReceiverID:      "0807060504030201",
+					TransactionID:   1234,
+					MessageType:     backend.AppSKeyReq,
+				},
+				DevEUI:       tst.devEUI,
+				SessionKeyID: tst.sessionKeyID,
+			})
+			assert.NoError(err)
+
+			resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+			assert.NoError(err)
+			defer resp.Body.Close()
+
+			assert.Equal(tst.httpStatus, resp.StatusCode)
+
+			var ans backend.AppSKeyAnsPayload
+			assert.NoError(json.NewDecoder(resp.Body).Decode(&ans))
+			assert.Equal(backend.BasePayload{
+				ProtocolVersion: backend.ProtocolVersion1_0,
+				SenderID:        "0807060504030201",
+				ReceiverID:      "as.example.com",
+				TransactionID:   1234,
+				MessageType:     backend.AppSKeyAns,
+			}, ans.BasePayload)
+			assert.Equal(backend.Result{ResultCode: tst.resultCode, Description: tst.description}, ans.Result)
+			assert.Equal(tst.devEUI, ans.DevEUI)
+			assert.Equal(tst.sessionKeyID, ans.SessionKeyID)
+
+			if tst.resultCode == backend.Success {
+				assert.Equal("as-kek", ans.AppSKey.KEKLabel)
+				key, err := ans.AppSKey.Unwrap(kek)
+				assert.NoError(err)
+				assert.Equal(appSKey, key)
+			} else {
+				assert.Nil(ans.AppSKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package joinserver
+
+import (
+	"fmt"
+
+	"github.com/brocaar/lorawan"
+)
+
+// KEK holds a key encryption key and its epoch. During a KEK rotation,
+// multiple KEKs (e.g. the old and the new KEK) can be configured for the
+// same label, each with a different epoch.
+type KEK struct {
+	Epoch int
+	KEK   []byte
+}
+
+// getKEK returns the KEK used to wrap the session-keys for the given SenderID,
+// KEK label and DevEUI. When GetKEKsByLabelFunc is set, the KEK is selected by
+// the epoch returned by GetKEKEpochFunc, or else the KEK with the highest
+// epoch is used. The KEKUsedFunc (if set) is called with the selected KEK.
+func (h *handler) getKEK(senderID, label string, devEUI lorawan.EUI64) ([]byte, error) {
+	if h.config.GetKEKsByLabelFunc == nil {
+		kek, err := h.config.GetKEKByLabelFunc(label)
+		if err != nil {
+			return nil, err
+		}
+		if len(kek) != 0 {
+			h.kekUsed(senderID, label, devEUI, 0)
+		}
+		return kek, nil
+	}
+
+	keks, err := h.config.GetKEKsByLabelFunc(label)
+	if err != nil {
+		return nil, err
+	}
+	if len(keks) == 0 {
+		return nil, nil
+	}
+
+	var kek *KEK
+	if h.config.GetKEKEpochFunc != nil {
+		epoch, err := h.config.GetKEKEpochFunc(senderID, label, devEUI)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range keks {
+			if keks[i].Epoch == epoch {
+				kek = &keks[i]
+				break
+			}
+		}
+		if kek == nil {
+			return nil, fmt.Errorf("no KEK with epoch %d for label %s", epoch, label)
+		}
+	} else {
+		for i := range keks {
+			if kek == nil || keks[i].Epoch > kek.Epoch {
+				kek = &keks[i]
+			}
+		}
+	}
+
+	h.kekUsed(senderID, label, devEUI, kek.Epoch)
+	return kek.KEK, nil
+}
+
+func (h *handler) kekUsed(senderID, label string, devEUI lorawan.EUI64, epoch int) {
+	if h.config.KEKUsedFunc != nil {
+		h.config.KEKUsedFunc(senderID, label, devEUI, epoch)
+	}
+}
@@ -0,0 +1,71 @@
+package joinserver
+
+import (
+	"fmt"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/crypto"
+)
+
+// KeyDeriver derives the device root keys (NwkKey and AppKey) for the given
+// DevEUI. This is used for devices which have been provisioned by the
+// manufacturer with diversified keys (e.g. derived from a factory root key),
+// so that the keys do not need to be stored per device.
+type KeyDeriver interface {
+	DeriveKeys(devEUI lorawan.EUI64) (nwkKey lorawan.AES128Key, appKey lorawan.AES128Key, err error)
+}
+
+// CMACKeyDeriver implements a KeyDeriver using AES-CMAC under a root key:
+//
+//	NwkKey = aes128_cmac(RootKey, 0x01 | DevEUI)
+//	AppKey = aes128_cmac(RootKey, 0x02 | DevEUI)
+//
+// The DevEUI is encoded MSB first (as printed on the device label).
+type CMACKeyDeriver struct {
+	RootKey lorawan.AES128Key
+}
+
+// DeriveKeys derives the NwkKey and AppKey for the given DevEUI.
+func (d CMACKeyDeriver) DeriveKeys(devEUI lorawan.EUI64) (lorawan.AES128Key, lorawan.AES128Key, error) {
+	nwkKey, err := d.derive(0x01, devEUI)
+	if err != nil {
+		return lorawan.AES128Key{}, lorawan.AES128Key{}, err
+	}
+
+	appKey, err := d.derive(0x02, devEUI)
+	if err != nil {
+		return lorawan.AES128Key{}, lorawan.AES128Key{}, err
+	}
+
+	return nwkKey, appKey, nil
+}
+
+func (d CMACKeyDeriver) derive(typ byte, devEUI lorawan.EUI64) (lorawan.AES128Key, error) {
+	return crypto.CMAC(d.RootKey, []byte{typ}, devEUI[:])
+}
+
+// DerivedDeviceKeysFunc returns a function which can be used as
+// HandlerConfig.GetDeviceKeysByDevEUIFunc, deriving the keys using the
+// given KeyDeriver. The getJoinNonceFunc must return the JoinNonce that
+// must be used for the join-accept of the given DevEUI (and must return
+// ErrDevEUINotFound when the device does not exist).
+func DerivedDeviceKeysFunc(d KeyDeriver, getJoinNonceFunc func(devEUI lorawan.EUI64) (int, error)) func(devEUI lorawan.EUI64) (DeviceKeys, error) {
+	return func(devEUI lorawan.EUI64) (DeviceKeys, error) {
+		joinNonce, err := getJoinNonceFunc(devEUI)
+		if err != nil {
+			return DeviceKeys{}, err
+		}
+
+		nwkKey, appKey, err := d.DeriveKeys(devEUI)
+		if err != nil {
+			return DeviceKeys{}, fmt.Errorf("derive keys error: %w", err)
+		}
+
+		return DeviceKeys{
+			DevEUI:    devEUI,
+			NwkKey:    nwkKey,
+			AppKey:    appKey,
+			JoinNonce: joinNonce,
+		}, nil
+	}
+}
@@ -0,0 +1,78 @@
+package joinserver
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestCMACKeyDeriver(t *testing.T) {
+	assert := require.New(t)
+
+	d := CMACKeyDeriver{RootKey: lorawan.AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}}
+
+	nwkKey, appKey, err := d.DeriveKeys(lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8})
+	assert.NoError(err)
+
+	var expNwkKey, expAppKey lorawan.AES128Key
+	assert.NoError(expNwkKey.UnmarshalText([]byte("bd72e24dac9acc5fd56d5c6860862cef")))
+	assert.NoError(expAppKey.UnmarshalText([]byte("038a923efd2bb01379ac2d134debd000")))
+	assert.Equal(expNwkKey, nwkKey)
+	assert.Equal(expAppKey, appKey)
+
+	nwkKey2, _, err := d.DeriveKeys(lorawan.EUI64{8, 7, 6, 5, 4, 3, 2, 1})
+	assert.NoError(err)
+	assert.NotEqual(nwkKey, nwkKey2)
+}
+
+func TestDerivedDeviceKeysFunc(t *testing.T) {
+	d := CMACKeyDeriver{RootKey: lorawan.AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}}
+	known := lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+
+	f := DerivedDeviceKeysFunc(d, func(devEUI lorawan.EUI64) (int, error) {
+		if devEUI == known {
+			return 10, nil
+		}
+		return 0, ErrDevEUINotFound
+	})
+
+	t.Run("Known device", func(t *testing.T) {
+		assert := require.New(t)
+
+		nwkKey, appKey, err := d.DeriveKeys(known)
+		assert.NoError(err)
+
+		dk, err := f(known)
+		assert.NoError(err)
+		assert.Equal(DeviceKeys{
+			DevEUI:    known,
+			NwkKey:    nwkKey,
+			AppKey:    appKey,
+			JoinNonce: 10,
+		}, dk)
+	})
+
+	t.Run("Unknown device", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := f(lorawan.EUI64{8, 7, 6, 5, 4, 3, 2, 1})
+		assert.Equal(ErrDevEUINotFound, err)
+	})
+
+	t.Run("Batch import", func(t *testing.T) {
+		assert := require.New(t)
+
+		nwkKey, appKey, err := d.DeriveKeys(known)
+		assert.NoError(err)
+
+		keys, err := ImportDeviceKeys(bytes.NewBufferString(`{"devEUI":"0102030405060708","joinNonce":5}`+"\n"), BatchOptions{KeyDeriver: d})
+		assert.NoError(err)
+		assert.Equal([]DeviceKeys{{DevEUI: known, NwkKey: nwkKey, AppKey: appKey, JoinNonce: 5}}, keys)
+
+		_, err = ImportDeviceKeys(bytes.NewBufferString(`{"devEUI":"0102030405060708","joinNonce":5}`+"\n"), BatchOptions{})
+		assert.EqualError(err, "line 1: nwkKey: key is missing")
+	})
+}
@@ -13,10 +13,11 @@ var rejoinTasks = []func(*context) error{
 	setRejoinContext,
 	setJoinNonce,
 	setSessionKeys,
+	setSessionKeyID,
 	createRejoinAnsPayload,
 }
 
-func handleRejoinRequestWrapper(rejoinReqPL backend.RejoinReqPayload, dk DeviceKeys, asKEKLabel string, asKEK []byte, nsKEKLabel string, nsKEK []byte) backend.RejoinAnsPayload {
+func handleRejoinRequestWrapper(rejoinReqPL backend.RejoinReqPayload, dk DeviceKeys, asKEKLabel string, asKEK []byte, nsKEKLabel string, nsKEK []byte, joinNoncePolicy JoinNoncePolicy, lastJoinNonce int, storeAppSKeyFunc func(devEUI lorawan.EUI64, sessionKeyID []byte, appSKey lorawan.AES128Key) error) backend.RejoinAnsPayload {
 	basePayload := backend.BasePayload{
 		ProtocolVersion: backend.ProtocolVersion1_0,
 		SenderID:        rejoinReqPL.ReceiverID,
@@ -25,23 +26,16 @@ func handleRejoinRequestWrapper(rejoinReqPL backend.RejoinReqPayload, dk DeviceK
 		MessageType:     backend.RejoinAns,
 	}
 
-	rjaPL, err := handleRejoinRequest(rejoinReqPL, dk, asKEKLabel, asKEK, nsKEKLabel, nsKEK)
+	rjaPL, err := handleRejoinRequest(rejoinReqPL, dk, asKEKLabel, asKEK, nsKEKLabel, nsKEK, joinNoncePolicy, lastJoinNonce, storeAppSKeyFunc)
 	if err != nil {
-		var resCode backend.ResultCode
-
-		switch errors.Cause(err) {
-		case ErrInvalidMIC:
-			resCode = backend.MICFailed
-		default:
-			resCode = backend.Other
-		}
+		resErr := getResultError(err, 0)
+		basePayload.VSExtension = resErr.VSExtension
 
 		rjaPL = backend.RejoinAnsPayload{
 			BasePayloadResult: backend.BasePayloadResult{
-				BasePayload: basePayload,
 				Result: backend.Result{
-					ResultCode:  resCode,
-					Description: err.Error(),
+					ResultCode:  resErr.ResultCode,
+					Description: resErr.Error(),
 				},
 			},
 		}
@@ -51,7 +45,7 @@ func handleRejoinRequestWrapper(rejoinReqPL backend.RejoinReqPayload, dk DeviceK
 	return rjaPL
 }
 
-func handleRejoinRequest(rejoinReqPL backend.RejoinReqPayload, dk DeviceKeys, asKEKLabel string, asKEK []byte, nsKEKLabel string, nsKEK []byte) (backend.RejoinAnsPayload, error) {
+func handleRejoinRequest(rejoinReqPL backend.RejoinReqPayload, dk DeviceKeys, asKEKLabel string, asKEK []byte, nsKEKLabel string, nsKEK []byte, joinNoncePolicy JoinNoncePolicy, lastJoinNonce int, storeAppSKeyFunc func(devEUI lorawan.EUI64, sessionKeyID []byte, appSKey lorawan.AES128Key) error) (backend.RejoinAnsPayload, error) {
 	ctx := context{
 		rejoinReqPayload: rejoinReqPL,
 		deviceKeys:       dk,
@@ -59,6 +53,9 @@ func handleRejoinRequest(rejoinReqPL backend.RejoinReqPayload, dk DeviceKeys, as
 		asKEK:            asKEK,
 		nsKEKLabel:       nsKEKLabel,
 		nsKEK:            nsKEK,
+		joinNoncePolicy:  joinNoncePolicy,
+		lastJoinNonce:    lastJoinNonce,
+		storeAppSKeyFunc: storeAppSKeyFunc,
 	}
 
 	for _, f := range rejoinTasks {
@@ -75,13 +72,17 @@ func setRejoinContext(ctx *context) error {
 		return errors.Wrap(err, "unmarshal phypayload error")
 	}
 
-	if err := ctx.netID.UnmarshalText([]byte(ctx.rejoinReqPayload.SenderID)); err != nil {
+	netID, err := ctx.rejoinReqPayload.ParseSenderID(backend.IDTypeNetID)
+	if err != nil {
 		return errors.Wrap(err, "unmarshal netid error")
 	}
+	ctx.netID = netID.NetID
 
-	if err := ctx.joinEUI.UnmarshalText([]byte(ctx.rejoinReqPayload.ReceiverID)); err != nil {
+	joinEUI, err := ctx.rejoinReqPayload.ParseReceiverID(backend.IDTypeEUI64)
+	if err != nil {
 		return errors.Wrap(err, "unmarshal joineui error")
 	}
+	ctx.joinEUI = joinEUI.EUI64
 
 	switch v := ctx.phyPayload.MACPayload.(type) {
 	case *lorawan.RejoinRequestType02Payload:
@@ -154,7 +155,8 @@ func createRejoinAnsPayload(ctx *context) error {
 				ResultCode: backend.Success,
 			},
 		},
-		PHYPayload: backend.HEXBytes(b),
+		PHYPayload:   backend.HEXBytes(b),
+		SessionKeyID: ctx.sessionKeyID,
 		// TODO: add Lifetime?
 	}
 
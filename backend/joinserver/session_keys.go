@@ -1,12 +1,10 @@
 package joinserver
 
 import (
-	"crypto/aes"
-	"fmt"
-
 	"github.com/pkg/errors"
 
 	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/crypto"
 )
 
 // getFNwkSIntKey returns the FNwkSIntKey.
@@ -42,7 +40,7 @@ func getJSEncKey(nwkKey lorawan.AES128Key, devEUI lorawan.EUI64) (lorawan.AES128
 
 func getSKey(optNeg bool, typ byte, nwkKey lorawan.AES128Key, netID lorawan.NetID, joinEUI lorawan.EUI64, joinNonce lorawan.JoinNonce, devNonce lorawan.DevNonce) (lorawan.AES128Key, error) {
 	var key lorawan.AES128Key
-	b := make([]byte, 16)
+	var b [16]byte
 	b[0] = typ
 
 	netIDB, err := netID.MarshalBinary()
@@ -75,37 +73,18 @@ func getSKey(optNeg bool, typ byte, nwkKey lorawan.AES128Key, netID lorawan.NetI
 		copy(b[7:9], devNonceB)
 	}
 
-	block, err := aes.NewCipher(nwkKey[:])
-	if err != nil {
-		return key, err
-	}
-	if block.BlockSize() != len(b) {
-		return key, fmt.Errorf("block-size of %d bytes is expected", len(b))
-	}
-	block.Encrypt(key[:], b)
-
-	return key, nil
+	return crypto.EncryptBlock(nwkKey, b)
 }
 
 func getJSKey(typ byte, devEUI lorawan.EUI64, nwkKey lorawan.AES128Key) (lorawan.AES128Key, error) {
-	var key lorawan.AES128Key
-	b := make([]byte, 16)
-
+	var b [16]byte
 	b[0] = typ
 
 	devB, err := devEUI.MarshalBinary()
 	if err != nil {
-		return key, err
+		return lorawan.AES128Key{}, err
 	}
 	copy(b[1:9], devB[:])
 
-	block, err := aes.NewCipher(nwkKey[:])
-	if err != nil {
-		return key, err
-	}
-	if block.BlockSize() != len(b) {
-		return key, fmt.Errorf("block-size of %d bytes is expected", len(b))
-	}
-	block.Encrypt(key[:], b)
-	return key, nil
+	return crypto.EncryptBlock(nwkKey, b)
 }
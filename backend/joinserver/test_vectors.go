@@ -0,0 +1,163 @@
+package joinserver
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/backend"
+)
+
+// SessionKeysTestVectorInput contains the root keys and join parameters used
+// to generate the session keys test-vectors.
+type SessionKeysTestVectorInput struct {
+	// NwkKey holds the NwkKey (LoRaWAN 1.1) or AppKey (LoRaWAN 1.0).
+	NwkKey lorawan.AES128Key `json:"nwkKey"`
+
+	// AppKey holds the AppKey (LoRaWAN 1.1 only).
+	AppKey lorawan.AES128Key `json:"appKey"`
+
+	DevEUI    lorawan.EUI64     `json:"devEUI"`
+	JoinEUI   lorawan.EUI64     `json:"joinEUI"`
+	NetID     lorawan.NetID     `json:"netID"`
+	JoinNonce lorawan.JoinNonce `json:"joinNonce"`
+	DevNonce  lorawan.DevNonce  `json:"devNonce"`
+
+	// DevAddr, FCnt, FPort and FRMPayload are used for the example uplink
+	// data frame.
+	DevAddr    lorawan.DevAddr  `json:"devAddr"`
+	FCnt       uint32           `json:"fCnt"`
+	FPort      uint8            `json:"fPort"`
+	FRMPayload backend.HEXBytes `json:"frmPayload"`
+}
+
+// SessionKeysTestVector contains the derived session keys and the example
+// uplink data frame for a single LoRaWAN version.
+type SessionKeysTestVector struct {
+	OptNeg bool                `json:"optNeg"`
+	Keys   lorawan.SessionKeys `json:"keys"`
+
+	// JSIntKey and JSEncKey are only set for LoRaWAN 1.1.
+	JSIntKey *lorawan.AES128Key `json:"jsIntKey,omitempty"`
+	JSEncKey *lorawan.AES128Key `json:"jsEncKey,omitempty"`
+
+	// UplinkDataFrame holds the unconfirmed uplink data frame (PHYPayload),
+	// with the FRMPayload encrypted using the AppSKey (or NwkSEncKey in case
+	// of FPort 0) and the MIC set.
+	UplinkDataFrame backend.HEXBytes `json:"uplinkDataFrame"`
+}
+
+// SessionKeysTestVectors contains the session keys test-vectors for
+// LoRaWAN 1.0.x and LoRaWAN 1.1.
+type SessionKeysTestVectors struct {
+	LoRaWAN1_0 SessionKeysTestVector `json:"lorawan1_0"`
+	LoRaWAN1_1 SessionKeysTestVector `json:"lorawan1_1"`
+}
+
+// GenerateSessionKeysTestVectors derives the session keys for the given
+// input, using the same key derivation as the join-server, and returns these
+// together with an example uplink data frame for both LoRaWAN 1.0.x and
+// LoRaWAN 1.1. This can be used by device manufacturers to validate their
+// implementation.
+func GenerateSessionKeysTestVectors(in SessionKeysTestVectorInput) (SessionKeysTestVectors, error) {
+	var out SessionKeysTestVectors
+	var err error
+
+	out.LoRaWAN1_0, err = generateSessionKeysTestVector(in, false)
+	if err != nil {
+		return out, errors.Wrap(err, "lorawan 1.0 error")
+	}
+
+	out.LoRaWAN1_1, err = generateSessionKeysTestVector(in, true)
+	if err != nil {
+		return out, errors.Wrap(err, "lorawan 1.1 error")
+	}
+
+	return out, nil
+}
+
+func generateSessionKeysTestVector(in SessionKeysTestVectorInput, optNeg bool) (SessionKeysTestVector, error) {
+	out := SessionKeysTestVector{
+		OptNeg: optNeg,
+	}
+	macVersion := lorawan.LoRaWAN1_0
+	appKey := in.NwkKey
+	var err error
+
+	if optNeg {
+		macVersion = lorawan.LoRaWAN1_1
+		appKey = in.AppKey
+
+		jsIntKey, err := getJSIntKey(in.NwkKey, in.DevEUI)
+		if err != nil {
+			return out, errors.Wrap(err, "get js int key error")
+		}
+		jsEncKey, err := getJSEncKey(in.NwkKey, in.DevEUI)
+		if err != nil {
+			return out, errors.Wrap(err, "get js enc key error")
+		}
+		out.JSIntKey = &jsIntKey
+		out.JSEncKey = &jsEncKey
+	}
+
+	out.Keys.FNwkSIntKey, err = getFNwkSIntKey(optNeg, in.NwkKey, in.NetID, in.JoinEUI, in.JoinNonce, in.DevNonce)
+	if err != nil {
+		return out, errors.Wrap(err, "get FNwkSIntKey error")
+	}
+
+	out.Keys.AppSKey, err = getAppSKey(optNeg, appKey, in.NetID, in.JoinEUI, in.JoinNonce, in.DevNonce)
+	if err != nil {
+		return out, errors.Wrap(err, "get AppSKey error")
+	}
+
+	if optNeg {
+		out.Keys.SNwkSIntKey, err = getSNwkSIntKey(optNeg, in.NwkKey, in.NetID, in.JoinEUI, in.JoinNonce, in.DevNonce)
+		if err != nil {
+			return out, errors.Wrap(err, "get SNwkSIntKey error")
+		}
+
+		out.Keys.NwkSEncKey, err = getNwkSEncKey(optNeg, in.NwkKey, in.NetID, in.JoinEUI, in.JoinNonce, in.DevNonce)
+		if err != nil {
+			return out, errors.Wrap(err, "get NwkSEncKey error")
+		}
+	} else {
+		out.Keys.SNwkSIntKey = out.Keys.FNwkSIntKey
+		out.Keys.NwkSEncKey = out.Keys.FNwkSIntKey
+	}
+
+	fPort := in.FPort
+	phy := lorawan.PHYPayload{
+		MHDR: lorawan.MHDR{
+			MType: lorawan.UnconfirmedDataUp,
+			Major: lorawan.LoRaWANR1,
+		},
+		MACPayload: &lorawan.MACPayload{
+			FHDR: lorawan.FHDR{
+				DevAddr: in.DevAddr,
+				FCnt:    in.FCnt,
+			},
+			FPort: &fPort,
+			FRMPayload: []lorawan.Payload{
+				&lorawan.DataPayload{Bytes: in.FRMPayload},
+			},
+		},
+	}
+
+	encKey := out.Keys.AppSKey
+	if fPort == 0 {
+		encKey = out.Keys.NwkSEncKey
+	}
+	if err := phy.EncryptFRMPayload(encKey); err != nil {
+		return out, errors.Wrap(err, "encrypt frmpayload error")
+	}
+
+	if err := phy.SetUplinkDataMIC(macVersion, 0, 0, 0, out.Keys.FNwkSIntKey, out.Keys.SNwkSIntKey); err != nil {
+		return out, errors.Wrap(err, "set uplink data mic error")
+	}
+
+	out.UplinkDataFrame, err = phy.MarshalBinary()
+	if err != nil {
+		return out, errors.Wrap(err, "marshal phypayload error")
+	}
+
+	return out, nil
+}
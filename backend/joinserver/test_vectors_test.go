@@ -0,0 +1,80 @@
+package joinserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestGenerateSessionKeysTestVectors(t *testing.T) {
+	assert := require.New(t)
+
+	// same input as the join-request tests of the JoinServerTestSuite
+	in := SessionKeysTestVectorInput{
+		NwkKey:     lorawan.AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8},
+		DevEUI:     lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+		JoinEUI:    lorawan.EUI64{8, 7, 6, 5, 4, 3, 2, 1},
+		NetID:      lorawan.NetID{1, 2, 3},
+		JoinNonce:  65536,
+		DevNonce:   258,
+		DevAddr:    lorawan.DevAddr{1, 2, 3, 4},
+		FCnt:       10,
+		FPort:      1,
+		FRMPayload: []byte{1, 2, 3, 4},
+	}
+
+	vectors, err := GenerateSessionKeysTestVectors(in)
+	assert.NoError(err)
+
+	t.Run("LoRaWAN 1.0", func(t *testing.T) {
+		assert := require.New(t)
+		v := vectors.LoRaWAN1_0
+		nwkSKey := lorawan.AES128Key{223, 83, 195, 95, 48, 52, 204, 206, 208, 255, 53, 76, 112, 222, 4, 223}
+
+		assert.False(v.OptNeg)
+		assert.Equal(lorawan.SessionKeys{
+			FNwkSIntKey: nwkSKey,
+			SNwkSIntKey: nwkSKey,
+			NwkSEncKey:  nwkSKey,
+			AppSKey:     lorawan.AES128Key{146, 123, 156, 145, 17, 131, 207, 254, 76, 178, 255, 75, 117, 84, 95, 109},
+		}, v.Keys)
+		assert.Nil(v.JSIntKey)
+		assert.Nil(v.JSEncKey)
+
+		var phy lorawan.PHYPayload
+		assert.NoError(phy.UnmarshalBinary(v.UplinkDataFrame))
+		ok, err := phy.ValidateUplinkDataMIC(lorawan.LoRaWAN1_0, 0, 0, 0, nwkSKey, nwkSKey)
+		assert.NoError(err)
+		assert.True(ok)
+		assert.NoError(phy.DecryptFRMPayload(v.Keys.AppSKey))
+		assert.Equal(&lorawan.DataPayload{Bytes: []byte{1, 2, 3, 4}}, phy.MACPayload.(*lorawan.MACPayload).FRMPayload[0])
+	})
+
+	t.Run("LoRaWAN 1.1", func(t *testing.T) {
+		assert := require.New(t)
+		v := vectors.LoRaWAN1_1
+
+		assert.True(v.OptNeg)
+		assert.Equal(lorawan.SessionKeys{
+			FNwkSIntKey: lorawan.AES128Key{83, 127, 138, 174, 137, 108, 121, 224, 21, 209, 2, 208, 98, 134, 53, 78},
+			SNwkSIntKey: lorawan.AES128Key{88, 148, 152, 153, 48, 146, 207, 219, 95, 210, 224, 42, 199, 81, 11, 241},
+			NwkSEncKey:  lorawan.AES128Key{152, 152, 40, 60, 79, 102, 235, 108, 111, 213, 22, 88, 130, 4, 108, 64},
+			AppSKey:     lorawan.AES128Key{1, 98, 18, 21, 209, 202, 8, 254, 191, 12, 96, 44, 194, 173, 144, 250},
+		}, v.Keys)
+
+		jsIntKey, err := getJSIntKey(in.NwkKey, in.DevEUI)
+		assert.NoError(err)
+		assert.Equal(&jsIntKey, v.JSIntKey)
+		assert.NotNil(v.JSEncKey)
+
+		var phy lorawan.PHYPayload
+		assert.NoError(phy.UnmarshalBinary(v.UplinkDataFrame))
+		ok, err := phy.ValidateUplinkDataMIC(lorawan.LoRaWAN1_1, 0, 0, 0, v.Keys.FNwkSIntKey, v.Keys.SNwkSIntKey)
+		assert.NoError(err)
+		assert.True(ok)
+		assert.NoError(phy.DecryptFRMPayload(v.Keys.AppSKey))
+		assert.Equal(&lorawan.DataPayload{Bytes: []byte{1, 2, 3, 4}}, phy.MACPayload.(*lorawan.MACPayload).FRMPayload[0])
+	})
+}
@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"math"
+
+	"github.com/brocaar/lorawan"
+)
+
+// GetLinkCheckAnsPayload returns the LinkCheckAns payload for the uplink
+// received by the given gateways. The Margin is the difference between the
+// best SNR of the uplink and the required SNR (demodulation floor) of the
+// uplink data-rate (see Band.GetRequiredSNRForDataRateIndex). The GwCnt is
+// the number of (unique) gateways which received the uplink.
+func GetLinkCheckAnsPayload(gwInfo []GWInfoElement, requiredSNR float64) lorawan.LinkCheckAnsPayload {
+	var out lorawan.LinkCheckAnsPayload
+	var maxSNR *float64
+	var gwCnt int
+	gatewayIDs := make(map[lorawan.GatewayEUI]struct{})
+
+	for _, gw := range gwInfo {
+		if gw.SNR != nil && (maxSNR == nil || *gw.SNR > *maxSNR) {
+			maxSNR = gw.SNR
+		}
+
+		if gw.ID != nil {
+			if _, ok := gatewayIDs[*gw.ID]; ok {
+				continue
+			}
+			gatewayIDs[*gw.ID] = struct{}{}
+		}
+		gwCnt++
+	}
+
+	if gwCnt > 255 {
+		gwCnt = 255
+	}
+	out.GwCnt = uint8(gwCnt)
+
+	if maxSNR != nil {
+		// 255 is reserved
+		margin := math.Floor(*maxSNR - requiredSNR)
+		if margin > 254 {
+			margin = 254
+		}
+		if margin > 0 {
+			out.Margin = uint8(margin)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/brocaar/lorawan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLinkCheckAnsPayload(t *testing.T) {
+	floatPtr := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		Name        string
+		GWInfo      []GWInfoElement
+		RequiredSNR float64
+		Expected    lorawan.LinkCheckAnsPayload
+	}{
+		{
+			Name:     "no gateways",
+			Expected: lorawan.LinkCheckAnsPayload{},
+		},
+		{
+			Name: "best snr is used",
+			GWInfo: []GWInfoElement{
+				{ID: &lorawan.GatewayEUI{1}, SNR: floatPtr(-5)},
+				{ID: &lorawan.GatewayEUI{2}, SNR: floatPtr(2.5)},
+				{ID: &lorawan.GatewayEUI{3}},
+			},
+			RequiredSNR: -20,
+			Expected:    lorawan.LinkCheckAnsPayload{Margin: 22, GwCnt: 3},
+		},
+		{
+			Name: "duplicate gateways are counted once",
+			GWInfo: []GWInfoElement{
+				{ID: &lorawan.GatewayEUI{1}, SNR: floatPtr(-5)},
+				{ID: &lorawan.GatewayEUI{1}, SNR: floatPtr(-4)},
+			},
+			RequiredSNR: -7.5,
+			Expected:    lorawan.LinkCheckAnsPayload{Margin: 3, GwCnt: 1},
+		},
+		{
+			Name: "snr below demodulation floor",
+			GWInfo: []GWInfoElement{
+				{SNR: floatPtr(-10)},
+			},
+			RequiredSNR: -7.5,
+			Expected:    lorawan.LinkCheckAnsPayload{Margin: 0, GwCnt: 1},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.Expected, GetLinkCheckAnsPayload(tst.GWInfo, tst.RequiredSNR))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package backend
+
+// ResultError defines an error which maps to a Result. The callback functions
+// of the handlers (e.g. joinserver and roamingserver) can return (or wrap) a
+// ResultError to control the exact Result of the answer.
+type ResultError struct {
+	ResultCode ResultCode
+
+	// Description is used as Result description. When empty, the message of
+	// the wrapped error is used.
+	Description string
+
+	// HTTPStatus overrides the HTTP status-code of the answer. When 0, the
+	// handler decides on the status-code.
+	HTTPStatus int
+
+	// VSExtension contains optional vendor-specific detail, which is set in
+	// the answer.
+	VSExtension VSExtension
+
+	// Err contains the (optional) wrapped error.
+	Err error
+}
+
+// NewResultError returns a new ResultError for the given ResultCode and
+// (optional) error.
+func NewResultError(resultCode ResultCode, err error) *ResultError {
+	return &ResultError{
+		ResultCode: resultCode,
+		Err:        err,
+	}
+}
+
+// Error implements the error interface.
+func (e *ResultError) Error() string {
+	if e.Description != "" {
+		return e.Description
+	}
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return string(e.ResultCode)
+}
+
+// Unwrap returns the wrapped error.
+func (e *ResultError) Unwrap() error {
+	return e.Err
+}
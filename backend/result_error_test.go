@@ -0,0 +1,28 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultError(t *testing.T) {
+	assert := require.New(t)
+	boom := errors.New("boom")
+
+	err := NewResultError(UnknownDevEUI, boom)
+	assert.Equal("boom", err.Error())
+	assert.True(errors.Is(err, boom))
+
+	err.Description = "device does not exist"
+	assert.Equal("device does not exist", err.Error())
+
+	err = NewResultError(ActivationDisallowed, nil)
+	assert.Equal("ActivationDisallowed", err.Error())
+
+	var re *ResultError
+	assert.True(errors.As(fmt.Errorf("get device error: %w", err), &re))
+	assert.Equal(ActivationDisallowed, re.ResultCode)
+}
@@ -0,0 +1,42 @@
+package roamingserver
+
+import (
+	"errors"
+
+	"github.com/brocaar/lorawan/backend"
+)
+
+// Errors
+var (
+	ErrUnknownDevAddr = errors.New("devaddr does not exist")
+	ErrUnknownDevEUI  = errors.New("deveui does not exist")
+)
+
+// getResultError maps the given error to a ResultError. When the error does
+// not specify a HTTP status-code, the given status-code is used.
+func getResultError(err error, httpStatus int) *backend.ResultError {
+	var out backend.ResultError
+
+	var re *backend.ResultError
+	switch {
+	case errors.As(err, &re):
+		out = *re
+	case errors.Is(err, ErrUnknownDevAddr):
+		out = backend.ResultError{ResultCode: backend.UnknownDevAddr, Err: err}
+	case errors.Is(err, ErrUnknownDevEUI):
+		out = backend.ResultError{ResultCode: backend.UnknownDevEUI, Err: err}
+	default:
+		out = backend.ResultError{ResultCode: backend.Other, Err: err}
+	}
+
+	if out.HTTPStatus == 0 {
+		out.HTTPStatus = httpStatus
+	}
+
+	// use the message of the (outer) error in case it has been wrapped
+	if out.Description == "" {
+		out.Description = err.Error()
+	}
+
+	return &out
+}
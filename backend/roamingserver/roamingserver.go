@@ -0,0 +1,230 @@
+// Package roamingserver provides a http.Handler interface which implements
+// the roaming API (the fNS, sNS and hNS side) as specified by the LoRaWAN
+// Backend Interfaces.
+package roamingserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/brocaar/lorawan/backend"
+)
+
+// HandlerConfig holds the roaming-server handler configuration. Requests of
+// which the callback function is not set are rejected. The callback
+// functions only need to set the message-type specific fields of the answer,
+// the BasePayload is set by the handler. When the Result of the answer is
+// not set, it is set to Success. The callback functions can return (or wrap)
+// a backend.ResultError to control the Result of the answer.
+type HandlerConfig struct {
+	Logger *log.Logger
+
+	PRStartReqFunc  func(pl backend.PRStartReqPayload) (backend.PRStartAnsPayload, error)
+	PRStopReqFunc   func(pl backend.PRStopReqPayload) (backend.PRStopAnsPayload, error)
+	XmitDataReqFunc func(pl backend.XmitDataReqPayload) (backend.XmitDataAnsPayload, error)
+	HomeNSReqFunc   func(pl backend.HomeNSReqPayload) (backend.HomeNSAnsPayload, error)
+	ProfileReqFunc  func(pl backend.ProfileReqPayload) (backend.ProfileAnsPayload, error)
+}
+
+type handler struct {
+	config HandlerConfig
+	log    *log.Logger
+}
+
+// NewHandler creates a new roaming-server handler.
+func NewHandler(config HandlerConfig) (http.Handler, error) {
+	if config.PRStartReqFunc == nil && config.PRStopReqFunc == nil && config.XmitDataReqFunc == nil && config.HomeNSReqFunc == nil && config.ProfileReqFunc == nil {
+		return nil, errors.New("backend/roamingserver: at least one request function must be set")
+	}
+
+	h := handler{
+		config: config,
+		log:    config.Logger,
+	}
+
+	if h.log == nil {
+		h.log = &log.Logger{
+			Out: ioutil.Discard,
+		}
+	}
+
+	return &h, nil
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var basePL backend.BasePayload
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.returnError(w, http.StatusInternalServerError, backend.Other, "read body error")
+		return
+	}
+
+	err = json.Unmarshal(b, &basePL)
+	if err != nil {
+		h.returnError(w, http.StatusBadRequest, backend.MalformedRequest, err.Error())
+		return
+	}
+
+	h.log.WithFields(log.Fields{
+		"message_type":   basePL.MessageType,
+		"sender_id":      basePL.SenderID,
+		"receiver_id":    basePL.ReceiverID,
+		"transaction_id": basePL.TransactionID,
+	}).Info("backend/roamingserver: request received")
+
+	switch {
+	case basePL.MessageType == backend.PRStartReq && h.config.PRStartReqFunc != nil:
+		h.handlePRStartReq(w, b)
+	case basePL.MessageType == backend.PRStopReq && h.config.PRStopReqFunc != nil:
+		h.handlePRStopReq(w, b)
+	case basePL.MessageType == backend.XmitDataReq && h.config.XmitDataReqFunc != nil:
+		h.handleXmitDataReq(w, b)
+	case basePL.MessageType == backend.HomeNSReq && h.config.HomeNSReqFunc != nil:
+		h.handleHomeNSReq(w, b)
+	case basePL.MessageType == backend.ProfileReq && h.config.ProfileReqFunc != nil:
+		h.handleProfileReq(w, b)
+	default:
+		h.returnError(w, http.StatusBadRequest, backend.Other, fmt.Sprintf("unsupported MessageType: %s", basePL.MessageType))
+	}
+}
+
+func (h *handler) handlePRStartReq(w http.ResponseWriter, b []byte) {
+	var req backend.PRStartReqPayload
+	if err := json.Unmarshal(b, &req); err != nil {
+		h.returnError(w, http.StatusBadRequest, backend.MalformedRequest, err.Error())
+		return
+	}
+
+	ans, err := h.config.PRStartReqFunc(req)
+	if err != nil {
+		ans = backend.PRStartAnsPayload{}
+	}
+
+	h.returnAnswer(w, req.BasePayload, backend.PRStartAns, &ans.BasePayloadResult, err, &ans)
+}
+
+func (h *handler) handlePRStopReq(w http.ResponseWriter, b []byte) {
+	var req backend.PRStopReqPayload
+	if err := json.Unmarshal(b, &req); err != nil {
+		h.returnError(w, http.StatusBadRequest, backend.MalformedRequest, err.Error())
+		return
+	}
+
+	ans, err := h.config.PRStopReqFunc(req)
+	if err != nil {
+		ans = backend.PRStopAnsPayload{}
+	}
+
+	h.returnAnswer(w, req.BasePayload, backend.PRStopAns, &ans.BasePayloadResult, err, &ans)
+}
+
+func (h *handler) handleXmitDataReq(w http.ResponseWriter, b []byte) {
+	var req backend.XmitDataReqPayload
+	if err := json.Unmarshal(b, &req); err != nil {
+		h.returnError(w, http.StatusBadRequest, backend.MalformedRequest, err.Error())
+		return
+	}
+
+	ans, err := h.config.XmitDataReqFunc(req)
+	if err != nil {
+		ans = backend.XmitDataAnsPayload{}
+	}
+
+	h.returnAnswer(w, req.BasePayload, backend.XmitDataAns, &ans.BasePayloadResult, err, &ans)
+}
+
+func (h *handler) handleHomeNSReq(w http.ResponseWriter, b []byte) {
+	var req backend.HomeNSReqPayload
+	if err := json.Unmarshal(b, &req); err != nil {
+		h.returnError(w, http.StatusBadRequest, backend.MalformedRequest, err.Error())
+		return
+	}
+
+	ans, err := h.config.HomeNSReqFunc(req)
+	if err != nil {
+		ans = backend.HomeNSAnsPayload{}
+	}
+
+	h.returnAnswer(w, req.BasePayload, backend.HomeNSAns, &ans.BasePayloadResult, err, &ans)
+}
+
+func (h *handler) handleProfileReq(w http.ResponseWriter, b []byte) {
+	var req backend.ProfileReqPayload
+	if err := json.Unmarshal(b, &req); err != nil {
+		h.returnError(w, http.StatusBadRequest, backend.MalformedRequest, err.Error())
+		return
+	}
+
+	ans, err := h.config.ProfileReqFunc(req)
+	if err != nil {
+		ans = backend.ProfileAnsPayload{}
+	}
+
+	h.returnAnswer(w, req.BasePayload, backend.ProfileAns, &ans.BasePayloadResult, err, &ans)
+}
+
+// returnAnswer sets the BasePayload and Result of the answer, given the
+// request BasePayload and the error returned by the callback function and
+// writes the answer (which must contain the given BasePayloadResult).
+func (h *handler) returnAnswer(w http.ResponseWriter, req backend.BasePayload, mt backend.MessageType, base *backend.BasePayloadResult, err error, ans interface{}) {
+	httpStatus := http.StatusOK
+
+	base.BasePayload = backend.BasePayload{
+		ProtocolVersion: backend.ProtocolVersion1_0,
+		SenderID:        req.ReceiverID,
+		ReceiverID:      req.SenderID,
+		TransactionID:   req.TransactionID,
+		MessageType:     mt,
+	}
+
+	if err != nil {
+		resErr := getResultError(err, http.StatusOK)
+		httpStatus = resErr.HTTPStatus
+		base.BasePayload.VSExtension = resErr.VSExtension
+		base.Result = backend.Result{
+			ResultCode:  resErr.ResultCode,
+			Description: resErr.Error(),
+		}
+	} else if base.Result.ResultCode == "" {
+		base.Result.ResultCode = backend.Success
+	}
+
+	h.log.WithFields(log.Fields{
+		"message_type":   base.BasePayload.MessageType,
+		"sender_id":      base.BasePayload.SenderID,
+		"receiver_id":    base.BasePayload.ReceiverID,
+		"transaction_id": base.BasePayload.TransactionID,
+		"result_code":    base.Result.ResultCode,
+	}).Info("backend/roamingserver: sending response")
+
+	h.returnPayload(w, httpStatus, ans)
+}
+
+func (h *handler) returnError(w http.ResponseWriter, code int, resultCode backend.ResultCode, msg string) {
+	h.log.WithFields(log.Fields{
+		"error": msg,
+	}).Error("backend/roamingserver: error handling request")
+
+	h.returnPayload(w, code, backend.Result{
+		ResultCode:  resultCode,
+		Description: msg,
+	})
+}
+
+func (h *handler) returnPayload(w http.ResponseWriter, code int, pl interface{}) {
+	w.WriteHeader(code)
+
+	b, err := json.Marshal(pl)
+	if err != nil {
+		h.log.WithError(err).Error("backend/roamingserver: marshal json error")
+		return
+	}
+
+	w.Write(b)
+}
@@ -0,0 +1,134 @@
+package roamingserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/backend"
+)
+
+func TestHandler(t *testing.T) {
+	assert := require.New(t)
+
+	_, err := NewHandler(HandlerConfig{})
+	assert.EqualError(err, "backend/roamingserver: at least one request function must be set")
+
+	lifetime := 60
+	h, err := NewHandler(HandlerConfig{
+		PRStartReqFunc: func(pl backend.PRStartReqPayload) (backend.PRStartAnsPayload, error) {
+			if len(pl.PHYPayload) == 0 {
+				return backend.PRStartAnsPayload{}, ErrUnknownDevAddr
+			}
+			return backend.PRStartAnsPayload{Lifetime: &lifetime}, nil
+		},
+		PRStopReqFunc: func(pl backend.PRStopReqPayload) (backend.PRStopAnsPayload, error) {
+			return backend.PRStopAnsPayload{}, &backend.ResultError{
+				ResultCode:  backend.Deferred,
+				Description: "try again later",
+				VSExtension: backend.VSExtension{VendorID: backend.HEXBytes{1, 2, 3}},
+			}
+		},
+		XmitDataReqFunc: func(pl backend.XmitDataReqPayload) (backend.XmitDataAnsPayload, error) {
+			return backend.XmitDataAnsPayload{}, fmt.Errorf("enqueue error: %w", backend.NewResultError(backend.XmitFailed, nil))
+		},
+		HomeNSReqFunc: func(pl backend.HomeNSReqPayload) (backend.HomeNSAnsPayload, error) {
+			return backend.HomeNSAnsPayload{HNetID: lorawan.NetID{1, 2, 3}}, nil
+		},
+	})
+	assert.NoError(err)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	client, err := backend.NewClient(backend.ClientConfig{
+		SenderID:   "010203",
+		ReceiverID: "030201",
+		Server:     server.URL,
+	})
+	assert.NoError(err)
+
+	t.Run("PRStartReq", func(t *testing.T) {
+		assert := require.New(t)
+
+		ans, err := client.PRStartReq(context.Background(), backend.PRStartReqPayload{
+			BasePayload: backend.BasePayload{TransactionID: 1234},
+			PHYPayload:  backend.HEXBytes{1, 2, 3},
+		})
+		assert.NoError(err)
+		assert.Equal(backend.PRStartAnsPayload{
+			BasePayloadResult: backend.BasePayloadResult{
+				BasePayload: backend.BasePayload{
+					ProtocolVersion: backend.ProtocolVersion1_0,
+					SenderID:        "030201",
+					ReceiverID:      "010203",
+					TransactionID:   1234,
+					MessageType:     backend.PRStartAns,
+				},
+				Result: backend.Result{ResultCode: backend.Success},
+			},
+			Lifetime: &lifetime,
+		}, ans)
+	})
+
+	t.Run("PRStartReq unknown DevAddr", func(t *testing.T) {
+		assert := require.New(t)
+
+		ans, err := client.PRStartReq(context.Background(), backend.PRStartReqPayload{})
+		assert.Error(err)
+		assert.Equal(backend.Result{ResultCode: backend.UnknownDevAddr, Description: "devaddr does not exist"}, ans.Result)
+		assert.Nil(ans.Lifetime)
+	})
+
+	t.Run("PRStopReq backend.ResultError", func(t *testing.T) {
+		assert := require.New(t)
+
+		ans, err := client.PRStopReq(context.Background(), backend.PRStopReqPayload{})
+		assert.Error(err)
+		assert.Equal(backend.Result{ResultCode: backend.Deferred, Description: "try again later"}, ans.Result)
+		assert.Equal(backend.VSExtension{VendorID: backend.HEXBytes{1, 2, 3}}, ans.VSExtension)
+	})
+
+	t.Run("XmitDataReq wrapped backend.ResultError", func(t *testing.T) {
+		assert := require.New(t)
+
+		ans, err := client.XmitDataReq(context.Background(), backend.XmitDataReqPayload{})
+		assert.Error(err)
+		assert.Equal(backend.Result{ResultCode: backend.XmitFailed, Description: "enqueue error: XmitFailed"}, ans.Result)
+	})
+
+	t.Run("HomeNSReq", func(t *testing.T) {
+		assert := require.New(t)
+
+		ans, err := client.HomeNSReq(context.Background(), backend.HomeNSReqPayload{})
+		assert.NoError(err)
+		assert.Equal(backend.HomeNSAns, ans.MessageType)
+		assert.Equal(lorawan.NetID{1, 2, 3}, ans.HNetID)
+	})
+
+	t.Run("ProfileReq not supported", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := json.Marshal(backend.ProfileReqPayload{
+			BasePayload: backend.BasePayload{MessageType: backend.ProfileReq},
+		})
+		assert.NoError(err)
+
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+		assert.NoError(err)
+		defer resp.Body.Close()
+
+		assert.Equal(http.StatusBadRequest, resp.StatusCode)
+
+		var res backend.Result
+		assert.NoError(json.NewDecoder(resp.Body).Decode(&res))
+		assert.Equal(backend.Result{ResultCode: backend.Other, Description: "unsupported MessageType: ProfileReq"}, res)
+	})
+}
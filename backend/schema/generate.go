@@ -0,0 +1,285 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/brocaar/lorawan/backend"
+)
+
+// messagePayloads maps the message-types to their payload structs.
+var messagePayloads = map[backend.MessageType]interface{}{
+	backend.JoinReq:     backend.JoinReqPayload{},
+	backend.JoinAns:     backend.JoinAnsPayload{},
+	backend.RejoinReq:   backend.RejoinReqPayload{},
+	backend.RejoinAns:   backend.RejoinAnsPayload{},
+	backend.AppSKeyReq:  backend.AppSKeyReqPayload{},
+	backend.AppSKeyAns:  backend.AppSKeyAnsPayload{},
+	backend.PRStartReq:  backend.PRStartReqPayload{},
+	backend.PRStartAns:  backend.PRStartAnsPayload{},
+	backend.PRStopReq:   backend.PRStopReqPayload{},
+	backend.PRStopAns:   backend.PRStopAnsPayload{},
+	backend.HRStartReq:  backend.HRStartReqPayload{},
+	backend.HRStartAns:  backend.HRStartAnsPayload{},
+	backend.HRStopReq:   backend.HRStopReqPayload{},
+	backend.HRStopAns:   backend.HRStopAnsPayload{},
+	backend.HomeNSReq:   backend.HomeNSReqPayload{},
+	backend.HomeNSAns:   backend.HomeNSAnsPayload{},
+	backend.ProfileReq:  backend.ProfileReqPayload{},
+	backend.ProfileAns:  backend.ProfileAnsPayload{},
+	backend.XmitDataReq: backend.XmitDataReqPayload{},
+	backend.XmitDataAns: backend.XmitDataAnsPayload{},
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// knownTypes contains the types for which the JSON encoding can not be
+	// derived from the Go type.
+	knownTypes = map[reflect.Type]Schema{
+		reflect.TypeOf(backend.HEXBytes{}): {
+			Type:    TypeList{"string"},
+			Pattern: "^(0x)?([0-9a-fA-F]{2})*$",
+		},
+		reflect.TypeOf(backend.ISO8601Time{}): {
+			Type:   TypeList{"string"},
+			Format: "date-time",
+		},
+		reflect.TypeOf(backend.Frequency(0)): {
+			Type:        TypeList{"number"},
+			Description: "frequency in MHz",
+			Minimum:     float64Ptr(0),
+		},
+		reflect.TypeOf(backend.Percentage(0)): {
+			Type:        TypeList{"number"},
+			Description: "percentage as fraction, e.g. 0.1 for 10%",
+			Minimum:     float64Ptr(0),
+			Maximum:     float64Ptr(1),
+		},
+		reflect.TypeOf(json.RawMessage{}): {},
+		reflect.TypeOf(backend.ResultCode("")): {
+			Type: TypeList{"string"},
+			Enum: []interface{}{
+				backend.Success, backend.MICFailed, backend.JoinReqFailed, backend.NoRoamingAgreement,
+				backend.DevRoamingDisallowed, backend.RoamingActDisallowed, backend.ActivationDisallowed,
+				backend.UnknownDevEUI, backend.UnknownDevAddr, backend.UnknownSender, backend.UnknownReceiver,
+				backend.Deferred, backend.XmitFailed, backend.InvalidFPort, backend.InvalidProtocolVersion,
+				backend.StaleDeviceProfile, backend.MalformedRequest, backend.FrameSizeError, backend.Other,
+			},
+		},
+		reflect.TypeOf(backend.RatePolicy("")): {
+			Type: TypeList{"string"},
+			Enum: []interface{}{backend.Drop, backend.Mark},
+		},
+		reflect.TypeOf(backend.RoamingType("")): {
+			Type: TypeList{"string"},
+			Enum: []interface{}{backend.Passive, backend.Handover},
+		},
+		reflect.TypeOf(backend.ClassMode("")): {
+			Type: TypeList{"string"},
+			Enum: []interface{}{backend.ClassModeA, backend.ClassModeB, backend.ClassModeC},
+		},
+	}
+)
+
+// GenerateMessage generates the JSON Schema document for the given
+// message-type.
+func GenerateMessage(mt backend.MessageType) (*Schema, error) {
+	pl, ok := messagePayloads[mt]
+	if !ok {
+		return nil, fmt.Errorf("no payload for message-type: %s", mt)
+	}
+
+	s, err := Generate(pl)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Schema = Draft
+	s.Title = string(mt)
+	if p, ok := s.Properties["MessageType"]; ok {
+		p.Enum = nil
+		p.Const = string(mt)
+	}
+
+	return s, nil
+}
+
+// Generate generates the JSON Schema for the given value, based on its type
+// and the encoding/json rules (struct tags, json.Marshaler and
+// encoding.TextMarshaler implementations). Fields without omitempty are
+// required.
+//
+// For encoding.TextMarshaler types which are not known to this package, a
+// fixed length HEX pattern is set when the zero value encodes to HEX (e.g.
+// lorawan.EUI64).
+func Generate(v interface{}) (*Schema, error) {
+	return generate(reflect.TypeOf(v), nil)
+}
+
+func generate(t reflect.Type, seen []reflect.Type) (*Schema, error) {
+	if s, ok := knownTypes[t]; ok {
+		s.Enum = append([]interface{}(nil), s.Enum...)
+		return &s, nil
+	}
+
+	if t.Kind() == reflect.Ptr {
+		s, err := generate(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+	}
+
+	if t.Implements(textMarshalerType) {
+		return generateText(t), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: TypeList{"boolean"}}, nil
+	case reflect.String:
+		return &Schema{Type: TypeList{"string"}}, nil
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: TypeList{"integer"}}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		bits := t.Bits() - 1
+		return &Schema{
+			Type:    TypeList{"integer"},
+			Minimum: float64Ptr(-math.Exp2(float64(bits))),
+			Maximum: float64Ptr(math.Exp2(float64(bits)) - 1),
+		}, nil
+	case reflect.Uint, reflect.Uint64:
+		return &Schema{Type: TypeList{"integer"}, Minimum: float64Ptr(0)}, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{
+			Type:    TypeList{"integer"},
+			Minimum: float64Ptr(0),
+			Maximum: float64Ptr(math.Exp2(float64(t.Bits())) - 1),
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeList{"number"}}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			// encoding/json encodes []byte as base64 string
+			return &Schema{Type: TypeList{"string"}, Format: "byte"}, nil
+		}
+
+		items, err := generate(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		s := &Schema{Type: TypeList{"array"}, Items: items}
+		if t.Kind() == reflect.Array {
+			s.MinItems = intPtr(t.Len())
+			s.MaxItems = intPtr(t.Len())
+		} else {
+			s = nullable(s)
+		}
+		return s, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map key must be of type string, got: %s", t.Key())
+		}
+		values, err := generate(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(&Schema{Type: TypeList{"object"}, AdditionalProperties: values}), nil
+	case reflect.Struct:
+		for _, st := range seen {
+			if st == t {
+				return nil, fmt.Errorf("recursive type is not supported: %s", t)
+			}
+		}
+		s := &Schema{Type: TypeList{"object"}, Properties: make(map[string]*Schema)}
+		if err := generateStruct(s, t, append(seen, t)); err != nil {
+			return nil, err
+		}
+		sort.Strings(s.Required)
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", t)
+	}
+}
+
+func generateStruct(s *Schema, t reflect.Type, seen []reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// embedded structs without json name are flattened
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			if err := generateStruct(s, f.Type, seen); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fs, err := generate(f.Type, seen)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		s.Properties[name] = fs
+
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return nil
+}
+
+func generateText(t reflect.Type) *Schema {
+	s := &Schema{Type: TypeList{"string"}}
+
+	b, err := reflect.Zero(t).Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil || len(b) == 0 || len(b)%2 != 0 {
+		return s
+	}
+	if _, err := hex.DecodeString(string(b)); err != nil {
+		return s
+	}
+
+	s.Pattern = fmt.Sprintf("^[0-9a-fA-F]{%d}$", len(b))
+	return s
+}
+
+func nullable(s *Schema) *Schema {
+	if len(s.Type) == 0 || s.Type.Contains("null") {
+		return s
+	}
+
+	s.Type = append(s.Type, "null")
+	if len(s.Enum) != 0 {
+		s.Enum = append(s.Enum, nil)
+	}
+	return s
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
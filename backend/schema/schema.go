@@ -0,0 +1,156 @@
+//go:generate go test -run TestSchemas -update-schemas
+
+// Package schema provides JSON Schema documents for the LoRaWAN Backend
+// Interfaces messages and a validator to validate JSON encoded messages
+// against these documents.
+//
+// The documents are generated from the structs of the backend package (using
+// go generate), so that the Go structs remain the single source of truth. The
+// raw documents can be published to integration partners using JSON.
+package schema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/brocaar/lorawan/backend"
+)
+
+// Draft defines the JSON Schema draft used by the generated documents.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+//go:embed schemas/*.json
+var schemasFS embed.FS
+
+var (
+	loadOnce sync.Once
+	loadErr  error
+	schemas  map[backend.MessageType]*Schema
+)
+
+// Schema defines a (subset of a) JSON Schema document.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 TypeList           `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// TypeList defines the JSON Schema type keyword. It is encoded as a string
+// when it contains a single type, else as an array of types.
+type TypeList []string
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t TypeList) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *TypeList) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = TypeList{s}
+		return nil
+	}
+
+	var l []string
+	if err := json.Unmarshal(b, &l); err != nil {
+		return err
+	}
+	*t = TypeList(l)
+	return nil
+}
+
+// Contains returns true when the list contains the given type.
+func (t TypeList) Contains(typ string) bool {
+	for _, v := range t {
+		if v == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON returns the raw JSON Schema document for the given message-type.
+func JSON(mt backend.MessageType) ([]byte, error) {
+	b, err := schemasFS.ReadFile(schemaFilename(mt))
+	if err != nil {
+		return nil, fmt.Errorf("no schema for message-type: %s", mt)
+	}
+	return b, nil
+}
+
+// Get returns the schema for the given message-type.
+func Get(mt backend.MessageType) (*Schema, error) {
+	loadOnce.Do(load)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	s, ok := schemas[mt]
+	if !ok {
+		return nil, fmt.Errorf("no schema for message-type: %s", mt)
+	}
+	return s, nil
+}
+
+// ValidateMessage validates the given JSON encoded backend message against
+// the schema matching its MessageType.
+func ValidateMessage(b []byte) error {
+	var bp struct {
+		MessageType backend.MessageType `json:"MessageType"`
+	}
+	if err := json.Unmarshal(b, &bp); err != nil {
+		return errors.Wrap(err, "unmarshal json error")
+	}
+
+	s, err := Get(bp.MessageType)
+	if err != nil {
+		return err
+	}
+
+	return s.Validate(b)
+}
+
+func load() {
+	schemas = make(map[backend.MessageType]*Schema)
+
+	for mt := range messagePayloads {
+		b, err := JSON(mt)
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		var s Schema
+		if err := json.Unmarshal(b, &s); err != nil {
+			loadErr = errors.Wrapf(err, "unmarshal %s schema error", mt)
+			return
+		}
+		schemas[mt] = &s
+	}
+}
+
+func schemaFilename(mt backend.MessageType) string {
+	return path.Join("schemas", string(mt)+".json")
+}
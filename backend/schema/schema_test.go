@@ -0,0 +1,182 @@
+package schema
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/backend"
+)
+
+var updateSchemas = flag.Bool("update-schemas", false, "update the JSON Schema documents")
+
+func TestSchemas(t *testing.T) {
+	for mt := range messagePayloads {
+		t.Run(string(mt), func(t *testing.T) {
+			assert := require.New(t)
+
+			s, err := GenerateMessage(mt)
+			assert.NoError(err)
+
+			b, err := json.MarshalIndent(s, "", "\t")
+			assert.NoError(err)
+			b = append(b, '\n')
+
+			if *updateSchemas {
+				assert.NoError(ioutil.WriteFile(filepath.FromSlash(schemaFilename(mt)), b, 0644))
+				return
+			}
+
+			// the schemas must be re-generated (go generate) when the
+			// backend structs have changed
+			published, err := JSON(mt)
+			assert.NoError(err)
+			assert.Equal(string(b), string(published))
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	assert := require.New(t)
+
+	s, err := GenerateMessage(backend.JoinReq)
+	assert.NoError(err)
+
+	assert.Equal(Draft, s.Schema)
+	assert.Equal("JoinReq", s.Title)
+	assert.Equal(TypeList{"object"}, s.Type)
+	assert.Equal("JoinReq", s.Properties["MessageType"].Const)
+	assert.Equal("^[0-9a-fA-F]{16}$", s.Properties["DevEUI"].Pattern)
+	assert.Equal("^[0-9a-fA-F]{8}$", s.Properties["DevAddr"].Pattern)
+	assert.Equal("^[0-9a-fA-F]{2}$", s.Properties["DLSettings"].Pattern)
+	assert.Contains(s.Required, "PHYPayload")
+	assert.NotContains(s.Required, "CFList")
+	assert.NotContains(s.Required, "SenderToken")
+
+	s, err = GenerateMessage(backend.ProfileAns)
+	assert.NoError(err)
+	assert.Equal(TypeList{"string", "null"}, s.Properties["RoamingActivationType"].Type)
+	assert.Equal([]interface{}{backend.Passive, backend.Handover, nil}, s.Properties["RoamingActivationType"].Enum)
+
+	_, err = Generate(struct{ C chan int }{})
+	assert.Error(err)
+}
+
+func TestValidateMessage(t *testing.T) {
+	devAddr := lorawan.DevAddr{1, 2, 3, 4}
+	lifetime := 3600
+	freq := 868.1
+	rssi := -120
+	gwID := lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8}
+
+	valid := []interface{}{
+		backend.JoinReqPayload{
+			BasePayload: backend.BasePayload{
+				ProtocolVersion: backend.ProtocolVersion1_0,
+				SenderID:        "010203",
+				ReceiverID:      "0102030405060708",
+				TransactionID:   1234,
+				MessageType:     backend.JoinReq,
+			},
+			MACVersion: "1.0.3",
+			PHYPayload: backend.HEXBytes{1, 2, 3},
+			DevEUI:     lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+			DevAddr:    devAddr,
+			DLSettings: lorawan.DLSettings{RX2DataRate: 3},
+			RxDelay:    1,
+		},
+		backend.JoinAnsPayload{
+			BasePayloadResult: backend.BasePayloadResult{
+				BasePayload: backend.BasePayload{
+					ProtocolVersion: backend.ProtocolVersion1_0,
+					MessageType:     backend.JoinAns,
+				},
+				Result: backend.Result{ResultCode: backend.Success},
+			},
+			PHYPayload: backend.HEXBytes{1, 2, 3},
+			Lifetime:   &lifetime,
+			NwkSKey:    &backend.KeyEnvelope{AESKey: backend.HEXBytes{1, 2, 3}},
+		},
+		backend.PRStartReqPayload{
+			BasePayload: backend.BasePayload{
+				ProtocolVersion: backend.ProtocolVersion1_0,
+				MessageType:     backend.PRStartReq,
+			},
+			PHYPayload: backend.HEXBytes{1, 2, 3},
+			ULMetaData: backend.ULMetaData{
+				DevAddr:  &devAddr,
+				ULFreq:   &freq,
+				RecvTime: backend.ISO8601Time(time.Now()),
+				GWInfo: []backend.GWInfoElement{
+					{ID: &gwID, RSSI: &rssi, DLAllowed: true},
+				},
+			},
+		},
+	}
+
+	for _, pl := range valid {
+		b, err := json.Marshal(pl)
+		require.NoError(t, err)
+		require.NoError(t, ValidateMessage(b), string(b))
+	}
+
+	tests := []struct {
+		Name          string
+		JSON          string
+		ExpectedError string
+	}{
+		{
+			Name:          "unknown message-type",
+			JSON:          `{"MessageType": "FooReq"}`,
+			ExpectedError: "no schema for message-type: FooReq",
+		},
+		{
+			Name:          "missing required property",
+			JSON:          `{"ProtocolVersion": "1.0", "SenderID": "010203", "ReceiverID": "0102030405060708", "TransactionID": 1, "MessageType": "HomeNSReq"}`,
+			ExpectedError: "$: missing required property DevEUI",
+		},
+		{
+			Name:          "invalid pattern",
+			JSON:          `{"ProtocolVersion": "1.0", "SenderID": "010203", "ReceiverID": "0102030405060708", "TransactionID": 1, "MessageType": "HomeNSReq", "DevEUI": "010203"}`,
+			ExpectedError: "$.DevEUI: value does not match pattern ^[0-9a-fA-F]{16}$",
+		},
+		{
+			Name:          "invalid type",
+			JSON:          `{"ProtocolVersion": "1.0", "SenderID": "010203", "ReceiverID": "0102030405060708", "TransactionID": "1", "MessageType": "HomeNSReq", "DevEUI": "0102030405060708"}`,
+			ExpectedError: "$.TransactionID: expected integer, got string",
+		},
+		{
+			Name:          "max value",
+			JSON:          `{"ProtocolVersion": "1.0", "SenderID": "010203", "ReceiverID": "0102030405060708", "TransactionID": 4294967296, "MessageType": "HomeNSReq", "DevEUI": "0102030405060708"}`,
+			ExpectedError: "$.TransactionID: max value is 4.294967295e+09",
+		},
+		{
+			Name:          "invalid enum value",
+			JSON:          `{"ProtocolVersion": "1.0", "SenderID": "010203", "ReceiverID": "0102030405060708", "TransactionID": 1, "MessageType": "HomeNSAns", "HNetID": "010203", "Result": {"ResultCode": "Foo", "Description": ""}}`,
+			ExpectedError: "$.Result.ResultCode: value Foo is not one of [Success MICFailed JoinReqFailed NoRoamingAgreement DevRoamingDisallowed RoamingActDisallowedA ActivationDisallowed UnknownDevEUI UnknownDevAddr UnknownSender UnkownReceiver Deferred XmitFailed InvalidFPort InvalidProtocolVersion StaleDeviceProfile MalformedRequest FrameSizeError Other]",
+		},
+		{
+			Name:          "invalid nested array item",
+			JSON:          `{"ProtocolVersion": "1.0", "SenderID": "010203", "ReceiverID": "0102030405060708", "TransactionID": 1, "MessageType": "PRStartReq", "PHYPayload": "0102", "ULMetaData": {"RecvTime": "2020-01-01T00:00:00Z", "GWInfo": [{"RSSI": -120.5}]}}`,
+			ExpectedError: "$.ULMetaData.GWInfo[0].RSSI: expected integer or null, got number",
+		},
+		{
+			Name:          "invalid date-time",
+			JSON:          `{"ProtocolVersion": "1.0", "SenderID": "010203", "ReceiverID": "0102030405060708", "TransactionID": 1, "MessageType": "PRStartReq", "PHYPayload": "0102", "ULMetaData": {"RecvTime": "yesterday"}}`,
+			ExpectedError: "$.ULMetaData.RecvTime: value is not a valid date-time",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+			assert.EqualError(ValidateMessage([]byte(tst.JSON)), tst.ExpectedError)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var patternCache sync.Map
+
+// ValidationError defines a schema validation error.
+type ValidationError struct {
+	// Path contains the JSON path of the invalid value, e.g. $.GWInfo[0].ID.
+	Path string
+
+	// Message contains the validation error.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Validate validates the given JSON document against the schema. In case of
+// a schema violation, a *ValidationError is returned.
+func (s *Schema) Validate(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return errors.Wrap(err, "unmarshal json error")
+	}
+
+	return s.validate("$", v)
+}
+
+func (s *Schema) validate(p string, v interface{}) error {
+	if len(s.Type) != 0 && !s.Type.Contains(jsonType(v)) {
+		// an integer is also a valid number
+		if !(jsonType(v) == "integer" && s.Type.Contains("number")) {
+			return &ValidationError{Path: p, Message: fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), jsonType(v))}
+		}
+	}
+
+	if s.Const != nil && !jsonEqual(s.Const, v) {
+		return &ValidationError{Path: p, Message: fmt.Sprintf("expected value %v", s.Const)}
+	}
+
+	if len(s.Enum) != 0 {
+		var found bool
+		for _, e := range s.Enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &ValidationError{Path: p, Message: fmt.Sprintf("value %v is not one of %v", v, s.Enum)}
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return s.validateString(p, v)
+	case json.Number:
+		return s.validateNumber(p, v)
+	case []interface{}:
+		return s.validateArray(p, v)
+	case map[string]interface{}:
+		return s.validateObject(p, v)
+	}
+
+	return nil
+}
+
+func (s *Schema) validateString(p string, v string) error {
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(v) {
+			return &ValidationError{Path: p, Message: fmt.Sprintf("value does not match pattern %s", s.Pattern)}
+		}
+	}
+
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return &ValidationError{Path: p, Message: "value is not a valid date-time"}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) validateNumber(p string, v json.Number) error {
+	f, err := v.Float64()
+	if err != nil {
+		return &ValidationError{Path: p, Message: err.Error()}
+	}
+
+	if s.Minimum != nil && f < *s.Minimum {
+		return &ValidationError{Path: p, Message: fmt.Sprintf("min value is %v", *s.Minimum)}
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		return &ValidationError{Path: p, Message: fmt.Sprintf("max value is %v", *s.Maximum)}
+	}
+
+	return nil
+}
+
+func (s *Schema) validateArray(p string, v []interface{}) error {
+	if s.MinItems != nil && len(v) < *s.MinItems {
+		return &ValidationError{Path: p, Message: fmt.Sprintf("min number of items is %d", *s.MinItems)}
+	}
+	if s.MaxItems != nil && len(v) > *s.MaxItems {
+		return &ValidationError{Path: p, Message: fmt.Sprintf("max number of items is %d", *s.MaxItems)}
+	}
+
+	if s.Items != nil {
+		for i, item := range v {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", p, i), item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) validateObject(p string, v map[string]interface{}) error {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			return &ValidationError{Path: p, Message: fmt.Sprintf("missing required property %s", name)}
+		}
+	}
+
+	// sort the keys so that the returned error is deterministic
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		ps, ok := s.Properties[k]
+		if !ok {
+			ps = s.AdditionalProperties
+		}
+		if ps == nil {
+			continue
+		}
+
+		if err := ps.validate(p+"."+k, v[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// jsonEqual compares a (schema) value with a decoded JSON value.
+func jsonEqual(a, b interface{}) bool {
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	var av, bv interface{}
+	if err := json.Unmarshal(ab, &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(bb, &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile pattern error")
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,31 @@
+package backend
+
+// NewXmitDataAnsPayload returns the XmitDataAns for the given (downlink)
+// XmitDataReq and the result of the transmission. A nil txErr results in
+// Success. Any other error, e.g. the semtechudp.TXAckError returned by the
+// gateway, results in XmitFailed, using the error as description.
+func NewXmitDataAnsPayload(req XmitDataReqPayload, txErr error) XmitDataAnsPayload {
+	ans := XmitDataAnsPayload{
+		BasePayloadResult: BasePayloadResult{
+			BasePayload: BasePayload{
+				ProtocolVersion: req.ProtocolVersion,
+				SenderID:        req.ReceiverID,
+				ReceiverID:      req.SenderID,
+				TransactionID:   req.TransactionID,
+				MessageType:     XmitDataAns,
+			},
+			Result: Result{
+				ResultCode: Success,
+			},
+		},
+	}
+
+	if txErr != nil {
+		ans.Result = Result{
+			ResultCode:  XmitFailed,
+			Description: txErr.Error(),
+		}
+	}
+
+	return ans
+}
@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan/semtechudp"
+)
+
+func TestNewXmitDataAnsPayload(t *testing.T) {
+	req := XmitDataReqPayload{
+		BasePayload: BasePayload{
+			ProtocolVersion: ProtocolVersion1_0,
+			SenderID:        "010203",
+			ReceiverID:      "030201",
+			TransactionID:   1234,
+			MessageType:     XmitDataReq,
+		},
+	}
+
+	base := BasePayload{
+		ProtocolVersion: ProtocolVersion1_0,
+		SenderID:        "030201",
+		ReceiverID:      "010203",
+		TransactionID:   1234,
+		MessageType:     XmitDataAns,
+	}
+
+	tests := []struct {
+		Name     string
+		Error    error
+		Expected XmitDataAnsPayload
+	}{
+		{
+			Name: "success",
+			Expected: XmitDataAnsPayload{
+				BasePayloadResult: BasePayloadResult{
+					BasePayload: base,
+					Result:      Result{ResultCode: Success},
+				},
+			},
+		},
+		{
+			Name:  "tx_ack error",
+			Error: semtechudp.NewTXAckError(10, semtechudp.TXAckTooLate),
+			Expected: XmitDataAnsPayload{
+				BasePayloadResult: BasePayloadResult{
+					BasePayload: base,
+					Result: Result{
+						ResultCode:  XmitFailed,
+						Description: "lorawan/semtechudp: too late to program the packet for downlink (token: 10, error: TOO_LATE)",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.Expected, NewXmitDataAnsPayload(req, tst.Error))
+		})
+	}
+}
@@ -5,15 +5,22 @@ package band
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/sensitivity"
 )
 
 const latest = "latest"
 
+// maxDynamicChannels defines the max number of channels that can be defined
+// by bands with a dynamic channel-plan.
+const maxDynamicChannels = 16
+
 // Name defines the band-name type.
 type Name string
 
@@ -39,6 +46,10 @@ const (
 )
 
 // Available ISM bands (deprecated, use the common name).
+//
+// Deprecated: these names are aliases for the common names and are
+// automatically translated by GetConfig. Use Name.CommonName to obtain the
+// common name.
 const (
 	AS_923     Name = "AS_923"
 	AU_915_928 Name = "AU_915_928"
@@ -70,6 +81,122 @@ const (
 	ISM2400 Name = "ISM2400"
 )
 
+// nameAliases maps the deprecated band names to their common name.
+var nameAliases = map[Name]Name{
+	AS_923:     AS923,
+	AU_915_928: AU915,
+	CN_470_510: CN470,
+	CN_779_787: CN779,
+	EU_433:     EU433,
+	EU_863_870: EU868,
+	IN_865_867: IN865,
+	KR_920_923: KR920,
+	US_902_928: US915,
+	RU_864_870: RU864,
+}
+
+// commonNames contains all the common band names.
+var commonNames = []Name{
+	EU868,
+	US915,
+	CN779,
+	EU433,
+	AU915,
+	CN470,
+	AS923,
+	AS923_2,
+	AS923_3,
+	AS923_4,
+	KR920,
+	IN865,
+	RU864,
+	ISM2400,
+}
+
+// frequencyRange defines a frequency range (in Hz).
+type frequencyRange struct {
+	min uint32
+	max uint32
+}
+
+// frequencyRanges contains the frequency range per band (by common name).
+var frequencyRanges = map[Name]frequencyRange{
+	EU868:   {863000000, 870000000},
+	US915:   {902000000, 928000000},
+	CN779:   {779000000, 787000000},
+	EU433:   {433050000, 434790000},
+	AU915:   {915000000, 928000000},
+	CN470:   {470000000, 510000000},
+	AS923:   {915000000, 928000000},
+	AS923_2: {915000000, 928000000},
+	AS923_3: {915000000, 928000000},
+	AS923_4: {915000000, 928000000},
+	KR920:   {920900000, 923300000},
+	IN865:   {865000000, 867000000},
+	RU864:   {864000000, 870000000},
+	ISM2400: {2400000000, 2500000000},
+}
+
+// CommonName returns the common name of the band. In case the name is a
+// (deprecated) alias, the common name it maps to is returned, else the name
+// itself is returned.
+func (n Name) CommonName() Name {
+	if common, ok := nameAliases[n]; ok {
+		return common
+	}
+	return n
+}
+
+// IsDeprecated returns true when the name is a deprecated alias of a common
+// band name.
+func (n Name) IsDeprecated() bool {
+	_, ok := nameAliases[n]
+	return ok
+}
+
+// GetNameAliases returns a map of the deprecated band names to their common
+// name.
+func GetNameAliases() map[Name]Name {
+	out := make(map[Name]Name, len(nameAliases))
+	for k, v := range nameAliases {
+		out[k] = v
+	}
+	return out
+}
+
+// ParseName returns the common band name for the given string. Both the
+// common and the deprecated names are accepted and matching is
+// case-insensitive.
+func ParseName(str string) (Name, error) {
+	for _, n := range commonNames {
+		if strings.EqualFold(str, string(n)) {
+			return n, nil
+		}
+	}
+
+	for alias, n := range nameAliases {
+		if strings.EqualFold(str, string(alias)) {
+			return n, nil
+		}
+	}
+
+	return "", fmt.Errorf("lorawan/band: band %s is undefined", str)
+}
+
+// Detect returns the (common) names of the bands of which the frequency
+// plan covers the given frequency (Hz). As frequency plans might overlap,
+// this can return multiple names. When no band matches, nil is returned.
+func Detect(frequency uint32) []Name {
+	var out []Name
+	for _, n := range commonNames {
+		r := frequencyRanges[n]
+		if frequency >= r.min && frequency <= r.max {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
 // Modulation defines the modulation type.
 type Modulation string
 
@@ -107,6 +234,17 @@ type Channel struct {
 	custom    bool // this channel was configured by the user
 }
 
+// IsEnabled returns true when the channel is enabled.
+func (c Channel) IsEnabled() bool {
+	return c.enabled
+}
+
+// IsCustom returns true when the channel was configured by the user (e.g.
+// using AddChannel), false for the default channels of the band.
+func (c Channel) IsCustom() bool {
+	return c.custom
+}
+
 // Defaults defines the default values defined by a band.
 type Defaults struct {
 	// RX2Frequency defines the fixed frequency for the RX2 receive window
@@ -128,6 +266,120 @@ type Defaults struct {
 	JoinAcceptDelay2 time.Duration
 }
 
+// RXWindowTolerance defines the maximum timing error (+/-) of the
+// end-device when opening a receive window.
+const RXWindowTolerance = 20 * time.Microsecond
+
+// RXWindow defines the timing of a Class-A receive window.
+type RXWindow struct {
+	// Time defines the (nominal) time at which the receive window opens.
+	Time time.Time
+
+	// Earliest and Latest define the time range in which the end-device
+	// opens the receive window (Time -/+ RXWindowTolerance).
+	Earliest time.Time
+	Latest   time.Time
+}
+
+// RXWindows defines the Class-A receive windows following an uplink.
+type RXWindows struct {
+	RX1 RXWindow
+	RX2 RXWindow
+}
+
+func newRXWindow(t time.Time) RXWindow {
+	return RXWindow{
+		Time:     t,
+		Earliest: t.Add(-RXWindowTolerance),
+		Latest:   t.Add(RXWindowTolerance),
+	}
+}
+
+// GetRXDelay returns the RX1 delay for the given RXDelay value, as used by
+// the RXTimingSetupReq mac-command and the join-accept. Both 0 and 1 map to
+// one second.
+func GetRXDelay(rxDelay uint8) time.Duration {
+	if rxDelay == 0 {
+		rxDelay = 1
+	}
+	return time.Duration(rxDelay) * time.Second
+}
+
+// ComputeRXWindows returns the Class-A receive windows for a data uplink,
+// given the uplink time (the end of the uplink transmission) and the RXDelay
+// value (as configured by the RXTimingSetupReq mac-command or join-accept).
+// When the RXDelay is 0, the band ReceiveDelay1 is used. The RX2 window
+// opens ReceiveDelay2 - ReceiveDelay1 after RX1.
+func ComputeRXWindows(uplinkTime time.Time, rxDelay uint8, defaults Defaults) RXWindows {
+	rx1Delay := defaults.ReceiveDelay1
+	if rxDelay != 0 {
+		rx1Delay = GetRXDelay(rxDelay)
+	}
+	rx2Delay := rx1Delay + (defaults.ReceiveDelay2 - defaults.ReceiveDelay1)
+
+	return RXWindows{
+		RX1: newRXWindow(uplinkTime.Add(rx1Delay)),
+		RX2: newRXWindow(uplinkTime.Add(rx2Delay)),
+	}
+}
+
+// ComputeJoinAcceptRXWindows returns the Class-A receive windows for a
+// join-request, given the uplink time (the end of the uplink transmission).
+func ComputeJoinAcceptRXWindows(uplinkTime time.Time, defaults Defaults) RXWindows {
+	return RXWindows{
+		RX1: newRXWindow(uplinkTime.Add(defaults.JoinAcceptDelay1)),
+		RX2: newRXWindow(uplinkTime.Add(defaults.JoinAcceptDelay2)),
+	}
+}
+
+// JoinAcceptRXParameters defines the Class-A receive window parameters used
+// by the end-device for receiving the join-accept.
+type JoinAcceptRXParameters struct {
+	// RX1Delay and RX2Delay define the delay after the join-request
+	// (JOIN_ACCEPT_DELAY1 and JOIN_ACCEPT_DELAY2).
+	RX1Delay time.Duration
+	RX2Delay time.Duration
+
+	RX1Frequency uint32
+	RX1DataRate  int
+
+	RX2Frequency uint32
+	RX2DataRate  int
+}
+
+// GetJoinAcceptRXParameters returns the receive window parameters for the
+// join-accept, given the frequency and data-rate of the join-request. As the
+// end-device has not yet received the DLSettings and RXDelay, these differ
+// from the parameters used for data downlinks:
+//
+//   - RX1 and RX2 use JoinAcceptDelay1 and JoinAcceptDelay2
+//   - RX1 uses a RX1DROffset of 0
+//   - RX2 uses the RX2 frequency and data-rate as defined by the Regional
+//     Parameters, ignoring the RX2 overrides of the band Options
+func GetJoinAcceptRXParameters(b Band, uplinkFrequency uint32, uplinkDR int) (JoinAcceptRXParameters, error) {
+	rx1Freq, err := b.GetRX1FrequencyForUplinkFrequency(uplinkFrequency)
+	if err != nil {
+		return JoinAcceptRXParameters{}, err
+	}
+
+	rx1DR, err := b.GetRX1DataRateIndex(uplinkDR, 0)
+	if err != nil {
+		return JoinAcceptRXParameters{}, err
+	}
+
+	// the join-accept always uses the regional default RX2 parameters
+	defaults := b.GetRegionalDefaults()
+
+	return JoinAcceptRXParameters{
+		RX1Delay:     defaults.JoinAcceptDelay1,
+		RX2Delay:     defaults.JoinAcceptDelay2,
+		RX1Frequency: rx1Freq,
+		RX1DataRate:  rx1DR,
+		RX2Frequency: defaults.RX2Frequency,
+		RX2DataRate:  defaults.RX2DataRate,
+	}, nil
+}
+
 // Band defines the interface of a LoRaWAN band object.
 type Band interface {
 	// Name returns the name of the band.
@@ -221,6 +473,13 @@ type Band interface {
 	// are no extra channels, this method returns nil.
 	GetCFList(protocolVersion string) *lorawan.CFList
 
+	// ValidateCFList validates that the given CFList is permitted for the
+	// given protocol version and band. A nil CFList is always valid.
+	// Bands with a dynamic channel-plan only permit a CFList with channels,
+	// bands with a fixed channel-plan only permit a CFList with
+	// channel-mask, which is supported since LoRaWAN 1.0.3.
+	ValidateCFList(protocolVersion string, cFList *lorawan.CFList) error
+
 	// GetLinkADRReqPayloadsForEnabledUplinkChannelIndices returns the LinkADRReqPayloads to
 	// reconfigure the device to the current enabled channels. Note that in case of
 	// activation, user-defined channels (e.g. CFList) will be ignored as it
@@ -232,6 +491,31 @@ type Band interface {
 	// channels.
 	GetEnabledUplinkChannelIndicesForLinkADRReqPayloads(deviceEnabledChannels []int, pls []lorawan.LinkADRReqPayload) ([]int, error)
 
+	// ApplyNewChannelReqPayload returns the uplink channel states of a device
+	// after applying the given NewChannelReqPayload to the given channel
+	// states (e.g. as returned by GetUplinkChannelStates). The band and the
+	// given states are not modified. A frequency of 0 disables the channel.
+	// Note that this is only supported by bands with a dynamic channel-plan
+	// and that the default channels can't be modified.
+	ApplyNewChannelReqPayload(channels []ChannelState, pl lorawan.NewChannelReqPayload) ([]ChannelState, error)
+
+	// EnableSubBand enables the uplink channels of the given sub-band (1 - 8)
+	// and disables all other uplink channels. A sub-band contains eight
+	// 125 kHz channels and the matching 500 kHz channel (e.g. sub-band 2
+	// contains channels 8 - 15 and 65).
+	// Note: this is only supported by bands with a fixed channel-plan (e.g.
+	// US915 and AU915).
+	EnableSubBand(subBand int) error
+
+	// GetSubBandLinkADRReqPayloads returns the LinkADRReqPayloads to
+	// reconfigure the device to only use the channels of the given sub-band,
+	// regardless the channels currently enabled on the device.
+	GetSubBandLinkADRReqPayloads(subBand int) ([]lorawan.LinkADRReqPayload, error)
+
+	// GetSubBandCFList returns the channel-mask CFList enabling only the
+	// channels of the given sub-band.
+	GetSubBandCFList(subBand int) (*lorawan.CFList, error)
+
 	// GetDownlinkTXPower returns the TX power for downlink transmissions
 	// using the given frequency. Depending the band, it could return different
 	// values for different frequencies.
@@ -241,14 +525,54 @@ type Band interface {
 	// Regional Parameters.
 	GetDefaultMaxUplinkEIRP() float32
 
-	// GetDefaults returns the band defaults.
+	// GetDefaults returns the band defaults, including the RX2 overrides of
+	// the band Options.
 	GetDefaults() Defaults
 
+	// GetRegionalDefaults returns the band defaults as defined by the
+	// Regional Parameters, ignoring the RX2 overrides of the band Options.
+	GetRegionalDefaults() Defaults
+
+	// GetRequiredSNRForDataRateIndex returns the required SNR (dB) for
+	// demodulating the given data-rate, also referred to as the demodulation
+	// floor. This is only defined for the LoRa modulation.
+	GetRequiredSNRForDataRateIndex(dr int) (float64, error)
+
+	// GetSensitivityForDataRateIndex returns the typical receiver sensitivity
+	// (dBm) for the given data-rate, assuming a receiver noise-figure of
+	// DefaultNoiseFigure. This is only defined for the LoRa modulation.
+	GetSensitivityForDataRateIndex(dr int) (float64, error)
+
+	// GetLinkBudgetForDataRateIndex returns the link budget (dB) for the
+	// given data-rate and TX power (dBm), this is the maximum path loss at
+	// which the receiver is still able to demodulate the transmission.
+	GetLinkBudgetForDataRateIndex(dr int, txPower float64) (float64, error)
+
 	// ImplementsTXParamSetup returns if the device supports the TxParamSetup mac-command.
 	ImplementsTXParamSetup(protocolVersion string) bool
 }
 
+// DefaultNoiseFigure defines the receiver noise-figure (dB) which is used
+// for calculating the receiver sensitivity.
+const DefaultNoiseFigure = 6
+
+// loRaRequiredSNR contains the required SNR (dB) per LoRa spreading-factor.
+var loRaRequiredSNR = map[int]float64{
+	5:  -2.5,
+	6:  -5,
+	7:  -7.5,
+	8:  -10,
+	9:  -12.5,
+	10: -15,
+	11: -17.5,
+	12: -20,
+}
+
 type band struct {
+	// mu protects the uplink and downlink channels, as these can be modified
+	// at runtime (e.g. AddChannel and EnableSubBand).
+	mu sync.RWMutex
+
 	supportsExtraChannels bool
 	cFListMinDR           int
 	cFListMaxDR           int
@@ -258,6 +582,25 @@ type band struct {
 	uplinkChannels        []Channel
 	downlinkChannels      []Channel
 	txPowerOffsets        []int
+	subBands              int            // number of 8 channel (125 kHz) sub-bands, followed by one 500 kHz channel per sub-band
+	frequencyRange        frequencyRange // used for validating (mac-command) frequencies
+	defaults              Defaults       // as defined by the Regional Parameters
+	options               Options
+}
+
+func (b *band) GetDefaults() Defaults {
+	d := b.defaults
+	if b.options.RX2Frequency != 0 {
+		d.RX2Frequency = b.options.RX2Frequency
+	}
+	if b.options.RX2DataRate != nil {
+		d.RX2DataRate = *b.options.RX2DataRate
+	}
+	return d
+}
+
+func (b *band) GetRegionalDefaults() Defaults {
+	return b.defaults
 }
 
 func (b *band) GetDataRateIndex(uplink bool, dataRate DataRate) (int, error) {
@@ -287,7 +630,49 @@ func (b *band) GetDataRate(dr int) (DataRate, error) {
 	return d, nil
 }
 
+func (b *band) GetRequiredSNRForDataRateIndex(dr int) (float64, error) {
+	dataRate, err := b.GetDataRate(dr)
+	if err != nil {
+		return 0, err
+	}
+
+	if dataRate.Modulation != LoRaModulation {
+		return 0, fmt.Errorf("lorawan/band: required snr is not defined for %s modulation", dataRate.Modulation)
+	}
+
+	snr, ok := loRaRequiredSNR[dataRate.SpreadFactor]
+	if !ok {
+		return 0, fmt.Errorf("lorawan/band: required snr is not defined for SF%d", dataRate.SpreadFactor)
+	}
+
+	return snr, nil
+}
+
+func (b *band) GetSensitivityForDataRateIndex(dr int) (float64, error) {
+	snr, err := b.GetRequiredSNRForDataRateIndex(dr)
+	if err != nil {
+		return 0, err
+	}
+
+	// the required snr is only returned for LoRa data-rates
+	dataRate := b.dataRates[dr]
+	return float64(sensitivity.CalculateSensitivity(dataRate.Bandwidth*1000, DefaultNoiseFigure, float32(snr))), nil
+}
+
+func (b *band) GetLinkBudgetForDataRateIndex(dr int, txPower float64) (float64, error) {
+	sens, err := b.GetSensitivityForDataRateIndex(dr)
+	if err != nil {
+		return 0, err
+	}
+
+	return txPower - sens, nil
+}
+
 func (b *band) GetMaxPayloadSizeForDataRateIndex(protocolVersion, regParamRevision string, dr int) (MaxPayloadSize, error) {
+	if regParamRevision == "" {
+		regParamRevision = b.options.RegParamsRevision
+	}
+
 	regParamMap, ok := b.maxPayloadSizePerDR[protocolVersion]
 	if !ok {
 		regParamMap, ok = b.maxPayloadSizePerDR[latest]
@@ -332,6 +717,13 @@ func (b *band) GetTXPowerOffset(txPower int) (int, error) {
 }
 
 func (b *band) AddChannel(frequency uint32, minDR, maxDR int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.addChannel(frequency, minDR, maxDR)
+}
+
+func (b *band) addChannel(frequency uint32, minDR, maxDR int) error {
 	if !b.supportsExtraChannels {
 		return errors.New("lorawan/band: band does not support extra channels")
 	}
@@ -350,6 +742,9 @@ func (b *band) AddChannel(frequency uint32, minDR, maxDR int) error {
 }
 
 func (b *band) GetUplinkChannel(channel int) (Channel, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	if channel > len(b.uplinkChannels)-1 {
 		return Channel{}, errors.New("lorawan/band: invalid channel")
 	}
@@ -358,6 +753,9 @@ func (b *band) GetUplinkChannel(channel int) (Channel, error) {
 }
 
 func (b *band) GetUplinkChannelIndex(frequency uint32, defaultChannel bool) (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	for i, channel := range b.uplinkChannels {
 		if frequency == channel.Frequency && channel.custom != defaultChannel {
 			return i, nil
@@ -393,6 +791,9 @@ func (b *band) GetUplinkChannelIndexForFrequencyDR(frequency uint32, dr int) (in
 }
 
 func (b *band) GetDownlinkChannel(channel int) (Channel, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	if channel > len(b.downlinkChannels)-1 {
 		return Channel{}, errors.New("lorawan/band: invalid channel")
 	}
@@ -400,6 +801,9 @@ func (b *band) GetDownlinkChannel(channel int) (Channel, error) {
 }
 
 func (b *band) DisableUplinkChannelIndex(channel int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	if channel > len(b.uplinkChannels)-1 {
 		return errors.New("lorawan/band: channel does not exist")
 	}
@@ -408,6 +812,9 @@ func (b *band) DisableUplinkChannelIndex(channel int) error {
 }
 
 func (b *band) EnableUplinkChannelIndex(channel int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	if channel > len(b.uplinkChannels)-1 {
 		return errors.New("lorawan/band: channel does not exist")
 	}
@@ -416,6 +823,9 @@ func (b *band) EnableUplinkChannelIndex(channel int) error {
 }
 
 func (b *band) GetUplinkChannelIndices() []int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	var out []int
 	for i := range b.uplinkChannels {
 		out = append(out, i)
@@ -424,6 +834,9 @@ func (b *band) GetUplinkChannelIndices() []int {
 }
 
 func (b *band) GetStandardUplinkChannelIndices() []int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	var out []int
 	for i, c := range b.uplinkChannels {
 		if !c.custom {
@@ -434,6 +847,9 @@ func (b *band) GetStandardUplinkChannelIndices() []int {
 }
 
 func (b *band) GetCustomUplinkChannelIndices() []int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	var out []int
 	for i, c := range b.uplinkChannels {
 		if c.custom {
@@ -444,6 +860,13 @@ func (b *band) GetCustomUplinkChannelIndices() []int {
 }
 
 func (b *band) GetEnabledUplinkChannelIndices() []int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.getEnabledUplinkChannelIndices()
+}
+
+func (b *band) getEnabledUplinkChannelIndices() []int {
 	var out []int
 	for i, c := range b.uplinkChannels {
 		if c.enabled {
@@ -454,6 +877,9 @@ func (b *band) GetEnabledUplinkChannelIndices() []int {
 }
 
 func (b *band) GetDisabledUplinkChannelIndices() []int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	var out []int
 	for i, c := range b.uplinkChannels {
 		if !c.enabled {
@@ -464,6 +890,9 @@ func (b *band) GetDisabledUplinkChannelIndices() []int {
 }
 
 func (b *band) GetEnabledUplinkDataRates() []int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	outS := make(map[int]struct{})
 	for _, c := range b.uplinkChannels {
 		for i := c.MinDR; i <= c.MaxDR; i++ {
@@ -481,6 +910,9 @@ func (b *band) GetEnabledUplinkDataRates() []int {
 }
 
 func (b *band) GetCFList(protocolVersion string) *lorawan.CFList {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	// Sending the channel-mask in the CFList is supported since LoRaWAN 1.0.3.
 	// For earlier versions, only a CFList with (extra) channel-list is
 	// supported.
@@ -495,6 +927,41 @@ func (b *band) GetCFList(protocolVersion string) *lorawan.CFList {
 	return b.getCFListChannelMask()
 }
 
+func (b *band) ValidateCFList(protocolVersion string, cFList *lorawan.CFList) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if cFList == nil {
+		return nil
+	}
+
+	if b.supportsExtraChannels {
+		if cFList.CFListType != lorawan.CFListChannel {
+			return ErrCFListNotPermitted
+		}
+		return nil
+	}
+
+	if cFList.CFListType != lorawan.CFListChannelMask || protocolVersion == LoRaWAN_1_0_0 || protocolVersion == LoRaWAN_1_0_1 || protocolVersion == LoRaWAN_1_0_2 {
+		return ErrCFListNotPermitted
+	}
+
+	pl, ok := cFList.Payload.(*lorawan.CFListChannelMaskPayload)
+	if !ok {
+		return fmt.Errorf("lorawan/band: *lorawan.CFListChannelMaskPayload expected, got %T", cFList.Payload)
+	}
+
+	for i, cm := range pl.ChannelMasks {
+		for j, enabled := range cm {
+			if enabled && i*len(cm)+j >= len(b.uplinkChannels) {
+				return ErrChannelDoesNotExist
+			}
+		}
+	}
+
+	return nil
+}
+
 func (b *band) getCFListChannelMask() *lorawan.CFList {
 	var pl lorawan.CFListChannelMaskPayload
 	var chMask lorawan.ChMask
@@ -536,7 +1003,10 @@ func (b *band) getCFListChannels() *lorawan.CFList {
 }
 
 func (b *band) GetLinkADRReqPayloadsForEnabledUplinkChannelIndices(deviceEnabledChannels []int) []lorawan.LinkADRReqPayload {
-	enabledChannels := b.GetEnabledUplinkChannelIndices()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	enabledChannels := b.getEnabledUplinkChannelIndices()
 
 	diff := intSliceDiff(deviceEnabledChannels, enabledChannels)
 	var filteredDiff []int
@@ -588,6 +1058,9 @@ func (b *band) GetLinkADRReqPayloadsForEnabledUplinkChannelIndices(deviceEnabled
 }
 
 func (b *band) GetEnabledUplinkChannelIndicesForLinkADRReqPayloads(deviceEnabledChannels []int, pls []lorawan.LinkADRReqPayload) ([]int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	chMask := make([]bool, len(b.uplinkChannels))
 	for _, c := range deviceEnabledChannels {
 		// make sure that we don't exceed the chMask length. in case we exceed
@@ -622,6 +1095,64 @@ func (b *band) GetEnabledUplinkChannelIndicesForLinkADRReqPayloads(deviceEnabled
 	return out, nil
 }
 
+func (b *band) ApplyNewChannelReqPayload(channels []ChannelState, pl lorawan.NewChannelReqPayload) ([]ChannelState, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.supportsExtraChannels {
+		return nil, ErrNewChannelReqNotSupported
+	}
+
+	chIndex := int(pl.ChIndex)
+	if chIndex >= maxDynamicChannels {
+		return nil, ErrChannelDoesNotExist
+	}
+
+	if chIndex < len(b.uplinkChannels) && !b.uplinkChannels[chIndex].custom {
+		return nil, ErrDefaultChannelNotModifiable
+	}
+
+	if !pl.IsDisabled() {
+		if b.frequencyRange.max != 0 && (pl.Freq < b.frequencyRange.min || pl.Freq > b.frequencyRange.max) {
+			return nil, ErrFrequencyOutOfRange
+		}
+
+		if pl.MinDR > pl.MaxDR {
+			return nil, errors.New("lorawan/band: MinDR must be less than or equal to MaxDR")
+		}
+
+		for _, dr := range []uint8{pl.MinDR, pl.MaxDR} {
+			if _, err := b.GetDataRate(int(dr)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	out := make([]ChannelState, len(channels))
+	copy(out, channels)
+
+	// pad the channels with disabled channels, so that the channel index
+	// matches the ChIndex of the payload
+	for len(out) <= chIndex {
+		i := len(out)
+		if i < len(b.uplinkChannels) && !b.uplinkChannels[i].custom {
+			return nil, fmt.Errorf("lorawan/band: default channel %d is missing from the channel states", i)
+		}
+		out = append(out, ChannelState{Index: i, Custom: true})
+	}
+
+	out[chIndex] = ChannelState{
+		Index:     chIndex,
+		Frequency: pl.Freq,
+		MinDR:     int(pl.MinDR),
+		MaxDR:     int(pl.MaxDR),
+		Enabled:   !pl.IsDisabled(),
+		Custom:    true,
+	}
+
+	return out, nil
+}
+
 // y that are not in x.
 func intSliceDiff(x, y []int) []int {
 	var out []int
@@ -664,40 +1195,315 @@ func channelIsActive(channels []int, i int) bool {
 	return false
 }
 
+// Options holds the band configuration options.
+type Options struct {
+	// RepeaterCompatible defines if the max payload-sizes must be compatible
+	// with repeaters.
+	RepeaterCompatible bool
+
+	// DwellTime defines the dwell time limitation (only used by bands
+	// implementing dwell time limitations).
+	DwellTime lorawan.DwellTime
+
+	// RegParamsRevision defines the Regional Parameters revision. When set,
+	// it is used as revision when GetMaxPayloadSizeForDataRateIndex is called
+	// with an empty revision.
+	RegParamsRevision string
+
+	// AS923Group defines the AS923 frequency offset group (1 - 4). This can
+	// only be used in combination with the AS923 band name. When not set,
+	// the group is implied by the band name.
+	AS923Group int
+
+	// CN470ChannelPlan defines the CN470 channel plan. This can only be used
+	// in combination with the CN470 band name.
+	CN470ChannelPlan CN470ChannelPlan
+
+	// RX2Frequency overrides the default RX2 frequency (Hz) when not 0.
+	RX2Frequency uint32
+
+	// RX2DataRate overrides the default RX2 data-rate when not nil.
+	RX2DataRate *int
+}
+
 // GetConfig returns the band configuration for the given band.
 // Please refer to the LoRaWAN specification for more details about the effect
 // of the repeater and dwell time arguments.
+// The name is matched case-insensitive and the deprecated names are
+// accepted as alias of the common name.
 func GetConfig(name Name, repeaterCompatible bool, dt lorawan.DwellTime) (Band, error) {
+	return GetConfigWithOptions(name, Options{
+		RepeaterCompatible: repeaterCompatible,
+		DwellTime:          dt,
+	})
+}
+
+// GetConfigWithOptions returns the band configuration for the given band,
+// using the given options.
+// The name is matched case-insensitive and the deprecated names are
+// accepted as alias of the common name.
+func GetConfigWithOptions(name Name, opts Options) (Band, error) {
+	name, err := ParseName(string(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.AS923Group != 0 {
+		if name != AS923 {
+			return nil, errors.New("lorawan/band: AS923Group can only be used with the AS923 band")
+		}
+
+		switch opts.AS923Group {
+		case 1:
+			name = AS923
+		case 2:
+			name = AS923_2
+		case 3:
+			name = AS923_3
+		case 4:
+			name = AS923_4
+		default:
+			return nil, fmt.Errorf("lorawan/band: invalid AS923 group %d", opts.AS923Group)
+		}
+	}
+
+	if opts.CN470ChannelPlan != CN470ChannelPlanDefault && name != CN470 {
+		return nil, errors.New("lorawan/band: CN470ChannelPlan can only be used with the CN470 band")
+	}
+
+	b, err := newBand(name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.RX2DataRate != nil {
+		if _, err := b.GetDataRate(*opts.RX2DataRate); err != nil {
+			return nil, errors.Wrap(err, "invalid RX2 data-rate")
+		}
+	}
+
+	return b, nil
+}
+
+func newBand(name Name, opts Options) (Band, error) {
 	switch name {
-	case AS_923, AS923:
-		return newAS923Band(repeaterCompatible, dt, 0, "")
+	case AS923:
+		return newAS923Band(opts, 0, "")
 	case AS923_2:
-		return newAS923Band(repeaterCompatible, dt, -1800000, "-2")
+		return newAS923Band(opts, -1800000, "-2")
 	case AS923_3:
-		return newAS923Band(repeaterCompatible, dt, -6600000, "-3")
+		return newAS923Band(opts, -6600000, "-3")
 	case AS923_4:
-		return newAS923Band(repeaterCompatible, dt, -5900000, "-4")
-	case AU_915_928, AU915:
-		return newAU915Band(repeaterCompatible, dt)
-	case CN_470_510, CN470:
-		return newCN470Band(repeaterCompatible)
-	case CN_779_787, CN779:
-		return newCN779Band(repeaterCompatible)
-	case EU_433, EU433:
-		return newEU433Band(repeaterCompatible)
-	case EU_863_870, EU868:
-		return newEU863Band(repeaterCompatible)
-	case IN_865_867, IN865:
-		return newIN865Band(repeaterCompatible)
-	case KR_920_923, KR920:
-		return newKR920Band(repeaterCompatible)
-	case US_902_928, US915:
-		return newUS902Band(repeaterCompatible)
-	case RU_864_870, RU864:
-		return newRU864Band(repeaterCompatible)
+		return newAS923Band(opts, -5900000, "-4")
+	case AU915:
+		return newAU915Band(opts)
+	case CN470:
+		return newCN470Band(opts)
+	case CN779:
+		return newCN779Band(opts)
+	case EU433:
+		return newEU433Band(opts)
+	case EU868:
+		return newEU863Band(opts)
+	case IN865:
+		return newIN865Band(opts)
+	case KR920:
+		return newKR920Band(opts)
+	case US915:
+		return newUS902Band(opts)
+	case RU864:
+		return newRU864Band(opts)
 	case ISM2400:
-		return newISM2400Band(repeaterCompatible)
+		return newISM2400Band(opts)
 	default:
 		return nil, fmt.Errorf("lorawan/band: band %s is undefined", name)
 	}
 }
+
+func (b *band) EnableSubBand(subBand int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	chMask, err := b.getSubBandChMask(subBand)
+	if err != nil {
+		return err
+	}
+
+	for i := range b.uplinkChannels {
+		b.uplinkChannels[i].enabled = chMask[i]
+	}
+
+	return nil
+}
+
+func (b *band) GetSubBandLinkADRReqPayloads(subBand int) ([]lorawan.LinkADRReqPayload, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if _, err := b.getSubBandChMask(subBand); err != nil {
+		return nil, err
+	}
+
+	// turn off all 125 kHz channels and enable the 500 kHz channel of the
+	// sub-band, then enable the 125 kHz channels of the sub-band
+	out := []lorawan.LinkADRReqPayload{
+		{Redundancy: lorawan.Redundancy{ChMaskCntl: 7}},
+		{Redundancy: lorawan.Redundancy{ChMaskCntl: uint8((subBand - 1) / 2)}},
+	}
+	out[0].ChMask[subBand-1] = true
+
+	for i := 0; i < 8; i++ {
+		out[1].ChMask[((subBand-1)%2)*8+i] = true
+	}
+
+	return out, nil
+}
+
+// getLinkADRReqPayloadsForAll125kHzChannels returns the LinkADRReqPayloads
+// for the US915 and AU915 channel-plans (64 x 125 kHz + 8 x 500 kHz
+// channels), using the first payload to turn all 125 kHz channels ON
+// (ChMaskCntl 6) or OFF (ChMaskCntl 7). This first payload also contains the
+// ChMask of the 500 kHz channels. The remaining payloads contain the ChMask
+// of the blocks of 16 125 kHz channels that differ from this.
+func getLinkADRReqPayloadsForAll125kHzChannels(enabled bool, enabledChannels []int) []lorawan.LinkADRReqPayload {
+	var chMask [64]bool
+
+	out := []lorawan.LinkADRReqPayload{
+		{Redundancy: lorawan.Redundancy{ChMaskCntl: 7}},
+	}
+	if enabled {
+		out[0].Redundancy.ChMaskCntl = 6
+	}
+
+	for _, c := range enabledChannels {
+		if c >= 64 {
+			out[0].ChMask[c%16] = true
+			continue
+		}
+		chMask[c] = true
+	}
+
+	for i := 0; i < len(chMask)/16; i++ {
+		pl := lorawan.LinkADRReqPayload{
+			Redundancy: lorawan.Redundancy{ChMaskCntl: uint8(i)},
+		}
+
+		var differs bool
+		for j := range pl.ChMask {
+			pl.ChMask[j] = chMask[i*16+j]
+			if pl.ChMask[j] != enabled {
+				differs = true
+			}
+		}
+
+		if differs {
+			out = append(out, pl)
+		}
+	}
+
+	return out
+}
+
+func (b *band) GetSubBandCFList(subBand int) (*lorawan.CFList, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	chMask, err := b.getSubBandChMask(subBand)
+	if err != nil {
+		return nil, err
+	}
+
+	var pl lorawan.CFListChannelMaskPayload
+	var cm lorawan.ChMask
+
+	for i, enabled := range chMask {
+		if i != 0 && i%len(cm) == 0 {
+			pl.ChannelMasks = append(pl.ChannelMasks, cm)
+			cm = lorawan.ChMask{}
+		}
+		cm[i%len(cm)] = enabled
+	}
+	pl.ChannelMasks = append(pl.ChannelMasks, cm)
+
+	return &lorawan.CFList{
+		CFListType: lorawan.CFListChannelMask,
+		Payload:    &pl,
+	}, nil
+}
+
+// getSubBandChMask returns the uplink channel-mask for the given sub-band.
+func (b *band) getSubBandChMask(subBand int) ([]bool, error) {
+	if b.subBands == 0 {
+		return nil, ErrSubBandNotSupported
+	}
+
+	if subBand < 1 || subBand > b.subBands {
+		return nil, fmt.Errorf("lorawan/band: invalid sub-band %d", subBand)
+	}
+
+	chMask := make([]bool, len(b.uplinkChannels))
+	for i := range chMask {
+		if i < b.subBands*8 {
+			chMask[i] = i/8 == subBand-1
+		} else {
+			chMask[i] = i-b.subBands*8 == subBand-1
+		}
+	}
+
+	return chMask, nil
+}
+
+// GetMaxPHYPayloadSize returns the max. PHYPayload size (MHDR, MACPayload
+// and MIC) in bytes for the given protocol version, regional-parameters
+// revision and data-rate. This can be used as lorawan.DecodeOptions MaxSize.
+func GetMaxPHYPayloadSize(b Band, protocolVersion, regParamRevision string, dr int) (int, error) {
+	size, err := b.GetMaxPayloadSizeForDataRateIndex(protocolVersion, regParamRevision, dr)
+	if err != nil {
+		return 0, err
+	}
+
+	// MHDR (1 byte) + MACPayload + MIC (4 bytes)
+	return size.M + 5, nil
+}
+
+// ValidatePingSlotChannelReqPayload validates the given
+// PingSlotChannelReqPayload against the given band and returns the
+// PingSlotChannelAnsPayload that a device would respond with.
+// A frequency of 0 (region default ping-slot frequency) is always valid.
+func ValidatePingSlotChannelReqPayload(b Band, pl lorawan.PingSlotChannelReqPayload) lorawan.PingSlotChannelAnsPayload {
+	var ans lorawan.PingSlotChannelAnsPayload
+
+	if dr, err := b.GetDataRate(int(pl.DR)); err == nil && dr.downlink {
+		ans.DataRateOK = true
+	}
+
+	if pl.UsesDefaultFrequency() {
+		ans.ChannelFrequencyOK = true
+	} else if name, err := ParseName(b.Name()); err == nil {
+		r := frequencyRanges[name]
+		ans.ChannelFrequencyOK = pl.Frequency >= r.min && pl.Frequency <= r.max
+	}
+
+	return ans
+}
+
+// GetPingSlotFrequencyForPingSlotChannelReqPayload returns the frequency to
+// use for the Class-B ping-slot after applying the given
+// PingSlotChannelReqPayload. When the payload frequency is 0, the region
+// default ping-slot frequency is returned (see GetPingSlotFrequency).
+// An error is returned when the payload is not valid for the given band.
+func GetPingSlotFrequencyForPingSlotChannelReqPayload(b Band, pl lorawan.PingSlotChannelReqPayload, devAddr lorawan.DevAddr, beaconTime time.Duration) (uint32, error) {
+	ans := ValidatePingSlotChannelReqPayload(b, pl)
+	if !ans.DataRateOK {
+		return 0, errors.New("lorawan/band: invalid ping-slot data-rate")
+	}
+	if !ans.ChannelFrequencyOK {
+		return 0, ErrFrequencyOutOfRange
+	}
+
+	if pl.UsesDefaultFrequency() {
+		return b.GetPingSlotFrequency(devAddr, beaconTime)
+	}
+
+	return pl.Frequency, nil
+}
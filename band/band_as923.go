@@ -18,17 +18,6 @@ func (b *as923Band) Name() string {
 	return "AS923" + b.nameSuffix
 }
 
-func (b *as923Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     uint32(923200000 + b.frequencyOffset),
-		RX2DataRate:      2,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *as923Band) GetDownlinkTXPower(freq uint32) int {
 	return 14
 }
@@ -81,13 +70,23 @@ func (b *as923Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return true
 }
 
-func newAS923Band(repeaterCompatible bool, dt lorawan.DwellTime, frequencyOffset int, nameSuffix string) (Band, error) {
+func newAS923Band(opts Options, frequencyOffset int, nameSuffix string) (Band, error) {
 	b := as923Band{
 		nameSuffix:      nameSuffix,
 		frequencyOffset: frequencyOffset,
-		dwellTime:       dt,
+		dwellTime:       opts.DwellTime,
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     uint32(923200000 + frequencyOffset),
+				RX2DataRate:      2,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options:               opts,
 			supportsExtraChannels: true,
+			frequencyRange:        frequencyRanges[AS923],
 			cFListMinDR:           0,
 			cFListMaxDR:           5,
 			dataRates: map[int]DataRate{
@@ -122,8 +121,8 @@ func newAS923Band(repeaterCompatible bool, dt lorawan.DwellTime, frequencyOffset
 		},
 	}
 
-	if dt == lorawan.DwellTime400ms {
-		if repeaterCompatible {
+	if opts.DwellTime == lorawan.DwellTime400ms {
+		if opts.RepeaterCompatible {
 			// repeater compatible + dwell time
 			b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 				LoRaWAN_1_0_2: map[string]map[int]MaxPayloadSize{
@@ -229,7 +228,7 @@ func newAS923Band(repeaterCompatible bool, dt lorawan.DwellTime, frequencyOffset
 			}
 		}
 	} else {
-		if repeaterCompatible {
+		if opts.RepeaterCompatible {
 			// repeater compatible + no dwell time
 			b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 				LoRaWAN_1_0_2: map[string]map[int]MaxPayloadSize{
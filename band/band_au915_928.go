@@ -2,7 +2,6 @@ package band
 
 import (
 	"encoding/binary"
-	"sort"
 	"time"
 
 	"github.com/brocaar/lorawan"
@@ -17,17 +16,6 @@ func (b *au915Band) Name() string {
 	return "AU915"
 }
 
-func (b *au915Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     923300000,
-		RX2DataRate:      8,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *au915Band) GetDownlinkTXPower(freq uint32) int {
 	return 27
 }
@@ -62,52 +50,27 @@ func (b *au915Band) GetRX1FrequencyForUplinkFrequency(uplinkFrequency uint32) (u
 }
 
 func (b *au915Band) GetLinkADRReqPayloadsForEnabledUplinkChannelIndices(deviceEnabledChannels []int) []lorawan.LinkADRReqPayload {
-	payloadsA := b.band.GetLinkADRReqPayloadsForEnabledUplinkChannelIndices(deviceEnabledChannels)
-
 	enabledChannels := b.GetEnabledUplinkChannelIndices()
-	sort.Ints(enabledChannels)
 
-	out := []lorawan.LinkADRReqPayload{
-		{Redundancy: lorawan.Redundancy{ChMaskCntl: 7}}, // All 125 kHz OFF ChMask applies to channels 64 to 71
+	// use the option resulting in the least number of payloads: the
+	// payloads per block of 16 channels, or turning all 125 kHz channels
+	// OFF (ChMaskCntl 7) or ON (ChMaskCntl 6) followed by the payloads for
+	// the blocks that differ
+	out := getLinkADRReqPayloadsForAll125kHzChannels(false, enabledChannels)
+	if pls := b.band.GetLinkADRReqPayloadsForEnabledUplinkChannelIndices(deviceEnabledChannels); len(pls) < len(out) {
+		out = pls
 	}
-
-	chMaskCntl := -1
-
-	for _, c := range enabledChannels {
-		// use the ChMask of the first LinkADRReqPayload, besides
-		// turning off all 125 kHz this payload contains the ChMask
-		// for the last block of channels.
-		if c >= 64 {
-			out[0].ChMask[c%16] = true
-			continue
-		}
-
-		if c/16 != chMaskCntl {
-			chMaskCntl = c / 16
-			pl := lorawan.LinkADRReqPayload{
-				Redundancy: lorawan.Redundancy{
-					ChMaskCntl: uint8(chMaskCntl),
-				},
-			}
-
-			// set the channel mask for this block
-			for _, ec := range enabledChannels {
-				if ec >= chMaskCntl*16 && ec < (chMaskCntl+1)*16 {
-					pl.ChMask[ec%16] = true
-				}
-			}
-
-			out = append(out, pl)
-		}
+	if pls := getLinkADRReqPayloadsForAll125kHzChannels(true, enabledChannels); len(pls) < len(out) {
+		out = pls
 	}
 
-	if len(payloadsA) < len(out) {
-		return payloadsA
-	}
 	return out
 }
 
 func (b *au915Band) GetEnabledUplinkChannelIndicesForLinkADRReqPayloads(deviceEnabledChannels []int, pls []lorawan.LinkADRReqPayload) ([]int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	chMask := make([]bool, len(b.uplinkChannels))
 	for _, c := range deviceEnabledChannels {
 		// make sure that we don't exceed the chMask length. in case we exceed
@@ -167,10 +130,19 @@ func (b *au915Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return true
 }
 
-func newAU915Band(repeaterCompatible bool, dt lorawan.DwellTime) (Band, error) {
+func newAU915Band(opts Options) (Band, error) {
 	b := au915Band{
-		dwellTime: dt,
+		dwellTime: opts.DwellTime,
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     923300000,
+				RX2DataRate:      8,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options: opts,
 			dataRates: map[int]DataRate{
 				0:  {Modulation: LoRaModulation, SpreadFactor: 12, Bandwidth: 125, uplink: true},
 				1:  {Modulation: LoRaModulation, SpreadFactor: 11, Bandwidth: 125, uplink: true},
@@ -215,12 +187,13 @@ func newAU915Band(repeaterCompatible bool, dt lorawan.DwellTime) (Band, error) {
 				-28, // 14
 			},
 			uplinkChannels:   make([]Channel, 72),
+			subBands:         8,
 			downlinkChannels: make([]Channel, 8),
 		},
 	}
 
-	if repeaterCompatible {
-		if dt == lorawan.DwellTime400ms {
+	if opts.RepeaterCompatible {
+		if opts.DwellTime == lorawan.DwellTime400ms {
 			// repeater compatibility + dwell time
 			b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 				// LoRaWAN < 1.0.3 + < LoRaWAN 1.1.0B does not have dwell-time
@@ -452,7 +425,7 @@ func newAU915Band(repeaterCompatible bool, dt lorawan.DwellTime) (Band, error) {
 		}
 	} else {
 		// no repeater compatibility + dwell time
-		if dt == lorawan.DwellTime400ms {
+		if opts.DwellTime == lorawan.DwellTime400ms {
 			b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 				// LoRaWAN < 1.0.3 + < LoRaWAN 1.1.0B does not have dwell-time
 				LoRaWAN_1_0_3: map[string]map[int]MaxPayloadSize{
@@ -2,30 +2,100 @@ package band
 
 import (
 	"encoding/binary"
+	"fmt"
 	"time"
 
 	"github.com/brocaar/lorawan"
 )
 
+// CN470ChannelPlan defines the CN470 channel frequency plan.
+type CN470ChannelPlan int
+
+// Available CN470 channel plans.
+const (
+	// CN470ChannelPlanDefault implements the original 96 uplink / 48 downlink
+	// channel plan.
+	CN470ChannelPlanDefault CN470ChannelPlan = iota
+
+	// CN470ChannelPlan20MHzA implements the RP002 20 MHz antenna, type A plan.
+	CN470ChannelPlan20MHzA
+
+	// CN470ChannelPlan20MHzB implements the RP002 20 MHz antenna, type B plan.
+	CN470ChannelPlan20MHzB
+
+	// CN470ChannelPlan26MHzA implements the RP002 26 MHz antenna, type A plan.
+	CN470ChannelPlan26MHzA
+
+	// CN470ChannelPlan26MHzB implements the RP002 26 MHz antenna, type B plan.
+	CN470ChannelPlan26MHzB
+)
+
+// cn470ChannelGroup defines a range of consecutive channels (200 kHz spacing).
+type cn470ChannelGroup struct {
+	frequency uint32
+	count     int
+}
+
+// cn470ChannelPlan defines the channels of a CN470 channel plan.
+type cn470ChannelPlan struct {
+	uplinkGroups   []cn470ChannelGroup
+	downlinkGroups []cn470ChannelGroup
+	rx2Frequency   uint32
+
+	// pingSlotChannels contains the number of downlink channels (starting
+	// from the first downlink channel) used for ping-slots.
+	pingSlotChannels int
+}
+
+var cn470ChannelPlans = map[CN470ChannelPlan]cn470ChannelPlan{
+	CN470ChannelPlan20MHzA: {
+		uplinkGroups:     []cn470ChannelGroup{{470300000, 32}, {503500000, 32}},
+		downlinkGroups:   []cn470ChannelGroup{{483900000, 64}},
+		rx2Frequency:     485300000,
+		pingSlotChannels: 32,
+	},
+	CN470ChannelPlan20MHzB: {
+		uplinkGroups:     []cn470ChannelGroup{{476900000, 32}, {490300000, 32}},
+		downlinkGroups:   []cn470ChannelGroup{{476900000, 32}, {490300000, 32}},
+		rx2Frequency:     486900000,
+		pingSlotChannels: 32,
+	},
+	CN470ChannelPlan26MHzA: {
+		uplinkGroups:     []cn470ChannelGroup{{470300000, 48}},
+		downlinkGroups:   []cn470ChannelGroup{{490100000, 24}},
+		rx2Frequency:     492500000,
+		pingSlotChannels: 24,
+	},
+	CN470ChannelPlan26MHzB: {
+		uplinkGroups:     []cn470ChannelGroup{{480300000, 48}},
+		downlinkGroups:   []cn470ChannelGroup{{500100000, 24}},
+		rx2Frequency:     502500000,
+		pingSlotChannels: 24,
+	},
+}
+
+func (g cn470ChannelGroup) channels() []Channel {
+	var out []Channel
+	for i := 0; i < g.count; i++ {
+		out = append(out, Channel{
+			Frequency: g.frequency + uint32(i*200000),
+			MinDR:     0,
+			MaxDR:     5,
+			enabled:   true,
+		})
+	}
+	return out
+}
+
 type cn470Band struct {
 	band
+	channelPlan CN470ChannelPlan
 }
 
 func (b *cn470Band) Name() string {
 	return "CN470"
 }
 
-func (b *cn470Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     505300000,
-		RX2DataRate:      0,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *cn470Band) GetDownlinkTXPower(freq uint32) int {
 	return 14
 }
@@ -35,6 +105,11 @@ func (b *cn470Band) GetDefaultMaxUplinkEIRP() float32 {
 }
 
 func (b *cn470Band) GetPingSlotFrequency(devAddr lorawan.DevAddr, beaconTime time.Duration) (uint32, error) {
+	if p, ok := cn470ChannelPlans[b.channelPlan]; ok {
+		downlinkChannel := (int(binary.BigEndian.Uint32(devAddr[:])) + int(beaconTime/(128*time.Second))) % p.pingSlotChannels
+		return b.downlinkChannels[downlinkChannel].Frequency, nil
+	}
+
 	downlinkChannel := (int(binary.BigEndian.Uint32(devAddr[:])) + int(beaconTime/(128*time.Second))) % 8
 	return []uint32{
 		508300000,
@@ -49,7 +124,7 @@ func (b *cn470Band) GetPingSlotFrequency(devAddr lorawan.DevAddr, beaconTime tim
 }
 
 func (b *cn470Band) GetRX1ChannelIndexForUplinkChannelIndex(uplinkChannel int) (int, error) {
-	return uplinkChannel % 48, nil
+	return uplinkChannel % len(b.downlinkChannels), nil
 }
 
 func (b *cn470Band) GetRX1FrequencyForUplinkFrequency(uplinkFrequency uint32) (uint32, error) {
@@ -70,9 +145,25 @@ func (b *cn470Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return false
 }
 
-func newCN470Band(repeaterCompatible bool) (Band, error) {
+func newCN470Band(opts Options) (Band, error) {
+	channelPlan := opts.CN470ChannelPlan
+	rx2Frequency := uint32(505300000)
+	if p, ok := cn470ChannelPlans[channelPlan]; ok {
+		rx2Frequency = p.rx2Frequency
+	}
+
 	b := cn470Band{
+		channelPlan: channelPlan,
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     rx2Frequency,
+				RX2DataRate:      0,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options: opts,
 			dataRates: map[int]DataRate{
 				0: {Modulation: LoRaModulation, SpreadFactor: 12, Bandwidth: 125, uplink: true, downlink: true},
 				1: {Modulation: LoRaModulation, SpreadFactor: 11, Bandwidth: 125, uplink: true, downlink: true},
@@ -101,12 +192,10 @@ func newCN470Band(repeaterCompatible bool) (Band, error) {
 				-12, // 6
 				-14, // 7
 			},
-			uplinkChannels:   make([]Channel, 96),
-			downlinkChannels: make([]Channel, 48),
 		},
 	}
 
-	if repeaterCompatible {
+	if opts.RepeaterCompatible {
 		b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 			LoRaWAN_1_0_1: map[string]map[int]MaxPayloadSize{
 				latest: map[int]MaxPayloadSize{ // LoRaWAN 1.0.1
@@ -244,24 +333,40 @@ func newCN470Band(repeaterCompatible bool) (Band, error) {
 		}
 	}
 
-	// initialize uplink channels
-	for i := uint32(0); i < 96; i++ {
-		b.uplinkChannels[i] = Channel{
-			Frequency: 470300000 + (i * 200000),
-			MinDR:     0,
-			MaxDR:     5,
-			enabled:   true,
+	if channelPlan == CN470ChannelPlanDefault {
+		// initialize uplink channels
+		for i := uint32(0); i < 96; i++ {
+			b.uplinkChannels = append(b.uplinkChannels, Channel{
+				Frequency: 470300000 + (i * 200000),
+				MinDR:     0,
+				MaxDR:     5,
+				enabled:   true,
+			})
 		}
-	}
 
-	// initialize downlink channels
-	for i := uint32(0); i < 48; i++ {
-		b.downlinkChannels[i] = Channel{
-			Frequency: 500300000 + (i * 200000),
-			MinDR:     0,
-			MaxDR:     5,
-			enabled:   true,
+		// initialize downlink channels
+		for i := uint32(0); i < 48; i++ {
+			b.downlinkChannels = append(b.downlinkChannels, Channel{
+				Frequency: 500300000 + (i * 200000),
+				MinDR:     0,
+				MaxDR:     5,
+				enabled:   true,
+			})
 		}
+
+		return &b, nil
+	}
+
+	p, ok := cn470ChannelPlans[channelPlan]
+	if !ok {
+		return nil, fmt.Errorf("lorawan/band: invalid CN470 channel plan %d", channelPlan)
+	}
+
+	for _, g := range p.uplinkGroups {
+		b.uplinkChannels = append(b.uplinkChannels, g.channels()...)
+	}
+	for _, g := range p.downlinkGroups {
+		b.downlinkChannels = append(b.downlinkChannels, g.channels()...)
 	}
 
 	return &b, nil
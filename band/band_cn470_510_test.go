@@ -153,3 +153,92 @@ func TestCN470Band(t *testing.T) {
 		})
 	})
 }
+
+func TestCN470ChannelPlans(t *testing.T) {
+	Convey("Given a set of CN470 channel plans", t, func() {
+		tests := []struct {
+			Name                 string
+			ChannelPlan          CN470ChannelPlan
+			UplinkChannels       int
+			DownlinkChannels     int
+			RX2Frequency         uint32
+			UplinkFrequency      uint32
+			ExpectedRX1Frequency uint32
+		}{
+			{
+				Name:                 "default",
+				ChannelPlan:          CN470ChannelPlanDefault,
+				UplinkChannels:       96,
+				DownlinkChannels:     48,
+				RX2Frequency:         505300000,
+				UplinkFrequency:      479700000,
+				ExpectedRX1Frequency: 509700000,
+			},
+			{
+				Name:                 "20 MHz type A",
+				ChannelPlan:          CN470ChannelPlan20MHzA,
+				UplinkChannels:       64,
+				DownlinkChannels:     64,
+				RX2Frequency:         485300000,
+				UplinkFrequency:      503500000,
+				ExpectedRX1Frequency: 490300000,
+			},
+			{
+				Name:                 "20 MHz type B",
+				ChannelPlan:          CN470ChannelPlan20MHzB,
+				UplinkChannels:       64,
+				DownlinkChannels:     64,
+				RX2Frequency:         486900000,
+				UplinkFrequency:      490300000,
+				ExpectedRX1Frequency: 490300000,
+			},
+			{
+				Name:                 "26 MHz type A",
+				ChannelPlan:          CN470ChannelPlan26MHzA,
+				UplinkChannels:       48,
+				DownlinkChannels:     24,
+				RX2Frequency:         492500000,
+				UplinkFrequency:      475100000,
+				ExpectedRX1Frequency: 490100000,
+			},
+			{
+				Name:                 "26 MHz type B",
+				ChannelPlan:          CN470ChannelPlan26MHzB,
+				UplinkChannels:       48,
+				DownlinkChannels:     24,
+				RX2Frequency:         502500000,
+				UplinkFrequency:      480500000,
+				ExpectedRX1Frequency: 500300000,
+			},
+		}
+
+		for i, test := range tests {
+			Convey(fmt.Sprintf("Testing: %s [%d]", test.Name, i), func() {
+				b, err := GetConfigWithOptions(CN470, Options{CN470ChannelPlan: test.ChannelPlan})
+				So(err, ShouldBeNil)
+
+				So(b.GetUplinkChannelIndices(), ShouldHaveLength, test.UplinkChannels)
+				So(b.GetDefaults().RX2Frequency, ShouldEqual, test.RX2Frequency)
+
+				f, err := b.GetRX1FrequencyForUplinkFrequency(test.UplinkFrequency)
+				So(err, ShouldBeNil)
+				So(f, ShouldEqual, test.ExpectedRX1Frequency)
+
+				_, err = b.GetDownlinkChannel(test.DownlinkChannels - 1)
+				So(err, ShouldBeNil)
+				_, err = b.GetDownlinkChannel(test.DownlinkChannels)
+				So(err, ShouldNotBeNil)
+			})
+		}
+
+		Convey("Then an invalid channel plan returns an error", func() {
+			_, err := GetConfigWithOptions(CN470, Options{CN470ChannelPlan: 10})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Then setting the channel plan for a non-CN470 band returns an error", func() {
+			_, err := GetConfigWithOptions(EU868, Options{CN470ChannelPlan: CN470ChannelPlan20MHzA})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
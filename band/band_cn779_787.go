@@ -14,17 +14,6 @@ func (b *cn779Band) Name() string {
 	return "CN779"
 }
 
-func (b *cn779Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     786000000,
-		RX2DataRate:      0,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *cn779Band) GetDownlinkTXPower(freq uint32) int {
 	return 10
 }
@@ -49,10 +38,20 @@ func (b *cn779Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return false
 }
 
-func newCN779Band(repeaterCompatible bool) (Band, error) {
+func newCN779Band(opts Options) (Band, error) {
 	b := cn779Band{
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     786000000,
+				RX2DataRate:      0,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options:               opts,
 			supportsExtraChannels: true,
+			frequencyRange:        frequencyRanges[CN779],
 			cFListMinDR:           0,
 			cFListMaxDR:           5,
 			dataRates: map[int]DataRate{
@@ -97,7 +96,7 @@ func newCN779Band(repeaterCompatible bool) (Band, error) {
 		},
 	}
 
-	if repeaterCompatible {
+	if opts.RepeaterCompatible {
 		b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 			LoRaWAN_1_0_0: map[string]map[int]MaxPayloadSize{
 				latest: map[int]MaxPayloadSize{ // LoRaWAN 1.0.0
@@ -14,27 +14,13 @@ func (b *eu863Band) Name() string {
 	return "EU868"
 }
 
-func (b *eu863Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     869525000,
-		RX2DataRate:      0,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *eu863Band) GetDownlinkTXPower(freq uint32) int {
-	// NOTE: as there are currently no further boundary checks on the frequency, this check is sufficient.
-	// TODO: However, there should be some mechanism, that checks the frequency for compliance to regulations.
-	if 863000000 <= freq && freq < 869200000 {
-		return 14 //25mW
-	} else if 869400000 <= freq && freq < 869650000 {
-		return 27 //500mW
-	} else {
-		return 14 // Default case
+	for _, sb := range dutyCycleSubBands[EU868] {
+		if sb.Contains(freq) {
+			return sb.TXPower
+		}
 	}
+	return 14 // Default case
 }
 
 func (b *eu863Band) GetDefaultMaxUplinkEIRP() float32 {
@@ -57,10 +43,20 @@ func (b *eu863Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return false
 }
 
-func newEU863Band(repeatedCompatible bool) (Band, error) {
+func newEU863Band(opts Options) (Band, error) {
 	b := eu863Band{
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     869525000,
+				RX2DataRate:      0,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options:               opts,
 			supportsExtraChannels: true,
+			frequencyRange:        frequencyRanges[EU868],
 			cFListMinDR:           0,
 			cFListMaxDR:           5,
 			dataRates: map[int]DataRate{
@@ -114,7 +110,7 @@ func newEU863Band(repeatedCompatible bool) (Band, error) {
 		},
 	}
 
-	if repeatedCompatible {
+	if opts.RepeaterCompatible {
 		b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 			LoRaWAN_1_0_0: map[string]map[int]MaxPayloadSize{
 				latest: map[int]MaxPayloadSize{ // LoRaWAN 1.0.0
@@ -131,6 +131,69 @@ func TestEU863Band(t *testing.T) {
 			}
 		})
 
+		Convey("When testing ApplyNewChannelReqPayload", func() {
+			states := GetUplinkChannelStates(band)
+
+			Convey("Then a default channel can not be modified", func() {
+				_, err := band.ApplyNewChannelReqPayload(states, lorawan.NewChannelReqPayload{ChIndex: 1, Freq: 867100000, MaxDR: 5})
+				So(err, ShouldEqual, ErrDefaultChannelNotModifiable)
+			})
+
+			Convey("Then a channel index >= 16 returns an error", func() {
+				_, err := band.ApplyNewChannelReqPayload(states, lorawan.NewChannelReqPayload{ChIndex: 16, Freq: 867100000, MaxDR: 5})
+				So(err, ShouldEqual, ErrChannelDoesNotExist)
+			})
+
+			Convey("Then an invalid data-rate range returns an error", func() {
+				_, err := band.ApplyNewChannelReqPayload(states, lorawan.NewChannelReqPayload{ChIndex: 3, Freq: 867100000, MinDR: 5, MaxDR: 3})
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then a frequency outside the band returns an error", func() {
+				_, err := band.ApplyNewChannelReqPayload(states, lorawan.NewChannelReqPayload{ChIndex: 3, Freq: 915100000, MaxDR: 5})
+				So(err, ShouldEqual, ErrFrequencyOutOfRange)
+			})
+
+			Convey("Then missing default channels return an error", func() {
+				_, err := band.ApplyNewChannelReqPayload(nil, lorawan.NewChannelReqPayload{ChIndex: 4, Freq: 867300000, MaxDR: 5})
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("When adding channel 4", func() {
+				out, err := band.ApplyNewChannelReqPayload(states, lorawan.NewChannelReqPayload{ChIndex: 4, Freq: 867300000, MaxDR: 5})
+				So(err, ShouldBeNil)
+
+				Convey("Then channel 3 has been added as disabled channel", func() {
+					So(out, ShouldHaveLength, 5)
+					So(out[3], ShouldResemble, ChannelState{Index: 3, Custom: true})
+				})
+
+				Convey("Then channel 4 has the expected frequency and data-rates", func() {
+					So(out[4], ShouldResemble, ChannelState{Index: 4, Frequency: 867300000, MinDR: 0, MaxDR: 5, Enabled: true, Custom: true})
+				})
+
+				Convey("Then the band and the given states are not modified", func() {
+					So(states, ShouldHaveLength, 3)
+					So(band.GetUplinkChannelIndices(), ShouldResemble, []int{0, 1, 2})
+					So(band.GetCustomUplinkChannelIndices(), ShouldBeEmpty)
+				})
+
+				Convey("When applying frequency 0 to channel 4", func() {
+					out, err = band.ApplyNewChannelReqPayload(out, lorawan.NewChannelReqPayload{ChIndex: 4})
+					So(err, ShouldBeNil)
+
+					Convey("Then channel 4 is disabled", func() {
+						So(out, ShouldHaveLength, 5)
+						So(out[4], ShouldResemble, ChannelState{Index: 4, Custom: true})
+					})
+				})
+			})
+		})
+
+		Convey("Then sub-bands are not supported", func() {
+			So(band.EnableSubBand(1), ShouldEqual, ErrSubBandNotSupported)
+		})
+
 		Convey("Given five extra channels", func() {
 			chans := []uint32{
 				867100000,
@@ -259,6 +322,14 @@ func TestEU863Band(t *testing.T) {
 				}
 			})
 
+			Convey("Then ValidateCFList only permits a CFList with channels", func() {
+				So(band.ValidateCFList(LoRaWAN_1_0_2, band.GetCFList(LoRaWAN_1_0_2)), ShouldBeNil)
+				So(band.ValidateCFList(LoRaWAN_1_1_0, &lorawan.CFList{
+					CFListType: lorawan.CFListChannelMask,
+					Payload:    &lorawan.CFListChannelMaskPayload{},
+				}), ShouldEqual, ErrCFListNotPermitted)
+			})
+
 			Convey("Then GetCFList returns the expected CFList", func() {
 				cFList := band.GetCFList(LoRaWAN_1_0_2)
 				So(cFList, ShouldNotBeNil)
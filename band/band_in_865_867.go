@@ -14,17 +14,6 @@ func (b *in865Band) Name() string {
 	return "IN865"
 }
 
-func (b *in865Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     866550000,
-		RX2DataRate:      2,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *in865Band) GetDownlinkTXPower(freq uint32) int {
 	return 27
 }
@@ -49,10 +38,20 @@ func (b *in865Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return false
 }
 
-func newIN865Band(repeaterCompatible bool) (Band, error) {
+func newIN865Band(opts Options) (Band, error) {
 	b := in865Band{
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     866550000,
+				RX2DataRate:      2,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options:               opts,
 			supportsExtraChannels: true,
+			frequencyRange:        frequencyRanges[IN865],
 			cFListMinDR:           0,
 			cFListMaxDR:           5,
 			dataRates: map[int]DataRate{
@@ -102,7 +101,7 @@ func newIN865Band(repeaterCompatible bool) (Band, error) {
 		},
 	}
 
-	if repeaterCompatible {
+	if opts.RepeaterCompatible {
 		b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 			LoRaWAN_1_0_2: map[string]map[int]MaxPayloadSize{
 				latest: map[int]MaxPayloadSize{ // LoRaWAN 1.0.2B
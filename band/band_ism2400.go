@@ -15,17 +15,6 @@ func (b *ism2400Band) Name() string {
 	return "ISM2400"
 }
 
-func (b *ism2400Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     2423000000,
-		RX2DataRate:      0,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *ism2400Band) GetDownlinkTXPower(freq uint32) int {
 	return 10
 }
@@ -50,10 +39,20 @@ func (b *ism2400Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return true
 }
 
-func newISM2400Band(repeaterCompatible bool) (Band, error) {
+func newISM2400Band(opts Options) (Band, error) {
 	b := ism2400Band{
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     2423000000,
+				RX2DataRate:      0,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options:               opts,
 			supportsExtraChannels: true,
+			frequencyRange:        frequencyRanges[ISM2400],
 			cFListMinDR:           0,
 			cFListMaxDR:           7,
 			dataRates: map[int]DataRate{
@@ -99,7 +98,7 @@ func newISM2400Band(repeaterCompatible bool) (Band, error) {
 		},
 	}
 
-	if repeaterCompatible {
+	if opts.RepeaterCompatible {
 		b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 			latest: map[string]map[int]MaxPayloadSize{
 				latest: map[int]MaxPayloadSize{
@@ -14,17 +14,6 @@ func (b *kr920Band) Name() string {
 	return "KR920"
 }
 
-func (b *kr920Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     921900000,
-		RX2DataRate:      0,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *kr920Band) GetDownlinkTXPower(freq uint32) int {
 	return 23
 }
@@ -49,10 +38,20 @@ func (b *kr920Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return false
 }
 
-func newKR920Band(repeaterCompatible bool) (Band, error) {
+func newKR920Band(opts Options) (Band, error) {
 	b := kr920Band{
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     921900000,
+				RX2DataRate:      0,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options:               opts,
 			supportsExtraChannels: true,
+			frequencyRange:        frequencyRanges[KR920],
 			cFListMinDR:           0,
 			cFListMaxDR:           5,
 			dataRates: map[int]DataRate{
@@ -97,7 +96,7 @@ func newKR920Band(repeaterCompatible bool) (Band, error) {
 		},
 	}
 
-	if repeaterCompatible {
+	if opts.RepeaterCompatible {
 		b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 			LoRaWAN_1_0_2: map[string]map[int]MaxPayloadSize{
 				latest: map[int]MaxPayloadSize{ // LoRaWAN 1.0.2B
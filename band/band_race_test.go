@@ -0,0 +1,95 @@
+package band
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+// TestBandConcurrency must be run with -race to detect data races between
+// band (channel) mutations and the methods reading the channels.
+func TestBandConcurrency(t *testing.T) {
+	t.Run("EU868", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+		assert.NoError(err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(4)
+
+			go func(i int) {
+				defer wg.Done()
+				_ = b.AddChannel(uint32(867100000+(i%5)*200000), 0, 5)
+			}(i)
+
+			go func() {
+				defer wg.Done()
+				_, _ = b.ApplyNewChannelReqPayload(GetUplinkChannelStates(b), lorawan.NewChannelReqPayload{ChIndex: 3, Freq: 867100000, MinDR: 0, MaxDR: 5})
+			}()
+
+			go func(i int) {
+				defer wg.Done()
+				if i%2 == 0 {
+					_ = b.DisableUplinkChannelIndex(0)
+				} else {
+					_ = b.EnableUplinkChannelIndex(0)
+				}
+			}(i)
+
+			go func() {
+				defer wg.Done()
+				b.GetEnabledUplinkChannelIndices()
+				b.GetCFList(LoRaWAN_1_0_3)
+				_, _ = b.GetUplinkChannelIndex(868100000, true)
+				_, _ = b.GetUplinkChannel(0)
+				b.GetLinkADRReqPayloadsForEnabledUplinkChannelIndices([]int{0, 1, 2})
+			}()
+		}
+		wg.Wait()
+	})
+
+	for _, name := range []Name{US915, AU915} {
+		t.Run(string(name), func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := GetConfig(name, false, lorawan.DwellTime400ms)
+			assert.NoError(err)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(3)
+
+				go func(i int) {
+					defer wg.Done()
+					assert.NoError(b.EnableSubBand(i%8 + 1))
+				}(i)
+
+				go func(i int) {
+					defer wg.Done()
+					if i%2 == 0 {
+						_ = b.DisableUplinkChannelIndex(64)
+					} else {
+						_ = b.EnableUplinkChannelIndex(64)
+					}
+				}(i)
+
+				go func() {
+					defer wg.Done()
+					b.GetEnabledUplinkChannelIndices()
+					_, _ = b.GetSubBandCFList(1)
+					_, _ = b.GetUplinkChannelIndexForFrequencyDR(902300000, 0)
+					b.GetLinkADRReqPayloadsForEnabledUplinkChannelIndices([]int{0, 1, 2})
+					_, _ = b.GetEnabledUplinkChannelIndicesForLinkADRReqPayloads([]int{0, 1, 2}, []lorawan.LinkADRReqPayload{
+						{Redundancy: lorawan.Redundancy{ChMaskCntl: 7}, ChMask: lorawan.ChMask{true}},
+					})
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
@@ -14,17 +14,6 @@ func (b *ru864Band) Name() string {
 	return "RU864"
 }
 
-func (b *ru864Band) GetDefaults() Defaults {
-	return Defaults{
-		RX2Frequency:     869100000,
-		RX2DataRate:      0,
-		ReceiveDelay1:    time.Second,
-		ReceiveDelay2:    time.Second * 2,
-		JoinAcceptDelay1: time.Second * 5,
-		JoinAcceptDelay2: time.Second * 6,
-	}
-}
-
 func (b *ru864Band) GetDownlinkTXPower(freq uint32) int {
 	return 14
 }
@@ -49,10 +38,20 @@ func (b *ru864Band) ImplementsTXParamSetup(protocolVersion string) bool {
 	return false
 }
 
-func newRU864Band(repeaterCompatible bool) (Band, error) {
+func newRU864Band(opts Options) (Band, error) {
 	b := ru864Band{
 		band: band{
+			defaults: Defaults{
+				RX2Frequency:     869100000,
+				RX2DataRate:      0,
+				ReceiveDelay1:    time.Second,
+				ReceiveDelay2:    time.Second * 2,
+				JoinAcceptDelay1: time.Second * 5,
+				JoinAcceptDelay2: time.Second * 6,
+			},
+			options:               opts,
 			supportsExtraChannels: true,
+			frequencyRange:        frequencyRanges[RU864],
 			cFListMinDR:           0,
 			cFListMaxDR:           5,
 			dataRates: map[int]DataRate{
@@ -97,7 +96,7 @@ func newRU864Band(repeaterCompatible bool) (Band, error) {
 		},
 	}
 
-	if repeaterCompatible {
+	if opts.RepeaterCompatible {
 		b.band.maxPayloadSizePerDR = map[string]map[string]map[int]MaxPayloadSize{
 			LoRaWAN_1_0_3: map[string]map[int]MaxPayloadSize{
 				latest: map[int]MaxPayloadSize{ // LoRaWAN 1.0.3A
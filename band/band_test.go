@@ -0,0 +1,407 @@
+package band
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brocaar/lorawan"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestName(t *testing.T) {
+	Convey("Given a set of band names", t, func() {
+		tests := []struct {
+			Str          string
+			ExpectedName Name
+			ExpectedErr  bool
+		}{
+			{Str: "EU868", ExpectedName: EU868},
+			{Str: "eu868", ExpectedName: EU868},
+			{Str: "EU_863_870", ExpectedName: EU868},
+			{Str: "as_923", ExpectedName: AS923},
+			{Str: "as923-2", ExpectedName: AS923_2},
+			{Str: "ISM2400", ExpectedName: ISM2400},
+			{Str: "EU999", ExpectedErr: true},
+		}
+
+		for _, test := range tests {
+			Convey("Then ParseName returns the expected name for "+test.Str, func() {
+				n, err := ParseName(test.Str)
+				if test.ExpectedErr {
+					So(err, ShouldNotBeNil)
+				} else {
+					So(err, ShouldBeNil)
+					So(n, ShouldEqual, test.ExpectedName)
+				}
+			})
+		}
+	})
+
+	Convey("Given a deprecated band name", t, func() {
+		n := US_902_928
+
+		Convey("Then IsDeprecated returns true", func() {
+			So(n.IsDeprecated(), ShouldBeTrue)
+			So(US915.IsDeprecated(), ShouldBeFalse)
+		})
+
+		Convey("Then CommonName returns the common name", func() {
+			So(n.CommonName(), ShouldEqual, US915)
+			So(US915.CommonName(), ShouldEqual, US915)
+		})
+
+		Convey("Then GetNameAliases contains the alias", func() {
+			So(GetNameAliases()[n], ShouldEqual, US915)
+		})
+
+		Convey("Then GetConfig accepts the lower-case name", func() {
+			b, err := GetConfig(Name("us_902_928"), false, lorawan.DwellTimeNoLimit)
+			So(err, ShouldBeNil)
+			So(b.Name(), ShouldEqual, "US915")
+		})
+	})
+}
+
+func TestDetect(t *testing.T) {
+	Convey("Given a set of frequencies", t, func() {
+		tests := []struct {
+			Frequency     uint32
+			ExpectedNames []Name
+		}{
+			{Frequency: 868100000, ExpectedNames: []Name{EU868, RU864}},
+			{Frequency: 433175000, ExpectedNames: []Name{EU433}},
+			{Frequency: 902300000, ExpectedNames: []Name{US915}},
+			{Frequency: 923200000, ExpectedNames: []Name{US915, AU915, AS923, AS923_2, AS923_3, AS923_4, KR920}},
+			{Frequency: 2403000000, ExpectedNames: []Name{ISM2400}},
+			{Frequency: 100000000},
+		}
+
+		for i, test := range tests {
+			Convey(fmt.Sprintf("Then Detect returns the expected names [%d]", i), func() {
+				So(Detect(test.Frequency), ShouldResemble, test.ExpectedNames)
+			})
+		}
+	})
+}
+
+func TestGetConfigWithOptions(t *testing.T) {
+	Convey("Given a set of options", t, func() {
+		dr := 3
+
+		Convey("When setting the AS923 group", func() {
+			b, err := GetConfigWithOptions(AS923, Options{AS923Group: 3})
+			So(err, ShouldBeNil)
+
+			Convey("Then the expected band is returned", func() {
+				So(b.Name(), ShouldEqual, "AS923-3")
+			})
+		})
+
+		Convey("Then an invalid AS923 group returns an error", func() {
+			_, err := GetConfigWithOptions(AS923, Options{AS923Group: 5})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Then setting the AS923 group for a non-AS923 band returns an error", func() {
+			_, err := GetConfigWithOptions(EU868, Options{AS923Group: 2})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When overriding the RX2 parameters", func() {
+			b, err := GetConfigWithOptions(EU868, Options{
+				RX2Frequency: 869525000 + 200000,
+				RX2DataRate:  &dr,
+			})
+			So(err, ShouldBeNil)
+
+			Convey("Then GetDefaults returns the overridden values", func() {
+				d := b.GetDefaults()
+				So(d.RX2Frequency, ShouldEqual, 869725000)
+				So(d.RX2DataRate, ShouldEqual, 3)
+			})
+
+			Convey("Then GetRegionalDefaults returns the regional values", func() {
+				d := b.GetRegionalDefaults()
+				So(d.RX2Frequency, ShouldEqual, 869525000)
+				So(d.RX2DataRate, ShouldEqual, 0)
+			})
+
+			Convey("Then the other band methods are not affected", func() {
+				So(b.Name(), ShouldEqual, "EU868")
+				So(b.GetUplinkChannelIndices(), ShouldResemble, []int{0, 1, 2})
+			})
+		})
+
+		Convey("Then an invalid RX2 data-rate returns an error", func() {
+			invalidDR := 15
+			_, err := GetConfigWithOptions(EU868, Options{RX2DataRate: &invalidDR})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When setting the Regional Parameters revision", func() {
+			b, err := GetConfigWithOptions(AU915, Options{
+				DwellTime:         lorawan.DwellTime400ms,
+				RegParamsRevision: RegParamRevA,
+			})
+			So(err, ShouldBeNil)
+
+			Convey("Then it is used when no revision is given", func() {
+				exp, err := b.GetMaxPayloadSizeForDataRateIndex(LoRaWAN_1_0_2, RegParamRevA, 0)
+				So(err, ShouldBeNil)
+
+				ps, err := b.GetMaxPayloadSizeForDataRateIndex(LoRaWAN_1_0_2, "", 0)
+				So(err, ShouldBeNil)
+				So(ps, ShouldResemble, exp)
+			})
+		})
+	})
+}
+
+func TestPingSlotChannelReqPayload(t *testing.T) {
+	Convey("Given the US915 band", t, func() {
+		b, err := GetConfig(US915, false, lorawan.DwellTimeNoLimit)
+		So(err, ShouldBeNil)
+
+		devAddr := lorawan.DevAddr{3, 20, 207, 54}
+		beaconTime := 334382*time.Hour + 52*time.Minute + 44*time.Second
+
+		Convey("Then frequency 0 returns the default ping-slot frequency", func() {
+			pl := lorawan.PingSlotChannelReqPayload{DR: 8}
+			So(ValidatePingSlotChannelReqPayload(b, pl), ShouldResemble, lorawan.PingSlotChannelAnsPayload{
+				DataRateOK:         true,
+				ChannelFrequencyOK: true,
+			})
+
+			exp, err := b.GetPingSlotFrequency(devAddr, beaconTime)
+			So(err, ShouldBeNil)
+
+			f, err := GetPingSlotFrequencyForPingSlotChannelReqPayload(b, pl, devAddr, beaconTime)
+			So(err, ShouldBeNil)
+			So(f, ShouldEqual, exp)
+		})
+
+		Convey("Then a custom frequency is returned as-is", func() {
+			pl := lorawan.PingSlotChannelReqPayload{Frequency: 923900000, DR: 8}
+			f, err := GetPingSlotFrequencyForPingSlotChannelReqPayload(b, pl, devAddr, beaconTime)
+			So(err, ShouldBeNil)
+			So(f, ShouldEqual, 923900000)
+		})
+
+		Convey("Then a frequency outside the band is rejected", func() {
+			pl := lorawan.PingSlotChannelReqPayload{Frequency: 868100000, DR: 8}
+			So(ValidatePingSlotChannelReqPayload(b, pl).ChannelFrequencyOK, ShouldBeFalse)
+
+			_, err := GetPingSlotFrequencyForPingSlotChannelReqPayload(b, pl, devAddr, beaconTime)
+			So(err, ShouldEqual, ErrFrequencyOutOfRange)
+		})
+
+		Convey("Then an uplink-only data-rate is rejected", func() {
+			pl := lorawan.PingSlotChannelReqPayload{DR: 0}
+			So(ValidatePingSlotChannelReqPayload(b, pl).DataRateOK, ShouldBeFalse)
+
+			_, err := GetPingSlotFrequencyForPingSlotChannelReqPayload(b, pl, devAddr, beaconTime)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestComputeRXWindows(t *testing.T) {
+	Convey("Given the EU868 band defaults and an uplink time", t, func() {
+		b, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+		So(err, ShouldBeNil)
+		defaults := b.GetDefaults()
+		uplinkTime := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		Convey("Then GetRXDelay returns the expected values", func() {
+			So(GetRXDelay(0), ShouldEqual, time.Second)
+			So(GetRXDelay(1), ShouldEqual, time.Second)
+			So(GetRXDelay(15), ShouldEqual, 15*time.Second)
+		})
+
+		Convey("When computing the windows without RXDelay", func() {
+			w := ComputeRXWindows(uplinkTime, 0, defaults)
+
+			Convey("Then the band defaults are used", func() {
+				So(w.RX1.Time, ShouldResemble, uplinkTime.Add(time.Second))
+				So(w.RX2.Time, ShouldResemble, uplinkTime.Add(2*time.Second))
+			})
+
+			Convey("Then the tolerance is applied", func() {
+				So(w.RX1.Earliest, ShouldResemble, uplinkTime.Add(time.Second-RXWindowTolerance))
+				So(w.RX1.Latest, ShouldResemble, uplinkTime.Add(time.Second+RXWindowTolerance))
+			})
+		})
+
+		Convey("When computing the windows with RXDelay=5", func() {
+			w := ComputeRXWindows(uplinkTime, 5, defaults)
+
+			Convey("Then RX1 opens after 5 seconds and RX2 one second later", func() {
+				So(w.RX1.Time, ShouldResemble, uplinkTime.Add(5*time.Second))
+				So(w.RX2.Time, ShouldResemble, uplinkTime.Add(6*time.Second))
+			})
+		})
+
+		Convey("When computing the join-accept windows", func() {
+			w := ComputeJoinAcceptRXWindows(uplinkTime, defaults)
+
+			Convey("Then the join-accept delays are used", func() {
+				So(w.RX1.Time, ShouldResemble, uplinkTime.Add(5*time.Second))
+				So(w.RX2.Time, ShouldResemble, uplinkTime.Add(6*time.Second))
+			})
+		})
+	})
+}
+
+func TestGetJoinAcceptRXParameters(t *testing.T) {
+	rx2DR := 3
+
+	tests := []struct {
+		name            string
+		band            Name
+		opts            Options
+		uplinkFrequency uint32
+		uplinkDR        int
+		expected        JoinAcceptRXParameters
+	}{
+		{
+			name:            "EU868",
+			band:            EU868,
+			uplinkFrequency: 868300000,
+			uplinkDR:        5,
+			expected: JoinAcceptRXParameters{
+				RX1Delay:     5 * time.Second,
+				RX2Delay:     6 * time.Second,
+				RX1Frequency: 868300000,
+				RX1DataRate:  5,
+				RX2Frequency: 869525000,
+				RX2DataRate:  0,
+			},
+		},
+		{
+			name: "EU868 with RX2 overrides",
+			band: EU868,
+			opts: Options{
+				RX2Frequency: 869525000,
+				RX2DataRate:  &rx2DR,
+			},
+			uplinkFrequency: 868100000,
+			uplinkDR:        0,
+			expected: JoinAcceptRXParameters{
+				RX1Delay:     5 * time.Second,
+				RX2Delay:     6 * time.Second,
+				RX1Frequency: 868100000,
+				RX1DataRate:  0,
+				RX2Frequency: 869525000,
+				RX2DataRate:  0,
+			},
+		},
+		{
+			name:            "US915",
+			band:            US915,
+			uplinkFrequency: 902300000,
+			uplinkDR:        3,
+			expected: JoinAcceptRXParameters{
+				RX1Delay:     5 * time.Second,
+				RX2Delay:     6 * time.Second,
+				RX1Frequency: 923300000,
+				RX1DataRate:  13,
+				RX2Frequency: 923300000,
+				RX2DataRate:  8,
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := GetConfigWithOptions(tst.band, tst.opts)
+			assert.NoError(err)
+
+			params, err := GetJoinAcceptRXParameters(b, tst.uplinkFrequency, tst.uplinkDR)
+			assert.NoError(err)
+			assert.Equal(tst.expected, params)
+
+			if tst.opts.RX2DataRate != nil {
+				assert.Equal(*tst.opts.RX2DataRate, b.GetDefaults().RX2DataRate)
+			}
+		})
+	}
+
+	t.Run("Invalid uplink frequency", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := GetConfig(US915, false, lorawan.DwellTimeNoLimit)
+		assert.NoError(err)
+
+		_, err = GetJoinAcceptRXParameters(b, 868100000, 0)
+		assert.Error(err)
+	})
+}
+
+func TestGetMaxPHYPayloadSize(t *testing.T) {
+	Convey("Given the EU868 band", t, func() {
+		b, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+		So(err, ShouldBeNil)
+
+		Convey("Then GetMaxPHYPayloadSize returns the expected value for DR0", func() {
+			size, err := GetMaxPHYPayloadSize(b, LoRaWAN_1_0_3, RegParamRevA, 0)
+			So(err, ShouldBeNil)
+			So(size, ShouldEqual, 64)
+		})
+
+		Convey("Then GetMaxPHYPayloadSize returns an error for an invalid data-rate", func() {
+			_, err := GetMaxPHYPayloadSize(b, LoRaWAN_1_0_3, RegParamRevA, 16)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestDataRateSensitivity(t *testing.T) {
+	Convey("Given the EU868 band", t, func() {
+		b, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+		So(err, ShouldBeNil)
+
+		Convey("Then GetRequiredSNRForDataRateIndex returns -20 for DR0 (SF12)", func() {
+			snr, err := b.GetRequiredSNRForDataRateIndex(0)
+			So(err, ShouldBeNil)
+			So(snr, ShouldEqual, -20)
+		})
+
+		Convey("Then GetRequiredSNRForDataRateIndex returns -7.5 for DR5 (SF7)", func() {
+			snr, err := b.GetRequiredSNRForDataRateIndex(5)
+			So(err, ShouldBeNil)
+			So(snr, ShouldEqual, -7.5)
+		})
+
+		Convey("Then GetRequiredSNRForDataRateIndex returns an error for DR7 (FSK)", func() {
+			_, err := b.GetRequiredSNRForDataRateIndex(7)
+			So(err.Error(), ShouldEqual, "lorawan/band: required snr is not defined for FSK modulation")
+		})
+
+		Convey("Then GetSensitivityForDataRateIndex returns -137 dBm for DR0 (SF12)", func() {
+			sens, err := b.GetSensitivityForDataRateIndex(0)
+			So(err, ShouldBeNil)
+			So(sens, ShouldAlmostEqual, -137, 0.1)
+		})
+
+		Convey("Then GetSensitivityForDataRateIndex returns -121.5 dBm for DR6 (SF7 / 250 kHz)", func() {
+			sens, err := b.GetSensitivityForDataRateIndex(6)
+			So(err, ShouldBeNil)
+			So(sens, ShouldAlmostEqual, -121.5, 0.1)
+		})
+
+		Convey("Then GetSensitivityForDataRateIndex returns an error for an invalid data-rate", func() {
+			_, err := b.GetSensitivityForDataRateIndex(16)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Then GetLinkBudgetForDataRateIndex returns 151 dB for DR0 and 14 dBm", func() {
+			lb, err := b.GetLinkBudgetForDataRateIndex(0, 14)
+			So(err, ShouldBeNil)
+			So(lb, ShouldAlmostEqual, 151, 0.1)
+		})
+	})
+}
@@ -29,6 +29,11 @@ func TestUS902Band(t *testing.T) {
 			So(band.GetDownlinkTXPower(0), ShouldEqual, 20)
 		})
 
+		Convey("Then ApplyNewChannelReqPayload returns an error", func() {
+			_, err := band.ApplyNewChannelReqPayload(GetUplinkChannelStates(band), lorawan.NewChannelReqPayload{ChIndex: 3, Freq: 902300000, MaxDR: 3})
+			So(err, ShouldEqual, ErrNewChannelReqNotSupported)
+		})
+
 		Convey("Then GetPingSlotFrequency returns the expected value", func() {
 			tests := []struct {
 				DevAddr           lorawan.DevAddr
@@ -221,6 +226,14 @@ func TestUS902Band(t *testing.T) {
 				filteredChans = append(filteredChans, i)
 			}
 
+			var all125kHzChans, all125kHzChansExcept20 []int
+			for i := 0; i < 65; i++ {
+				all125kHzChans = append(all125kHzChans, i)
+				if i != 20 {
+					all125kHzChansExcept20 = append(all125kHzChansExcept20, i)
+				}
+			}
+
 			tests := []struct {
 				Name                       string
 				NodeChannels               []int
@@ -295,6 +308,34 @@ func TestUS902Band(t *testing.T) {
 						},
 					},
 				},
+				{
+					Name:                   "activate all 125 kHz channels",
+					NodeChannels:           []int{0, 1, 2, 3, 4, 5, 6, 7, 64},
+					DisableChannels:        []int{65, 66, 67, 68, 69, 70, 71},
+					ExpectedUplinkChannels: all125kHzChans,
+					ExpectedLinkADRReqPayloads: []lorawan.LinkADRReqPayload{
+						{
+							ChMask:     lorawan.ChMask{true},
+							Redundancy: lorawan.Redundancy{ChMaskCntl: 6},
+						},
+					},
+				},
+				{
+					Name:                   "activate all 125 kHz channels except channel 20",
+					NodeChannels:           []int{0, 1, 2, 3, 4, 5, 6, 7, 64},
+					DisableChannels:        []int{20, 65, 66, 67, 68, 69, 70, 71},
+					ExpectedUplinkChannels: all125kHzChansExcept20,
+					ExpectedLinkADRReqPayloads: []lorawan.LinkADRReqPayload{
+						{
+							ChMask:     lorawan.ChMask{true},
+							Redundancy: lorawan.Redundancy{ChMaskCntl: 6},
+						},
+						{
+							ChMask:     lorawan.ChMask{true, true, true, true, false, true, true, true, true, true, true, true, true, true, true, true},
+							Redundancy: lorawan.Redundancy{ChMaskCntl: 1},
+						},
+					},
+				},
 			}
 
 			for i, test := range tests {
@@ -314,5 +355,60 @@ func TestUS902Band(t *testing.T) {
 				})
 			}
 		})
+
+		Convey("When enabling sub-band 2", func() {
+			So(band.EnableSubBand(2), ShouldBeNil)
+
+			Convey("Then only channels 8 - 15 and 65 are enabled", func() {
+				So(band.GetEnabledUplinkChannelIndices(), ShouldResemble, []int{8, 9, 10, 11, 12, 13, 14, 15, 65})
+			})
+
+			Convey("Then GetSubBandLinkADRReqPayloads returns the payloads for the sub-band", func() {
+				pls, err := band.GetSubBandLinkADRReqPayloads(2)
+				So(err, ShouldBeNil)
+				So(pls, ShouldResemble, []lorawan.LinkADRReqPayload{
+					{
+						ChMask:     lorawan.ChMask{false, true},
+						Redundancy: lorawan.Redundancy{ChMaskCntl: 7},
+					},
+					{
+						ChMask:     lorawan.ChMask{false, false, false, false, false, false, false, false, true, true, true, true, true, true, true, true},
+						Redundancy: lorawan.Redundancy{ChMaskCntl: 0},
+					},
+				})
+
+				chans, err := band.GetEnabledUplinkChannelIndicesForLinkADRReqPayloads(band.GetUplinkChannelIndices(), pls)
+				So(err, ShouldBeNil)
+				So(chans, ShouldResemble, band.GetEnabledUplinkChannelIndices())
+			})
+
+			Convey("Then GetSubBandCFList returns the same channel-mask as GetCFList", func() {
+				cFList, err := band.GetSubBandCFList(2)
+				So(err, ShouldBeNil)
+				So(cFList, ShouldResemble, band.GetCFList(LoRaWAN_1_0_3))
+			})
+		})
+
+		Convey("Then ValidateCFList validates the CFList", func() {
+			cFList := band.GetCFList(LoRaWAN_1_0_3)
+			So(band.ValidateCFList(LoRaWAN_1_0_3, cFList), ShouldBeNil)
+			So(band.ValidateCFList(LoRaWAN_1_0_2, cFList), ShouldEqual, ErrCFListNotPermitted)
+			So(band.ValidateCFList(LoRaWAN_1_0_2, nil), ShouldBeNil)
+			So(band.ValidateCFList(LoRaWAN_1_0_3, &lorawan.CFList{
+				CFListType: lorawan.CFListChannel,
+				Payload:    &lorawan.CFListChannelPayload{},
+			}), ShouldEqual, ErrCFListNotPermitted)
+			So(band.ValidateCFList(LoRaWAN_1_0_3, &lorawan.CFList{
+				CFListType: lorawan.CFListChannelMask,
+				Payload: &lorawan.CFListChannelMaskPayload{
+					ChannelMasks: []lorawan.ChMask{{}, {}, {}, {}, {}, {true}},
+				},
+			}), ShouldEqual, ErrChannelDoesNotExist)
+		})
+
+		Convey("Then an invalid sub-band returns an error", func() {
+			So(band.EnableSubBand(0), ShouldNotBeNil)
+			So(band.EnableSubBand(9), ShouldNotBeNil)
+		})
 	})
 }
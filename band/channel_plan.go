@@ -0,0 +1,170 @@
+package band
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/brocaar/lorawan"
+)
+
+// GetChannelPlanHash returns a stable hash of the uplink channel
+// configuration of the given band (the band name and for each uplink channel
+// the frequency, data-rate range and if it is enabled). This hash can be
+// stored together with the device to detect that the channel-plan assumed by
+// the device diverges from the band configuration (e.g. after the
+// channel-plan was changed or after a device reset).
+func GetChannelPlanHash(b Band) string {
+	h := sha256.New()
+	h.Write([]byte(b.Name()))
+
+	enabled := make(map[int]struct{})
+	for _, i := range b.GetEnabledUplinkChannelIndices() {
+		enabled[i] = struct{}{}
+	}
+
+	for _, i := range b.GetUplinkChannelIndices() {
+		c, err := b.GetUplinkChannel(i)
+		if err != nil {
+			continue
+		}
+
+		var buf [14]byte
+		binary.LittleEndian.PutUint32(buf[0:4], uint32(i))
+		binary.LittleEndian.PutUint32(buf[4:8], c.Frequency)
+		buf[8] = uint8(c.MinDR)
+		buf[9] = uint8(c.MaxDR)
+		if _, ok := enabled[i]; ok {
+			buf[10] = 1
+		}
+		h.Write(buf[:])
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// ChannelPlanDiverged returns true when the given channel-plan hash, as
+// stored for the device, does not match the current channel-plan of the band.
+// An empty hash (e.g. the device has been reset) always diverges.
+func ChannelPlanDiverged(b Band, deviceHash string) bool {
+	return deviceHash != GetChannelPlanHash(b)
+}
+
+// GetChannelPlanResyncMACCommands returns the mac-commands to reconfigure a
+// device, which is assumed to operate with the default channels of the band
+// (e.g. after a reset), to the current channel-plan of the band.
+// For bands with a dynamic channel-plan, this returns a NewChannelReq for
+// each enabled user-defined channel. These are followed by the LinkADRReq
+// mac-commands to set the enabled channels. Note that the DataRate, TXPower
+// and NbRep of the last LinkADRReq must be set by the caller, as these are
+// applied by the device.
+func GetChannelPlanResyncMACCommands(b Band) []lorawan.MACCommand {
+	var out []lorawan.MACCommand
+
+	// after a reset, all default channels are enabled
+	deviceChannels := b.GetStandardUplinkChannelIndices()
+	enabledChannels := b.GetEnabledUplinkChannelIndices()
+
+	for _, i := range b.GetCustomUplinkChannelIndices() {
+		c, err := b.GetUplinkChannel(i)
+		if err != nil || c.Frequency == 0 || !channelIsActive(enabledChannels, i) {
+			continue
+		}
+
+		out = append(out, lorawan.MACCommand{
+			CID: lorawan.NewChannelReq,
+			Payload: &lorawan.NewChannelReqPayload{
+				ChIndex: uint8(i),
+				Freq:    c.Frequency,
+				MinDR:   uint8(c.MinDR),
+				MaxDR:   uint8(c.MaxDR),
+			},
+		})
+
+		// the NewChannelReq enables the channel
+		deviceChannels = append(deviceChannels, i)
+	}
+
+	for _, pl := range b.GetLinkADRReqPayloadsForEnabledUplinkChannelIndices(deviceChannels) {
+		pl := pl
+		out = append(out, lorawan.MACCommand{
+			CID:     lorawan.LinkADRReq,
+			Payload: &pl,
+		})
+	}
+
+	return out
+}
+
+// ChannelState defines the state of an uplink channel, e.g. to display or
+// persist the channel configuration of a band.
+type ChannelState struct {
+	Index     int    `json:"index"`
+	Frequency uint32 `json:"frequency"`
+	MinDR     int    `json:"minDR"`
+	MaxDR     int    `json:"maxDR"`
+	Enabled   bool   `json:"enabled"`
+	Custom    bool   `json:"custom"`
+}
+
+// GetUplinkChannelStates returns a snapshot of the state of all uplink
+// channels of the given band, ordered by channel index.
+func GetUplinkChannelStates(b Band) []ChannelState {
+	var out []ChannelState
+
+	for _, i := range b.GetUplinkChannelIndices() {
+		c, err := b.GetUplinkChannel(i)
+		if err != nil {
+			continue
+		}
+
+		out = append(out, ChannelState{
+			Index:     i,
+			Frequency: c.Frequency,
+			MinDR:     c.MinDR,
+			MaxDR:     c.MaxDR,
+			Enabled:   c.IsEnabled(),
+			Custom:    c.IsCustom(),
+		})
+	}
+
+	return out
+}
+
+// ApplyUplinkChannelStates restores the given snapshot (see
+// GetUplinkChannelStates) to the given band, which must be configured with
+// the same default channels. Custom channels which do not yet exist are added
+// to the band, after which the channels are enabled or disabled.
+func ApplyUplinkChannelStates(b Band, states []ChannelState) error {
+	for _, s := range states {
+		c, err := b.GetUplinkChannel(s.Index)
+		if err != nil {
+			if !s.Custom || s.Index != len(b.GetUplinkChannelIndices()) {
+				return fmt.Errorf("lorawan/band: channel %d does not exist", s.Index)
+			}
+			if err := b.AddChannel(s.Frequency, s.MinDR, s.MaxDR); err != nil {
+				return err
+			}
+			c, err = b.GetUplinkChannel(s.Index)
+			if err != nil {
+				return err
+			}
+		}
+
+		if c.Frequency != s.Frequency || c.MinDR != s.MinDR || c.MaxDR != s.MaxDR || c.IsCustom() != s.Custom {
+			return fmt.Errorf("lorawan/band: channel %d does not match the band configuration", s.Index)
+		}
+
+		if s.Enabled {
+			err = b.EnableUplinkChannelIndex(s.Index)
+		} else {
+			err = b.DisableUplinkChannelIndex(s.Index)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package band
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brocaar/lorawan"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestChannelPlan(t *testing.T) {
+	Convey("Given the EU868 band", t, func() {
+		b, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+		So(err, ShouldBeNil)
+		hash := GetChannelPlanHash(b)
+
+		Convey("Then the hash is stable", func() {
+			b2, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+			So(err, ShouldBeNil)
+			So(GetChannelPlanHash(b2), ShouldEqual, hash)
+			So(hash, ShouldHaveLength, 16)
+			So(ChannelPlanDiverged(b, hash), ShouldBeFalse)
+			So(ChannelPlanDiverged(b, ""), ShouldBeTrue)
+		})
+
+		Convey("Then no mac-commands are needed for resync", func() {
+			So(GetChannelPlanResyncMACCommands(b), ShouldHaveLength, 0)
+		})
+
+		Convey("When adding two extra channels", func() {
+			So(b.AddChannel(867100000, 0, 5), ShouldBeNil)
+			So(b.AddChannel(867300000, 0, 5), ShouldBeNil)
+
+			Convey("Then the hash has changed", func() {
+				So(ChannelPlanDiverged(b, hash), ShouldBeTrue)
+			})
+
+			Convey("Then GetChannelPlanResyncMACCommands returns the NewChannelReq mac-commands", func() {
+				So(GetChannelPlanResyncMACCommands(b), ShouldResemble, []lorawan.MACCommand{
+					{CID: lorawan.NewChannelReq, Payload: &lorawan.NewChannelReqPayload{ChIndex: 3, Freq: 867100000, MaxDR: 5}},
+					{CID: lorawan.NewChannelReq, Payload: &lorawan.NewChannelReqPayload{ChIndex: 4, Freq: 867300000, MaxDR: 5}},
+				})
+			})
+
+			Convey("Then the Channel accessors return the channel state", func() {
+				c, err := b.GetUplinkChannel(0)
+				So(err, ShouldBeNil)
+				So(c.IsEnabled(), ShouldBeTrue)
+				So(c.IsCustom(), ShouldBeFalse)
+
+				c, err = b.GetUplinkChannel(3)
+				So(err, ShouldBeNil)
+				So(c.IsEnabled(), ShouldBeTrue)
+				So(c.IsCustom(), ShouldBeTrue)
+			})
+
+			Convey("When disabling the first extra channel", func() {
+				So(b.DisableUplinkChannelIndex(3), ShouldBeNil)
+
+				Convey("Then GetUplinkChannelStates returns the channel states", func() {
+					states := GetUplinkChannelStates(b)
+					So(states, ShouldHaveLength, 5)
+					So(states[0], ShouldResemble, ChannelState{Index: 0, Frequency: 868100000, MaxDR: 5, Enabled: true})
+					So(states[3], ShouldResemble, ChannelState{Index: 3, Frequency: 867100000, MaxDR: 5, Custom: true})
+					So(states[4], ShouldResemble, ChannelState{Index: 4, Frequency: 867300000, MaxDR: 5, Enabled: true, Custom: true})
+
+					Convey("Then the states can be applied to a new band", func() {
+						b2, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+						So(err, ShouldBeNil)
+						So(ApplyUplinkChannelStates(b2, states), ShouldBeNil)
+						So(GetUplinkChannelStates(b2), ShouldResemble, states)
+						So(GetChannelPlanHash(b2), ShouldEqual, GetChannelPlanHash(b))
+					})
+
+					Convey("Then states not matching the band are rejected", func() {
+						b2, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+						So(err, ShouldBeNil)
+						So(ApplyUplinkChannelStates(b2, states[4:]), ShouldResemble, errors.New("lorawan/band: channel 4 does not exist"))
+
+						states[0].Frequency = 868300000
+						So(ApplyUplinkChannelStates(b2, states), ShouldResemble, errors.New("lorawan/band: channel 0 does not match the band configuration"))
+					})
+				})
+
+				Convey("Then GetChannelPlanResyncMACCommands skips the disabled channel", func() {
+					So(GetChannelPlanResyncMACCommands(b), ShouldResemble, []lorawan.MACCommand{
+						{CID: lorawan.NewChannelReq, Payload: &lorawan.NewChannelReqPayload{ChIndex: 4, Freq: 867300000, MaxDR: 5}},
+					})
+				})
+			})
+		})
+	})
+
+	Convey("Given the US915 band with sub-band 2 enabled", t, func() {
+		b, err := GetConfig(US915, false, lorawan.DwellTimeNoLimit)
+		So(err, ShouldBeNil)
+		hash := GetChannelPlanHash(b)
+		So(b.EnableSubBand(2), ShouldBeNil)
+
+		Convey("Then the hash has changed", func() {
+			So(ChannelPlanDiverged(b, hash), ShouldBeTrue)
+		})
+
+		Convey("Then GetChannelPlanResyncMACCommands returns LinkADRReq mac-commands enabling the sub-band", func() {
+			cmds := GetChannelPlanResyncMACCommands(b)
+			So(cmds, ShouldNotBeEmpty)
+
+			var pls []lorawan.LinkADRReqPayload
+			for _, cmd := range cmds {
+				So(cmd.CID, ShouldEqual, lorawan.LinkADRReq)
+				pls = append(pls, *cmd.Payload.(*lorawan.LinkADRReqPayload))
+			}
+
+			chans, err := b.GetEnabledUplinkChannelIndicesForLinkADRReqPayloads(b.GetUplinkChannelIndices(), pls)
+			So(err, ShouldBeNil)
+			So(chans, ShouldResemble, b.GetEnabledUplinkChannelIndices())
+		})
+	})
+}
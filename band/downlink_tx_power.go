@@ -0,0 +1,84 @@
+package band
+
+// DutyCycleSubBand defines a regulatory sub-band with its max. TX power and
+// duty-cycle limitation.
+type DutyCycleSubBand struct {
+	// Name holds the name of the sub-band (e.g. as defined by ERC
+	// Recommendation 70-03 for EU868).
+	Name string
+
+	// MinFrequency (inclusive) and MaxFrequency (exclusive) define the
+	// frequency range of the sub-band (Hz).
+	MinFrequency uint32
+	MaxFrequency uint32
+
+	// TXPower defines the max. TX power (dBm).
+	TXPower int
+
+	// DutyCycle defines the max. duty-cycle (e.g. 0.01 for 1%).
+	DutyCycle float64
+}
+
+// Contains returns true when the given frequency is within the sub-band.
+func (s DutyCycleSubBand) Contains(frequency uint32) bool {
+	return frequency >= s.MinFrequency && frequency < s.MaxFrequency
+}
+
+// dutyCycleSubBands contains the regulatory sub-bands per band (by common
+// name).
+var dutyCycleSubBands = map[Name][]DutyCycleSubBand{
+	EU868: {
+		{Name: "h1.3", MinFrequency: 863000000, MaxFrequency: 865000000, TXPower: 14, DutyCycle: 0.001},
+		{Name: "h1.4", MinFrequency: 865000000, MaxFrequency: 868000000, TXPower: 14, DutyCycle: 0.01},
+		{Name: "h1.5", MinFrequency: 868000000, MaxFrequency: 868600000, TXPower: 14, DutyCycle: 0.01},
+		{Name: "h1.6", MinFrequency: 868700000, MaxFrequency: 869200000, TXPower: 14, DutyCycle: 0.001},
+		// includes the 869.525 MHz RX2 and Class-B frequency
+		{Name: "h1.7", MinFrequency: 869400000, MaxFrequency: 869650000, TXPower: 27, DutyCycle: 0.1},
+		{Name: "h1.8", MinFrequency: 869700000, MaxFrequency: 870000000, TXPower: 14, DutyCycle: 0.01},
+	},
+	EU433: {
+		{Name: "h1.2", MinFrequency: 433050000, MaxFrequency: 434790000, TXPower: 10, DutyCycle: 0.1},
+	},
+}
+
+// DownlinkTXPowerLimit defines the max. downlink TX power and the duty-cycle
+// limitation for a frequency.
+type DownlinkTXPowerLimit struct {
+	// TXPower defines the max. TX power (dBm).
+	TXPower int
+
+	// SubBand holds the regulatory sub-band of the frequency. It is nil when
+	// the band does not define duty-cycle sub-bands or when the frequency is
+	// not within one of these.
+	SubBand *DutyCycleSubBand
+}
+
+// GetDutyCycleSubBands returns the regulatory (duty-cycle) sub-bands of the
+// given band. It returns nil when the band does not define these.
+func GetDutyCycleSubBands(b Band) []DutyCycleSubBand {
+	name, err := ParseName(b.Name())
+	if err != nil {
+		return nil
+	}
+	return append([]DutyCycleSubBand(nil), dutyCycleSubBands[name]...)
+}
+
+// GetDownlinkTXPowerLimit returns the max. downlink TX power and the
+// regulatory sub-band for the given frequency, so that the TX power and the
+// duty-cycle accounting of a downlink are based on the same sub-band, e.g.
+// when using the 869.525 MHz RX2 frequency of EU868 at 27 dBm.
+func GetDownlinkTXPowerLimit(b Band, frequency uint32) DownlinkTXPowerLimit {
+	out := DownlinkTXPowerLimit{
+		TXPower: b.GetDownlinkTXPower(frequency),
+	}
+
+	for _, sb := range GetDutyCycleSubBands(b) {
+		if sb.Contains(frequency) {
+			sb := sb
+			out.SubBand = &sb
+			break
+		}
+	}
+
+	return out
+}
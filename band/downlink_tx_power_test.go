@@ -0,0 +1,81 @@
+package band
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestGetDownlinkTXPowerLimit(t *testing.T) {
+	eu868, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+	require.NoError(t, err)
+	us915, err := GetConfig(US915, false, lorawan.DwellTimeNoLimit)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name              string
+		Band              Band
+		Frequency         uint32
+		ExpectedTXPower   int
+		ExpectedSubBand   string
+		ExpectedDutyCycle float64
+	}{
+		{
+			Name:              "EU868 RX2 high power",
+			Band:              eu868,
+			Frequency:         869525000,
+			ExpectedTXPower:   27,
+			ExpectedSubBand:   "h1.7",
+			ExpectedDutyCycle: 0.1,
+		},
+		{
+			Name:              "EU868 default channel",
+			Band:              eu868,
+			Frequency:         868100000,
+			ExpectedTXPower:   14,
+			ExpectedSubBand:   "h1.5",
+			ExpectedDutyCycle: 0.01,
+		},
+		{
+			Name:              "EU868 0.1% sub-band",
+			Band:              eu868,
+			Frequency:         864100000,
+			ExpectedTXPower:   14,
+			ExpectedSubBand:   "h1.3",
+			ExpectedDutyCycle: 0.001,
+		},
+		{
+			Name:            "EU868 outside sub-bands",
+			Band:            eu868,
+			Frequency:       869300000,
+			ExpectedTXPower: 14,
+		},
+		{
+			Name:            "US915",
+			Band:            us915,
+			Frequency:       923300000,
+			ExpectedTXPower: 20,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			limit := GetDownlinkTXPowerLimit(tst.Band, tst.Frequency)
+			assert.Equal(tst.ExpectedTXPower, limit.TXPower)
+			assert.Equal(tst.Band.GetDownlinkTXPower(tst.Frequency), limit.TXPower)
+
+			if tst.ExpectedSubBand == "" {
+				assert.Nil(limit.SubBand)
+				return
+			}
+
+			assert.NotNil(limit.SubBand)
+			assert.Equal(tst.ExpectedSubBand, limit.SubBand.Name)
+			assert.Equal(tst.ExpectedDutyCycle, limit.SubBand.DutyCycle)
+		})
+	}
+}
@@ -4,5 +4,10 @@ import "errors"
 
 // errors
 var (
-	ErrChannelDoesNotExist = errors.New("lorawan/band: channel does not exist")
+	ErrChannelDoesNotExist         = errors.New("lorawan/band: channel does not exist")
+	ErrNewChannelReqNotSupported   = errors.New("lorawan/band: band does not support the NewChannelReq mac-command")
+	ErrDefaultChannelNotModifiable = errors.New("lorawan/band: default channel can not be modified")
+	ErrSubBandNotSupported         = errors.New("lorawan/band: band does not support sub-bands")
+	ErrFrequencyOutOfRange         = errors.New("lorawan/band: frequency is outside the band frequency range")
+	ErrCFListNotPermitted          = errors.New("lorawan/band: CFList is not permitted for this band and protocol version")
 )
@@ -0,0 +1,212 @@
+package band
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/brocaar/lorawan"
+)
+
+// maxFOptsLen defines the max. length of the FOpts field.
+const maxFOptsLen = 15
+
+// payloadLessMACCommands contains the (per direction) mac-commands that do
+// not have a payload.
+var payloadLessMACCommands = map[bool]map[lorawan.CID]struct{}{
+	true: {
+		lorawan.LinkCheckReq:     {},
+		lorawan.DutyCycleAns:     {},
+		lorawan.RXTimingSetupAns: {},
+		lorawan.TXParamSetupAns:  {},
+		lorawan.ADRParamSetupAns: {},
+		lorawan.DeviceTimeReq:    {},
+	},
+	false: {
+		lorawan.DevStatusReq:    {},
+		lorawan.PingSlotInfoAns: {},
+	},
+}
+
+// FrameProfile holds the device and transmission context used by
+// ValidateFrame. Optional fields are skipped when not set.
+type FrameProfile struct {
+	// ProtocolVersion and RegParamsRevision are used to lookup the max.
+	// payload size.
+	ProtocolVersion   string
+	RegParamsRevision string
+
+	// DataRate holds the (optional) data-rate index of the transmission.
+	DataRate *int
+
+	// Frequency holds the (optional) frequency (Hz) of the transmission.
+	Frequency uint32
+}
+
+// FrameFinding describes a single issue found by ValidateFrame.
+type FrameFinding struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (f FrameFinding) Error() string {
+	return fmt.Sprintf("lorawan/band: %s: %s", f.Field, f.Message)
+}
+
+// ValidateFrame performs a structural validation of the given PHYPayload
+// against the band and profile. It validates the FOpts length, the FPort
+// rules, the mac-command direction, the payload size for the data-rate,
+// the data-rate and frequency of the transmission and the DevEUI and JoinEUI
+// of (re)join-requests. All findings are returned,
+// an empty slice means that no issues were found. The mac-commands are only
+// validated when they have been decoded (e.g. using DecodeFOptsToMACCommands).
+func ValidateFrame(b Band, phy lorawan.PHYPayload, profile FrameProfile) []FrameFinding {
+	var findings []FrameFinding
+	add := func(field, format string, a ...interface{}) {
+		findings = append(findings, FrameFinding{Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if phy.MACPayload == nil {
+		add("macPayload", "must not be nil")
+		return findings
+	}
+
+	uplink := isUplinkMType(phy.MHDR.MType)
+
+	if profile.DataRate != nil {
+		dr, err := b.GetDataRate(*profile.DataRate)
+		if err != nil {
+			add("dataRate", "data-rate %d does not exist", *profile.DataRate)
+		} else if uplink && !dr.uplink {
+			add("dataRate", "data-rate %d can not be used for uplink", *profile.DataRate)
+		} else if !uplink && !dr.downlink {
+			add("dataRate", "data-rate %d can not be used for downlink", *profile.DataRate)
+		}
+	}
+
+	if profile.Frequency != 0 {
+		if name, err := ParseName(b.Name()); err == nil {
+			r := frequencyRanges[name]
+			if profile.Frequency < r.min || profile.Frequency > r.max {
+				add("frequency", "frequency %d is outside the band frequency range", profile.Frequency)
+			}
+		}
+	}
+
+	if profile.DataRate != nil && profile.ProtocolVersion != "" {
+		size, err := b.GetMaxPayloadSizeForDataRateIndex(profile.ProtocolVersion, profile.RegParamsRevision, *profile.DataRate)
+		if err == nil {
+			if pb, err := phy.MACPayload.MarshalBinary(); err == nil && len(pb) > size.M {
+				add("macPayload", "size of %d bytes exceeds the max. size of %d bytes", len(pb), size.M)
+			}
+		}
+	}
+
+	validateEUI := func(field string, err error) {
+		if err != nil {
+			add(field, "%s", strings.TrimPrefix(err.Error(), "lorawan: "))
+		}
+	}
+
+	switch v := phy.MACPayload.(type) {
+	case *lorawan.JoinRequestPayload:
+		validateEUI("joinEUI", lorawan.ValidateJoinEUI(v.JoinEUI))
+		validateEUI("devEUI", lorawan.ValidateDevEUI(v.DevEUI))
+	case *lorawan.RejoinRequestType02Payload:
+		validateEUI("devEUI", lorawan.ValidateDevEUI(v.DevEUI))
+	case *lorawan.RejoinRequestType1Payload:
+		validateEUI("joinEUI", lorawan.ValidateJoinEUI(v.JoinEUI))
+		validateEUI("devEUI", lorawan.ValidateDevEUI(v.DevEUI))
+	}
+
+	macPL, ok := phy.MACPayload.(*lorawan.MACPayload)
+	if !ok {
+		return findings
+	}
+
+	var fOptsLen int
+	for i, pl := range macPL.FHDR.FOpts {
+		if pb, err := pl.MarshalBinary(); err == nil {
+			fOptsLen += len(pb)
+		}
+		if mac, ok := pl.(*lorawan.MACCommand); ok {
+			if msg := validateMACCommandDirection(uplink, mac); msg != "" {
+				add(fmt.Sprintf("fhdr.fOpts[%d]", i), msg)
+			}
+		}
+	}
+	if fOptsLen > maxFOptsLen {
+		add("fhdr.fOpts", "size of %d bytes exceeds the max. size of %d bytes", fOptsLen, maxFOptsLen)
+	}
+
+	if macPL.FPort == nil {
+		if len(macPL.FRMPayload) != 0 {
+			add("fPort", "must be set when FRMPayload is set")
+		}
+		return findings
+	}
+
+	fPort := *macPL.FPort
+	if fPort == 0 && len(macPL.FHDR.FOpts) != 0 {
+		add("fPort", "must not be 0 when FOpts are set")
+	}
+	if fPort > 224 {
+		add("fPort", "FPort %d is reserved for future use", fPort)
+	}
+
+	for i, pl := range macPL.FRMPayload {
+		mac, ok := pl.(*lorawan.MACCommand)
+		if !ok {
+			continue
+		}
+		if fPort != 0 {
+			add(fmt.Sprintf("frmPayload[%d]", i), "mac-commands are only allowed when FPort is 0")
+			continue
+		}
+		if msg := validateMACCommandDirection(uplink, mac); msg != "" {
+			add(fmt.Sprintf("frmPayload[%d]", i), msg)
+		}
+	}
+
+	return findings
+}
+
+// validateMACCommandDirection validates that the mac-command (and payload)
+// is valid for the given direction. It returns an empty string when valid.
+func validateMACCommandDirection(uplink bool, mac *lorawan.MACCommand) string {
+	// proprietary mac-commands
+	if mac.CID >= 0x80 {
+		return ""
+	}
+
+	expected, _, err := lorawan.GetMACPayloadAndSize(uplink, mac.CID)
+	if err != nil {
+		if _, ok := payloadLessMACCommands[uplink][mac.CID]; !ok {
+			return fmt.Sprintf("CID 0x%02x is not valid for uplink=%t", byte(mac.CID), uplink)
+		}
+		if mac.Payload != nil {
+			return fmt.Sprintf("CID 0x%02x must not have a payload", byte(mac.CID))
+		}
+		return ""
+	}
+
+	if mac.Payload == nil {
+		return fmt.Sprintf("CID 0x%02x must have a payload", byte(mac.CID))
+	}
+	if reflect.TypeOf(mac.Payload) != reflect.TypeOf(expected) {
+		return fmt.Sprintf("CID 0x%02x expects payload of type %T for uplink=%t, got %T", byte(mac.CID), expected, uplink, mac.Payload)
+	}
+
+	return ""
+}
+
+// isUplinkMType returns true when the given MType is sent by the end-device.
+func isUplinkMType(mType lorawan.MType) bool {
+	switch mType {
+	case lorawan.JoinRequest, lorawan.UnconfirmedDataUp, lorawan.ConfirmedDataUp, lorawan.RejoinRequest:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,179 @@
+package band
+
+import (
+	"testing"
+
+	"github.com/brocaar/lorawan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFrame(t *testing.T) {
+	b, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+	require.NoError(t, err)
+
+	intPtr := func(i int) *int { return &i }
+	fPortPtr := func(i uint8) *uint8 { return &i }
+
+	dataUp := func(fOpts []lorawan.Payload, fPort *uint8, frmPayload []lorawan.Payload) lorawan.PHYPayload {
+		return lorawan.PHYPayload{
+			MHDR: lorawan.MHDR{MType: lorawan.UnconfirmedDataUp, Major: lorawan.LoRaWANR1},
+			MACPayload: &lorawan.MACPayload{
+				FHDR: lorawan.FHDR{
+					DevAddr: lorawan.DevAddr{1, 2, 3, 4},
+					FOpts:   fOpts,
+				},
+				FPort:      fPort,
+				FRMPayload: frmPayload,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		phy      lorawan.PHYPayload
+		profile  FrameProfile
+		findings []FrameFinding
+	}{
+		{
+			name: "valid uplink",
+			phy: dataUp(
+				[]lorawan.Payload{&lorawan.MACCommand{CID: lorawan.LinkCheckReq}},
+				fPortPtr(10),
+				[]lorawan.Payload{&lorawan.DataPayload{Bytes: []byte{1, 2, 3}}},
+			),
+			profile: FrameProfile{
+				ProtocolVersion: LoRaWAN_1_0_3,
+				DataRate:        intPtr(5),
+				Frequency:       868100000,
+			},
+		},
+		{
+			name: "nil MACPayload",
+			phy:  lorawan.PHYPayload{},
+			findings: []FrameFinding{
+				{Field: "macPayload", Message: "must not be nil"},
+			},
+		},
+		{
+			name: "invalid data-rate and frequency",
+			phy:  dataUp(nil, nil, nil),
+			profile: FrameProfile{
+				DataRate:  intPtr(15),
+				Frequency: 915000000,
+			},
+			findings: []FrameFinding{
+				{Field: "dataRate", Message: "data-rate 15 does not exist"},
+				{Field: "frequency", Message: "frequency 915000000 is outside the band frequency range"},
+			},
+		},
+		{
+			name: "payload exceeds max size",
+			phy:  dataUp(nil, fPortPtr(1), []lorawan.Payload{&lorawan.DataPayload{Bytes: make([]byte, 60)}}),
+			profile: FrameProfile{
+				ProtocolVersion: LoRaWAN_1_0_3,
+				DataRate:        intPtr(0),
+			},
+			findings: []FrameFinding{
+				{Field: "macPayload", Message: "size of 68 bytes exceeds the max. size of 59 bytes"},
+			},
+		},
+		{
+			name: "downlink mac-command in uplink",
+			phy: dataUp(
+				[]lorawan.Payload{
+					&lorawan.MACCommand{CID: lorawan.DevStatusReq},
+					&lorawan.MACCommand{CID: lorawan.LinkADRReq, Payload: &lorawan.LinkADRReqPayload{}},
+				},
+				nil,
+				nil,
+			),
+			findings: []FrameFinding{
+				{Field: "fhdr.fOpts[0]", Message: "CID 0x06 must have a payload"},
+				{Field: "fhdr.fOpts[1]", Message: "CID 0x03 expects payload of type *lorawan.LinkADRAnsPayload for uplink=true, got *lorawan.LinkADRReqPayload"},
+			},
+		},
+		{
+			name: "FOpts too long",
+			phy: dataUp(
+				[]lorawan.Payload{&lorawan.DataPayload{Bytes: make([]byte, 16)}},
+				nil,
+				nil,
+			),
+			findings: []FrameFinding{
+				{Field: "fhdr.fOpts", Message: "size of 16 bytes exceeds the max. size of 15 bytes"},
+			},
+		},
+		{
+			name: "FRMPayload without FPort",
+			phy:  dataUp(nil, nil, []lorawan.Payload{&lorawan.DataPayload{Bytes: []byte{1}}}),
+			findings: []FrameFinding{
+				{Field: "fPort", Message: "must be set when FRMPayload is set"},
+			},
+		},
+		{
+			name: "FPort rules",
+			phy: dataUp(
+				[]lorawan.Payload{&lorawan.MACCommand{CID: lorawan.LinkCheckReq}},
+				fPortPtr(0),
+				[]lorawan.Payload{&lorawan.MACCommand{CID: 0x30}},
+			),
+			findings: []FrameFinding{
+				{Field: "fPort", Message: "must not be 0 when FOpts are set"},
+				{Field: "frmPayload[0]", Message: "CID 0x30 is not valid for uplink=true"},
+			},
+		},
+		{
+			name: "RFU FPort",
+			phy:  dataUp(nil, fPortPtr(225), nil),
+			findings: []FrameFinding{
+				{Field: "fPort", Message: "FPort 225 is reserved for future use"},
+			},
+		},
+		{
+			name: "valid join-request",
+			phy: lorawan.PHYPayload{
+				MHDR: lorawan.MHDR{MType: lorawan.JoinRequest, Major: lorawan.LoRaWANR1},
+				MACPayload: &lorawan.JoinRequestPayload{
+					DevEUI: lorawan.EUI64{0x70, 0xb3, 0xd5, 0x7e, 0xd0, 0x00, 0x00, 0x01},
+				},
+			},
+		},
+		{
+			name: "join-request with reserved EUIs",
+			phy: lorawan.PHYPayload{
+				MHDR: lorawan.MHDR{MType: lorawan.JoinRequest, Major: lorawan.LoRaWANR1},
+				MACPayload: &lorawan.JoinRequestPayload{
+					JoinEUI: lorawan.EUI64{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+				},
+			},
+			findings: []FrameFinding{
+				{Field: "joinEUI", Message: "EUI must not have the multicast (group) bit set"},
+				{Field: "devEUI", Message: "EUI must not be 0"},
+			},
+		},
+		{
+			name: "rejoin-request with broadcast DevEUI",
+			phy: lorawan.PHYPayload{
+				MHDR: lorawan.MHDR{MType: lorawan.RejoinRequest, Major: lorawan.LoRaWANR1},
+				MACPayload: &lorawan.RejoinRequestType02Payload{
+					DevEUI: lorawan.EUI64{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+				},
+			},
+			findings: []FrameFinding{
+				{Field: "devEUI", Message: "EUI must not be the broadcast address (FFFFFFFFFFFFFFFF)"},
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.findings, ValidateFrame(b, tst.phy, tst.profile))
+		})
+	}
+
+	t.Run("FrameFinding implements error", func(t *testing.T) {
+		var err error = FrameFinding{Field: "fPort", Message: "must be set"}
+		require.EqualError(t, err, "lorawan/band: fPort: must be set")
+	})
+}
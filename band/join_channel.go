@@ -0,0 +1,130 @@
+package band
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// JoinChannel defines the uplink channel and data-rate of a join-request.
+type JoinChannel struct {
+	Channel   int
+	Frequency uint32
+	DR        int
+}
+
+// JoinChannelSelector selects the uplink channel and data-rate of the
+// join-requests of an end-device, e.g. for simulating realistic join traffic.
+// Only the default (standard) uplink channels of the band are used:
+//
+//   - For bands with a dynamic channel-plan (e.g. EU868), a random default
+//     channel and a random data-rate of that channel are selected.
+//   - For bands with a fixed channel-plan (e.g. US915 and AU915), the
+//     join-requests alternate between a random 125 kHz channel and a random
+//     500 kHz channel (if any), using the min. data-rate of the channel. The
+//     sub-bands of the 125 kHz channels are used in random order, so that
+//     every sub-band is used once before a sub-band is re-used.
+//
+// A JoinChannelSelector is not safe for concurrent use.
+type JoinChannelSelector struct {
+	band Band
+	rnd  *rand.Rand
+
+	fixed     bool
+	channels  []int   // dynamic channel-plan
+	subBands  [][]int // fixed channel-plan, 125 kHz channels per sub-band
+	channels2 []int   // fixed channel-plan, 500 kHz channels
+
+	subBandOrder []int
+	count        int
+}
+
+// NewJoinChannelSelector returns a JoinChannelSelector for the given band.
+// When rnd is nil, a randomly seeded source is used. Use a seeded rnd to make
+// the selection deterministic.
+func NewJoinChannelSelector(b Band, rnd *rand.Rand) (*JoinChannelSelector, error) {
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	s := JoinChannelSelector{
+		band:     b,
+		rnd:      rnd,
+		channels: b.GetStandardUplinkChannelIndices(),
+	}
+
+	if len(s.channels) == 0 {
+		return nil, errors.New("lorawan/band: band does not have default uplink channels")
+	}
+
+	if len(s.channels) > maxDynamicChannels {
+		s.fixed = true
+
+		var channels125 []int
+		for _, i := range s.channels {
+			c, err := b.GetUplinkChannel(i)
+			if err != nil {
+				return nil, err
+			}
+			dr, err := b.GetDataRate(c.MinDR)
+			if err != nil {
+				return nil, err
+			}
+
+			if dr.Bandwidth == 500 {
+				s.channels2 = append(s.channels2, i)
+			} else {
+				channels125 = append(channels125, i)
+			}
+		}
+
+		for i := 0; i < len(channels125); i += 8 {
+			end := i + 8
+			if end > len(channels125) {
+				end = len(channels125)
+			}
+			s.subBands = append(s.subBands, channels125[i:end])
+		}
+	}
+
+	return &s, nil
+}
+
+// Next returns the channel and data-rate for the next join-request.
+func (s *JoinChannelSelector) Next() (JoinChannel, error) {
+	if !s.fixed {
+		return s.joinChannel(s.channels[s.rnd.Intn(len(s.channels))], true)
+	}
+
+	defer func() { s.count++ }()
+
+	if s.count%2 == 1 && len(s.channels2) != 0 {
+		return s.joinChannel(s.channels2[s.rnd.Intn(len(s.channels2))], false)
+	}
+
+	if len(s.subBandOrder) == 0 {
+		s.subBandOrder = s.rnd.Perm(len(s.subBands))
+	}
+	subBand := s.subBands[s.subBandOrder[0]]
+	s.subBandOrder = s.subBandOrder[1:]
+
+	return s.joinChannel(subBand[s.rnd.Intn(len(subBand))], false)
+}
+
+func (s *JoinChannelSelector) joinChannel(i int, randomDR bool) (JoinChannel, error) {
+	c, err := s.band.GetUplinkChannel(i)
+	if err != nil {
+		return JoinChannel{}, err
+	}
+
+	dr := c.MinDR
+	if randomDR && c.MaxDR > c.MinDR {
+		dr += s.rnd.Intn(c.MaxDR - c.MinDR + 1)
+	}
+
+	return JoinChannel{
+		Channel:   i,
+		Frequency: c.Frequency,
+		DR:        dr,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package band
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestJoinChannelSelector(t *testing.T) {
+	t.Run("EU868", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := GetConfig(EU868, false, lorawan.DwellTimeNoLimit)
+		assert.NoError(err)
+		assert.NoError(b.AddChannel(867100000, 0, 5))
+
+		s, err := NewJoinChannelSelector(b, rand.New(rand.NewSource(1)))
+		assert.NoError(err)
+
+		channels := make(map[int]struct{})
+		drs := make(map[int]struct{})
+		for i := 0; i < 100; i++ {
+			jc, err := s.Next()
+			assert.NoError(err)
+			channels[jc.Channel] = struct{}{}
+			drs[jc.DR] = struct{}{}
+
+			c, err := b.GetUplinkChannel(jc.Channel)
+			assert.NoError(err)
+			assert.Equal(c.Frequency, jc.Frequency)
+		}
+
+		// only the three default channels are used
+		assert.Equal(map[int]struct{}{0: {}, 1: {}, 2: {}}, channels)
+		assert.Len(drs, 6)
+	})
+
+	t.Run("US915", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := GetConfig(US915, false, lorawan.DwellTimeNoLimit)
+		assert.NoError(err)
+
+		s, err := NewJoinChannelSelector(b, rand.New(rand.NewSource(1)))
+		assert.NoError(err)
+
+		subBands := make(map[int]struct{})
+		for i := 0; i < 16; i++ {
+			jc, err := s.Next()
+			assert.NoError(err)
+
+			if i%2 == 0 {
+				assert.Less(jc.Channel, 64)
+				assert.Equal(0, jc.DR)
+				subBands[jc.Channel/8] = struct{}{}
+			} else {
+				assert.GreaterOrEqual(jc.Channel, 64)
+				assert.Equal(4, jc.DR)
+			}
+		}
+
+		// every sub-band is used once
+		assert.Len(subBands, 8)
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := GetConfig(AU915, false, lorawan.DwellTimeNoLimit)
+		assert.NoError(err)
+
+		s1, err := NewJoinChannelSelector(b, rand.New(rand.NewSource(42)))
+		assert.NoError(err)
+		s2, err := NewJoinChannelSelector(b, rand.New(rand.NewSource(42)))
+		assert.NoError(err)
+
+		for i := 0; i < 20; i++ {
+			jc1, err := s1.Next()
+			assert.NoError(err)
+			jc2, err := s2.Next()
+			assert.NoError(err)
+			assert.Equal(jc1, jc2)
+		}
+	})
+}
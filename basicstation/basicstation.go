@@ -0,0 +1,136 @@
+// Package basicstation implements the structures of the LoRa Basics Station
+// protocols (as documented at https://doc.sm.tc/station/).
+package basicstation
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brocaar/lorawan"
+)
+
+// EUI64 implements an EUI64 which is encoded as ID6 string (e.g.
+// "b827:ebff:fe61:3f7d"). When decoding, it also accepts the EUI64 as plain
+// HEX string, dash separated HEX string or as integer.
+type EUI64 lorawan.EUI64
+
+// String implements fmt.Stringer, returning the ID6 representation.
+func (e EUI64) String() string {
+	groups := make([]uint16, 4)
+	for i := range groups {
+		groups[i] = binary.BigEndian.Uint16(e[i*2:])
+	}
+
+	// find the longest run of zero groups to abbreviate
+	var start, length int
+	for i := 0; i < len(groups); i++ {
+		var l int
+		for i+l < len(groups) && groups[i+l] == 0 {
+			l++
+		}
+		if l > length {
+			start, length = i, l
+		}
+	}
+
+	var parts []string
+	for i := 0; i < len(groups); i++ {
+		if length > 0 && i == start {
+			parts = append(parts, "")
+			if i == 0 {
+				parts = append(parts, "")
+			}
+			i += length - 1
+			if i == len(groups)-1 {
+				parts = append(parts, "")
+			}
+			continue
+		}
+		parts = append(parts, strconv.FormatUint(uint64(groups[i]), 16))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (e EUI64) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (e *EUI64) UnmarshalText(text []byte) error {
+	s := string(text)
+
+	if strings.Contains(s, ":") {
+		return e.unmarshalID6(s)
+	}
+
+	var eui lorawan.EUI64
+	if err := eui.UnmarshalText([]byte(strings.ReplaceAll(s, "-", ""))); err != nil {
+		return fmt.Errorf("lorawan/basicstation: invalid eui64: %s", s)
+	}
+	*e = EUI64(eui)
+
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *EUI64) UnmarshalJSON(data []byte) error {
+	if i, err := strconv.ParseUint(string(data), 10, 64); err == nil {
+		binary.BigEndian.PutUint64(e[:], i)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("lorawan/basicstation: invalid eui64: %s", data)
+	}
+
+	return e.UnmarshalText([]byte(s))
+}
+
+func (e *EUI64) unmarshalID6(s string) error {
+	var head, tail []string
+
+	parts := strings.Split(s, "::")
+	switch len(parts) {
+	case 1:
+		head = strings.Split(parts[0], ":")
+	case 2:
+		if parts[0] != "" {
+			head = strings.Split(parts[0], ":")
+		}
+		if parts[1] != "" {
+			tail = strings.Split(parts[1], ":")
+		}
+	default:
+		return fmt.Errorf("lorawan/basicstation: invalid id6: %s", s)
+	}
+
+	if len(head)+len(tail) > 4 || (len(parts) == 1 && len(head) != 4) {
+		return fmt.Errorf("lorawan/basicstation: invalid id6: %s", s)
+	}
+
+	groups := make([]string, 4)
+	copy(groups, head)
+	copy(groups[4-len(tail):], tail)
+
+	var out EUI64
+	for i, g := range groups {
+		if g == "" {
+			continue
+		}
+
+		v, err := strconv.ParseUint(g, 16, 16)
+		if err != nil {
+			return fmt.Errorf("lorawan/basicstation: invalid id6: %s", s)
+		}
+		binary.BigEndian.PutUint16(out[i*2:], uint16(v))
+	}
+	*e = out
+
+	return nil
+}
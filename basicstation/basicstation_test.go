@@ -0,0 +1,54 @@
+package basicstation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEUI64(t *testing.T) {
+	tests := []struct {
+		EUI64 EUI64
+		ID6   string
+	}{
+		{EUI64{0xb8, 0x27, 0xeb, 0xff, 0xfe, 0x61, 0x3f, 0x7d}, "b827:ebff:fe61:3f7d"},
+		{EUI64{}, "::"},
+		{EUI64{0, 0, 0, 0, 0, 0, 0, 1}, "::1"},
+		{EUI64{0, 0x01, 0, 0, 0, 0, 0, 0}, "1::"},
+		{EUI64{0, 0x01, 0, 0, 0, 0, 0, 0x02}, "1::2"},
+		{EUI64{0, 0x01, 0, 0x02, 0, 0, 0, 0}, "1:2::"},
+		{EUI64{0, 0x01, 0, 0, 0, 0x02, 0, 0x03}, "1::2:3"},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.ID6, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.ID6, tst.EUI64.String())
+
+			var eui EUI64
+			assert.NoError(eui.UnmarshalText([]byte(tst.ID6)))
+			assert.Equal(tst.EUI64, eui)
+		})
+	}
+
+	t.Run("Unmarshal other formats", func(t *testing.T) {
+		assert := require.New(t)
+		expected := EUI64{0xb8, 0x27, 0xeb, 0xff, 0xfe, 0x61, 0x3f, 0x7d}
+
+		for _, s := range []string{`"b827ebfffe613f7d"`, `"b8-27-eb-ff-fe-61-3f-7d"`, `13269834311787429757`} {
+			var eui EUI64
+			assert.NoError(json.Unmarshal([]byte(s), &eui))
+			assert.Equal(expected, eui)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert := require.New(t)
+
+		for _, s := range []string{"1:2:3", "1::2::3", "1:2:3:4:5", "xyz::"} {
+			var eui EUI64
+			assert.Error(eui.UnmarshalText([]byte(s)), s)
+		}
+	})
+}
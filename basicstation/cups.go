@@ -0,0 +1,343 @@
+package basicstation
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// Errors.
+var (
+	ErrURITooLong              = errors.New("lorawan/basicstation: uri must not exceed 255 bytes")
+	ErrCredentialsTooLong      = errors.New("lorawan/basicstation: credentials must not exceed 65535 bytes")
+	ErrUpdateInfoTruncated     = errors.New("lorawan/basicstation: update-info response is truncated")
+	ErrCredentialsTruncated    = errors.New("lorawan/basicstation: credentials are truncated")
+	ErrInvalidSignature        = errors.New("lorawan/basicstation: invalid signature")
+	ErrSignatureKeyCRCMismatch = errors.New("lorawan/basicstation: signature key crc does not match")
+)
+
+// UpdateInfoRequest implements the CUPS update-info request, which is
+// sent by the station (using HTTP POST to the /update-info path).
+type UpdateInfoRequest struct {
+	Router      EUI64    `json:"router"`
+	CUPSURI     string   `json:"cupsUri"`
+	TCURI       string   `json:"tcUri"`
+	CUPSCredCRC uint32   `json:"cupsCredCrc"`
+	TCCredCRC   uint32   `json:"tcCredCrc"`
+	Station     string   `json:"station"`
+	Model       string   `json:"model"`
+	Package     string   `json:"package"`
+	Keys        []uint32 `json:"keys"` // CRC32 of the public signing keys known by the station
+}
+
+// UpdateInfoResponse implements the CUPS update-info response. Empty fields
+// indicate that the station must not update the corresponding item.
+type UpdateInfoResponse struct {
+	CUPSURI         string
+	TCURI           string
+	CUPSCredentials []byte
+	TCCredentials   []byte
+	Signature       *Signature
+	UpdateData      []byte
+}
+
+// MarshalBinary encodes the response into its binary (little-endian)
+// representation.
+func (r UpdateInfoResponse) MarshalBinary() ([]byte, error) {
+	if len(r.CUPSURI) > 255 || len(r.TCURI) > 255 {
+		return nil, ErrURITooLong
+	}
+	if len(r.CUPSCredentials) > 65535 || len(r.TCCredentials) > 65535 {
+		return nil, ErrCredentialsTooLong
+	}
+
+	var sig []byte
+	if r.Signature != nil {
+		sig = r.Signature.marshal()
+	}
+
+	out := make([]byte, 0, 14+len(r.CUPSURI)+len(r.TCURI)+len(r.CUPSCredentials)+len(r.TCCredentials)+len(sig)+len(r.UpdateData))
+
+	out = append(out, uint8(len(r.CUPSURI)))
+	out = append(out, r.CUPSURI...)
+	out = append(out, uint8(len(r.TCURI)))
+	out = append(out, r.TCURI...)
+	out = append(out, make([]byte, 2)...)
+	binary.LittleEndian.PutUint16(out[len(out)-2:], uint16(len(r.CUPSCredentials)))
+	out = append(out, r.CUPSCredentials...)
+	out = append(out, make([]byte, 2)...)
+	binary.LittleEndian.PutUint16(out[len(out)-2:], uint16(len(r.TCCredentials)))
+	out = append(out, r.TCCredentials...)
+	out = append(out, make([]byte, 4)...)
+	binary.LittleEndian.PutUint32(out[len(out)-4:], uint32(len(sig)))
+	out = append(out, sig...)
+	out = append(out, make([]byte, 4)...)
+	binary.LittleEndian.PutUint32(out[len(out)-4:], uint32(len(r.UpdateData)))
+	out = append(out, r.UpdateData...)
+
+	return out, nil
+}
+
+// UnmarshalBinary decodes the response from its binary representation.
+func (r *UpdateInfoResponse) UnmarshalBinary(data []byte) error {
+	d := decoder{b: data}
+
+	*r = UpdateInfoResponse{
+		CUPSURI:         string(d.next(int(d.uint8()))),
+		TCURI:           string(d.next(int(d.uint8()))),
+		CUPSCredentials: d.next(int(d.uint16())),
+		TCCredentials:   d.next(int(d.uint16())),
+	}
+	sig := d.next(int(d.uint32()))
+	r.UpdateData = d.next(int(d.uint32()))
+
+	if d.err != nil {
+		return ErrUpdateInfoTruncated
+	}
+
+	if len(sig) != 0 {
+		r.Signature = &Signature{}
+		if err := r.Signature.unmarshal(sig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Signature contains the signature of the update data.
+type Signature struct {
+	// KeyCRC contains the CRC32 of the public key (see PublicKeyCRC) that
+	// must be used to verify the signature.
+	KeyCRC uint32
+
+	// Signature contains the ASN.1 DER encoded ECDSA signature of the
+	// SHA-512 hash of the update data.
+	Signature []byte
+}
+
+func (s Signature) marshal() []byte {
+	out := make([]byte, 4, 4+len(s.Signature))
+	binary.LittleEndian.PutUint32(out, s.KeyCRC)
+	return append(out, s.Signature...)
+}
+
+func (s *Signature) unmarshal(data []byte) error {
+	if len(data) < 4 {
+		return ErrUpdateInfoTruncated
+	}
+
+	s.KeyCRC = binary.LittleEndian.Uint32(data)
+	s.Signature = append([]byte{}, data[4:]...)
+
+	return nil
+}
+
+// PublicKeyCRC returns the CRC32 of the given public key, which is used by
+// the station to lookup the key for verifying the update signature. The
+// CRC32 is calculated over the raw X and Y coordinates.
+func PublicKeyCRC(pub *ecdsa.PublicKey) uint32 {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	b := make([]byte, 2*size)
+	pub.X.FillBytes(b[:size])
+	pub.Y.FillBytes(b[size:])
+
+	return crc32.ChecksumIEEE(b)
+}
+
+// SignUpdateData returns the Signature for the given update data (e.g. the
+// firmware).
+func SignUpdateData(priv *ecdsa.PrivateKey, data []byte) (Signature, error) {
+	hash := sha512.Sum512(data)
+
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, hash[:])
+	if err != nil {
+		return Signature{}, fmt.Errorf("lorawan/basicstation: sign update data error: %w", err)
+	}
+
+	return Signature{
+		KeyCRC:    PublicKeyCRC(&priv.PublicKey),
+		Signature: sig,
+	}, nil
+}
+
+// VerifyUpdateData verifies the Signature of the given update data.
+func VerifyUpdateData(pub *ecdsa.PublicKey, sig Signature, data []byte) error {
+	if sig.KeyCRC != PublicKeyCRC(pub) {
+		return ErrSignatureKeyCRCMismatch
+	}
+
+	hash := sha512.Sum512(data)
+	if !ecdsa.VerifyASN1(pub, hash[:], sig.Signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Credentials implements the credentials blob as used in the
+// UpdateInfoResponse. It contains the DER encoded trust (CA certificate),
+// followed by either the DER encoded client certificate and private key or,
+// in case of token authentication, four zero bytes followed by the
+// HTTP header(s) containing the token (e.g. "Authorization: Bearer ...\r\n").
+type Credentials struct {
+	Trust []byte
+	Cert  []byte
+	Key   []byte
+	Token string
+}
+
+// MarshalBinary encodes the credentials into the credentials blob.
+func (c Credentials) MarshalBinary() ([]byte, error) {
+	out := append([]byte{}, c.Trust...)
+
+	if c.Token != "" {
+		out = append(out, 0, 0, 0, 0)
+		out = append(out, c.Token...)
+	} else {
+		out = append(out, c.Cert...)
+		out = append(out, c.Key...)
+	}
+
+	if len(out) > 65535 {
+		return nil, ErrCredentialsTooLong
+	}
+
+	return out, nil
+}
+
+// UnmarshalBinary decodes the credentials from the credentials blob.
+func (c *Credentials) UnmarshalBinary(data []byte) error {
+	var parts [][]byte
+	var token bool
+
+	for len(data) != 0 && len(parts) < 3 {
+		if len(parts) == 1 && len(data) >= 4 && binary.LittleEndian.Uint32(data) == 0 {
+			token = true
+			data = data[4:]
+			break
+		}
+
+		size, err := derSize(data)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, data[:size])
+		data = data[size:]
+	}
+
+	*c = Credentials{}
+	if len(parts) > 0 {
+		c.Trust = parts[0]
+	}
+
+	if token {
+		c.Token = string(data)
+		return nil
+	}
+
+	if len(data) != 0 {
+		return ErrCredentialsTruncated
+	}
+
+	if len(parts) > 1 {
+		c.Cert = parts[1]
+	}
+	if len(parts) > 2 {
+		c.Key = parts[2]
+	}
+
+	return nil
+}
+
+// CRC returns the CRC32 of the credentials blob, which is reported by the
+// station as CUPSCredCRC or TCCredCRC in the UpdateInfoRequest.
+func (c Credentials) CRC() (uint32, error) {
+	b, err := c.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	return crc32.ChecksumIEEE(b), nil
+}
+
+// derSize returns the size of the (first) DER encoded element, including
+// its tag and length bytes.
+func derSize(b []byte) (int, error) {
+	if len(b) < 2 {
+		return 0, ErrCredentialsTruncated
+	}
+
+	size := int(b[1])
+	header := 2
+
+	if size&0x80 != 0 {
+		n := size & 0x7f
+		if n == 0 || n > 4 || len(b) < 2+n {
+			return 0, ErrCredentialsTruncated
+		}
+
+		size = 0
+		for _, v := range b[2 : 2+n] {
+			size = size<<8 | int(v)
+		}
+		header += n
+	}
+
+	if len(b) < header+size {
+		return 0, ErrCredentialsTruncated
+	}
+
+	return header + size, nil
+}
+
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.b) < n {
+		d.err = ErrUpdateInfoTruncated
+		return nil
+	}
+
+	out := d.b[:n]
+	d.b = d.b[n:]
+
+	if n == 0 {
+		return nil
+	}
+	return append([]byte{}, out...)
+}
+
+func (d *decoder) uint8() uint8 {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) uint16() uint16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
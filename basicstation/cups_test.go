@@ -0,0 +1,162 @@
+package basicstation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateInfoRequest(t *testing.T) {
+	assert := require.New(t)
+
+	var req UpdateInfoRequest
+	assert.NoError(json.Unmarshal([]byte(`{
+		"router": "b827:ebff:fe61:3f7d",
+		"cupsUri": "https://cups.example.com:443",
+		"tcUri": "wss://lns.example.com:443",
+		"cupsCredCrc": 1234,
+		"tcCredCrc": 5678,
+		"station": "2.0.6(rpi/std)",
+		"model": "rpi",
+		"package": "1.0.0",
+		"keys": [1, 2]
+	}`), &req))
+
+	assert.Equal(UpdateInfoRequest{
+		Router:      EUI64{0xb8, 0x27, 0xeb, 0xff, 0xfe, 0x61, 0x3f, 0x7d},
+		CUPSURI:     "https://cups.example.com:443",
+		TCURI:       "wss://lns.example.com:443",
+		CUPSCredCRC: 1234,
+		TCCredCRC:   5678,
+		Station:     "2.0.6(rpi/std)",
+		Model:       "rpi",
+		Package:     "1.0.0",
+		Keys:        []uint32{1, 2},
+	}, req)
+}
+
+func TestUpdateInfoResponse(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := UpdateInfoResponse{}.MarshalBinary()
+		assert.NoError(err)
+		assert.Equal([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, b)
+
+		var resp UpdateInfoResponse
+		assert.NoError(resp.UnmarshalBinary(b))
+		assert.Equal(UpdateInfoResponse{}, resp)
+	})
+
+	t.Run("All fields", func(t *testing.T) {
+		assert := require.New(t)
+
+		resp := UpdateInfoResponse{
+			CUPSURI:         "a",
+			TCURI:           "bc",
+			CUPSCredentials: []byte{1},
+			TCCredentials:   []byte{2, 3},
+			Signature:       &Signature{KeyCRC: 0x01020304, Signature: []byte{5}},
+			UpdateData:      []byte{6, 7},
+		}
+
+		b, err := resp.MarshalBinary()
+		assert.NoError(err)
+		assert.Equal([]byte{
+			1, 'a',
+			2, 'b', 'c',
+			1, 0, 1,
+			2, 0, 2, 3,
+			5, 0, 0, 0, 4, 3, 2, 1, 5,
+			2, 0, 0, 0, 6, 7,
+		}, b)
+
+		var decoded UpdateInfoResponse
+		assert.NoError(decoded.UnmarshalBinary(b))
+		assert.Equal(resp, decoded)
+
+		assert.Equal(ErrUpdateInfoTruncated, decoded.UnmarshalBinary(b[:len(b)-1]))
+	})
+
+	t.Run("URI too long", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := UpdateInfoResponse{CUPSURI: string(make([]byte, 256))}.MarshalBinary()
+		assert.Equal(ErrURITooLong, err)
+	})
+}
+
+func TestUpdateDataSignature(t *testing.T) {
+	assert := require.New(t)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+
+	data := []byte("firmware")
+	sig, err := SignUpdateData(priv, data)
+	assert.NoError(err)
+	assert.Equal(PublicKeyCRC(&priv.PublicKey), sig.KeyCRC)
+
+	assert.NoError(VerifyUpdateData(&priv.PublicKey, sig, data))
+	assert.Equal(ErrInvalidSignature, VerifyUpdateData(&priv.PublicKey, sig, []byte("other")))
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+	assert.Equal(ErrSignatureKeyCRCMismatch, VerifyUpdateData(&other.PublicKey, sig, data))
+}
+
+func TestCredentials(t *testing.T) {
+	trust := []byte{0x30, 0x02, 0x01, 0x02}
+	cert := append([]byte{0x30, 0x81, 0x80}, make([]byte, 128)...)
+	key := []byte{0x30, 0x01, 0x03}
+
+	tests := []struct {
+		Name        string
+		Credentials Credentials
+		Bytes       []byte
+	}{
+		{
+			Name:        "trust only",
+			Credentials: Credentials{Trust: trust},
+			Bytes:       trust,
+		},
+		{
+			Name:        "client certificate",
+			Credentials: Credentials{Trust: trust, Cert: cert, Key: key},
+			Bytes:       append(append(append([]byte{}, trust...), cert...), key...),
+		},
+		{
+			Name:        "token",
+			Credentials: Credentials{Trust: trust, Token: "Authorization: Bearer abc\r\n"},
+			Bytes:       append(append([]byte{}, trust...), append([]byte{0, 0, 0, 0}, "Authorization: Bearer abc\r\n"...)...),
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := tst.Credentials.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(tst.Bytes, b)
+
+			var c Credentials
+			assert.NoError(c.UnmarshalBinary(b))
+			assert.Equal(tst.Credentials, c)
+
+			_, err = tst.Credentials.CRC()
+			assert.NoError(err)
+		})
+	}
+
+	t.Run("Truncated", func(t *testing.T) {
+		assert := require.New(t)
+
+		var c Credentials
+		assert.Equal(ErrCredentialsTruncated, c.UnmarshalBinary(cert[:10]))
+	})
+}
@@ -0,0 +1,286 @@
+package basicstation
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/brocaar/lorawan"
+)
+
+// ProtocolVersion defines the LNS protocol version.
+const ProtocolVersion = 2
+
+// MessageType defines the LNS message-type.
+type MessageType string
+
+// Message types.
+const (
+	VersionMessage              MessageType = "version"
+	RouterConfigMessage         MessageType = "router_config"
+	JoinRequestMessage          MessageType = "jreq"
+	UplinkDataFrameMessage      MessageType = "updf"
+	ProprietaryDataFrameMessage MessageType = "propdf"
+	DownlinkMessage             MessageType = "dnmsg"
+	DownlinkTransmittedMessage  MessageType = "dntxed"
+	TimeSyncMessage             MessageType = "timesync"
+)
+
+// ErrInvalidFrame is returned when the frame can't be converted into a
+// PHYPayload.
+var ErrInvalidFrame = errors.New("lorawan/basicstation: invalid frame")
+
+// GetMessageType returns the message-type of the given LNS message.
+func GetMessageType(b []byte) (MessageType, error) {
+	var msg struct {
+		MessageType MessageType `json:"msgtype"`
+	}
+
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return "", fmt.Errorf("lorawan/basicstation: unmarshal json error: %w", err)
+	}
+
+	return msg.MessageType, nil
+}
+
+// DiscoveryRequest implements the router-info request, which is sent by the
+// station to the /router-info endpoint to discover the LNS connection URI.
+type DiscoveryRequest struct {
+	Router EUI64 `json:"router"`
+}
+
+// DiscoveryResponse implements the router-info response.
+type DiscoveryResponse struct {
+	Router EUI64  `json:"router"`
+	Muxs   EUI64  `json:"muxs"`
+	URI    string `json:"uri"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Version implements the version message, which is sent by the station
+// after the connection has been established.
+type Version struct {
+	MessageType MessageType `json:"msgtype"`
+	Station     string      `json:"station"`
+	Firmware    string      `json:"firmware"`
+	Package     string      `json:"package"`
+	Model       string      `json:"model"`
+	Protocol    int         `json:"protocol"`
+	Features    string      `json:"features,omitempty"`
+}
+
+// GetFeatures returns the (space separated) features as slice.
+func (v Version) GetFeatures() []string {
+	return strings.Fields(v.Features)
+}
+
+// ValidateProtocol returns an error when the protocol version of the station
+// is not supported.
+func (v Version) ValidateProtocol() error {
+	if v.Protocol != ProtocolVersion {
+		return fmt.Errorf("lorawan/basicstation: protocol version %d is not supported", v.Protocol)
+	}
+	return nil
+}
+
+// RouterConfig implements the router_config message, which is sent by the
+// LNS as response to the version message.
+type RouterConfig struct {
+	MessageType MessageType  `json:"msgtype"`
+	NetID       []uint32     `json:"NetID"`
+	JoinEUI     [][2]uint64  `json:"JoinEui"` // Ranges of JoinEUIs (begin, end)
+	Region      string       `json:"region"`
+	HWSpec      string       `json:"hwspec"`
+	FreqRange   [2]uint32    `json:"freq_range"`
+	DRs         [][3]int     `json:"DRs"` // Per data-rate: spreading-factor (or 0 for FSK), bandwidth (kHz) and downlink-only flag
+	SX1301Conf  []SX1301Conf `json:"sx1301_conf"`
+	NoCCA       bool         `json:"nocca"`
+	NoDC        bool         `json:"nodc"`
+	NoDwell     bool         `json:"nodwell"`
+	MuxTime     float64      `json:"MuxTime,omitempty"`
+}
+
+// SX1301Conf implements the concentrator configuration.
+type SX1301Conf struct {
+	Radio0       SX1301ConfRadio   `json:"radio_0"`
+	Radio1       SX1301ConfRadio   `json:"radio_1"`
+	ChanFSK      SX1301ConfChannel `json:"chan_FSK"`
+	ChanLoRaStd  SX1301ConfChannel `json:"chan_Lora_std"`
+	ChanMultiSF0 SX1301ConfChannel `json:"chan_multiSF_0"`
+	ChanMultiSF1 SX1301ConfChannel `json:"chan_multiSF_1"`
+	ChanMultiSF2 SX1301ConfChannel `json:"chan_multiSF_2"`
+	ChanMultiSF3 SX1301ConfChannel `json:"chan_multiSF_3"`
+	ChanMultiSF4 SX1301ConfChannel `json:"chan_multiSF_4"`
+	ChanMultiSF5 SX1301ConfChannel `json:"chan_multiSF_5"`
+	ChanMultiSF6 SX1301ConfChannel `json:"chan_multiSF_6"`
+	ChanMultiSF7 SX1301ConfChannel `json:"chan_multiSF_7"`
+}
+
+// SX1301ConfRadio implements the radio configuration.
+type SX1301ConfRadio struct {
+	Enable bool   `json:"enable"`
+	Freq   uint32 `json:"freq"`
+}
+
+// SX1301ConfChannel implements the channel configuration.
+type SX1301ConfChannel struct {
+	Enable       bool `json:"enable"`
+	Radio        int  `json:"radio"`
+	IF           int  `json:"if"`
+	Bandwidth    int  `json:"bandwidth,omitempty"`
+	SpreadFactor int  `json:"spread_factor,omitempty"`
+}
+
+// RadioMetaData contains the radio meta-data of an uplink frame.
+type RadioMetaData struct {
+	DR     int                 `json:"DR"`
+	Freq   uint32              `json:"Freq"`
+	UpInfo RadioMetaDataUpInfo `json:"upinfo"`
+}
+
+// RadioMetaDataUpInfo contains the uplink info of an uplink frame.
+type RadioMetaDataUpInfo struct {
+	RCtx    int64   `json:"rctx"`
+	XTime   int64   `json:"xtime"`
+	GPSTime int64   `json:"gpstime"`
+	FTS     *int    `json:"fts,omitempty"` // fine-timestamp (ns)
+	RSSI    float32 `json:"rssi"`
+	SNR     float32 `json:"snr"`
+	RxTime  float64 `json:"rxtime"`
+}
+
+// JoinRequest implements the jreq message.
+type JoinRequest struct {
+	RadioMetaData
+
+	MessageType MessageType `json:"msgtype"`
+	MHDR        uint8       `json:"MHdr"`
+	JoinEUI     EUI64       `json:"JoinEui"`
+	DevEUI      EUI64       `json:"DevEui"`
+	DevNonce    uint16      `json:"DevNonce"`
+	MIC         int32       `json:"MIC"`
+	RefTime     float64     `json:"RefTime"`
+}
+
+// PHYPayload returns the PHYPayload bytes of the join-request.
+func (j JoinRequest) PHYPayload() []byte {
+	b := make([]byte, 23)
+	b[0] = j.MHDR
+	putEUI64(b[1:9], j.JoinEUI)
+	putEUI64(b[9:17], j.DevEUI)
+	binary.LittleEndian.PutUint16(b[17:19], j.DevNonce)
+	binary.LittleEndian.PutUint32(b[19:23], uint32(j.MIC))
+	return b
+}
+
+// UplinkDataFrame implements the updf message.
+type UplinkDataFrame struct {
+	RadioMetaData
+
+	MessageType MessageType      `json:"msgtype"`
+	MHDR        uint8            `json:"MHdr"`
+	DevAddr     int32            `json:"DevAddr"`
+	FCtrl       uint8            `json:"FCtrl"`
+	FCnt        uint16           `json:"FCnt"`
+	FOpts       lorawan.HEXBytes `json:"FOpts"`
+	FPort       int              `json:"FPort"` // -1 when the frame does not contain a FPort
+	FRMPayload  lorawan.HEXBytes `json:"FRMPayload"`
+	MIC         int32            `json:"MIC"`
+	RefTime     float64          `json:"RefTime"`
+}
+
+// PHYPayload returns the PHYPayload bytes of the uplink data frame.
+func (u UplinkDataFrame) PHYPayload() ([]byte, error) {
+	if len(u.FOpts) > 15 || len(u.FOpts) != int(u.FCtrl&0x0f) {
+		return nil, ErrInvalidFrame
+	}
+	if u.FPort < -1 || u.FPort > 255 || (u.FPort == -1 && len(u.FRMPayload) != 0) {
+		return nil, ErrInvalidFrame
+	}
+
+	b := make([]byte, 8, 13+len(u.FOpts)+len(u.FRMPayload))
+	b[0] = u.MHDR
+	binary.LittleEndian.PutUint32(b[1:5], uint32(u.DevAddr))
+	b[5] = u.FCtrl
+	binary.LittleEndian.PutUint16(b[6:8], u.FCnt)
+	b = append(b, u.FOpts...)
+	if u.FPort != -1 {
+		b = append(b, uint8(u.FPort))
+		b = append(b, u.FRMPayload...)
+	}
+	b = append(b, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b[len(b)-4:], uint32(u.MIC))
+
+	return b, nil
+}
+
+// ProprietaryDataFrame implements the propdf message.
+type ProprietaryDataFrame struct {
+	RadioMetaData
+
+	MessageType MessageType      `json:"msgtype"`
+	FRMPayload  lorawan.HEXBytes `json:"FRMPayload"`
+	RefTime     float64          `json:"RefTime"`
+}
+
+// DownlinkFrame implements the dnmsg message.
+type DownlinkFrame struct {
+	MessageType MessageType      `json:"msgtype"`
+	DevEUI      EUI64            `json:"DevEui"`
+	DeviceClass uint8            `json:"dC"` // 0 = Class A, 1 = Class B, 2 = Class C
+	DIID        int64            `json:"diid"`
+	PDU         lorawan.HEXBytes `json:"pdu"`
+	Priority    int              `json:"priority"`
+
+	// Class A
+	RxDelay *int    `json:"RxDelay,omitempty"`
+	RX1DR   *int    `json:"RX1DR,omitempty"`
+	RX1Freq *uint32 `json:"RX1Freq,omitempty"`
+	XTime   *int64  `json:"xtime,omitempty"`
+
+	// Class A & C
+	RX2DR   *int    `json:"RX2DR,omitempty"`
+	RX2Freq *uint32 `json:"RX2Freq,omitempty"`
+	RCtx    *int64  `json:"rctx,omitempty"`
+
+	// Class B
+	GPSTime *int64  `json:"gpstime,omitempty"`
+	DR      *int    `json:"DR,omitempty"`
+	Freq    *uint32 `json:"Freq,omitempty"`
+
+	MuxTime float64 `json:"MuxTime,omitempty"`
+}
+
+// DownlinkTransmitted implements the dntxed message, which is sent by the
+// station after the downlink has been transmitted.
+type DownlinkTransmitted struct {
+	MessageType MessageType `json:"msgtype"`
+	DIID        int64       `json:"diid"`
+	DevEUI      EUI64       `json:"DevEui"`
+	RCtx        int64       `json:"rctx"`
+	XTime       int64       `json:"xtime"`
+	TxTime      float64     `json:"txtime"`
+	GPSTime     int64       `json:"gpstime"`
+}
+
+// TimeSyncRequest implements the timesync request, sent by the station.
+type TimeSyncRequest struct {
+	MessageType MessageType `json:"msgtype"`
+	TxTime      int64       `json:"txtime"`
+}
+
+// TimeSyncResponse implements the timesync response, sent by the LNS.
+type TimeSyncResponse struct {
+	MessageType MessageType `json:"msgtype"`
+	TxTime      int64       `json:"txtime"`
+	GPSTime     int64       `json:"gpstime"`
+}
+
+// putEUI64 puts the given EUI64 in little-endian (air) byte order.
+func putEUI64(b []byte, eui EUI64) {
+	for i := range eui {
+		b[i] = eui[len(eui)-1-i]
+	}
+}
@@ -0,0 +1,174 @@
+package basicstation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestGetMessageType(t *testing.T) {
+	assert := require.New(t)
+
+	mt, err := GetMessageType([]byte(`{"msgtype":"updf"}`))
+	assert.NoError(err)
+	assert.Equal(UplinkDataFrameMessage, mt)
+
+	_, err = GetMessageType([]byte(`{`))
+	assert.Error(err)
+}
+
+func TestVersion(t *testing.T) {
+	assert := require.New(t)
+
+	var v Version
+	assert.NoError(json.Unmarshal([]byte(`{"msgtype":"version","station":"2.0.6(rpi/std)","firmware":null,"package":null,"model":"rpi","protocol":2,"features":"rmtsh gps"}`), &v))
+	assert.Equal(Version{
+		MessageType: VersionMessage,
+		Station:     "2.0.6(rpi/std)",
+		Model:       "rpi",
+		Protocol:    2,
+		Features:    "rmtsh gps",
+	}, v)
+	assert.Equal([]string{"rmtsh", "gps"}, v.GetFeatures())
+	assert.NoError(v.ValidateProtocol())
+
+	v.Protocol = 1
+	assert.EqualError(v.ValidateProtocol(), "lorawan/basicstation: protocol version 1 is not supported")
+}
+
+func TestJoinRequest(t *testing.T) {
+	assert := require.New(t)
+
+	var jr JoinRequest
+	assert.NoError(json.Unmarshal([]byte(`{
+		"msgtype": "jreq",
+		"MHdr": 0,
+		"JoinEui": "01-02-03-04-05-06-07-08",
+		"DevEui": "08-07-06-05-04-03-02-01",
+		"DevNonce": 258,
+		"MIC": -1,
+		"RefTime": 0,
+		"DR": 5,
+		"Freq": 868100000,
+		"upinfo": {"rctx": 0, "xtime": 1234, "gpstime": 0, "rssi": -50, "snr": 5.5, "rxtime": 1.5}
+	}`), &jr))
+
+	assert.Equal(5, jr.DR)
+	assert.Equal(uint32(868100000), jr.Freq)
+	assert.Equal(int64(1234), jr.UpInfo.XTime)
+	assert.Equal(float32(5.5), jr.UpInfo.SNR)
+
+	var phy lorawan.PHYPayload
+	assert.NoError(phy.UnmarshalBinary(jr.PHYPayload()))
+	assert.Equal(lorawan.JoinRequest, phy.MHDR.MType)
+	assert.Equal(lorawan.MIC{0xff, 0xff, 0xff, 0xff}, phy.MIC)
+	assert.Equal(&lorawan.JoinRequestPayload{
+		JoinEUI:  lorawan.EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+		DevEUI:   lorawan.EUI64{8, 7, 6, 5, 4, 3, 2, 1},
+		DevNonce: 258,
+	}, phy.MACPayload)
+}
+
+func TestUplinkDataFrame(t *testing.T) {
+	fPort := uint8(10)
+	phy := lorawan.PHYPayload{
+		MHDR: lorawan.MHDR{
+			MType: lorawan.UnconfirmedDataUp,
+			Major: lorawan.LoRaWANR1,
+		},
+		MACPayload: &lorawan.MACPayload{
+			FHDR: lorawan.FHDR{
+				DevAddr: lorawan.DevAddr{0x81, 0x02, 0x03, 0x04},
+				FCtrl:   lorawan.FCtrl{ADR: true},
+				FCnt:    10,
+				FOpts: []lorawan.Payload{
+					&lorawan.MACCommand{CID: lorawan.LinkCheckReq},
+				},
+			},
+			FPort:      &fPort,
+			FRMPayload: []lorawan.Payload{&lorawan.DataPayload{Bytes: []byte{1, 2, 3}}},
+		},
+		MIC: lorawan.MIC{0x01, 0x02, 0x03, 0x84},
+	}
+	phyB, err := phy.MarshalBinary()
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name          string
+		Frame         UplinkDataFrame
+		Expected      []byte
+		ExpectedError error
+	}{
+		{
+			Name: "valid frame",
+			Frame: UplinkDataFrame{
+				MHDR:       0x40,
+				DevAddr:    -2130574588, // 0x81020304
+				FCtrl:      0x81,
+				FCnt:       10,
+				FOpts:      lorawan.HEXBytes{0x02},
+				FPort:      10,
+				FRMPayload: lorawan.HEXBytes{1, 2, 3},
+				MIC:        -2080177663, // 0x84030201
+			},
+			Expected: phyB,
+		},
+		{
+			Name:          "FOpts length mismatch",
+			Frame:         UplinkDataFrame{FOpts: lorawan.HEXBytes{0x02}, FPort: -1},
+			ExpectedError: ErrInvalidFrame,
+		},
+		{
+			Name:          "FRMPayload without FPort",
+			Frame:         UplinkDataFrame{FPort: -1, FRMPayload: lorawan.HEXBytes{1}},
+			ExpectedError: ErrInvalidFrame,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := tst.Frame.PHYPayload()
+			assert.Equal(tst.ExpectedError, err)
+			assert.Equal(tst.Expected, b)
+		})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		assert := require.New(t)
+
+		var updf UplinkDataFrame
+		assert.NoError(json.Unmarshal([]byte(`{"msgtype":"updf","MHdr":64,"DevAddr":-2130574588,"FCtrl":129,"FCnt":10,"FOpts":"02","FPort":10,"FRMPayload":"010203","MIC":-2080177663,"RefTime":0,"DR":5,"Freq":868100000,"upinfo":{"rctx":0,"xtime":0,"gpstime":0,"rssi":-50,"snr":5,"rxtime":0}}`), &updf))
+		assert.Equal(lorawan.HEXBytes{1, 2, 3}, updf.FRMPayload)
+
+		b, err := updf.PHYPayload()
+		assert.NoError(err)
+		assert.Equal(phyB, b)
+	})
+}
+
+func TestDownlinkFrame(t *testing.T) {
+	assert := require.New(t)
+
+	rxDelay := 1
+	rx1DR := 5
+	rx1Freq := uint32(868100000)
+	xTime := int64(1234)
+
+	b, err := json.Marshal(DownlinkFrame{
+		MessageType: DownlinkMessage,
+		DevEUI:      EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+		DIID:        10,
+		PDU:         lorawan.HEXBytes{1, 2, 3},
+		RxDelay:     &rxDelay,
+		RX1DR:       &rx1DR,
+		RX1Freq:     &rx1Freq,
+		XTime:       &xTime,
+	})
+	assert.NoError(err)
+	assert.JSONEq(`{"msgtype":"dnmsg","DevEui":"102:304:506:708","dC":0,"diid":10,"pdu":"010203","priority":0,"RxDelay":1,"RX1DR":5,"RX1Freq":868100000,"xtime":1234}`, string(b))
+}
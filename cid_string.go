@@ -8,10 +8,12 @@ const (
 	_CID_name_0 = "ResetIndLinkCheckReqLinkADRReqDutyCycleReqRXParamSetupReqDevStatusReqNewChannelReqRXTimingSetupReqTXParamSetupReqDLChannelReqRekeyIndADRParamSetupReqDeviceTimeReqForceRejoinReqRejoinParamSetupReqPingSlotInfoReqPingSlotChannelReq"
 	_CID_name_1 = "BeaconFreqReq"
 	_CID_name_2 = "DeviceModeInd"
+	_CID_name_3 = "RelayConfReqEndDeviceConfReqFilterListReqUpdateUplinkListReqCtrlUplinkListReqConfigureFwdLimitReq"
 )
 
 var (
 	_CID_index_0 = [...]uint8{0, 8, 20, 30, 42, 57, 69, 82, 98, 113, 125, 133, 149, 162, 176, 195, 210, 228}
+	_CID_index_3 = [...]uint8{0, 12, 28, 41, 60, 77, 97}
 )
 
 func (i CID) String() string {
@@ -23,6 +25,9 @@ func (i CID) String() string {
 		return _CID_name_1
 	case i == 32:
 		return _CID_name_2
+	case 64 <= i && i <= 69:
+		i -= 64
+		return _CID_name_3[_CID_index_3[i]:_CID_index_3[i+1]]
 	default:
 		return "CID(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
@@ -0,0 +1,227 @@
+package classb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/band"
+)
+
+// Beacon errors.
+var (
+	ErrBeaconCRC           = errors.New("lorawan/classb: invalid beacon CRC")
+	ErrBeaconGwSpecificCRC = errors.New("lorawan/classb: invalid beacon GwSpecific CRC")
+)
+
+// InfoDesc defines the GwSpecific InfoDesc type.
+type InfoDesc uint8
+
+// Available InfoDesc values. Values 4 - 127 are RFU, values 128 - 255 are
+// reserved for network specific broadcasts.
+const (
+	InfoDescGPSAntenna1    InfoDesc = 0
+	InfoDescGPSAntenna2    InfoDesc = 1
+	InfoDescGPSAntenna3    InfoDesc = 2
+	InfoDescNetIDGatewayID InfoDesc = 3
+)
+
+// BeaconFormat defines the region specific beacon format, this is the size
+// of the RFU fields.
+type BeaconFormat struct {
+	RFU1Size int
+	RFU2Size int
+}
+
+// GetBeaconFormat returns the beacon format for the given band.
+func GetBeaconFormat(b band.Band) BeaconFormat {
+	switch b.Name() {
+	case "US915", "AU915":
+		return BeaconFormat{RFU1Size: 5, RFU2Size: 3}
+	case "CN470":
+		return BeaconFormat{RFU1Size: 3, RFU2Size: 1}
+	default:
+		return BeaconFormat{RFU1Size: 2}
+	}
+}
+
+// Size returns the size of the beacon payload in bytes.
+func (f BeaconFormat) Size() int {
+	// RFU1 | Time (4) | CRC (2) | GwSpecific (7) | RFU2 | CRC (2)
+	return f.RFU1Size + 4 + 2 + 7 + f.RFU2Size + 2
+}
+
+// GwSpecific holds the gateway specific part of the beacon.
+type GwSpecific struct {
+	InfoDesc InfoDesc
+	Info     [6]byte
+}
+
+// NewGPSCoordinateGwSpecific returns a GwSpecific containing the GPS
+// coordinate of the given gateway antenna (InfoDescGPSAntenna1 - 3).
+func NewGPSCoordinateGwSpecific(infoDesc InfoDesc, lat, lng float64) (GwSpecific, error) {
+	if infoDesc > InfoDescGPSAntenna3 {
+		return GwSpecific{}, fmt.Errorf("lorawan/classb: InfoDesc %d is not a GPS coordinate", infoDesc)
+	}
+	if lat < -90 || lat > 90 {
+		return GwSpecific{}, errors.New("lorawan/classb: latitude must be between -90 and 90")
+	}
+	if lng < -180 || lng > 180 {
+		return GwSpecific{}, errors.New("lorawan/classb: longitude must be between -180 and 180")
+	}
+
+	out := GwSpecific{InfoDesc: infoDesc}
+	putInt24(out.Info[0:3], math.Round(lat*(1<<23)/90))
+	putInt24(out.Info[3:6], math.Round(lng*(1<<23)/180))
+	return out, nil
+}
+
+// GPSCoordinate returns the GPS coordinate (latitude and longitude in
+// degrees). An error is returned when the InfoDesc does not define a GPS
+// coordinate.
+func (g GwSpecific) GPSCoordinate() (lat, lng float64, err error) {
+	if g.InfoDesc > InfoDescGPSAntenna3 {
+		return 0, 0, fmt.Errorf("lorawan/classb: InfoDesc %d is not a GPS coordinate", g.InfoDesc)
+	}
+
+	lat = float64(int24(g.Info[0:3])) * 90 / (1 << 23)
+	lng = float64(int24(g.Info[3:6])) * 180 / (1 << 23)
+	return lat, lng, nil
+}
+
+// NewNetIDGatewayIDGwSpecific returns a GwSpecific containing the NetID and
+// the (network specific) gateway ID.
+func NewNetIDGatewayIDGwSpecific(netID lorawan.NetID, gatewayID [3]byte) GwSpecific {
+	out := GwSpecific{InfoDesc: InfoDescNetIDGatewayID}
+	for i := range netID {
+		// little-endian
+		out.Info[i] = netID[len(netID)-1-i]
+	}
+	copy(out.Info[3:6], gatewayID[:])
+	return out
+}
+
+// NetIDGatewayID returns the NetID and gateway ID. An error is returned when
+// the InfoDesc is not InfoDescNetIDGatewayID.
+func (g GwSpecific) NetIDGatewayID() (lorawan.NetID, [3]byte, error) {
+	var netID lorawan.NetID
+	var gatewayID [3]byte
+
+	if g.InfoDesc != InfoDescNetIDGatewayID {
+		return netID, gatewayID, fmt.Errorf("lorawan/classb: InfoDesc %d does not contain the NetID and gateway ID", g.InfoDesc)
+	}
+
+	for i := range netID {
+		netID[len(netID)-1-i] = g.Info[i]
+	}
+	copy(gatewayID[:], g.Info[3:6])
+	return netID, gatewayID, nil
+}
+
+// Beacon implements the Class-B beacon payload.
+type Beacon struct {
+	// Format holds the region specific beacon format (see GetBeaconFormat).
+	Format BeaconFormat
+
+	// Time holds the time since GPS epoch of the beacon. Only the (32 bit)
+	// seconds are encoded.
+	Time time.Duration
+
+	// GwSpecific holds the gateway specific part of the beacon.
+	GwSpecific GwSpecific
+}
+
+// MarshalBinary encodes the beacon into bytes.
+func (b Beacon) MarshalBinary() ([]byte, error) {
+	if b.Time < 0 {
+		return nil, errors.New("lorawan/classb: Time must be greater than or equal to 0")
+	}
+
+	out := make([]byte, b.Format.Size())
+
+	// RFU1 | Time | CRC
+	i := b.Format.RFU1Size
+	binary.LittleEndian.PutUint32(out[i:i+4], uint32(b.Time/time.Second))
+	i += 4
+	binary.LittleEndian.PutUint16(out[i:i+2], beaconCRC(out[:i]))
+	i += 2
+
+	// GwSpecific | RFU2 | CRC
+	start := i
+	out[i] = byte(b.GwSpecific.InfoDesc)
+	copy(out[i+1:i+7], b.GwSpecific.Info[:])
+	i += 7 + b.Format.RFU2Size
+	binary.LittleEndian.PutUint16(out[i:i+2], beaconCRC(out[start:i]))
+
+	return out, nil
+}
+
+// UnmarshalBinary decodes the beacon from bytes, using the Format of the
+// beacon. When only the CRC of the GwSpecific part is invalid, the Time is
+// decoded and ErrBeaconGwSpecificCRC is returned, as the Time can still be
+// used by the device.
+func (b *Beacon) UnmarshalBinary(data []byte) error {
+	if len(data) != b.Format.Size() {
+		return fmt.Errorf("lorawan/classb: %d bytes of data are expected", b.Format.Size())
+	}
+
+	i := b.Format.RFU1Size + 4
+	if binary.LittleEndian.Uint16(data[i:i+2]) != beaconCRC(data[:i]) {
+		return ErrBeaconCRC
+	}
+	b.Time = time.Duration(binary.LittleEndian.Uint32(data[i-4:i])) * time.Second
+	i += 2
+
+	start := i
+	i += 7 + b.Format.RFU2Size
+	if binary.LittleEndian.Uint16(data[i:i+2]) != beaconCRC(data[start:i]) {
+		b.GwSpecific = GwSpecific{}
+		return ErrBeaconGwSpecificCRC
+	}
+
+	b.GwSpecific.InfoDesc = InfoDesc(data[start])
+	copy(b.GwSpecific.Info[:], data[start+1:start+7])
+
+	return nil
+}
+
+// beaconCRC returns the CRC-16 (polynomial 0x1021, initial value 0) of the
+// given data, as defined by IEEE 802.15.4.
+func beaconCRC(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// putInt24 encodes the given value as little-endian 24 bit two's complement
+// integer, clamped to the 24 bit range.
+func putInt24(b []byte, v float64) {
+	if v > math.MaxInt32>>8 {
+		v = math.MaxInt32 >> 8
+	}
+	if v < math.MinInt32>>8 {
+		v = math.MinInt32 >> 8
+	}
+
+	i := int32(v)
+	b[0] = byte(i)
+	b[1] = byte(i >> 8)
+	b[2] = byte(i >> 16)
+}
+
+// int24 decodes the little-endian 24 bit two's complement integer.
+func int24(b []byte) int32 {
+	return int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+}
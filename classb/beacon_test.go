@@ -0,0 +1,127 @@
+package classb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/band"
+)
+
+func TestGetBeaconFormat(t *testing.T) {
+	tests := []struct {
+		Band         band.Name
+		ExpectedSize int
+	}{
+		{Band: band.EU868, ExpectedSize: 17},
+		{Band: band.AS923_2, ExpectedSize: 17},
+		{Band: band.US915, ExpectedSize: 23},
+		{Band: band.AU915, ExpectedSize: 23},
+		{Band: band.CN470, ExpectedSize: 19},
+	}
+
+	for _, tst := range tests {
+		t.Run(string(tst.Band), func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := band.GetConfig(tst.Band, false, lorawan.DwellTimeNoLimit)
+			assert.NoError(err)
+			assert.Equal(tst.ExpectedSize, GetBeaconFormat(b).Size())
+		})
+	}
+}
+
+func TestBeacon(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Beacon Beacon
+		Bytes  []byte
+	}{
+		{
+			// example of the LoRaWAN Class-B specification
+			Name: "EU868 GPS coordinate",
+			Beacon: Beacon{
+				Format: BeaconFormat{RFU1Size: 2},
+				Time:   0xcc020000 * time.Second,
+				GwSpecific: GwSpecific{
+					InfoDesc: InfoDescGPSAntenna1,
+					Info:     [6]byte{0x01, 0x20, 0x00, 0x00, 0x81, 0x03},
+				},
+			},
+			Bytes: []byte{0x00, 0x00, 0x00, 0x00, 0x02, 0xcc, 0xa2, 0x7e, 0x00, 0x01, 0x20, 0x00, 0x00, 0x81, 0x03, 0xde, 0x55},
+		},
+		{
+			Name: "US915 NetID and gateway ID",
+			Beacon: Beacon{
+				Format:     BeaconFormat{RFU1Size: 5, RFU2Size: 3},
+				Time:       1234 * time.Second,
+				GwSpecific: NewNetIDGatewayIDGwSpecific(lorawan.NetID{0x01, 0x02, 0x03}, [3]byte{0x04, 0x05, 0x06}),
+			},
+			Bytes: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0xd2, 0x04, 0x00, 0x00, 0xab, 0x99, 0x03, 0x03, 0x02, 0x01, 0x04, 0x05, 0x06, 0x00, 0x00, 0x00, 0x23, 0xa9},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := tst.Beacon.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(tst.Bytes, b)
+
+			beacon := Beacon{Format: tst.Beacon.Format}
+			assert.NoError(beacon.UnmarshalBinary(b))
+			assert.Equal(tst.Beacon, beacon)
+		})
+	}
+
+	t.Run("invalid CRC", func(t *testing.T) {
+		assert := require.New(t)
+
+		b := []byte{0x00, 0x00, 0x00, 0x00, 0x02, 0xcc, 0xa2, 0x7e, 0x00, 0x01, 0x20, 0x00, 0x00, 0x81, 0x03, 0xde, 0x55}
+		beacon := Beacon{Format: BeaconFormat{RFU1Size: 2}}
+
+		b[16] = 0x00
+		assert.Equal(ErrBeaconGwSpecificCRC, beacon.UnmarshalBinary(b))
+		assert.Equal(0xcc020000*time.Second, beacon.Time)
+
+		b[7] = 0x00
+		assert.Equal(ErrBeaconCRC, beacon.UnmarshalBinary(b))
+
+		assert.EqualError(beacon.UnmarshalBinary(b[:10]), "lorawan/classb: 17 bytes of data are expected")
+	})
+}
+
+func TestGwSpecific(t *testing.T) {
+	assert := require.New(t)
+
+	gw, err := NewGPSCoordinateGwSpecific(InfoDescGPSAntenna2, 51.5, -0.125)
+	assert.NoError(err)
+	assert.Equal(InfoDescGPSAntenna2, gw.InfoDesc)
+
+	lat, lng, err := gw.GPSCoordinate()
+	assert.NoError(err)
+	assert.InDelta(51.5, lat, 90.0/(1<<23))
+	assert.InDelta(-0.125, lng, 180.0/(1<<23))
+
+	// the north pole is clamped to the max. 24 bit value
+	gw, err = NewGPSCoordinateGwSpecific(InfoDescGPSAntenna1, 90, 180)
+	assert.NoError(err)
+	assert.Equal([6]byte{0xff, 0xff, 0x7f, 0xff, 0xff, 0x7f}, gw.Info)
+
+	_, err = NewGPSCoordinateGwSpecific(InfoDescGPSAntenna1, 91, 0)
+	assert.Error(err)
+	_, err = NewGPSCoordinateGwSpecific(InfoDescNetIDGatewayID, 0, 0)
+	assert.Error(err)
+
+	gw = NewNetIDGatewayIDGwSpecific(lorawan.NetID{0x01, 0x02, 0x03}, [3]byte{0x04, 0x05, 0x06})
+	netID, gatewayID, err := gw.NetIDGatewayID()
+	assert.NoError(err)
+	assert.Equal(lorawan.NetID{0x01, 0x02, 0x03}, netID)
+	assert.Equal([3]byte{0x04, 0x05, 0x06}, gatewayID)
+
+	_, _, err = gw.GPSCoordinate()
+	assert.Error(err)
+}
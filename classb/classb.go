@@ -0,0 +1,122 @@
+// Package classb provides Class-B beacon frame and timing helpers, including
+// the beacon-only time synchronization of LoRaWAN 1.0.x devices which do not
+// implement the DeviceTimeReq mac-command.
+package classb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/brocaar/lorawan/backend"
+	"github.com/brocaar/lorawan/band"
+)
+
+// Beacon timing (LoRaWAN Class-B specification).
+const (
+	BeaconPeriod   = 128 * time.Second
+	BeaconReserved = 2120 * time.Millisecond
+	BeaconGuard    = 3 * time.Second
+	BeaconWindow   = 122880 * time.Millisecond
+
+	// BeaconlessOperationTimeout defines the duration a device stays in
+	// Class-B mode after the last received beacon.
+	BeaconlessOperationTimeout = 120 * time.Minute
+)
+
+// Errors.
+var (
+	ErrClassBTimeout  = errors.New("lorawan/classb: ClassBTimeout must be greater than 0")
+	ErrPingSlotPeriod = errors.New("lorawan/classb: PingSlotPeriod must be a power of two between 1 and 128 seconds")
+)
+
+// GetBeaconStartForTime returns the start of the beacon period (as time
+// since GPS epoch) containing the given time since GPS epoch.
+func GetBeaconStartForTime(ts time.Duration) time.Duration {
+	return ts - (ts % BeaconPeriod)
+}
+
+// GetNextBeaconStartForTime returns the start of the next beacon period (as
+// time since GPS epoch) after the given time since GPS epoch.
+func GetNextBeaconStartForTime(ts time.Duration) time.Duration {
+	return GetBeaconStartForTime(ts) + BeaconPeriod
+}
+
+// SupportsDeviceTime returns if the given LoRaWAN version implements the
+// DeviceTimeReq mac-command (LoRaWAN 1.0.3+). Devices implementing an
+// earlier version must acquire the time from the beacon.
+func SupportsDeviceTime(macVersion string) bool {
+	switch macVersion {
+	case band.LoRaWAN_1_0_0, band.LoRaWAN_1_0_1, band.LoRaWAN_1_0_2:
+		return false
+	default:
+		return true
+	}
+}
+
+// BeaconOnlyTimeSync contains the parameters of the time synchronization of
+// a device which acquires the time by searching for the beacon only (the
+// device does not implement DeviceTimeReq). As the device does not know when
+// the beacon is sent, it must listen continuously for up to one beacon
+// period.
+type BeaconOnlyTimeSync struct {
+	// SearchStart is the (GPS epoch) time at which the device starts
+	// searching for the beacon.
+	SearchStart time.Duration
+
+	// NextBeacon is the (GPS epoch) time of the first beacon the device
+	// can receive.
+	NextBeacon time.Duration
+
+	// SearchTimeout is the max. duration of the beacon search.
+	SearchTimeout time.Duration
+
+	// ClassBAvailable is the (GPS epoch) time after which the network-server
+	// can assume that the device has locked on the beacon and that the
+	// ping-slots can be used. This is after the beacon-reserved interval
+	// of the first beacon the device can receive.
+	ClassBAvailable time.Duration
+}
+
+// GetBeaconOnlyTimeSync returns the beacon-only time synchronization
+// parameters for a device starting the beacon search at the given time
+// since GPS epoch.
+func GetBeaconOnlyTimeSync(searchStart time.Duration) BeaconOnlyTimeSync {
+	next := GetBeaconStartForTime(searchStart)
+	if next < searchStart {
+		next += BeaconPeriod
+	}
+
+	return BeaconOnlyTimeSync{
+		SearchStart:     searchStart,
+		NextBeacon:      next,
+		SearchTimeout:   BeaconPeriod,
+		ClassBAvailable: next + BeaconReserved,
+	}
+}
+
+// ValidateDeviceProfile validates the Class-B parameters of the given
+// device-profile. The PingSlotPeriod (seconds) must be a power of two
+// between 1 and 128 seconds and the ClassBTimeout must be set and must
+// be greater than or equal to the ping-slot period, so that the device has
+// at least one ping-slot within the timeout. When Class-B is not supported,
+// this always returns nil.
+func ValidateDeviceProfile(dp backend.DeviceProfile) error {
+	if !dp.SupportsClassB {
+		return nil
+	}
+
+	if dp.PingSlotPeriod < 1 || dp.PingSlotPeriod > 128 || dp.PingSlotPeriod&(dp.PingSlotPeriod-1) != 0 {
+		return ErrPingSlotPeriod
+	}
+
+	if dp.ClassBTimeout <= 0 {
+		return ErrClassBTimeout
+	}
+
+	if dp.ClassBTimeout < dp.PingSlotPeriod {
+		return fmt.Errorf("lorawan/classb: ClassBTimeout (%ds) must be greater than or equal to the PingSlotPeriod (%ds)", dp.ClassBTimeout, dp.PingSlotPeriod)
+	}
+
+	return nil
+}
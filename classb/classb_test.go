@@ -0,0 +1,163 @@
+package classb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/backend"
+	"github.com/brocaar/lorawan/band"
+)
+
+func TestBeaconStart(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(1280*time.Second, GetBeaconStartForTime(1280*time.Second))
+	assert.Equal(1280*time.Second, GetBeaconStartForTime(1300*time.Second))
+	assert.Equal(1408*time.Second, GetNextBeaconStartForTime(1280*time.Second))
+	assert.Equal(1408*time.Second, GetNextBeaconStartForTime(1300*time.Second))
+}
+
+func TestSupportsDeviceTime(t *testing.T) {
+	assert := require.New(t)
+
+	assert.False(SupportsDeviceTime(band.LoRaWAN_1_0_2))
+	assert.True(SupportsDeviceTime(band.LoRaWAN_1_0_3))
+	assert.True(SupportsDeviceTime(band.LoRaWAN_1_1_0))
+}
+
+func TestGetBeaconOnlyTimeSync(t *testing.T) {
+	tests := []struct {
+		Name        string
+		SearchStart time.Duration
+		Expected    BeaconOnlyTimeSync
+	}{
+		{
+			Name:        "search starts at beacon",
+			SearchStart: 1280 * time.Second,
+			Expected: BeaconOnlyTimeSync{
+				SearchStart:     1280 * time.Second,
+				NextBeacon:      1280 * time.Second,
+				SearchTimeout:   BeaconPeriod,
+				ClassBAvailable: 1280*time.Second + BeaconReserved,
+			},
+		},
+		{
+			Name:        "search starts within beacon period",
+			SearchStart: 1300 * time.Second,
+			Expected: BeaconOnlyTimeSync{
+				SearchStart:     1300 * time.Second,
+				NextBeacon:      1408 * time.Second,
+				SearchTimeout:   BeaconPeriod,
+				ClassBAvailable: 1408*time.Second + BeaconReserved,
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.Expected, GetBeaconOnlyTimeSync(tst.SearchStart))
+		})
+	}
+}
+
+func TestValidateDeviceProfile(t *testing.T) {
+	tests := []struct {
+		Name          string
+		DeviceProfile backend.DeviceProfile
+		ExpectedError string
+	}{
+		{
+			Name:          "Class-B not supported",
+			DeviceProfile: backend.DeviceProfile{},
+		},
+		{
+			Name:          "valid",
+			DeviceProfile: backend.DeviceProfile{SupportsClassB: true, PingSlotPeriod: 32, ClassBTimeout: 60},
+		},
+		{
+			Name:          "invalid ping-slot period",
+			DeviceProfile: backend.DeviceProfile{SupportsClassB: true, PingSlotPeriod: 30, ClassBTimeout: 60},
+			ExpectedError: ErrPingSlotPeriod.Error(),
+		},
+		{
+			Name:          "ClassBTimeout not set",
+			DeviceProfile: backend.DeviceProfile{SupportsClassB: true, PingSlotPeriod: 32},
+			ExpectedError: ErrClassBTimeout.Error(),
+		},
+		{
+			Name:          "ClassBTimeout < PingSlotPeriod",
+			DeviceProfile: backend.DeviceProfile{SupportsClassB: true, PingSlotPeriod: 128, ClassBTimeout: 60},
+			ExpectedError: "lorawan/classb: ClassBTimeout (60s) must be greater than or equal to the PingSlotPeriod (128s)",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			err := ValidateDeviceProfile(tst.DeviceProfile)
+			if tst.ExpectedError == "" {
+				assert.NoError(err)
+			} else {
+				assert.EqualError(err, tst.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestPingSlots(t *testing.T) {
+	assert := require.New(t)
+
+	devAddr := lorawan.DevAddr{1, 2, 3, 4}
+	beaconTime := 1234 * BeaconPeriod
+
+	pingNb, err := GetPingNb(0)
+	assert.NoError(err)
+	assert.Equal(128, pingNb)
+
+	pingPeriod, err := GetPingPeriod(7)
+	assert.NoError(err)
+	assert.Equal(4096, pingPeriod)
+
+	_, err = GetPingPeriod(8)
+	assert.EqualError(err, "lorawan/classb: periodicity must be between 0 and 7, got 8")
+
+	// Rand[0:2] = 0x97, 0x97 (38807)
+	pingOffset, err := GetPingOffset(beaconTime, devAddr, 32)
+	assert.NoError(err)
+	assert.Equal(23, pingOffset)
+
+	pingOffset, err = GetPingOffset(beaconTime, devAddr, 4096)
+	assert.NoError(err)
+	assert.Equal(1943, pingOffset)
+
+	_, err = GetPingOffset(beaconTime, devAddr, 0)
+	assert.Error(err)
+
+	slots, err := GetPingSlots(beaconTime, devAddr, 7)
+	assert.NoError(err)
+	assert.Equal([]time.Duration{beaconTime + BeaconReserved + 1943*PingSlotLen}, slots)
+
+	slots, err = GetPingSlots(beaconTime, devAddr, 0)
+	assert.NoError(err)
+	assert.Len(slots, 128)
+	assert.Equal(beaconTime+BeaconReserved+23*PingSlotLen, slots[0])
+	assert.Equal(960*time.Millisecond, slots[1]-slots[0])
+	assert.True(slots[127] < beaconTime+BeaconReserved+BeaconWindow)
+
+	next, err := GetNextPingSlotAfter(beaconTime, devAddr, 7)
+	assert.NoError(err)
+	assert.Equal(beaconTime+BeaconReserved+1943*PingSlotLen, next)
+
+	// after the last ping-slot, the first ping-slot of the next beacon period
+	// is returned
+	next, err = GetNextPingSlotAfter(beaconTime+BeaconPeriod-time.Second, devAddr, 7)
+	assert.NoError(err)
+	nextSlots, err := GetPingSlots(beaconTime+BeaconPeriod, devAddr, 7)
+	assert.NoError(err)
+	assert.Equal(nextSlots[0], next)
+}
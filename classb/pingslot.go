@@ -0,0 +1,108 @@
+package classb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/crypto"
+)
+
+// PingSlotLen defines the duration of a ping-slot.
+const PingSlotLen = 30 * time.Millisecond
+
+// pingSlotCount defines the number of ping-slots in the beacon window.
+const pingSlotCount = 1 << 12
+
+// GetPingNb returns the number of ping-slots per beacon period for the given
+// periodicity (0 - 7), as used by the PingSlotInfoReq mac-command.
+func GetPingNb(periodicity int) (int, error) {
+	if periodicity < 0 || periodicity > 7 {
+		return 0, fmt.Errorf("lorawan/classb: periodicity must be between 0 and 7, got %d", periodicity)
+	}
+	return 1 << (7 - periodicity), nil
+}
+
+// GetPingPeriod returns the period (in number of ping-slots) between two
+// consecutive ping-slots for the given periodicity (0 - 7).
+func GetPingPeriod(periodicity int) (int, error) {
+	pingNb, err := GetPingNb(periodicity)
+	if err != nil {
+		return 0, err
+	}
+	return pingSlotCount / pingNb, nil
+}
+
+// GetPingOffset returns the (pseudo-random) offset (in number of
+// ping-slots) of the first ping-slot of the beacon period starting at the
+// given beacon time (time since GPS epoch), for the given DevAddr and ping
+// period:
+//
+//	Rand = aes128_encrypt(0x00..., BeaconTime | DevAddr | pad16)
+//	pingOffset = (Rand[0] + Rand[1] * 256) modulo pingPeriod
+func GetPingOffset(beaconTime time.Duration, devAddr lorawan.DevAddr, pingPeriod int) (int, error) {
+	if pingPeriod <= 0 || pingPeriod > pingSlotCount {
+		return 0, fmt.Errorf("lorawan/classb: ping period must be between 1 and %d, got %d", pingSlotCount, pingPeriod)
+	}
+
+	devAddrB, err := devAddr.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	var b [16]byte
+	binary.LittleEndian.PutUint32(b[0:4], uint32(beaconTime/time.Second))
+	copy(b[4:8], devAddrB)
+
+	rand, err := crypto.EncryptBlock([16]byte{}, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return (int(rand[0]) + int(rand[1])*256) % pingPeriod, nil
+}
+
+// GetPingSlots returns the start of the ping-slots (as time since GPS epoch)
+// of the given DevAddr and periodicity (0 - 7), within the beacon period
+// starting at the given beacon time (time since GPS epoch).
+func GetPingSlots(beaconTime time.Duration, devAddr lorawan.DevAddr, periodicity int) ([]time.Duration, error) {
+	pingPeriod, err := GetPingPeriod(periodicity)
+	if err != nil {
+		return nil, err
+	}
+
+	pingOffset, err := GetPingOffset(beaconTime, devAddr, pingPeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]time.Duration, 0, pingSlotCount/pingPeriod)
+	for slot := pingOffset; slot < pingSlotCount; slot += pingPeriod {
+		out = append(out, beaconTime+BeaconReserved+time.Duration(slot)*PingSlotLen)
+	}
+
+	return out, nil
+}
+
+// GetNextPingSlotAfter returns the start of the first ping-slot (as time
+// since GPS epoch) of the given DevAddr and periodicity (0 - 7) after the
+// given time since GPS epoch.
+func GetNextPingSlotAfter(after time.Duration, devAddr lorawan.DevAddr, periodicity int) (time.Duration, error) {
+	beaconTime := GetBeaconStartForTime(after)
+
+	for {
+		slots, err := GetPingSlots(beaconTime, devAddr, periodicity)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, s := range slots {
+			if s > after {
+				return s, nil
+			}
+		}
+
+		beaconTime += BeaconPeriod
+	}
+}
@@ -0,0 +1,111 @@
+// Package clock provides the Clock interface used by the time-dependent
+// components of this module, so that these can be tested or simulated using
+// virtual time.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock defines the clock interface.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current
+	// time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real implements Clock using the system clock.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Virtual implements a Clock using virtual time. The time only moves forward
+// by calling Add or Set. It is safe for concurrent use.
+type Virtual struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	until time.Time
+	ch    chan time.Time
+}
+
+// NewVirtual returns a new Virtual clock, set to the given time.
+func NewVirtual(t time.Time) *Virtual {
+	return &Virtual{
+		now: t,
+	}
+}
+
+// Now returns the current virtual time.
+func (c *Virtual) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After returns a channel on which the virtual time is sent once the virtual
+// time has been advanced by at least d.
+func (c *Virtual) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, waiter{
+		until: c.now.Add(d),
+		ch:    ch,
+	})
+
+	return ch
+}
+
+// Add advances the virtual time by d.
+func (c *Virtual) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(c.now.Add(d))
+}
+
+// Set sets the virtual time to t. Setting the time backwards does not
+// trigger any pending After channel.
+func (c *Virtual) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(t)
+}
+
+func (c *Virtual) set(t time.Time) {
+	c.now = t
+
+	var pending []waiter
+	for _, w := range c.waiters {
+		if !w.until.After(t) {
+			w.ch <- t
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+}
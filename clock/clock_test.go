@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReal(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Now()
+	assert.False(Real.Now().Before(now))
+
+	select {
+	case <-Real.After(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestVirtual(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Now", func(t *testing.T) {
+		assert := require.New(t)
+
+		c := NewVirtual(start)
+		assert.Equal(start, c.Now())
+
+		c.Add(time.Second)
+		assert.Equal(start.Add(time.Second), c.Now())
+
+		c.Set(start)
+		assert.Equal(start, c.Now())
+	})
+
+	t.Run("After", func(t *testing.T) {
+		assert := require.New(t)
+
+		c := NewVirtual(start)
+		ch := c.After(time.Second)
+
+		c.Add(500 * time.Millisecond)
+		assert.Len(ch, 0)
+
+		c.Add(500 * time.Millisecond)
+		assert.Len(ch, 1)
+		assert.Equal(start.Add(time.Second), <-ch)
+	})
+
+	t.Run("After zero duration", func(t *testing.T) {
+		assert := require.New(t)
+
+		c := NewVirtual(start)
+		assert.Equal(start, <-c.After(0))
+	})
+
+	t.Run("Implements Clock", func(t *testing.T) {
+		var _ Clock = NewVirtual(start)
+	})
+}
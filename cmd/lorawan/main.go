@@ -0,0 +1,154 @@
+// Command lorawan provides LoRaWAN debugging utilities.
+//
+// Usage:
+//
+//	lorawan session-keys [flags]
+//	lorawan diff [flags] <frame-a> <frame-b>
+//
+// The session-keys subcommand prints the derived session keys and an example
+// encrypted uplink data frame for LoRaWAN 1.0.x and LoRaWAN 1.1, given the
+// root keys and join parameters.
+//
+// The diff subcommand prints the field-level differences between two
+// (HEX or Base64 encoded) frames.
+package main
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/backend/joinserver"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: lorawan <command> [flags]\n\ncommands:\n  session-keys  print the session keys test-vectors\n  diff          print the differences between two frames")
+	}
+
+	switch args[0] {
+	case "session-keys":
+		return sessionKeys(args[1:], stdout, stderr)
+	case "diff":
+		return diff(args[1:], stdout, stderr)
+	default:
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+}
+
+func sessionKeys(args []string, stdout, stderr io.Writer) error {
+	var in joinserver.SessionKeysTestVectorInput
+	var joinNonce, devNonce, fCnt, fPort uint
+
+	fs := flag.NewFlagSet("session-keys", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	textVar(fs, &in.NwkKey, "nwk-key", "NwkKey (LoRaWAN 1.1) or AppKey (LoRaWAN 1.0) (HEX)")
+	textVar(fs, &in.AppKey, "app-key", "AppKey (LoRaWAN 1.1) (HEX)")
+	textVar(fs, &in.DevEUI, "dev-eui", "DevEUI (HEX)")
+	textVar(fs, &in.JoinEUI, "join-eui", "JoinEUI (HEX)")
+	textVar(fs, &in.NetID, "net-id", "NetID (HEX)")
+	textVar(fs, &in.DevAddr, "dev-addr", "DevAddr of the example frame (HEX)")
+	fs.UintVar(&joinNonce, "join-nonce", 0, "JoinNonce")
+	fs.UintVar(&devNonce, "dev-nonce", 0, "DevNonce")
+	fs.UintVar(&fCnt, "f-cnt", 0, "FCnt of the example frame")
+	fs.UintVar(&fPort, "f-port", 1, "FPort of the example frame")
+	fs.Func("frm-payload", "FRMPayload of the example frame (HEX)", func(s string) error {
+		b, err := hex.DecodeString(s)
+		in.FRMPayload = b
+		return err
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if joinNonce > 1<<24-1 {
+		return fmt.Errorf("join-nonce must be less than %d", 1<<24)
+	}
+	if devNonce > 1<<16-1 {
+		return fmt.Errorf("dev-nonce must be less than %d", 1<<16)
+	}
+	if fPort > 255 {
+		return fmt.Errorf("f-port must be less than 256")
+	}
+
+	in.JoinNonce = lorawan.JoinNonce(joinNonce)
+	in.DevNonce = lorawan.DevNonce(devNonce)
+	in.FCnt = uint32(fCnt)
+	in.FPort = uint8(fPort)
+
+	vectors, err := joinserver.GenerateSessionKeysTestVectors(in)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Input   joinserver.SessionKeysTestVectorInput `json:"input"`
+		Vectors joinserver.SessionKeysTestVectors     `json:"vectors"`
+	}{in, vectors})
+}
+
+func diff(args []string, stdout, stderr io.Writer) error {
+	var useBase64 bool
+
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.BoolVar(&useBase64, "base64", false, "frames are Base64 encoded (default HEX)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: lorawan diff [flags] <frame-a> <frame-b>")
+	}
+
+	var frames [2][]byte
+	for i := range frames {
+		var err error
+		if useBase64 {
+			frames[i], err = base64.StdEncoding.DecodeString(fs.Arg(i))
+		} else {
+			frames[i], err = hex.DecodeString(fs.Arg(i))
+		}
+		if err != nil {
+			return fmt.Errorf("decode frame %d error: %w", i+1, err)
+		}
+	}
+
+	diffs, err := lorawan.DiffFrames(frames[0], frames[1])
+	if err != nil {
+		return err
+	}
+
+	if len(diffs) == 0 {
+		fmt.Fprintln(stdout, "frames are equal")
+		return nil
+	}
+	for _, d := range diffs {
+		fmt.Fprintln(stdout, d)
+	}
+	return nil
+}
+
+// textVar defines a flag with the given name, using the UnmarshalText method
+// of the given value.
+func textVar(fs *flag.FlagSet, v encoding.TextUnmarshaler, name, usage string) {
+	fs.Func(name, usage, func(s string) error {
+		return v.UnmarshalText([]byte(s))
+	})
+}
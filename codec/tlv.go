@@ -0,0 +1,256 @@
+// Package codec implements a generic TLV (type-length-value) codec which can
+// be used for proprietary FRMPayload formats (FPort > 0).
+//
+// Each TLV element is encoded as 1 byte type, 1 byte value length, followed
+// by the value. The value types are registered per direction, so that vendors
+// can declare their payload formats:
+//
+//	c := codec.New()
+//	c.Register(true, 0x01, 2, func() codec.Value { return new(codec.Int16) }) // temperature
+//	c.Register(true, 0x02, 1, func() codec.Value { return new(codec.Uint8) }) // humidity
+//
+// The built-in value types are little-endian encoded.
+package codec
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/brocaar/lorawan/registry"
+)
+
+// MaxValueSize defines the max. value size (in bytes) of a TLV element.
+const MaxValueSize = 255
+
+// Type defines the TLV type.
+type Type byte
+
+// Value defines the interface of a TLV value.
+type Value interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// TLV defines a TLV element.
+type TLV struct {
+	Type  Type  `json:"type"`
+	Value Value `json:"value"`
+}
+
+// Codec implements the TLV codec. It is safe for concurrent use.
+type Codec struct {
+	registry *registry.Registry[Type, Value]
+}
+
+// New returns a new Codec without registered value types.
+func New() *Codec {
+	return &Codec{
+		registry: registry.New[Type, Value](nil, nil),
+	}
+}
+
+// Register registers (or overwrites) the value type for the given direction
+// and TLV type. Size holds the fixed value size in bytes, or 0 in case the
+// value has a variable size.
+func (c *Codec) Register(uplink bool, t Type, size int, newFunc func() Value) {
+	c.registry.Register(uplink, t, registry.Command[Value]{Size: size, New: newFunc})
+}
+
+// Marshal encodes the given TLV elements into bytes.
+func (c *Codec) Marshal(elements []TLV) ([]byte, error) {
+	var out []byte
+
+	for _, e := range elements {
+		if e.Value == nil {
+			return nil, fmt.Errorf("lorawan/codec: value of type %d must not be nil", e.Type)
+		}
+
+		b, err := e.Value.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("lorawan/codec: marshal value of type %d error: %w", e.Type, err)
+		}
+		if len(b) > MaxValueSize {
+			return nil, fmt.Errorf("lorawan/codec: max value size of type %d is %d bytes", e.Type, MaxValueSize)
+		}
+
+		out = append(out, byte(e.Type), byte(len(b)))
+		out = append(out, b...)
+	}
+
+	return out, nil
+}
+
+// Unmarshal decodes the given bytes into TLV elements. Values of types that
+// are not registered for the given direction are decoded as *RawValue.
+func (c *Codec) Unmarshal(uplink bool, b []byte) ([]TLV, error) {
+	var out []TLV
+
+	for len(b) != 0 {
+		if len(b) < 2 {
+			return nil, errors.New("lorawan/codec: not enough remaining bytes for type and length")
+		}
+
+		t := Type(b[0])
+		l := int(b[1])
+		b = b[2:]
+
+		if len(b) < l {
+			return nil, fmt.Errorf("lorawan/codec: not enough remaining bytes for value of type %d", t)
+		}
+
+		v, size, ok := c.registry.Get(uplink, t)
+		if !ok {
+			v = &RawValue{}
+		} else if size != 0 && size != l {
+			return nil, fmt.Errorf("lorawan/codec: value of type %d must be %d bytes, got %d", t, size, l)
+		}
+
+		if err := v.UnmarshalBinary(b[:l]); err != nil {
+			return nil, fmt.Errorf("lorawan/codec: unmarshal value of type %d error: %w", t, err)
+		}
+
+		out = append(out, TLV{Type: t, Value: v})
+		b = b[l:]
+	}
+
+	return out, nil
+}
+
+// RawValue implements a value with raw bytes.
+type RawValue []byte
+
+// MarshalBinary encodes the value into bytes.
+func (v RawValue) MarshalBinary() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalBinary decodes the value from bytes.
+func (v *RawValue) UnmarshalBinary(data []byte) error {
+	*v = make(RawValue, len(data))
+	copy(*v, data)
+	return nil
+}
+
+// String implements a (UTF-8) string value.
+type String string
+
+// MarshalBinary encodes the value into bytes.
+func (v String) MarshalBinary() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalBinary decodes the value from bytes.
+func (v *String) UnmarshalBinary(data []byte) error {
+	*v = String(data)
+	return nil
+}
+
+// Uint8 implements an uint8 value.
+type Uint8 uint8
+
+// MarshalBinary encodes the value into bytes.
+func (v Uint8) MarshalBinary() ([]byte, error) {
+	return []byte{byte(v)}, nil
+}
+
+// UnmarshalBinary decodes the value from bytes.
+func (v *Uint8) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return errors.New("1 byte is expected")
+	}
+	*v = Uint8(data[0])
+	return nil
+}
+
+// Int8 implements an int8 value.
+type Int8 int8
+
+// MarshalBinary encodes the value into bytes.
+func (v Int8) MarshalBinary() ([]byte, error) {
+	return []byte{byte(v)}, nil
+}
+
+// UnmarshalBinary decodes the value from bytes.
+func (v *Int8) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return errors.New("1 byte is expected")
+	}
+	*v = Int8(data[0])
+	return nil
+}
+
+// Uint16 implements an uint16 value.
+type Uint16 uint16
+
+// MarshalBinary encodes the value into bytes.
+func (v Uint16) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, uint16(v))
+	return b, nil
+}
+
+// UnmarshalBinary decodes the value from bytes.
+func (v *Uint16) UnmarshalBinary(data []byte) error {
+	if len(data) != 2 {
+		return errors.New("2 bytes are expected")
+	}
+	*v = Uint16(binary.LittleEndian.Uint16(data))
+	return nil
+}
+
+// Int16 implements an int16 value.
+type Int16 int16
+
+// MarshalBinary encodes the value into bytes.
+func (v Int16) MarshalBinary() ([]byte, error) {
+	return Uint16(v).MarshalBinary()
+}
+
+// UnmarshalBinary decodes the value from bytes.
+func (v *Int16) UnmarshalBinary(data []byte) error {
+	var u Uint16
+	if err := u.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	*v = Int16(u)
+	return nil
+}
+
+// Uint32 implements an uint32 value.
+type Uint32 uint32
+
+// MarshalBinary encodes the value into bytes.
+func (v Uint32) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	return b, nil
+}
+
+// UnmarshalBinary decodes the value from bytes.
+func (v *Uint32) UnmarshalBinary(data []byte) error {
+	if len(data) != 4 {
+		return errors.New("4 bytes are expected")
+	}
+	*v = Uint32(binary.LittleEndian.Uint32(data))
+	return nil
+}
+
+// Int32 implements an int32 value.
+type Int32 int32
+
+// MarshalBinary encodes the value into bytes.
+func (v Int32) MarshalBinary() ([]byte, error) {
+	return Uint32(v).MarshalBinary()
+}
+
+// UnmarshalBinary decodes the value from bytes.
+func (v *Int32) UnmarshalBinary(data []byte) error {
+	var u Uint32
+	if err := u.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	*v = Int32(u)
+	return nil
+}
@@ -0,0 +1,106 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	c := New()
+	c.Register(true, 0x01, 2, func() Value { return new(Int16) })
+	c.Register(true, 0x02, 1, func() Value { return new(Uint8) })
+	c.Register(true, 0x03, 0, func() Value { return new(String) })
+	c.Register(true, 0x04, 4, func() Value { return new(Uint32) })
+	c.Register(false, 0x01, 4, func() Value { return new(Int32) })
+
+	int16Val := Int16(-215)
+	uint8Val := Uint8(65)
+	stringVal := String("v1.2")
+	uint32Val := Uint32(3600)
+	int32Val := Int32(-1)
+	rawVal := RawValue{1, 2, 3}
+
+	tests := []struct {
+		Name          string
+		Uplink        bool
+		Elements      []TLV
+		Bytes         []byte
+		ExpectedError error
+	}{
+		{
+			Name:   "uplink",
+			Uplink: true,
+			Elements: []TLV{
+				{Type: 0x01, Value: &int16Val},
+				{Type: 0x02, Value: &uint8Val},
+				{Type: 0x03, Value: &stringVal},
+				{Type: 0x04, Value: &uint32Val},
+			},
+			Bytes: []byte{0x01, 0x02, 0x29, 0xff, 0x02, 0x01, 0x41, 0x03, 0x04, 'v', '1', '.', '2', 0x04, 0x04, 0x10, 0x0e, 0x00, 0x00},
+		},
+		{
+			Name: "downlink",
+			Elements: []TLV{
+				{Type: 0x01, Value: &int32Val},
+			},
+			Bytes: []byte{0x01, 0x04, 0xff, 0xff, 0xff, 0xff},
+		},
+		{
+			Name:   "unknown type",
+			Uplink: true,
+			Elements: []TLV{
+				{Type: 0x10, Value: &rawVal},
+			},
+			Bytes: []byte{0x10, 0x03, 0x01, 0x02, 0x03},
+		},
+		{
+			Name:          "invalid value size",
+			Uplink:        true,
+			Bytes:         []byte{0x02, 0x02, 0x01, 0x02},
+			ExpectedError: errors.New("lorawan/codec: value of type 2 must be 1 bytes, got 2"),
+		},
+		{
+			Name:          "truncated value",
+			Uplink:        true,
+			Bytes:         []byte{0x04, 0x04, 0x01},
+			ExpectedError: errors.New("lorawan/codec: not enough remaining bytes for value of type 4"),
+		},
+		{
+			Name:          "truncated header",
+			Uplink:        true,
+			Bytes:         []byte{0x02, 0x01, 0x01, 0x02},
+			ExpectedError: errors.New("lorawan/codec: not enough remaining bytes for type and length"),
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			elements, err := c.Unmarshal(tst.Uplink, tst.Bytes)
+			if tst.ExpectedError != nil {
+				assert.Equal(tst.ExpectedError, err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tst.Elements, elements)
+
+			b, err := c.Marshal(tst.Elements)
+			assert.NoError(err)
+			assert.Equal(tst.Bytes, b)
+		})
+	}
+
+	t.Run("Marshal errors", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := c.Marshal([]TLV{{Type: 0x01}})
+		assert.EqualError(err, "lorawan/codec: value of type 1 must not be nil")
+
+		large := make(RawValue, 256)
+		_, err = c.Marshal([]TLV{{Type: 0x01, Value: &large}})
+		assert.EqualError(err, "lorawan/codec: max value size of type 1 is 255 bytes")
+	})
+}
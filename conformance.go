@@ -0,0 +1,105 @@
+package lorawan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidateConformance validates that the mac-command conforms to the
+// value-range constraints mandated by the LoRaWAN specification. By default,
+// the value-ranges of the mac-command fields are only validated when
+// marshaling and values which can't be represented are rejected. To enforce
+// these constraints symmetrically, call ValidateConformance before
+// MarshalBinary and set DecodeOptions.Conformance when decoding.
+//
+// The following constraints are checked:
+//
+//	Constraint                                 Default            Conformance
+//	-----------------------------------------  -----------------  ------------------
+//	Field fits its bit-width (e.g. Delay,      Marshal            Marshal, Unmarshal
+//	NbRep, ChMaskCntl, MaxEIRP)
+//	RFU bits are zero                          Marshal (implicit) Marshal, Unmarshal
+//	Frequency >= MinMACCommandFrequency (or 0) Marshal            Marshal, Unmarshal
+//	DutyCycleReq MaxDCycle is 0 - 15 or 255    Marshal (*)        Marshal, Unmarshal
+//	ForceRejoinReq RejoinType is 0 or 2        Marshal            Marshal, Unmarshal
+//	NewChannelReq MinDR <= MaxDR (Freq != 0)   -                  Marshal, Unmarshal
+//	Version Minor is 1 (LoRaWAN 1.1)           -                  Marshal, Unmarshal
+//	DeviceModeInd / DeviceModeConf Class is    Marshal            Marshal, Unmarshal
+//	Class-A or Class-C
+//
+//	(*) also on Unmarshal when DecodeOptions.StrictDutyCycleReq is set.
+func (m MACCommand) ValidateConformance() error {
+	if m.Payload == nil {
+		return nil
+	}
+	if _, err := m.Payload.MarshalBinary(); err != nil {
+		return err
+	}
+	return validateMACCommandConformance(m.Payload, nil)
+}
+
+// conformanceValidator is implemented by the mac-command payloads which
+// have constraints that are only enforced when validating the conformance.
+type conformanceValidator interface {
+	validateConformance() error
+}
+
+// validateMACCommandConformance validates the given (decoded) mac-command
+// payload. When data is not nil, it also validates that the payload encodes
+// to the same bytes, meaning that all fields are within their range and that
+// no RFU bits are set.
+func validateMACCommandConformance(pl MACCommandPayload, data []byte) error {
+	// the CID is shared by the uplink and downlink mac-command, therefore
+	// the name is derived from the payload type
+	name := strings.TrimSuffix(strings.TrimPrefix(fmt.Sprintf("%T", pl), "*lorawan."), "Payload")
+
+	if data != nil {
+		b, err := pl.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("lorawan: %s payload is not conformant: %w", name, err)
+		}
+		if !bytes.Equal(b, data) {
+			return fmt.Errorf("lorawan: %s payload is not conformant: RFU bits must be 0", name)
+		}
+	}
+
+	if v, ok := pl.(conformanceValidator); ok {
+		if err := v.validateConformance(); err != nil {
+			return fmt.Errorf("lorawan: %s payload is not conformant: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (p NewChannelReqPayload) validateConformance() error {
+	if !p.IsDisabled() && p.MinDR > p.MaxDR {
+		return errors.New("MinDR must be less than or equal to MaxDR")
+	}
+	return nil
+}
+
+func (v Version) validateConformance() error {
+	if v.Minor != 1 {
+		return errors.New("Minor must be 1")
+	}
+	return nil
+}
+
+func (p ResetIndPayload) validateConformance() error {
+	return p.DevLoRaWANVersion.validateConformance()
+}
+
+func (p ResetConfPayload) validateConformance() error {
+	return p.ServLoRaWANVersion.validateConformance()
+}
+
+func (p RekeyIndPayload) validateConformance() error {
+	return p.DevLoRaWANVersion.validateConformance()
+}
+
+func (p RekeyConfPayload) validateConformance() error {
+	return p.ServLoRaWANVersion.validateConformance()
+}
@@ -0,0 +1,116 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformanceMode(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Uplink        bool
+		Bytes         []byte
+		MACCommand    *MACCommand
+		ExpectedError string
+	}{
+		{
+			Name:          "RXTimingSetupReq with RFU bits set",
+			Bytes:         []byte{byte(RXTimingSetupReq), 0x15},
+			ExpectedError: "lorawan: RXTimingSetupReq payload is not conformant: lorawan: the max value of Delay is 15",
+		},
+		{
+			Name:          "LinkADRReq with RFU bit set in Redundancy",
+			Bytes:         []byte{byte(LinkADRReq), 0x51, 0x07, 0x00, 0x81},
+			ExpectedError: "lorawan: LinkADRReq payload is not conformant: RFU bits must be 0",
+		},
+		{
+			Name:          "DutyCycleReq with RFU MaxDCycle",
+			Bytes:         []byte{byte(DutyCycleReq), 16},
+			ExpectedError: "lorawan: only a MaxDCycle value of 0 - 15 and 255 is allowed",
+		},
+		{
+			Name:          "ForceRejoinReq with RejoinType 1",
+			Bytes:         []byte{byte(ForceRejoinReq), 0x15, 0x00},
+			ExpectedError: "lorawan: ForceRejoinReq payload is not conformant: lorawan: RejoinType must be 0 or 2",
+		},
+		{
+			Name:          "NewChannelReq with MinDR > MaxDR",
+			Bytes:         []byte{byte(NewChannelReq), 3, 0x18, 0x4f, 0x84, 0x05},
+			ExpectedError: "lorawan: NewChannelReq payload is not conformant: MinDR must be less than or equal to MaxDR",
+		},
+		{
+			Name:          "ResetInd with RFU version",
+			Uplink:        true,
+			Bytes:         []byte{byte(ResetInd), 2},
+			ExpectedError: "lorawan: ResetInd payload is not conformant: Minor must be 1",
+		},
+		{
+			Name:          "DeviceModeInd with RFU class",
+			Uplink:        true,
+			Bytes:         []byte{byte(DeviceModeInd), 0x01},
+			ExpectedError: "lorawan: DeviceModeInd payload is not conformant: lorawan: Class must be DeviceModeClassA or DeviceModeClassC, got DeviceModeRFU",
+		},
+		{
+			Name:       "valid DeviceModeConf",
+			Bytes:      []byte{byte(DeviceModeConf), 0x02},
+			MACCommand: &MACCommand{CID: DeviceModeConf, Payload: &DeviceModeConfPayload{Class: DeviceModeClassC}},
+		},
+		{
+			Name:       "valid NewChannelReq",
+			Bytes:      []byte{byte(NewChannelReq), 3, 0x18, 0x4f, 0x84, 0x50},
+			MACCommand: &MACCommand{CID: NewChannelReq, Payload: &NewChannelReqPayload{ChIndex: 3, Freq: 867100000, MinDR: 0, MaxDR: 5}},
+		},
+		{
+			Name:       "valid RXTimingSetupReq",
+			Bytes:      []byte{byte(RXTimingSetupReq), 0x05},
+			MACCommand: &MACCommand{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 5}},
+		},
+	}
+
+	opts := DecodeOptions{Conformance: true}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			var mac MACCommand
+			err := mac.UnmarshalBinaryWithOptions(tst.Uplink, tst.Bytes, opts)
+			if tst.ExpectedError != "" {
+				assert.EqualError(err, tst.ExpectedError)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Equal(*tst.MACCommand, mac)
+			assert.NoError(mac.ValidateConformance())
+
+			b, err := mac.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(tst.Bytes, b)
+		})
+	}
+
+	t.Run("ValidateConformance", func(t *testing.T) {
+		assert := require.New(t)
+
+		err := MACCommand{CID: NewChannelReq, Payload: &NewChannelReqPayload{ChIndex: 3, Freq: 867100000, MinDR: 5, MaxDR: 0}}.ValidateConformance()
+		assert.EqualError(err, "lorawan: NewChannelReq payload is not conformant: MinDR must be less than or equal to MaxDR")
+
+		err = MACCommand{CID: ResetConf, Payload: &ResetConfPayload{ServLoRaWANVersion: Version{Minor: 0}}}.ValidateConformance()
+		assert.EqualError(err, "lorawan: ResetConf payload is not conformant: Minor must be 1")
+
+		err = MACCommand{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 16}}.ValidateConformance()
+		assert.EqualError(err, "lorawan: the max value of Delay is 15")
+
+		assert.NoError(MACCommand{CID: DevStatusReq}.ValidateConformance())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert := require.New(t)
+
+		var mac MACCommand
+		assert.NoError(mac.UnmarshalBinary(false, []byte{byte(RXTimingSetupReq), 0x15}))
+		assert.Equal(MACCommand{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 0x15}}, mac)
+	})
+}
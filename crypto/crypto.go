@@ -0,0 +1,153 @@
+// Package crypto provides the AES-128 primitives used by LoRaWAN and its
+// adjacent specifications (e.g. Relay and the application-layer packages):
+// AES-CMAC based MIC calculation, AES-ECB block encryption (e.g. for key
+// derivation and the join-accept encryption) and the AES-CTR like
+// encryption of the FRMPayload and FOpts using A-blocks.
+//
+// Keys are passed as [16]byte, to which the lorawan.AES128Key type is
+// assignable. Blocks are handled in the byte order in which they are
+// defined by the LoRaWAN specification, meaning that multi-byte fields
+// (e.g. DevAddr and FCnt) must already be encoded little-endian.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"github.com/jacobsa/crypto/cmac"
+)
+
+// BlockSize defines the AES block size in bytes.
+const BlockSize = aes.BlockSize
+
+// newCipher returns the AES-128 block cipher for the given key.
+func newCipher(key [16]byte) (cipher.Block, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	if block.BlockSize() != BlockSize {
+		return nil, fmt.Errorf("lorawan/crypto: block-size of %d bytes is expected", BlockSize)
+	}
+	return block, nil
+}
+
+// CMAC returns the AES-CMAC of the concatenation of the given data, using
+// the given key.
+func CMAC(key [16]byte, data ...[]byte) ([16]byte, error) {
+	var out [16]byte
+
+	hash, err := cmac.New(key[:])
+	if err != nil {
+		return out, err
+	}
+	for _, b := range data {
+		if _, err := hash.Write(b); err != nil {
+			return out, err
+		}
+	}
+
+	hb := hash.Sum(nil)
+	if len(hb) < len(out) {
+		return out, fmt.Errorf("lorawan/crypto: the hash returned less than %d bytes", len(out))
+	}
+	copy(out[:], hb)
+
+	return out, nil
+}
+
+// MIC returns the MIC (the first four bytes of the AES-CMAC) of the
+// concatenation of the given data, using the given key.
+func MIC(key [16]byte, data ...[]byte) ([4]byte, error) {
+	var mic [4]byte
+
+	b, err := CMAC(key, data...)
+	if err != nil {
+		return mic, err
+	}
+	copy(mic[:], b[:])
+
+	return mic, nil
+}
+
+// EncryptBlock returns aes128_encrypt(key, b), e.g. as used for the key
+// derivation.
+func EncryptBlock(key [16]byte, b [16]byte) ([16]byte, error) {
+	var out [16]byte
+
+	block, err := newCipher(key)
+	if err != nil {
+		return out, err
+	}
+	block.Encrypt(out[:], b[:])
+
+	return out, nil
+}
+
+// EncryptECB encrypts the given data using AES-ECB. The length of the data
+// must be a multiple of BlockSize.
+func EncryptECB(key [16]byte, data []byte) ([]byte, error) {
+	return ecb(key, data, false)
+}
+
+// DecryptECB decrypts the given data using AES-ECB. The length of the data
+// must be a multiple of BlockSize.
+//
+// Note that the LoRaWAN join-accept is encrypted using aes128_decrypt and
+// decrypted using aes128_encrypt, so that the end-device only needs to
+// implement the AES encrypt operation.
+func DecryptECB(key [16]byte, data []byte) ([]byte, error) {
+	return ecb(key, data, true)
+}
+
+func ecb(key [16]byte, data []byte, decrypt bool) ([]byte, error) {
+	if len(data)%BlockSize != 0 {
+		return nil, fmt.Errorf("lorawan/crypto: data must be a multiple of %d bytes", BlockSize)
+	}
+
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += BlockSize {
+		if decrypt {
+			block.Decrypt(out[i:i+BlockSize], data[i:i+BlockSize])
+		} else {
+			block.Encrypt(out[i:i+BlockSize], data[i:i+BlockSize])
+		}
+	}
+
+	return out, nil
+}
+
+// EncryptABlocks encrypts (or decrypts) the given data in-place by XOR-ing
+// it with the key-stream S = aes128_encrypt(key, A1) | aes128_encrypt(key, A2)
+// | ..., where Ai equals the given A-block with the last byte set to i (the
+// block counter). This is the scheme used for the FRMPayload and FOpts
+// encryption. The given data is returned.
+func EncryptABlocks(key [16]byte, a [16]byte, data []byte) ([]byte, error) {
+	if len(data) > 255*BlockSize {
+		return nil, errors.New("lorawan/crypto: max number of A-blocks is 255")
+	}
+
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var s [16]byte
+	for i := 0; i*BlockSize < len(data); i++ {
+		a[15] = byte(i + 1)
+		block.Encrypt(s[:], a[:])
+
+		for j := 0; j < BlockSize && i*BlockSize+j < len(data); j++ {
+			data[i*BlockSize+j] ^= s[j]
+		}
+	}
+
+	return data, nil
+}
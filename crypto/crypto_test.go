@@ -0,0 +1,125 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestCMAC(t *testing.T) {
+	// test vectors from RFC 4493
+	key := [16]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+
+	tests := []struct {
+		Name     string
+		Data     [][]byte
+		Expected string
+	}{
+		{
+			Name:     "empty",
+			Expected: "bb1d6929e95937287fa37d129b756746",
+		},
+		{
+			Name:     "16 bytes",
+			Data:     [][]byte{mustDecodeHex("6bc1bee22e409f96e93d7e117393172a")},
+			Expected: "070a16b46b4d4144f79bdd9dd04a287c",
+		},
+		{
+			Name:     "16 bytes in multiple slices",
+			Data:     [][]byte{mustDecodeHex("6bc1bee22e40"), mustDecodeHex("9f96e93d7e117393172a")},
+			Expected: "070a16b46b4d4144f79bdd9dd04a287c",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := CMAC(key, tst.Data...)
+			assert.NoError(err)
+			assert.Equal(tst.Expected, hex.EncodeToString(b[:]))
+
+			mic, err := MIC(key, tst.Data...)
+			assert.NoError(err)
+			assert.Equal(tst.Expected[0:8], hex.EncodeToString(mic[:]))
+		})
+	}
+}
+
+func TestEncryptBlock(t *testing.T) {
+	assert := require.New(t)
+
+	// test vector from FIPS-197
+	key := [16]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	var pt [16]byte
+	copy(pt[:], mustDecodeHex("00112233445566778899aabbccddeeff"))
+
+	ct, err := EncryptBlock(key, pt)
+	assert.NoError(err)
+	assert.Equal("69c4e0d86a7b0430d8cdb78070b4c55a", hex.EncodeToString(ct[:]))
+}
+
+func TestECB(t *testing.T) {
+	assert := require.New(t)
+
+	key := [16]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	pt := mustDecodeHex("00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff")
+
+	ct, err := EncryptECB(key, pt)
+	assert.NoError(err)
+	assert.Equal("69c4e0d86a7b0430d8cdb78070b4c55a69c4e0d86a7b0430d8cdb78070b4c55a", hex.EncodeToString(ct))
+
+	out, err := DecryptECB(key, ct)
+	assert.NoError(err)
+	assert.Equal(pt, out)
+
+	_, err = EncryptECB(key, pt[1:])
+	assert.EqualError(err, "lorawan/crypto: data must be a multiple of 16 bytes")
+}
+
+func TestEncryptABlocks(t *testing.T) {
+	assert := require.New(t)
+
+	key := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	a := [16]byte{0x01, 0, 0, 0, 0, 0x01, 1, 2, 3, 4, 10}
+	pt := []byte("hello world, this is LoRaWAN")
+
+	// calculate the expected key-stream
+	var s []byte
+	for i := 1; i <= 2; i++ {
+		ai := a
+		ai[15] = byte(i)
+		si, err := EncryptBlock(key, ai)
+		assert.NoError(err)
+		s = append(s, si[:]...)
+	}
+
+	expected := make([]byte, len(pt))
+	for i := range pt {
+		expected[i] = pt[i] ^ s[i]
+	}
+
+	data := make([]byte, len(pt))
+	copy(data, pt)
+
+	ct, err := EncryptABlocks(key, a, data)
+	assert.NoError(err)
+	assert.Equal(expected, ct)
+
+	// encryption is in-place
+	assert.Equal(expected, data)
+
+	// decryption
+	out, err := EncryptABlocks(key, a, ct)
+	assert.NoError(err)
+	assert.Equal(pt, out)
+}
@@ -0,0 +1,204 @@
+package lorawan
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DevAddrPrefix defines a DevAddr prefix, e.g. the AddrPrefix (type-prefix
+// and NwkID) of a NetID. Only the first Length bits of the DevAddr are used.
+type DevAddrPrefix struct {
+	DevAddr DevAddr
+	Length  int
+}
+
+// String implements fmt.Stringer.
+func (p DevAddrPrefix) String() string {
+	return fmt.Sprintf("%s/%d", p.DevAddr, p.Length)
+}
+
+// Size returns the number of DevAddrs within the prefix.
+func (p DevAddrPrefix) Size() uint64 {
+	return 1 << uint(32-p.Length)
+}
+
+// Contains returns true when the given DevAddr is within the prefix.
+func (p DevAddrPrefix) Contains(a DevAddr) bool {
+	return a.mask(p.Length) == p.DevAddr.mask(p.Length)
+}
+
+// RandomDevAddr returns a random DevAddr within the prefix.
+func (p DevAddrPrefix) RandomDevAddr() (DevAddr, error) {
+	var a DevAddr
+	if _, err := rand.Read(a[:]); err != nil {
+		return a, fmt.Errorf("lorawan: read random bytes error: %w", err)
+	}
+
+	// keep the random bits outside the prefix
+	nwkAddr := binary.BigEndian.Uint32(a[:]) &^ (^uint32(0) << uint(32-p.Length))
+	binary.BigEndian.PutUint32(a[:], p.DevAddr.mask(p.Length)|nwkAddr)
+
+	return a, nil
+}
+
+// mask returns the DevAddr as uint32, keeping only the first n bits.
+func (a DevAddr) mask(n int) uint32 {
+	if n == 0 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(a[:]) & (^uint32(0) << uint(32-n))
+}
+
+// DevAddrPrefix returns the AddrPrefix of the NetID, covering the DevAddrs
+// that can be assigned by the network with this NetID.
+func (n NetID) DevAddrPrefix() DevAddrPrefix {
+	var a DevAddr
+	a.SetAddrPrefix(n)
+
+	return DevAddrPrefix{
+		DevAddr: a,
+		Length:  n.AddrPrefixLength(),
+	}
+}
+
+// AddrPrefix returns the AddrPrefix (type-prefix and NwkID) of the DevAddr.
+func (a DevAddr) AddrPrefix() DevAddrPrefix {
+	t := a.NetIDType()
+	if t == -1 {
+		// all bits are set, which is an invalid (RFU) type-prefix
+		return DevAddrPrefix{DevAddr: a, Length: 32}
+	}
+
+	bits := devAddrPrefixBits[t]
+	length := bits[0] + bits[1]
+
+	var out DevAddr
+	binary.BigEndian.PutUint32(out[:], a.mask(length))
+
+	return DevAddrPrefix{
+		DevAddr: out,
+		Length:  length,
+	}
+}
+
+// DevAddrUtilization holds the DevAddr utilization of a DevAddr prefix.
+type DevAddrUtilization struct {
+	// Prefix holds the DevAddr prefix.
+	Prefix DevAddrPrefix
+
+	// Used holds the number of unique DevAddrs observed within the prefix.
+	Used uint64
+
+	// Capacity holds the number of DevAddrs within the prefix.
+	Capacity uint64
+
+	// Ratio holds the Used / Capacity ratio.
+	Ratio float64
+
+	// ExhaustionRisk is set when the Ratio is greater than or equal to the
+	// threshold of the DevAddrStats.
+	ExhaustionRisk bool
+}
+
+// DevAddrStats collects the (unique) DevAddrs observed by the network and
+// reports the address-space utilization per NetID or DevAddr prefix, e.g. to
+// plan the assignment of additional NetIDs before the DevAddr space is
+// exhausted. It is safe for concurrent use.
+type DevAddrStats struct {
+	mu        sync.RWMutex
+	threshold float64
+	devAddrs  map[DevAddr]struct{}
+}
+
+// NewDevAddrStats creates a new DevAddrStats. The threshold (0 - 1) defines
+// the utilization ratio from which the exhaustion risk is flagged.
+func NewDevAddrStats(threshold float64) *DevAddrStats {
+	return &DevAddrStats{
+		threshold: threshold,
+		devAddrs:  make(map[DevAddr]struct{}),
+	}
+}
+
+// Add adds the given observed DevAddrs. DevAddrs that have already been
+// observed are counted once.
+func (s *DevAddrStats) Add(devAddrs ...DevAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range devAddrs {
+		s.devAddrs[a] = struct{}{}
+	}
+}
+
+// Remove removes the given DevAddrs, e.g. when these are no longer in use.
+func (s *DevAddrStats) Remove(devAddrs ...DevAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range devAddrs {
+		delete(s.devAddrs, a)
+	}
+}
+
+// Utilization returns the utilization of the given DevAddr prefix.
+func (s *DevAddrStats) Utilization(prefix DevAddrPrefix) DevAddrUtilization {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var used uint64
+	for a := range s.devAddrs {
+		if prefix.Contains(a) {
+			used++
+		}
+	}
+
+	return s.newUtilization(prefix, used)
+}
+
+// NetIDUtilization returns the utilization of the AddrPrefix of the given
+// NetID.
+func (s *DevAddrStats) NetIDUtilization(netID NetID) DevAddrUtilization {
+	return s.Utilization(netID.DevAddrPrefix())
+}
+
+// PrefixUtilizations returns the utilization of every AddrPrefix for which
+// DevAddrs have been observed, sorted by ratio (highest first).
+func (s *DevAddrStats) PrefixUtilizations() []DevAddrUtilization {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	used := make(map[DevAddrPrefix]uint64)
+	for a := range s.devAddrs {
+		used[a.AddrPrefix()]++
+	}
+
+	out := make([]DevAddrUtilization, 0, len(used))
+	for prefix, count := range used {
+		out = append(out, s.newUtilization(prefix, count))
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Ratio != out[j].Ratio {
+			return out[i].Ratio > out[j].Ratio
+		}
+		return out[i].Prefix.String() < out[j].Prefix.String()
+	})
+
+	return out
+}
+
+func (s *DevAddrStats) newUtilization(prefix DevAddrPrefix, used uint64) DevAddrUtilization {
+	capacity := prefix.Size()
+	ratio := float64(used) / float64(capacity)
+
+	return DevAddrUtilization{
+		Prefix:         prefix,
+		Used:           used,
+		Capacity:       capacity,
+		Ratio:          ratio,
+		ExhaustionRisk: ratio >= s.threshold,
+	}
+}
@@ -0,0 +1,129 @@
+package lorawan
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevAddrPrefix(t *testing.T) {
+	tests := []struct {
+		NetID          NetID
+		ExpectedPrefix string
+		ExpectedSize   uint64
+	}{
+		{NetID{0x00, 0x00, 0x01}, "02000000/7", 1 << 25},
+		{NetID{0x20, 0x00, 0x01}, "81000000/8", 1 << 24},
+		{NetID{0x40, 0x00, 0x01}, "c0100000/12", 1 << 20},
+		{NetID{0xc0, 0x00, 0x01}, "fc000400/22", 1 << 10},
+		{NetID{0xe0, 0x00, 0x01}, "fe000080/25", 1 << 7},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.NetID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			prefix := tst.NetID.DevAddrPrefix()
+			assert.Equal(tst.ExpectedPrefix, prefix.String())
+			assert.Equal(tst.ExpectedSize, prefix.Size())
+
+			var a DevAddr
+			binary.BigEndian.PutUint32(a[:], binary.BigEndian.Uint32(prefix.DevAddr[:])|uint32(prefix.Size()-1))
+			assert.True(prefix.Contains(a))
+			assert.True(a.IsNetID(tst.NetID))
+			assert.Equal(prefix, a.AddrPrefix())
+
+			a[0] ^= 0x80
+			assert.False(prefix.Contains(a))
+		})
+	}
+}
+
+func TestNewRandomDevAddr(t *testing.T) {
+	netIDs := []NetID{
+		{0x00, 0x00, 0x3f},
+		{0x20, 0x00, 0x3f},
+		{0x40, 0x01, 0xff},
+		{0x60, 0x07, 0xff},
+		{0x80, 0x0f, 0xff},
+		{0xa0, 0x1f, 0xff},
+		{0xc0, 0x7f, 0xff},
+		{0xe1, 0xff, 0xff},
+	}
+
+	for _, netID := range netIDs {
+		t.Run(netID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			seen := make(map[DevAddr]struct{})
+			for i := 0; i < 100; i++ {
+				a, err := NewRandomDevAddr(netID)
+				assert.NoError(err)
+				assert.True(a.IsNetID(netID))
+				assert.Equal(netID.Type(), a.NetIDType())
+				assert.Equal(netID.DevAddrPrefix(), a.AddrPrefix())
+				seen[a] = struct{}{}
+			}
+
+			// the NwkAddr bits must be random
+			assert.Greater(len(seen), 1)
+		})
+	}
+
+	t.Run("full prefix", func(t *testing.T) {
+		assert := require.New(t)
+
+		prefix := DevAddrPrefix{DevAddr: DevAddr{1, 2, 3, 4}, Length: 32}
+		a, err := prefix.RandomDevAddr()
+		assert.NoError(err)
+		assert.Equal(DevAddr{1, 2, 3, 4}, a)
+	})
+}
+
+func TestDevAddrStats(t *testing.T) {
+	assert := require.New(t)
+
+	netIDA := NetID{0xc0, 0x00, 0x01} // 1024 DevAddrs
+	netIDB := NetID{0xe0, 0x00, 0x01} // 128 DevAddrs
+
+	// newDevAddr returns the n-th DevAddr of the given NetID
+	newDevAddr := func(netID NetID, n uint32) DevAddr {
+		var a DevAddr
+		binary.BigEndian.PutUint32(a[:], n)
+		a.SetAddrPrefix(netID)
+		return a
+	}
+
+	stats := NewDevAddrStats(0.9)
+	for i := uint32(0); i < 256; i++ {
+		stats.Add(newDevAddr(netIDA, i))
+	}
+	for i := uint32(0); i < 120; i++ {
+		// every DevAddr is observed twice
+		stats.Add(newDevAddr(netIDB, i), newDevAddr(netIDB, i))
+	}
+
+	assert.Equal(DevAddrUtilization{
+		Prefix:   netIDA.DevAddrPrefix(),
+		Used:     256,
+		Capacity: 1024,
+		Ratio:    0.25,
+	}, stats.NetIDUtilization(netIDA))
+
+	assert.Equal(DevAddrUtilization{
+		Prefix:         netIDB.DevAddrPrefix(),
+		Used:           120,
+		Capacity:       128,
+		Ratio:          0.9375,
+		ExhaustionRisk: true,
+	}, stats.NetIDUtilization(netIDB))
+
+	assert.Equal([]DevAddrUtilization{
+		stats.NetIDUtilization(netIDB),
+		stats.NetIDUtilization(netIDA),
+	}, stats.PrefixUtilizations())
+
+	stats.Remove(newDevAddr(netIDB, 0), newDevAddr(netIDB, 1))
+	assert.Equal(uint64(118), stats.NetIDUtilization(netIDB).Used)
+}
@@ -0,0 +1,216 @@
+package lorawan
+
+import (
+	"errors"
+	"sync"
+)
+
+// Downlink queue errors.
+var (
+	ErrDownlinkQueueEmpty = errors.New("lorawan: downlink queue is empty")
+	ErrDownlinkPendingAck = errors.New("lorawan: confirmed downlink is pending acknowledgement")
+)
+
+// DownlinkQueueItem represents an application downlink pending transmission.
+type DownlinkQueueItem struct {
+	FPort      uint8  `json:"fPort"`
+	FRMPayload []byte `json:"frmPayload"`
+	Confirmed  bool   `json:"confirmed"`
+
+	// FCnt holds the downlink frame-counter used for the transmission. It is
+	// set once a confirmed item has been sent and is pending acknowledgement.
+	FCnt uint32 `json:"fCnt"`
+}
+
+// MType returns the MType to use for the transmission of the item.
+func (i DownlinkQueueItem) MType() MType {
+	if i.Confirmed {
+		return ConfirmedDataDown
+	}
+	return UnconfirmedDataDown
+}
+
+// deviceDownlinkQueue holds the FIFO queue and the confirmed item pending
+// acknowledgement of a single device.
+type deviceDownlinkQueue struct {
+	items   []DownlinkQueueItem
+	pending *DownlinkQueueItem
+}
+
+// DownlinkQueue implements a FIFO downlink queue per device. It computes the
+// FPending bit for outgoing frames and guarantees that there is only one
+// confirmed downlink outstanding per device: while a confirmed downlink is
+// pending acknowledgement, no other downlinks are returned. It is safe for
+// concurrent use.
+type DownlinkQueue struct {
+	mu      sync.Mutex
+	devices map[EUI64]*deviceDownlinkQueue
+}
+
+// NewDownlinkQueue returns a new DownlinkQueue.
+func NewDownlinkQueue() *DownlinkQueue {
+	return &DownlinkQueue{
+		devices: make(map[EUI64]*deviceDownlinkQueue),
+	}
+}
+
+// Enqueue adds the given item to the end of the queue of the device.
+func (q *DownlinkQueue) Enqueue(devEUI EUI64, item DownlinkQueueItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	d := q.device(devEUI)
+	d.items = append(d.items, item)
+}
+
+// Len returns the number of items in the queue of the device, excluding
+// the item pending acknowledgement.
+func (q *DownlinkQueue) Len(devEUI EUI64) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if d, ok := q.devices[devEUI]; ok {
+		return len(d.items)
+	}
+	return 0
+}
+
+// Pending returns the confirmed item pending acknowledgement (if any).
+func (q *DownlinkQueue) Pending(devEUI EUI64) (DownlinkQueueItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if d, ok := q.devices[devEUI]; ok && d.pending != nil {
+		return *d.pending, true
+	}
+	return DownlinkQueueItem{}, false
+}
+
+// Next removes and returns the next item of the queue of the device, given
+// the downlink frame-counter that will be used for its transmission. The
+// returned bool is the FPending value, which is true when more items are
+// queued. A confirmed item is kept as pending until Ack or Nack is called.
+// ErrDownlinkPendingAck is returned when a confirmed item is still pending
+// and ErrDownlinkQueueEmpty when the queue is empty.
+func (q *DownlinkQueue) Next(devEUI EUI64, fCnt uint32) (DownlinkQueueItem, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	d, ok := q.devices[devEUI]
+	if !ok {
+		return DownlinkQueueItem{}, false, ErrDownlinkQueueEmpty
+	}
+	if d.pending != nil {
+		return DownlinkQueueItem{}, false, ErrDownlinkPendingAck
+	}
+	if len(d.items) == 0 {
+		return DownlinkQueueItem{}, false, ErrDownlinkQueueEmpty
+	}
+
+	item := d.items[0]
+	d.items = d.items[1:]
+	item.FCnt = fCnt
+
+	if item.Confirmed {
+		pending := item
+		d.pending = &pending
+	}
+
+	fPending := len(d.items) != 0
+	q.cleanup(devEUI, d)
+
+	return item, fPending, nil
+}
+
+// NextPHYPayload returns the PHYPayload for the next item of the queue of
+// the device. The given FHDR is used as base, of which the FCnt is used for
+// the transmission and the FPending bit is set when more items are queued.
+// The FRMPayload must still be encrypted and the MIC must still be set by
+// the caller. See Next for the returned errors.
+func (q *DownlinkQueue) NextPHYPayload(devEUI EUI64, fhdr FHDR) (PHYPayload, error) {
+	item, fPending, err := q.Next(devEUI, fhdr.FCnt)
+	if err != nil {
+		return PHYPayload{}, err
+	}
+
+	fhdr.FCtrl.FPending = fPending
+	fPort := item.FPort
+
+	return PHYPayload{
+		MHDR: MHDR{
+			MType: item.MType(),
+			Major: LoRaWANR1,
+		},
+		MACPayload: &MACPayload{
+			FHDR:       fhdr,
+			FPort:      &fPort,
+			FRMPayload: []Payload{&DataPayload{Bytes: item.FRMPayload}},
+		},
+	}, nil
+}
+
+// Ack handles the acknowledgement (ACK bit of the uplink) of the pending
+// confirmed item. It returns the acknowledged item. The returned bool is
+// false when there was no pending item.
+func (q *DownlinkQueue) Ack(devEUI EUI64) (DownlinkQueueItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	d, ok := q.devices[devEUI]
+	if !ok || d.pending == nil {
+		return DownlinkQueueItem{}, false
+	}
+
+	item := *d.pending
+	d.pending = nil
+	q.cleanup(devEUI, d)
+
+	return item, true
+}
+
+// Nack handles a missing acknowledgement (e.g. on timeout) of the pending
+// confirmed item. When retry is set, the item is put back at the head of
+// the queue, else it is discarded. It returns the pending item. The
+// returned bool is false when there was no pending item.
+func (q *DownlinkQueue) Nack(devEUI EUI64, retry bool) (DownlinkQueueItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	d, ok := q.devices[devEUI]
+	if !ok || d.pending == nil {
+		return DownlinkQueueItem{}, false
+	}
+
+	item := *d.pending
+	d.pending = nil
+
+	if retry {
+		d.items = append([]DownlinkQueueItem{item}, d.items...)
+	}
+	q.cleanup(devEUI, d)
+
+	return item, true
+}
+
+// Flush removes all items (including the pending item) of the device.
+func (q *DownlinkQueue) Flush(devEUI EUI64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.devices, devEUI)
+}
+
+func (q *DownlinkQueue) device(devEUI EUI64) *deviceDownlinkQueue {
+	d, ok := q.devices[devEUI]
+	if !ok {
+		d = &deviceDownlinkQueue{}
+		q.devices[devEUI] = d
+	}
+	return d
+}
+
+func (q *DownlinkQueue) cleanup(devEUI EUI64, d *deviceDownlinkQueue) {
+	if len(d.items) == 0 && d.pending == nil {
+		delete(q.devices, devEUI)
+	}
+}
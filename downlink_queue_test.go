@@ -0,0 +1,123 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownlinkQueue(t *testing.T) {
+	devEUI := EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+
+	t.Run("FIFO and FPending", func(t *testing.T) {
+		assert := require.New(t)
+		q := NewDownlinkQueue()
+
+		_, _, err := q.Next(devEUI, 0)
+		assert.Equal(ErrDownlinkQueueEmpty, err)
+
+		q.Enqueue(devEUI, DownlinkQueueItem{FPort: 1, FRMPayload: []byte{1}})
+		q.Enqueue(devEUI, DownlinkQueueItem{FPort: 2, FRMPayload: []byte{2}})
+		assert.Equal(2, q.Len(devEUI))
+
+		item, fPending, err := q.Next(devEUI, 10)
+		assert.NoError(err)
+		assert.True(fPending)
+		assert.Equal(DownlinkQueueItem{FPort: 1, FRMPayload: []byte{1}, FCnt: 10}, item)
+
+		item, fPending, err = q.Next(devEUI, 11)
+		assert.NoError(err)
+		assert.False(fPending)
+		assert.Equal(uint8(2), item.FPort)
+		assert.Equal(0, q.Len(devEUI))
+	})
+
+	t.Run("Single confirmed outstanding", func(t *testing.T) {
+		assert := require.New(t)
+		q := NewDownlinkQueue()
+
+		q.Enqueue(devEUI, DownlinkQueueItem{FPort: 1, Confirmed: true})
+		q.Enqueue(devEUI, DownlinkQueueItem{FPort: 2})
+
+		item, fPending, err := q.Next(devEUI, 5)
+		assert.NoError(err)
+		assert.True(fPending)
+		assert.Equal(ConfirmedDataDown, item.MType())
+
+		pending, ok := q.Pending(devEUI)
+		assert.True(ok)
+		assert.Equal(uint32(5), pending.FCnt)
+
+		_, _, err = q.Next(devEUI, 6)
+		assert.Equal(ErrDownlinkPendingAck, err)
+
+		t.Run("Nack with retry", func(t *testing.T) {
+			assert := require.New(t)
+
+			item, ok := q.Nack(devEUI, true)
+			assert.True(ok)
+			assert.Equal(uint8(1), item.FPort)
+			assert.Equal(2, q.Len(devEUI))
+
+			item, _, err := q.Next(devEUI, 6)
+			assert.NoError(err)
+			assert.Equal(uint8(1), item.FPort)
+			assert.Equal(uint32(6), item.FCnt)
+		})
+
+		t.Run("Ack", func(t *testing.T) {
+			assert := require.New(t)
+
+			item, ok := q.Ack(devEUI)
+			assert.True(ok)
+			assert.Equal(uint32(6), item.FCnt)
+
+			_, ok = q.Ack(devEUI)
+			assert.False(ok)
+
+			item, fPending, err := q.Next(devEUI, 7)
+			assert.NoError(err)
+			assert.False(fPending)
+			assert.Equal(UnconfirmedDataDown, item.MType())
+		})
+	})
+
+	t.Run("NextPHYPayload", func(t *testing.T) {
+		assert := require.New(t)
+		q := NewDownlinkQueue()
+
+		q.Enqueue(devEUI, DownlinkQueueItem{FPort: 10, FRMPayload: []byte{1, 2, 3}, Confirmed: true})
+		q.Enqueue(devEUI, DownlinkQueueItem{FPort: 20})
+
+		fPort := uint8(10)
+		phy, err := q.NextPHYPayload(devEUI, FHDR{DevAddr: DevAddr{1, 2, 3, 4}, FCnt: 3})
+		assert.NoError(err)
+		assert.Equal(PHYPayload{
+			MHDR: MHDR{MType: ConfirmedDataDown, Major: LoRaWANR1},
+			MACPayload: &MACPayload{
+				FHDR: FHDR{
+					DevAddr: DevAddr{1, 2, 3, 4},
+					FCtrl:   FCtrl{FPending: true},
+					FCnt:    3,
+				},
+				FPort:      &fPort,
+				FRMPayload: []Payload{&DataPayload{Bytes: []byte{1, 2, 3}}},
+			},
+		}, phy)
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		assert := require.New(t)
+		q := NewDownlinkQueue()
+
+		q.Enqueue(devEUI, DownlinkQueueItem{Confirmed: true})
+		q.Enqueue(devEUI, DownlinkQueueItem{})
+		_, _, err := q.Next(devEUI, 0)
+		assert.NoError(err)
+
+		q.Flush(devEUI)
+		assert.Equal(0, q.Len(devEUI))
+		_, ok := q.Pending(devEUI)
+		assert.False(ok)
+	})
+}
@@ -0,0 +1,59 @@
+package lorawan
+
+import "errors"
+
+// EUI validation errors.
+var (
+	ErrEUIZero      = errors.New("lorawan: EUI must not be 0")
+	ErrEUIBroadcast = errors.New("lorawan: EUI must not be the broadcast address (FFFFFFFFFFFFFFFF)")
+	ErrEUIMulticast = errors.New("lorawan: EUI must not have the multicast (group) bit set")
+)
+
+// IsZero returns true when all bytes of the EUI are 0.
+func (e EUI64) IsZero() bool {
+	return e == EUI64{}
+}
+
+// IsBroadcast returns true when all bytes of the EUI are 0xff.
+func (e EUI64) IsBroadcast() bool {
+	return e == EUI64{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+}
+
+// IsMulticast returns true when the individual / group bit (the least
+// significant bit of the first byte) of the EUI is set. Such EUIs are
+// reserved for group addresses and must not be assigned to a single device
+// or join-server.
+func (e EUI64) IsMulticast() bool {
+	return e[0]&0x01 != 0
+}
+
+// ValidateDevEUI validates that the given DevEUI can be used to identify a
+// single device (e.g. on join). It returns ErrEUIZero, ErrEUIBroadcast or
+// ErrEUIMulticast when the DevEUI is reserved or invalid.
+func ValidateDevEUI(devEUI EUI64) error {
+	if devEUI.IsZero() {
+		return ErrEUIZero
+	}
+	return validateUnicastEUI(devEUI)
+}
+
+// ValidateJoinEUI validates the given JoinEUI. Unlike the DevEUI, a JoinEUI
+// of 0 is permitted as it is commonly used by devices that are not bound to
+// a specific join-server. It returns ErrEUIBroadcast or ErrEUIMulticast when
+// the JoinEUI is reserved.
+func ValidateJoinEUI(joinEUI EUI64) error {
+	if joinEUI.IsZero() {
+		return nil
+	}
+	return validateUnicastEUI(joinEUI)
+}
+
+func validateUnicastEUI(e EUI64) error {
+	if e.IsBroadcast() {
+		return ErrEUIBroadcast
+	}
+	if e.IsMulticast() {
+		return ErrEUIMulticast
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package lorawan
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEUI(t *testing.T) {
+	tests := []struct {
+		EUI          EUI64
+		DevEUIError  error
+		JoinEUIError error
+	}{
+		{EUI64{}, ErrEUIZero, nil},
+		{EUI64{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}, ErrEUIMulticast, ErrEUIMulticast},
+		{EUI64{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, ErrEUIBroadcast, ErrEUIBroadcast},
+		{EUI64{0x70, 0xb3, 0xd5, 0x7e, 0xd0, 0x00, 0x00, 0x01}, nil, nil},
+		{EUI64{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, nil, nil},
+	}
+
+	for i, tst := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.DevEUIError, ValidateDevEUI(tst.EUI))
+			assert.Equal(tst.JoinEUIError, ValidateJoinEUI(tst.EUI))
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package lorawan
+
+// MACCommandVectors exports the mac-command test-vectors to the external test
+// package, as the testvectors package can not be imported by the internal
+// tests (import cycle).
+var MACCommandVectors = macCommandVectors
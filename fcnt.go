@@ -0,0 +1,50 @@
+package lorawan
+
+import "errors"
+
+// MaxFCntGap defines the default max. gap between the expected and the
+// received frame-counter (MAX_FCNT_GAP as defined by LoRaWAN 1.0).
+const MaxFCntGap = 16384
+
+// ErrFCntOverflow is returned when the 32 bit frame-counter can not be
+// incremented. A new session (e.g. by a re-join) must be established before
+// more frames can be sent.
+var ErrFCntOverflow = errors.New("lorawan: frame-counter overflow, a new session must be established")
+
+// ResolveFCnt returns the full 32 bit frame-counter, given the next expected
+// (full) frame-counter (e.g. Session.FCntUp) and the 16 least-significant
+// bits of the received frame-counter. The 16 bit roll-over is handled by
+// selecting the first value greater than or equal to expectedFCnt.
+//
+// It returns false when the gap between the expected and the resolved
+// frame-counter exceeds maxGap (e.g. MaxFCntGap), which also covers the
+// re-transmission and replay of an older frame, or when the resolved
+// frame-counter would exceed the 32 bit range. Note that the MIC must be
+// validated using the resolved frame-counter before accepting it.
+func ResolveFCnt(expectedFCnt uint32, received uint16, maxGap uint32) (uint32, bool) {
+	full := (expectedFCnt &^ 0xffff) | uint32(received)
+	if full < expectedFCnt {
+		full += 1 << 16
+
+		// 32 bit roll-over
+		if full < expectedFCnt {
+			return 0, false
+		}
+	}
+
+	if full-expectedFCnt > maxGap {
+		return 0, false
+	}
+
+	return full, true
+}
+
+// NextFCnt returns the frame-counter following the given frame-counter.
+// ErrFCntOverflow is returned when the frame-counter equals the max. 32 bit
+// value.
+func NextFCnt(fCnt uint32) (uint32, error) {
+	if fCnt == 1<<32-1 {
+		return 0, ErrFCntOverflow
+	}
+	return fCnt + 1, nil
+}
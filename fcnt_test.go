@@ -0,0 +1,102 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveFCnt(t *testing.T) {
+	tests := []struct {
+		Name         string
+		ExpectedFCnt uint32
+		Received     uint16
+		MaxGap       uint32
+		Expected     uint32
+		ExpectedOK   bool
+	}{
+		{
+			Name:       "first frame",
+			Received:   0,
+			MaxGap:     MaxFCntGap,
+			Expected:   0,
+			ExpectedOK: true,
+		},
+		{
+			Name:         "expected frame-counter",
+			ExpectedFCnt: 10,
+			Received:     10,
+			MaxGap:       MaxFCntGap,
+			Expected:     10,
+			ExpectedOK:   true,
+		},
+		{
+			Name:         "gap within max gap",
+			ExpectedFCnt: 10,
+			Received:     20,
+			MaxGap:       MaxFCntGap,
+			Expected:     20,
+			ExpectedOK:   true,
+		},
+		{
+			Name:         "16 bit roll-over",
+			ExpectedFCnt: 0x1fffe,
+			Received:     0x0002,
+			MaxGap:       MaxFCntGap,
+			Expected:     0x20002,
+			ExpectedOK:   true,
+		},
+		{
+			Name:         "upper bits retained",
+			ExpectedFCnt: 0x30005,
+			Received:     0x0006,
+			MaxGap:       MaxFCntGap,
+			Expected:     0x30006,
+			ExpectedOK:   true,
+		},
+		{
+			Name:         "gap exceeds max gap",
+			ExpectedFCnt: 10,
+			Received:     10 + MaxFCntGap + 1,
+			MaxGap:       MaxFCntGap,
+		},
+		{
+			Name:         "replay",
+			ExpectedFCnt: 10,
+			Received:     9,
+			MaxGap:       MaxFCntGap,
+		},
+		{
+			Name:         "replay without max gap",
+			ExpectedFCnt: 0xffff0010,
+			Received:     9,
+			MaxGap:       1<<32 - 1,
+		},
+		{
+			Name:         "max gap 0",
+			ExpectedFCnt: 10,
+			Received:     11,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			fCnt, ok := ResolveFCnt(tst.ExpectedFCnt, tst.Received, tst.MaxGap)
+			assert.Equal(tst.ExpectedOK, ok)
+			assert.Equal(tst.Expected, fCnt)
+		})
+	}
+}
+
+func TestNextFCnt(t *testing.T) {
+	assert := require.New(t)
+
+	fCnt, err := NextFCnt(0xffff)
+	assert.NoError(err)
+	assert.Equal(uint32(0x10000), fCnt)
+
+	_, err = NextFCnt(1<<32 - 1)
+	assert.Equal(ErrFCntOverflow, err)
+}
@@ -1,7 +1,6 @@
 package lorawan
 
 import (
-	"database/sql/driver"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
@@ -25,48 +24,19 @@ func (a DevAddr) NetIDType() int {
 
 // NwkID returns the NwkID bits of the DevAddr.
 func (a DevAddr) NwkID() []byte {
-	switch a.NetIDType() {
-	case 0:
-		return a.getNwkID(1, 6)
-	case 1:
-		return a.getNwkID(2, 6)
-	case 2:
-		return a.getNwkID(3, 9)
-	case 3:
-		return a.getNwkID(4, 11)
-	case 4:
-		return a.getNwkID(5, 12)
-	case 5:
-		return a.getNwkID(6, 13)
-	case 6:
-		return a.getNwkID(7, 15)
-	case 7:
-		return a.getNwkID(8, 17)
-	default:
+	t := a.NetIDType()
+	if t == -1 {
 		return nil
 	}
+
+	bits := devAddrPrefixBits[t]
+	return a.getNwkID(bits[0], bits[1])
 }
 
 // SetAddrPrefix sets the NetID based AddrPrefix.
 func (a *DevAddr) SetAddrPrefix(netID NetID) {
-	switch netID.Type() {
-	case 0:
-		a.setAddrPrefix(1, 6, netID)
-	case 1:
-		a.setAddrPrefix(2, 6, netID)
-	case 2:
-		a.setAddrPrefix(3, 9, netID)
-	case 3:
-		a.setAddrPrefix(4, 11, netID)
-	case 4:
-		a.setAddrPrefix(5, 12, netID)
-	case 5:
-		a.setAddrPrefix(6, 13, netID)
-	case 6:
-		a.setAddrPrefix(7, 15, netID)
-	case 7:
-		a.setAddrPrefix(8, 17, netID)
-	}
+	bits := devAddrPrefixBits[netID.Type()]
+	a.setAddrPrefix(bits[0], bits[1], netID)
 }
 
 // IsNetID returns a bool indicating if the NwkID matches the given NetID.
@@ -81,6 +51,12 @@ func (a DevAddr) IsNetID(netID NetID) bool {
 	return false
 }
 
+// NewRandomDevAddr returns a random DevAddr within the address space
+// (AddrPrefix) of the given NetID, e.g. to assign a DevAddr on OTAA.
+func NewRandomDevAddr(netID NetID) (DevAddr, error) {
+	return netID.DevAddrPrefix().RandomDevAddr()
+}
+
 func (a *DevAddr) setAddrPrefix(prefixLength, nwkIDBits int, netID NetID) {
 	// convert DevAddr to uint32
 	devAddr := binary.BigEndian.Uint32(a[:])
@@ -175,24 +151,6 @@ func (a DevAddr) String() string {
 	return hex.EncodeToString(a[:])
 }
 
-// Scan implements sql.Scanner.
-func (a *DevAddr) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return errors.New("lorawan: []byte type expected")
-	}
-	if len(b) != len(a) {
-		return fmt.Errorf("lorawan []byte must have length %d", len(a))
-	}
-	copy(a[:], b)
-	return nil
-}
-
-// Value implements driver.Valuer.
-func (a DevAddr) Value() (driver.Value, error) {
-	return a[:], nil
-}
-
 // FCtrl represents the FCtrl (frame control) field.
 // Please note that the FPending and ClassB are mapped to the same bit. This
 // means that when unmarshaling from a byte-slice, both fields will contain
@@ -212,6 +170,11 @@ func (c FCtrl) MarshalBinary() ([]byte, error) {
 		return []byte{}, errors.New("lorawan: max value of FOptsLen is 15")
 	}
 
+	return []byte{c.byte()}, nil
+}
+
+// byte returns the FCtrl as byte. It does not validate the fOptsLen.
+func (c FCtrl) byte() byte {
 	var b byte
 	if c.ADR {
 		b |= 0x80
@@ -227,7 +190,7 @@ func (c FCtrl) MarshalBinary() ([]byte, error) {
 	}
 	b |= byte(c.fOptsLen) & 0x0f
 
-	return []byte{b}, nil
+	return b
 }
 
 // UnmarshalBinary decodes the object from binary form.
@@ -256,44 +219,52 @@ type FHDR struct {
 
 // MarshalBinary marshals the object in binary form.
 func (h FHDR) MarshalBinary() ([]byte, error) {
-	var b []byte
-	var err error
-	var opts []byte
-
-	for _, mac := range h.FOpts {
-		b, err = mac.MarshalBinary()
-		if err != nil {
-			return []byte{}, err
-		}
-		opts = append(opts, b...)
+	b, err := h.appendBinary(make([]byte, 0, 7+15))
+	if err != nil {
+		return []byte{}, err
 	}
-	h.FCtrl.fOptsLen = uint8(len(opts))
-	if h.FCtrl.fOptsLen > 15 {
-		return []byte{}, errors.New("lorawan: max number of FOpts bytes is 15")
+	return b, nil
+}
+
+// appendBinary appends the object in binary form to b.
+func (h FHDR) appendBinary(b []byte) ([]byte, error) {
+	start := len(b)
+
+	// DevAddr (little endian)
+	for i := len(h.DevAddr) - 1; i >= 0; i-- {
+		b = append(b, h.DevAddr[i])
 	}
 
-	out := make([]byte, 0, 7+h.FCtrl.fOptsLen)
-	b, err = h.DevAddr.MarshalBinary()
-	if err != nil {
-		return []byte{}, err
+	// FCtrl is set after the FOpts have been appended, as it contains the
+	// FOptsLen
+	fCtrlPos := len(b)
+	b = append(b, 0, byte(h.FCnt), byte(h.FCnt>>8))
+
+	var err error
+	for _, mac := range h.FOpts {
+		if b, err = appendPayload(b, mac); err != nil {
+			return b[:start], err
+		}
 	}
-	out = append(out, b...)
 
-	b, err = h.FCtrl.MarshalBinary()
-	if err != nil {
-		return []byte{}, err
+	fOptsLen := len(b) - fCtrlPos - 3
+	if fOptsLen > 15 {
+		return b[:start], errors.New("lorawan: max number of FOpts bytes is 15")
 	}
-	out = append(out, b...)
-	fCntBytes := make([]byte, 4)
-	binary.LittleEndian.PutUint32(fCntBytes, h.FCnt)
-	out = append(out, fCntBytes[0:2]...)
-	out = append(out, opts...)
+	h.FCtrl.fOptsLen = uint8(fOptsLen)
+	b[fCtrlPos] = h.FCtrl.byte()
 
-	return out, nil
+	return b, nil
 }
 
 // UnmarshalBinary decodes the object from binary form.
 func (h *FHDR) UnmarshalBinary(uplink bool, data []byte) error {
+	return h.unmarshalBinary(uplink, data, false)
+}
+
+// unmarshalBinary decodes the object from binary form. When reuse is set,
+// the DataPayload of the current FOpts (if any) is re-used.
+func (h *FHDR) unmarshalBinary(uplink bool, data []byte, reuse bool) error {
 	if len(data) < 7 {
 		return errors.New("lorawan: at least 7 bytes are expected")
 	}
@@ -304,14 +275,12 @@ func (h *FHDR) UnmarshalBinary(uplink bool, data []byte) error {
 	if err := h.FCtrl.UnmarshalBinary(data[4:5]); err != nil {
 		return err
 	}
-	fCntBytes := make([]byte, 4)
-	copy(fCntBytes, data[5:7])
-	h.FCnt = binary.LittleEndian.Uint32(fCntBytes)
+	h.FCnt = uint32(binary.LittleEndian.Uint16(data[5:7]))
 
 	if len(data) > 7 {
-		h.FOpts = []Payload{
-			&DataPayload{Bytes: data[7:]},
-		}
+		h.FOpts = getDataPayloads(h.FOpts, data[7:], reuse)
+	} else if reuse {
+		h.FOpts = nil
 	}
 
 	return nil
@@ -305,7 +305,7 @@ func TestFHDR(t *testing.T) {
 			Convey("Then UnmarshalBinary does not return an error", func() {
 				err := h.UnmarshalBinary(false, b)
 				So(err, ShouldBeNil)
-				h.FOpts, err = decodeDataPayloadToMACCommands(false, h.FOpts)
+				h.FOpts, err = decodeDataPayloadToMACCommands(false, h.FOpts, DecodeOptions{})
 				So(err, ShouldBeNil)
 
 				Convey("Then DevAddr=[4]{1, 2, 3, 4}", func() {
@@ -339,7 +339,7 @@ func TestFHDR(t *testing.T) {
 			Convey("Then UnmarshalBinary does not return an error", func() {
 				err := h.UnmarshalBinary(false, b)
 				So(err, ShouldBeNil)
-				h.FOpts, err = decodeDataPayloadToMACCommands(false, h.FOpts)
+				h.FOpts, err = decodeDataPayloadToMACCommands(false, h.FOpts, DecodeOptions{})
 				So(err, ShouldBeNil)
 
 				Convey("Then DevAddr=[4]{1, 2, 3, 4}", func() {
@@ -376,7 +376,7 @@ func TestFHDR(t *testing.T) {
 			Convey("Then UnmarshalBinary returns an error", func() {
 				err := h.UnmarshalBinary(false, b)
 				So(err, ShouldBeNil)
-				h.FOpts, err = decodeDataPayloadToMACCommands(false, h.FOpts)
+				h.FOpts, err = decodeDataPayloadToMACCommands(false, h.FOpts, DecodeOptions{})
 				So(err, ShouldResemble, errors.New("lorawan: not enough remaining bytes"))
 			})
 		})
@@ -398,7 +398,7 @@ func TestFHDR(t *testing.T) {
 				Convey("Then it can be converted back to the original payload", func() {
 					actual := FHDR{}
 					So(actual.UnmarshalBinary(false, b), ShouldBeNil)
-					actual.FOpts, err = decodeDataPayloadToMACCommands(false, actual.FOpts)
+					actual.FOpts, err = decodeDataPayloadToMACCommands(false, actual.FOpts, DecodeOptions{})
 					So(err, ShouldBeNil)
 					So(actual.FOpts, ShouldResemble, []Payload{&m})
 				})
@@ -0,0 +1,190 @@
+package lorawan
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Direction defines the direction of a frame.
+type Direction int
+
+// Available directions.
+const (
+	DirectionUplink Direction = iota
+	DirectionDownlink
+)
+
+// String implements fmt.Stringer.
+func (d Direction) String() string {
+	switch d {
+	case DirectionUplink:
+		return "Uplink"
+	case DirectionDownlink:
+		return "Downlink"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+// DevIdentifierType defines the type of device identifier.
+type DevIdentifierType int
+
+// Available device identifier types.
+const (
+	DevIdentifierDevAddr DevIdentifierType = iota
+	DevIdentifierDevEUI
+)
+
+// DevIdentifier identifies the device of a frame. Data frames are identified
+// by the DevAddr, join and rejoin-request frames by the DevEUI. The
+// DevIdentifier is comparable, so that it can be used as map key.
+type DevIdentifier struct {
+	Type    DevIdentifierType
+	DevAddr DevAddr
+	DevEUI  EUI64
+}
+
+// String implements fmt.Stringer.
+func (d DevIdentifier) String() string {
+	if d.Type == DevIdentifierDevEUI {
+		return "DevEUI " + d.DevEUI.String()
+	}
+	return "DevAddr " + d.DevAddr.String()
+}
+
+// Frame defines the interface implemented by the data, join-request and
+// rejoin-request frames, so that e.g. routing, de-duplication and metrics
+// can be implemented without a type switch on the MACPayload.
+type Frame interface {
+	// Direction returns the direction of the frame.
+	Direction() Direction
+
+	// DevIdentifier returns the identifier of the device.
+	DevIdentifier() DevIdentifier
+
+	// FCnt returns the (transmitted) frame-counter of the frame. For
+	// rejoin-requests, this is the RJcount0 or RJcount1. It returns false
+	// when the frame does not have a frame-counter.
+	FCnt() (uint32, bool)
+
+	// MarshalBinary marshals the frame in binary form.
+	MarshalBinary() ([]byte, error)
+}
+
+// DataFrame implements Frame for the (un)confirmed data up and down frames.
+type DataFrame struct {
+	PHYPayload
+}
+
+// Direction returns the direction of the frame.
+func (f DataFrame) Direction() Direction {
+	return frameDirection(f.PHYPayload)
+}
+
+// DevIdentifier returns the DevAddr of the frame.
+func (f DataFrame) DevIdentifier() DevIdentifier {
+	out := DevIdentifier{Type: DevIdentifierDevAddr}
+	if pl, ok := f.MACPayload.(*MACPayload); ok {
+		out.DevAddr = pl.FHDR.DevAddr
+	}
+	return out
+}
+
+// FCnt returns the FCnt of the frame.
+func (f DataFrame) FCnt() (uint32, bool) {
+	if pl, ok := f.MACPayload.(*MACPayload); ok {
+		return pl.FHDR.FCnt, true
+	}
+	return 0, false
+}
+
+// JoinRequestFrame implements Frame for the join-request frame.
+type JoinRequestFrame struct {
+	PHYPayload
+}
+
+// Direction returns the direction of the frame.
+func (f JoinRequestFrame) Direction() Direction {
+	return DirectionUplink
+}
+
+// DevIdentifier returns the DevEUI of the frame.
+func (f JoinRequestFrame) DevIdentifier() DevIdentifier {
+	out := DevIdentifier{Type: DevIdentifierDevEUI}
+	if pl, ok := f.MACPayload.(*JoinRequestPayload); ok {
+		out.DevEUI = pl.DevEUI
+	}
+	return out
+}
+
+// FCnt always returns false, as the join-request does not have a
+// frame-counter.
+func (f JoinRequestFrame) FCnt() (uint32, bool) {
+	return 0, false
+}
+
+// RejoinRequestFrame implements Frame for the rejoin-request frames.
+type RejoinRequestFrame struct {
+	PHYPayload
+}
+
+// Direction returns the direction of the frame.
+func (f RejoinRequestFrame) Direction() Direction {
+	return DirectionUplink
+}
+
+// DevIdentifier returns the DevEUI of the frame.
+func (f RejoinRequestFrame) DevIdentifier() DevIdentifier {
+	out := DevIdentifier{Type: DevIdentifierDevEUI}
+	switch pl := f.MACPayload.(type) {
+	case *RejoinRequestType02Payload:
+		out.DevEUI = pl.DevEUI
+	case *RejoinRequestType1Payload:
+		out.DevEUI = pl.DevEUI
+	}
+	return out
+}
+
+// FCnt returns the RJcount0 (type 0 and 2) or RJcount1 (type 1) of the frame.
+func (f RejoinRequestFrame) FCnt() (uint32, bool) {
+	switch pl := f.MACPayload.(type) {
+	case *RejoinRequestType02Payload:
+		return uint32(pl.RJCount0), true
+	case *RejoinRequestType1Payload:
+		return uint32(pl.RJCount1), true
+	}
+	return 0, false
+}
+
+// Frame returns the PHYPayload as Frame. An error is returned for the
+// join-accept and proprietary MTypes and when the MACPayload does not match
+// the MType.
+func (p PHYPayload) Frame() (Frame, error) {
+	switch p.MHDR.MType {
+	case UnconfirmedDataUp, UnconfirmedDataDown, ConfirmedDataUp, ConfirmedDataDown:
+		if _, ok := p.MACPayload.(*MACPayload); !ok {
+			return nil, errors.New("lorawan: MACPayload must be of type *MACPayload")
+		}
+		return DataFrame{p}, nil
+	case JoinRequest:
+		if _, ok := p.MACPayload.(*JoinRequestPayload); !ok {
+			return nil, errors.New("lorawan: MACPayload must be of type *JoinRequestPayload")
+		}
+		return JoinRequestFrame{p}, nil
+	case RejoinRequest:
+		switch p.MACPayload.(type) {
+		case *RejoinRequestType02Payload, *RejoinRequestType1Payload:
+			return RejoinRequestFrame{p}, nil
+		}
+		return nil, errors.New("lorawan: MACPayload must be of type *RejoinRequestType02Payload or *RejoinRequestType1Payload")
+	default:
+		return nil, fmt.Errorf("lorawan: MType %s does not implement Frame", p.MHDR.MType)
+	}
+}
+
+func frameDirection(p PHYPayload) Direction {
+	if p.isUplink() {
+		return DirectionUplink
+	}
+	return DirectionDownlink
+}
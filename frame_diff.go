@@ -0,0 +1,164 @@
+package lorawan
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// FrameDiff defines a field-level difference between two frames.
+type FrameDiff struct {
+	// Field holds the path of the field, e.g. MHDR.MType or
+	// MACPayload.FHDR.FCtrl.ADR. Elements of the FOpts and FRMPayload are
+	// indexed, e.g. MACPayload.FRMPayload[0].Bytes.
+	Field string `json:"field"`
+
+	// A and B hold the string representation of the field in the first and
+	// second frame. When the field does not exist in one of the frames, the
+	// value is "<nil>". When the types differ (e.g. the MACPayload types),
+	// the type names are used.
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// String implements fmt.Stringer.
+func (d FrameDiff) String() string {
+	return fmt.Sprintf("%s: %s != %s", d.Field, d.A, d.B)
+}
+
+// DiffPHYPayloads returns the field-level differences between the given
+// PHYPayloads, e.g. for regression tests and interop debugging. Note that
+// the (encrypted) FRMPayload and FOpts are compared as-is. It returns nil
+// when both PHYPayloads are equal.
+func DiffPHYPayloads(a, b PHYPayload) []FrameDiff {
+	var out []FrameDiff
+	diffValues(&out, "", reflect.ValueOf(a), reflect.ValueOf(b))
+	return out
+}
+
+// DiffFrames decodes the given (uplink or downlink) frames and returns their
+// field-level differences (see DiffPHYPayloads).
+func DiffFrames(a, b []byte) ([]FrameDiff, error) {
+	var phyA, phyB PHYPayload
+	if err := phyA.UnmarshalBinary(a); err != nil {
+		return nil, fmt.Errorf("lorawan: unmarshal first frame error: %w", err)
+	}
+	if err := phyB.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("lorawan: unmarshal second frame error: %w", err)
+	}
+
+	out := DiffPHYPayloads(phyA, phyB)
+	if len(out) == 0 && len(a) != len(b) {
+		// e.g. trailing bytes which are not covered by the PHYPayload fields
+		out = append(out, FrameDiff{
+			Field: "Length",
+			A:     fmt.Sprint(len(a)),
+			B:     fmt.Sprint(len(b)),
+		})
+	}
+	return out, nil
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+func diffValues(out *[]FrameDiff, path string, a, b reflect.Value) {
+	// resolve interfaces and pointers
+	for (a.IsValid() && (a.Kind() == reflect.Interface || a.Kind() == reflect.Ptr)) || (b.IsValid() && (b.Kind() == reflect.Interface || b.Kind() == reflect.Ptr)) {
+		if a.IsValid() && (a.Kind() == reflect.Interface || a.Kind() == reflect.Ptr) {
+			a = a.Elem()
+		}
+		if b.IsValid() && (b.Kind() == reflect.Interface || b.Kind() == reflect.Ptr) {
+			b = b.Elem()
+		}
+	}
+
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		if !a.IsValid() && !b.IsValid() {
+			return
+		}
+		*out = append(*out, FrameDiff{Field: path, A: formatDiffValue(a), B: formatDiffValue(b)})
+		return
+	}
+
+	if isDiffLeaf(a) {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*out = append(*out, FrameDiff{Field: path, A: formatDiffValue(a), B: formatDiffValue(b)})
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			diffValues(out, joinDiffPath(path, f.Name), a.Field(i), b.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		n := a.Len()
+		if b.Len() > n {
+			n = b.Len()
+		}
+		for i := 0; i < n; i++ {
+			var ai, bi reflect.Value
+			if i < a.Len() {
+				ai = a.Index(i)
+			}
+			if i < b.Len() {
+				bi = b.Index(i)
+			}
+			diffValues(out, fmt.Sprintf("%s[%d]", path, i), ai, bi)
+		}
+	}
+}
+
+// isDiffLeaf returns true when the value must be compared as a whole.
+func isDiffLeaf(v reflect.Value) bool {
+	if v.Type().Implements(stringerType) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.Slice, reflect.Array:
+		return v.Type().Elem().Kind() == reflect.Uint8
+	default:
+		return true
+	}
+}
+
+func formatDiffValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	if v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return hex.EncodeToString(v.Bytes())
+		}
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hex.EncodeToString(b)
+		}
+	case reflect.Struct:
+		// only formatted when the types differ
+		return v.Type().String()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+func joinDiffPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,125 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPHYPayloads(t *testing.T) {
+	fPort := uint8(10)
+	base := func() PHYPayload {
+		return PHYPayload{
+			MHDR: MHDR{MType: UnconfirmedDataUp, Major: LoRaWANR1},
+			MACPayload: &MACPayload{
+				FHDR: FHDR{
+					DevAddr: DevAddr{1, 2, 3, 4},
+					FCtrl:   FCtrl{ADR: true},
+					FCnt:    10,
+				},
+				FPort:      &fPort,
+				FRMPayload: []Payload{&DataPayload{Bytes: []byte{1, 2, 3}}},
+			},
+			MIC: MIC{1, 2, 3, 4},
+		}
+	}
+
+	tests := []struct {
+		Name     string
+		Modify   func(p *PHYPayload)
+		Expected []FrameDiff
+	}{
+		{
+			Name:   "equal",
+			Modify: func(p *PHYPayload) {},
+		},
+		{
+			Name: "MHDR, FCtrl, FCnt and MIC",
+			Modify: func(p *PHYPayload) {
+				p.MHDR.MType = ConfirmedDataUp
+				p.MACPayload.(*MACPayload).FHDR.FCtrl.ADR = false
+				p.MACPayload.(*MACPayload).FHDR.FCnt = 11
+				p.MIC = MIC{4, 3, 2, 1}
+			},
+			Expected: []FrameDiff{
+				{Field: "MHDR.MType", A: "UnconfirmedDataUp", B: "ConfirmedDataUp"},
+				{Field: "MACPayload.FHDR.FCtrl.ADR", A: "true", B: "false"},
+				{Field: "MACPayload.FHDR.FCnt", A: "10", B: "11"},
+				{Field: "MIC", A: "01020304", B: "04030201"},
+			},
+		},
+		{
+			Name: "payload bytes",
+			Modify: func(p *PHYPayload) {
+				p.MACPayload.(*MACPayload).FRMPayload = []Payload{&DataPayload{Bytes: []byte{1, 2, 4}}}
+			},
+			Expected: []FrameDiff{
+				{Field: "MACPayload.FRMPayload[0].Bytes", A: "010203", B: "010204"},
+			},
+		},
+		{
+			Name: "FPort removed",
+			Modify: func(p *PHYPayload) {
+				p.MACPayload.(*MACPayload).FPort = nil
+				p.MACPayload.(*MACPayload).FRMPayload = nil
+			},
+			Expected: []FrameDiff{
+				{Field: "MACPayload.FPort", A: "10", B: "<nil>"},
+				{Field: "MACPayload.FRMPayload[0]", A: "lorawan.DataPayload", B: "<nil>"},
+			},
+		},
+		{
+			Name: "MACPayload type",
+			Modify: func(p *PHYPayload) {
+				p.MHDR.MType = JoinRequest
+				p.MACPayload = &JoinRequestPayload{}
+			},
+			Expected: []FrameDiff{
+				{Field: "MHDR.MType", A: "UnconfirmedDataUp", B: "JoinRequest"},
+				{Field: "MACPayload", A: "lorawan.MACPayload", B: "lorawan.JoinRequestPayload"},
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b := base()
+			tst.Modify(&b)
+			assert.Equal(tst.Expected, DiffPHYPayloads(base(), b))
+		})
+	}
+}
+
+func TestDiffFrames(t *testing.T) {
+	assert := require.New(t)
+
+	fPort := uint8(1)
+	phy := PHYPayload{
+		MHDR: MHDR{MType: UnconfirmedDataUp, Major: LoRaWANR1},
+		MACPayload: &MACPayload{
+			FHDR:       FHDR{DevAddr: DevAddr{1, 2, 3, 4}, FCnt: 1},
+			FPort:      &fPort,
+			FRMPayload: []Payload{&DataPayload{Bytes: []byte{1}}},
+		},
+	}
+	a, err := phy.MarshalBinary()
+	assert.NoError(err)
+
+	phy.MACPayload.(*MACPayload).FHDR.FCnt = 2
+	b, err := phy.MarshalBinary()
+	assert.NoError(err)
+
+	diffs, err := DiffFrames(a, b)
+	assert.NoError(err)
+	assert.Equal([]FrameDiff{{Field: "MACPayload.FHDR.FCnt", A: "1", B: "2"}}, diffs)
+	assert.Equal("MACPayload.FHDR.FCnt: 1 != 2", diffs[0].String())
+
+	diffs, err = DiffFrames(a, a)
+	assert.NoError(err)
+	assert.Nil(diffs)
+
+	_, err = DiffFrames(a, []byte{1})
+	assert.Error(err)
+}
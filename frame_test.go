@@ -0,0 +1,125 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrame(t *testing.T) {
+	devAddr := DevAddr{1, 2, 3, 4}
+	devEUI := EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+
+	tests := []struct {
+		Name                  string
+		PHYPayload            PHYPayload
+		ExpectedDirection     Direction
+		ExpectedDevIdentifier DevIdentifier
+		ExpectedFCnt          uint32
+		ExpectedFCntOK        bool
+		ExpectedError         string
+	}{
+		{
+			Name: "unconfirmed data up",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: UnconfirmedDataUp, Major: LoRaWANR1},
+				MACPayload: &MACPayload{FHDR: FHDR{DevAddr: devAddr, FCnt: 10}},
+			},
+			ExpectedDirection:     DirectionUplink,
+			ExpectedDevIdentifier: DevIdentifier{Type: DevIdentifierDevAddr, DevAddr: devAddr},
+			ExpectedFCnt:          10,
+			ExpectedFCntOK:        true,
+		},
+		{
+			Name: "confirmed data down",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: ConfirmedDataDown, Major: LoRaWANR1},
+				MACPayload: &MACPayload{FHDR: FHDR{DevAddr: devAddr, FCnt: 5}},
+			},
+			ExpectedDirection:     DirectionDownlink,
+			ExpectedDevIdentifier: DevIdentifier{Type: DevIdentifierDevAddr, DevAddr: devAddr},
+			ExpectedFCnt:          5,
+			ExpectedFCntOK:        true,
+		},
+		{
+			Name: "join-request",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: JoinRequest, Major: LoRaWANR1},
+				MACPayload: &JoinRequestPayload{DevEUI: devEUI, DevNonce: 3},
+			},
+			ExpectedDirection:     DirectionUplink,
+			ExpectedDevIdentifier: DevIdentifier{Type: DevIdentifierDevEUI, DevEUI: devEUI},
+		},
+		{
+			Name: "rejoin-request type 0",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: RejoinRequest, Major: LoRaWANR1},
+				MACPayload: &RejoinRequestType02Payload{RejoinType: RejoinRequestType0, DevEUI: devEUI, RJCount0: 7},
+			},
+			ExpectedDirection:     DirectionUplink,
+			ExpectedDevIdentifier: DevIdentifier{Type: DevIdentifierDevEUI, DevEUI: devEUI},
+			ExpectedFCnt:          7,
+			ExpectedFCntOK:        true,
+		},
+		{
+			Name: "rejoin-request type 1",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: RejoinRequest, Major: LoRaWANR1},
+				MACPayload: &RejoinRequestType1Payload{RejoinType: RejoinRequestType1, DevEUI: devEUI, RJCount1: 8},
+			},
+			ExpectedDirection:     DirectionUplink,
+			ExpectedDevIdentifier: DevIdentifier{Type: DevIdentifierDevEUI, DevEUI: devEUI},
+			ExpectedFCnt:          8,
+			ExpectedFCntOK:        true,
+		},
+		{
+			Name: "join-accept",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: JoinAccept, Major: LoRaWANR1},
+				MACPayload: &JoinAcceptPayload{},
+			},
+			ExpectedError: "lorawan: MType JoinAccept does not implement Frame",
+		},
+		{
+			Name: "invalid MACPayload",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: UnconfirmedDataUp, Major: LoRaWANR1},
+				MACPayload: &DataPayload{},
+			},
+			ExpectedError: "lorawan: MACPayload must be of type *MACPayload",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			f, err := tst.PHYPayload.Frame()
+			if tst.ExpectedError != "" {
+				assert.EqualError(err, tst.ExpectedError)
+				return
+			}
+			assert.NoError(err)
+
+			assert.Equal(tst.ExpectedDirection, f.Direction())
+			assert.Equal(tst.ExpectedDevIdentifier, f.DevIdentifier())
+
+			fCnt, ok := f.FCnt()
+			assert.Equal(tst.ExpectedFCntOK, ok)
+			assert.Equal(tst.ExpectedFCnt, fCnt)
+
+			b1, err := f.MarshalBinary()
+			assert.NoError(err)
+			b2, err := tst.PHYPayload.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(b2, b1)
+		})
+	}
+
+	t.Run("String", func(t *testing.T) {
+		assert := require.New(t)
+		assert.Equal("DevAddr 01020304", DevIdentifier{DevAddr: devAddr}.String())
+		assert.Equal("DevEUI 0102030405060708", DevIdentifier{Type: DevIdentifierDevEUI, DevEUI: devEUI}.String())
+		assert.Equal("Downlink", DirectionDownlink.String())
+	})
+}
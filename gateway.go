@@ -0,0 +1,53 @@
+package lorawan
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GatewayEUI represents the 64 bit gateway EUI (also referred to as the
+// gateway ID), as used by the Semtech packet-forwarder protocol.
+//
+// Unlike EUI64, the binary representation is big endian (MSB first), as this
+// is the byte order in which the gateway EUI is sent by the packet-forwarder.
+type GatewayEUI [8]byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (e GatewayEUI) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (e *GatewayEUI) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil {
+		return err
+	}
+	if len(e) != len(b) {
+		return fmt.Errorf("lorawan: exactly %d bytes are expected", len(e))
+	}
+	copy(e[:], b)
+	return nil
+}
+
+// String implement fmt.Stringer.
+func (e GatewayEUI) String() string {
+	return hex.EncodeToString(e[:])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (e GatewayEUI) MarshalBinary() ([]byte, error) {
+	out := make([]byte, len(e))
+	copy(out, e[:])
+	return out, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (e *GatewayEUI) UnmarshalBinary(data []byte) error {
+	if len(data) != len(e) {
+		return fmt.Errorf("lorawan: %d bytes of data are expected", len(e))
+	}
+	copy(e[:], data)
+	return nil
+}
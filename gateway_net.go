@@ -0,0 +1,31 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import (
+	"fmt"
+	"net"
+)
+
+// GatewayEUIFromMAC returns the GatewayEUI for the given hardware address.
+// A 48 bit MAC address is converted into a 64 bit EUI by inserting FFFE
+// between the OUI and the NIC specific part (e.g. b8:27:eb:01:02:03 becomes
+// b827ebfffe010203). A 64 bit hardware address is used as-is.
+func GatewayEUIFromMAC(hw net.HardwareAddr) (GatewayEUI, error) {
+	var out GatewayEUI
+
+	switch len(hw) {
+	case 6:
+		copy(out[0:3], hw[0:3])
+		out[3] = 0xff
+		out[4] = 0xfe
+		copy(out[5:], hw[3:])
+	case 8:
+		copy(out[:], hw)
+	default:
+		return out, fmt.Errorf("lorawan: 6 or 8 bytes hardware address expected, got %d", len(hw))
+	}
+
+	return out, nil
+}
@@ -0,0 +1,56 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGatewayEUIFromMAC(t *testing.T) {
+	Convey("Given a set of tests", t, func() {
+		tests := []struct {
+			Name          string
+			MAC           string
+			Expected      GatewayEUI
+			ExpectedError string
+		}{
+			{
+				Name:     "48 bit MAC",
+				MAC:      "b8:27:eb:01:02:03",
+				Expected: GatewayEUI{0xb8, 0x27, 0xeb, 0xff, 0xfe, 0x01, 0x02, 0x03},
+			},
+			{
+				Name:     "64 bit EUI",
+				MAC:      "01:02:03:04:05:06:07:08",
+				Expected: GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8},
+			},
+			{
+				Name:          "20 octet IPoIB address",
+				MAC:           "00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01",
+				ExpectedError: "lorawan: 6 or 8 bytes hardware address expected, got 20",
+			},
+		}
+
+		for i, test := range tests {
+			Convey(fmt.Sprintf("Testing: %s [%d]", test.Name, i), func() {
+				hw, err := net.ParseMAC(test.MAC)
+				So(err, ShouldBeNil)
+
+				eui, err := GatewayEUIFromMAC(hw)
+				if test.ExpectedError != "" {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, test.ExpectedError)
+					return
+				}
+
+				So(err, ShouldBeNil)
+				So(eui, ShouldResemble, test.Expected)
+			})
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package lorawan
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGatewayEUI(t *testing.T) {
+	Convey("Given an empty GatewayEUI", t, func() {
+		var eui GatewayEUI
+
+		Convey("When the value is [8]{1, 2, 3, 4, 5, 6, 7, 8}", func() {
+			eui = [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+			Convey("Then MarshalText returns 0102030405060708", func() {
+				b, err := eui.MarshalText()
+				So(err, ShouldBeNil)
+				So(string(b), ShouldEqual, "0102030405060708")
+			})
+
+			Convey("Then MarshalBinary returns []byte{1, 2, 3, 4, 5, 6, 7, 8}", func() {
+				b, err := eui.MarshalBinary()
+				So(err, ShouldBeNil)
+				So(b, ShouldResemble, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+			})
+		})
+
+		Convey("Given the string 0102030405060708", func() {
+			str := "0102030405060708"
+
+			Convey("Then UnmarshalText returns GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8}", func() {
+				So(eui.UnmarshalText([]byte(str)), ShouldBeNil)
+				So(eui, ShouldResemble, GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8})
+			})
+		})
+
+		Convey("Given []byte{1, 2, 3, 4, 5, 6, 7, 8}", func() {
+			b := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+			Convey("Then UnmarshalBinary returns GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8}", func() {
+				So(eui.UnmarshalBinary(b), ShouldBeNil)
+				So(eui, ShouldResemble, GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8})
+			})
+		})
+	})
+}
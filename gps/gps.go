@@ -8,6 +8,9 @@ import (
 
 var gpsEpochTime = time.Date(1980, time.January, 6, 0, 0, 0, 0, time.UTC)
 
+// leapSecondsTable contains the leap seconds inserted since the GPS epoch
+// (source: IERS Bulletin C). When the IERS announces a new leap second, it
+// must be appended to this table.
 var leapSecondsTable = []struct {
 	Time     time.Time
 	Duration time.Duration
@@ -32,6 +35,31 @@ var leapSecondsTable = []struct {
 	{Time: time.Date(2016, time.December, 31, 23, 59, 59, 0, time.UTC), Duration: time.Second},
 }
 
+// TimeToGPSEpoch returns the time since GPS epoch for the given time,
+// corrected with the leap seconds. This is the value used by the
+// DeviceTimeAns mac-command and the Class-B beacon.
+func TimeToGPSEpoch(t time.Time) time.Duration {
+	return Time(t).TimeSinceGPSEpoch()
+}
+
+// GPSEpochToTime returns the time for the given time since GPS epoch,
+// corrected with the leap seconds.
+func GPSEpochToTime(sinceEpoch time.Duration) time.Time {
+	return time.Time(NewTimeFromTimeSinceGPSEpoch(sinceEpoch))
+}
+
+// LeapSeconds returns the number of leap seconds inserted between the GPS
+// epoch and the given time, this is the offset between GPS time and UTC.
+func LeapSeconds(t time.Time) time.Duration {
+	var offset time.Duration
+	for _, ls := range leapSecondsTable {
+		if ls.Time.Before(t) {
+			offset += ls.Duration
+		}
+	}
+	return offset
+}
+
 // Time represents a GPS time wrapper.
 type Time time.Time
 
@@ -51,14 +79,7 @@ func NewTimeFromTimeSinceGPSEpoch(sinceEpoch time.Duration) Time {
 // TimeSinceGPSEpoch returns the time duration since GPS epoch, corrected with
 // the leap seconds.
 func (t Time) TimeSinceGPSEpoch() time.Duration {
-	var offset time.Duration
-	for _, ls := range leapSecondsTable {
-		if ls.Time.Before(time.Time(t)) {
-			offset += ls.Duration
-		}
-	}
-
-	return time.Time(t).Sub(gpsEpochTime) + offset
+	return time.Time(t).Sub(gpsEpochTime) + LeapSeconds(time.Time(t))
 }
 
 // String implements the Stringer interface.
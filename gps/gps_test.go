@@ -33,3 +33,30 @@ func TestTime(t *testing.T) {
 		})
 	}
 }
+
+func TestConversion(t *testing.T) {
+	assert := require.New(t)
+
+	ts := time.Date(2025, time.July, 14, 0, 0, 0, 0, time.UTC)
+	assert.Equal(1436486418*time.Second, TimeToGPSEpoch(ts))
+	assert.True(GPSEpochToTime(1436486418 * time.Second).Equal(ts))
+
+	// sub-second precision is retained (DeviceTimeAns has a 1/256 s
+	// resolution)
+	ts = ts.Add(500 * time.Millisecond)
+	assert.Equal(1436486418500*time.Millisecond, TimeToGPSEpoch(ts))
+	assert.True(GPSEpochToTime(TimeToGPSEpoch(ts)).Equal(ts))
+}
+
+func TestLeapSeconds(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(time.Duration(0), LeapSeconds(gpsEpochTime))
+	assert.Equal(15*time.Second, LeapSeconds(time.Date(2012, time.June, 30, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(18*time.Second, LeapSeconds(time.Date(2025, time.July, 14, 0, 0, 0, 0, time.UTC)))
+
+	// the table must be sorted
+	for i := 1; i < len(leapSecondsTable); i++ {
+		assert.True(leapSecondsTable[i-1].Time.Before(leapSecondsTable[i].Time))
+	}
+}
@@ -0,0 +1,30 @@
+package lorawan
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// HEXBytes defines a type which represents bytes as HEX when marshaled to
+// text. When unmarshaling, an optional 0x prefix is accepted.
+type HEXBytes []byte
+
+// String implements fmt.Stringer.
+func (hb HEXBytes) String() string {
+	return hex.EncodeToString(hb[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (hb HEXBytes) MarshalText() ([]byte, error) {
+	return []byte(hb.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (hb *HEXBytes) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil {
+		return err
+	}
+	*hb = HEXBytes(b)
+	return nil
+}
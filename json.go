@@ -0,0 +1,514 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import "encoding/json"
+
+// This file contains the JSON encoding helpers that are not needed for the
+// binary codecs. These are excluded from TinyGo builds.
+
+// MarshalJSON encodes the PHYPayload into JSON. The mac-command CIDs are
+// encoded using the name matching the direction of the frame, e.g.
+// LinkADRAns for uplink and LinkADRReq for downlink.
+func (p PHYPayload) MarshalJSON() ([]byte, error) {
+	type phyAlias PHYPayload
+	return json.Marshal(phyAlias(p.withDirectionalMACCommands()))
+}
+
+// UnmarshalJSON decodes the PHYPayload from JSON, as encoded by MarshalJSON.
+// The type of the MACPayload is derived from the MType and the mac-command
+// payloads are decoded using the direction of the frame. For backwards
+// compatibility, it also accepts the base64 encoded PHYPayload (see
+// UnmarshalText).
+func (p *PHYPayload) UnmarshalJSON(data []byte) error {
+	if len(data) != 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		return p.UnmarshalText([]byte(text))
+	}
+
+	var in struct {
+		MHDR       MHDR            `json:"mhdr"`
+		MACPayload json.RawMessage `json:"macPayload"`
+		MIC        MIC             `json:"mic"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	p.MHDR = in.MHDR
+	p.MIC = in.MIC
+	p.MACPayload = nil
+
+	if isJSONNull(in.MACPayload) {
+		return nil
+	}
+
+	switch p.MHDR.MType {
+	case JoinRequest:
+		p.MACPayload = &JoinRequestPayload{}
+	case JoinAccept:
+		// the join-accept is either encrypted (DataPayload) or decrypted
+		if hasJSONKey(in.MACPayload, "bytes") {
+			p.MACPayload = &DataPayload{}
+		} else {
+			p.MACPayload = &JoinAcceptPayload{}
+		}
+	case RejoinRequest:
+		// note that the RejoinType of the type 1 payload is encoded as
+		// rejoinRequest
+		if hasJSONKey(in.MACPayload, "rejoinRequest") {
+			p.MACPayload = &RejoinRequestType1Payload{}
+		} else {
+			p.MACPayload = &RejoinRequestType02Payload{}
+		}
+	case Proprietary:
+		p.MACPayload = &DataPayload{}
+	default:
+		macPL, err := unmarshalJSONMACPayload(p.isUplink(), in.MACPayload)
+		if err != nil {
+			return err
+		}
+		p.MACPayload = macPL
+		return nil
+	}
+
+	return json.Unmarshal(in.MACPayload, p.MACPayload)
+}
+
+// UnmarshalJSON decodes the CFList from JSON. The type of the payload is
+// derived from the CFListType.
+func (l *CFList) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Payload    json.RawMessage `json:"payload"`
+		CFListType CFListType      `json:"cFListType"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	l.CFListType = in.CFListType
+	l.Payload = nil
+
+	if isJSONNull(in.Payload) {
+		return nil
+	}
+
+	switch l.CFListType {
+	case CFListChannelMask:
+		l.Payload = &CFListChannelMaskPayload{}
+	default:
+		l.Payload = &CFListChannelPayload{}
+	}
+
+	return json.Unmarshal(in.Payload, l.Payload)
+}
+
+// UnmarshalJSONWithDirection decodes the MACCommand from JSON. As the
+// payload type depends on the direction, uplink must be set for uplink
+// mac-commands. Unknown proprietary mac-commands are decoded as
+// ProprietaryMACCommandPayload, other unknown mac-commands as
+// RawMACCommandPayload.
+func (m *MACCommand) UnmarshalJSONWithDirection(uplink bool, data []byte) error {
+	var in struct {
+		CID     CID             `json:"cid"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	payload, err := newMACCommandQueuePayload(uplink, in.CID, !isJSONNull(in.Payload))
+	if err != nil {
+		return err
+	}
+
+	m.CID = in.CID
+	m.Payload = payload
+
+	if m.Payload != nil {
+		return json.Unmarshal(in.Payload, m.Payload)
+	}
+	return nil
+}
+
+// unmarshalJSONMACPayload decodes the MACPayload from JSON.
+func unmarshalJSONMACPayload(uplink bool, data []byte) (*MACPayload, error) {
+	var in struct {
+		FHDR struct {
+			DevAddr DevAddr           `json:"devAddr"`
+			FCtrl   FCtrl             `json:"fCtrl"`
+			FCnt    uint32            `json:"fCnt"`
+			FOpts   []json.RawMessage `json:"fOpts"`
+		} `json:"fhdr"`
+		FPort      *uint8            `json:"fPort"`
+		FRMPayload []json.RawMessage `json:"frmPayload"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+
+	fOpts, err := unmarshalJSONPayloads(uplink, in.FHDR.FOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	frmPayload, err := unmarshalJSONPayloads(uplink, in.FRMPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MACPayload{
+		FHDR: FHDR{
+			DevAddr: in.FHDR.DevAddr,
+			FCtrl:   in.FHDR.FCtrl,
+			FCnt:    in.FHDR.FCnt,
+			FOpts:   fOpts,
+		},
+		FPort:      in.FPort,
+		FRMPayload: frmPayload,
+	}, nil
+}
+
+// unmarshalJSONPayloads decodes the given FOpts or FRMPayload items. Items
+// containing a cid are decoded as MACCommand, the other items as
+// DataPayload.
+func unmarshalJSONPayloads(uplink bool, items []json.RawMessage) ([]Payload, error) {
+	if items == nil {
+		return nil, nil
+	}
+
+	out := make([]Payload, 0, len(items))
+	for _, item := range items {
+		if hasJSONKey(item, "cid") {
+			var mac MACCommand
+			if err := mac.UnmarshalJSONWithDirection(uplink, item); err != nil {
+				return nil, err
+			}
+			out = append(out, &mac)
+			continue
+		}
+
+		var pl DataPayload
+		if err := json.Unmarshal(item, &pl); err != nil {
+			return nil, err
+		}
+		out = append(out, &pl)
+	}
+
+	return out, nil
+}
+
+// hasJSONKey returns true when the given JSON object contains the given key.
+func hasJSONKey(data []byte, key string) bool {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return false
+	}
+	_, ok := obj[key]
+	return ok
+}
+
+// isJSONNull returns true when the given JSON value is empty or null.
+func isJSONNull(data []byte) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+// MarshalJSONWithDirection encodes the MACCommand into JSON, using the CID
+// name for the given direction (see CID.StringWithDirection).
+func (m MACCommand) MarshalJSONWithDirection(uplink bool) ([]byte, error) {
+	return json.Marshal(struct {
+		CID     string            `json:"cid"`
+		Payload MACCommandPayload `json:"payload"`
+	}{
+		CID:     m.CID.StringWithDirection(uplink),
+		Payload: m.Payload,
+	})
+}
+
+// directionalMACCommand wraps a MACCommand, so that it is JSON encoded using
+// the direction-aware CID name.
+type directionalMACCommand struct {
+	MACCommand
+	uplink bool
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m directionalMACCommand) MarshalJSON() ([]byte, error) {
+	return m.MACCommand.MarshalJSONWithDirection(m.uplink)
+}
+
+// withDirectionalMACCommands returns a copy of the PHYPayload in which the
+// mac-commands of the MACPayload are wrapped by directionalMACCommand. The
+// original PHYPayload is not modified.
+func (p PHYPayload) withDirectionalMACCommands() PHYPayload {
+	macPL, ok := p.MACPayload.(*MACPayload)
+	if !ok || macPL == nil {
+		return p
+	}
+
+	pl := *macPL
+	pl.FHDR.FOpts = directionalMACCommands(p.isUplink(), pl.FHDR.FOpts)
+	pl.FRMPayload = directionalMACCommands(p.isUplink(), pl.FRMPayload)
+	p.MACPayload = &pl
+
+	return p
+}
+
+// directionalMACCommands returns a copy of the given payloads in which the
+// mac-commands are wrapped by directionalMACCommand.
+func directionalMACCommands(uplink bool, payloads []Payload) []Payload {
+	if payloads == nil {
+		return nil
+	}
+
+	out := make([]Payload, len(payloads))
+	for i, pl := range payloads {
+		if mac, ok := pl.(*MACCommand); ok && mac != nil {
+			out[i] = &directionalMACCommand{MACCommand: *mac, uplink: uplink}
+		} else {
+			out[i] = pl
+		}
+	}
+	return out
+}
+
+// decryptedFrame contains the decrypted and decoded FOpts and FRMPayload.
+type decryptedFrame struct {
+	FOpts      []Payload `json:"fOpts"`
+	FRMPayload []Payload `json:"frmPayload"`
+}
+
+// MarshalJSONWithKeys encodes the PHYPayload into JSON, like MarshalJSON.
+// For data frames it adds a "decrypted" object, containing the decrypted
+// and decoded FOpts and FRMPayload, using the given session keys. The
+// PHYPayload must be in its encrypted form (e.g. as received) and the FCnt
+// must contain the full 32 bit frame-counter. The FOpts are only decrypted
+// for LoRaWAN 1.1. When the AppSKey is not set (e.g. it is unknown to the
+// network-server), the application FRMPayload is left out. The PHYPayload
+// itself is not modified.
+func (p PHYPayload) MarshalJSONWithKeys(macVersion MACVersion, keys SessionKeys) ([]byte, error) {
+	type phyAlias PHYPayload
+	out := struct {
+		phyAlias
+		Decrypted *decryptedFrame `json:"decrypted,omitempty"`
+	}{
+		phyAlias: phyAlias(p.withDirectionalMACCommands()),
+	}
+
+	if _, ok := p.MACPayload.(*MACPayload); ok {
+		decrypted, err := p.decryptFrame(macVersion, keys)
+		if err != nil {
+			return nil, err
+		}
+		decrypted.FOpts = directionalMACCommands(p.isUplink(), decrypted.FOpts)
+		decrypted.FRMPayload = directionalMACCommands(p.isUplink(), decrypted.FRMPayload)
+		out.Decrypted = decrypted
+	}
+
+	return json.Marshal(out)
+}
+
+// JSONOptions defines the options for MarshalJSONWithOptions.
+type JSONOptions struct {
+	// MACVersion defines the LoRaWAN version of the frame. For LoRaWAN 1.1
+	// the FOpts are encrypted.
+	MACVersion MACVersion
+
+	// NwkSEncKey is used to decrypt the FRMPayload of FPort 0 frames and
+	// for LoRaWAN 1.1 the FOpts. When not set, these are left encrypted.
+	NwkSEncKey AES128Key
+}
+
+// MarshalJSONWithOptions encodes the PHYPayload into JSON, like MarshalJSON.
+// For data frames, the FOpts and the FRMPayload of FPort 0 frames are
+// rendered as decoded mac-commands, regardless if DecodeFOptsToMACCommands
+// or DecryptFRMPayload has been called. Like MarshalJSONWithKeys, the
+// PHYPayload must be in its encrypted form and the FCnt must contain the full
+// 32 bit frame-counter. The PHYPayload itself is not modified.
+func (p PHYPayload) MarshalJSONWithOptions(opts JSONOptions) ([]byte, error) {
+	if _, ok := p.MACPayload.(*MACPayload); !ok {
+		return p.MarshalJSON()
+	}
+
+	phy, err := p.copyEncrypted()
+	if err != nil {
+		return nil, err
+	}
+	macPL := phy.MACPayload.(*MACPayload)
+	keySet := opts.NwkSEncKey != (AES128Key{})
+
+	if opts.MACVersion != LoRaWAN1_1 || keySet {
+		if opts.MACVersion == LoRaWAN1_1 {
+			if err := phy.EncryptFOpts(opts.NwkSEncKey); err != nil {
+				return nil, err
+			}
+		}
+		if err := phy.DecodeFOptsToMACCommands(); err != nil {
+			return nil, err
+		}
+	}
+
+	if macPL.FPort != nil && *macPL.FPort == 0 && keySet {
+		if err := phy.DecryptFRMPayload(opts.NwkSEncKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return phy.MarshalJSON()
+}
+
+// copyEncrypted returns a copy of the data PHYPayload, in which the FOpts
+// and FRMPayload are (re-)decoded from their binary form.
+func (p PHYPayload) copyEncrypted() (PHYPayload, error) {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return PHYPayload{}, err
+	}
+
+	var phy PHYPayload
+	if err := phy.UnmarshalBinary(b); err != nil {
+		return PHYPayload{}, err
+	}
+
+	// restore the full frame-counter, as only the 16 LSB are transmitted
+	phy.MACPayload.(*MACPayload).FHDR.FCnt = p.MACPayload.(*MACPayload).FHDR.FCnt
+
+	return phy, nil
+}
+
+// decryptFrame decrypts a copy of the MACPayload and returns the decrypted
+// FOpts and FRMPayload.
+func (p PHYPayload) decryptFrame(macVersion MACVersion, keys SessionKeys) (*decryptedFrame, error) {
+	phy, err := p.copyEncrypted()
+	if err != nil {
+		return nil, err
+	}
+	macPL := phy.MACPayload.(*MACPayload)
+
+	if macVersion == LoRaWAN1_1 {
+		if err := phy.EncryptFOpts(keys.NwkSEncKey); err != nil {
+			return nil, err
+		}
+	}
+	if err := phy.DecodeFOptsToMACCommands(); err != nil {
+		return nil, err
+	}
+
+	if macPL.FPort != nil {
+		key := keys.NwkSEncKey
+		if *macPL.FPort > 0 {
+			key = keys.AppSKey
+		}
+
+		if key == (AES128Key{}) {
+			macPL.FRMPayload = nil
+		} else if err := phy.DecryptFRMPayload(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &decryptedFrame{
+		FOpts:      macPL.FHDR.FOpts,
+		FRMPayload: macPL.FRMPayload,
+	}, nil
+}
+
+// macCommandQueueJSON is used for the JSON (un)marshaling of the
+// MACCommandQueue. The CID is encoded as integer, so that proprietary
+// mac-commands can be restored too.
+type macCommandQueueJSON struct {
+	Uplink   bool                      `json:"uplink"`
+	Commands []macCommandQueueItemJSON `json:"commands"`
+}
+
+type macCommandQueueItemJSON struct {
+	CID     uint8           `json:"cid"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (q MACCommandQueue) MarshalJSON() ([]byte, error) {
+	out := macCommandQueueJSON{
+		Uplink:   q.Uplink,
+		Commands: []macCommandQueueItemJSON{},
+	}
+
+	for _, mac := range q.Commands {
+		pl, err := json.Marshal(mac.Payload)
+		if err != nil {
+			return nil, err
+		}
+
+		out.Commands = append(out.Commands, macCommandQueueItemJSON{
+			CID:     uint8(mac.CID),
+			Payload: pl,
+		})
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (q *MACCommandQueue) UnmarshalJSON(data []byte) error {
+	var in macCommandQueueJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	q.Uplink = in.Uplink
+	q.Commands = nil
+
+	for _, item := range in.Commands {
+		payload, err := newMACCommandQueuePayload(q.Uplink, CID(item.CID), !isJSONNull(item.Payload))
+		if err != nil {
+			return err
+		}
+
+		mac := MACCommand{
+			CID:     CID(item.CID),
+			Payload: payload,
+		}
+
+		if mac.Payload != nil {
+			if err := json.Unmarshal(item.Payload, mac.Payload); err != nil {
+				return err
+			}
+		}
+
+		q.Commands = append(q.Commands, mac)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. It returns an error
+// when the ABP activation is not valid.
+func (a ABPActivation) MarshalJSON() ([]byte, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	type abpActivation ABPActivation
+	return json.Marshal(abpActivation(a))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It returns an
+// error when the ABP activation is not valid.
+func (a *ABPActivation) UnmarshalJSON(b []byte) error {
+	type abpActivation ABPActivation
+	var out abpActivation
+	if err := json.Unmarshal(b, &out); err != nil {
+		return err
+	}
+
+	if err := ABPActivation(out).Validate(); err != nil {
+		return err
+	}
+
+	*a = ABPActivation(out)
+	return nil
+}
@@ -0,0 +1,108 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// jsonCompatFieldNames maps the (ChirpStack v4) JSON field names which can't
+// be derived by converting snake_case to camelCase.
+var jsonCompatFieldNames = map[string]string{
+	"f_type":  "mType",
+	"devaddr": "devAddr",
+	"cf_list": "cFlist",
+}
+
+// jsonCompatAcronyms contains the snake_case segments which are written as
+// acronym by the JSON field names of this package.
+var jsonCompatAcronyms = map[string]string{
+	"eui":     "EUI",
+	"id":      "ID",
+	"ok":      "OK",
+	"dr":      "DR",
+	"gps":     "GPS",
+	"eirp":    "EIRP",
+	"lorawan": "LoRaWAN",
+}
+
+// NormalizeJSON rewrites the JSON field names of the given document into the
+// field names used by this package, such that it can be unmarshaled into the
+// structures of this package. This makes it possible to ingest both the
+// ChirpStack v3 JSON format (which uses the field names of this package) and
+// the ChirpStack v4 JSON format (which uses snake_case and renamed fields,
+// e.g. f_type, devaddr and payload instead of mType, devAddr and macPayload).
+// Field names which are already in the format of this package are not
+// modified.
+func NormalizeJSON(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(normalizeJSONValue(v))
+}
+
+// UnmarshalCompatJSON normalizes the given JSON document using NormalizeJSON
+// and unmarshals the result into v.
+func UnmarshalCompatJSON(b []byte, v interface{}) error {
+	b, err := NormalizeJSON(b)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func normalizeJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		_, isPHYPayload := v["mhdr"]
+
+		for k, val := range v {
+			if isPHYPayload && k == "payload" {
+				k = "macPayload"
+			}
+			out[normalizeJSONFieldName(k)] = normalizeJSONValue(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = normalizeJSONValue(v[i])
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func normalizeJSONFieldName(name string) string {
+	if n, ok := jsonCompatFieldNames[name]; ok {
+		return n
+	}
+
+	if !strings.Contains(name, "_") {
+		return name
+	}
+
+	parts := strings.Split(name, "_")
+	for i, p := range parts {
+		if i == 0 || p == "" {
+			continue
+		}
+		if a, ok := jsonCompatAcronyms[p]; ok {
+			parts[i] = a
+			continue
+		}
+		parts[i] = strings.ToUpper(p[0:1]) + p[1:]
+	}
+
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,103 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeJSONFieldName(t *testing.T) {
+	tests := map[string]string{
+		"devAddr":             "devAddr",
+		"devaddr":             "devAddr",
+		"f_type":              "mType",
+		"f_ctrl":              "fCtrl",
+		"adr_ack_req":         "adrAckReq",
+		"class_b":             "classB",
+		"join_eui":            "joinEUI",
+		"home_net_id":         "homeNetID",
+		"rx1_dr_offset":       "rx1DROffset",
+		"data_rate_ok":        "dataRateOK",
+		"dev_lorawan_version": "devLoRaWANVersion",
+		"cf_list":             "cFlist",
+	}
+
+	for in, expected := range tests {
+		t.Run(in, func(t *testing.T) {
+			require.Equal(t, expected, normalizeJSONFieldName(in))
+		})
+	}
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	t.Run("ChirpStack v4 PHYPayload", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := NormalizeJSON([]byte(`{
+			"mhdr": {"f_type": "UnconfirmedDataUp", "major": "LoRaWANR1"},
+			"payload": {
+				"fhdr": {
+					"devaddr": "01020304",
+					"f_ctrl": {"adr": true, "adr_ack_req": false, "ack": false, "f_pending": false, "class_b": false},
+					"f_cnt": 10,
+					"f_opts": []
+				},
+				"f_port": 1,
+				"frm_payload": null
+			},
+			"mic": "01020304"
+		}`))
+		assert.NoError(err)
+		assert.JSONEq(`{
+			"mhdr": {"mType": "UnconfirmedDataUp", "major": "LoRaWANR1"},
+			"macPayload": {
+				"fhdr": {
+					"devAddr": "01020304",
+					"fCtrl": {"adr": true, "adrAckReq": false, "ack": false, "fPending": false, "classB": false},
+					"fCnt": 10,
+					"fOpts": []
+				},
+				"fPort": 1,
+				"frmPayload": null
+			},
+			"mic": "01020304"
+		}`, string(b))
+	})
+
+	t.Run("mac-command payload is not renamed", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := NormalizeJSON([]byte(`{"cid": "LinkCheckAns", "payload": {"margin": 10, "gw_cnt": 2}}`))
+		assert.NoError(err)
+		assert.JSONEq(`{"cid": "LinkCheckAns", "payload": {"margin": 10, "gwCnt": 2}}`, string(b))
+	})
+
+	t.Run("ChirpStack v3 format is not modified", func(t *testing.T) {
+		assert := require.New(t)
+
+		in := `{"joinEUI": "0102030405060708", "devEUI": "0807060504030201", "devNonce": 1024}`
+		b, err := NormalizeJSON([]byte(in))
+		assert.NoError(err)
+		assert.JSONEq(in, string(b))
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := NormalizeJSON([]byte(`{`))
+		require.Error(t, err)
+	})
+}
+
+func TestUnmarshalCompatJSON(t *testing.T) {
+	assert := require.New(t)
+
+	var pl JoinRequestPayload
+	assert.NoError(UnmarshalCompatJSON([]byte(`{"join_eui": "0102030405060708", "dev_eui": "0807060504030201", "dev_nonce": 1024}`), &pl))
+	assert.Equal(JoinRequestPayload{
+		JoinEUI:  EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+		DevEUI:   EUI64{8, 7, 6, 5, 4, 3, 2, 1},
+		DevNonce: 1024,
+	}, pl)
+}
@@ -0,0 +1,441 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPHYPayloadJSON(t *testing.T) {
+	fPort0 := uint8(0)
+	fPort10 := uint8(10)
+
+	tests := []struct {
+		Name       string
+		PHYPayload PHYPayload
+	}{
+		{
+			Name: "uplink with mac-commands",
+			PHYPayload: PHYPayload{
+				MHDR: MHDR{MType: UnconfirmedDataUp, Major: LoRaWANR1},
+				MACPayload: &MACPayload{
+					FHDR: FHDR{
+						DevAddr: DevAddr{1, 2, 3, 4},
+						FCtrl:   FCtrl{ADR: true},
+						FCnt:    10,
+						FOpts: []Payload{
+							&MACCommand{CID: DevStatusAns, Payload: &DevStatusAnsPayload{Battery: 115, Margin: 7}},
+							&MACCommand{CID: LinkCheckReq},
+						},
+					},
+					FPort:      &fPort10,
+					FRMPayload: []Payload{&DataPayload{Bytes: []byte{1, 2, 3, 4}}},
+				},
+				MIC: MIC{1, 2, 3, 4},
+			},
+		},
+		{
+			Name: "downlink with mac-commands in FRMPayload",
+			PHYPayload: PHYPayload{
+				MHDR: MHDR{MType: ConfirmedDataDown, Major: LoRaWANR1},
+				MACPayload: &MACPayload{
+					FHDR: FHDR{
+						DevAddr: DevAddr{1, 2, 3, 4},
+						FCtrl:   FCtrl{ACK: true},
+					},
+					FPort: &fPort0,
+					FRMPayload: []Payload{
+						&MACCommand{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 5, TXPower: 2, ChMask: ChMask{true, true, true}, Redundancy: Redundancy{NbRep: 1}}},
+						&MACCommand{CID: 0x80, Payload: &ProprietaryMACCommandPayload{Bytes: []byte{1, 2}}},
+					},
+				},
+				MIC: MIC{4, 3, 2, 1},
+			},
+		},
+		{
+			Name: "join-request",
+			PHYPayload: PHYPayload{
+				MHDR: MHDR{MType: JoinRequest, Major: LoRaWANR1},
+				MACPayload: &JoinRequestPayload{
+					JoinEUI:  EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+					DevEUI:   EUI64{8, 7, 6, 5, 4, 3, 2, 1},
+					DevNonce: 258,
+				},
+			},
+		},
+		{
+			Name: "join-accept",
+			PHYPayload: PHYPayload{
+				MHDR: MHDR{MType: JoinAccept, Major: LoRaWANR1},
+				MACPayload: &JoinAcceptPayload{
+					JoinNonce:  65793,
+					HomeNetID:  NetID{1, 2, 3},
+					DevAddr:    DevAddr{1, 2, 3, 4},
+					DLSettings: DLSettings{RX2DataRate: 3, RX1DROffset: 1},
+					RXDelay:    1,
+					CFList: &CFList{
+						CFListType: CFListChannel,
+						Payload:    &CFListChannelPayload{Channels: [5]uint32{867100000, 867300000}},
+					},
+				},
+			},
+		},
+		{
+			Name: "encrypted join-accept",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: JoinAccept, Major: LoRaWANR1},
+				MACPayload: &DataPayload{Bytes: []byte{1, 2, 3, 4}},
+			},
+		},
+		{
+			Name: "rejoin-request type 0",
+			PHYPayload: PHYPayload{
+				MHDR: MHDR{MType: RejoinRequest, Major: LoRaWANR1},
+				MACPayload: &RejoinRequestType02Payload{
+					RejoinType: RejoinRequestType0,
+					NetID:      NetID{1, 2, 3},
+					DevEUI:     EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+					RJCount0:   10,
+				},
+			},
+		},
+		{
+			Name: "rejoin-request type 1",
+			PHYPayload: PHYPayload{
+				MHDR: MHDR{MType: RejoinRequest, Major: LoRaWANR1},
+				MACPayload: &RejoinRequestType1Payload{
+					RejoinType: RejoinRequestType1,
+					JoinEUI:    EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+					DevEUI:     EUI64{8, 7, 6, 5, 4, 3, 2, 1},
+					RJCount1:   10,
+				},
+			},
+		},
+		{
+			Name: "proprietary",
+			PHYPayload: PHYPayload{
+				MHDR:       MHDR{MType: Proprietary, Major: LoRaWANR1},
+				MACPayload: &DataPayload{Bytes: []byte{1, 2, 3}},
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := json.Marshal(tst.PHYPayload)
+			assert.NoError(err)
+
+			var phy PHYPayload
+			assert.NoError(json.Unmarshal(b, &phy))
+
+			// compare the binary and JSON encoding, as unexported fields
+			// (e.g. the FOptsLen) are not part of the JSON encoding
+			expBytes, err := tst.PHYPayload.MarshalBinary()
+			assert.NoError(err)
+			phyBytes, err := phy.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(expBytes, phyBytes)
+
+			b2, err := json.Marshal(phy)
+			assert.NoError(err)
+			assert.JSONEq(string(b), string(b2))
+		})
+	}
+
+	t.Run("base64", func(t *testing.T) {
+		assert := require.New(t)
+
+		var phy PHYPayload
+		assert.NoError(json.Unmarshal([]byte(`"gAQDAgEDAAAGcwcK4mTU9+EX0sA="`), &phy))
+		assert.Equal(ConfirmedDataUp, phy.MHDR.MType)
+		assert.Equal(MIC{0xe1, 0x17, 0xd2, 0xc0}, phy.MIC)
+	})
+
+	t.Run("invalid mType", func(t *testing.T) {
+		assert := require.New(t)
+
+		var phy PHYPayload
+		assert.EqualError(json.Unmarshal([]byte(`{"mhdr": {"mType": "Foo", "major": "LoRaWANR1"}}`), &phy), "lorawan: invalid MType Foo")
+	})
+}
+
+func TestPHYPayloadMarshalJSONWithKeys(t *testing.T) {
+	Convey("Given a LoRaWAN 1.0 uplink with FOpts and FRMPayload", t, func() {
+		keys := SessionKeys{
+			FNwkSIntKey: [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SNwkSIntKey: [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			NwkSEncKey:  [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			AppSKey:     [16]byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+		}
+
+		var phy PHYPayload
+		So(phy.UnmarshalText([]byte("gAQDAgEDAAAGcwcK4mTU9+EX0sA=")), ShouldBeNil)
+
+		Convey("Then MarshalJSONWithKeys contains the encrypted and decrypted payloads", func() {
+			b, err := phy.MarshalJSONWithKeys(LoRaWAN1_0, keys)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, `{"mhdr":{"mType":"ConfirmedDataUp","major":"LoRaWANR1"},"macPayload":{"fhdr":{"devAddr":"01020304","fCtrl":{"adr":false,"adrAckReq":false,"ack":false,"fPending":false,"classB":false},"fCnt":0,"fOpts":[{"bytes":"BnMH"}]},"fPort":10,"frmPayload":[{"bytes":"4mTU9w=="}]},"mic":"e117d2c0","decrypted":{"fOpts":[{"cid":"DevStatusAns","payload":{"battery":115,"margin":7}}],"frmPayload":[{"bytes":"AQIDBA=="}]}}`)
+
+			Convey("Then the PHYPayload has not been modified", func() {
+				str, err := phy.MarshalText()
+				So(err, ShouldBeNil)
+				So(string(str), ShouldEqual, "gAQDAgEDAAAGcwcK4mTU9+EX0sA=")
+			})
+		})
+
+		Convey("Then the FRMPayload is left out when the AppSKey is not set", func() {
+			keys.AppSKey = AES128Key{}
+			b, err := phy.MarshalJSONWithKeys(LoRaWAN1_0, keys)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEndWith, `"decrypted":{"fOpts":[{"cid":"DevStatusAns","payload":{"battery":115,"margin":7}}],"frmPayload":null}}`)
+		})
+	})
+
+	Convey("Given a LoRaWAN 1.1 uplink with encrypted FOpts", t, func() {
+		keys := SessionKeys{
+			NwkSEncKey: [16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2},
+			AppSKey:    [16]byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+		}
+		fPort := uint8(1)
+
+		phy := PHYPayload{
+			MHDR: MHDR{
+				MType: UnconfirmedDataUp,
+				Major: LoRaWANR1,
+			},
+			MACPayload: &MACPayload{
+				FHDR: FHDR{
+					DevAddr: DevAddr{1, 2, 3, 4},
+					FCnt:    65537,
+					FOpts: []Payload{
+						&MACCommand{CID: LinkCheckReq},
+					},
+				},
+				FPort: &fPort,
+				FRMPayload: []Payload{
+					&DataPayload{Bytes: []byte{1, 2, 3, 4}},
+				},
+			},
+		}
+		So(phy.EncryptFOpts(keys.NwkSEncKey), ShouldBeNil)
+		So(phy.EncryptFRMPayload(keys.AppSKey), ShouldBeNil)
+
+		Convey("Then MarshalJSONWithKeys decrypts the FOpts and FRMPayload", func() {
+			b, err := phy.MarshalJSONWithKeys(LoRaWAN1_1, keys)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEndWith, `"decrypted":{"fOpts":[{"cid":"LinkCheckReq","payload":null}],"frmPayload":[{"bytes":"AQIDBA=="}]}}`)
+		})
+	})
+
+	Convey("Given a join-request", t, func() {
+		phy := PHYPayload{
+			MHDR: MHDR{
+				MType: JoinRequest,
+				Major: LoRaWANR1,
+			},
+			MACPayload: &JoinRequestPayload{},
+		}
+
+		Convey("Then MarshalJSONWithKeys equals MarshalJSON", func() {
+			b1, err := phy.MarshalJSONWithKeys(LoRaWAN1_0, SessionKeys{})
+			So(err, ShouldBeNil)
+			b2, err := phy.MarshalJSON()
+			So(err, ShouldBeNil)
+			So(string(b1), ShouldEqual, string(b2))
+		})
+	})
+}
+
+func TestPHYPayloadMarshalJSONWithOptions(t *testing.T) {
+	nwkSEncKey := AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+	Convey("Given a LoRaWAN 1.0 downlink with FPort 0 FRMPayload", t, func() {
+		fPort := uint8(0)
+
+		phy := PHYPayload{
+			MHDR: MHDR{
+				MType: UnconfirmedDataDown,
+				Major: LoRaWANR1,
+			},
+			MACPayload: &MACPayload{
+				FHDR: FHDR{
+					DevAddr: DevAddr{1, 2, 3, 4},
+					FCnt:    65537,
+				},
+				FPort: &fPort,
+				FRMPayload: []Payload{
+					&MACCommand{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 5}},
+				},
+			},
+		}
+		So(phy.EncryptFRMPayload(nwkSEncKey), ShouldBeNil)
+
+		b, err := phy.MarshalBinary()
+		So(err, ShouldBeNil)
+
+		Convey("Then MarshalJSONWithOptions renders the decoded mac-commands", func() {
+			out, err := phy.MarshalJSONWithOptions(JSONOptions{NwkSEncKey: nwkSEncKey})
+			So(err, ShouldBeNil)
+			So(string(out), ShouldContainSubstring, `"frmPayload":[{"cid":"RXTimingSetupReq","payload":{"delay":5}}]`)
+
+			Convey("Then the PHYPayload has not been modified", func() {
+				b2, err := phy.MarshalBinary()
+				So(err, ShouldBeNil)
+				So(b2, ShouldResemble, b)
+				So(phy.MACPayload.(*MACPayload).FHDR.FCnt, ShouldEqual, 65537)
+			})
+		})
+
+		Convey("Then the FRMPayload is left encrypted when the NwkSEncKey is not set", func() {
+			out, err := phy.MarshalJSONWithOptions(JSONOptions{})
+			So(err, ShouldBeNil)
+			So(string(out), ShouldContainSubstring, `"frmPayload":[{"bytes":`)
+		})
+	})
+
+	Convey("Given a downlink with FOpts", t, func() {
+		phy := PHYPayload{
+			MHDR: MHDR{
+				MType: UnconfirmedDataDown,
+				Major: LoRaWANR1,
+			},
+			MACPayload: &MACPayload{
+				FHDR: FHDR{
+					DevAddr: DevAddr{1, 2, 3, 4},
+					FOpts: []Payload{
+						&DataPayload{Bytes: []byte{0x06}},
+					},
+				},
+			},
+		}
+
+		Convey("Then MarshalJSONWithOptions decodes the LoRaWAN 1.0 FOpts", func() {
+			out, err := phy.MarshalJSONWithOptions(JSONOptions{})
+			So(err, ShouldBeNil)
+			So(string(out), ShouldContainSubstring, `"fOpts":[{"cid":"DevStatusReq","payload":null}]`)
+		})
+
+		Convey("Then the LoRaWAN 1.1 FOpts are left encrypted when the NwkSEncKey is not set", func() {
+			out, err := phy.MarshalJSONWithOptions(JSONOptions{MACVersion: LoRaWAN1_1})
+			So(err, ShouldBeNil)
+			So(string(out), ShouldContainSubstring, `"fOpts":[{"bytes":"Bg=="}]`)
+		})
+	})
+}
+
+func TestABPActivationJSON(t *testing.T) {
+	assert := require.New(t)
+
+	abp10 := ABPActivation{
+		MACVersion:   LoRaWAN1_0,
+		DevAddr:      DevAddr{1, 2, 3, 4},
+		NwkSKey:      AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8},
+		AppSKey:      AES128Key{8, 7, 6, 5, 4, 3, 2, 1, 8, 7, 6, 5, 4, 3, 2, 1},
+		FCntUp:       10,
+		NFCntDown:    5,
+		RX1DROffset:  1,
+		RX2DataRate:  3,
+		RX2Frequency: 869525000,
+		RXDelay:      1,
+	}
+
+	b, err := json.Marshal(abp10)
+	assert.NoError(err)
+	assert.Equal(`{"macVersion":0,"devAddr":"01020304","nwkSKey":"01020304050607080102030405060708","fNwkSIntKey":"00000000000000000000000000000000","sNwkSIntKey":"00000000000000000000000000000000","nwkSEncKey":"00000000000000000000000000000000","appSKey":"08070605040302010807060504030201","fCntUp":10,"nFCntDown":5,"aFCntDown":0,"rx1DROffset":1,"rx2DataRate":3,"rx2Frequency":869525000,"rxDelay":1}`, string(b))
+
+	var out ABPActivation
+	assert.NoError(json.Unmarshal(b, &out))
+	assert.Equal(abp10, out)
+
+	_, err = json.Marshal(ABPActivation{})
+	assert.Error(err)
+	assert.EqualError(json.Unmarshal([]byte(`{"macVersion":1}`), &out), "lorawan: FNwkSIntKey, SNwkSIntKey and NwkSEncKey must be set for LoRaWAN 1.1")
+}
+
+func TestMACCommandQueueJSON(t *testing.T) {
+	for _, tst := range macCommandQueueTests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := json.Marshal(tst.Queue)
+			assert.NoError(err)
+
+			var q MACCommandQueue
+			assert.NoError(json.Unmarshal(b, &q))
+			assert.Equal(tst.Queue, q)
+		})
+	}
+}
+
+func TestMACCommandUnmarshalJSONWithDirection(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Uplink        bool
+		JSON          string
+		Expected      MACCommand
+		ExpectedError string
+	}{
+		{
+			Name:     "uplink LinkCheckReq",
+			Uplink:   true,
+			JSON:     `{"cid": "LinkCheckReq", "payload": null}`,
+			Expected: MACCommand{CID: LinkCheckReq},
+		},
+		{
+			Name:     "downlink LinkCheckAns",
+			JSON:     `{"cid": "LinkCheckAns", "payload": {"margin": 10, "gwCnt": 2}}`,
+			Expected: MACCommand{CID: LinkCheckAns, Payload: &LinkCheckAnsPayload{Margin: 10, GwCnt: 2}},
+		},
+		{
+			Name:     "uplink LinkADRAns using Req name",
+			Uplink:   true,
+			JSON:     `{"cid": "LinkADRReq", "payload": {"channelMaskAck": true, "dataRateAck": true, "powerAck": false}}`,
+			Expected: MACCommand{CID: LinkADRAns, Payload: &LinkADRAnsPayload{ChannelMaskACK: true, DataRateACK: true}},
+		},
+		{
+			Name:     "proprietary",
+			JSON:     `{"cid": "CID(255)", "payload": {"bytes": "AQI="}}`,
+			Expected: MACCommand{CID: 0xff, Payload: &ProprietaryMACCommandPayload{Bytes: []byte{1, 2}}},
+		},
+		{
+			Name:          "invalid cid",
+			JSON:          `{"cid": "Foo"}`,
+			ExpectedError: "lorawan: invalid CID Foo",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			var mac MACCommand
+			err := mac.UnmarshalJSONWithDirection(tst.Uplink, []byte(tst.JSON))
+			if tst.ExpectedError != "" {
+				assert.EqualError(err, tst.ExpectedError)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tst.Expected, mac)
+		})
+	}
+}
+
+func TestMACCommandMarshalJSONWithDirection(t *testing.T) {
+	assert := require.New(t)
+
+	mac := MACCommand{CID: LinkCheckAns, Payload: &LinkCheckAnsPayload{Margin: 10, GwCnt: 2}}
+	b, err := mac.MarshalJSONWithDirection(false)
+	assert.NoError(err)
+	assert.JSONEq(`{"cid": "LinkCheckAns", "payload": {"margin": 10, "gwCnt": 2}}`, string(b))
+
+	b, err = MACCommand{CID: LinkCheckReq}.MarshalJSONWithDirection(true)
+	assert.NoError(err)
+	assert.JSONEq(`{"cid": "LinkCheckReq", "payload": null}`, string(b))
+}
@@ -0,0 +1,148 @@
+package lorawan
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxFOptsLen defines the max. number of FOpts bytes.
+const MaxFOptsLen = 15
+
+// MACCommands represents a slice of mac-commands, e.g. the mac-commands
+// pending to be sent to a device.
+type MACCommands []MACCommand
+
+// MarshalBinary marshals the mac-commands in binary form (the concatenation
+// of the encoded mac-commands, as sent in the FOpts or FRMPayload).
+func (m MACCommands) MarshalBinary() ([]byte, error) {
+	var out []byte
+	for _, mac := range m {
+		b, err := mac.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary decodes the mac-commands from binary form. Unlike
+// PHYPayload.DecodeFOptsToMACCommands, it returns an error for the first
+// mac-command which could not be decoded.
+func (m *MACCommands) UnmarshalBinary(uplink bool, data []byte) error {
+	pls, err := decodeDataPayloadToMACCommands(uplink, []Payload{&DataPayload{Bytes: data}}, DecodeOptions{MACCommandDecodeMode: MACCommandDecodeStrict})
+	if err != nil {
+		return err
+	}
+
+	out := make(MACCommands, 0, len(pls))
+	for _, pl := range pls {
+		out = append(out, *pl.(*MACCommand))
+	}
+	*m = out
+
+	return nil
+}
+
+// Size returns the total encoded size (in bytes) of the mac-commands.
+func (m MACCommands) Size() (int, error) {
+	var size int
+	for _, mac := range m {
+		s, err := mac.size()
+		if err != nil {
+			return 0, err
+		}
+		size += s
+	}
+	return size, nil
+}
+
+// Payloads returns the mac-commands as a slice of Payload, which can be used
+// as FOpts or FRMPayload.
+func (m MACCommands) Payloads() []Payload {
+	out := make([]Payload, 0, len(m))
+	for i := range m {
+		mac := m[i]
+		out = append(out, &mac)
+	}
+	return out
+}
+
+// Batches splits the mac-commands into consecutive batches of which the
+// encoded size does not exceed maxSize bytes. The order of the mac-commands
+// is retained. An error is returned when a single mac-command exceeds
+// maxSize.
+func (m MACCommands) Batches(maxSize int) ([]MACCommands, error) {
+	var out []MACCommands
+	var batch MACCommands
+	var batchSize int
+
+	for _, mac := range m {
+		s, err := mac.size()
+		if err != nil {
+			return nil, err
+		}
+		if s > maxSize {
+			return nil, fmt.Errorf("lorawan: size of mac-command %s (%d bytes) exceeds the max. size of %d bytes", mac.CID, s, maxSize)
+		}
+
+		if batchSize+s > maxSize {
+			out = append(out, batch)
+			batch = nil
+			batchSize = 0
+		}
+
+		batch = append(batch, mac)
+		batchSize += s
+	}
+
+	if len(batch) != 0 {
+		out = append(out, batch)
+	}
+
+	return out, nil
+}
+
+// Split splits the mac-commands into the mac-commands to send as FOpts
+// (max. MaxFOptsLen bytes) and the batches to send as FRMPayload (FPort = 0)
+// of max. maxFRMPayloadSize bytes each, e.g. the max. FRMPayload size of the
+// data-rate (the N value of band.MaxPayloadSize). The order of the
+// mac-commands is retained, meaning that all mac-commands after the first
+// mac-command which does not fit in the FOpts are sent as FRMPayload.
+func (m MACCommands) Split(maxFRMPayloadSize int) (MACCommands, []MACCommands, error) {
+	if maxFRMPayloadSize <= 0 {
+		return nil, nil, errors.New("lorawan: max. FRMPayload size must be greater than 0")
+	}
+
+	var fOpts MACCommands
+	var fOptsSize int
+
+	for i, mac := range m {
+		s, err := mac.size()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if fOptsSize+s > MaxFOptsLen {
+			frmPayload, err := m[i:].Batches(maxFRMPayloadSize)
+			if err != nil {
+				return nil, nil, err
+			}
+			return fOpts, frmPayload, nil
+		}
+
+		fOpts = append(fOpts, mac)
+		fOptsSize += s
+	}
+
+	return fOpts, nil, nil
+}
+
+// size returns the encoded size (in bytes) of the mac-command.
+func (m MACCommand) size() (int, error) {
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,164 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMACCommands(t *testing.T) {
+	assert := require.New(t)
+
+	macs := MACCommands{
+		{CID: DevStatusReq},
+		{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 5}},
+		{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 5, TXPower: 1, ChMask: ChMask{true, true, true}, Redundancy: Redundancy{NbRep: 1}}},
+	}
+
+	size, err := macs.Size()
+	assert.NoError(err)
+	assert.Equal(8, size)
+
+	b, err := macs.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal([]byte{0x06, 0x08, 0x05, 0x03, 0x51, 0x07, 0x00, 0x01}, b)
+
+	var out MACCommands
+	assert.NoError(out.UnmarshalBinary(false, b))
+	assert.Equal(macs, out)
+
+	assert.Equal([]Payload{&macs[0], &macs[1], &macs[2]}, macs.Payloads())
+
+	t.Run("invalid mac-command", func(t *testing.T) {
+		assert := require.New(t)
+
+		macs := MACCommands{
+			{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 16}},
+		}
+
+		_, err := macs.Size()
+		assert.EqualError(err, "lorawan: the max value of Delay is 15")
+
+		_, err = macs.MarshalBinary()
+		assert.EqualError(err, "lorawan: the max value of Delay is 15")
+	})
+}
+
+func TestMACCommandsBatches(t *testing.T) {
+	// 1 + 5 + 2 + 5 + 1 bytes
+	macs := MACCommands{
+		{CID: DevStatusReq},
+		{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 5}},
+		{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 5}},
+		{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 3}},
+		{CID: DevStatusReq},
+	}
+
+	tests := []struct {
+		Name            string
+		MaxSize         int
+		ExpectedBatches []MACCommands
+		ExpectedError   string
+	}{
+		{
+			Name:            "single batch",
+			MaxSize:         14,
+			ExpectedBatches: []MACCommands{macs},
+		},
+		{
+			Name:            "two batches",
+			MaxSize:         8,
+			ExpectedBatches: []MACCommands{macs[0:3], macs[3:5]},
+		},
+		{
+			Name:            "batch per mac-command",
+			MaxSize:         5,
+			ExpectedBatches: []MACCommands{macs[0:1], macs[1:2], macs[2:3], macs[3:4], macs[4:5]},
+		},
+		{
+			Name:          "mac-command exceeds max size",
+			MaxSize:       4,
+			ExpectedError: "lorawan: size of mac-command LinkADRReq (5 bytes) exceeds the max. size of 4 bytes",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			batches, err := macs.Batches(tst.MaxSize)
+			if tst.ExpectedError != "" {
+				assert.EqualError(err, tst.ExpectedError)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tst.ExpectedBatches, batches)
+		})
+	}
+}
+
+func TestMACCommandsSplit(t *testing.T) {
+	// 5 + 5 + 2 + 5 + 1 bytes
+	macs := MACCommands{
+		{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 5}},
+		{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 4}},
+		{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 5}},
+		{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 3}},
+		{CID: DevStatusReq},
+	}
+
+	tests := []struct {
+		Name               string
+		MACCommands        MACCommands
+		MaxFRMPayloadSize  int
+		ExpectedFOpts      MACCommands
+		ExpectedFRMPayload []MACCommands
+		ExpectedError      string
+	}{
+		{
+			Name:              "empty",
+			MaxFRMPayloadSize: 51,
+		},
+		{
+			Name:              "all in FOpts",
+			MACCommands:       macs[0:3],
+			MaxFRMPayloadSize: 51,
+			ExpectedFOpts:     macs[0:3],
+		},
+		{
+			Name:               "remaining in single FRMPayload",
+			MACCommands:        macs,
+			MaxFRMPayloadSize:  51,
+			ExpectedFOpts:      macs[0:3],
+			ExpectedFRMPayload: []MACCommands{macs[3:5]},
+		},
+		{
+			Name:               "remaining in multiple FRMPayloads",
+			MACCommands:        macs,
+			MaxFRMPayloadSize:  5,
+			ExpectedFOpts:      macs[0:3],
+			ExpectedFRMPayload: []MACCommands{macs[3:4], macs[4:5]},
+		},
+		{
+			Name:              "invalid max FRMPayload size",
+			MACCommands:       macs,
+			MaxFRMPayloadSize: 0,
+			ExpectedError:     "lorawan: max. FRMPayload size must be greater than 0",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			fOpts, frmPayload, err := tst.MACCommands.Split(tst.MaxFRMPayloadSize)
+			if tst.ExpectedError != "" {
+				assert.EqualError(err, tst.ExpectedError)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tst.ExpectedFOpts, fOpts)
+			assert.Equal(tst.ExpectedFRMPayload, frmPayload)
+		})
+	}
+}
@@ -0,0 +1,536 @@
+package lorawan
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// FieldDescription describes a single field of a mac-command payload.
+type FieldDescription struct {
+	Name        string      `json:"name"`
+	Value       interface{} `json:"value"`
+	Unit        string      `json:"unit,omitempty"`
+	Description string      `json:"description,omitempty"` // human readable interpretation of the value
+}
+
+// MACCommandPayloadDescriber is implemented by the mac-command payloads
+// that are able to describe their fields.
+type MACCommandPayloadDescriber interface {
+	Describe() []FieldDescription
+}
+
+// MACCommandDescription describes a mac-command and its payload.
+type MACCommandDescription struct {
+	CID    CID                `json:"cid"`
+	Name   string             `json:"name"`
+	Fields []FieldDescription `json:"fields"`
+}
+
+// macCommandNames contains the (per direction) mac-command names.
+var macCommandNames = map[bool]map[CID]string{
+	true: {
+		ResetInd:             "ResetInd",
+		LinkCheckReq:         "LinkCheckReq",
+		LinkADRAns:           "LinkADRAns",
+		DutyCycleAns:         "DutyCycleAns",
+		RXParamSetupAns:      "RXParamSetupAns",
+		DevStatusAns:         "DevStatusAns",
+		NewChannelAns:        "NewChannelAns",
+		RXTimingSetupAns:     "RXTimingSetupAns",
+		TXParamSetupAns:      "TXParamSetupAns",
+		DLChannelAns:         "DLChannelAns",
+		RekeyInd:             "RekeyInd",
+		ADRParamSetupAns:     "ADRParamSetupAns",
+		DeviceTimeReq:        "DeviceTimeReq",
+		RejoinParamSetupAns:  "RejoinParamSetupAns",
+		PingSlotInfoReq:      "PingSlotInfoReq",
+		PingSlotChannelAns:   "PingSlotChannelAns",
+		BeaconFreqAns:        "BeaconFreqAns",
+		DeviceModeInd:        "DeviceModeInd",
+		RelayConfAns:         "RelayConfAns",
+		EndDeviceConfAns:     "EndDeviceConfAns",
+		FilterListAns:        "FilterListAns",
+		UpdateUplinkListAns:  "UpdateUplinkListAns",
+		CtrlUplinkListAns:    "CtrlUplinkListAns",
+		ConfigureFwdLimitAns: "ConfigureFwdLimitAns",
+	},
+	false: {
+		ResetConf:            "ResetConf",
+		LinkCheckAns:         "LinkCheckAns",
+		LinkADRReq:           "LinkADRReq",
+		DutyCycleReq:         "DutyCycleReq",
+		RXParamSetupReq:      "RXParamSetupReq",
+		DevStatusReq:         "DevStatusReq",
+		NewChannelReq:        "NewChannelReq",
+		RXTimingSetupReq:     "RXTimingSetupReq",
+		TXParamSetupReq:      "TXParamSetupReq",
+		DLChannelReq:         "DLChannelReq",
+		RekeyConf:            "RekeyConf",
+		ADRParamSetupReq:     "ADRParamSetupReq",
+		DeviceTimeAns:        "DeviceTimeAns",
+		ForceRejoinReq:       "ForceRejoinReq",
+		RejoinParamSetupReq:  "RejoinParamSetupReq",
+		PingSlotInfoAns:      "PingSlotInfoAns",
+		PingSlotChannelReq:   "PingSlotChannelReq",
+		BeaconFreqReq:        "BeaconFreqReq",
+		DeviceModeConf:       "DeviceModeConf",
+		RelayConfReq:         "RelayConfReq",
+		EndDeviceConfReq:     "EndDeviceConfReq",
+		FilterListReq:        "FilterListReq",
+		UpdateUplinkListReq:  "UpdateUplinkListReq",
+		CtrlUplinkListReq:    "CtrlUplinkListReq",
+		ConfigureFwdLimitReq: "ConfigureFwdLimitReq",
+	},
+}
+
+// StringWithDirection returns the name of the CID for the given direction,
+// e.g. LinkADRAns for uplink and LinkADRReq for downlink. Note that String
+// (and MarshalText) can not make this distinction, as the *Req and *Ans
+// CIDs share the same value. It falls back to String for unknown CIDs.
+func (c CID) StringWithDirection(uplink bool) string {
+	if name, ok := macCommandNames[uplink][c]; ok {
+		return name
+	}
+	return c.String()
+}
+
+// DescribeMACCommand returns the description of the given mac-command.
+// As the name and payload of a mac-command depend on the direction, uplink
+// must be set for uplink mac-commands. The fields are only set when the
+// payload implements MACCommandPayloadDescriber.
+func DescribeMACCommand(uplink bool, mac MACCommand) MACCommandDescription {
+	out := MACCommandDescription{
+		CID:  mac.CID,
+		Name: macCommandNames[uplink][mac.CID],
+	}
+
+	if out.Name == "" {
+		if mac.CID >= 0x80 {
+			out.Name = "Proprietary"
+		} else {
+			out.Name = fmt.Sprintf("Unknown(%s)", mac.CID)
+		}
+	}
+
+	if d, ok := mac.Payload.(MACCommandPayloadDescriber); ok {
+		out.Fields = d.Describe()
+	}
+
+	return out
+}
+
+func describeACK(name string, ack bool) FieldDescription {
+	d := FieldDescription{Name: name, Value: ack, Description: "rejected"}
+	if ack {
+		d.Description = "accepted"
+	}
+	return d
+}
+
+func describeFrequency(name string, freq uint32, zero string) FieldDescription {
+	d := FieldDescription{Name: name, Value: freq, Unit: "Hz", Description: fmt.Sprintf("%.6g MHz", float64(freq)/1000000)}
+	if freq == 0 {
+		d.Description = zero
+	}
+	return d
+}
+
+func describeVersion(name string, v Version) FieldDescription {
+	return FieldDescription{Name: name, Value: v.Minor, Description: fmt.Sprintf("LoRaWAN 1.%d", v.Minor)}
+}
+
+func describeDwellTime(name string, dt DwellTime) FieldDescription {
+	d := FieldDescription{Name: name, Value: int(dt), Description: "no limit"}
+	if dt == DwellTime400ms {
+		d.Description = "400 ms"
+	}
+	return d
+}
+
+func describeDeviceModeClass(c DeviceModeClass) FieldDescription {
+	d := FieldDescription{Name: "Class", Value: uint8(c), Description: "RFU"}
+	switch c {
+	case DeviceModeClassA:
+		d.Description = "Class A"
+	case DeviceModeClassC:
+		d.Description = "Class C"
+	}
+	return d
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p ProprietaryMACCommandPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "Bytes", Value: hex.EncodeToString(p.Bytes)},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RawMACCommandPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "Bytes", Value: hex.EncodeToString(p.Bytes)},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p LinkCheckAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "Margin", Value: p.Margin, Unit: "dB", Description: "link margin above the demodulation floor"},
+		{Name: "GwCnt", Value: p.GwCnt, Description: "number of gateways that received the LinkCheckReq"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p LinkADRReqPayload) Describe() []FieldDescription {
+	var channels []string
+	for i, enabled := range p.ChMask {
+		if enabled {
+			channels = append(channels, fmt.Sprintf("%d", i))
+		}
+	}
+
+	return []FieldDescription{
+		{Name: "DataRate", Value: p.DataRate, Description: "data-rate index"},
+		{Name: "TXPower", Value: p.TXPower, Description: "tx power index"},
+		{Name: "ChMask", Value: p.ChMask, Description: "enabled channels: " + strings.Join(channels, ", ")},
+		{Name: "ChMaskCntl", Value: p.Redundancy.ChMaskCntl, Description: "channel mask control"},
+		{Name: "NbRep", Value: p.Redundancy.NbRep, Description: "number of transmissions of each uplink"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p LinkADRAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("ChannelMaskACK", p.ChannelMaskACK),
+		describeACK("DataRateACK", p.DataRateACK),
+		describeACK("PowerACK", p.PowerACK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p DutyCycleReqPayload) Describe() []FieldDescription {
+	d := FieldDescription{Name: "MaxDCycle", Value: p.MaxDCycle}
+	switch {
+	case p.IsSilenced():
+		d.Description = "silenced"
+	case p.MaxDCycle > 15:
+		d.Description = "RFU"
+	default:
+		d.Description = fmt.Sprintf("max. aggregated duty-cycle %g%%", p.DutyCyclePercent())
+	}
+	return []FieldDescription{d}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RXParamSetupReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeFrequency("Frequency", p.Frequency, ""),
+		{Name: "RX2DataRate", Value: p.DLSettings.RX2DataRate, Description: "RX2 data-rate index"},
+		{Name: "RX1DROffset", Value: p.DLSettings.RX1DROffset, Description: "RX1 data-rate offset"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RXParamSetupAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("ChannelACK", p.ChannelACK),
+		describeACK("RX2DataRateACK", p.RX2DataRateACK),
+		describeACK("RX1DROffsetACK", p.RX1DROffsetACK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p DevStatusAnsPayload) Describe() []FieldDescription {
+	battery := FieldDescription{Name: "Battery", Value: p.Battery}
+	switch p.Battery {
+	case 0:
+		battery.Description = "external power source"
+	case 255:
+		battery.Description = "unable to measure the battery level"
+	default:
+		battery.Description = fmt.Sprintf("%.0f%%", float64(p.Battery)/254*100)
+	}
+
+	return []FieldDescription{
+		battery,
+		{Name: "Margin", Value: p.Margin, Unit: "dB", Description: "demodulation SNR margin of the last DevStatusReq"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p NewChannelReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "ChIndex", Value: p.ChIndex, Description: "channel index"},
+		describeFrequency("Freq", p.Freq, "channel disabled"),
+		{Name: "MinDR", Value: p.MinDR, Description: "min. data-rate index"},
+		{Name: "MaxDR", Value: p.MaxDR, Description: "max. data-rate index"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p NewChannelAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("ChannelFrequencyOK", p.ChannelFrequencyOK),
+		describeACK("DataRateRangeOK", p.DataRateRangeOK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RXTimingSetupReqPayload) Describe() []FieldDescription {
+	delay := p.Delay
+	if delay == 0 {
+		delay = 1
+	}
+	return []FieldDescription{
+		{Name: "Delay", Value: p.Delay, Description: fmt.Sprintf("RX1 delay of %d seconds", delay)},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p TXParamSetupReqPayload) Describe() []FieldDescription {
+	eirp := FieldDescription{Name: "MaxEIRP", Value: p.MaxEIRP}
+	if v, err := GetTXParamSetupEIRP(p.MaxEIRP); err == nil {
+		eirp.Description = fmt.Sprintf("max. EIRP of %g dBm", v)
+	}
+
+	return []FieldDescription{
+		describeDwellTime("DownlinkDwellTime", p.DownlinkDwelltime),
+		describeDwellTime("UplinkDwellTime", p.UplinkDwellTime),
+		eirp,
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p DLChannelReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "ChIndex", Value: p.ChIndex, Description: "channel index"},
+		describeFrequency("Freq", p.Freq, ""),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p DLChannelAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("UplinkFrequencyExists", p.UplinkFrequencyExists),
+		describeACK("ChannelFrequencyOK", p.ChannelFrequencyOK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p PingSlotInfoReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "Periodicity", Value: p.Periodicity, Description: fmt.Sprintf("ping-slot every %d seconds", 1<<p.Periodicity)},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p BeaconFreqReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeFrequency("Frequency", p.Frequency, "default beacon frequency"),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p BeaconFreqAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("BeaconFrequencyOK", p.BeaconFrequencyOK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p PingSlotChannelReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeFrequency("Frequency", p.Frequency, "default ping-slot frequency"),
+		{Name: "DR", Value: p.DR, Description: "data-rate index"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p PingSlotChannelAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("DataRateOK", p.DataRateOK),
+		describeACK("ChannelFrequencyOK", p.ChannelFrequencyOK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p DeviceTimeAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "TimeSinceGPSEpoch", Value: p.TimeSinceGPSEpoch.Seconds(), Unit: "s", Description: "time since the GPS epoch"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p ResetIndPayload) Describe() []FieldDescription {
+	return []FieldDescription{describeVersion("DevLoRaWANVersion", p.DevLoRaWANVersion)}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p ResetConfPayload) Describe() []FieldDescription {
+	return []FieldDescription{describeVersion("ServLoRaWANVersion", p.ServLoRaWANVersion)}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RekeyIndPayload) Describe() []FieldDescription {
+	return []FieldDescription{describeVersion("DevLoRaWANVersion", p.DevLoRaWANVersion)}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RekeyConfPayload) Describe() []FieldDescription {
+	return []FieldDescription{describeVersion("ServLoRaWANVersion", p.ServLoRaWANVersion)}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p ADRParamSetupReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "LimitExp", Value: p.ADRParam.LimitExp, Description: fmt.Sprintf("ADR_ACK_LIMIT of %d uplinks", 1<<p.ADRParam.LimitExp)},
+		{Name: "DelayExp", Value: p.ADRParam.DelayExp, Description: fmt.Sprintf("ADR_ACK_DELAY of %d uplinks", 1<<p.ADRParam.DelayExp)},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p ForceRejoinReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "Period", Value: p.Period, Description: fmt.Sprintf("retransmission delay of %d seconds + random delay", 32*(1<<p.Period))},
+		{Name: "MaxRetries", Value: p.MaxRetries, Description: "max. number of retransmissions"},
+		{Name: "RejoinType", Value: p.RejoinType, Description: "rejoin-request type"},
+		{Name: "DR", Value: p.DR, Description: "data-rate index"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RejoinParamSetupReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "MaxTimeN", Value: p.MaxTimeN, Description: fmt.Sprintf("rejoin-request every %d seconds", 1<<(p.MaxTimeN+10))},
+		{Name: "MaxCountN", Value: p.MaxCountN, Description: fmt.Sprintf("rejoin-request every %d uplinks", 1<<(p.MaxCountN+4))},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RejoinParamSetupAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("TimeOK", p.TimeOK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p DeviceModeIndPayload) Describe() []FieldDescription {
+	return []FieldDescription{describeDeviceModeClass(p.Class)}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p DeviceModeConfPayload) Describe() []FieldDescription {
+	return []FieldDescription{describeDeviceModeClass(p.Class)}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RelayConfReqPayload) Describe() []FieldDescription {
+	c := p.ChannelSettingsRelay
+	return []FieldDescription{
+		{Name: "StartStop", Value: c.StartStop, Description: "1 = relay enabled"},
+		{Name: "CADPeriodicity", Value: c.CADPeriodicity, Description: "CAD periodicity index"},
+		{Name: "DefaultChIdx", Value: c.DefaultChIdx, Description: "default channel index"},
+		{Name: "SecondChIdx", Value: c.SecondChIdx, Description: "second channel index"},
+		{Name: "SecondChDR", Value: c.SecondChDR, Description: "second channel data-rate index"},
+		{Name: "SecondChAckOffset", Value: c.SecondChAckOffset, Description: "second channel ACK frequency offset index"},
+		describeFrequency("SecondChFreq", p.SecondChFreq, "second channel disabled"),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p RelayConfAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("SecondChAckOffsetACK", p.SecondChAckOffsetACK),
+		describeACK("SecondChDRACK", p.SecondChDRACK),
+		describeACK("SecondChIdxACK", p.SecondChIdxACK),
+		describeACK("DefaultChIdxACK", p.DefaultChIdxACK),
+		describeACK("CADPeriodicityACK", p.CADPeriodicityACK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p EndDeviceConfReqPayload) Describe() []FieldDescription {
+	c := p.ChannelSettingsED
+	return []FieldDescription{
+		{Name: "RelayModeActivation", Value: p.ActivationRelayMode.RelayModeActivation, Description: "relay mode activation"},
+		{Name: "SmartEnableLevel", Value: p.ActivationRelayMode.SmartEnableLevel, Description: "smart enable level"},
+		{Name: "Backoff", Value: c.Backoff, Description: "number of uplinks without relay before using the relay"},
+		{Name: "SecondChIdx", Value: c.SecondChIdx, Description: "second channel index"},
+		{Name: "SecondChDR", Value: c.SecondChDR, Description: "second channel data-rate index"},
+		{Name: "SecondChAckOffset", Value: c.SecondChAckOffset, Description: "second channel ACK frequency offset index"},
+		describeFrequency("SecondChFreq", p.SecondChFreq, "second channel disabled"),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p EndDeviceConfAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("SecondChAckOffsetACK", p.SecondChAckOffsetACK),
+		describeACK("SecondChDRACK", p.SecondChDRACK),
+		describeACK("SecondChIdxACK", p.SecondChIdxACK),
+		describeACK("BackoffACK", p.BackoffACK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p FilterListReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "FilterListIdx", Value: p.FilterListIdx, Description: "filter-list rule index"},
+		{Name: "FilterListAction", Value: p.FilterListAction, Description: "0 = no rule, 1 = forward, 2 = filter"},
+		{Name: "JoinEUI", Value: p.JoinEUI.String()},
+		{Name: "DevEUI", Value: p.DevEUI.String()},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p FilterListAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("FilterListActionACK", p.FilterListActionACK),
+		describeACK("FilterListLenACK", p.FilterListLenACK),
+		describeACK("CombinedRulesACK", p.CombinedRulesACK),
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p UpdateUplinkListReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "UplinkListIdx", Value: p.UplinkListIdx, Description: "uplink-list index"},
+		{Name: "BucketSize", Value: p.UplinkLimit.BucketSize, Description: "token-bucket size factor"},
+		{Name: "ReloadRate", Value: p.UplinkLimit.ReloadRate, Description: "tokens per hour"},
+		{Name: "DevAddr", Value: p.DevAddr.String()},
+		{Name: "WFCnt", Value: p.WFCnt, Description: "WOR frame-counter"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p CtrlUplinkListReqPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		{Name: "UplinkListIdx", Value: p.UplinkListIdx, Description: "uplink-list index"},
+		{Name: "CtrlUplinkAction", Value: p.CtrlUplinkAction, Description: "0 = read WFCnt, 1 = remove"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p CtrlUplinkListAnsPayload) Describe() []FieldDescription {
+	return []FieldDescription{
+		describeACK("UplinkListIdxACK", p.UplinkListIdxACK),
+		{Name: "WFCnt", Value: p.WFCnt, Description: "WOR frame-counter"},
+	}
+}
+
+// Describe implements the MACCommandPayloadDescriber interface.
+func (p ConfigureFwdLimitReqPayload) Describe() []FieldDescription {
+	rr, lc := p.ReloadRate, p.LoadCapacity
+	return []FieldDescription{
+		{Name: "OverallReloadRate", Value: rr.OverallReloadRate, Description: "tokens per hour"},
+		{Name: "GlobalUplinkReloadRate", Value: rr.GlobalUplinkReloadRate, Description: "tokens per hour"},
+		{Name: "NotifyReloadRate", Value: rr.NotifyReloadRate, Description: "tokens per hour"},
+		{Name: "JoinReqReloadRate", Value: rr.JoinReqReloadRate, Description: "tokens per hour"},
+		{Name: "ResetLimitCounter", Value: rr.ResetLimitCounter},
+		{Name: "OverallLimitSize", Value: lc.OverallLimitSize, Description: "token-bucket size factor"},
+		{Name: "GlobalUplinkLimitSize", Value: lc.GlobalUplinkLimitSize, Description: "token-bucket size factor"},
+		{Name: "NotifyLimitSize", Value: lc.NotifyLimitSize, Description: "token-bucket size factor"},
+		{Name: "JoinReqLimitSize", Value: lc.JoinReqLimitSize, Description: "token-bucket size factor"},
+	}
+}
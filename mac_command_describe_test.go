@@ -0,0 +1,118 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeMACCommand(t *testing.T) {
+	t.Run("All payloads implement MACCommandPayloadDescriber", func(t *testing.T) {
+		for _, v := range macCommandVectors {
+			t.Run(v.Name, func(t *testing.T) {
+				assert := require.New(t)
+
+				d := DescribeMACCommand(v.Uplink, v.MACCommand)
+				assert.Equal(v.Name, d.Name)
+				if v.MACCommand.Payload != nil {
+					assert.NotEmpty(d.Fields)
+				}
+			})
+		}
+	})
+
+	tests := []struct {
+		Name     string
+		Uplink   bool
+		Command  MACCommand
+		Expected MACCommandDescription
+	}{
+		{
+			Name:    "DevStatusAns",
+			Uplink:  true,
+			Command: MACCommand{CID: DevStatusAns, Payload: &DevStatusAnsPayload{Battery: 127, Margin: 10}},
+			Expected: MACCommandDescription{
+				CID:  DevStatusAns,
+				Name: "DevStatusAns",
+				Fields: []FieldDescription{
+					{Name: "Battery", Value: uint8(127), Description: "50%"},
+					{Name: "Margin", Value: int8(10), Unit: "dB", Description: "demodulation SNR margin of the last DevStatusReq"},
+				},
+			},
+		},
+		{
+			Name:    "DutyCycleReq",
+			Command: MACCommand{CID: DutyCycleReq, Payload: &DutyCycleReqPayload{MaxDCycle: 2}},
+			Expected: MACCommandDescription{
+				CID:  DutyCycleReq,
+				Name: "DutyCycleReq",
+				Fields: []FieldDescription{
+					{Name: "MaxDCycle", Value: uint8(2), Description: "max. aggregated duty-cycle 25%"},
+				},
+			},
+		},
+		{
+			Name:    "NewChannelReq disable",
+			Command: MACCommand{CID: NewChannelReq, Payload: &NewChannelReqPayload{ChIndex: 3}},
+			Expected: MACCommandDescription{
+				CID:  NewChannelReq,
+				Name: "NewChannelReq",
+				Fields: []FieldDescription{
+					{Name: "ChIndex", Value: uint8(3), Description: "channel index"},
+					{Name: "Freq", Value: uint32(0), Unit: "Hz", Description: "channel disabled"},
+					{Name: "MinDR", Value: uint8(0), Description: "min. data-rate index"},
+					{Name: "MaxDR", Value: uint8(0), Description: "max. data-rate index"},
+				},
+			},
+		},
+		{
+			Name:    "LinkCheckReq",
+			Uplink:  true,
+			Command: MACCommand{CID: LinkCheckReq},
+			Expected: MACCommandDescription{
+				CID:  LinkCheckReq,
+				Name: "LinkCheckReq",
+			},
+		},
+		{
+			Name:    "Proprietary",
+			Uplink:  true,
+			Command: MACCommand{CID: 0x80, Payload: &ProprietaryMACCommandPayload{Bytes: []byte{1, 2}}},
+			Expected: MACCommandDescription{
+				CID:  0x80,
+				Name: "Proprietary",
+				Fields: []FieldDescription{
+					{Name: "Bytes", Value: "0102"},
+				},
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.Expected, DescribeMACCommand(tst.Uplink, tst.Command))
+		})
+	}
+}
+
+func TestCIDStringWithDirection(t *testing.T) {
+	tests := []struct {
+		CID      CID
+		Uplink   bool
+		Expected string
+	}{
+		{LinkADRAns, true, "LinkADRAns"},
+		{LinkADRReq, false, "LinkADRReq"},
+		{ResetInd, true, "ResetInd"},
+		{ResetConf, false, "ResetConf"},
+		{0x80, true, "CID(128)"},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Expected, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.Expected, tst.CID.StringWithDirection(tst.Uplink))
+		})
+	}
+}
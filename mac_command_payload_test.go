@@ -69,6 +69,12 @@ func (ts MACCommandPayloadTestSuite) TestDeviceModeIndClass() {
 			},
 			Bytes: []byte{0x02},
 		},
+		{
+			Payload: &DeviceModeIndPayload{
+				Class: DeviceModeRFU,
+			},
+			Error: errors.New("lorawan: Class must be DeviceModeClassA or DeviceModeClassC, got DeviceModeRFU"),
+		},
 	}
 
 	ts.run(func() MACCommandPayload { return &DeviceModeIndPayload{} }, tests)
@@ -96,6 +102,12 @@ func (ts MACCommandPayloadTestSuite) TestDeviceModeConfClass() {
 			},
 			Bytes: []byte{0x02},
 		},
+		{
+			Payload: &DeviceModeConfPayload{
+				Class: DeviceModeRFU,
+			},
+			Error: errors.New("lorawan: Class must be DeviceModeClassA or DeviceModeClassC, got DeviceModeRFU"),
+		},
 	}
 
 	ts.run(func() MACCommandPayload { return &DeviceModeConfPayload{} }, tests)
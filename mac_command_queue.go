@@ -0,0 +1,105 @@
+package lorawan
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MACCommandQueue represents a queue of (pending) mac-commands for a single
+// direction. As the payload of a mac-command depends on the direction, the
+// direction is stored together with the mac-commands so that the typed
+// payloads can be restored (e.g. when storing the queue in a database).
+type MACCommandQueue struct {
+	Uplink   bool
+	Commands []MACCommand
+}
+
+// MarshalBinary marshals the object in binary form.
+// The first byte contains the direction (0x01 = uplink, 0x00 = downlink),
+// followed by each mac-command prefixed by its length (1 byte).
+func (q MACCommandQueue) MarshalBinary() ([]byte, error) {
+	out := []byte{0x00}
+	if q.Uplink {
+		out[0] = 0x01
+	}
+
+	for _, mac := range q.Commands {
+		b, err := mac.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(b) > 255 {
+			return nil, fmt.Errorf("lorawan: max size of mac-command %s exceeded", mac.CID)
+		}
+
+		out = append(out, byte(len(b)))
+		out = append(out, b...)
+	}
+
+	return out, nil
+}
+
+// UnmarshalBinary decodes the object from binary form.
+func (q *MACCommandQueue) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("lorawan: at least 1 byte of data is expected")
+	}
+
+	if data[0] > 0x01 {
+		return errors.New("lorawan: invalid direction byte")
+	}
+
+	q.Uplink = data[0] == 0x01
+	q.Commands = nil
+
+	for i := 1; i < len(data); {
+		size := int(data[i])
+		i++
+
+		if size == 0 || len(data[i:]) < size {
+			return errors.New("lorawan: not enough remaining bytes")
+		}
+
+		payload, err := newMACCommandQueuePayload(q.Uplink, CID(data[i]), size > 1)
+		if err != nil {
+			return err
+		}
+
+		mac := MACCommand{
+			CID:     CID(data[i]),
+			Payload: payload,
+		}
+
+		if mac.Payload != nil {
+			if err := mac.Payload.UnmarshalBinary(data[i+1 : i+size]); err != nil {
+				return err
+			}
+		}
+
+		q.Commands = append(q.Commands, mac)
+		i += size
+	}
+
+	return nil
+}
+
+// newMACCommandQueuePayload returns a new MACCommandPayload for the given
+// direction and CID. For proprietary mac-commands that have not been
+// registered, a ProprietaryMACCommandPayload is returned. For other unknown
+// CIDs (see UnknownCIDRaw), a RawMACCommandPayload is returned.
+func newMACCommandQueuePayload(uplink bool, cid CID, hasPayload bool) (MACCommandPayload, error) {
+	if !hasPayload {
+		return nil, nil
+	}
+
+	p, _, err := GetMACPayloadAndSize(uplink, cid)
+	if err != nil {
+		if cid >= 128 {
+			return &ProprietaryMACCommandPayload{}, nil
+		}
+		return &RawMACCommandPayload{}, nil
+	}
+
+	return p, nil
+}
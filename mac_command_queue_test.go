@@ -0,0 +1,97 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var macCommandQueueTests = []struct {
+	Name  string
+	Queue MACCommandQueue
+	Bytes []byte
+}{
+	{
+		Name:  "empty downlink queue",
+		Queue: MACCommandQueue{},
+		Bytes: []byte{0x00},
+	},
+	{
+		Name: "downlink mac-commands",
+		Queue: MACCommandQueue{
+			Commands: []MACCommand{
+				{
+					CID: LinkADRReq,
+					Payload: &LinkADRReqPayload{
+						DataRate: 3,
+						TXPower:  2,
+						ChMask:   ChMask{true, true, true},
+						Redundancy: Redundancy{
+							NbRep: 1,
+						},
+					},
+				},
+				{CID: DevStatusReq},
+				{
+					CID: RXParamSetupReq,
+					Payload: &RXParamSetupReqPayload{
+						Frequency:  869525000,
+						DLSettings: DLSettings{RX2DataRate: 3, RX1DROffset: 1},
+					},
+				},
+			},
+		},
+		Bytes: []byte{0x00, 0x05, 0x03, 0x32, 0x07, 0x00, 0x01, 0x01, 0x06, 0x05, 0x05, 0x13, 0xd2, 0xad, 0x84},
+	},
+	{
+		Name: "uplink mac-commands",
+		Queue: MACCommandQueue{
+			Uplink: true,
+			Commands: []MACCommand{
+				{
+					CID:     DevStatusAns,
+					Payload: &DevStatusAnsPayload{Battery: 128, Margin: -5},
+				},
+				{CID: LinkCheckReq},
+			},
+		},
+		Bytes: []byte{0x01, 0x03, 0x06, 0x80, 0x3b, 0x01, 0x02},
+	},
+	{
+		Name: "proprietary mac-command",
+		Queue: MACCommandQueue{
+			Commands: []MACCommand{
+				{
+					CID:     CID(0x80),
+					Payload: &ProprietaryMACCommandPayload{Bytes: []byte{0x01, 0x02, 0x03}},
+				},
+			},
+		},
+		Bytes: []byte{0x00, 0x04, 0x80, 0x01, 0x02, 0x03},
+	},
+}
+
+func TestMACCommandQueue(t *testing.T) {
+	for _, tst := range macCommandQueueTests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := tst.Queue.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(tst.Bytes, b)
+
+			var q MACCommandQueue
+			assert.NoError(q.UnmarshalBinary(b))
+			assert.Equal(tst.Queue, q)
+		})
+	}
+
+	t.Run("invalid binary input", func(t *testing.T) {
+		assert := require.New(t)
+		var q MACCommandQueue
+
+		assert.Error(q.UnmarshalBinary(nil))
+		assert.Error(q.UnmarshalBinary([]byte{0x02}))
+		assert.Error(q.UnmarshalBinary([]byte{0x00, 0x05, 0x03}))
+	})
+}
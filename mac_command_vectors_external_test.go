@@ -0,0 +1,84 @@
+package lorawan_test
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/testvectors"
+)
+
+var updateVectors = flag.Bool("update-vectors", false, "update the mac-command test-vectors")
+
+const macCommandVectorsFile = "testvectors/mac_commands.json"
+
+func TestMACCommandVectors(t *testing.T) {
+	var generated []testvectors.MACCommand
+
+	for _, v := range lorawan.MACCommandVectors {
+		b, err := v.MACCommand.MarshalBinary()
+		require.NoError(t, err)
+
+		pl, err := json.Marshal(v.MACCommand.Payload)
+		require.NoError(t, err)
+
+		generated = append(generated, testvectors.MACCommand{
+			Name:    v.Name,
+			Uplink:  v.Uplink,
+			CID:     uint8(v.MACCommand.CID),
+			Payload: pl,
+			Bytes:   b,
+		})
+	}
+
+	if *updateVectors {
+		b, err := json.MarshalIndent(generated, "", "\t")
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(macCommandVectorsFile, append(b, '\n'), 0644))
+		return
+	}
+
+	vectors, err := testvectors.MACCommands()
+	require.NoError(t, err)
+
+	t.Run("Vectors are up-to-date", func(t *testing.T) {
+		assert := require.New(t)
+		assert.Equal(len(generated), len(vectors))
+
+		for i := range generated {
+			assert.Equal(generated[i].Name, vectors[i].Name)
+			assert.Equal(generated[i].Uplink, vectors[i].Uplink)
+			assert.Equal(generated[i].Bytes, vectors[i].Bytes)
+			assert.JSONEq(string(generated[i].Payload), string(vectors[i].Payload))
+		}
+	})
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			// decode the JSON payload into the typed payload
+			mac := lorawan.MACCommand{CID: lorawan.CID(v.CID)}
+			if string(v.Payload) != "null" {
+				pl, _, err := lorawan.GetMACPayloadAndSize(v.Uplink, mac.CID)
+				assert.NoError(err)
+				assert.NoError(json.Unmarshal(v.Payload, pl))
+				mac.Payload = pl
+			}
+
+			// encode
+			b, err := mac.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal([]byte(v.Bytes), b)
+
+			// decode
+			var decoded lorawan.MACCommand
+			assert.NoError(decoded.UnmarshalBinary(v.Uplink, v.Bytes))
+			assert.Equal(mac, decoded)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package lorawan
+
+import "time"
+
+// macCommandVectors contains the mac-commands from which the test-vectors are
+// generated (using go test -run TestMACCommandVectors -update-vectors).
+var macCommandVectors = []struct {
+	Uplink     bool
+	Name       string
+	MACCommand MACCommand
+}{
+	// uplink
+	{true, "ResetInd", MACCommand{CID: ResetInd, Payload: &ResetIndPayload{DevLoRaWANVersion: Version{Minor: 1}}}},
+	{true, "LinkCheckReq", MACCommand{CID: LinkCheckReq}},
+	{true, "LinkADRAns", MACCommand{CID: LinkADRAns, Payload: &LinkADRAnsPayload{ChannelMaskACK: true, DataRateACK: true, PowerACK: true}}},
+	{true, "LinkADRAns", MACCommand{CID: LinkADRAns, Payload: &LinkADRAnsPayload{ChannelMaskACK: true}}},
+	{true, "DutyCycleAns", MACCommand{CID: DutyCycleAns}},
+	{true, "RXParamSetupAns", MACCommand{CID: RXParamSetupAns, Payload: &RXParamSetupAnsPayload{ChannelACK: true, RX2DataRateACK: true, RX1DROffsetACK: true}}},
+	{true, "DevStatusAns", MACCommand{CID: DevStatusAns, Payload: &DevStatusAnsPayload{Battery: 254, Margin: -32}}},
+	{true, "DevStatusAns", MACCommand{CID: DevStatusAns, Payload: &DevStatusAnsPayload{Battery: 1, Margin: 31}}},
+	{true, "NewChannelAns", MACCommand{CID: NewChannelAns, Payload: &NewChannelAnsPayload{ChannelFrequencyOK: true, DataRateRangeOK: true}}},
+	{true, "RXTimingSetupAns", MACCommand{CID: RXTimingSetupAns}},
+	{true, "TXParamSetupAns", MACCommand{CID: TXParamSetupAns}},
+	{true, "DLChannelAns", MACCommand{CID: DLChannelAns, Payload: &DLChannelAnsPayload{UplinkFrequencyExists: true, ChannelFrequencyOK: true}}},
+	{true, "RekeyInd", MACCommand{CID: RekeyInd, Payload: &RekeyIndPayload{DevLoRaWANVersion: Version{Minor: 1}}}},
+	{true, "ADRParamSetupAns", MACCommand{CID: ADRParamSetupAns}},
+	{true, "DeviceTimeReq", MACCommand{CID: DeviceTimeReq}},
+	{true, "RejoinParamSetupAns", MACCommand{CID: RejoinParamSetupAns, Payload: &RejoinParamSetupAnsPayload{TimeOK: true}}},
+	{true, "PingSlotInfoReq", MACCommand{CID: PingSlotInfoReq, Payload: &PingSlotInfoReqPayload{Periodicity: 3}}},
+	{true, "PingSlotChannelAns", MACCommand{CID: PingSlotChannelAns, Payload: &PingSlotChannelAnsPayload{DataRateOK: true, ChannelFrequencyOK: true}}},
+	{true, "BeaconFreqAns", MACCommand{CID: BeaconFreqAns, Payload: &BeaconFreqAnsPayload{BeaconFrequencyOK: true}}},
+	{true, "DeviceModeInd", MACCommand{CID: DeviceModeInd, Payload: &DeviceModeIndPayload{Class: DeviceModeClassC}}},
+	{true, "RelayConfAns", MACCommand{CID: RelayConfAns, Payload: &RelayConfAnsPayload{SecondChAckOffsetACK: true, SecondChDRACK: true, SecondChIdxACK: true, DefaultChIdxACK: true, CADPeriodicityACK: true}}},
+	{true, "EndDeviceConfAns", MACCommand{CID: EndDeviceConfAns, Payload: &EndDeviceConfAnsPayload{SecondChAckOffsetACK: true, BackoffACK: true}}},
+	{true, "FilterListAns", MACCommand{CID: FilterListAns, Payload: &FilterListAnsPayload{FilterListActionACK: true, FilterListLenACK: true}}},
+	{true, "UpdateUplinkListAns", MACCommand{CID: UpdateUplinkListAns}},
+	{true, "CtrlUplinkListAns", MACCommand{CID: CtrlUplinkListAns, Payload: &CtrlUplinkListAnsPayload{UplinkListIdxACK: true, WFCnt: 1024}}},
+	{true, "ConfigureFwdLimitAns", MACCommand{CID: ConfigureFwdLimitAns}},
+
+	// downlink
+	{false, "ResetConf", MACCommand{CID: ResetConf, Payload: &ResetConfPayload{ServLoRaWANVersion: Version{Minor: 1}}}},
+	{false, "LinkCheckAns", MACCommand{CID: LinkCheckAns, Payload: &LinkCheckAnsPayload{Margin: 20, GwCnt: 3}}},
+	{false, "LinkADRReq", MACCommand{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 5, TXPower: 2, ChMask: ChMask{true, true, true}, Redundancy: Redundancy{NbRep: 1}}}},
+	{false, "LinkADRReq", MACCommand{CID: LinkADRReq, Payload: &LinkADRReqPayload{DataRate: 3, TXPower: 1, ChMask: ChMask{false, true}, Redundancy: Redundancy{ChMaskCntl: 7, NbRep: 2}}}},
+	{false, "DutyCycleReq", MACCommand{CID: DutyCycleReq, Payload: &DutyCycleReqPayload{MaxDCycle: 7}}},
+	{false, "RXParamSetupReq", MACCommand{CID: RXParamSetupReq, Payload: &RXParamSetupReqPayload{Frequency: 869525000, DLSettings: DLSettings{RX2DataRate: 3, RX1DROffset: 1}}}},
+	{false, "DevStatusReq", MACCommand{CID: DevStatusReq}},
+	{false, "NewChannelReq", MACCommand{CID: NewChannelReq, Payload: &NewChannelReqPayload{ChIndex: 3, Freq: 867100000, MinDR: 0, MaxDR: 5}}},
+	{false, "NewChannelReq", MACCommand{CID: NewChannelReq, Payload: &NewChannelReqPayload{ChIndex: 4}}},
+	{false, "NewChannelReq", MACCommand{CID: NewChannelReq, Payload: &NewChannelReqPayload{ChIndex: 3, Freq: 2403000000, MinDR: 0, MaxDR: 7}}},
+	{false, "RXTimingSetupReq", MACCommand{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 5}}},
+	{false, "TXParamSetupReq", MACCommand{CID: TXParamSetupReq, Payload: &TXParamSetupReqPayload{DownlinkDwelltime: DwellTime400ms, UplinkDwellTime: DwellTime400ms, MaxEIRP: 13}}},
+	{false, "DLChannelReq", MACCommand{CID: DLChannelReq, Payload: &DLChannelReqPayload{ChIndex: 3, Freq: 868700000}}},
+	{false, "RekeyConf", MACCommand{CID: RekeyConf, Payload: &RekeyConfPayload{ServLoRaWANVersion: Version{Minor: 1}}}},
+	{false, "ADRParamSetupReq", MACCommand{CID: ADRParamSetupReq, Payload: &ADRParamSetupReqPayload{ADRParam: ADRParam{LimitExp: 6, DelayExp: 5}}}},
+	{false, "DeviceTimeAns", MACCommand{CID: DeviceTimeAns, Payload: &DeviceTimeAnsPayload{TimeSinceGPSEpoch: 1234567890*time.Second + 500*time.Millisecond}}},
+	{false, "ForceRejoinReq", MACCommand{CID: ForceRejoinReq, Payload: &ForceRejoinReqPayload{Period: 3, MaxRetries: 4, RejoinType: 2, DR: 5}}},
+	{false, "RejoinParamSetupReq", MACCommand{CID: RejoinParamSetupReq, Payload: &RejoinParamSetupReqPayload{MaxTimeN: 10, MaxCountN: 5}}},
+	{false, "PingSlotInfoAns", MACCommand{CID: PingSlotInfoAns}},
+	{false, "PingSlotChannelReq", MACCommand{CID: PingSlotChannelReq, Payload: &PingSlotChannelReqPayload{Frequency: 869525000, DR: 3}}},
+	{false, "PingSlotChannelReq", MACCommand{CID: PingSlotChannelReq, Payload: &PingSlotChannelReqPayload{DR: 3}}},
+	{false, "BeaconFreqReq", MACCommand{CID: BeaconFreqReq, Payload: &BeaconFreqReqPayload{Frequency: 869525000}}},
+	{false, "DeviceModeConf", MACCommand{CID: DeviceModeConf, Payload: &DeviceModeConfPayload{Class: DeviceModeClassC}}},
+	{false, "RelayConfReq", MACCommand{CID: RelayConfReq, Payload: &RelayConfReqPayload{ChannelSettingsRelay: ChannelSettingsRelay{StartStop: 1, CADPeriodicity: 2, SecondChIdx: 1, SecondChDR: 3, SecondChAckOffset: 2}, SecondChFreq: 868100000}}},
+	{false, "EndDeviceConfReq", MACCommand{CID: EndDeviceConfReq, Payload: &EndDeviceConfReqPayload{ActivationRelayMode: ActivationRelayMode{RelayModeActivation: RelayModeActivationDynamic, SmartEnableLevel: 1}, ChannelSettingsED: ChannelSettingsED{Backoff: 10, SecondChIdx: 1, SecondChDR: 3, SecondChAckOffset: 2}, SecondChFreq: 868100000}}},
+	{false, "FilterListReq", MACCommand{CID: FilterListReq, Payload: &FilterListReqPayload{FilterListIdx: 3, FilterListAction: FilterListActionForward, JoinEUI: EUI64{1, 2, 3, 4, 5, 6, 7, 8}, DevEUI: EUI64{8, 7, 6, 5, 4, 3, 2, 1}}}},
+	{false, "UpdateUplinkListReq", MACCommand{CID: UpdateUplinkListReq, Payload: &UpdateUplinkListReqPayload{UplinkListIdx: 2, UplinkLimit: UplinkLimit{BucketSize: 1, ReloadRate: 10}, DevAddr: DevAddr{1, 2, 3, 4}, WFCnt: 258, RootWorSKey: AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}}}},
+	{false, "CtrlUplinkListReq", MACCommand{CID: CtrlUplinkListReq, Payload: &CtrlUplinkListReqPayload{UplinkListIdx: 5, CtrlUplinkAction: CtrlUplinkActionReadWFCnt}}},
+	{false, "ConfigureFwdLimitReq", MACCommand{CID: ConfigureFwdLimitReq, Payload: &ConfigureFwdLimitReqPayload{ReloadRate: FwdLimitReloadRate{OverallReloadRate: 1, GlobalUplinkReloadRate: 2, NotifyReloadRate: 3, JoinReqReloadRate: 4, ResetLimitCounter: ResetLimitCounterReloadRate}, LoadCapacity: FwdLimitLoadCapacity{OverallLimitSize: 1, GlobalUplinkLimitSize: 2, NotifyLimitSize: 3}}}},
+}
@@ -8,14 +8,11 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
-	"sync"
+	"strings"
 	"time"
-)
 
-// macPayloadMutex is used when registering proprietary MAC command payloads to
-// the macPayloadRegistry.
-var macPayloadMutex sync.RWMutex
+	"github.com/brocaar/lorawan/registry"
+)
 
 // CID defines the MAC command identifier.
 type CID byte
@@ -25,6 +22,29 @@ func (c CID) MarshalText() ([]byte, error) {
 	return []byte(c.String()), nil
 }
 
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts both the
+// uplink and downlink names (e.g. LinkADRAns and LinkADRReq), as these share
+// the same CID, and the String format of unknown CIDs (e.g. CID(128)).
+func (c *CID) UnmarshalText(text []byte) error {
+	for _, uplink := range []bool{true, false} {
+		for cid, name := range macCommandNames[uplink] {
+			if name == string(text) {
+				*c = cid
+				return nil
+			}
+		}
+	}
+
+	for i := 0; i < 256; i++ {
+		if CID(i).String() == string(text) {
+			*c = CID(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("lorawan: invalid CID %s", text)
+}
+
 // MAC commands as specified by the LoRaWAN R1.0 specs. Note that each *Req / *Ans
 // has the same value. Based on the fact if a message is uplink or downlink
 // you should use on or the other.
@@ -72,54 +92,73 @@ const (
 	// Class-C
 	DeviceModeInd  CID = 0x20
 	DeviceModeConf CID = 0x20
+
+	// Relay (TS011)
+	RelayConfReq         CID = 0x40
+	RelayConfAns         CID = 0x40
+	EndDeviceConfReq     CID = 0x41
+	EndDeviceConfAns     CID = 0x41
+	FilterListReq        CID = 0x42
+	FilterListAns        CID = 0x42
+	UpdateUplinkListReq  CID = 0x43
+	UpdateUplinkListAns  CID = 0x43
+	CtrlUplinkListReq    CID = 0x44
+	CtrlUplinkListAns    CID = 0x44
+	ConfigureFwdLimitReq CID = 0x45
+	ConfigureFwdLimitAns CID = 0x45
 	// 0x80 to 0xFF reserved for proprietary network command extensions
 )
 
-// macPayloadInfo contains the info about a MAC payload
-type macPayloadInfo struct {
-	size    int
-	payload func() MACCommandPayload
-}
-
-// macPayloadRegistry contains the info for uplink and downlink MAC payloads
-// in the format map[uplink]map[CID].
+// macPayloadRegistry contains the uplink and downlink MAC payloads.
 // Note that MAC command that do not have a payload are not included in this
-// list.
-var macPayloadRegistry = map[bool]map[CID]macPayloadInfo{
-	false: map[CID]macPayloadInfo{
-		ResetConf:           {1, func() MACCommandPayload { return &ResetConfPayload{} }},
-		LinkCheckAns:        {2, func() MACCommandPayload { return &LinkCheckAnsPayload{} }},
-		LinkADRReq:          {4, func() MACCommandPayload { return &LinkADRReqPayload{} }},
-		DutyCycleReq:        {1, func() MACCommandPayload { return &DutyCycleReqPayload{} }},
-		RXParamSetupReq:     {4, func() MACCommandPayload { return &RXParamSetupReqPayload{} }},
-		NewChannelReq:       {5, func() MACCommandPayload { return &NewChannelReqPayload{} }},
-		RXTimingSetupReq:    {1, func() MACCommandPayload { return &RXTimingSetupReqPayload{} }},
-		TXParamSetupReq:     {1, func() MACCommandPayload { return &TXParamSetupReqPayload{} }},
-		DLChannelReq:        {4, func() MACCommandPayload { return &DLChannelReqPayload{} }},
-		BeaconFreqReq:       {3, func() MACCommandPayload { return &BeaconFreqReqPayload{} }},
-		PingSlotChannelReq:  {4, func() MACCommandPayload { return &PingSlotChannelReqPayload{} }},
-		DeviceTimeAns:       {5, func() MACCommandPayload { return &DeviceTimeAnsPayload{} }},
-		RekeyConf:           {1, func() MACCommandPayload { return &RekeyConfPayload{} }},
-		ADRParamSetupReq:    {1, func() MACCommandPayload { return &ADRParamSetupReqPayload{} }},
-		ForceRejoinReq:      {2, func() MACCommandPayload { return &ForceRejoinReqPayload{} }},
-		RejoinParamSetupReq: {1, func() MACCommandPayload { return &RejoinParamSetupReqPayload{} }},
-		DeviceModeConf:      {1, func() MACCommandPayload { return &DeviceModeConfPayload{} }},
+// registry.
+var macPayloadRegistry = registry.New(
+	// uplink
+	map[CID]registry.Command[MACCommandPayload]{
+		ResetInd:            {Size: 1, New: func() MACCommandPayload { return &ResetIndPayload{} }},
+		LinkADRAns:          {Size: 1, New: func() MACCommandPayload { return &LinkADRAnsPayload{} }},
+		RXParamSetupAns:     {Size: 1, New: func() MACCommandPayload { return &RXParamSetupAnsPayload{} }},
+		DevStatusAns:        {Size: 2, New: func() MACCommandPayload { return &DevStatusAnsPayload{} }},
+		NewChannelAns:       {Size: 1, New: func() MACCommandPayload { return &NewChannelAnsPayload{} }},
+		DLChannelAns:        {Size: 1, New: func() MACCommandPayload { return &DLChannelAnsPayload{} }},
+		PingSlotInfoReq:     {Size: 1, New: func() MACCommandPayload { return &PingSlotInfoReqPayload{} }},
+		BeaconFreqAns:       {Size: 1, New: func() MACCommandPayload { return &BeaconFreqAnsPayload{} }},
+		PingSlotChannelAns:  {Size: 1, New: func() MACCommandPayload { return &PingSlotChannelAnsPayload{} }},
+		RekeyInd:            {Size: 1, New: func() MACCommandPayload { return &RekeyIndPayload{} }},
+		RejoinParamSetupAns: {Size: 1, New: func() MACCommandPayload { return &RejoinParamSetupAnsPayload{} }},
+		DeviceModeInd:       {Size: 1, New: func() MACCommandPayload { return &DeviceModeIndPayload{} }},
+		RelayConfAns:        {Size: 1, New: func() MACCommandPayload { return &RelayConfAnsPayload{} }},
+		EndDeviceConfAns:    {Size: 1, New: func() MACCommandPayload { return &EndDeviceConfAnsPayload{} }},
+		FilterListAns:       {Size: 1, New: func() MACCommandPayload { return &FilterListAnsPayload{} }},
+		CtrlUplinkListAns:   {Size: 5, New: func() MACCommandPayload { return &CtrlUplinkListAnsPayload{} }},
 	},
-	true: map[CID]macPayloadInfo{
-		ResetInd:            {1, func() MACCommandPayload { return &ResetIndPayload{} }},
-		LinkADRAns:          {1, func() MACCommandPayload { return &LinkADRAnsPayload{} }},
-		RXParamSetupAns:     {1, func() MACCommandPayload { return &RXParamSetupAnsPayload{} }},
-		DevStatusAns:        {2, func() MACCommandPayload { return &DevStatusAnsPayload{} }},
-		NewChannelAns:       {1, func() MACCommandPayload { return &NewChannelAnsPayload{} }},
-		DLChannelAns:        {1, func() MACCommandPayload { return &DLChannelAnsPayload{} }},
-		PingSlotInfoReq:     {1, func() MACCommandPayload { return &PingSlotInfoReqPayload{} }},
-		BeaconFreqAns:       {1, func() MACCommandPayload { return &BeaconFreqAnsPayload{} }},
-		PingSlotChannelAns:  {1, func() MACCommandPayload { return &PingSlotChannelAnsPayload{} }},
-		RekeyInd:            {1, func() MACCommandPayload { return &RekeyIndPayload{} }},
-		RejoinParamSetupAns: {1, func() MACCommandPayload { return &RejoinParamSetupAnsPayload{} }},
-		DeviceModeInd:       {1, func() MACCommandPayload { return &DeviceModeIndPayload{} }},
+	// downlink
+	map[CID]registry.Command[MACCommandPayload]{
+		ResetConf:            {Size: 1, New: func() MACCommandPayload { return &ResetConfPayload{} }},
+		LinkCheckAns:         {Size: 2, New: func() MACCommandPayload { return &LinkCheckAnsPayload{} }},
+		LinkADRReq:           {Size: 4, New: func() MACCommandPayload { return &LinkADRReqPayload{} }},
+		DutyCycleReq:         {Size: 1, New: func() MACCommandPayload { return &DutyCycleReqPayload{} }},
+		RXParamSetupReq:      {Size: 4, New: func() MACCommandPayload { return &RXParamSetupReqPayload{} }},
+		NewChannelReq:        {Size: 5, New: func() MACCommandPayload { return &NewChannelReqPayload{} }},
+		RXTimingSetupReq:     {Size: 1, New: func() MACCommandPayload { return &RXTimingSetupReqPayload{} }},
+		TXParamSetupReq:      {Size: 1, New: func() MACCommandPayload { return &TXParamSetupReqPayload{} }},
+		DLChannelReq:         {Size: 4, New: func() MACCommandPayload { return &DLChannelReqPayload{} }},
+		BeaconFreqReq:        {Size: 3, New: func() MACCommandPayload { return &BeaconFreqReqPayload{} }},
+		PingSlotChannelReq:   {Size: 4, New: func() MACCommandPayload { return &PingSlotChannelReqPayload{} }},
+		DeviceTimeAns:        {Size: 5, New: func() MACCommandPayload { return &DeviceTimeAnsPayload{} }},
+		RekeyConf:            {Size: 1, New: func() MACCommandPayload { return &RekeyConfPayload{} }},
+		ADRParamSetupReq:     {Size: 1, New: func() MACCommandPayload { return &ADRParamSetupReqPayload{} }},
+		ForceRejoinReq:       {Size: 2, New: func() MACCommandPayload { return &ForceRejoinReqPayload{} }},
+		RejoinParamSetupReq:  {Size: 1, New: func() MACCommandPayload { return &RejoinParamSetupReqPayload{} }},
+		DeviceModeConf:       {Size: 1, New: func() MACCommandPayload { return &DeviceModeConfPayload{} }},
+		RelayConfReq:         {Size: 5, New: func() MACCommandPayload { return &RelayConfReqPayload{} }},
+		EndDeviceConfReq:     {Size: 6, New: func() MACCommandPayload { return &EndDeviceConfReqPayload{} }},
+		FilterListReq:        {Size: 17, New: func() MACCommandPayload { return &FilterListReqPayload{} }},
+		UpdateUplinkListReq:  {Size: 26, New: func() MACCommandPayload { return &UpdateUplinkListReqPayload{} }},
+		CtrlUplinkListReq:    {Size: 1, New: func() MACCommandPayload { return &CtrlUplinkListReqPayload{} }},
+		ConfigureFwdLimitReq: {Size: 5, New: func() MACCommandPayload { return &ConfigureFwdLimitReqPayload{} }},
 	},
-}
+)
 
 // DwellTime defines the dwell time type.
 type DwellTime int
@@ -130,17 +169,28 @@ const (
 	DwellTime400ms
 )
 
+// MinMACCommandFrequency defines the minimum (non-zero) frequency (Hz) that
+// can be encoded in a mac-command payload. Lower frequencies are not used by
+// any region and indicate invalid input.
+const MinMACCommandFrequency uint32 = 100000000
+
+// validateMACCommandFrequency returns an error when the given frequency is
+// non-zero and lower than MinMACCommandFrequency.
+func validateMACCommandFrequency(field string, freq uint32) error {
+	if freq != 0 && freq < MinMACCommandFrequency {
+		return fmt.Errorf("lorawan: min value of %s is %d (or 0)", field, MinMACCommandFrequency)
+	}
+	return nil
+}
+
 // GetMACPayloadAndSize returns a new MACCommandPayload instance and it's size.
 func GetMACPayloadAndSize(uplink bool, c CID) (MACCommandPayload, int, error) {
-	macPayloadMutex.RLock()
-	defer macPayloadMutex.RUnlock()
-
-	v, ok := macPayloadRegistry[uplink][c]
+	p, size, ok := macPayloadRegistry.Get(uplink, c)
 	if !ok {
 		return nil, 0, fmt.Errorf("lorawan: payload unknown for uplink=%v and CID=%v", uplink, c)
 	}
 
-	return v.payload(), v.size, nil
+	return p, size, nil
 }
 
 // RegisterProprietaryMACCommand registers a proprietary MAC command. Note
@@ -155,17 +205,62 @@ func RegisterProprietaryMACCommand(uplink bool, cid CID, payloadSize int) error
 		return nil
 	}
 
-	macPayloadMutex.Lock()
-	defer macPayloadMutex.Unlock()
+	macPayloadRegistry.Register(uplink, cid, registry.Command[MACCommandPayload]{
+		Size: payloadSize,
+		New:  func() MACCommandPayload { return &ProprietaryMACCommandPayload{} },
+	})
 
-	macPayloadRegistry[uplink][cid] = macPayloadInfo{
-		size:    payloadSize,
-		payload: func() MACCommandPayload { return &ProprietaryMACCommandPayload{} },
-	}
+	return nil
+}
+
+// RawMACCommandPayload holds the raw payload bytes of a mac-command of which
+// the CID is unknown (e.g. RFU or deprecated CIDs like 0x12, the former
+// BeaconTimingReq / BeaconTimingAns). As the size of the payload is not
+// known, it contains all the remaining bytes. See
+// DecodeOptions.UnknownCIDPolicy.
+type RawMACCommandPayload struct {
+	Bytes []byte `json:"bytes"`
+}
 
+// MarshalBinary marshals the object into a slice of bytes.
+func (p RawMACCommandPayload) MarshalBinary() ([]byte, error) {
+	return p.Bytes, nil
+}
+
+// UnmarshalBinary decodes the object from a slice of bytes.
+func (p *RawMACCommandPayload) UnmarshalBinary(data []byte) error {
+	p.Bytes = make([]byte, len(data))
+	copy(p.Bytes, data)
 	return nil
 }
 
+// UnknownCIDPolicy defines how mac-commands with an unknown CID are decoded.
+// Proprietary CIDs (0x80 - 0xff) are never considered unknown, see
+// RegisterProprietaryMACCommand.
+type UnknownCIDPolicy int32
+
+// Available unknown CID policies.
+const (
+	// UnknownCIDSkip decodes the unknown CID as a mac-command without
+	// payload and decodes the next byte as the CID of the next mac-command.
+	UnknownCIDSkip UnknownCIDPolicy = iota
+
+	// UnknownCIDRaw decodes the unknown CID as a mac-command with a
+	// RawMACCommandPayload containing all the remaining bytes, such that
+	// the frames of non-compliant devices can still be inspected.
+	UnknownCIDRaw
+)
+
+// isKnownCID returns true when the given CID is defined for the given
+// direction or is within the proprietary range (0x80 - 0xff).
+func isKnownCID(uplink bool, cid CID) bool {
+	if cid >= 0x80 {
+		return true
+	}
+	_, ok := macCommandNames[uplink][cid]
+	return ok
+}
+
 // MACCommandPayload is the interface that every MACCommand payload
 // must implement.
 type MACCommandPayload interface {
@@ -195,6 +290,12 @@ func (m MACCommand) MarshalBinary() ([]byte, error) {
 
 // UnmarshalBinary decodes the object from binary form.
 func (m *MACCommand) UnmarshalBinary(uplink bool, data []byte) error {
+	return m.UnmarshalBinaryWithOptions(uplink, data, DecodeOptions{})
+}
+
+// UnmarshalBinaryWithOptions decodes the object from binary form, using the
+// given decode options.
+func (m *MACCommand) UnmarshalBinaryWithOptions(uplink bool, data []byte, opts DecodeOptions) error {
 	if len(data) == 0 {
 		return errors.New("lorawan: at least 1 byte of data is expected")
 	}
@@ -210,6 +311,16 @@ func (m *MACCommand) UnmarshalBinary(uplink bool, data []byte) error {
 		if err := m.Payload.UnmarshalBinary(data[1:]); err != nil {
 			return err
 		}
+		if pl, ok := m.Payload.(*DutyCycleReqPayload); ok && (opts.StrictDutyCycleReq || opts.Conformance) {
+			if err := pl.validate(); err != nil {
+				return err
+			}
+		}
+		if opts.Conformance {
+			if err := validateMACCommandConformance(m.Payload, data[1:]); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -402,22 +513,51 @@ func (p *LinkADRAnsPayload) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// DutyCycleSilenced defines the MaxDCycle value which requests the device
+// to become silent until it is re-enabled by a subsequent DutyCycleReq.
+const DutyCycleSilenced uint8 = 255
+
 // DutyCycleReqPayload represents the DutyCycleReq payload.
 type DutyCycleReqPayload struct {
 	MaxDCycle uint8 `json:"maxDCycle"`
 }
 
+// IsSilenced returns true when the device is requested to become silent
+// (MaxDCycle = 255).
+func (p DutyCycleReqPayload) IsSilenced() bool {
+	return p.MaxDCycle == DutyCycleSilenced
+}
+
+// DutyCyclePercent returns the max. aggregated duty-cycle in percent
+// (100 / 2^MaxDCycle). It returns 0 when the device is silenced or when
+// MaxDCycle holds a RFU value.
+func (p DutyCycleReqPayload) DutyCyclePercent() float64 {
+	if p.MaxDCycle > 15 {
+		return 0
+	}
+	return 100 / float64(uint16(1)<<p.MaxDCycle)
+}
+
+// validate validates the MaxDCycle value.
+func (p DutyCycleReqPayload) validate() error {
+	if p.MaxDCycle > 15 && p.MaxDCycle < DutyCycleSilenced {
+		return errors.New("lorawan: only a MaxDCycle value of 0 - 15 and 255 is allowed")
+	}
+	return nil
+}
+
 // MarshalBinary marshals the object in binary form.
 func (p DutyCycleReqPayload) MarshalBinary() ([]byte, error) {
 	b := make([]byte, 0, 1)
-	if p.MaxDCycle > 15 && p.MaxDCycle < 255 {
-		return b, errors.New("lorawan: only a MaxDCycle value of 0 - 15 and 255 is allowed")
+	if err := p.validate(); err != nil {
+		return b, err
 	}
 	b = append(b, p.MaxDCycle)
 	return b, nil
 }
 
-// UnmarshalBinary decodes the object from binary form.
+// UnmarshalBinary decodes the object from binary form. The RFU MaxDCycle
+// values (16 - 254) are accepted, see DecodeOptions.StrictDutyCycleReq.
 func (p *DutyCycleReqPayload) UnmarshalBinary(data []byte) error {
 	if len(data) != 1 {
 		return errors.New("lorawan: 1 byte of data is expected")
@@ -426,6 +566,34 @@ func (p *DutyCycleReqPayload) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// DutyCycleState holds the aggregated duty-cycle state of a device, as set
+// by the DutyCycleReq mac-command.
+type DutyCycleState struct {
+	MaxDCycle uint8 `json:"maxDCycle"`
+}
+
+// ApplyDutyCycleReqPayload applies the given DutyCycleReq payload to the
+// state. A silenced device is re-enabled by a MaxDCycle value of 0 - 15.
+// An error is returned for the RFU MaxDCycle values, in which case the
+// state is not modified.
+func (s *DutyCycleState) ApplyDutyCycleReqPayload(pl DutyCycleReqPayload) error {
+	if err := pl.validate(); err != nil {
+		return err
+	}
+	s.MaxDCycle = pl.MaxDCycle
+	return nil
+}
+
+// IsSilenced returns true when the device must not transmit.
+func (s DutyCycleState) IsSilenced() bool {
+	return DutyCycleReqPayload{MaxDCycle: s.MaxDCycle}.IsSilenced()
+}
+
+// DutyCyclePercent returns the max. aggregated duty-cycle in percent.
+func (s DutyCycleState) DutyCyclePercent() float64 {
+	return DutyCycleReqPayload{MaxDCycle: s.MaxDCycle}.DutyCyclePercent()
+}
+
 // DLSettings represents the DLSettings fields (downlink settings).
 type DLSettings struct {
 	OptNeg      bool  `json:"optNeg"`
@@ -500,6 +668,9 @@ func (p RXParamSetupReqPayload) MarshalBinary() ([]byte, error) {
 	if p.Frequency%100 != 0 {
 		return b, errors.New("lorawan: Frequency must be a multiple of 100")
 	}
+	if err := validateMACCommandFrequency("Frequency", p.Frequency); err != nil {
+		return b, err
+	}
 	bytes, err := p.DLSettings.MarshalBinary()
 	if err != nil {
 		return b, err
@@ -609,6 +780,11 @@ type NewChannelReqPayload struct {
 	MinDR   uint8  `json:"minDR"`
 }
 
+// IsDisabled returns true when the payload disables the channel (Freq = 0).
+func (p NewChannelReqPayload) IsDisabled() bool {
+	return p.Freq == 0
+}
+
 // MarshalBinary marshals the object in binary form.
 func (p NewChannelReqPayload) MarshalBinary() ([]byte, error) {
 	b := make([]byte, 5)
@@ -627,6 +803,9 @@ func (p NewChannelReqPayload) MarshalBinary() ([]byte, error) {
 	if p.Freq%100 != 0 {
 		return b, errors.New("lorawan: Freq must be a multiple of 100")
 	}
+	if err := validateMACCommandFrequency("Freq", p.Freq); err != nil {
+		return b, err
+	}
 	if p.MaxDR > 15 {
 		return b, errors.New("lorawan: max value of MaxDR is 15")
 	}
@@ -781,6 +960,10 @@ func (p DLChannelReqPayload) MarshalBinary() ([]byte, error) {
 		return b, errors.New("lorawan: Freq must be a multiple of 100")
 	}
 
+	if err := validateMACCommandFrequency("Freq", p.Freq); err != nil {
+		return b, err
+	}
+
 	b[0] = p.ChIndex
 	binary.LittleEndian.PutUint32(b[1:5], p.Freq/100)
 
@@ -868,6 +1051,9 @@ func (p BeaconFreqReqPayload) MarshalBinary() ([]byte, error) {
 	if p.Frequency%100 != 0 {
 		return nil, errors.New("lorawan: Frequency must be a multiple of 100")
 	}
+	if err := validateMACCommandFrequency("Frequency", p.Frequency); err != nil {
+		return nil, err
+	}
 
 	// we need 4 bytes for PutUint32
 	b := make([]byte, 4)
@@ -918,11 +1104,19 @@ func (p *BeaconFreqAnsPayload) UnmarshalBinary(data []byte) error {
 }
 
 // PingSlotChannelReqPayload represents the PingSlotChannelReq payload.
+// A Frequency of 0 means that the region default ping-slot frequency
+// must be used.
 type PingSlotChannelReqPayload struct {
 	Frequency uint32 `json:"frequency"`
 	DR        uint8  `json:"dr"`
 }
 
+// UsesDefaultFrequency returns true when the payload instructs the device to
+// use the region default ping-slot frequency (Frequency = 0).
+func (p PingSlotChannelReqPayload) UsesDefaultFrequency() bool {
+	return p.Frequency == 0
+}
+
 // MarshalBinary encodes the object into bytes.
 func (p PingSlotChannelReqPayload) MarshalBinary() ([]byte, error) {
 	if p.Frequency/100 >= 16777216 { // 2^24
@@ -931,6 +1125,9 @@ func (p PingSlotChannelReqPayload) MarshalBinary() ([]byte, error) {
 	if p.Frequency%100 != 0 {
 		return nil, errors.New("lorawan: Frequency must be a multiple of 100")
 	}
+	if err := validateMACCommandFrequency("Frequency", p.Frequency); err != nil {
+		return nil, err
+	}
 	if p.DR >= 16 { // 2^4
 		return nil, errors.New("lorawan: max value of DR is 15")
 	}
@@ -1219,6 +1416,18 @@ func (p *RejoinParamSetupReqPayload) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// MaxTime returns the max. time between two rejoin-requests (type 0), which
+// is 2^(MaxTimeN+10) seconds.
+func (p RejoinParamSetupReqPayload) MaxTime() time.Duration {
+	return time.Duration(1<<(uint(p.MaxTimeN&0x0f)+10)) * time.Second
+}
+
+// MaxCount returns the max. number of uplinks between two rejoin-requests
+// (type 0), which is 2^(MaxCountN+4) uplinks.
+func (p RejoinParamSetupReqPayload) MaxCount() uint32 {
+	return 1 << (uint(p.MaxCountN&0x0f) + 4)
+}
+
 // RejoinParamSetupAnsPayload represents the RejoinParamSetupAns payload.
 type RejoinParamSetupAnsPayload struct {
 	TimeOK bool `json:"timeOK"`
@@ -1244,7 +1453,8 @@ func (p *RejoinParamSetupAnsPayload) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
-// DeviceModeIndClass defines the DeviceModeInd class.
+// DeviceModeClass defines the class of the DeviceModeInd and DeviceModeConf
+// mac-commands (LoRaWAN 1.0.4 / 1.1).
 type DeviceModeClass byte
 
 // DeviceModeInd class options.
@@ -1254,13 +1464,25 @@ const (
 	DeviceModeClassC DeviceModeClass = 0x02
 )
 
-// DeviceModeIndPayload represents the DeviceModeInd payload.
+// validate returns an error when the class is not Class-A or Class-C.
+func (c DeviceModeClass) validate() error {
+	if c != DeviceModeClassA && c != DeviceModeClassC {
+		return fmt.Errorf("lorawan: Class must be DeviceModeClassA or DeviceModeClassC, got %s", c)
+	}
+	return nil
+}
+
+// DeviceModeIndPayload represents the DeviceModeInd payload, which is sent
+// by the device to indicate its (new) operating mode.
 type DeviceModeIndPayload struct {
-	Class DeviceModeClass
+	Class DeviceModeClass `json:"class"`
 }
 
 // MarshalBinary encodes the object into bytes.
 func (p DeviceModeIndPayload) MarshalBinary() ([]byte, error) {
+	if err := p.Class.validate(); err != nil {
+		return nil, err
+	}
 	return []byte{byte(p.Class)}, nil
 }
 
@@ -1275,13 +1497,17 @@ func (p *DeviceModeIndPayload) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
-// DeviceModeConfPayload represents the DeviceModeConf payload.
+// DeviceModeConfPayload represents the DeviceModeConf payload, which is sent
+// by the network-server to confirm the operating mode of the device.
 type DeviceModeConfPayload struct {
-	Class DeviceModeClass
+	Class DeviceModeClass `json:"class"`
 }
 
 // MarshalBinary encodes the object into bytes.
 func (p DeviceModeConfPayload) MarshalBinary() ([]byte, error) {
+	if err := p.Class.validate(); err != nil {
+		return nil, err
+	}
 	return []byte{byte(p.Class)}, nil
 }
 
@@ -1296,40 +1522,765 @@ func (p *DeviceModeConfPayload) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// ChannelSettingsRelay defines the relay channel settings of the
+// RelayConfReq payload.
+type ChannelSettingsRelay struct {
+	StartStop         uint8 `json:"startStop"`
+	CADPeriodicity    uint8 `json:"cadPeriodicity"`
+	DefaultChIdx      uint8 `json:"defaultChIdx"`
+	SecondChIdx       uint8 `json:"secondChIdx"`
+	SecondChDR        uint8 `json:"secondChDR"`
+	SecondChAckOffset uint8 `json:"secondChAckOffset"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (c ChannelSettingsRelay) MarshalBinary() ([]byte, error) {
+	if c.StartStop > 1 {
+		return nil, errors.New("lorawan: max value of StartStop is 1")
+	}
+	if c.CADPeriodicity > 7 {
+		return nil, errors.New("lorawan: max value of CADPeriodicity is 7")
+	}
+	if c.DefaultChIdx > 1 {
+		return nil, errors.New("lorawan: max value of DefaultChIdx is 1")
+	}
+	if c.SecondChIdx > 3 {
+		return nil, errors.New("lorawan: max value of SecondChIdx is 3")
+	}
+	if c.SecondChDR > 15 {
+		return nil, errors.New("lorawan: max value of SecondChDR is 15")
+	}
+	if c.SecondChAckOffset > 7 {
+		return nil, errors.New("lorawan: max value of SecondChAckOffset is 7")
+	}
+
+	v := uint16(c.SecondChAckOffset) |
+		uint16(c.SecondChDR)<<3 |
+		uint16(c.SecondChIdx)<<7 |
+		uint16(c.DefaultChIdx)<<9 |
+		uint16(c.CADPeriodicity)<<10 |
+		uint16(c.StartStop)<<13
+
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b, nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (c *ChannelSettingsRelay) UnmarshalBinary(data []byte) error {
+	if len(data) != 2 {
+		return errors.New("lorawan: 2 bytes of data are expected")
+	}
+
+	v := binary.LittleEndian.Uint16(data)
+	c.SecondChAckOffset = uint8(v & 0x07)
+	c.SecondChDR = uint8((v >> 3) & 0x0f)
+	c.SecondChIdx = uint8((v >> 7) & 0x03)
+	c.DefaultChIdx = uint8((v >> 9) & 0x01)
+	c.CADPeriodicity = uint8((v >> 10) & 0x07)
+	c.StartStop = uint8((v >> 13) & 0x01)
+
+	return nil
+}
+
+// RelayConfReqPayload represents the RelayConfReq payload.
+type RelayConfReqPayload struct {
+	ChannelSettingsRelay ChannelSettingsRelay `json:"channelSettingsRelay"`
+	SecondChFreq         uint32               `json:"secondChFreq"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p RelayConfReqPayload) MarshalBinary() ([]byte, error) {
+	b, err := p.ChannelSettingsRelay.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	freq, err := marshalRelayFrequency("SecondChFreq", p.SecondChFreq)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, freq...), nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *RelayConfReqPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 5 {
+		return errors.New("lorawan: 5 bytes of data are expected")
+	}
+
+	if err := p.ChannelSettingsRelay.UnmarshalBinary(data[0:2]); err != nil {
+		return err
+	}
+	p.SecondChFreq = unmarshalRelayFrequency(data[2:5])
+
+	return nil
+}
+
+// RelayConfAnsPayload represents the RelayConfAns payload.
+type RelayConfAnsPayload struct {
+	SecondChAckOffsetACK bool `json:"secondChAckOffsetACK"`
+	SecondChDRACK        bool `json:"secondChDRACK"`
+	SecondChIdxACK       bool `json:"secondChIdxACK"`
+	DefaultChIdxACK      bool `json:"defaultChIdxACK"`
+	CADPeriodicityACK    bool `json:"cadPeriodicityACK"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p RelayConfAnsPayload) MarshalBinary() ([]byte, error) {
+	var b byte
+	if p.SecondChAckOffsetACK {
+		b = b ^ (1 << 0)
+	}
+	if p.SecondChDRACK {
+		b = b ^ (1 << 1)
+	}
+	if p.SecondChIdxACK {
+		b = b ^ (1 << 2)
+	}
+	if p.DefaultChIdxACK {
+		b = b ^ (1 << 3)
+	}
+	if p.CADPeriodicityACK {
+		b = b ^ (1 << 4)
+	}
+	return []byte{b}, nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *RelayConfAnsPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return errors.New("lorawan: 1 byte of data is expected")
+	}
+
+	p.SecondChAckOffsetACK = data[0]&(1<<0) > 0
+	p.SecondChDRACK = data[0]&(1<<1) > 0
+	p.SecondChIdxACK = data[0]&(1<<2) > 0
+	p.DefaultChIdxACK = data[0]&(1<<3) > 0
+	p.CADPeriodicityACK = data[0]&(1<<4) > 0
+
+	return nil
+}
+
+// RelayModeActivation defines the relay mode activation of the
+// EndDeviceConfReq payload.
+type RelayModeActivation uint8
+
+// Available relay mode activation options.
+const (
+	RelayModeActivationDisabled RelayModeActivation = iota
+	RelayModeActivationEnabled
+	RelayModeActivationDynamic
+	RelayModeActivationEndDeviceControlled
+)
+
+// ActivationRelayMode defines the relay activation settings of the
+// EndDeviceConfReq payload.
+type ActivationRelayMode struct {
+	RelayModeActivation RelayModeActivation `json:"relayModeActivation"`
+	SmartEnableLevel    uint8               `json:"smartEnableLevel"`
+}
+
+// ChannelSettingsED defines the end-device channel settings of the
+// EndDeviceConfReq payload.
+type ChannelSettingsED struct {
+	Backoff           uint8 `json:"backoff"`
+	SecondChIdx       uint8 `json:"secondChIdx"`
+	SecondChDR        uint8 `json:"secondChDR"`
+	SecondChAckOffset uint8 `json:"secondChAckOffset"`
+}
+
+// EndDeviceConfReqPayload represents the EndDeviceConfReq payload.
+type EndDeviceConfReqPayload struct {
+	ActivationRelayMode ActivationRelayMode `json:"activationRelayMode"`
+	ChannelSettingsED   ChannelSettingsED   `json:"channelSettingsED"`
+	SecondChFreq        uint32              `json:"secondChFreq"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p EndDeviceConfReqPayload) MarshalBinary() ([]byte, error) {
+	if p.ActivationRelayMode.RelayModeActivation > 3 {
+		return nil, errors.New("lorawan: max value of RelayModeActivation is 3")
+	}
+	if p.ActivationRelayMode.SmartEnableLevel > 3 {
+		return nil, errors.New("lorawan: max value of SmartEnableLevel is 3")
+	}
+	if p.ChannelSettingsED.Backoff > 63 {
+		return nil, errors.New("lorawan: max value of Backoff is 63")
+	}
+	if p.ChannelSettingsED.SecondChIdx > 3 {
+		return nil, errors.New("lorawan: max value of SecondChIdx is 3")
+	}
+	if p.ChannelSettingsED.SecondChDR > 15 {
+		return nil, errors.New("lorawan: max value of SecondChDR is 15")
+	}
+	if p.ChannelSettingsED.SecondChAckOffset > 7 {
+		return nil, errors.New("lorawan: max value of SecondChAckOffset is 7")
+	}
+
+	freq, err := marshalRelayFrequency("SecondChFreq", p.SecondChFreq)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 3, 6)
+	b[0] = p.ActivationRelayMode.SmartEnableLevel | byte(p.ActivationRelayMode.RelayModeActivation)<<2
+	binary.LittleEndian.PutUint16(b[1:3], uint16(p.ChannelSettingsED.SecondChAckOffset)|
+		uint16(p.ChannelSettingsED.SecondChDR)<<3|
+		uint16(p.ChannelSettingsED.SecondChIdx)<<7|
+		uint16(p.ChannelSettingsED.Backoff)<<9)
+
+	return append(b, freq...), nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *EndDeviceConfReqPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 6 {
+		return errors.New("lorawan: 6 bytes of data are expected")
+	}
+
+	p.ActivationRelayMode.SmartEnableLevel = data[0] & 0x03
+	p.ActivationRelayMode.RelayModeActivation = RelayModeActivation((data[0] >> 2) & 0x03)
+
+	v := binary.LittleEndian.Uint16(data[1:3])
+	p.ChannelSettingsED.SecondChAckOffset = uint8(v & 0x07)
+	p.ChannelSettingsED.SecondChDR = uint8((v >> 3) & 0x0f)
+	p.ChannelSettingsED.SecondChIdx = uint8((v >> 7) & 0x03)
+	p.ChannelSettingsED.Backoff = uint8((v >> 9) & 0x3f)
+
+	p.SecondChFreq = unmarshalRelayFrequency(data[3:6])
+
+	return nil
+}
+
+// EndDeviceConfAnsPayload represents the EndDeviceConfAns payload.
+type EndDeviceConfAnsPayload struct {
+	SecondChAckOffsetACK bool `json:"secondChAckOffsetACK"`
+	SecondChDRACK        bool `json:"secondChDRACK"`
+	SecondChIdxACK       bool `json:"secondChIdxACK"`
+	BackoffACK           bool `json:"backoffACK"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p EndDeviceConfAnsPayload) MarshalBinary() ([]byte, error) {
+	var b byte
+	if p.SecondChAckOffsetACK {
+		b = b ^ (1 << 0)
+	}
+	if p.SecondChDRACK {
+		b = b ^ (1 << 1)
+	}
+	if p.SecondChIdxACK {
+		b = b ^ (1 << 2)
+	}
+	if p.BackoffACK {
+		b = b ^ (1 << 3)
+	}
+	return []byte{b}, nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *EndDeviceConfAnsPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return errors.New("lorawan: 1 byte of data is expected")
+	}
+
+	p.SecondChAckOffsetACK = data[0]&(1<<0) > 0
+	p.SecondChDRACK = data[0]&(1<<1) > 0
+	p.SecondChIdxACK = data[0]&(1<<2) > 0
+	p.BackoffACK = data[0]&(1<<3) > 0
+
+	return nil
+}
+
+// FilterListAction defines the action of a FilterListReq rule.
+type FilterListAction uint8
+
+// Available filter-list actions.
+const (
+	FilterListActionNoRule FilterListAction = iota
+	FilterListActionForward
+	FilterListActionFilter
+)
+
+// FilterListReqPayload represents the FilterListReq payload. The rule
+// matches join-requests of devices with the given JoinEUI and DevEUI.
+type FilterListReqPayload struct {
+	FilterListIdx    uint8            `json:"filterListIdx"`
+	FilterListAction FilterListAction `json:"filterListAction"`
+	JoinEUI          EUI64            `json:"joinEUI"`
+	DevEUI           EUI64            `json:"devEUI"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p FilterListReqPayload) MarshalBinary() ([]byte, error) {
+	if p.FilterListIdx > 15 {
+		return nil, errors.New("lorawan: max value of FilterListIdx is 15")
+	}
+	if p.FilterListAction > 3 {
+		return nil, errors.New("lorawan: max value of FilterListAction is 3")
+	}
+
+	b := make([]byte, 1, 17)
+	b[0] = p.FilterListIdx&0x0f | byte(p.FilterListAction)<<4
+
+	joinEUI, err := p.JoinEUI.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	devEUI, err := p.DevEUI.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, joinEUI...)
+	return append(b, devEUI...), nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *FilterListReqPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 17 {
+		return errors.New("lorawan: 17 bytes of data are expected")
+	}
+
+	p.FilterListIdx = data[0] & 0x0f
+	p.FilterListAction = FilterListAction((data[0] >> 4) & 0x03)
+
+	if err := p.JoinEUI.UnmarshalBinary(data[1:9]); err != nil {
+		return err
+	}
+	if err := p.DevEUI.UnmarshalBinary(data[9:17]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FilterListAnsPayload represents the FilterListAns payload.
+type FilterListAnsPayload struct {
+	FilterListActionACK bool `json:"filterListActionACK"`
+	FilterListLenACK    bool `json:"filterListLenACK"`
+	CombinedRulesACK    bool `json:"combinedRulesACK"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p FilterListAnsPayload) MarshalBinary() ([]byte, error) {
+	var b byte
+	if p.FilterListActionACK {
+		b = b ^ (1 << 0)
+	}
+	if p.FilterListLenACK {
+		b = b ^ (1 << 1)
+	}
+	if p.CombinedRulesACK {
+		b = b ^ (1 << 2)
+	}
+	return []byte{b}, nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *FilterListAnsPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return errors.New("lorawan: 1 byte of data is expected")
+	}
+
+	p.FilterListActionACK = data[0]&(1<<0) > 0
+	p.FilterListLenACK = data[0]&(1<<1) > 0
+	p.CombinedRulesACK = data[0]&(1<<2) > 0
+
+	return nil
+}
+
+// UplinkLimit defines the token-bucket settings of an uplink-list entry.
+type UplinkLimit struct {
+	BucketSize uint8 `json:"bucketSize"`
+	ReloadRate uint8 `json:"reloadRate"`
+}
+
+// UpdateUplinkListReqPayload represents the UpdateUplinkListReq payload.
+// RootWorSKey must contain the encrypted RootWorSKey of the end-device
+// (see the relay package for the key derivation).
+type UpdateUplinkListReqPayload struct {
+	UplinkListIdx uint8       `json:"uplinkListIdx"`
+	UplinkLimit   UplinkLimit `json:"uplinkLimit"`
+	DevAddr       DevAddr     `json:"devAddr"`
+	WFCnt         uint32      `json:"wFCnt"`
+	RootWorSKey   AES128Key   `json:"rootWorSKey"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p UpdateUplinkListReqPayload) MarshalBinary() ([]byte, error) {
+	if p.UplinkListIdx > 15 {
+		return nil, errors.New("lorawan: max value of UplinkListIdx is 15")
+	}
+	if p.UplinkLimit.BucketSize > 3 {
+		return nil, errors.New("lorawan: max value of BucketSize is 3")
+	}
+	if p.UplinkLimit.ReloadRate > 63 {
+		return nil, errors.New("lorawan: max value of ReloadRate is 63")
+	}
+
+	b := make([]byte, 10, 26)
+	b[0] = p.UplinkListIdx
+	b[1] = p.UplinkLimit.ReloadRate | p.UplinkLimit.BucketSize<<6
+
+	devAddr, err := p.DevAddr.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	copy(b[2:6], devAddr)
+	binary.LittleEndian.PutUint32(b[6:10], p.WFCnt)
+
+	return append(b, p.RootWorSKey[:]...), nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *UpdateUplinkListReqPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 26 {
+		return errors.New("lorawan: 26 bytes of data are expected")
+	}
+
+	p.UplinkListIdx = data[0] & 0x0f
+	p.UplinkLimit.ReloadRate = data[1] & 0x3f
+	p.UplinkLimit.BucketSize = data[1] >> 6
+
+	if err := p.DevAddr.UnmarshalBinary(data[2:6]); err != nil {
+		return err
+	}
+	p.WFCnt = binary.LittleEndian.Uint32(data[6:10])
+	copy(p.RootWorSKey[:], data[10:26])
+
+	return nil
+}
+
+// CtrlUplinkAction defines the action of the CtrlUplinkListReq.
+type CtrlUplinkAction uint8
+
+// Available CtrlUplinkListReq actions.
+const (
+	CtrlUplinkActionReadWFCnt CtrlUplinkAction = iota
+	CtrlUplinkActionRemove
+)
+
+// CtrlUplinkListReqPayload represents the CtrlUplinkListReq payload.
+type CtrlUplinkListReqPayload struct {
+	UplinkListIdx    uint8            `json:"uplinkListIdx"`
+	CtrlUplinkAction CtrlUplinkAction `json:"ctrlUplinkAction"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p CtrlUplinkListReqPayload) MarshalBinary() ([]byte, error) {
+	if p.UplinkListIdx > 15 {
+		return nil, errors.New("lorawan: max value of UplinkListIdx is 15")
+	}
+	if p.CtrlUplinkAction > 1 {
+		return nil, errors.New("lorawan: max value of CtrlUplinkAction is 1")
+	}
+
+	return []byte{p.UplinkListIdx | byte(p.CtrlUplinkAction)<<4}, nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *CtrlUplinkListReqPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return errors.New("lorawan: 1 byte of data is expected")
+	}
+
+	p.UplinkListIdx = data[0] & 0x0f
+	p.CtrlUplinkAction = CtrlUplinkAction((data[0] >> 4) & 0x01)
+
+	return nil
+}
+
+// CtrlUplinkListAnsPayload represents the CtrlUplinkListAns payload.
+type CtrlUplinkListAnsPayload struct {
+	UplinkListIdxACK bool   `json:"uplinkListIdxACK"`
+	WFCnt            uint32 `json:"wFCnt"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p CtrlUplinkListAnsPayload) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 5)
+	if p.UplinkListIdxACK {
+		b[0] = (1 << 0)
+	}
+	binary.LittleEndian.PutUint32(b[1:5], p.WFCnt)
+
+	return b, nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *CtrlUplinkListAnsPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 5 {
+		return errors.New("lorawan: 5 bytes of data are expected")
+	}
+
+	p.UplinkListIdxACK = data[0]&(1<<0) > 0
+	p.WFCnt = binary.LittleEndian.Uint32(data[1:5])
+
+	return nil
+}
+
+// ResetLimitCounter defines how the relay must reset its forwarding limit
+// counters after a ConfigureFwdLimitReq.
+type ResetLimitCounter uint8
+
+// Available reset limit counter options.
+const (
+	ResetLimitCounterZero ResetLimitCounter = iota
+	ResetLimitCounterReloadRate
+	ResetLimitCounterMaxValue
+	ResetLimitCounterNoChange
+)
+
+// FwdLimitReloadRate defines the reload rates of the relay forwarding
+// limits.
+type FwdLimitReloadRate struct {
+	OverallReloadRate      uint8             `json:"overallReloadRate"`
+	GlobalUplinkReloadRate uint8             `json:"globalUplinkReloadRate"`
+	NotifyReloadRate       uint8             `json:"notifyReloadRate"`
+	JoinReqReloadRate      uint8             `json:"joinReqReloadRate"`
+	ResetLimitCounter      ResetLimitCounter `json:"resetLimitCounter"`
+}
+
+// FwdLimitLoadCapacity defines the bucket sizes of the relay forwarding
+// limits.
+type FwdLimitLoadCapacity struct {
+	OverallLimitSize      uint8 `json:"overallLimitSize"`
+	GlobalUplinkLimitSize uint8 `json:"globalUplinkLimitSize"`
+	NotifyLimitSize       uint8 `json:"notifyLimitSize"`
+	JoinReqLimitSize      uint8 `json:"joinReqLimitSize"`
+}
+
+// ConfigureFwdLimitReqPayload represents the ConfigureFwdLimitReq payload.
+type ConfigureFwdLimitReqPayload struct {
+	ReloadRate   FwdLimitReloadRate   `json:"reloadRate"`
+	LoadCapacity FwdLimitLoadCapacity `json:"loadCapacity"`
+}
+
+// MarshalBinary encodes the object into bytes.
+func (p ConfigureFwdLimitReqPayload) MarshalBinary() ([]byte, error) {
+	rr := p.ReloadRate
+	for _, f := range []struct {
+		name  string
+		value uint8
+	}{
+		{"OverallReloadRate", rr.OverallReloadRate},
+		{"GlobalUplinkReloadRate", rr.GlobalUplinkReloadRate},
+		{"NotifyReloadRate", rr.NotifyReloadRate},
+		{"JoinReqReloadRate", rr.JoinReqReloadRate},
+	} {
+		if f.value > 127 {
+			return nil, fmt.Errorf("lorawan: max value of %s is 127", f.name)
+		}
+	}
+	if rr.ResetLimitCounter > 3 {
+		return nil, errors.New("lorawan: max value of ResetLimitCounter is 3")
+	}
+
+	lc := p.LoadCapacity
+	for _, f := range []struct {
+		name  string
+		value uint8
+	}{
+		{"OverallLimitSize", lc.OverallLimitSize},
+		{"GlobalUplinkLimitSize", lc.GlobalUplinkLimitSize},
+		{"NotifyLimitSize", lc.NotifyLimitSize},
+		{"JoinReqLimitSize", lc.JoinReqLimitSize},
+	} {
+		if f.value > 3 {
+			return nil, fmt.Errorf("lorawan: max value of %s is 3", f.name)
+		}
+	}
+
+	b := make([]byte, 5)
+	binary.LittleEndian.PutUint32(b[0:4], uint32(rr.OverallReloadRate)|
+		uint32(rr.GlobalUplinkReloadRate)<<7|
+		uint32(rr.NotifyReloadRate)<<14|
+		uint32(rr.JoinReqReloadRate)<<21|
+		uint32(rr.ResetLimitCounter)<<28)
+	b[4] = lc.OverallLimitSize |
+		lc.GlobalUplinkLimitSize<<2 |
+		lc.NotifyLimitSize<<4 |
+		lc.JoinReqLimitSize<<6
+
+	return b, nil
+}
+
+// UnmarshalBinary decodes the object from bytes.
+func (p *ConfigureFwdLimitReqPayload) UnmarshalBinary(data []byte) error {
+	if len(data) != 5 {
+		return errors.New("lorawan: 5 bytes of data are expected")
+	}
+
+	v := binary.LittleEndian.Uint32(data[0:4])
+	p.ReloadRate.OverallReloadRate = uint8(v & 0x7f)
+	p.ReloadRate.GlobalUplinkReloadRate = uint8((v >> 7) & 0x7f)
+	p.ReloadRate.NotifyReloadRate = uint8((v >> 14) & 0x7f)
+	p.ReloadRate.JoinReqReloadRate = uint8((v >> 21) & 0x7f)
+	p.ReloadRate.ResetLimitCounter = ResetLimitCounter((v >> 28) & 0x03)
+
+	p.LoadCapacity.OverallLimitSize = data[4] & 0x03
+	p.LoadCapacity.GlobalUplinkLimitSize = (data[4] >> 2) & 0x03
+	p.LoadCapacity.NotifyLimitSize = (data[4] >> 4) & 0x03
+	p.LoadCapacity.JoinReqLimitSize = (data[4] >> 6) & 0x03
+
+	return nil
+}
+
+// marshalRelayFrequency encodes the given frequency (Hz) as 3 bytes, in
+// steps of 100 Hz.
+func marshalRelayFrequency(field string, freq uint32) ([]byte, error) {
+	if freq/100 >= 16777216 { // 2^24
+		return nil, fmt.Errorf("lorawan: max value of %s is 2^24 - 1", field)
+	}
+	if freq%100 != 0 {
+		return nil, fmt.Errorf("lorawan: %s must be a multiple of 100", field)
+	}
+	if err := validateMACCommandFrequency(field, freq); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, freq/100)
+	return b[0:3], nil
+}
+
+// unmarshalRelayFrequency decodes the given 3 bytes into a frequency (Hz).
+func unmarshalRelayFrequency(data []byte) uint32 {
+	b := make([]byte, 4)
+	copy(b, data)
+	return binary.LittleEndian.Uint32(b) * 100
+}
+
+// MACCommandDecodeMode defines how the mac-commands are decoded from the
+// FOpts or FRMPayload bytes.
+type MACCommandDecodeMode int32
+
+// Available mac-command decode modes.
+const (
+	// MACCommandDecodeLenient skips the mac-commands which could not be
+	// decoded and continues with the remaining bytes. The (partially)
+	// decoded mac-commands are stored and no error is returned. Use
+	// PHYPayload.DecodeFOptsToMACCommandsWithOptions or
+	// PHYPayload.DecodeFRMPayloadToMACCommandsWithOptions to retrieve the
+	// decode errors.
+	MACCommandDecodeLenient MACCommandDecodeMode = iota
+
+	// MACCommandDecodeStrict stops at the first mac-command which could not
+	// be decoded and returns it as *MACCommandDecodeError. The FOpts or
+	// FRMPayload are not modified.
+	MACCommandDecodeStrict
+)
+
+// MACCommandError contains the error of a mac-command which could not be
+// decoded.
+type MACCommandError struct {
+	// CID holds the CID of the mac-command.
+	CID CID
+
+	// Offset holds the offset of the mac-command (CID byte) within the
+	// FOpts or FRMPayload bytes.
+	Offset int
+
+	// Err holds the decode error.
+	Err error
+}
+
+// MACCommandDecodeError is returned in MACCommandDecodeStrict mode when a
+// mac-command could not be decoded.
+type MACCommandDecodeError struct {
+	Errors []MACCommandError
+}
+
+// CIDs returns the CIDs of the mac-commands which could not be decoded.
+func (e *MACCommandDecodeError) CIDs() []CID {
+	out := make([]CID, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		out = append(out, err.CID)
+	}
+	return out
+}
+
+// Error implements the error interface.
+func (e *MACCommandDecodeError) Error() string {
+	errs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, fmt.Sprintf("%s (offset %d): %s", err.CID, err.Offset, err.Err))
+	}
+	return fmt.Sprintf("lorawan: decode mac-commands error: %s", strings.Join(errs, ", "))
+}
+
+// Unwrap returns the error of the first mac-command which could not be
+// decoded.
+func (e *MACCommandDecodeError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors[0].Err
+}
+
 // decodeDataPayloadToMACCommands decodes a DataPayload into a slice of
-// MACCommands.
-func decodeDataPayloadToMACCommands(uplink bool, payloads []Payload) ([]Payload, error) {
+// MACCommands, using the given decode options.
+func decodeDataPayloadToMACCommands(uplink bool, payloads []Payload, opts DecodeOptions) ([]Payload, error) {
+	out, _, err := decodeDataPayloadToMACCommandsWithErrors(uplink, payloads, opts)
+	return out, err
+}
+
+// decodeDataPayloadToMACCommandsWithErrors decodes a DataPayload into a slice
+// of MACCommands, using the given decode options. In lenient mode, the
+// mac-commands which could not be decoded are returned as MACCommandError
+// slice. In strict mode, these are returned as *MACCommandDecodeError.
+func decodeDataPayloadToMACCommandsWithErrors(uplink bool, payloads []Payload, opts DecodeOptions) ([]Payload, []MACCommandError, error) {
 	if len(payloads) != 1 {
-		return nil, errors.New("lorawan: exactly one Payload expected")
+		return nil, nil, errors.New("lorawan: exactly one Payload expected")
 	}
 
 	dataPL, ok := payloads[0].(*DataPayload)
 	if !ok {
-		return nil, fmt.Errorf("lorawan: expected *DataPayload, got %T", payloads[0])
+		return nil, nil, fmt.Errorf("lorawan: expected *DataPayload, got %T", payloads[0])
 	}
 
 	var plLen int
 	var out []Payload
+	var macErrs []MACCommandError
 
 	for i := 0; i < len(dataPL.Bytes); i++ {
 		if _, s, err := GetMACPayloadAndSize(uplink, CID(dataPL.Bytes[i])); err != nil {
+			if opts.UnknownCIDPolicy == UnknownCIDRaw && !isKnownCID(uplink, CID(dataPL.Bytes[i])) {
+				pl := &RawMACCommandPayload{}
+				if err := pl.UnmarshalBinary(dataPL.Bytes[i+1:]); err != nil {
+					return nil, nil, err
+				}
+				out = append(out, &MACCommand{CID: CID(dataPL.Bytes[i]), Payload: pl})
+				break
+			}
 			plLen = 0
 		} else {
 			plLen = s
 		}
 
 		if len(dataPL.Bytes[i:]) < plLen+1 {
-			return nil, errors.New("lorawan: not enough remaining bytes")
+			return nil, nil, errors.New("lorawan: not enough remaining bytes")
 		}
 
 		mc := &MACCommand{}
-		if err := mc.UnmarshalBinary(uplink, dataPL.Bytes[i:i+1+plLen]); err != nil {
-			log.Printf("warning: unmarshal mac-command error (skipping remaining mac-command bytes): %s", err)
+		if err := mc.UnmarshalBinaryWithOptions(uplink, dataPL.Bytes[i:i+1+plLen], opts); err != nil {
+			macErrs = append(macErrs, MACCommandError{
+				CID:    CID(dataPL.Bytes[i]),
+				Offset: i,
+				Err:    err,
+			})
+
+			if opts.MACCommandDecodeMode == MACCommandDecodeStrict {
+				return nil, nil, &MACCommandDecodeError{Errors: macErrs}
+			}
 		}
 
 		out = append(out, mc)
 		i = i + plLen
 	}
 
-	return out, nil
+	return out, macErrs, nil
 }
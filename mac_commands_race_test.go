@@ -0,0 +1,56 @@
+package lorawan
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMACCommandRegistryConcurrency must be run with -race to detect data
+// races between registering proprietary MAC commands and (un)marshaling
+// MAC commands.
+func TestMACCommandRegistryConcurrency(t *testing.T) {
+	assert := require.New(t)
+
+	var wg sync.WaitGroup
+	errC := make(chan error, 400)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(4)
+
+		go func(i int) {
+			defer wg.Done()
+			errC <- RegisterProprietaryMACCommand(i%2 == 0, CID(0xf0+i%16), 4)
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+			_, _, err := GetMACPayloadAndSize(false, LinkCheckAns)
+			errC <- err
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			var mac MACCommand
+			errC <- mac.UnmarshalBinary(false, []byte{byte(LinkADRReq), 0x25, 0x01, 0x00, 0x61})
+		}()
+
+		go func(i int) {
+			defer wg.Done()
+			mac := MACCommand{
+				CID:     CID(0xf0 + i%16),
+				Payload: &ProprietaryMACCommandPayload{Bytes: []byte{1, 2, 3, 4}},
+			}
+			_, err := mac.MarshalBinary()
+			errC <- err
+		}(i)
+	}
+
+	wg.Wait()
+	close(errC)
+
+	for err := range errC {
+		assert.NoError(err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetMACPayloadAndSize(t *testing.T) {
@@ -369,6 +370,79 @@ func TestDutyCycleReqPayload(t *testing.T) {
 				So(p, ShouldResemble, DutyCycleReqPayload{13})
 			})
 		})
+
+		Convey("Given a slice []byte{16}", func() {
+			b := []byte{16}
+			Convey("Then UnmarshalBinary does not return an error", func() {
+				So(p.UnmarshalBinary(b), ShouldBeNil)
+			})
+
+			Convey("When strict validation is enabled", func() {
+				opts := DecodeOptions{StrictDutyCycleReq: true}
+				var mac MACCommand
+
+				Convey("Then UnmarshalBinaryWithOptions returns an error", func() {
+					So(mac.UnmarshalBinaryWithOptions(false, append([]byte{byte(DutyCycleReq)}, b...), opts), ShouldNotBeNil)
+				})
+
+				Convey("Then UnmarshalBinaryWithOptions accepts MaxDCycle=255", func() {
+					So(mac.UnmarshalBinaryWithOptions(false, []byte{byte(DutyCycleReq), 255}, opts), ShouldBeNil)
+					So(mac.Payload.(*DutyCycleReqPayload).IsSilenced(), ShouldBeTrue)
+				})
+			})
+		})
+
+		Convey("Then DutyCyclePercent returns the expected values", func() {
+			for i, test := range []struct {
+				MaxDCycle uint8
+				Percent   float64
+			}{
+				{0, 100},
+				{1, 50},
+				{7, 0.78125},
+				{15, 0.0030517578125},
+				{16, 0},
+				{255, 0},
+			} {
+				Convey(fmt.Sprintf("Testing: %d [%d]", test.MaxDCycle, i), func() {
+					So(DutyCycleReqPayload{MaxDCycle: test.MaxDCycle}.DutyCyclePercent(), ShouldEqual, test.Percent)
+				})
+			}
+		})
+	})
+}
+
+func TestDutyCycleState(t *testing.T) {
+	Convey("Given an empty DutyCycleState", t, func() {
+		var s DutyCycleState
+
+		Convey("Then the device is not silenced", func() {
+			So(s.IsSilenced(), ShouldBeFalse)
+			So(s.DutyCyclePercent(), ShouldEqual, 100)
+		})
+
+		Convey("When applying MaxDCycle=255", func() {
+			So(s.ApplyDutyCycleReqPayload(DutyCycleReqPayload{MaxDCycle: 255}), ShouldBeNil)
+
+			Convey("Then the device is silenced", func() {
+				So(s.IsSilenced(), ShouldBeTrue)
+				So(s.DutyCyclePercent(), ShouldEqual, 0)
+			})
+
+			Convey("When applying MaxDCycle=1", func() {
+				So(s.ApplyDutyCycleReqPayload(DutyCycleReqPayload{MaxDCycle: 1}), ShouldBeNil)
+
+				Convey("Then the device is re-enabled", func() {
+					So(s.IsSilenced(), ShouldBeFalse)
+					So(s.DutyCyclePercent(), ShouldEqual, 50)
+				})
+			})
+		})
+
+		Convey("Then applying a RFU value returns an error", func() {
+			So(s.ApplyDutyCycleReqPayload(DutyCycleReqPayload{MaxDCycle: 100}), ShouldNotBeNil)
+			So(s.MaxDCycle, ShouldEqual, 0)
+		})
 	})
 }
 
@@ -455,13 +529,21 @@ func TestRXParamSetupReqPayload(t *testing.T) {
 			})
 		})
 
-		Convey("Given Frequency=26265700 and DLSettings(RX2DataRate=11, RX1DROffset=3)", func() {
+		Convey("Given Frequency=26265700 (< 100 MHz)", func() {
 			p.Frequency = 26265700
+			Convey("Then MarshalBinary returns an error", func() {
+				_, err := p.MarshalBinary()
+				So(err, ShouldResemble, errors.New("lorawan: min value of Frequency is 100000000 (or 0)"))
+			})
+		})
+
+		Convey("Given Frequency=868100000 and DLSettings(RX2DataRate=11, RX1DROffset=3)", func() {
+			p.Frequency = 868100000
 			p.DLSettings = DLSettings{RX2DataRate: 11, RX1DROffset: 3}
-			Convey("Then MarshalBinary returns []byte{1, 2, 4, 59}", func() {
+			Convey("Then MarshalBinary returns []byte{59, 40, 118, 132}", func() {
 				b, err := p.MarshalBinary()
 				So(err, ShouldBeNil)
-				So(b, ShouldResemble, []byte{59, 1, 2, 4})
+				So(b, ShouldResemble, []byte{59, 40, 118, 132})
 			})
 		})
 
@@ -606,15 +688,38 @@ func TestNewChannelReqPayload(t *testing.T) {
 			})
 		})
 
-		Convey("Given ChIndex=3, Freq=26265700, MaxDR=5, MinDR=10", func() {
-			p.ChIndex = 3
+		Convey("Given Freq=26265700 (< 100 MHz)", func() {
 			p.Freq = 26265700
+			Convey("MarshalBinary returns an error", func() {
+				_, err := p.MarshalBinary()
+				So(err, ShouldResemble, errors.New("lorawan: min value of Freq is 100000000 (or 0)"))
+			})
+		})
+
+		Convey("Given Freq=0", func() {
+			Convey("Then IsDisabled returns true", func() {
+				So(p.IsDisabled(), ShouldBeTrue)
+			})
+
+			Convey("Then MarshalBinary does not return an error", func() {
+				_, err := p.MarshalBinary()
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("Given ChIndex=3, Freq=868100000, MaxDR=5, MinDR=10", func() {
+			p.ChIndex = 3
+			p.Freq = 868100000
 			p.MaxDR = 5
 			p.MinDR = 10
-			Convey("Then MarshalBinary returns []byte{3, 1, 2, 4, 90}", func() {
+			Convey("Then IsDisabled returns false", func() {
+				So(p.IsDisabled(), ShouldBeFalse)
+			})
+
+			Convey("Then MarshalBinary returns []byte{3, 40, 118, 132, 90}", func() {
 				b, err := p.MarshalBinary()
 				So(err, ShouldBeNil)
-				So(b, ShouldResemble, []byte{3, 1, 2, 4, 90})
+				So(b, ShouldResemble, []byte{3, 40, 118, 132, 90})
 			})
 		})
 
@@ -889,9 +994,18 @@ func TestMACPayloads(t *testing.T) {
 				Payload:       &PingSlotChannelReqPayload{Frequency: 868100000, DR: 5},
 				ExpectedBytes: []byte{40, 118, 132, 5},
 			},
+			{
+				Payload:       &PingSlotChannelReqPayload{DR: 5},
+				ExpectedBytes: []byte{0, 0, 0, 5},
+			},
 		}
 
 		testMACPayloads(func() MACCommandPayload { return &PingSlotChannelReqPayload{} }, tests)
+
+		Convey("Then UsesDefaultFrequency returns true when Frequency is 0", func() {
+			So(PingSlotChannelReqPayload{}.UsesDefaultFrequency(), ShouldBeTrue)
+			So(PingSlotChannelReqPayload{Frequency: 868100000}.UsesDefaultFrequency(), ShouldBeFalse)
+		})
 	})
 
 	Convey("Testing PingSlotChannelAnsPayload", t, func() {
@@ -1106,6 +1220,241 @@ func TestMACPayloads(t *testing.T) {
 
 		testMACPayloads(func() MACCommandPayload { return &RejoinParamSetupAnsPayload{} }, tests)
 	})
+	Convey("Testing RelayConfReqPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload: &RelayConfReqPayload{
+					ChannelSettingsRelay: ChannelSettingsRelay{
+						StartStop:         1,
+						CADPeriodicity:    2,
+						DefaultChIdx:      1,
+						SecondChIdx:       1,
+						SecondChDR:        3,
+						SecondChAckOffset: 2,
+					},
+					SecondChFreq: 868100000,
+				},
+				ExpectedBytes: []byte{154, 42, 40, 118, 132},
+			},
+			{
+				Payload:       &RelayConfReqPayload{ChannelSettingsRelay: ChannelSettingsRelay{CADPeriodicity: 8}},
+				ExpectedError: errors.New("lorawan: max value of CADPeriodicity is 7"),
+			},
+			{
+				Payload:       &RelayConfReqPayload{ChannelSettingsRelay: ChannelSettingsRelay{SecondChIdx: 4}},
+				ExpectedError: errors.New("lorawan: max value of SecondChIdx is 3"),
+			},
+			{
+				Payload:       &RelayConfReqPayload{SecondChFreq: 868100001},
+				ExpectedError: errors.New("lorawan: SecondChFreq must be a multiple of 100"),
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &RelayConfReqPayload{} }, tests)
+	})
+
+	Convey("Testing RelayConfAnsPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload:       &RelayConfAnsPayload{SecondChAckOffsetACK: true, SecondChDRACK: true, SecondChIdxACK: true, DefaultChIdxACK: true, CADPeriodicityACK: true},
+				ExpectedBytes: []byte{31},
+			},
+			{
+				Payload:       &RelayConfAnsPayload{CADPeriodicityACK: true},
+				ExpectedBytes: []byte{16},
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &RelayConfAnsPayload{} }, tests)
+	})
+
+	Convey("Testing EndDeviceConfReqPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload: &EndDeviceConfReqPayload{
+					ActivationRelayMode: ActivationRelayMode{
+						RelayModeActivation: RelayModeActivationDynamic,
+						SmartEnableLevel:    1,
+					},
+					ChannelSettingsED: ChannelSettingsED{
+						Backoff:           10,
+						SecondChIdx:       1,
+						SecondChDR:        3,
+						SecondChAckOffset: 2,
+					},
+					SecondChFreq: 868100000,
+				},
+				ExpectedBytes: []byte{9, 154, 20, 40, 118, 132},
+			},
+			{
+				Payload:       &EndDeviceConfReqPayload{ChannelSettingsED: ChannelSettingsED{Backoff: 64}},
+				ExpectedError: errors.New("lorawan: max value of Backoff is 63"),
+			},
+			{
+				Payload:       &EndDeviceConfReqPayload{ActivationRelayMode: ActivationRelayMode{SmartEnableLevel: 4}},
+				ExpectedError: errors.New("lorawan: max value of SmartEnableLevel is 3"),
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &EndDeviceConfReqPayload{} }, tests)
+	})
+
+	Convey("Testing EndDeviceConfAnsPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload:       &EndDeviceConfAnsPayload{SecondChAckOffsetACK: true, SecondChDRACK: true, SecondChIdxACK: true, BackoffACK: true},
+				ExpectedBytes: []byte{15},
+			},
+			{
+				Payload:       &EndDeviceConfAnsPayload{BackoffACK: true},
+				ExpectedBytes: []byte{8},
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &EndDeviceConfAnsPayload{} }, tests)
+	})
+
+	Convey("Testing FilterListReqPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload: &FilterListReqPayload{
+					FilterListIdx:    3,
+					FilterListAction: FilterListActionForward,
+					JoinEUI:          EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+					DevEUI:           EUI64{9, 10, 11, 12, 13, 14, 15, 16},
+				},
+				ExpectedBytes: []byte{0x13, 8, 7, 6, 5, 4, 3, 2, 1, 16, 15, 14, 13, 12, 11, 10, 9},
+			},
+			{
+				Payload: &FilterListReqPayload{
+					FilterListIdx:    15,
+					FilterListAction: FilterListActionFilter,
+					JoinEUI:          EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+					DevEUI:           EUI64{9, 10, 11, 12, 13, 14, 15, 16},
+				},
+				ExpectedBytes: []byte{0x2f, 8, 7, 6, 5, 4, 3, 2, 1, 16, 15, 14, 13, 12, 11, 10, 9},
+			},
+			{
+				Payload:       &FilterListReqPayload{FilterListIdx: 16},
+				ExpectedError: errors.New("lorawan: max value of FilterListIdx is 15"),
+			},
+			{
+				Payload:       &FilterListReqPayload{FilterListAction: 4},
+				ExpectedError: errors.New("lorawan: max value of FilterListAction is 3"),
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &FilterListReqPayload{} }, tests)
+
+		Convey("Then UnmarshalBinary decodes FilterListIdx from bits 0-3 and FilterListAction from bits 4-5", func() {
+			var pl FilterListReqPayload
+			So(pl.UnmarshalBinary([]byte{0x1a, 8, 7, 6, 5, 4, 3, 2, 1, 16, 15, 14, 13, 12, 11, 10, 9}), ShouldBeNil)
+			So(pl, ShouldResemble, FilterListReqPayload{
+				FilterListIdx:    10,
+				FilterListAction: FilterListActionForward,
+				JoinEUI:          EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+				DevEUI:           EUI64{9, 10, 11, 12, 13, 14, 15, 16},
+			})
+		})
+	})
+
+	Convey("Testing FilterListAnsPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload:       &FilterListAnsPayload{FilterListActionACK: true, FilterListLenACK: true, CombinedRulesACK: true},
+				ExpectedBytes: []byte{7},
+			},
+			{
+				Payload:       &FilterListAnsPayload{FilterListLenACK: true},
+				ExpectedBytes: []byte{2},
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &FilterListAnsPayload{} }, tests)
+	})
+
+	Convey("Testing UpdateUplinkListReqPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload: &UpdateUplinkListReqPayload{
+					UplinkListIdx: 2,
+					UplinkLimit:   UplinkLimit{BucketSize: 1, ReloadRate: 10},
+					DevAddr:       DevAddr{1, 2, 3, 4},
+					WFCnt:         258,
+					RootWorSKey:   AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				},
+				ExpectedBytes: []byte{2, 74, 4, 3, 2, 1, 2, 1, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			},
+			{
+				Payload:       &UpdateUplinkListReqPayload{UplinkListIdx: 16},
+				ExpectedError: errors.New("lorawan: max value of UplinkListIdx is 15"),
+			},
+			{
+				Payload:       &UpdateUplinkListReqPayload{UplinkLimit: UplinkLimit{ReloadRate: 64}},
+				ExpectedError: errors.New("lorawan: max value of ReloadRate is 63"),
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &UpdateUplinkListReqPayload{} }, tests)
+	})
+
+	Convey("Testing CtrlUplinkListReqPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload:       &CtrlUplinkListReqPayload{UplinkListIdx: 5, CtrlUplinkAction: CtrlUplinkActionRemove},
+				ExpectedBytes: []byte{21},
+			},
+			{
+				Payload:       &CtrlUplinkListReqPayload{UplinkListIdx: 16},
+				ExpectedError: errors.New("lorawan: max value of UplinkListIdx is 15"),
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &CtrlUplinkListReqPayload{} }, tests)
+	})
+
+	Convey("Testing CtrlUplinkListAnsPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload:       &CtrlUplinkListAnsPayload{UplinkListIdxACK: true, WFCnt: 258},
+				ExpectedBytes: []byte{1, 2, 1, 0, 0},
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &CtrlUplinkListAnsPayload{} }, tests)
+	})
+
+	Convey("Testing ConfigureFwdLimitReqPayload", t, func() {
+		tests := []macPayloadTest{
+			{
+				Payload: &ConfigureFwdLimitReqPayload{
+					ReloadRate: FwdLimitReloadRate{
+						OverallReloadRate:      1,
+						GlobalUplinkReloadRate: 2,
+						NotifyReloadRate:       3,
+						JoinReqReloadRate:      4,
+						ResetLimitCounter:      ResetLimitCounterReloadRate,
+					},
+					LoadCapacity: FwdLimitLoadCapacity{
+						OverallLimitSize:      1,
+						GlobalUplinkLimitSize: 2,
+						NotifyLimitSize:       3,
+					},
+				},
+				ExpectedBytes: []byte{1, 193, 128, 16, 57},
+			},
+			{
+				Payload:       &ConfigureFwdLimitReqPayload{ReloadRate: FwdLimitReloadRate{NotifyReloadRate: 128}},
+				ExpectedError: errors.New("lorawan: max value of NotifyReloadRate is 127"),
+			},
+			{
+				Payload:       &ConfigureFwdLimitReqPayload{LoadCapacity: FwdLimitLoadCapacity{JoinReqLimitSize: 4}},
+				ExpectedError: errors.New("lorawan: max value of JoinReqLimitSize is 3"),
+			},
+		}
+
+		testMACPayloads(func() MACCommandPayload { return &ConfigureFwdLimitReqPayload{} }, tests)
+	})
 }
 
 func testMACPayloads(newPLFunc func() MACCommandPayload, tests []macPayloadTest) {
@@ -1139,3 +1488,174 @@ func testMACPayloads(newPLFunc func() MACCommandPayload, tests []macPayloadTest)
 		})
 	}
 }
+
+func TestMACCommandDecodeMode(t *testing.T) {
+	// DutyCycleReq with RFU MaxDCycle, DevStatusReq, DutyCycleReq with RFU
+	// MaxDCycle, RXTimingSetupReq
+	fOpts := []byte{byte(DutyCycleReq), 16, byte(DevStatusReq), byte(DutyCycleReq), 20, byte(RXTimingSetupReq), 0x05}
+	dcErr := errors.New("lorawan: only a MaxDCycle value of 0 - 15 and 255 is allowed")
+
+	tests := []struct {
+		Name           string
+		Mode           MACCommandDecodeMode
+		ExpectedFOpts  []Payload
+		ExpectedErrors []MACCommandError
+		ExpectedError  error
+	}{
+		{
+			Name: "lenient",
+			Mode: MACCommandDecodeLenient,
+			ExpectedFOpts: []Payload{
+				&MACCommand{CID: DutyCycleReq, Payload: &DutyCycleReqPayload{MaxDCycle: 16}},
+				&MACCommand{CID: DevStatusReq},
+				&MACCommand{CID: DutyCycleReq, Payload: &DutyCycleReqPayload{MaxDCycle: 20}},
+				&MACCommand{CID: RXTimingSetupReq, Payload: &RXTimingSetupReqPayload{Delay: 5}},
+			},
+			ExpectedErrors: []MACCommandError{
+				{CID: DutyCycleReq, Offset: 0, Err: dcErr},
+				{CID: DutyCycleReq, Offset: 3, Err: dcErr},
+			},
+		},
+		{
+			Name: "strict",
+			Mode: MACCommandDecodeStrict,
+			ExpectedFOpts: []Payload{
+				&DataPayload{Bytes: fOpts},
+			},
+			ExpectedError: &MACCommandDecodeError{
+				Errors: []MACCommandError{
+					{CID: DutyCycleReq, Offset: 0, Err: dcErr},
+				},
+			},
+		},
+	}
+
+	newPHYPayload := func() PHYPayload {
+		return PHYPayload{
+			MHDR: MHDR{MType: UnconfirmedDataDown, Major: LoRaWANR1},
+			MACPayload: &MACPayload{
+				FHDR: FHDR{
+					FOpts: []Payload{&DataPayload{Bytes: fOpts}},
+				},
+			},
+		}
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			phy := newPHYPayload()
+			macErrs, err := phy.DecodeFOptsToMACCommandsWithOptions(DecodeOptions{
+				StrictDutyCycleReq:   true,
+				MACCommandDecodeMode: tst.Mode,
+			})
+			assert.Equal(tst.ExpectedError, err)
+			assert.Equal(tst.ExpectedErrors, macErrs)
+			assert.Equal(tst.ExpectedFOpts, phy.MACPayload.(*MACPayload).FHDR.FOpts)
+		})
+	}
+
+	t.Run("lenient unknown cid", func(t *testing.T) {
+		assert := require.New(t)
+
+		phy := PHYPayload{
+			MHDR: MHDR{MType: UnconfirmedDataUp, Major: LoRaWANR1},
+			MACPayload: &MACPayload{
+				FPort:      new(uint8),
+				FRMPayload: []Payload{&DataPayload{Bytes: []byte{byte(DevStatusAns), 10, 20, 0x7e}}},
+			},
+		}
+
+		assert.NoError(phy.DecodeFRMPayloadToMACCommands())
+		assert.Equal([]Payload{
+			&MACCommand{CID: DevStatusAns, Payload: &DevStatusAnsPayload{Battery: 10, Margin: 20}},
+			&MACCommand{CID: CID(0x7e)},
+		}, phy.MACPayload.(*MACPayload).FRMPayload)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		assert := require.New(t)
+
+		err := &MACCommandDecodeError{
+			Errors: []MACCommandError{
+				{CID: DutyCycleReq, Offset: 0, Err: dcErr},
+				{CID: CID(0x12), Offset: 3, Err: errors.New("boom")},
+			},
+		}
+		assert.Equal("lorawan: decode mac-commands error: DutyCycleReq (offset 0): lorawan: only a MaxDCycle value of 0 - 15 and 255 is allowed, CID(18) (offset 3): boom", err.Error())
+		assert.Equal([]CID{DutyCycleReq, CID(0x12)}, err.CIDs())
+		assert.Equal(dcErr, errors.Unwrap(err))
+	})
+}
+
+func TestUnknownCIDPolicy(t *testing.T) {
+	// DevStatusReq, deprecated BeaconTimingAns (0x12) with unknown payload,
+	// DevStatusReq
+	fOpts := []byte{byte(DevStatusReq), 0x12, 0x01, 0x02, byte(DevStatusReq)}
+
+	tests := []struct {
+		Name          string
+		Policy        UnknownCIDPolicy
+		ExpectedFOpts []Payload
+	}{
+		{
+			Name:   "skip",
+			Policy: UnknownCIDSkip,
+			ExpectedFOpts: []Payload{
+				&MACCommand{CID: DevStatusReq},
+				&MACCommand{CID: 0x12},
+				&MACCommand{CID: ResetConf, Payload: &ResetConfPayload{ServLoRaWANVersion: Version{Minor: 2}}},
+				&MACCommand{CID: DevStatusReq},
+			},
+		},
+		{
+			Name:   "raw",
+			Policy: UnknownCIDRaw,
+			ExpectedFOpts: []Payload{
+				&MACCommand{CID: DevStatusReq},
+				&MACCommand{CID: 0x12, Payload: &RawMACCommandPayload{Bytes: []byte{0x01, 0x02, byte(DevStatusReq)}}},
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			phy := PHYPayload{
+				MHDR: MHDR{MType: UnconfirmedDataDown, Major: LoRaWANR1},
+				MACPayload: &MACPayload{
+					FHDR: FHDR{
+						FOpts: []Payload{&DataPayload{Bytes: fOpts}},
+					},
+				},
+			}
+
+			_, err := phy.DecodeFOptsToMACCommandsWithOptions(DecodeOptions{UnknownCIDPolicy: tst.Policy})
+			assert.NoError(err)
+			assert.Equal(tst.ExpectedFOpts, phy.MACPayload.(*MACPayload).FHDR.FOpts)
+
+			// the FOpts bytes are retained
+			b, err := phy.MACPayload.(*MACPayload).FHDR.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(fOpts, b[7:])
+		})
+	}
+
+	t.Run("queue", func(t *testing.T) {
+		assert := require.New(t)
+
+		q := MACCommandQueue{
+			Commands: []MACCommand{
+				{CID: 0x12, Payload: &RawMACCommandPayload{Bytes: []byte{0x01, 0x02}}},
+			},
+		}
+		b, err := q.MarshalBinary()
+		assert.NoError(err)
+
+		var out MACCommandQueue
+		assert.NoError(out.UnmarshalBinary(b))
+		assert.Equal(q, out)
+	})
+}
@@ -13,58 +13,77 @@ type MACPayload struct {
 }
 
 func (p MACPayload) marshalPayload() ([]byte, error) {
-	var out []byte
-	var b []byte
+	return p.appendPayload(nil)
+}
+
+// appendPayload appends the FRMPayload in binary form to b.
+func (p MACPayload) appendPayload(b []byte) ([]byte, error) {
 	var err error
 	for _, fp := range p.FRMPayload {
-		if mac, ok := fp.(*MACCommand); ok {
+		if _, ok := fp.(*MACCommand); ok {
 			if p.FPort == nil || (p.FPort != nil && *p.FPort != 0) {
-				return []byte{}, errors.New("lorawan: a MAC command is only allowed when FPort=0")
+				return b, errors.New("lorawan: a MAC command is only allowed when FPort=0")
 			}
-			b, err = mac.MarshalBinary()
-		} else {
-			b, err = fp.MarshalBinary()
 		}
-		if err != nil {
-			return nil, err
+		if b, err = appendPayload(b, fp); err != nil {
+			return b, err
 		}
-		out = append(out, b...)
 	}
-	return out, nil
+	return b, nil
 }
 
 // MarshalBinary marshals the object in binary form.
 func (p MACPayload) MarshalBinary() ([]byte, error) {
-	var b []byte
-	var out []byte
-	var err error
-
-	b, err = p.FHDR.MarshalBinary()
+	b, err := p.appendBinary(nil)
 	if err != nil {
 		return nil, err
 	}
-	out = append(out, b...)
+	return b, nil
+}
+
+// appendBinary appends the object in binary form to b.
+func (p MACPayload) appendBinary(b []byte) ([]byte, error) {
+	b, err := p.FHDR.appendBinary(b)
+	if err != nil {
+		return b, err
+	}
 
 	if p.FPort == nil {
 		if len(p.FRMPayload) != 0 {
-			return nil, errors.New("lorawan: FPort must be set when FRMPayload is not empty")
+			return b, errors.New("lorawan: FPort must be set when FRMPayload is not empty")
 		}
-		return out, nil
+		return b, nil
 	} else if len(p.FHDR.FOpts) != 0 && *p.FPort == 0 {
-		return nil, errors.New("lorawan: FPort must not be 0 when FOpts are set")
+		return b, errors.New("lorawan: FPort must not be 0 when FOpts are set")
 	}
 
-	out = append(out, *p.FPort)
+	b = append(b, *p.FPort)
+	return p.appendPayload(b)
+}
 
-	if b, err = p.marshalPayload(); err != nil {
-		return nil, err
+// appendPayload appends the given payload in binary form to b. The bytes of
+// a DataPayload are appended directly, to avoid an intermediate slice.
+func appendPayload(b []byte, pl Payload) ([]byte, error) {
+	if dataPL, ok := pl.(*DataPayload); ok {
+		return append(b, dataPL.Bytes...), nil
 	}
-	out = append(out, b...)
-	return out, nil
+
+	plB, err := pl.MarshalBinary()
+	if err != nil {
+		return b, err
+	}
+	return append(b, plB...), nil
 }
 
 // UnmarshalBinary decodes the object from binary form.
 func (p *MACPayload) UnmarshalBinary(uplink bool, data []byte) error {
+	return p.unmarshalBinary(uplink, data, false)
+}
+
+// unmarshalBinary decodes the object from binary form. When reuse is set,
+// the FPort and the DataPayload of the current MACPayload (if any) are
+// re-used.
+func (p *MACPayload) unmarshalBinary(uplink bool, data []byte, reuse bool) error {
 	dataLen := len(data)
 
 	// check that there are enough bytes to decode a minimal FHDR
@@ -83,14 +102,20 @@ func (p *MACPayload) UnmarshalBinary(uplink bool, data []byte) error {
 	}
 
 	// decode the full FHDR (including optional FOpts)
-	if err := p.FHDR.UnmarshalBinary(uplink, data[0:7+p.FHDR.FCtrl.fOptsLen]); err != nil {
+	if err := p.FHDR.unmarshalBinary(uplink, data[0:7+p.FHDR.FCtrl.fOptsLen], reuse); err != nil {
 		return err
 	}
 
 	// decode the optional FPort
 	if dataLen > 7+int(p.FHDR.FCtrl.fOptsLen) {
-		fPort := uint8(data[7+int(p.FHDR.FCtrl.fOptsLen)])
-		p.FPort = &fPort
+		if reuse && p.FPort != nil {
+			*p.FPort = data[7+int(p.FHDR.FCtrl.fOptsLen)]
+		} else {
+			fPort := uint8(data[7+int(p.FHDR.FCtrl.fOptsLen)])
+			p.FPort = &fPort
+		}
+	} else if reuse {
+		p.FPort = nil
 	}
 
 	// decode the rest of the payload (if present)
@@ -101,8 +126,24 @@ func (p *MACPayload) UnmarshalBinary(uplink bool, data []byte) error {
 
 		// even when FPort = 0, we store the mac-commands within a DataPayload.
 		// only after decryption we're able to unmarshal them.
-		p.FRMPayload = []Payload{&DataPayload{Bytes: data[7+p.FHDR.FCtrl.fOptsLen+1:]}}
+		p.FRMPayload = getDataPayloads(p.FRMPayload, data[7+p.FHDR.FCtrl.fOptsLen+1:], reuse)
+	} else if reuse {
+		p.FRMPayload = nil
 	}
 
 	return nil
 }
+
+// getDataPayloads returns a slice containing a single DataPayload with the
+// given bytes. When reuse is set and the given payloads contain a single
+// DataPayload, then this is re-used.
+func getDataPayloads(pls []Payload, b []byte, reuse bool) []Payload {
+	if reuse && len(pls) == 1 {
+		if dataPL, ok := pls[0].(*DataPayload); ok {
+			dataPL.Bytes = b
+			return pls
+		}
+	}
+
+	return []Payload{&DataPayload{Bytes: b}}
+}
@@ -112,7 +112,7 @@ func TestMACPayload(t *testing.T) {
 				So(err, ShouldBeNil)
 
 				// normally the mac commands are unmarshaled after decryption
-				_, err = decodeDataPayloadToMACCommands(true, p.FRMPayload)
+				_, err = decodeDataPayloadToMACCommands(true, p.FRMPayload, DecodeOptions{})
 				So(err, ShouldResemble, errors.New("lorawan: not enough remaining bytes"))
 			})
 		})
@@ -134,7 +134,7 @@ func TestMACPayload(t *testing.T) {
 				Convey("Then FRMPayload=[]Payload{MACCommand{CID: DevStatusAns, Payload: DevStatusAnsPayload(Battery=10, Margin=20)}}", func() {
 					// mac commands are normally unmarshaled when decrypting
 					var err error
-					p.FRMPayload, err = decodeDataPayloadToMACCommands(true, p.FRMPayload)
+					p.FRMPayload, err = decodeDataPayloadToMACCommands(true, p.FRMPayload, DecodeOptions{})
 					So(err, ShouldBeNil)
 
 					So(p.FRMPayload, ShouldHaveLength, 1)
@@ -160,7 +160,7 @@ func TestMACPayload(t *testing.T) {
 				So(err, ShouldBeNil)
 
 				// mac commands are normally unmarshaled when decrypting
-				p.FRMPayload, err = decodeDataPayloadToMACCommands(true, p.FRMPayload)
+				p.FRMPayload, err = decodeDataPayloadToMACCommands(true, p.FRMPayload, DecodeOptions{})
 				So(err, ShouldBeNil)
 
 				Convey("Then FHDR(DevAddr=[4]byte{1, 2, 3, 4})", func() {
@@ -1,14 +1,26 @@
 package lorawan
 
 import (
-	"database/sql/driver"
 	"encoding/binary"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"strings"
 )
 
+// devAddrPrefixBits contains per NetID type the type-prefix length and the
+// number of NwkID bits of the DevAddr, as defined by the LoRaWAN Backend
+// Interfaces specification.
+var devAddrPrefixBits = [8][2]int{
+	{1, 6},
+	{2, 6},
+	{3, 9},
+	{4, 11},
+	{5, 12},
+	{6, 13},
+	{7, 15},
+	{8, 17},
+}
+
 // NetID represents the NetID.
 type NetID [3]byte
 
@@ -17,6 +29,26 @@ func (n NetID) Type() int {
 	return int(n[0] >> 5)
 }
 
+// NwkIDBits returns the number of NwkID bits of the DevAddr for the NetID
+// type.
+func (n NetID) NwkIDBits() int {
+	return devAddrPrefixBits[n.Type()][1]
+}
+
+// NwkID returns the NwkID, which equals the NwkIDBits least-significant bits
+// of the NetID ID.
+func (n NetID) NwkID() []byte {
+	return n.getID(n.NwkIDBits())
+}
+
+// AddrPrefixLength returns the length (in bits) of the AddrPrefix of the
+// DevAddr (the type-prefix and the NwkID) for the NetID type. The remaining
+// bits of the DevAddr are used for the NwkAddr.
+func (n NetID) AddrPrefixLength() int {
+	bits := devAddrPrefixBits[n.Type()]
+	return bits[0] + bits[1]
+}
+
 // ID returns the NetID ID part.
 func (n NetID) ID() []byte {
 	switch n.Type() {
@@ -99,21 +131,3 @@ func (n *NetID) UnmarshalBinary(data []byte) error {
 
 	return nil
 }
-
-// Value implements driver.Valuer.
-func (n NetID) Value() (driver.Value, error) {
-	return n[:], nil
-}
-
-// Scan implements sql.Scanner.
-func (n *NetID) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return errors.New("lorawan: []byte type expected")
-	}
-	if len(b) != len(n) {
-		return fmt.Errorf("lorawan: []byte must have length %d", len(n))
-	}
-	copy(n[:], b)
-	return nil
-}
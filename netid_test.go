@@ -10,76 +10,94 @@ import (
 func TestNetID(t *testing.T) {
 	Convey("Given a set of tests", t, func() {
 		tests := []struct {
-			Name   string
-			NetID  NetID
-			Type   int
-			ID     []byte
-			Bytes  []byte
-			String string
+			Name             string
+			NetID            NetID
+			Type             int
+			ID               []byte
+			NwkID            []byte
+			AddrPrefixLength int
+			Bytes            []byte
+			String           string
 		}{
 			{
-				Name:   "NetID type 0",
-				NetID:  NetID{0, 0, 109},
-				Type:   0,
-				ID:     []byte{45},
-				Bytes:  []byte{109, 0, 0},
-				String: "00006d",
+				Name:             "NetID type 0",
+				NetID:            NetID{0, 0, 109},
+				Type:             0,
+				ID:               []byte{45},
+				NwkID:            []byte{45},
+				AddrPrefixLength: 7,
+				Bytes:            []byte{109, 0, 0},
+				String:           "00006d",
 			},
 			{
-				Name:   "NetID type 1",
-				NetID:  NetID{32, 0, 109},
-				Type:   1,
-				ID:     []byte{45},
-				Bytes:  []byte{109, 0, 32},
-				String: "20006d",
+				Name:             "NetID type 1",
+				NetID:            NetID{32, 0, 109},
+				Type:             1,
+				ID:               []byte{45},
+				NwkID:            []byte{45},
+				AddrPrefixLength: 8,
+				Bytes:            []byte{109, 0, 32},
+				String:           "20006d",
 			},
 			{
-				Name:   "NetID type 2",
-				NetID:  NetID{64, 3, 109},
-				Type:   2,
-				ID:     []byte{1, 109},
-				Bytes:  []byte{109, 3, 64},
-				String: "40036d",
+				Name:             "NetID type 2",
+				NetID:            NetID{64, 3, 109},
+				Type:             2,
+				ID:               []byte{1, 109},
+				NwkID:            []byte{1, 109},
+				AddrPrefixLength: 12,
+				Bytes:            []byte{109, 3, 64},
+				String:           "40036d",
 			},
 			{
-				Name:   "NetID type 3",
-				NetID:  NetID{118, 219, 109},
-				Type:   3,
-				ID:     []byte{22, 219, 109},
-				Bytes:  []byte{109, 219, 118},
-				String: "76db6d",
+				Name:             "NetID type 3",
+				NetID:            NetID{118, 219, 109},
+				Type:             3,
+				ID:               []byte{22, 219, 109},
+				NwkID:            []byte{3, 109},
+				AddrPrefixLength: 15,
+				Bytes:            []byte{109, 219, 118},
+				String:           "76db6d",
 			},
 			{
-				Name:   "NetID type 4",
-				NetID:  NetID{150, 219, 109},
-				Type:   4,
-				ID:     []byte{22, 219, 109},
-				Bytes:  []byte{109, 219, 150},
-				String: "96db6d",
+				Name:             "NetID type 4",
+				NetID:            NetID{150, 219, 109},
+				Type:             4,
+				ID:               []byte{22, 219, 109},
+				NwkID:            []byte{11, 109},
+				AddrPrefixLength: 17,
+				Bytes:            []byte{109, 219, 150},
+				String:           "96db6d",
 			},
 			{
-				Name:   "NetID type 5",
-				NetID:  NetID{182, 219, 109},
-				Type:   5,
-				ID:     []byte{22, 219, 109},
-				Bytes:  []byte{109, 219, 182},
-				String: "b6db6d",
+				Name:             "NetID type 5",
+				NetID:            NetID{182, 219, 109},
+				Type:             5,
+				ID:               []byte{22, 219, 109},
+				NwkID:            []byte{27, 109},
+				AddrPrefixLength: 19,
+				Bytes:            []byte{109, 219, 182},
+				String:           "b6db6d",
 			},
 			{
-				Name:   "NetID type 6",
-				NetID:  NetID{214, 219, 109},
-				Type:   6,
-				ID:     []byte{22, 219, 109},
-				Bytes:  []byte{109, 219, 214},
-				String: "d6db6d",
+				Name:             "NetID type 6",
+				NetID:            NetID{214, 219, 109},
+				Type:             6,
+				ID:               []byte{22, 219, 109},
+				NwkID:            []byte{91, 109},
+				AddrPrefixLength: 22,
+				Bytes:            []byte{109, 219, 214},
+				String:           "d6db6d",
 			},
 			{
-				Name:   "NetID type 7",
-				NetID:  NetID{246, 219, 109},
-				Type:   7,
-				ID:     []byte{22, 219, 109},
-				Bytes:  []byte{109, 219, 246},
-				String: "f6db6d",
+				Name:             "NetID type 7",
+				NetID:            NetID{246, 219, 109},
+				Type:             7,
+				ID:               []byte{22, 219, 109},
+				NwkID:            []byte{0, 219, 109},
+				AddrPrefixLength: 25,
+				Bytes:            []byte{109, 219, 246},
+				String:           "f6db6d",
 			},
 		}
 
@@ -87,6 +105,13 @@ func TestNetID(t *testing.T) {
 			Convey(fmt.Sprintf("Testing: %s [%d]", test.Name, i), func() {
 				So(test.NetID.Type(), ShouldEqual, test.Type)
 				So(test.NetID.ID(), ShouldResemble, test.ID)
+				So(test.NetID.NwkID(), ShouldResemble, test.NwkID)
+				So(test.NetID.NwkIDBits()+test.NetID.Type()+1, ShouldEqual, test.AddrPrefixLength)
+				So(test.NetID.AddrPrefixLength(), ShouldEqual, test.AddrPrefixLength)
+
+				var devAddr DevAddr
+				devAddr.SetAddrPrefix(test.NetID)
+				So(devAddr.NwkID(), ShouldResemble, test.NwkID)
 
 				b, err := test.NetID.MarshalBinary()
 				So(err, ShouldBeNil)
@@ -0,0 +1,124 @@
+package lorawan
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxJoinNonce defines the max. JoinNonce value (24 bits).
+const MaxJoinNonce JoinNonce = 1<<24 - 1
+
+// MaxDevNonce defines the max. DevNonce value (16 bits).
+const MaxDevNonce DevNonce = 1<<16 - 1
+
+// Nonce errors. In LoRaWAN 1.1, the JoinNonce and DevNonce are counters
+// which must never be re-used for the same root-keys. Once a counter has
+// reached its max. value, the device must be re-provisioned (e.g. with new
+// root-keys).
+var (
+	ErrJoinNonceOverflow = errors.New("lorawan: JoinNonce overflow, the device must be re-provisioned")
+	ErrDevNonceOverflow  = errors.New("lorawan: DevNonce overflow, the device must be re-provisioned")
+	ErrDevNonceReplay    = errors.New("lorawan: DevNonce must be greater than the last DevNonce")
+)
+
+// NonceWrapPolicy defines the behavior when a nonce counter is incremented
+// beyond its max. value.
+type NonceWrapPolicy int
+
+// Available nonce wrap policies.
+const (
+	// NonceWrapError returns an overflow error. This is the behavior
+	// mandated by LoRaWAN 1.1.
+	NonceWrapError NonceWrapPolicy = iota
+
+	// NonceWrapAround wraps the counter around to 0. Note that this results
+	// in the re-use of nonces (and thus session-keys) and must only be used
+	// for e.g. testing purposes.
+	NonceWrapAround
+)
+
+// Validate returns ErrJoinNonceOverflow when the JoinNonce exceeds
+// MaxJoinNonce.
+func (n JoinNonce) Validate() error {
+	if n > MaxJoinNonce {
+		return ErrJoinNonceOverflow
+	}
+	return nil
+}
+
+// Next returns the next JoinNonce, using the given wrap policy when the
+// JoinNonce equals MaxJoinNonce.
+func (n JoinNonce) Next(policy NonceWrapPolicy) (JoinNonce, error) {
+	if err := n.Validate(); err != nil {
+		return 0, err
+	}
+	if n < MaxJoinNonce {
+		return n + 1, nil
+	}
+
+	switch policy {
+	case NonceWrapError:
+		return 0, ErrJoinNonceOverflow
+	case NonceWrapAround:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("lorawan: invalid nonce wrap policy: %d", policy)
+	}
+}
+
+// Next returns the next DevNonce, using the given wrap policy when the
+// DevNonce equals MaxDevNonce.
+func (n DevNonce) Next(policy NonceWrapPolicy) (DevNonce, error) {
+	if n < MaxDevNonce {
+		return n + 1, nil
+	}
+
+	switch policy {
+	case NonceWrapError:
+		return 0, ErrDevNonceOverflow
+	case NonceWrapAround:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("lorawan: invalid nonce wrap policy: %d", policy)
+	}
+}
+
+// DevNonceState tracks the last DevNonce of a LoRaWAN 1.1 device, for which
+// the DevNonce is a counter that must be incremented for every join-request.
+type DevNonceState struct {
+	// Valid is set once a DevNonce has been accepted.
+	Valid bool `json:"valid"`
+
+	// DevNonce holds the last accepted DevNonce.
+	DevNonce DevNonce `json:"devNonce"`
+}
+
+// Validate validates the given DevNonce against the state, without updating
+// it. It returns ErrDevNonceOverflow when the last DevNonce equals
+// MaxDevNonce and ErrDevNonceReplay when the given DevNonce is not greater
+// than the last DevNonce.
+func (s DevNonceState) Validate(devNonce DevNonce) error {
+	if !s.Valid {
+		return nil
+	}
+	if s.DevNonce == MaxDevNonce {
+		return ErrDevNonceOverflow
+	}
+	if devNonce <= s.DevNonce {
+		return ErrDevNonceReplay
+	}
+	return nil
+}
+
+// Accept validates the given DevNonce and on success, stores it as the last
+// DevNonce.
+func (s *DevNonceState) Accept(devNonce DevNonce) error {
+	if err := s.Validate(devNonce); err != nil {
+		return err
+	}
+
+	s.Valid = true
+	s.DevNonce = devNonce
+
+	return nil
+}
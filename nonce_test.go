@@ -0,0 +1,85 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinNonceNext(t *testing.T) {
+	tests := []struct {
+		Name          string
+		JoinNonce     JoinNonce
+		Policy        NonceWrapPolicy
+		Expected      JoinNonce
+		ExpectedError error
+	}{
+		{
+			Name:      "increment",
+			JoinNonce: 10,
+			Expected:  11,
+		},
+		{
+			Name:          "overflow",
+			JoinNonce:     MaxJoinNonce,
+			ExpectedError: ErrJoinNonceOverflow,
+		},
+		{
+			Name:      "wrap around",
+			JoinNonce: MaxJoinNonce,
+			Policy:    NonceWrapAround,
+			Expected:  0,
+		},
+		{
+			Name:          "exceeds 24 bits",
+			JoinNonce:     MaxJoinNonce + 1,
+			Policy:        NonceWrapAround,
+			ExpectedError: ErrJoinNonceOverflow,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			n, err := tst.JoinNonce.Next(tst.Policy)
+			assert.Equal(tst.ExpectedError, err)
+			assert.Equal(tst.Expected, n)
+		})
+	}
+}
+
+func TestDevNonceNext(t *testing.T) {
+	assert := require.New(t)
+
+	n, err := DevNonce(10).Next(NonceWrapError)
+	assert.NoError(err)
+	assert.Equal(DevNonce(11), n)
+
+	_, err = MaxDevNonce.Next(NonceWrapError)
+	assert.Equal(ErrDevNonceOverflow, err)
+
+	n, err = MaxDevNonce.Next(NonceWrapAround)
+	assert.NoError(err)
+	assert.Equal(DevNonce(0), n)
+
+	_, err = MaxDevNonce.Next(NonceWrapPolicy(5))
+	assert.EqualError(err, "lorawan: invalid nonce wrap policy: 5")
+}
+
+func TestDevNonceState(t *testing.T) {
+	assert := require.New(t)
+
+	var s DevNonceState
+	assert.NoError(s.Accept(0))
+	assert.Equal(DevNonceState{Valid: true, DevNonce: 0}, s)
+
+	assert.NoError(s.Accept(5))
+	assert.Equal(ErrDevNonceReplay, s.Accept(5))
+	assert.Equal(ErrDevNonceReplay, s.Accept(4))
+	assert.Equal(DevNonce(5), s.DevNonce)
+
+	assert.NoError(s.Accept(MaxDevNonce))
+	assert.Equal(ErrDevNonceOverflow, s.Validate(0))
+	assert.Equal(ErrDevNonceOverflow, s.Accept(MaxDevNonce))
+}
@@ -1,7 +1,6 @@
 package lorawan
 
 import (
-	"database/sql/driver"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
@@ -68,24 +67,6 @@ func (e *EUI64) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
-// Scan implements sql.Scanner.
-func (e *EUI64) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return errors.New("lorawan: []byte type expected")
-	}
-	if len(b) != len(e) {
-		return fmt.Errorf("lorawan: []byte must have length %d", len(e))
-	}
-	copy(e[:], b)
-	return nil
-}
-
-// Value implements driver.Valuer.
-func (e EUI64) Value() (driver.Value, error) {
-	return e[:], nil
-}
-
 // DevNonce represents the dev-nonce.
 type DevNonce uint16
 
@@ -362,6 +343,12 @@ func (p *CFListChannelMaskPayload) UnmarshalBinary(uplink bool, data []byte) err
 	return nil
 }
 
+// Join-accept payload sizes (excluding MHDR and MIC).
+const (
+	JoinAcceptPayloadSize           = 12
+	JoinAcceptPayloadWithCFListSize = JoinAcceptPayloadSize + 16
+)
+
 // JoinAcceptPayload represents the join-accept message payload.
 type JoinAcceptPayload struct {
 	JoinNonce  JoinNonce  `json:"joinNonce"`
@@ -372,13 +359,52 @@ type JoinAcceptPayload struct {
 	CFList     *CFList    `json:"cFlist"`
 }
 
+// Size returns the size in bytes of the join-accept payload. This is
+// JoinAcceptPayloadWithCFListSize when a CFList is present, else
+// JoinAcceptPayloadSize.
+func (p JoinAcceptPayload) Size() int {
+	if p.CFList != nil {
+		return JoinAcceptPayloadWithCFListSize
+	}
+	return JoinAcceptPayloadSize
+}
+
+// Validate validates the join-accept payload. It returns an error when
+// the RXDelay is out of range, or when the CFList payload does not match
+// the CFListType.
+func (p JoinAcceptPayload) Validate() error {
+	if p.RXDelay > 15 {
+		return errors.New("lorawan: the max value of RXDelay is 15")
+	}
+
+	if p.CFList == nil {
+		return nil
+	}
+
+	var valid bool
+	switch p.CFList.CFListType {
+	case CFListChannel:
+		_, valid = p.CFList.Payload.(*CFListChannelPayload)
+	case CFListChannelMask:
+		_, valid = p.CFList.Payload.(*CFListChannelMaskPayload)
+	default:
+		return fmt.Errorf("lorawan: invalid CFListType %d", p.CFList.CFListType)
+	}
+
+	if !valid {
+		return fmt.Errorf("lorawan: invalid CFList payload %T for CFListType %d", p.CFList.Payload, p.CFList.CFListType)
+	}
+
+	return nil
+}
+
 // MarshalBinary marshals the object in binary form.
 func (p JoinAcceptPayload) MarshalBinary() ([]byte, error) {
-	if p.RXDelay > 15 {
-		return nil, errors.New("lorawan: the max value of RXDelay is 15")
+	if err := p.Validate(); err != nil {
+		return nil, err
 	}
 
-	out := make([]byte, 0, 12)
+	out := make([]byte, 0, p.Size())
 
 	b, err := p.JoinNonce.MarshalBinary()
 	if err != nil {
@@ -419,7 +445,7 @@ func (p JoinAcceptPayload) MarshalBinary() ([]byte, error) {
 // UnmarshalBinary decodes the object from binary form.
 func (p *JoinAcceptPayload) UnmarshalBinary(uplink bool, data []byte) error {
 	l := len(data)
-	if l != 12 && l != 28 {
+	if l != JoinAcceptPayloadSize && l != JoinAcceptPayloadWithCFListSize {
 		return errors.New("lorawan: 12 or 28 bytes of data are expected (28 bytes if CFList is present)")
 	}
 
@@ -439,7 +465,8 @@ func (p *JoinAcceptPayload) UnmarshalBinary(uplink bool, data []byte) error {
 	}
 	p.RXDelay = uint8(data[11])
 
-	if l == 28 {
+	p.CFList = nil
+	if l == JoinAcceptPayloadWithCFListSize {
 		p.CFList = &CFList{}
 		if err := p.CFList.UnmarshalBinary(data[12:]); err != nil {
 			return err
@@ -1,7 +1,6 @@
 package lorawan
 
 import (
-	"database/sql/driver"
 	"errors"
 	"fmt"
 	"testing"
@@ -21,12 +20,6 @@ func TestEUI64(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(string(b), ShouldEqual, "0102030405060708")
 			})
-
-			Convey("Then Value returns the expected value", func() {
-				v, err := eui.Value()
-				So(err, ShouldBeNil)
-				So(v, ShouldResemble, driver.Value(eui[:]))
-			})
 		})
 
 		Convey("Given the string 0102030405060708", func() {
@@ -38,14 +31,6 @@ func TestEUI64(t *testing.T) {
 				So(eui, ShouldResemble, EUI64{1, 2, 3, 4, 5, 6, 7, 8})
 			})
 		})
-
-		Convey("Given []byte{1, 2, 3, 4, 5, 6, 7, 8}", func() {
-			b := []byte{1, 2, 3, 4, 5, 6, 7, 8}
-			Convey("Then Scan scans the value correctly", func() {
-				So(eui.Scan(b), ShouldBeNil)
-				So(eui[:], ShouldResemble, b)
-			})
-		})
 	})
 }
 
@@ -266,6 +251,15 @@ func TestCFList(t *testing.T) {
 func TestJoinAcceptPayload(t *testing.T) {
 	Convey("Given an empty JoinAcceptPayload", t, func() {
 		var p JoinAcceptPayload
+
+		Convey("Then Size returns JoinAcceptPayloadSize", func() {
+			So(p.Size(), ShouldEqual, JoinAcceptPayloadSize)
+		})
+
+		Convey("Then Validate returns an error when RXDelay > 15", func() {
+			p.RXDelay = 16
+			So(p.Validate(), ShouldResemble, errors.New("lorawan: the max value of RXDelay is 15"))
+		})
 		Convey("Then MarshalBinary returns []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0", func() {
 			b, err := p.MarshalBinary()
 			So(err, ShouldBeNil)
@@ -313,6 +307,18 @@ func TestJoinAcceptPayload(t *testing.T) {
 				So(b, ShouldResemble, []byte{1, 1, 1, 2, 2, 2, 4, 3, 2, 1, 103, 9, 24, 79, 132, 232, 86, 132, 184, 94, 132, 136, 102, 132, 88, 110, 132, 0})
 			})
 
+			Convey("Then Size returns JoinAcceptPayloadWithCFListSize", func() {
+				So(p.Size(), ShouldEqual, JoinAcceptPayloadWithCFListSize)
+			})
+
+			Convey("Then Validate returns an error when the CFList payload does not match the CFListType", func() {
+				p.CFList.CFListType = CFListChannelMask
+				So(p.Validate(), ShouldNotBeNil)
+
+				_, err := p.MarshalBinary()
+				So(err, ShouldNotBeNil)
+			})
+
 		})
 
 		Convey("Given a slice of bytes with an invalid size", func() {
@@ -6,17 +6,14 @@ package lorawan
 
 import (
 	"bytes"
-	"crypto/aes"
-	"database/sql/driver"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/jacobsa/crypto/cmac"
+	"github.com/brocaar/lorawan/crypto"
 )
 
 // MType represents the message type.
@@ -27,6 +24,17 @@ func (m MType) MarshalText() ([]byte, error) {
 	return []byte(m.String()), nil
 }
 
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *MType) UnmarshalText(text []byte) error {
+	for i := JoinRequest; i <= Proprietary; i++ {
+		if i.String() == string(text) {
+			*m = i
+			return nil
+		}
+	}
+	return fmt.Errorf("lorawan: invalid MType %s", text)
+}
+
 // Supported message types (MType)
 const (
 	JoinRequest MType = iota
@@ -61,6 +69,17 @@ func (m Major) MarshalText() ([]byte, error) {
 	return []byte(m.String()), nil
 }
 
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Major) UnmarshalText(text []byte) error {
+	for i := Major(0); i < 4; i++ {
+		if i.String() == string(text) {
+			*m = i
+			return nil
+		}
+	}
+	return fmt.Errorf("lorawan: invalid Major %s", text)
+}
+
 // AES128Key represents a 128 bit AES key.
 type AES128Key [16]byte
 
@@ -87,24 +106,6 @@ func (k *AES128Key) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements sql.Scanner.
-func (k *AES128Key) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return errors.New("lorawan: []byte type expected")
-	}
-	if len(b) != len(k) {
-		return fmt.Errorf("lorawan []byte must have length %d", len(k))
-	}
-	copy(k[:], b)
-	return nil
-}
-
-// Value implements driver.Valuer.
-func (k AES128Key) Value() (driver.Value, error) {
-	return k[:], nil
-}
-
 // MarshalBinary encodes the key to a slice of bytes.
 func (k AES128Key) MarshalBinary() ([]byte, error) {
 	b := make([]byte, len(k))
@@ -142,6 +143,19 @@ func (m MIC) MarshalText() ([]byte, error) {
 	return []byte(m.String()), nil
 }
 
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *MIC) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	if len(b) != len(m) {
+		return fmt.Errorf("lorawan: exactly %d bytes are expected", len(m))
+	}
+	copy(m[:], b)
+	return nil
+}
+
 // MHDR represents the MAC header.
 type MHDR struct {
 	MType MType `json:"mType"`
@@ -196,6 +210,26 @@ func (p PHYPayload) ValidateUplinkDataMIC(macVersion MACVersion, confFCnt uint32
 	return p.MIC == mic, nil
 }
 
+// ValidateUplinkDataMICBytes validates the MIC of an uplink data frame, using
+// the given (received) PHYPayload bytes instead of marshaling the MACPayload.
+// This guarantees that the MIC is calculated over exactly the received bytes.
+// The PHYPayload must be the unmarshaled representation of the given bytes
+// and the FCnt value must be set to the full 32 bit frame-counter value.
+// The confirmed frame-counter, TX data-rate TX channel index and SNwkSIntKey
+// are only required for LoRaWAN 1.1 and can be left blank otherwise.
+func (p PHYPayload) ValidateUplinkDataMICBytes(phyBytes []byte, macVersion MACVersion, confFCnt uint32, txDR, txCh uint8, fNwkSIntKey, sNwkSIntKey AES128Key) (bool, error) {
+	micBytes, err := getMICBytes(phyBytes)
+	if err != nil {
+		return false, err
+	}
+
+	mic, err := p.calculateUplinkDataMICForBytes(micBytes, macVersion, confFCnt, txDR, txCh, fNwkSIntKey, sNwkSIntKey)
+	if err != nil {
+		return false, err
+	}
+	return p.MIC == mic, nil
+}
+
 // ValidateUplinkDataMICF validates the cmacF part of the uplink data MIC (LoRaWAN 1.1 only).
 // In order to validate the MIC, the FCnt value must first be set to the
 // full 32 bit frame-counter value, as only the 16 least-significant bits
@@ -237,6 +271,26 @@ func (p PHYPayload) ValidateDownlinkDataMIC(macVersion MACVersion, confFCnt uint
 	return p.MIC == mic, nil
 }
 
+// ValidateDownlinkDataMICBytes validates the MIC of a downlink data frame,
+// using the given (received) PHYPayload bytes instead of marshaling the
+// MACPayload. The PHYPayload must be the unmarshaled representation of the
+// given bytes and the FCnt value must be set to the full 32 bit frame-counter
+// value.
+// The confirmed frame-counter and is only required for LoRaWAN 1.1 and can be
+// left blank otherwise.
+func (p PHYPayload) ValidateDownlinkDataMICBytes(phyBytes []byte, macVersion MACVersion, confFCnt uint32, sNwkSIntKey AES128Key) (bool, error) {
+	micBytes, err := getMICBytes(phyBytes)
+	if err != nil {
+		return false, err
+	}
+
+	mic, err := p.calculateDownlinkDataMICForBytes(micBytes, macVersion, confFCnt, sNwkSIntKey)
+	if err != nil {
+		return false, err
+	}
+	return p.MIC == mic, nil
+}
+
 // SetUplinkJoinMIC calculates and sets the MIC field for uplink join requests.
 func (p *PHYPayload) SetUplinkJoinMIC(key AES128Key) error {
 	mic, err := p.calculateUplinkJoinMIC(key)
@@ -256,6 +310,22 @@ func (p PHYPayload) ValidateUplinkJoinMIC(key AES128Key) (bool, error) {
 	return p.MIC == mic, nil
 }
 
+// ValidateUplinkJoinMICBytes validates the MIC of an uplink join request,
+// using the given (received) PHYPayload bytes instead of marshaling the
+// MACPayload.
+func (p PHYPayload) ValidateUplinkJoinMICBytes(phyBytes []byte, key AES128Key) (bool, error) {
+	micBytes, err := getMICBytes(phyBytes)
+	if err != nil {
+		return false, err
+	}
+
+	mic, err := calculateUplinkJoinMICForBytes(micBytes, key)
+	if err != nil {
+		return false, err
+	}
+	return p.MIC == mic, nil
+}
+
 // SetDownlinkJoinMIC calculates and sets the MIC field for downlink join requests.
 func (p *PHYPayload) SetDownlinkJoinMIC(joinReqType JoinType, joinEUI EUI64, devNonce DevNonce, key AES128Key) error {
 	mic, err := p.calculateDownlinkJoinMIC(joinReqType, joinEUI, devNonce, key)
@@ -300,18 +370,10 @@ func (p *PHYPayload) EncryptJoinAcceptPayload(key AES128Key) error {
 		return errors.New("lorawan: plaintext must be a multiple of 16 bytes")
 	}
 
-	block, err := aes.NewCipher(key[:])
+	ct, err := crypto.DecryptECB(key, pt)
 	if err != nil {
 		return err
 	}
-	if block.BlockSize() != 16 {
-		return errors.New("lorawan: block-size of 16 bytes is expected")
-	}
-	ct := make([]byte, len(pt))
-	for i := 0; i < len(ct)/16; i++ {
-		offset := i * 16
-		block.Decrypt(ct[offset:offset+16], pt[offset:offset+16])
-	}
 	p.MACPayload = &DataPayload{Bytes: ct[0 : len(ct)-4]}
 	copy(p.MIC[:], ct[len(ct)-4:])
 	return nil
@@ -335,24 +397,53 @@ func (p *PHYPayload) DecryptJoinAcceptPayload(key AES128Key) error {
 		return errors.New("lorawan: plaintext must be a multiple of 16 bytes")
 	}
 
-	block, err := aes.NewCipher(key[:])
+	pt, err := crypto.EncryptECB(key, ct)
 	if err != nil {
 		return err
 	}
-	if block.BlockSize() != 16 {
-		return errors.New("lorawan: block-size of 16 bytes is expected")
-	}
-	pt := make([]byte, len(ct))
-	for i := 0; i < len(pt)/16; i++ {
-		offset := i * 16
-		block.Encrypt(pt[offset:offset+16], ct[offset:offset+16])
-	}
 
 	p.MACPayload = &JoinAcceptPayload{}
 	copy(p.MIC[:], pt[len(pt)-4:len(pt)]) // set the decrypted MIC
 	return p.MACPayload.UnmarshalBinary(p.isUplink(), pt[0:len(pt)-4])
 }
 
+// MarshalEncryptedJoinAccept returns the binary representation of the
+// PHYPayload with the join-accept payload encrypted using the given key.
+// Unlike EncryptJoinAcceptPayload, the PHYPayload itself is not modified and
+// the MACPayload remains of type *JoinAcceptPayload. Note that SetDownlinkJoinMIC
+// must be called first (since the MIC is part of the encrypted payload).
+//
+// Note: for encrypting a join-request response, use NwkKey
+//       for rejoin-request 0, 1, 2 response, use JSEncKey
+func (p PHYPayload) MarshalEncryptedJoinAccept(key AES128Key) ([]byte, error) {
+	if err := p.EncryptJoinAcceptPayload(key); err != nil {
+		return nil, err
+	}
+	return p.MarshalBinary()
+}
+
+// UnmarshalEncryptedJoinAccept decodes the given (encrypted) join-accept
+// PHYPayload and decrypts it using the given key. It is the counterpart of
+// MarshalEncryptedJoinAccept. On success, the MACPayload is of type
+// *JoinAcceptPayload and the MIC can be validated directly.
+//
+// Note: for decrypting a join-request response, use NwkKey
+//       for rejoin-request 0, 1, 2 response, use JSEncKey
+func (p *PHYPayload) UnmarshalEncryptedJoinAccept(key AES128Key, data []byte) error {
+	var phy PHYPayload
+	if err := phy.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if phy.MHDR.MType != JoinAccept {
+		return errors.New("lorawan: MType must be JoinAccept")
+	}
+	if err := phy.DecryptJoinAcceptPayload(key); err != nil {
+		return err
+	}
+	*p = phy
+	return nil
+}
+
 // EncryptFOpts encrypts the FOpts with the given key.
 func (p *PHYPayload) EncryptFOpts(nwkSEncKey AES128Key) error {
 	macPL, ok := p.MACPayload.(*MACPayload)
@@ -395,11 +486,18 @@ func (p *PHYPayload) EncryptFOpts(nwkSEncKey AES128Key) error {
 // DecryptFOpts decrypts the FOpts payload and decodes it into mac-command
 // structures.
 func (p *PHYPayload) DecryptFOpts(nwkSEncKey AES128Key) error {
+	return p.DecryptFOptsWithOptions(nwkSEncKey, DecodeOptions{})
+}
+
+// DecryptFOptsWithOptions decrypts the FOpts payload and decodes it into
+// mac-command structures, using the given decode options.
+func (p *PHYPayload) DecryptFOptsWithOptions(nwkSEncKey AES128Key, opts DecodeOptions) error {
 	if err := p.EncryptFOpts(nwkSEncKey); err != nil {
 		return nil
 	}
 
-	return p.DecodeFOptsToMACCommands()
+	_, err := p.DecodeFOptsToMACCommandsWithOptions(opts)
+	return err
 }
 
 // EncryptFRMPayload encrypts the FRMPayload with the given key.
@@ -432,6 +530,12 @@ func (p *PHYPayload) EncryptFRMPayload(key AES128Key) error {
 
 // DecryptFRMPayload decrypts the FRMPayload with the given key.
 func (p *PHYPayload) DecryptFRMPayload(key AES128Key) error {
+	return p.DecryptFRMPayloadWithOptions(key, DecodeOptions{})
+}
+
+// DecryptFRMPayloadWithOptions decrypts the FRMPayload with the given key.
+// When FPort=0, the mac-commands are decoded using the given decode options.
+func (p *PHYPayload) DecryptFRMPayloadWithOptions(key AES128Key, opts DecodeOptions) error {
 	if err := p.EncryptFRMPayload(key); err != nil {
 		return err
 	}
@@ -442,73 +546,171 @@ func (p *PHYPayload) DecryptFRMPayload(key AES128Key) error {
 	}
 
 	// the FRMPayload contains MAC commands, which we need to unmarshal
-	var err error
 	if macPL.FPort != nil && *macPL.FPort == 0 {
-		macPL.FRMPayload, err = decodeDataPayloadToMACCommands(p.isUplink(), macPL.FRMPayload)
+		_, err := p.DecodeFRMPayloadToMACCommandsWithOptions(opts)
+		return err
 	}
 
-	return err
+	return nil
 }
 
 // DecodeFRMPayloadToMACCommands decodes the (decrypted) FRMPayload bytes into
 // MAC commands. Note that after calling DecryptFRMPayload, this method is
-// called automatically when FPort=0.
+// called automatically when FPort=0. The mac-commands which could not be
+// decoded are skipped (MACCommandDecodeLenient).
 func (p *PHYPayload) DecodeFRMPayloadToMACCommands() error {
+	_, err := p.DecodeFRMPayloadToMACCommandsWithOptions(DecodeOptions{})
+	return err
+}
+
+// DecodeFRMPayloadToMACCommandsWithOptions decodes the (decrypted)
+// FRMPayload bytes into MAC commands, using the given decode options. In
+// MACCommandDecodeLenient mode, it returns the errors of the mac-commands
+// which could not be decoded.
+func (p *PHYPayload) DecodeFRMPayloadToMACCommandsWithOptions(opts DecodeOptions) ([]MACCommandError, error) {
 	macPL, ok := p.MACPayload.(*MACPayload)
 	if !ok {
-		return errors.New("lorawan: MACPayload must be of type *MACPayload")
+		return nil, errors.New("lorawan: MACPayload must be of type *MACPayload")
 	}
 
-	var err error
-	macPL.FRMPayload, err = decodeDataPayloadToMACCommands(p.isUplink(), macPL.FRMPayload)
-	return err
+	pls, macErrs, err := decodeDataPayloadToMACCommandsWithErrors(p.isUplink(), macPL.FRMPayload, opts)
+	if err != nil {
+		return nil, err
+	}
+	macPL.FRMPayload = pls
+	return macErrs, nil
 }
 
 // DecodeFOptsToMACCommands decodes the (decrypted) FOpts bytes into
-// MAC commands.
+// MAC commands. The mac-commands which could not be decoded are skipped
+// (MACCommandDecodeLenient).
 func (p *PHYPayload) DecodeFOptsToMACCommands() error {
+	_, err := p.DecodeFOptsToMACCommandsWithOptions(DecodeOptions{})
+	return err
+}
+
+// DecodeFOptsToMACCommandsWithOptions decodes the (decrypted) FOpts bytes
+// into MAC commands, using the given decode options. In
+// MACCommandDecodeLenient mode, it returns the errors of the mac-commands
+// which could not be decoded.
+func (p *PHYPayload) DecodeFOptsToMACCommandsWithOptions(opts DecodeOptions) ([]MACCommandError, error) {
 	macPL, ok := p.MACPayload.(*MACPayload)
 	if !ok {
-		return errors.New("lorawan: MACPayload must be of type *MACPayload")
+		return nil, errors.New("lorawan: MACPayload must be of type *MACPayload")
 	}
 
 	if len(macPL.FHDR.FOpts) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	var err error
-	macPL.FHDR.FOpts, err = decodeDataPayloadToMACCommands(p.isUplink(), macPL.FHDR.FOpts)
-	return err
+	pls, macErrs, err := decodeDataPayloadToMACCommandsWithErrors(p.isUplink(), macPL.FHDR.FOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+	macPL.FHDR.FOpts = pls
+	return macErrs, nil
 }
 
 // MarshalBinary marshals the object in binary form.
 func (p PHYPayload) MarshalBinary() ([]byte, error) {
+	b, err := p.AppendBinary(make([]byte, 0, MaxPHYPayloadSize))
+	if err != nil {
+		return []byte{}, err
+	}
+	return b, nil
+}
+
+// AppendBinary appends the object in binary form to b and returns the
+// extended buffer. In case of an error, b is returned unmodified. Unlike
+// MarshalBinary, this does not allocate when b has sufficient capacity (and
+// the FOpts and FRMPayload contain DataPayload items), which makes it
+// possible to re-use the same buffer for marshaling many frames.
+func (p PHYPayload) AppendBinary(b []byte) ([]byte, error) {
 	if p.MACPayload == nil {
-		return []byte{}, errors.New("lorawan: MACPayload should not be nil")
+		return b, errors.New("lorawan: MACPayload should not be nil")
 	}
 
-	var out []byte
-	var b []byte
-	var err error
+	start := len(b)
+	b = append(b, (byte(p.MHDR.MType)<<5)|(byte(p.MHDR.Major)&0x03))
 
-	if b, err = p.MHDR.MarshalBinary(); err != nil {
-		return []byte{}, err
+	var err error
+	switch pl := p.MACPayload.(type) {
+	case *MACPayload:
+		b, err = pl.appendBinary(b)
+	default:
+		b, err = appendPayload(b, pl)
 	}
-	out = append(out, b...)
-
-	if b, err = p.MACPayload.MarshalBinary(); err != nil {
-		return []byte{}, err
+	if err != nil {
+		return b[:start], err
 	}
-	out = append(out, b...)
-	out = append(out, p.MIC[0:len(p.MIC)]...)
-	return out, nil
+
+	return append(b, p.MIC[:]...), nil
+}
+
+// MaxPHYPayloadSize defines the absolute max. size (in bytes) of a
+// PHYPayload. No region allows a larger LoRa PHYPayload.
+const MaxPHYPayloadSize = 255
+
+// DecodeOptions holds the options used by PHYPayload.UnmarshalBinaryWithOptions
+// and by the decoding of mac-commands (e.g.
+// PHYPayload.DecodeFOptsToMACCommandsWithOptions and
+// MACCommand.UnmarshalBinaryWithOptions). As the options are passed per
+// call, different options can be used concurrently.
+type DecodeOptions struct {
+	// MaxSize defines the max. PHYPayload size in bytes. When set, larger
+	// frames are rejected before decoding. This can be set to the regional
+	// max. PHYPayload size for the data-rate of the uplink (see
+	// band.GetMaxPHYPayloadSize). Frames larger than MaxPHYPayloadSize are
+	// always rejected.
+	MaxSize int
+
+	// ReuseBuffers avoids allocations when decoding many frames into the
+	// same PHYPayload. When set, the decoded payload bytes reference the
+	// given data (instead of a copy) and the current MACPayload (including
+	// its FPort and DataPayload items) is re-used when it matches the type
+	// of the decoded frame. The data must therefore not be modified while
+	// the PHYPayload is in use, and references to the previous MACPayload
+	// must not be retained.
+	ReuseBuffers bool
+
+	// StrictDutyCycleReq enables the spec-strict validation of received
+	// DutyCycleReq mac-commands. When set, the RFU MaxDCycle values
+	// (16 - 254) are rejected. By default these values are accepted when
+	// decoding.
+	StrictDutyCycleReq bool
+
+	// Conformance enables the validation of all the value-range constraints
+	// mandated by the LoRaWAN specification when decoding mac-commands,
+	// including that all RFU bits are zero. See
+	// MACCommand.ValidateConformance for the constraints which are checked.
+	Conformance bool
+
+	// MACCommandDecodeMode defines how the mac-commands which could not be
+	// decoded are handled. The default is MACCommandDecodeLenient.
+	MACCommandDecodeMode MACCommandDecodeMode
+
+	// UnknownCIDPolicy defines how the mac-commands with an unknown (e.g.
+	// RFU or deprecated) CID are decoded. The default is UnknownCIDSkip.
+	UnknownCIDPolicy UnknownCIDPolicy
 }
 
 // UnmarshalBinary decodes the object from binary form.
 func (p *PHYPayload) UnmarshalBinary(data []byte) error {
+	return p.UnmarshalBinaryWithOptions(data, DecodeOptions{})
+}
+
+// UnmarshalBinaryWithOptions decodes the object from binary form, using the
+// given decode options.
+func (p *PHYPayload) UnmarshalBinaryWithOptions(data []byte, opts DecodeOptions) error {
 	if len(data) < 5 {
 		return errors.New("lorawan: at least 5 bytes needed to decode PHYPayload")
 	}
+	if len(data) > MaxPHYPayloadSize {
+		return fmt.Errorf("lorawan: max size of PHYPayload is %d bytes", MaxPHYPayloadSize)
+	}
+	if opts.MaxSize != 0 && len(data) > opts.MaxSize {
+		return fmt.Errorf("lorawan: PHYPayload size of %d bytes exceeds the max. size of %d bytes", len(data), opts.MaxSize)
+	}
 
 	// MHDR
 	if err := p.MHDR.UnmarshalBinary(data[0:1]); err != nil {
@@ -516,6 +718,16 @@ func (p *PHYPayload) UnmarshalBinary(data []byte) error {
 	}
 
 	// MACPayload
+	if opts.ReuseBuffers {
+		if ok, err := p.unmarshalReusedMACPayload(data[1 : len(data)-4]); ok {
+			if err != nil {
+				return err
+			}
+			copy(p.MIC[:], data[len(data)-4:])
+			return nil
+		}
+	}
+
 	switch p.MHDR.MType {
 	case JoinRequest:
 		p.MACPayload = &JoinRequestPayload{}
@@ -548,6 +760,27 @@ func (p *PHYPayload) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// unmarshalReusedMACPayload decodes the given MACPayload bytes into the
+// current MACPayload. It returns false when the current MACPayload can't be
+// re-used for the MType of the frame.
+func (p *PHYPayload) unmarshalReusedMACPayload(data []byte) (bool, error) {
+	switch pl := p.MACPayload.(type) {
+	case *DataPayload:
+		if p.MHDR.MType != JoinAccept && p.MHDR.MType != Proprietary {
+			return false, nil
+		}
+		pl.Bytes = data
+		return true, nil
+	case *MACPayload:
+		switch p.MHDR.MType {
+		case UnconfirmedDataUp, UnconfirmedDataDown, ConfirmedDataUp, ConfirmedDataDown:
+			return true, pl.unmarshalBinary(p.isUplink(), data, true)
+		}
+	}
+
+	return false, nil
+}
+
 // MarshalText encodes the PHYPayload into base64.
 func (p PHYPayload) MarshalText() ([]byte, error) {
 	b, err := p.MarshalBinary()
@@ -566,12 +799,6 @@ func (p *PHYPayload) UnmarshalText(text []byte) error {
 	return p.UnmarshalBinary(b)
 }
 
-// MarshalJSON encodes the PHYPayload into JSON.
-func (p PHYPayload) MarshalJSON() ([]byte, error) {
-	type phyAlias PHYPayload
-	return json.Marshal(phyAlias(p))
-}
-
 // isUplink returns a bool indicating if the packet is uplink or downlink.
 // Note that for MType Proprietary it can't derrive if the packet is uplink
 // or downlink. This is fine (I think) since it is also unknown how to
@@ -607,20 +834,11 @@ func (p PHYPayload) calculateUplinkJoinMIC(key AES128Key) (MIC, error) {
 	}
 	micBytes = append(micBytes, b...)
 
-	hash, err := cmac.New(key[:])
-	if err != nil {
-		return mic, err
-	}
-	if _, err = hash.Write(micBytes); err != nil {
-		return mic, err
-	}
-	hb := hash.Sum([]byte{})
-	if len(hb) < 4 {
-		return mic, errors.New("lorawan: the hash returned less than 4 bytes")
-	}
+	return calculateUplinkJoinMICForBytes(micBytes, key)
+}
 
-	copy(mic[:], hb[0:4])
-	return mic, nil
+func calculateUplinkJoinMICForBytes(micBytes []byte, key AES128Key) (MIC, error) {
+	return crypto.MIC(key, micBytes)
 }
 
 func (p PHYPayload) calculateDownlinkJoinMIC(joinReqType JoinType, joinEUI EUI64, devNonce DevNonce, key AES128Key) (MIC, error) {
@@ -668,20 +886,7 @@ func (p PHYPayload) calculateDownlinkJoinMIC(joinReqType JoinType, joinEUI EUI64
 	}
 	micBytes = append(micBytes, b...)
 
-	hash, err := cmac.New(key[:])
-	if err != nil {
-		return mic, err
-	}
-	if _, err = hash.Write(micBytes); err != nil {
-		return mic, err
-	}
-	hb := hash.Sum([]byte{})
-	if len(hb) < len(mic) {
-		return mic, fmt.Errorf("lorawan: the hash returned less than %d bytes", len(mic))
-	}
-
-	copy(mic[:], hb[0:len(mic)])
-	return mic, nil
+	return crypto.MIC(key, micBytes)
 }
 
 func (p *PHYPayload) calculateUplinkDataMIC(macVersion MACVersion, confFCnt uint32, txDR, txCh uint8, fNwkSIntKey, sNwkSIntKey AES128Key) (MIC, error) {
@@ -696,13 +901,6 @@ func (p *PHYPayload) calculateUplinkDataMIC(macVersion MACVersion, confFCnt uint
 		return mic, errors.New("lorawan: MACPayload field must be of type *MACPayload")
 	}
 
-	// set to 0 when the uplink does not contain an ACK
-	if !macPL.FHDR.FCtrl.ACK {
-		confFCnt = 0
-	}
-
-	confFCnt = confFCnt % (1 << 16)
-
 	var micBytes []byte
 	b, err := p.MHDR.MarshalBinary()
 	if err != nil {
@@ -716,6 +914,30 @@ func (p *PHYPayload) calculateUplinkDataMIC(macVersion MACVersion, confFCnt uint
 	}
 	micBytes = append(micBytes, b...)
 
+	return p.calculateUplinkDataMICForBytes(micBytes, macVersion, confFCnt, txDR, txCh, fNwkSIntKey, sNwkSIntKey)
+}
+
+// calculateUplinkDataMICForBytes calculates the uplink data MIC over the
+// given MHDR | FHDR | FPort | FRMPayload bytes.
+func (p *PHYPayload) calculateUplinkDataMICForBytes(micBytes []byte, macVersion MACVersion, confFCnt uint32, txDR, txCh uint8, fNwkSIntKey, sNwkSIntKey AES128Key) (MIC, error) {
+	var mic MIC
+
+	if p.MACPayload == nil {
+		return mic, errors.New("lorawan: MACPayload must not be nil")
+	}
+
+	macPL, ok := p.MACPayload.(*MACPayload)
+	if !ok {
+		return mic, errors.New("lorawan: MACPayload field must be of type *MACPayload")
+	}
+
+	// set to 0 when the uplink does not contain an ACK
+	if !macPL.FHDR.FCtrl.ACK {
+		confFCnt = 0
+	}
+
+	confFCnt = confFCnt % (1 << 16)
+
 	b0 := make([]byte, 16)
 	b1 := make([]byte, 16)
 
@@ -723,7 +945,7 @@ func (p *PHYPayload) calculateUplinkDataMIC(macVersion MACVersion, confFCnt uint
 	b1[0] = 0x49
 
 	// devaddr
-	b, err = macPL.FHDR.DevAddr.MarshalBinary()
+	b, err := macPL.FHDR.DevAddr.MarshalBinary()
 	if err != nil {
 		return mic, err
 	}
@@ -743,37 +965,15 @@ func (p *PHYPayload) calculateUplinkDataMIC(macVersion MACVersion, confFCnt uint
 	b1[3] = txDR
 	b1[4] = txCh
 
-	hash, err := cmac.New(sNwkSIntKey[:])
+	cmacS, err := crypto.CMAC(sNwkSIntKey, b1, micBytes)
 	if err != nil {
 		return mic, err
 	}
-	if _, err = hash.Write(b1); err != nil {
-		return mic, err
-	}
-	if _, err = hash.Write(micBytes); err != nil {
-		return mic, err
-	}
-
-	cmacS := hash.Sum([]byte{})
-	if len(cmacS) < 4 {
-		return mic, errors.New("lorawan: the hash returned less than 4 bytes")
-	}
 
-	hash, err = cmac.New(fNwkSIntKey[:])
+	cmacF, err := crypto.CMAC(fNwkSIntKey, b0, micBytes)
 	if err != nil {
 		return mic, err
 	}
-	if _, err = hash.Write(b0); err != nil {
-		return mic, err
-	}
-	if _, err = hash.Write(micBytes); err != nil {
-		return mic, err
-	}
-
-	cmacF := hash.Sum([]byte{})
-	if len(cmacF) < 2 {
-		return mic, errors.New("lorawan: the hash returned less than 2 bytes")
-	}
 
 	if macVersion == LoRaWAN1_0 {
 		copy(mic[:], cmacF[0:4])
@@ -797,13 +997,6 @@ func (p *PHYPayload) calculateDownlinkDataMIC(macVersion MACVersion, confFCnt ui
 		return mic, errors.New("lorawan: MACPayload field must be of type *MACPayload")
 	}
 
-	// The confirmed FCnt is only used in case of LoRaWAN 1.1 when the ACK
-	// flag is set.
-	if macVersion == LoRaWAN1_0 || !macPL.FHDR.FCtrl.ACK {
-		confFCnt = 0
-	}
-	confFCnt = confFCnt % (1 << 16)
-
 	var micBytes []byte
 	b, err := p.MHDR.MarshalBinary()
 	if err != nil {
@@ -817,12 +1010,36 @@ func (p *PHYPayload) calculateDownlinkDataMIC(macVersion MACVersion, confFCnt ui
 	}
 	micBytes = append(micBytes, b...)
 
+	return p.calculateDownlinkDataMICForBytes(micBytes, macVersion, confFCnt, sNwkSIntKey)
+}
+
+// calculateDownlinkDataMICForBytes calculates the downlink data MIC over the
+// given MHDR | FHDR | FPort | FRMPayload bytes.
+func (p *PHYPayload) calculateDownlinkDataMICForBytes(micBytes []byte, macVersion MACVersion, confFCnt uint32, sNwkSIntKey AES128Key) (MIC, error) {
+	var mic MIC
+
+	if p.MACPayload == nil {
+		return mic, errors.New("lorawan: MACPayload must not be nil")
+	}
+
+	macPL, ok := p.MACPayload.(*MACPayload)
+	if !ok {
+		return mic, errors.New("lorawan: MACPayload field must be of type *MACPayload")
+	}
+
+	// The confirmed FCnt is only used in case of LoRaWAN 1.1 when the ACK
+	// flag is set.
+	if macVersion == LoRaWAN1_0 || !macPL.FHDR.FCtrl.ACK {
+		confFCnt = 0
+	}
+	confFCnt = confFCnt % (1 << 16)
+
 	b0 := make([]byte, 16)
 	b0[0] = 0x49
 	binary.LittleEndian.PutUint16(b0[1:3], uint16(confFCnt))
 	b0[5] = 0x01
 
-	b, err = macPL.FHDR.DevAddr.MarshalBinary()
+	b, err := macPL.FHDR.DevAddr.MarshalBinary()
 	if err != nil {
 		return mic, err
 	}
@@ -830,46 +1047,22 @@ func (p *PHYPayload) calculateDownlinkDataMIC(macVersion MACVersion, confFCnt ui
 	binary.LittleEndian.PutUint32(b0[10:14], macPL.FHDR.FCnt)
 	b0[15] = byte(len(micBytes))
 
-	hash, err := cmac.New(sNwkSIntKey[:])
-	if err != nil {
-		return mic, err
-	}
-
-	if _, err = hash.Write(b0); err != nil {
-		return mic, err
-	}
-	if _, err = hash.Write(micBytes); err != nil {
-		return mic, err
-	}
+	return crypto.MIC(sNwkSIntKey, b0, micBytes)
+}
 
-	hb := hash.Sum([]byte{})
-	if len(hb) < 4 {
-		return mic, errors.New("lorawan: the hash returned less than 4 bytes")
+// getMICBytes returns the bytes over which the MIC is calculated given the
+// PHYPayload bytes (MHDR | MACPayload | MIC).
+func getMICBytes(phyBytes []byte) ([]byte, error) {
+	if len(phyBytes) < 5 {
+		return nil, errors.New("lorawan: at least 5 bytes needed to decode PHYPayload")
 	}
-
-	copy(mic[:], hb[0:4])
-	return mic, nil
+	return phyBytes[:len(phyBytes)-4], nil
 }
 
 // EncryptFRMPayload encrypts the FRMPayload (slice of bytes).
 // Note that EncryptFRMPayload is used for both encryption and decryption.
 func EncryptFRMPayload(key AES128Key, uplink bool, devAddr DevAddr, fCnt uint32, data []byte) ([]byte, error) {
-	pLen := len(data)
-	if pLen%16 != 0 {
-		// append with empty bytes so that len(data) is a multiple of 16
-		data = append(data, make([]byte, 16-(pLen%16))...)
-	}
-
-	block, err := aes.NewCipher(key[:])
-	if err != nil {
-		return nil, err
-	}
-	if block.BlockSize() != 16 {
-		return nil, errors.New("lorawan: block size of 16 was expected")
-	}
-
-	s := make([]byte, 16)
-	a := make([]byte, 16)
+	var a [16]byte
 	a[0] = 0x01
 	if !uplink {
 		a[5] = 0x01
@@ -882,16 +1075,7 @@ func EncryptFRMPayload(key AES128Key, uplink bool, devAddr DevAddr, fCnt uint32,
 	copy(a[6:10], b)
 	binary.LittleEndian.PutUint32(a[10:14], uint32(fCnt))
 
-	for i := 0; i < len(data)/16; i++ {
-		a[15] = byte(i + 1)
-		block.Encrypt(s, a)
-
-		for j := 0; j < len(s); j++ {
-			data[i*16+j] = data[i*16+j] ^ s[j]
-		}
-	}
-
-	return data[0:pLen], nil
+	return crypto.EncryptABlocks(key, a, data)
 }
 
 // EncryptFOpts encrypts the FOpts mac-commands.
@@ -906,15 +1090,7 @@ func EncryptFOpts(nwkSEncKey AES128Key, aFCntDown, uplink bool, devAddr DevAddr,
 		return nil, errors.New("lorawan: max size of FOpts is 15 bytes")
 	}
 
-	block, err := aes.NewCipher(nwkSEncKey[:])
-	if err != nil {
-		return nil, err
-	}
-	if block.BlockSize() != 16 {
-		return nil, errors.New("lorawan: block size of 16 was expected")
-	}
-
-	a := make([]byte, 16)
+	var a [16]byte
 	a[0] = 0x01
 	if aFCntDown {
 		a[4] = 0x02
@@ -931,17 +1107,7 @@ func EncryptFOpts(nwkSEncKey AES128Key, aFCntDown, uplink bool, devAddr DevAddr,
 		return nil, err
 	}
 	copy(a[6:10], b)
-
-	a[15] = 0x01
-
 	binary.LittleEndian.PutUint32(a[10:14], fCnt)
 
-	s := make([]byte, 16)
-	block.Encrypt(s, a)
-
-	for i := range data {
-		data[i] ^= s[i]
-	}
-
-	return data, nil
+	return crypto.EncryptABlocks(nwkSEncKey, a, data)
 }
@@ -0,0 +1,214 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func getTestDataPHYPayload() PHYPayload {
+	fPort := uint8(10)
+	return PHYPayload{
+		MHDR: MHDR{
+			MType: UnconfirmedDataUp,
+			Major: LoRaWANR1,
+		},
+		MACPayload: &MACPayload{
+			FHDR: FHDR{
+				DevAddr: DevAddr{1, 2, 3, 4},
+				FCtrl: FCtrl{
+					ADR: true,
+				},
+				FCnt: 258,
+				FOpts: []Payload{
+					&DataPayload{Bytes: []byte{0x02}},
+				},
+			},
+			FPort: &fPort,
+			FRMPayload: []Payload{
+				&DataPayload{Bytes: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+			},
+		},
+		MIC: MIC{1, 2, 3, 4},
+	}
+}
+
+func TestPHYPayloadAppendBinary(t *testing.T) {
+	t.Run("Data", func(t *testing.T) {
+		assert := require.New(t)
+
+		phy := getTestDataPHYPayload()
+		expected, err := phy.MarshalBinary()
+		assert.NoError(err)
+		assert.Equal([]byte{0x40, 0x04, 0x03, 0x02, 0x01, 0x81, 0x02, 0x01, 0x02, 0x0a, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x01, 0x02, 0x03, 0x04}, expected)
+
+		b, err := phy.AppendBinary([]byte{0xff})
+		assert.NoError(err)
+		assert.Equal(append([]byte{0xff}, expected...), b)
+	})
+
+	t.Run("MAC commands", func(t *testing.T) {
+		assert := require.New(t)
+
+		phy := getTestDataPHYPayload()
+		phy.MACPayload.(*MACPayload).FHDR.FOpts = []Payload{
+			&MACCommand{CID: LinkCheckReq},
+			&MACCommand{CID: DevStatusAns, Payload: &DevStatusAnsPayload{Battery: 10, Margin: 5}},
+		}
+
+		b, err := phy.AppendBinary(nil)
+		assert.NoError(err)
+		assert.Equal([]byte{0x40, 0x04, 0x03, 0x02, 0x01, 0x84, 0x02, 0x01, 0x02, 0x06, 0x0a, 0x05, 0x0a, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x01, 0x02, 0x03, 0x04}, b)
+	})
+
+	t.Run("Join-request", func(t *testing.T) {
+		assert := require.New(t)
+
+		phy := PHYPayload{
+			MHDR: MHDR{
+				MType: JoinRequest,
+				Major: LoRaWANR1,
+			},
+			MACPayload: &JoinRequestPayload{
+				JoinEUI:  EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+				DevEUI:   EUI64{8, 7, 6, 5, 4, 3, 2, 1},
+				DevNonce: 258,
+			},
+		}
+
+		expected, err := phy.MarshalBinary()
+		assert.NoError(err)
+
+		b, err := phy.AppendBinary(nil)
+		assert.NoError(err)
+		assert.Equal(expected, b)
+	})
+
+	t.Run("Error leaves buffer unmodified", func(t *testing.T) {
+		assert := require.New(t)
+
+		phy := getTestDataPHYPayload()
+		phy.MACPayload.(*MACPayload).FHDR.FOpts = []Payload{
+			&DataPayload{Bytes: make([]byte, 16)},
+		}
+
+		b, err := phy.AppendBinary([]byte{0xff})
+		assert.EqualError(err, "lorawan: max number of FOpts bytes is 15")
+		assert.Equal([]byte{0xff}, b)
+
+		_, err = PHYPayload{}.AppendBinary(nil)
+		assert.EqualError(err, "lorawan: MACPayload should not be nil")
+	})
+}
+
+func TestPHYPayloadUnmarshalBinaryReuseBuffers(t *testing.T) {
+	assert := require.New(t)
+	opts := DecodeOptions{ReuseBuffers: true}
+
+	phy := getTestDataPHYPayload()
+	withFOpts, err := phy.MarshalBinary()
+	assert.NoError(err)
+
+	phy.MACPayload.(*MACPayload).FHDR.FOpts = nil
+	phy.MACPayload.(*MACPayload).FPort = nil
+	phy.MACPayload.(*MACPayload).FRMPayload = nil
+	withoutFRMPayload, err := phy.MarshalBinary()
+	assert.NoError(err)
+
+	var expectedWithFOpts, expectedWithoutFRMPayload PHYPayload
+	assert.NoError(expectedWithFOpts.UnmarshalBinary(withFOpts))
+	assert.NoError(expectedWithoutFRMPayload.UnmarshalBinary(withoutFRMPayload))
+
+	var decoded PHYPayload
+	assert.NoError(decoded.UnmarshalBinaryWithOptions(withFOpts, opts))
+	assert.Equal(expectedWithFOpts, decoded)
+	macPL := decoded.MACPayload.(*MACPayload)
+
+	// the MACPayload is re-used and fields which are not present are reset
+	assert.NoError(decoded.UnmarshalBinaryWithOptions(withoutFRMPayload, opts))
+	assert.Equal(expectedWithoutFRMPayload, decoded)
+	assert.True(macPL == decoded.MACPayload)
+
+	assert.NoError(decoded.UnmarshalBinaryWithOptions(withFOpts, opts))
+	assert.Equal(expectedWithFOpts, decoded)
+	assert.True(macPL == decoded.MACPayload)
+
+	// the payload bytes reference the given data
+	frmPL := decoded.MACPayload.(*MACPayload).FRMPayload[0].(*DataPayload)
+	assert.True(&withFOpts[10] == &frmPL.Bytes[0])
+
+	// a different MType results in a new MACPayload
+	jrPHY := PHYPayload{
+		MHDR: MHDR{
+			MType: JoinRequest,
+			Major: LoRaWANR1,
+		},
+		MACPayload: &JoinRequestPayload{
+			JoinEUI:  EUI64{1, 2, 3, 4, 5, 6, 7, 8},
+			DevEUI:   EUI64{8, 7, 6, 5, 4, 3, 2, 1},
+			DevNonce: 258,
+		},
+	}
+	jrBytes, err := jrPHY.MarshalBinary()
+	assert.NoError(err)
+
+	assert.NoError(decoded.UnmarshalBinaryWithOptions(jrBytes, opts))
+	assert.Equal(jrPHY, decoded)
+}
+
+func BenchmarkPHYPayloadMarshalBinary(b *testing.B) {
+	phy := getTestDataPHYPayload()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := phy.MarshalBinary(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPHYPayloadAppendBinary(b *testing.B) {
+	phy := getTestDataPHYPayload()
+	buf := make([]byte, 0, MaxPHYPayloadSize)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = phy.AppendBinary(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPHYPayloadUnmarshalBinary(b *testing.B) {
+	phy := getTestDataPHYPayload()
+	data, err := phy.MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var out PHYPayload
+		if err := out.UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPHYPayloadUnmarshalBinaryReuseBuffers(b *testing.B) {
+	phy := getTestDataPHYPayload()
+	data, err := phy.MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	opts := DecodeOptions{ReuseBuffers: true}
+	var out PHYPayload
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := out.UnmarshalBinaryWithOptions(data, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
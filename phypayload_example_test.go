@@ -0,0 +1,441 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import (
+	"fmt"
+)
+
+func ExamplePHYPayload_lorawan10Encode() {
+	nwkSKey := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	appSKey := [16]byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	fPort := uint8(10)
+
+	phy := PHYPayload{
+		MHDR: MHDR{
+			MType: ConfirmedDataUp,
+			Major: LoRaWANR1,
+		},
+		MACPayload: &MACPayload{
+			FHDR: FHDR{
+				DevAddr: DevAddr([4]byte{1, 2, 3, 4}),
+				FCtrl: FCtrl{
+					ADR:       false,
+					ADRACKReq: false,
+					ACK:       false,
+				},
+				FCnt: 0,
+				FOpts: []Payload{
+					&MACCommand{
+						CID: DevStatusAns,
+						Payload: &DevStatusAnsPayload{
+							Battery: 115,
+							Margin:  7,
+						},
+					},
+				},
+			},
+			FPort:      &fPort,
+			FRMPayload: []Payload{&DataPayload{Bytes: []byte{1, 2, 3, 4}}},
+		},
+	}
+
+	if err := phy.EncryptFRMPayload(appSKey); err != nil {
+		panic(err)
+	}
+
+	if err := phy.SetUplinkDataMIC(LoRaWAN1_0, 0, 0, 0, nwkSKey, AES128Key{}); err != nil {
+		panic(err)
+	}
+
+	str, err := phy.MarshalText()
+	if err != nil {
+		panic(err)
+	}
+
+	bytes, err := phy.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	phyJSON, err := phy.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(str))
+	fmt.Println(bytes)
+	fmt.Println(string(phyJSON))
+
+	// Output:
+	// gAQDAgEDAAAGcwcK4mTU9+EX0sA=
+	// [128 4 3 2 1 3 0 0 6 115 7 10 226 100 212 247 225 23 210 192]
+	// {"mhdr":{"mType":"ConfirmedDataUp","major":"LoRaWANR1"},"macPayload":{"fhdr":{"devAddr":"01020304","fCtrl":{"adr":false,"adrAckReq":false,"ack":false,"fPending":false,"classB":false},"fCnt":0,"fOpts":[{"cid":"DevStatusAns","payload":{"battery":115,"margin":7}}]},"fPort":10,"frmPayload":[{"bytes":"4mTU9w=="}]},"mic":"e117d2c0"}
+}
+
+func ExamplePHYPayload_lorawan10Decode() {
+	nwkSKey := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	appSKey := [16]byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+
+	var phy PHYPayload
+	// use use UnmarshalBinary when decoding a byte-slice
+	if err := phy.UnmarshalText([]byte("gAQDAgEDAAAGcwcK4mTU9+EX0sA=")); err != nil {
+		panic(err)
+	}
+
+	ok, err := phy.ValidateUplinkDataMIC(LoRaWAN1_0, 0, 0, 0, nwkSKey, AES128Key{})
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		panic("invalid mic")
+	}
+
+	if err := phy.DecodeFOptsToMACCommands(); err != nil {
+		panic(err)
+	}
+
+	phyJSON, err := phy.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	if err := phy.DecryptFRMPayload(appSKey); err != nil {
+		panic(err)
+	}
+	macPL, ok := phy.MACPayload.(*MACPayload)
+	if !ok {
+		panic("*MACPayload expected")
+	}
+
+	pl, ok := macPL.FRMPayload[0].(*DataPayload)
+	if !ok {
+		panic("*DataPayload expected")
+	}
+
+	fmt.Println(string(phyJSON))
+	fmt.Println(pl.Bytes)
+
+	// Output:
+	// {"mhdr":{"mType":"ConfirmedDataUp","major":"LoRaWANR1"},"macPayload":{"fhdr":{"devAddr":"01020304","fCtrl":{"adr":false,"adrAckReq":false,"ack":false,"fPending":false,"classB":false},"fCnt":0,"fOpts":[{"cid":"DevStatusAns","payload":{"battery":115,"margin":7}}]},"fPort":10,"frmPayload":[{"bytes":"4mTU9w=="}]},"mic":"e117d2c0"}
+	// [1 2 3 4]
+}
+
+func ExamplePHYPayload_lorawan11EncryptedFoptsEncode() {
+	sNwkSIntKey := [16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+	nwkSEncKey := [16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2}
+	appSKey := [16]byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	var fport1 uint8 = 1
+
+	phy := PHYPayload{
+		MHDR: MHDR{
+			MType: UnconfirmedDataDown,
+			Major: LoRaWANR1,
+		},
+		MACPayload: &MACPayload{
+			FHDR: FHDR{
+				DevAddr: DevAddr{1, 2, 3, 4},
+				FOpts: []Payload{
+					&MACCommand{
+						CID: LinkCheckAns,
+						Payload: &LinkCheckAnsPayload{
+							Margin: 7,
+							GwCnt:  1,
+						},
+					},
+				},
+			},
+			FPort: &fport1,
+			FRMPayload: []Payload{
+				&DataPayload{Bytes: []byte{1, 2, 3, 4}},
+			},
+		},
+	}
+
+	if err := phy.EncryptFOpts(nwkSEncKey); err != nil {
+		panic(err)
+	}
+
+	if err := phy.EncryptFRMPayload(appSKey); err != nil {
+		panic(err)
+	}
+
+	if err := phy.SetDownlinkDataMIC(LoRaWAN1_1, 0, sNwkSIntKey); err != nil {
+		panic(err)
+	}
+
+	str, err := phy.MarshalText()
+	if err != nil {
+		panic(err)
+	}
+
+	bytes, err := phy.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	phyJSON, err := phy.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(str))
+	fmt.Println(bytes)
+	fmt.Println(string(phyJSON))
+
+	// Output:
+	// YAQDAgEDAAAirAoB8LRo3ape0To=
+	// [96 4 3 2 1 3 0 0 34 172 10 1 240 180 104 221 170 94 209 58]
+	// {"mhdr":{"mType":"UnconfirmedDataDown","major":"LoRaWANR1"},"macPayload":{"fhdr":{"devAddr":"01020304","fCtrl":{"adr":false,"adrAckReq":false,"ack":false,"fPending":false,"classB":false},"fCnt":0,"fOpts":[{"bytes":"IqwK"}]},"fPort":1,"frmPayload":[{"bytes":"8LRo3Q=="}]},"mic":"aa5ed13a"}
+}
+
+func ExamplePHYPayload_lorawan11EncryptedFoptsDecode() {
+	sNwkSIntKey := [16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+	nwkSEncKey := [16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2}
+	appSKey := [16]byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+
+	var phy PHYPayload
+	if err := phy.UnmarshalText([]byte("YAQDAgEDAAAirAoB8LRo3ape0To=")); err != nil {
+		panic(err)
+	}
+
+	ok, err := phy.ValidateDownlinkDataMIC(LoRaWAN1_1, 0, sNwkSIntKey)
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		panic("invalid mic")
+	}
+
+	if err := phy.DecryptFOpts(nwkSEncKey); err != nil {
+		panic(err)
+	}
+
+	if err := phy.DecryptFRMPayload(appSKey); err != nil {
+		panic(err)
+	}
+
+	phyJSON, err := phy.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(phyJSON))
+
+	// Output:
+	// {"mhdr":{"mType":"UnconfirmedDataDown","major":"LoRaWANR1"},"macPayload":{"fhdr":{"devAddr":"01020304","fCtrl":{"adr":false,"adrAckReq":false,"ack":false,"fPending":false,"classB":false},"fCnt":0,"fOpts":[{"cid":"LinkCheckAns","payload":{"margin":7,"gwCnt":1}}]},"fPort":1,"frmPayload":[{"bytes":"AQIDBA=="}]},"mic":"aa5ed13a"}
+}
+
+func ExamplePHYPayload_proprietaryEncode() {
+	phy := PHYPayload{
+		MHDR: MHDR{
+			MType: Proprietary,
+			Major: LoRaWANR1,
+		},
+		MACPayload: &DataPayload{Bytes: []byte{5, 6, 7, 8, 9, 10}},
+		MIC:        MIC{1, 2, 3, 4},
+	}
+
+	str, err := phy.MarshalText()
+	if err != nil {
+		panic(err)
+	}
+
+	bytes, err := phy.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	phyJSON, err := phy.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(str))
+	fmt.Println(bytes)
+	fmt.Println(string(phyJSON))
+
+	// Output:
+	// 4AUGBwgJCgECAwQ=
+	// [224 5 6 7 8 9 10 1 2 3 4]
+	// {"mhdr":{"mType":"Proprietary","major":"LoRaWANR1"},"macPayload":{"bytes":"BQYHCAkK"},"mic":"01020304"}
+}
+
+func ExamplePHYPayload_proprietaryDecode() {
+	var phy PHYPayload
+
+	if err := phy.UnmarshalText([]byte("4AUGBwgJCgECAwQ=")); err != nil {
+		panic(err)
+	}
+
+	phyJSON, err := phy.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+
+	pl, ok := phy.MACPayload.(*DataPayload)
+	if !ok {
+		panic("*DataPayload expected")
+	}
+
+	fmt.Println(phy.MIC)
+	fmt.Println(pl.Bytes)
+	fmt.Println(string(phyJSON))
+
+	// Output:
+	// 01020304
+	// [5 6 7 8 9 10]
+	// {"mhdr":{"mType":"Proprietary","major":"LoRaWANR1"},"macPayload":{"bytes":"BQYHCAkK"},"mic":"01020304"}
+}
+
+func ExamplePHYPayload_joinRequest() {
+	appKey := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+	phy := PHYPayload{
+		MHDR: MHDR{
+			MType: JoinRequest,
+			Major: LoRaWANR1,
+		},
+		MACPayload: &JoinRequestPayload{
+			JoinEUI:  [8]byte{1, 1, 1, 1, 1, 1, 1, 1},
+			DevEUI:   [8]byte{2, 2, 2, 2, 2, 2, 2, 2},
+			DevNonce: 771,
+		},
+	}
+
+	if err := phy.SetUplinkJoinMIC(appKey); err != nil {
+		panic(err)
+	}
+
+	str, err := phy.MarshalText()
+	if err != nil {
+		panic(err)
+	}
+
+	bytes, err := phy.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(str))
+	fmt.Println(bytes)
+
+	// Output:
+	// AAEBAQEBAQEBAgICAgICAgIDAwm5ezI=
+	// [0 1 1 1 1 1 1 1 1 2 2 2 2 2 2 2 2 3 3 9 185 123 50]
+}
+
+func ExamplePHYPayload_joinAcceptSend() {
+	appKey := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	joinEUI := EUI64{8, 7, 6, 5, 4, 3, 2, 1}
+	devNonce := DevNonce(258)
+
+	phy := PHYPayload{
+		MHDR: MHDR{
+			MType: JoinAccept,
+			Major: LoRaWANR1,
+		},
+		MACPayload: &JoinAcceptPayload{
+			JoinNonce:  65793,
+			HomeNetID:  [3]byte{2, 2, 2},
+			DevAddr:    DevAddr([4]byte{1, 2, 3, 4}),
+			DLSettings: DLSettings{RX2DataRate: 0, RX1DROffset: 0},
+			RXDelay:    0,
+		},
+	}
+
+	// set the MIC before encryption
+	if err := phy.SetDownlinkJoinMIC(JoinRequestType, joinEUI, devNonce, appKey); err != nil {
+		panic(err)
+	}
+	if err := phy.EncryptJoinAcceptPayload(appKey); err != nil {
+		panic(err)
+	}
+
+	str, err := phy.MarshalText()
+	if err != nil {
+		panic(err)
+	}
+
+	bytes, err := phy.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(str))
+	fmt.Println(bytes)
+
+	// Output:
+	// ICPPM1SJquMYPAvguqje5fM=
+	// [32 35 207 51 84 137 170 227 24 60 11 224 186 168 222 229 243]
+}
+
+func ExamplePHYPayload_lorawan11JoinAcceptSend() {
+	appKey := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	joinEUI := EUI64{8, 7, 6, 5, 4, 3, 2, 1}
+	devNonce := DevNonce(258)
+
+	// note: the DLSettings OptNeg is set to true!
+	phy := PHYPayload{
+		MHDR: MHDR{
+			MType: JoinAccept,
+			Major: LoRaWANR1,
+		},
+		MACPayload: &JoinAcceptPayload{
+			JoinNonce:  65793,
+			HomeNetID:  [3]byte{2, 2, 2},
+			DevAddr:    DevAddr([4]byte{1, 2, 3, 4}),
+			DLSettings: DLSettings{RX2DataRate: 0, RX1DROffset: 0, OptNeg: true},
+			RXDelay:    0,
+		},
+	}
+
+	// set the MIC before encryption
+	if err := phy.SetDownlinkJoinMIC(JoinRequestType, joinEUI, devNonce, appKey); err != nil {
+		panic(err)
+	}
+	if err := phy.EncryptJoinAcceptPayload(appKey); err != nil {
+		panic(err)
+	}
+
+	str, err := phy.MarshalText()
+	if err != nil {
+		panic(err)
+	}
+
+	bytes, err := phy.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(str))
+	fmt.Println(bytes)
+
+	// Output:
+	// IHq+6gawKSDxHALQNI/PGBU=
+	// [32 122 190 234 6 176 41 32 241 28 2 208 52 143 207 24 21]
+}
+
+func ExamplePHYPayload_readJoinRequest() {
+	var phy PHYPayload
+	if err := phy.UnmarshalText([]byte("AAQDAgEEAwIBBQQDAgUEAwItEGqZDhI=")); err != nil {
+		panic(err)
+	}
+
+	jrPL, ok := phy.MACPayload.(*JoinRequestPayload)
+	if !ok {
+		panic("MACPayload must be a *JoinRequestPayload")
+	}
+
+	fmt.Println(phy.MHDR.MType)
+	fmt.Println(jrPL.JoinEUI)
+	fmt.Println(jrPL.DevEUI)
+	fmt.Println(jrPL.DevNonce)
+
+	// Output:
+	// JoinRequest
+	// 0102030401020304
+	// 0203040502030405
+	// 4141
+}
@@ -0,0 +1,188 @@
+// Package qr implements the LoRa Alliance device onboarding QR code format,
+// as specified by the LoRa Alliance TR005 technical recommendation.
+//
+// Example:
+//
+//	LW:D0:1122334455667788:AABBCCDDEEFF0011:AABB1122:OAABBCCDD:SYYWWNNNNNN:PFOOBAR:C056B
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brocaar/lorawan"
+)
+
+// SchemeID defines the (only) supported QR code scheme.
+const SchemeID = "D0"
+
+const prefix = "LW"
+
+// Errors.
+var (
+	ErrInvalidPrefix   = errors.New("lorawan/qr: QR code must start with LW")
+	ErrInvalidSchemeID = errors.New("lorawan/qr: unsupported SchemeID")
+	ErrInvalidFormat   = errors.New("lorawan/qr: invalid QR code format")
+	ErrInvalidChecksum = errors.New("lorawan/qr: invalid checksum")
+)
+
+// Code represents the device onboarding QR code.
+type Code struct {
+	JoinEUI lorawan.EUI64
+	DevEUI  lorawan.EUI64
+
+	// VendorID and ModelID form the ProfileID. The VendorID is assigned by
+	// the LoRa Alliance, the ModelID by the vendor.
+	VendorID uint16
+	ModelID  uint16
+
+	// Optional fields.
+	OwnerToken   string
+	SerialNumber string
+	Proprietary  string
+
+	// Checksum defines if the checksum must be appended when encoding.
+	// When decoding, it is set when the QR code contains a (valid) checksum.
+	Checksum bool
+}
+
+// String returns the QR code as string. It returns an empty string in case
+// the code contains invalid optional fields.
+func (c Code) String() string {
+	b, err := c.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Code) MarshalText() ([]byte, error) {
+	fields := []string{
+		prefix,
+		SchemeID,
+		strings.ToUpper(c.JoinEUI.String()),
+		strings.ToUpper(c.DevEUI.String()),
+		fmt.Sprintf("%04X%04X", c.VendorID, c.ModelID),
+	}
+
+	for _, opt := range []struct {
+		tag   string
+		value string
+	}{
+		{"O", c.OwnerToken},
+		{"S", c.SerialNumber},
+		{"P", c.Proprietary},
+	} {
+		if opt.value == "" {
+			continue
+		}
+		if strings.Contains(opt.value, ":") {
+			return nil, fmt.Errorf("lorawan/qr: option %s must not contain ':'", opt.tag)
+		}
+		fields = append(fields, opt.tag+opt.value)
+	}
+
+	s := strings.Join(fields, ":")
+	if c.Checksum {
+		// the checksum covers all preceding characters, including the ':'
+		s += ":"
+		s += fmt.Sprintf("C%04X", checksum(s))
+	}
+
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Code) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	fields := strings.Split(s, ":")
+
+	if len(fields) < 5 {
+		return ErrInvalidFormat
+	}
+	if fields[0] != prefix {
+		return ErrInvalidPrefix
+	}
+	if fields[1] != SchemeID {
+		return ErrInvalidSchemeID
+	}
+
+	var out Code
+
+	if err := out.JoinEUI.UnmarshalText([]byte(fields[2])); err != nil {
+		return fmt.Errorf("lorawan/qr: invalid JoinEUI: %w", err)
+	}
+	if err := out.DevEUI.UnmarshalText([]byte(fields[3])); err != nil {
+		return fmt.Errorf("lorawan/qr: invalid DevEUI: %w", err)
+	}
+
+	if len(fields[4]) != 8 {
+		return errors.New("lorawan/qr: ProfileID must be 8 characters")
+	}
+	profileID, err := strconv.ParseUint(fields[4], 16, 32)
+	if err != nil {
+		return fmt.Errorf("lorawan/qr: invalid ProfileID: %w", err)
+	}
+	out.VendorID = uint16(profileID >> 16)
+	out.ModelID = uint16(profileID)
+
+	for i, f := range fields[5:] {
+		if f == "" {
+			return ErrInvalidFormat
+		}
+
+		switch f[0] {
+		case 'O':
+			out.OwnerToken = f[1:]
+		case 'S':
+			out.SerialNumber = f[1:]
+		case 'P':
+			out.Proprietary = f[1:]
+		case 'C':
+			if i != len(fields[5:])-1 {
+				return errors.New("lorawan/qr: checksum must be the last field")
+			}
+
+			sum, err := strconv.ParseUint(f[1:], 16, 16)
+			if err != nil || len(f) != 5 {
+				return ErrInvalidChecksum
+			}
+			if uint16(sum) != checksum(s[:len(s)-len(f)]) {
+				return ErrInvalidChecksum
+			}
+			out.Checksum = true
+		default:
+			// unknown options are ignored for forward compatibility
+		}
+	}
+
+	*c = out
+	return nil
+}
+
+// Parse parses the given QR code string.
+func Parse(s string) (Code, error) {
+	var c Code
+	err := c.UnmarshalText([]byte(s))
+	return c, err
+}
+
+// checksum returns the CRC-16/CCITT-FALSE (poly 0x1021, init 0xffff) of the
+// given string, which contains all characters preceding the checksum field.
+func checksum(s string) uint16 {
+	crc := uint16(0xffff)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
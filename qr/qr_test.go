@@ -0,0 +1,87 @@
+package qr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		Name string
+		Code Code
+		Text string
+	}{
+		{
+			Name: "mandatory fields only",
+			Code: Code{
+				JoinEUI:  lorawan.EUI64{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88},
+				DevEUI:   lorawan.EUI64{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00, 0x11},
+				VendorID: 0xaabb,
+				ModelID:  0x1122,
+			},
+			Text: "LW:D0:1122334455667788:AABBCCDDEEFF0011:AABB1122",
+		},
+		{
+			Name: "all fields",
+			Code: Code{
+				JoinEUI:      lorawan.EUI64{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88},
+				DevEUI:       lorawan.EUI64{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00, 0x11},
+				VendorID:     0xaabb,
+				ModelID:      0x1122,
+				OwnerToken:   "AABBCCDD",
+				SerialNumber: "YYWWNNNNNN",
+				Proprietary:  "FOOBAR",
+				Checksum:     true,
+			},
+			Text: "LW:D0:1122334455667788:AABBCCDDEEFF0011:AABB1122:OAABBCCDD:SYYWWNNNNNN:PFOOBAR:C056B",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := tst.Code.MarshalText()
+			assert.NoError(err)
+			assert.Equal(tst.Text, string(b))
+			assert.Equal(tst.Text, tst.Code.String())
+
+			c, err := Parse(tst.Text)
+			assert.NoError(err)
+			assert.Equal(tst.Code, c)
+		})
+	}
+
+	t.Run("Parse errors", func(t *testing.T) {
+		tests := []struct {
+			Text          string
+			ExpectedError string
+		}{
+			{"LW:D0:1122334455667788", ErrInvalidFormat.Error()},
+			{"XX:D0:1122334455667788:AABBCCDDEEFF0011:AABB1122", ErrInvalidPrefix.Error()},
+			{"LW:D1:1122334455667788:AABBCCDDEEFF0011:AABB1122", ErrInvalidSchemeID.Error()},
+			{"LW:D0:11223344556677:AABBCCDDEEFF0011:AABB1122", "lorawan/qr: invalid JoinEUI: lorawan: exactly 8 bytes are expected"},
+			{"LW:D0:1122334455667788:AABBCCDDEEFF0011:AABB11", "lorawan/qr: ProfileID must be 8 characters"},
+			{"LW:D0:1122334455667788:AABBCCDDEEFF0011:AABB1122:C0000", ErrInvalidChecksum.Error()},
+			{"LW:D0:1122334455667788:AABBCCDDEEFF0011:AABB1122:C0000:SABC", "lorawan/qr: checksum must be the last field"},
+			{"LW:D0:1122334455667788:AABBCCDDEEFF0011:AABB1122::SABC", ErrInvalidFormat.Error()},
+		}
+
+		for _, tst := range tests {
+			t.Run(tst.Text, func(t *testing.T) {
+				assert := require.New(t)
+				_, err := Parse(tst.Text)
+				assert.EqualError(err, tst.ExpectedError)
+			})
+		}
+	})
+
+	t.Run("Option containing separator", func(t *testing.T) {
+		assert := require.New(t)
+		_, err := Code{SerialNumber: "A:B"}.MarshalText()
+		assert.EqualError(err, "lorawan/qr: option S must not contain ':'")
+	})
+}
@@ -0,0 +1,67 @@
+// Package registry implements a generic command payload registry, used to
+// lookup the payload type of a command by its direction and command
+// identifier (CID).
+package registry
+
+import "sync"
+
+// Command holds the registration of a command payload.
+type Command[P any] struct {
+	// Size holds the payload size in bytes. This can be left 0 when the
+	// size can be derived from the payload itself.
+	Size int
+
+	// New returns a new (empty) payload instance.
+	New func() P
+}
+
+// Registry holds the command payloads for uplink and downlink commands.
+// It is safe for concurrent use.
+type Registry[C ~byte, P any] struct {
+	mu       sync.RWMutex
+	commands map[bool]map[C]Command[P]
+}
+
+// New returns a new Registry, initialized with the given uplink and downlink
+// commands.
+func New[C ~byte, P any](uplink, downlink map[C]Command[P]) *Registry[C, P] {
+	r := Registry[C, P]{
+		commands: map[bool]map[C]Command[P]{
+			true:  make(map[C]Command[P]),
+			false: make(map[C]Command[P]),
+		},
+	}
+
+	for cid, cmd := range uplink {
+		r.commands[true][cid] = cmd
+	}
+	for cid, cmd := range downlink {
+		r.commands[false][cid] = cmd
+	}
+
+	return &r
+}
+
+// Register registers (or overwrites) the command payload for the given
+// direction and CID.
+func (r *Registry[C, P]) Register(uplink bool, cid C, cmd Command[P]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands[uplink][cid] = cmd
+}
+
+// Get returns a new payload instance and its size for the given direction
+// and CID. The returned bool is false when no payload has been registered.
+func (r *Registry[C, P]) Get(uplink bool, cid C) (P, int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cmd, ok := r.commands[uplink][cid]
+	if !ok {
+		var p P
+		return p, 0, false
+	}
+
+	return cmd.New(), cmd.Size, true
+}
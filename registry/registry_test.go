@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCID byte
+
+type testPayload struct {
+	Value int
+}
+
+func TestRegistry(t *testing.T) {
+	assert := require.New(t)
+
+	r := New(
+		map[testCID]Command[*testPayload]{
+			0x01: {Size: 1, New: func() *testPayload { return &testPayload{Value: 1} }},
+		},
+		map[testCID]Command[*testPayload]{
+			0x01: {Size: 2, New: func() *testPayload { return &testPayload{Value: 2} }},
+		},
+	)
+
+	t.Run("Get uplink", func(t *testing.T) {
+		p, size, ok := r.Get(true, 0x01)
+		assert.True(ok)
+		assert.Equal(1, size)
+		assert.Equal(&testPayload{Value: 1}, p)
+	})
+
+	t.Run("Get downlink", func(t *testing.T) {
+		p, size, ok := r.Get(false, 0x01)
+		assert.True(ok)
+		assert.Equal(2, size)
+		assert.Equal(&testPayload{Value: 2}, p)
+	})
+
+	t.Run("Get returns a new instance", func(t *testing.T) {
+		p1, _, _ := r.Get(true, 0x01)
+		p2, _, _ := r.Get(true, 0x01)
+		assert.NotSame(p1, p2)
+	})
+
+	t.Run("Get unknown", func(t *testing.T) {
+		p, size, ok := r.Get(true, 0x02)
+		assert.False(ok)
+		assert.Equal(0, size)
+		assert.Nil(p)
+	})
+
+	t.Run("Register", func(t *testing.T) {
+		r.Register(true, 0x02, Command[*testPayload]{Size: 3, New: func() *testPayload { return &testPayload{Value: 3} }})
+
+		p, size, ok := r.Get(true, 0x02)
+		assert.True(ok)
+		assert.Equal(3, size)
+		assert.Equal(&testPayload{Value: 3}, p)
+	})
+}
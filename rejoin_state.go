@@ -0,0 +1,122 @@
+package lorawan
+
+import "time"
+
+// RejoinState holds the periodic rejoin state of a device, as configured by
+// the RejoinParamSetupReq mac-command. After the RejoinParamSetupReq has been
+// applied, the device must send a rejoin-request (type 0) at least every
+// MaxCount uplinks and, when supported by the device (TimeOK), at least every
+// MaxTime.
+//
+// On the network-server side, it can be used to detect the devices which
+// missed their rejoin window. On the device side (e.g. a device simulator),
+// it can be used to schedule the rejoin-requests.
+type RejoinState struct {
+	// Enabled is set when the RejoinParamSetupReq has been applied.
+	Enabled bool `json:"enabled"`
+
+	MaxTimeN  uint8 `json:"maxTimeN"`
+	MaxCountN uint8 `json:"maxCountN"`
+
+	// TimeOK holds the TimeOK field of the RejoinParamSetupAns. When false,
+	// the device only implements the MaxCountN limit.
+	TimeOK bool `json:"timeOK"`
+
+	// LastRejoin holds the time of the last rejoin-request, or the time the
+	// RejoinParamSetupReq was applied.
+	LastRejoin time.Time `json:"lastRejoin"`
+
+	// UplinkCount holds the number of uplinks since the last rejoin-request.
+	UplinkCount uint32 `json:"uplinkCount"`
+}
+
+// ApplyRejoinParamSetup applies the given RejoinParamSetupReq and
+// RejoinParamSetupAns payloads at the given time, which starts a new rejoin
+// window. An error is returned for invalid payloads, in which case the state
+// is not modified.
+func (s *RejoinState) ApplyRejoinParamSetup(req RejoinParamSetupReqPayload, ans RejoinParamSetupAnsPayload, t time.Time) error {
+	if _, err := req.MarshalBinary(); err != nil {
+		return err
+	}
+
+	*s = RejoinState{
+		Enabled:    true,
+		MaxTimeN:   req.MaxTimeN,
+		MaxCountN:  req.MaxCountN,
+		TimeOK:     ans.TimeOK,
+		LastRejoin: t,
+	}
+
+	return nil
+}
+
+// MaxTime returns the max. time between two rejoin-requests. It returns 0
+// when the time limit does not apply.
+func (s RejoinState) MaxTime() time.Duration {
+	if !s.Enabled || !s.TimeOK {
+		return 0
+	}
+	return RejoinParamSetupReqPayload{MaxTimeN: s.MaxTimeN}.MaxTime()
+}
+
+// MaxCount returns the max. number of uplinks between two rejoin-requests.
+// It returns 0 when the periodic rejoin is not enabled.
+func (s RejoinState) MaxCount() uint32 {
+	if !s.Enabled {
+		return 0
+	}
+	return RejoinParamSetupReqPayload{MaxCountN: s.MaxCountN}.MaxCount()
+}
+
+// Uplink must be called for every (non rejoin-request) uplink of the device.
+func (s *RejoinState) Uplink() {
+	s.UplinkCount++
+}
+
+// Rejoin must be called for every rejoin-request (type 0) of the device.
+// It starts a new rejoin window.
+func (s *RejoinState) Rejoin(t time.Time) {
+	s.LastRejoin = t
+	s.UplinkCount = 0
+}
+
+// NextRejoinTime returns the time before which the next rejoin-request must
+// be sent. It returns false when the time limit does not apply.
+func (s RejoinState) NextRejoinTime() (time.Time, bool) {
+	maxTime := s.MaxTime()
+	if maxTime == 0 {
+		return time.Time{}, false
+	}
+	return s.LastRejoin.Add(maxTime), true
+}
+
+// RejoinDue returns true when the device must send a rejoin-request at the
+// given time, as the MaxCount or MaxTime limit has been reached.
+func (s RejoinState) RejoinDue(t time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+
+	if s.UplinkCount >= s.MaxCount() {
+		return true
+	}
+
+	next, ok := s.NextRejoinTime()
+	return ok && !t.Before(next)
+}
+
+// RejoinMissed returns true when the device missed its rejoin window at the
+// given time, meaning that it has sent more than MaxCount uplinks or that
+// more than MaxTime has elapsed since the last rejoin-request.
+func (s RejoinState) RejoinMissed(t time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+
+	if s.UplinkCount > s.MaxCount() {
+		return true
+	}
+
+	next, ok := s.NextRejoinTime()
+	return ok && t.After(next)
+}
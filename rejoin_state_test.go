@@ -0,0 +1,91 @@
+package lorawan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejoinParamSetupReqPayloadLimits(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(1024*time.Second, RejoinParamSetupReqPayload{MaxTimeN: 0}.MaxTime())
+	assert.Equal(time.Duration(1<<25)*time.Second, RejoinParamSetupReqPayload{MaxTimeN: 15}.MaxTime())
+	assert.Equal(uint32(16), RejoinParamSetupReqPayload{MaxCountN: 0}.MaxCount())
+	assert.Equal(uint32(1<<19), RejoinParamSetupReqPayload{MaxCountN: 15}.MaxCount())
+}
+
+func TestRejoinState(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("not enabled", func(t *testing.T) {
+		assert := require.New(t)
+
+		var s RejoinState
+		for i := 0; i < 100; i++ {
+			s.Uplink()
+		}
+		assert.False(s.RejoinDue(start))
+		assert.False(s.RejoinMissed(start))
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		assert := require.New(t)
+
+		var s RejoinState
+		assert.EqualError(s.ApplyRejoinParamSetup(RejoinParamSetupReqPayload{MaxTimeN: 16}, RejoinParamSetupAnsPayload{}, start), "lorawan: max value of MaxTimeN is 15")
+		assert.Equal(RejoinState{}, s)
+	})
+
+	t.Run("count limit", func(t *testing.T) {
+		assert := require.New(t)
+
+		var s RejoinState
+		assert.NoError(s.ApplyRejoinParamSetup(RejoinParamSetupReqPayload{MaxTimeN: 0, MaxCountN: 0}, RejoinParamSetupAnsPayload{TimeOK: false}, start))
+		assert.Equal(uint32(16), s.MaxCount())
+		assert.Equal(time.Duration(0), s.MaxTime())
+
+		_, ok := s.NextRejoinTime()
+		assert.False(ok)
+
+		for i := 0; i < 15; i++ {
+			s.Uplink()
+		}
+		assert.False(s.RejoinDue(start.Add(time.Hour)))
+
+		s.Uplink()
+		assert.True(s.RejoinDue(start))
+		assert.False(s.RejoinMissed(start))
+
+		s.Uplink()
+		assert.True(s.RejoinMissed(start))
+
+		s.Rejoin(start)
+		assert.False(s.RejoinDue(start))
+		assert.False(s.RejoinMissed(start))
+	})
+
+	t.Run("time limit", func(t *testing.T) {
+		assert := require.New(t)
+
+		var s RejoinState
+		assert.NoError(s.ApplyRejoinParamSetup(RejoinParamSetupReqPayload{MaxTimeN: 1, MaxCountN: 15}, RejoinParamSetupAnsPayload{TimeOK: true}, start))
+		assert.Equal(2048*time.Second, s.MaxTime())
+
+		next, ok := s.NextRejoinTime()
+		assert.True(ok)
+		assert.Equal(start.Add(2048*time.Second), next)
+
+		assert.False(s.RejoinDue(next.Add(-time.Second)))
+		assert.True(s.RejoinDue(next))
+		assert.False(s.RejoinMissed(next))
+		assert.True(s.RejoinMissed(next.Add(time.Second)))
+
+		s.Rejoin(next)
+		assert.False(s.RejoinDue(next))
+
+		next, _ = s.NextRejoinTime()
+		assert.Equal(start.Add(4096*time.Second), next)
+	})
+}
@@ -0,0 +1,103 @@
+package relay
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/crypto"
+)
+
+// ErrWFCntReplay is returned when the WOR frame-counter has already been used.
+var ErrWFCntReplay = errors.New("relay: WFCnt has already been used (replay)")
+
+// EncryptWORPayload encrypts (or decrypts) the WOR payload using the
+// WorSEncKey. The WOR frame is sent by the end-device (uplink), the WOR ACK
+// is sent by the relay (downlink).
+func EncryptWORPayload(worSEncKey lorawan.AES128Key, uplink bool, devAddr lorawan.DevAddr, wFCnt uint32, data []byte) ([]byte, error) {
+	a, err := getBlock(0x01, uplink, devAddr, wFCnt)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	return crypto.EncryptABlocks(worSEncKey, a, out)
+}
+
+// CalculateWORMIC calculates the MIC of the given (encrypted) WOR payload
+// using the WorSIntKey.
+func CalculateWORMIC(worSIntKey lorawan.AES128Key, uplink bool, devAddr lorawan.DevAddr, wFCnt uint32, data []byte) (lorawan.MIC, error) {
+	var mic lorawan.MIC
+
+	b0, err := getBlock(0x49, uplink, devAddr, wFCnt)
+	if err != nil {
+		return mic, err
+	}
+	b0[15] = byte(len(data))
+
+	return crypto.MIC(worSIntKey, b0[:], data)
+}
+
+// ValidateWORMIC validates the MIC of the given (encrypted) WOR payload.
+func ValidateWORMIC(worSIntKey lorawan.AES128Key, uplink bool, devAddr lorawan.DevAddr, wFCnt uint32, data []byte, mic lorawan.MIC) (bool, error) {
+	calculated, err := CalculateWORMIC(worSIntKey, uplink, devAddr, wFCnt, data)
+	if err != nil {
+		return false, err
+	}
+	return calculated == mic, nil
+}
+
+// WFCntState holds the WOR frame-counter state of an end-device, used to
+// protect against replayed WOR frames.
+type WFCntState struct {
+	// WFCnt holds the last accepted (full 32 bit) WOR frame-counter.
+	WFCnt uint32 `json:"wFCnt"`
+
+	// Valid is set once a WOR frame-counter has been accepted.
+	Valid bool `json:"valid"`
+}
+
+// GetFullWFCnt returns the full 32 bit WOR frame-counter, given the 16 LSB
+// of the received frame-counter.
+func (s WFCntState) GetFullWFCnt(wFCnt uint16) uint32 {
+	full := (s.WFCnt &^ 0xffff) | uint32(wFCnt)
+	if full < s.WFCnt {
+		full += 1 << 16
+	}
+	return full
+}
+
+// Accept validates the given (full 32 bit) WOR frame-counter and updates the
+// state. ErrWFCntReplay is returned when the frame-counter is not greater
+// than the last accepted frame-counter. Note that the MIC must be validated
+// (using the full frame-counter) before calling Accept.
+func (s *WFCntState) Accept(wFCnt uint32) error {
+	if s.Valid && wFCnt <= s.WFCnt {
+		return ErrWFCntReplay
+	}
+
+	s.WFCnt = wFCnt
+	s.Valid = true
+	return nil
+}
+
+// getBlock returns the A / B0 block used for the WOR encryption and MIC
+// calculation.
+func getBlock(typ byte, uplink bool, devAddr lorawan.DevAddr, wFCnt uint32) ([16]byte, error) {
+	var b [16]byte
+	b[0] = typ
+	if !uplink {
+		b[5] = 0x01
+	}
+
+	devAddrB, err := devAddr.MarshalBinary()
+	if err != nil {
+		return b, err
+	}
+	copy(b[6:10], devAddrB)
+	binary.LittleEndian.PutUint32(b[10:14], wFCnt)
+
+	return b, nil
+}
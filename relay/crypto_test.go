@@ -0,0 +1,105 @@
+package relay
+
+import (
+	"testing"
+
+	"github.com/brocaar/lorawan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWORPayloadCrypto(t *testing.T) {
+	assert := require.New(t)
+
+	worSEncKey := lorawan.AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	worSIntKey := lorawan.AES128Key{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	devAddr := lorawan.DevAddr{1, 2, 3, 4}
+	plaintext := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18}
+
+	encrypted, err := EncryptWORPayload(worSEncKey, true, devAddr, 10, plaintext)
+	assert.NoError(err)
+	assert.Len(encrypted, len(plaintext))
+	assert.NotEqual(plaintext, encrypted)
+	assert.Equal([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18}, plaintext, "input must not be modified")
+
+	t.Run("Decrypt", func(t *testing.T) {
+		assert := require.New(t)
+		decrypted, err := EncryptWORPayload(worSEncKey, true, devAddr, 10, encrypted)
+		assert.NoError(err)
+		assert.Equal(plaintext, decrypted)
+	})
+
+	t.Run("Direction and WFCnt are part of the keystream", func(t *testing.T) {
+		assert := require.New(t)
+		b, err := EncryptWORPayload(worSEncKey, false, devAddr, 10, plaintext)
+		assert.NoError(err)
+		assert.NotEqual(encrypted, b)
+
+		b, err = EncryptWORPayload(worSEncKey, true, devAddr, 11, plaintext)
+		assert.NoError(err)
+		assert.NotEqual(encrypted, b)
+	})
+
+	t.Run("MIC", func(t *testing.T) {
+		assert := require.New(t)
+		mic, err := CalculateWORMIC(worSIntKey, true, devAddr, 10, encrypted)
+		assert.NoError(err)
+
+		ok, err := ValidateWORMIC(worSIntKey, true, devAddr, 10, encrypted, mic)
+		assert.NoError(err)
+		assert.True(ok)
+
+		ok, err = ValidateWORMIC(worSIntKey, true, devAddr, 11, encrypted, mic)
+		assert.NoError(err)
+		assert.False(ok)
+	})
+}
+
+func TestWFCntState(t *testing.T) {
+	tests := []struct {
+		name          string
+		state         WFCntState
+		wFCnt         uint16
+		expectedFull  uint32
+		expectedError error
+	}{
+		{
+			name:         "first frame",
+			wFCnt:        5,
+			expectedFull: 5,
+		},
+		{
+			name:         "increment",
+			state:        WFCntState{WFCnt: 5, Valid: true},
+			wFCnt:        6,
+			expectedFull: 6,
+		},
+		{
+			name:         "16 bit rollover",
+			state:        WFCntState{WFCnt: 65535, Valid: true},
+			wFCnt:        1,
+			expectedFull: 65537,
+		},
+		{
+			name:          "replay",
+			state:         WFCntState{WFCnt: 65537, Valid: true},
+			wFCnt:         1,
+			expectedFull:  65537,
+			expectedError: ErrWFCntReplay,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			full := tst.state.GetFullWFCnt(tst.wFCnt)
+			assert.Equal(tst.expectedFull, full)
+
+			err := tst.state.Accept(full)
+			assert.Equal(tst.expectedError, err)
+			if err == nil {
+				assert.Equal(WFCntState{WFCnt: full, Valid: true}, tst.state)
+			}
+		})
+	}
+}
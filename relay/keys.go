@@ -0,0 +1,40 @@
+// Package relay implements the LoRaWAN Relay (TS011) wake-on-radio (WOR)
+// key derivation and frame crypto.
+package relay
+
+import (
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/crypto"
+)
+
+// GetRootWorSKey returns the RootWorSKey given the NwkSEncKey of the
+// end-device. For LoRaWAN 1.0.x devices, the NwkSKey must be used.
+func GetRootWorSKey(nwkSEncKey lorawan.AES128Key) (lorawan.AES128Key, error) {
+	return getKey(nwkSEncKey, [16]byte{0x01})
+}
+
+// GetWorSIntKey returns the WorSIntKey given the RootWorSKey and DevAddr.
+func GetWorSIntKey(rootWorSKey lorawan.AES128Key, devAddr lorawan.DevAddr) (lorawan.AES128Key, error) {
+	return getWorSKey(rootWorSKey, 0x01, devAddr)
+}
+
+// GetWorSEncKey returns the WorSEncKey given the RootWorSKey and DevAddr.
+func GetWorSEncKey(rootWorSKey lorawan.AES128Key, devAddr lorawan.DevAddr) (lorawan.AES128Key, error) {
+	return getWorSKey(rootWorSKey, 0x02, devAddr)
+}
+
+func getWorSKey(rootWorSKey lorawan.AES128Key, typ byte, devAddr lorawan.DevAddr) (lorawan.AES128Key, error) {
+	b := [16]byte{typ}
+
+	devAddrB, err := devAddr.MarshalBinary()
+	if err != nil {
+		return lorawan.AES128Key{}, err
+	}
+	copy(b[1:5], devAddrB)
+
+	return getKey(rootWorSKey, b)
+}
+
+func getKey(key lorawan.AES128Key, b [16]byte) (lorawan.AES128Key, error) {
+	return crypto.EncryptBlock(key, b)
+}
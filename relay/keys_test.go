@@ -0,0 +1,35 @@
+package relay
+
+import (
+	"testing"
+
+	"github.com/brocaar/lorawan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeys(t *testing.T) {
+	nwkSEncKey := lorawan.AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	rootWorSKey := lorawan.AES128Key{0x45, 0x22, 0xa0, 0x3d, 0x98, 0x00, 0x9d, 0x55, 0x45, 0xed, 0x42, 0xfb, 0xd8, 0x35, 0x78, 0xd0}
+	devAddr := lorawan.DevAddr{1, 2, 3, 4}
+
+	t.Run("GetRootWorSKey", func(t *testing.T) {
+		assert := require.New(t)
+		key, err := GetRootWorSKey(nwkSEncKey)
+		assert.NoError(err)
+		assert.Equal(rootWorSKey, key)
+	})
+
+	t.Run("GetWorSIntKey", func(t *testing.T) {
+		assert := require.New(t)
+		key, err := GetWorSIntKey(rootWorSKey, devAddr)
+		assert.NoError(err)
+		assert.Equal(lorawan.AES128Key{0x06, 0x0c, 0x1a, 0x76, 0x6f, 0xff, 0xdf, 0x10, 0x98, 0xdd, 0x52, 0x4c, 0x93, 0xeb, 0xe3, 0x95}, key)
+	})
+
+	t.Run("GetWorSEncKey", func(t *testing.T) {
+		assert := require.New(t)
+		key, err := GetWorSEncKey(rootWorSKey, devAddr)
+		assert.NoError(err)
+		assert.Equal(lorawan.AES128Key{0x41, 0x46, 0x2a, 0xa4, 0x1c, 0x78, 0x48, 0x63, 0x94, 0xcb, 0x43, 0x74, 0xfe, 0xad, 0xe9, 0xd6}, key)
+	})
+}
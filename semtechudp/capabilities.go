@@ -0,0 +1,124 @@
+package semtechudp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Errors
+var (
+	ErrTXFreq      = errors.New("lorawan/semtechudp: frequency is not supported by the gateway")
+	ErrTXPower     = errors.New("lorawan/semtechudp: tx power exceeds the max. power of the gateway")
+	ErrDataRate    = errors.New("lorawan/semtechudp: data-rate is not supported by the gateway")
+	ErrModulation  = errors.New("lorawan/semtechudp: modulation is not supported by the gateway")
+	ErrRFChain     = errors.New("lorawan/semtechudp: rf chain does not exist")
+	ErrPayloadSize = errors.New("lorawan/semtechudp: payload size exceeds the max. payload size of the gateway")
+)
+
+// FrequencyRange defines a frequency range (Hz, inclusive).
+type FrequencyRange struct {
+	Min uint32
+	Max uint32
+}
+
+// Capabilities defines the TX capabilities of a gateway. These are
+// typically derived from the gateway board information. Fields that are
+// left empty are not validated.
+type Capabilities struct {
+	// TXFrequencies holds the frequency ranges that the gateway is able to
+	// transmit on.
+	TXFrequencies []FrequencyRange
+
+	// MaxTXPower holds the max. TX power (dBm).
+	MaxTXPower *uint8
+
+	// RFChains holds the number of RF chains of the gateway.
+	RFChains uint8
+
+	// LoRaDataRates holds the supported LoRa data-rate identifiers
+	// (e.g. SF7BW125).
+	LoRaDataRates []string
+
+	// FSK is set when the gateway supports FSK modulation.
+	FSK bool
+
+	// MaxPayloadSize holds the max. RF payload size in bytes.
+	MaxPayloadSize int
+}
+
+// ValidateTXPK validates the given TXPK against the capabilities of the
+// gateway and the internal consistency of the TXPK. It makes it possible to
+// reject downlinks that the gateway is not able to emit, before these are
+// sent to the gateway.
+func (c Capabilities) ValidateTXPK(txpk TXPK) error {
+	if int(txpk.Size) != len(txpk.Data) {
+		return fmt.Errorf("lorawan/semtechudp: size %d does not match data length %d", txpk.Size, len(txpk.Data))
+	}
+
+	timing := 0
+	if txpk.Imme {
+		timing++
+	}
+	if txpk.Tmst != nil {
+		timing++
+	}
+	if txpk.Tmms != nil {
+		timing++
+	}
+	if timing != 1 {
+		return errors.New("lorawan/semtechudp: exactly one of imme, tmst or tmms must be set")
+	}
+
+	if c.MaxPayloadSize != 0 && len(txpk.Data) > c.MaxPayloadSize {
+		return ErrPayloadSize
+	}
+
+	if len(c.TXFrequencies) != 0 {
+		freq := uint32(math.Round(txpk.Freq * 1000000))
+		var ok bool
+		for _, r := range c.TXFrequencies {
+			if freq >= r.Min && freq <= r.Max {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return ErrTXFreq
+		}
+	}
+
+	if c.MaxTXPower != nil && txpk.Powe > *c.MaxTXPower {
+		return ErrTXPower
+	}
+
+	if c.RFChains != 0 && txpk.RFCh >= c.RFChains {
+		return ErrRFChain
+	}
+
+	switch txpk.Modu {
+	case ModulationLoRa:
+		if txpk.DatR.LoRa == "" {
+			return ErrDataRate
+		}
+		if len(c.LoRaDataRates) == 0 {
+			return nil
+		}
+		for _, dr := range c.LoRaDataRates {
+			if dr == txpk.DatR.LoRa {
+				return nil
+			}
+		}
+		return ErrDataRate
+	case ModulationFSK:
+		if !c.FSK {
+			return ErrModulation
+		}
+		if txpk.DatR.FSK == 0 {
+			return ErrDataRate
+		}
+		return nil
+	default:
+		return ErrModulation
+	}
+}
@@ -0,0 +1,79 @@
+package semtechudp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatR(t *testing.T) {
+	assert := require.New(t)
+
+	var d DatR
+	assert.NoError(json.Unmarshal([]byte(`"SF7BW125"`), &d))
+	assert.Equal(DatR{LoRa: "SF7BW125"}, d)
+
+	d = DatR{}
+	assert.NoError(json.Unmarshal([]byte(`50000`), &d))
+	assert.Equal(DatR{FSK: 50000}, d)
+
+	b, err := json.Marshal(PullRespPayload{TXPK: TXPK{Imme: true, Freq: 869.525, Modu: ModulationLoRa, DatR: DatR{LoRa: "SF9BW125"}, Size: 2, Data: []byte{1, 2}}})
+	assert.NoError(err)
+	assert.JSONEq(`{"txpk":{"imme":true,"freq":869.525,"rfch":0,"powe":0,"modu":"LORA","datr":"SF9BW125","ipol":false,"size":2,"data":"AQI="}}`, string(b))
+}
+
+func TestCapabilitiesValidateTXPK(t *testing.T) {
+	tmst := uint32(12345)
+	maxPower := uint8(27)
+
+	c := Capabilities{
+		TXFrequencies:  []FrequencyRange{{Min: 863000000, Max: 870000000}},
+		MaxTXPower:     &maxPower,
+		RFChains:       2,
+		LoRaDataRates:  []string{"SF12BW125", "SF7BW125"},
+		MaxPayloadSize: 5,
+	}
+
+	valid := func() TXPK {
+		return TXPK{
+			Tmst: &tmst,
+			Freq: 868.1,
+			RFCh: 0,
+			Powe: 14,
+			Modu: ModulationLoRa,
+			DatR: DatR{LoRa: "SF7BW125"},
+			CodR: "4/5",
+			IPol: true,
+			Size: 3,
+			Data: []byte{1, 2, 3},
+		}
+	}
+
+	tests := []struct {
+		Name   string
+		Modify func(*TXPK)
+		Error  error
+	}{
+		{"valid", func(*TXPK) {}, nil},
+		{"frequency", func(t *TXPK) { t.Freq = 915.0 }, ErrTXFreq},
+		{"tx power", func(t *TXPK) { t.Powe = 30 }, ErrTXPower},
+		{"rf chain", func(t *TXPK) { t.RFCh = 2 }, ErrRFChain},
+		{"data-rate", func(t *TXPK) { t.DatR = DatR{LoRa: "SF9BW125"} }, ErrDataRate},
+		{"fsk", func(t *TXPK) { t.Modu = ModulationFSK; t.DatR = DatR{FSK: 50000} }, ErrModulation},
+		{"payload size", func(t *TXPK) { t.Data = make([]byte, 6); t.Size = 6 }, ErrPayloadSize},
+		{"size mismatch", func(t *TXPK) { t.Size = 4 }, errors.New("lorawan/semtechudp: size 4 does not match data length 3")},
+		{"timing", func(t *TXPK) { t.Imme = true }, errors.New("lorawan/semtechudp: exactly one of imme, tmst or tmms must be set")},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			txpk := valid()
+			tst.Modify(&txpk)
+			assert.Equal(tst.Error, c.ValidateTXPK(txpk))
+		})
+	}
+}
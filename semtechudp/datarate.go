@@ -0,0 +1,70 @@
+package semtechudp
+
+import (
+	"fmt"
+
+	"github.com/brocaar/lorawan/band"
+)
+
+// GetDataRate returns the band.DataRate for the given modulation and datr.
+func GetDataRate(modu string, datr DatR) (band.DataRate, error) {
+	switch modu {
+	case ModulationLoRa:
+		sf, bw, err := datr.LoRaSFBW()
+		if err != nil {
+			return band.DataRate{}, err
+		}
+		return band.DataRate{
+			Modulation:   band.LoRaModulation,
+			SpreadFactor: sf,
+			Bandwidth:    bw,
+		}, nil
+	case ModulationFSK:
+		if datr.FSK == 0 {
+			return band.DataRate{}, ErrDataRate
+		}
+		return band.DataRate{
+			Modulation: band.FSKModulation,
+			BitRate:    int(datr.FSK),
+		}, nil
+	default:
+		return band.DataRate{}, ErrModulation
+	}
+}
+
+// GetModulationAndDatR returns the modulation and datr for the given
+// band.DataRate. Note that LR-FHSS is not supported by the Semtech UDP
+// protocol.
+func GetModulationAndDatR(dr band.DataRate) (string, DatR, error) {
+	switch dr.Modulation {
+	case band.LoRaModulation:
+		return ModulationLoRa, NewLoRaDatR(dr.SpreadFactor, dr.Bandwidth), nil
+	case band.FSKModulation:
+		return ModulationFSK, DatR{FSK: uint32(dr.BitRate)}, nil
+	default:
+		return "", DatR{}, fmt.Errorf("lorawan/semtechudp: modulation %s is not supported", dr.Modulation)
+	}
+}
+
+// DataRate returns the band.DataRate of the RXPK.
+func (r RXPK) DataRate() (band.DataRate, error) {
+	return GetDataRate(r.Modu, r.DatR)
+}
+
+// DataRate returns the band.DataRate of the TXPK.
+func (t TXPK) DataRate() (band.DataRate, error) {
+	return GetDataRate(t.Modu, t.DatR)
+}
+
+// SetDataRate sets the modulation and datr of the TXPK, using the given
+// band.DataRate.
+func (t *TXPK) SetDataRate(dr band.DataRate) error {
+	modu, datr, err := GetModulationAndDatR(dr)
+	if err != nil {
+		return err
+	}
+
+	t.Modu = modu
+	t.DatR = datr
+	return nil
+}
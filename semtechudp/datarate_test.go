@@ -0,0 +1,78 @@
+package semtechudp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/band"
+)
+
+func TestDataRate(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Modu             string
+		DatR             DatR
+		ExpectedDataRate band.DataRate
+		ExpectedError    error
+	}{
+		{
+			Name:             "LoRa",
+			Modu:             ModulationLoRa,
+			DatR:             DatR{LoRa: "SF12BW125"},
+			ExpectedDataRate: band.DataRate{Modulation: band.LoRaModulation, SpreadFactor: 12, Bandwidth: 125},
+		},
+		{
+			Name:             "FSK",
+			Modu:             ModulationFSK,
+			DatR:             DatR{FSK: 50000},
+			ExpectedDataRate: band.DataRate{Modulation: band.FSKModulation, BitRate: 50000},
+		},
+		{
+			Name:          "invalid LoRa datr",
+			Modu:          ModulationLoRa,
+			DatR:          DatR{LoRa: "SF12"},
+			ExpectedError: errors.New("lorawan/semtechudp: invalid datr: SF12"),
+		},
+		{
+			Name:          "invalid modulation",
+			Modu:          "LR-FHSS",
+			ExpectedError: ErrModulation,
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			dr, err := GetDataRate(tst.Modu, tst.DatR)
+			if tst.ExpectedError != nil {
+				assert.Equal(tst.ExpectedError, err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tst.ExpectedDataRate, dr)
+
+			var txpk TXPK
+			assert.NoError(txpk.SetDataRate(dr))
+			assert.Equal(tst.Modu, txpk.Modu)
+			assert.Equal(tst.DatR, txpk.DatR)
+		})
+	}
+
+	t.Run("band data-rate index", func(t *testing.T) {
+		assert := require.New(t)
+
+		b, err := band.GetConfig(band.EU868, false, lorawan.DwellTimeNoLimit)
+		assert.NoError(err)
+
+		dr, err := RXPK{Modu: ModulationLoRa, DatR: DatR{LoRa: "SF7BW125"}}.DataRate()
+		assert.NoError(err)
+
+		drIndex, err := b.GetDataRateIndex(true, dr)
+		assert.NoError(err)
+		assert.Equal(5, drIndex)
+	})
+}
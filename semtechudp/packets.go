@@ -0,0 +1,442 @@
+// Package semtechudp implements the structures of the Semtech UDP
+// packet-forwarder protocol (as documented in the PROTOCOL.TXT of the
+// packet-forwarder repository).
+package semtechudp
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/brocaar/lorawan"
+)
+
+// Protocol versions.
+const (
+	ProtocolVersion1 uint8 = 0x01
+	ProtocolVersion2 uint8 = 0x02
+)
+
+// PacketType defines the packet type (identifier).
+type PacketType byte
+
+// Packet types.
+const (
+	PushData PacketType = 0x00
+	PushACK  PacketType = 0x01
+	PullData PacketType = 0x02
+	PullResp PacketType = 0x03
+	PullACK  PacketType = 0x04
+	TXACK    PacketType = 0x05
+)
+
+// String implements fmt.Stringer.
+func (t PacketType) String() string {
+	switch t {
+	case PushData:
+		return "PUSH_DATA"
+	case PushACK:
+		return "PUSH_ACK"
+	case PullData:
+		return "PULL_DATA"
+	case PullResp:
+		return "PULL_RESP"
+	case PullACK:
+		return "PULL_ACK"
+	case TXACK:
+		return "TX_ACK"
+	default:
+		return fmt.Sprintf("PacketType(%d)", byte(t))
+	}
+}
+
+// GetPacketType returns the packet type of the given packet.
+func GetPacketType(data []byte) (PacketType, error) {
+	if len(data) < 4 {
+		return 0, errors.New("lorawan/semtechudp: at least 4 bytes of data are expected")
+	}
+	if data[0] != ProtocolVersion1 && data[0] != ProtocolVersion2 {
+		return 0, fmt.Errorf("lorawan/semtechudp: unsupported protocol version %d", data[0])
+	}
+	return PacketType(data[3]), nil
+}
+
+// Modulations.
+const (
+	ModulationLoRa = "LORA"
+	ModulationFSK  = "FSK"
+)
+
+// DatR implements the data-rate, which can be either a string (LoRa
+// identifier, e.g. SF7BW125) or an unsigned integer (FSK data-rate in bits
+// per second).
+type DatR struct {
+	LoRa string
+	FSK  uint32
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d DatR) MarshalJSON() ([]byte, error) {
+	if d.LoRa != "" {
+		return []byte(`"` + d.LoRa + `"`), nil
+	}
+	return []byte(strconv.FormatUint(uint64(d.FSK), 10)), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *DatR) UnmarshalJSON(data []byte) error {
+	i, err := strconv.ParseUint(string(data), 10, 32)
+	if err != nil {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("lorawan/semtechudp: invalid datr: %s", data)
+		}
+		d.LoRa = s
+		return nil
+	}
+	d.FSK = uint32(i)
+	return nil
+}
+
+// NewLoRaDatR returns the LoRa DatR for the given spreading-factor and
+// bandwidth (kHz), e.g. SF7BW125.
+func NewLoRaDatR(sf, bw int) DatR {
+	return DatR{LoRa: fmt.Sprintf("SF%dBW%d", sf, bw)}
+}
+
+// LoRaSFBW parses the LoRa data-rate identifier (e.g. SF7BW125) into the
+// spreading-factor and bandwidth (kHz).
+func (d DatR) LoRaSFBW() (sf, bw int, err error) {
+	if d.LoRa == "" {
+		return 0, 0, ErrDataRate
+	}
+
+	if n, err := fmt.Sscanf(d.LoRa, "SF%dBW%d", &sf, &bw); err != nil || n != 2 || fmt.Sprintf("SF%dBW%d", sf, bw) != d.LoRa {
+		return 0, 0, fmt.Errorf("lorawan/semtechudp: invalid datr: %s", d.LoRa)
+	}
+	return sf, bw, nil
+}
+
+// marshalHeader returns the packet header (protocol version, random token
+// and identifier).
+func marshalHeader(protocolVersion uint8, randomToken uint16, t PacketType) []byte {
+	out := make([]byte, 4, 12)
+	out[0] = protocolVersion
+	binary.LittleEndian.PutUint16(out[1:3], randomToken)
+	out[3] = byte(t)
+	return out
+}
+
+// unmarshalHeader validates the packet length and header and returns the
+// protocol version and random token.
+func unmarshalHeader(data []byte, t PacketType, minLength int) (uint8, uint16, error) {
+	if len(data) < minLength {
+		return 0, 0, fmt.Errorf("lorawan/semtechudp: at least %d bytes of data are expected", minLength)
+	}
+	if data[3] != byte(t) {
+		return 0, 0, fmt.Errorf("lorawan/semtechudp: identifier mismatch (%s expected)", t)
+	}
+	if data[0] != ProtocolVersion1 && data[0] != ProtocolVersion2 {
+		return 0, 0, fmt.Errorf("lorawan/semtechudp: unsupported protocol version %d", data[0])
+	}
+	return data[0], binary.LittleEndian.Uint16(data[1:3]), nil
+}
+
+// unmarshalJSONPayload decodes the JSON payload into v. Trailing NUL bytes,
+// as added by some packet-forwarders, are ignored.
+func unmarshalJSONPayload(data []byte, v interface{}) error {
+	for len(data) != 0 && data[len(data)-1] == 0 {
+		data = data[:len(data)-1]
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("lorawan/semtechudp: unmarshal payload error: %w", err)
+	}
+	return nil
+}
+
+// PushDataPacket is used by the gateway mainly to forward the RF packets
+// received, and associated metadata, to the server.
+type PushDataPacket struct {
+	ProtocolVersion uint8
+	RandomToken     uint16
+	GatewayMAC      lorawan.GatewayEUI
+	Payload         PushDataPayload
+}
+
+// MarshalBinary marshals the object in binary form.
+func (p PushDataPacket) MarshalBinary() ([]byte, error) {
+	pb, err := json.Marshal(p.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	out := marshalHeader(p.ProtocolVersion, p.RandomToken, PushData)
+	out = append(out, p.GatewayMAC[:]...)
+	return append(out, pb...), nil
+}
+
+// UnmarshalBinary decodes the object from binary form.
+func (p *PushDataPacket) UnmarshalBinary(data []byte) error {
+	var err error
+	if p.ProtocolVersion, p.RandomToken, err = unmarshalHeader(data, PushData, 13); err != nil {
+		return err
+	}
+	copy(p.GatewayMAC[:], data[4:12])
+	p.Payload = PushDataPayload{}
+	return unmarshalJSONPayload(data[12:], &p.Payload)
+}
+
+// PushACKPacket is used by the server to acknowledge immediately all the
+// PUSH_DATA packets received.
+type PushACKPacket struct {
+	ProtocolVersion uint8
+	RandomToken     uint16
+}
+
+// MarshalBinary marshals the object in binary form.
+func (p PushACKPacket) MarshalBinary() ([]byte, error) {
+	return marshalHeader(p.ProtocolVersion, p.RandomToken, PushACK), nil
+}
+
+// UnmarshalBinary decodes the object from binary form.
+func (p *PushACKPacket) UnmarshalBinary(data []byte) error {
+	var err error
+	p.ProtocolVersion, p.RandomToken, err = unmarshalHeader(data, PushACK, 4)
+	return err
+}
+
+// PullDataPacket is used by the gateway to poll data from the server. It
+// also opens the downlink path (e.g. through NAT) for the PULL_RESP packets.
+type PullDataPacket struct {
+	ProtocolVersion uint8
+	RandomToken     uint16
+	GatewayMAC      lorawan.GatewayEUI
+}
+
+// MarshalBinary marshals the object in binary form.
+func (p PullDataPacket) MarshalBinary() ([]byte, error) {
+	out := marshalHeader(p.ProtocolVersion, p.RandomToken, PullData)
+	return append(out, p.GatewayMAC[:]...), nil
+}
+
+// UnmarshalBinary decodes the object from binary form.
+func (p *PullDataPacket) UnmarshalBinary(data []byte) error {
+	var err error
+	if p.ProtocolVersion, p.RandomToken, err = unmarshalHeader(data, PullData, 12); err != nil {
+		return err
+	}
+	copy(p.GatewayMAC[:], data[4:12])
+	return nil
+}
+
+// PullACKPacket is used by the server to confirm that the network route is
+// open and that the server can send PULL_RESP packets at any time.
+type PullACKPacket struct {
+	ProtocolVersion uint8
+	RandomToken     uint16
+}
+
+// MarshalBinary marshals the object in binary form.
+func (p PullACKPacket) MarshalBinary() ([]byte, error) {
+	return marshalHeader(p.ProtocolVersion, p.RandomToken, PullACK), nil
+}
+
+// UnmarshalBinary decodes the object from binary form.
+func (p *PullACKPacket) UnmarshalBinary(data []byte) error {
+	var err error
+	p.ProtocolVersion, p.RandomToken, err = unmarshalHeader(data, PullACK, 4)
+	return err
+}
+
+// PullRespPacket is used by the server to send RF packets and associated
+// metadata that will have to be emitted by the gateway. Note that the random
+// token is only used by protocol version 2, the gateway returns it in the
+// TX_ACK packet.
+type PullRespPacket struct {
+	ProtocolVersion uint8
+	RandomToken     uint16
+	Payload         PullRespPayload
+}
+
+// MarshalBinary marshals the object in binary form.
+func (p PullRespPacket) MarshalBinary() ([]byte, error) {
+	pb, err := json.Marshal(p.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	out := marshalHeader(p.ProtocolVersion, p.RandomToken, PullResp)
+	return append(out, pb...), nil
+}
+
+// UnmarshalBinary decodes the object from binary form.
+func (p *PullRespPacket) UnmarshalBinary(data []byte) error {
+	var err error
+	if p.ProtocolVersion, p.RandomToken, err = unmarshalHeader(data, PullResp, 5); err != nil {
+		return err
+	}
+	p.Payload = PullRespPayload{}
+	return unmarshalJSONPayload(data[4:], &p.Payload)
+}
+
+// TXPK contains a RF packet to be emitted and associated metadata.
+type TXPK struct {
+	Imme bool    `json:"imme"`           // Send packet immediately (will ignore tmst & time)
+	Tmst *uint32 `json:"tmst,omitempty"` // Send packet on a certain timestamp value (will ignore time)
+	Tmms *uint64 `json:"tmms,omitempty"` // Send packet at a certain GPS time (GPS synchronization required)
+	Freq float64 `json:"freq"`           // TX central frequency in MHz (unsigned float, Hz precision)
+	RFCh uint8   `json:"rfch"`           // Concentrator "RF chain" used for TX (unsigned integer)
+	Powe uint8   `json:"powe"`           // TX output power in dBm (unsigned integer, dBm precision)
+	Modu string  `json:"modu"`           // Modulation identifier "LORA" or "FSK"
+	DatR DatR    `json:"datr"`           // LoRa datarate identifier (eg. SF12BW500) || FSK Datarate (unsigned, in bits per second)
+	CodR string  `json:"codr,omitempty"` // LoRa ECC coding rate identifier
+	FDev uint16  `json:"fdev,omitempty"` // FSK frequency deviation (unsigned integer, in Hz)
+	IPol bool    `json:"ipol"`           // Lora modulation polarization inversion
+	Prea uint16  `json:"prea,omitempty"` // RF preamble size (unsigned integer)
+	Size uint16  `json:"size"`           // RF packet payload size in bytes (unsigned integer)
+	Data []byte  `json:"data"`           // Base64 encoded RF packet payload, padding optional
+	NCRC bool    `json:"ncrc,omitempty"` // If true, disable the CRC of the physical layer (optional)
+}
+
+// PullRespPayload defines the payload of the PULL_RESP packet.
+type PullRespPayload struct {
+	TXPK TXPK `json:"txpk"`
+}
+
+// LoRa coding-rates.
+var loRaCodingRates = map[string]struct{}{
+	"4/5": {},
+	"4/6": {},
+	"4/7": {},
+	"4/8": {},
+}
+
+// ExpandedTime implements the time format used by the STAT structure
+// (e.g. 2014-01-12 08:59:28 GMT).
+type ExpandedTime time.Time
+
+// expandedTimeLayout defines the layout of the ExpandedTime.
+const expandedTimeLayout = "2006-01-02 15:04:05 MST"
+
+// MarshalJSON implements json.Marshaler.
+func (t ExpandedTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).UTC().Format(expandedTimeLayout))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *ExpandedTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	ts, err := time.Parse(expandedTimeLayout, s)
+	if err != nil {
+		return fmt.Errorf("lorawan/semtechudp: invalid time: %w", err)
+	}
+	*t = ExpandedTime(ts)
+	return nil
+}
+
+// RSig contains the signal information per antenna (as sent by gateways
+// with multiple antennas, e.g. SX1302 / SX1303 based gateways).
+type RSig struct {
+	Ant    uint8   `json:"ant"`              // Antenna number on which signal has been received
+	Chan   uint8   `json:"chan"`             // Concentrator "IF" channel used for RX (unsigned integer)
+	RSSIC  int16   `json:"rssic"`            // RSSI in dBm of the channel (signed integer, 1 dB precision)
+	RSSIS  *int16  `json:"rssis,omitempty"`  // RSSI in dBm of the signal (signed integer, 1 dB precision) (Optional)
+	LSNR   float64 `json:"lsnr"`             // Lora SNR ratio in dB (signed float, 0.1 dB precision)
+	ETime  []byte  `json:"etime,omitempty"`  // Encrypted 'main' fine timestamp, ns precision [0..999999999] (Optional)
+	FTime  *uint32 `json:"ftime,omitempty"`  // Fine timestamp, number of nanoseconds since last PPS [0..999999999] (Optional)
+	FOff   *int32  `json:"foff,omitempty"`   // Frequency offset in Hz [-125 kHz..+125 kHz] (Optional)
+	FTStat *uint8  `json:"ftstat,omitempty"` // Fine timestamp status (Optional)
+}
+
+// RXPK contains a RF packet and associated metadata.
+type RXPK struct {
+	Time  *time.Time `json:"time,omitempty"`  // UTC time of pkt RX, us precision, ISO 8601 'compact' format (e.g. 2013-03-31T16:21:17.528002Z)
+	Tmms  *uint64    `json:"tmms,omitempty"`  // GPS time of pkt RX, number of milliseconds since 06.Jan.1980
+	Tmst  uint32     `json:"tmst"`            // Internal timestamp of "RX finished" event (32b unsigned)
+	FTime *uint32    `json:"ftime,omitempty"` // Fine timestamp, number of nanoseconds since last PPS [0..999999999] (Optional)
+	Freq  float64    `json:"freq"`            // RX central frequency in MHz (unsigned float, Hz precision)
+	Chan  uint8      `json:"chan"`            // Concentrator "IF" channel used for RX (unsigned integer)
+	RFCh  uint8      `json:"rfch"`            // Concentrator "RF chain" used for RX (unsigned integer)
+	Stat  int8       `json:"stat"`            // CRC status: 1 = OK, -1 = fail, 0 = no CRC
+	Modu  string     `json:"modu"`            // Modulation identifier "LORA" or "FSK"
+	DatR  DatR       `json:"datr"`            // LoRa datarate identifier (eg. SF12BW500) || FSK datarate (unsigned, in bits per second)
+	CodR  string     `json:"codr,omitempty"`  // LoRa ECC coding rate identifier
+	RSSI  int16      `json:"rssi"`            // RSSI in dBm (signed integer, 1 dB precision)
+	LSNR  float64    `json:"lsnr"`            // Lora SNR ratio in dB (signed float, 0.1 dB precision)
+	FOff  *int32     `json:"foff,omitempty"`  // Frequency offset in Hz (Optional)
+	Size  uint16     `json:"size"`            // RF packet payload size in bytes (unsigned integer)
+	Data  []byte     `json:"data"`            // Base64 encoded RF packet payload, padded
+	RSig  []RSig     `json:"rsig,omitempty"`  // Signal information per antenna (Optional)
+}
+
+// Validate validates the internal consistency of the RXPK.
+func (r RXPK) Validate() error {
+	if int(r.Size) != len(r.Data) {
+		return fmt.Errorf("lorawan/semtechudp: size %d does not match data length %d", r.Size, len(r.Data))
+	}
+
+	if r.Stat < -1 || r.Stat > 1 {
+		return fmt.Errorf("lorawan/semtechudp: invalid stat %d", r.Stat)
+	}
+
+	return validateModulation(r.Modu, r.DatR, r.CodR)
+}
+
+// Stat contains the status of the gateway.
+type Stat struct {
+	Time ExpandedTime `json:"time"`           // UTC 'system' time of the gateway, ISO 8601 'expanded' format
+	Lati *float64     `json:"lati,omitempty"` // GPS latitude of the gateway in degree (float, N is +)
+	Long *float64     `json:"long,omitempty"` // GPS latitude of the gateway in degree (float, E is +)
+	Alti *int32       `json:"alti,omitempty"` // GPS altitude of the gateway in meter RX (integer)
+	RXNb uint32       `json:"rxnb"`           // Number of radio packets received (unsigned integer)
+	RXOK uint32       `json:"rxok"`           // Number of radio packets received with a valid PHY CRC
+	RXFW uint32       `json:"rxfw"`           // Number of radio packets forwarded (unsigned integer)
+	ACKR float64      `json:"ackr"`           // Percentage of upstream datagrams that were acknowledged
+	DWNb uint32       `json:"dwnb"`           // Number of downlink datagrams received (unsigned integer)
+	TXNb uint32       `json:"txnb"`           // Number of packets emitted (unsigned integer)
+	Temp *float64     `json:"temp,omitempty"` // Concentrator temperature in degree Celsius (Optional)
+}
+
+// PushDataPayload defines the payload of the PUSH_DATA packet.
+type PushDataPayload struct {
+	RXPK []RXPK `json:"rxpk,omitempty"`
+	Stat *Stat  `json:"stat,omitempty"`
+}
+
+// Validate validates the RXPK items of the payload.
+func (p PushDataPayload) Validate() error {
+	for i, rxpk := range p.RXPK {
+		if err := rxpk.Validate(); err != nil {
+			return fmt.Errorf("rxpk %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateModulation validates the given modulation, data-rate and
+// coding-rate.
+func validateModulation(modu string, datr DatR, codr string) error {
+	switch modu {
+	case ModulationLoRa:
+		if _, _, err := datr.LoRaSFBW(); err != nil {
+			return err
+		}
+		if _, ok := loRaCodingRates[codr]; !ok {
+			return fmt.Errorf("lorawan/semtechudp: invalid codr: %s", codr)
+		}
+	case ModulationFSK:
+		if datr.LoRa != "" || datr.FSK == 0 {
+			return ErrDataRate
+		}
+	default:
+		return ErrModulation
+	}
+	return nil
+}
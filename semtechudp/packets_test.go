@@ -0,0 +1,239 @@
+package semtechudp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestPushDataPayload(t *testing.T) {
+	assert := require.New(t)
+
+	// based on the example of the packet-forwarder PROTOCOL.TXT
+	b := []byte(`{
+		"rxpk":[{
+			"time":"2013-03-31T16:21:17.528002Z",
+			"tmst":3512348611,
+			"chan":2,
+			"rfch":0,
+			"freq":866.349812,
+			"stat":1,
+			"modu":"LORA",
+			"datr":"SF7BW125",
+			"codr":"4/6",
+			"rssi":-35,
+			"lsnr":5.1,
+			"size":32,
+			"data":"AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=",
+			"rsig":[{"ant":0,"chan":2,"rssic":-35,"lsnr":5.1,"etime":"AQID","foff":-20}]
+		}],
+		"stat":{
+			"time":"2014-01-12 08:59:28 GMT",
+			"lati":46.24000,
+			"long":3.25230,
+			"alti":145,
+			"rxnb":2,
+			"rxok":2,
+			"rxfw":2,
+			"ackr":100.0,
+			"dwnb":2,
+			"txnb":2
+		}
+	}`)
+
+	var pl PushDataPayload
+	assert.NoError(json.Unmarshal(b, &pl))
+
+	assert.Len(pl.RXPK, 1)
+	rxpk := pl.RXPK[0]
+	assert.Equal(time.Date(2013, 3, 31, 16, 21, 17, 528002000, time.UTC), *rxpk.Time)
+	assert.Equal(uint32(3512348611), rxpk.Tmst)
+	assert.Equal(DatR{LoRa: "SF7BW125"}, rxpk.DatR)
+	assert.Equal(int16(-35), rxpk.RSSI)
+	assert.Equal([]byte{1, 2, 3}, rxpk.RSig[0].ETime)
+	assert.Equal(int32(-20), *rxpk.RSig[0].FOff)
+	assert.NoError(pl.Validate())
+
+	sf, bw, err := rxpk.DatR.LoRaSFBW()
+	assert.NoError(err)
+	assert.Equal(7, sf)
+	assert.Equal(125, bw)
+
+	assert.Equal(time.Date(2014, 1, 12, 8, 59, 28, 0, time.UTC), time.Time(pl.Stat.Time).UTC())
+	assert.Equal(int32(145), *pl.Stat.Alti)
+	assert.Equal(100.0, pl.Stat.ACKR)
+
+	out, err := json.Marshal(pl.Stat)
+	assert.NoError(err)
+	assert.Contains(string(out), `"time":"2014-01-12 08:59:28 UTC"`)
+}
+
+func TestRXPKValidate(t *testing.T) {
+	valid := RXPK{
+		Stat: 1,
+		Modu: ModulationLoRa,
+		DatR: DatR{LoRa: "SF7BW125"},
+		CodR: "4/5",
+		Size: 2,
+		Data: []byte{1, 2},
+	}
+
+	tests := []struct {
+		Name          string
+		RXPK          func(RXPK) RXPK
+		ExpectedError error
+	}{
+		{
+			Name: "valid",
+			RXPK: func(r RXPK) RXPK { return r },
+		},
+		{
+			Name:          "size mismatch",
+			RXPK:          func(r RXPK) RXPK { r.Size = 3; return r },
+			ExpectedError: errors.New("lorawan/semtechudp: size 3 does not match data length 2"),
+		},
+		{
+			Name:          "invalid codr",
+			RXPK:          func(r RXPK) RXPK { r.CodR = "4/9"; return r },
+			ExpectedError: errors.New("lorawan/semtechudp: invalid codr: 4/9"),
+		},
+		{
+			Name:          "invalid stat",
+			RXPK:          func(r RXPK) RXPK { r.Stat = 2; return r },
+			ExpectedError: errors.New("lorawan/semtechudp: invalid stat 2"),
+		},
+		{
+			Name:          "FSK without bit-rate",
+			RXPK:          func(r RXPK) RXPK { r.Modu = ModulationFSK; r.DatR = DatR{}; return r },
+			ExpectedError: ErrDataRate,
+		},
+		{
+			Name: "FSK",
+			RXPK: func(r RXPK) RXPK { r.Modu = ModulationFSK; r.DatR = DatR{FSK: 50000}; r.CodR = ""; return r },
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+			assert.Equal(tst.ExpectedError, tst.RXPK(valid).Validate())
+		})
+	}
+}
+
+func TestPackets(t *testing.T) {
+	gatewayMAC := lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8}
+
+	tests := []struct {
+		Name   string
+		Type   PacketType
+		Packet interface {
+			MarshalBinary() ([]byte, error)
+		}
+		Bytes  []byte
+		Decode func([]byte) (interface{}, error)
+	}{
+		{
+			Name: "PUSH_DATA",
+			Type: PushData,
+			Packet: PushDataPacket{
+				ProtocolVersion: ProtocolVersion2,
+				RandomToken:     0x0201,
+				GatewayMAC:      gatewayMAC,
+				Payload: PushDataPayload{
+					RXPK: []RXPK{{Tmst: 1234, Freq: 868.1, Modu: "LORA", DatR: NewLoRaDatR(7, 125), CodR: "4/5", Size: 1, Data: []byte{1}}},
+				},
+			},
+			Bytes: append([]byte{0x02, 0x01, 0x02, 0x00, 1, 2, 3, 4, 5, 6, 7, 8}, []byte(`{"rxpk":[{"tmst":1234,"freq":868.1,"chan":0,"rfch":0,"stat":0,"modu":"LORA","datr":"SF7BW125","codr":"4/5","rssi":0,"lsnr":0,"size":1,"data":"AQ=="}]}`)...),
+			Decode: func(b []byte) (interface{}, error) {
+				var pkt PushDataPacket
+				err := pkt.UnmarshalBinary(b)
+				return pkt, err
+			},
+		},
+		{
+			Name:   "PUSH_ACK",
+			Type:   PushACK,
+			Packet: PushACKPacket{ProtocolVersion: ProtocolVersion2, RandomToken: 0x0201},
+			Bytes:  []byte{0x02, 0x01, 0x02, 0x01},
+			Decode: func(b []byte) (interface{}, error) {
+				var pkt PushACKPacket
+				err := pkt.UnmarshalBinary(b)
+				return pkt, err
+			},
+		},
+		{
+			Name:   "PULL_DATA",
+			Type:   PullData,
+			Packet: PullDataPacket{ProtocolVersion: ProtocolVersion1, RandomToken: 0x0201, GatewayMAC: gatewayMAC},
+			Bytes:  []byte{0x01, 0x01, 0x02, 0x02, 1, 2, 3, 4, 5, 6, 7, 8},
+			Decode: func(b []byte) (interface{}, error) {
+				var pkt PullDataPacket
+				err := pkt.UnmarshalBinary(b)
+				return pkt, err
+			},
+		},
+		{
+			Name:   "PULL_ACK",
+			Type:   PullACK,
+			Packet: PullACKPacket{ProtocolVersion: ProtocolVersion1, RandomToken: 0x0201},
+			Bytes:  []byte{0x01, 0x01, 0x02, 0x04},
+			Decode: func(b []byte) (interface{}, error) {
+				var pkt PullACKPacket
+				err := pkt.UnmarshalBinary(b)
+				return pkt, err
+			},
+		},
+		{
+			Name: "PULL_RESP",
+			Type: PullResp,
+			Packet: PullRespPacket{
+				ProtocolVersion: ProtocolVersion2,
+				RandomToken:     0x0201,
+				Payload: PullRespPayload{
+					TXPK: TXPK{Imme: true, Freq: 869.525, Powe: 14, Modu: "LORA", DatR: NewLoRaDatR(9, 125), CodR: "4/5", IPol: true, Size: 1, Data: []byte{1}},
+				},
+			},
+			Bytes: append([]byte{0x02, 0x01, 0x02, 0x03}, []byte(`{"txpk":{"imme":true,"freq":869.525,"rfch":0,"powe":14,"modu":"LORA","datr":"SF9BW125","codr":"4/5","ipol":true,"size":1,"data":"AQ=="}}`)...),
+			Decode: func(b []byte) (interface{}, error) {
+				var pkt PullRespPacket
+				err := pkt.UnmarshalBinary(b)
+				return pkt, err
+			},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := tst.Packet.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(tst.Bytes, b)
+
+			pt, err := GetPacketType(b)
+			assert.NoError(err)
+			assert.Equal(tst.Type, pt)
+
+			pkt, err := tst.Decode(b)
+			assert.NoError(err)
+			assert.Equal(tst.Packet, pkt)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		assert := require.New(t)
+
+		var pushData PushDataPacket
+		assert.EqualError(pushData.UnmarshalBinary([]byte{0x02, 0x01, 0x02, 0x00}), "lorawan/semtechudp: at least 13 bytes of data are expected")
+
+		var pullData PullDataPacket
+		assert.EqualError(pullData.UnmarshalBinary([]byte{0x02, 0x01, 0x02, 0x00, 1, 2, 3, 4, 5, 6, 7, 8}), "lorawan/semtechudp: identifier mismatch (PULL_DATA expected)")
+		assert.EqualError(pullData.UnmarshalBinary([]byte{0x03, 0x01, 0x02, 0x02, 1, 2, 3, 4, 5, 6, 7, 8}), "lorawan/semtechudp: unsupported protocol version 3")
+	})
+}
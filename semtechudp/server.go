@@ -0,0 +1,309 @@
+package semtechudp
+
+import (
+	"crypto/rand"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/clock"
+)
+
+// maxPacketSize defines the max. UDP packet size.
+const maxPacketSize = 65507
+
+// ErrGatewayNotConnected is returned when sending a downlink to a gateway
+// from which no PULL_DATA has been received.
+var ErrGatewayNotConnected = errors.New("lorawan/semtechudp: gateway is not connected")
+
+// ServerConfig holds the Server configuration. Note that the callbacks are
+// called from the read loop of the server and therefore should not block.
+type ServerConfig struct {
+	// Bind holds the UDP address to listen on, e.g. 0.0.0.0:1700.
+	Bind string
+
+	// OnUplink is called for every RXPK received from a gateway.
+	OnUplink func(gatewayMAC lorawan.GatewayEUI, rxpk RXPK)
+
+	// OnStats is called for every STAT received from a gateway.
+	OnStats func(gatewayMAC lorawan.GatewayEUI, stat Stat)
+
+	// OnTXAck is called for every TX_ACK received from a gateway. The error
+	// is nil when the downlink was accepted by the gateway, else it is a
+	// *TXAckError.
+	OnTXAck func(gatewayMAC lorawan.GatewayEUI, token uint16, err error)
+
+	// OnError is called for packets that could not be handled, e.g. because
+	// these could not be decoded.
+	OnError func(addr *net.UDPAddr, err error)
+
+	// Clock holds the clock used for the gateway last-seen timestamps. When
+	// not set, clock.Real is used.
+	Clock clock.Clock
+}
+
+// Gateway holds the state of a gateway.
+type Gateway struct {
+	// MAC holds the gateway MAC (EUI).
+	MAC lorawan.GatewayEUI
+
+	// ProtocolVersion holds the protocol version used by the gateway.
+	ProtocolVersion uint8
+
+	// PullAddr holds the address from which the last PULL_DATA was received.
+	// This address is used for sending downlinks. It is nil when no
+	// PULL_DATA has been received yet.
+	PullAddr *net.UDPAddr
+
+	// LastSeen holds the timestamp of the last packet received from the
+	// gateway.
+	LastSeen time.Time
+}
+
+// Server implements a Semtech UDP packet-forwarder server. It acknowledges
+// the PUSH_DATA and PULL_DATA packets, tracks the state of the gateways and
+// forwards the received uplinks, stats and TX_ACKs to the callbacks of the
+// ServerConfig. It is safe for concurrent use.
+type Server struct {
+	config ServerConfig
+	conn   *net.UDPConn
+	wg     sync.WaitGroup
+
+	mu       sync.RWMutex
+	gateways map[lorawan.GatewayEUI]Gateway
+}
+
+// NewServer creates a new Server, listening on the configured address. Close
+// must be called to stop the server.
+func NewServer(config ServerConfig) (*Server, error) {
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", config.Bind)
+	if err != nil {
+		return nil, fmt.Errorf("lorawan/semtechudp: resolve udp addr error: %w", err)
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("lorawan/semtechudp: listen udp error: %w", err)
+	}
+
+	s := Server{
+		config:   config,
+		conn:     conn,
+		gateways: make(map[lorawan.GatewayEUI]Gateway),
+	}
+
+	s.wg.Add(1)
+	go s.readLoop()
+
+	return &s, nil
+}
+
+// Addr returns the local address of the server.
+func (s *Server) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	err := s.conn.Close()
+	s.wg.Wait()
+	return err
+}
+
+// GetGateway returns the state of the given gateway.
+func (s *Server) GetGateway(gatewayMAC lorawan.GatewayEUI) (Gateway, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	gw, ok := s.gateways[gatewayMAC]
+	return gw, ok
+}
+
+// GetGateways returns the state of all the gateways, sorted by MAC.
+func (s *Server) GetGateways() []Gateway {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Gateway, 0, len(s.gateways))
+	for _, gw := range s.gateways {
+		out = append(out, gw)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].MAC.String() < out[j].MAC.String()
+	})
+
+	return out
+}
+
+// SendDownlink sends the given TXPK to the gateway (PULL_RESP packet). It
+// returns the random token of the packet, which is returned by the gateway
+// in the TX_ACK packet (see ServerConfig.OnTXAck).
+func (s *Server) SendDownlink(gatewayMAC lorawan.GatewayEUI, txpk TXPK) (uint16, error) {
+	gw, ok := s.GetGateway(gatewayMAC)
+	if !ok || gw.PullAddr == nil {
+		return 0, ErrGatewayNotConnected
+	}
+
+	b := make([]byte, 2)
+	if _, err := rand.Read(b); err != nil {
+		return 0, fmt.Errorf("lorawan/semtechudp: read random bytes error: %w", err)
+	}
+	token := binary.LittleEndian.Uint16(b)
+
+	pkt := PullRespPacket{
+		ProtocolVersion: gw.ProtocolVersion,
+		RandomToken:     token,
+		Payload: PullRespPayload{
+			TXPK: txpk,
+		},
+	}
+
+	if err := s.send(gw.PullAddr, pkt); err != nil {
+		return 0, err
+	}
+
+	return token, nil
+}
+
+func (s *Server) readLoop() {
+	defer s.wg.Done()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.handleError(addr, fmt.Errorf("lorawan/semtechudp: read udp error: %w", err))
+			continue
+		}
+
+		data := make([]byte, n)
+		copy(data, buf[:n])
+
+		if err := s.handlePacket(addr, data); err != nil {
+			s.handleError(addr, err)
+		}
+	}
+}
+
+func (s *Server) handlePacket(addr *net.UDPAddr, data []byte) error {
+	t, err := GetPacketType(data)
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case PushData:
+		return s.handlePushData(addr, data)
+	case PullData:
+		return s.handlePullData(addr, data)
+	case TXACK:
+		return s.handleTXACK(addr, data)
+	default:
+		return fmt.Errorf("lorawan/semtechudp: unexpected packet type %s", t)
+	}
+}
+
+func (s *Server) handlePushData(addr *net.UDPAddr, data []byte) error {
+	var pkt PushDataPacket
+	if err := pkt.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	s.setGatewaySeen(pkt.GatewayMAC, pkt.ProtocolVersion, nil)
+
+	if err := s.send(addr, PushACKPacket{
+		ProtocolVersion: pkt.ProtocolVersion,
+		RandomToken:     pkt.RandomToken,
+	}); err != nil {
+		return err
+	}
+
+	if s.config.OnUplink != nil {
+		for _, rxpk := range pkt.Payload.RXPK {
+			s.config.OnUplink(pkt.GatewayMAC, rxpk)
+		}
+	}
+
+	if s.config.OnStats != nil && pkt.Payload.Stat != nil {
+		s.config.OnStats(pkt.GatewayMAC, *pkt.Payload.Stat)
+	}
+
+	return nil
+}
+
+func (s *Server) handlePullData(addr *net.UDPAddr, data []byte) error {
+	var pkt PullDataPacket
+	if err := pkt.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	s.setGatewaySeen(pkt.GatewayMAC, pkt.ProtocolVersion, addr)
+
+	return s.send(addr, PullACKPacket{
+		ProtocolVersion: pkt.ProtocolVersion,
+		RandomToken:     pkt.RandomToken,
+	})
+}
+
+func (s *Server) handleTXACK(addr *net.UDPAddr, data []byte) error {
+	var pkt TXACKPacket
+	if err := pkt.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	s.setGatewaySeen(pkt.GatewayMAC, pkt.ProtocolVersion, nil)
+
+	if s.config.OnTXAck != nil {
+		s.config.OnTXAck(pkt.GatewayMAC, pkt.RandomToken, pkt.Err())
+	}
+
+	return nil
+}
+
+// setGatewaySeen updates the last-seen timestamp and protocol version of
+// the gateway. The pull address is only updated when it is not nil.
+func (s *Server) setGatewaySeen(gatewayMAC lorawan.GatewayEUI, protocolVersion uint8, pullAddr *net.UDPAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gw := s.gateways[gatewayMAC]
+	gw.MAC = gatewayMAC
+	gw.ProtocolVersion = protocolVersion
+	gw.LastSeen = s.config.Clock.Now()
+	if pullAddr != nil {
+		gw.PullAddr = pullAddr
+	}
+	s.gateways[gatewayMAC] = gw
+}
+
+func (s *Server) send(addr *net.UDPAddr, pkt encoding.BinaryMarshaler) error {
+	b, err := pkt.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.conn.WriteToUDP(b, addr); err != nil {
+		return fmt.Errorf("lorawan/semtechudp: write udp error: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) handleError(addr *net.UDPAddr, err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(addr, err)
+	}
+}
@@ -0,0 +1,148 @@
+package semtechudp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+	"github.com/brocaar/lorawan/clock"
+)
+
+type txAck struct {
+	GatewayMAC lorawan.GatewayEUI
+	Token      uint16
+	Err        error
+}
+
+func TestServer(t *testing.T) {
+	assert := require.New(t)
+
+	gatewayMAC := lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	uplinks := make(chan RXPK, 1)
+	stats := make(chan Stat, 1)
+	txAcks := make(chan txAck, 1)
+
+	s, err := NewServer(ServerConfig{
+		Bind: "127.0.0.1:0",
+		OnUplink: func(mac lorawan.GatewayEUI, rxpk RXPK) {
+			uplinks <- rxpk
+		},
+		OnStats: func(mac lorawan.GatewayEUI, stat Stat) {
+			stats <- stat
+		},
+		OnTXAck: func(mac lorawan.GatewayEUI, token uint16, err error) {
+			txAcks <- txAck{mac, token, err}
+		},
+		Clock: clock.NewVirtual(now),
+	})
+	assert.NoError(err)
+	defer s.Close()
+
+	conn, err := net.DialUDP("udp", nil, s.Addr().(*net.UDPAddr))
+	assert.NoError(err)
+	defer conn.Close()
+	assert.NoError(conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	send := func(pkt interface{ MarshalBinary() ([]byte, error) }) {
+		b, err := pkt.MarshalBinary()
+		assert.NoError(err)
+		_, err = conn.Write(b)
+		assert.NoError(err)
+	}
+
+	receive := func() []byte {
+		buf := make([]byte, maxPacketSize)
+		n, err := conn.Read(buf)
+		assert.NoError(err)
+		return buf[:n]
+	}
+
+	t.Run("SendDownlink unknown gateway", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := s.SendDownlink(gatewayMAC, TXPK{})
+		assert.Equal(ErrGatewayNotConnected, err)
+	})
+
+	t.Run("PUSH_DATA", func(t *testing.T) {
+		assert := require.New(t)
+
+		rxpk := RXPK{Tmst: 1234, Freq: 868.1, Modu: "LORA", DatR: NewLoRaDatR(7, 125), CodR: "4/5", Size: 1, Data: []byte{1}}
+		send(PushDataPacket{
+			ProtocolVersion: ProtocolVersion2,
+			RandomToken:     123,
+			GatewayMAC:      gatewayMAC,
+			Payload: PushDataPayload{
+				RXPK: []RXPK{rxpk},
+				Stat: &Stat{RXNb: 1},
+			},
+		})
+
+		var ack PushACKPacket
+		assert.NoError(ack.UnmarshalBinary(receive()))
+		assert.Equal(PushACKPacket{ProtocolVersion: ProtocolVersion2, RandomToken: 123}, ack)
+		assert.Equal(rxpk, <-uplinks)
+		assert.Equal(uint32(1), (<-stats).RXNb)
+
+		gw, ok := s.GetGateway(gatewayMAC)
+		assert.True(ok)
+		assert.Equal(now, gw.LastSeen)
+		assert.Nil(gw.PullAddr)
+
+		_, err := s.SendDownlink(gatewayMAC, TXPK{})
+		assert.Equal(ErrGatewayNotConnected, err)
+	})
+
+	t.Run("PULL_DATA", func(t *testing.T) {
+		assert := require.New(t)
+
+		send(PullDataPacket{
+			ProtocolVersion: ProtocolVersion2,
+			RandomToken:     124,
+			GatewayMAC:      gatewayMAC,
+		})
+
+		var ack PullACKPacket
+		assert.NoError(ack.UnmarshalBinary(receive()))
+		assert.Equal(PullACKPacket{ProtocolVersion: ProtocolVersion2, RandomToken: 124}, ack)
+
+		gws := s.GetGateways()
+		assert.Len(gws, 1)
+		assert.Equal(gatewayMAC, gws[0].MAC)
+		assert.Equal(conn.LocalAddr().String(), gws[0].PullAddr.String())
+	})
+
+	t.Run("SendDownlink", func(t *testing.T) {
+		assert := require.New(t)
+
+		txpk := TXPK{Imme: true, Freq: 869.525, Powe: 14, Modu: "LORA", DatR: NewLoRaDatR(9, 125), CodR: "4/5", IPol: true, Size: 1, Data: []byte{1}}
+		token, err := s.SendDownlink(gatewayMAC, txpk)
+		assert.NoError(err)
+
+		var pkt PullRespPacket
+		assert.NoError(pkt.UnmarshalBinary(receive()))
+		assert.Equal(PullRespPacket{
+			ProtocolVersion: ProtocolVersion2,
+			RandomToken:     token,
+			Payload:         PullRespPayload{TXPK: txpk},
+		}, pkt)
+
+		send(TXACKPacket{
+			ProtocolVersion: ProtocolVersion2,
+			RandomToken:     token,
+			GatewayMAC:      gatewayMAC,
+			Payload: &TXACKPayload{
+				TXPKACK: TXPKACK{Error: TXAckTooLate},
+			},
+		})
+
+		ack := <-txAcks
+		assert.Equal(gatewayMAC, ack.GatewayMAC)
+		assert.Equal(token, ack.Token)
+		assert.ErrorIs(ack.Err, ErrTooLate)
+	})
+}
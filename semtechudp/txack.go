@@ -0,0 +1,151 @@
+package semtechudp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/brocaar/lorawan"
+)
+
+// TXAckErrorCode defines the error field of the TX_ACK packet, sent by the
+// gateway to report the result of the PULL_RESP (downlink) request.
+type TXAckErrorCode string
+
+// TX_ACK error codes.
+const (
+	TXAckNone            TXAckErrorCode = "NONE"             // Packet has been programmed for downlink
+	TXAckTooLate         TXAckErrorCode = "TOO_LATE"         // Rejected because it was already too late to program this packet for downlink
+	TXAckTooEarly        TXAckErrorCode = "TOO_EARLY"        // Rejected because downlink packet timestamp is too much in advance
+	TXAckCollisionPacket TXAckErrorCode = "COLLISION_PACKET" // Rejected because there was already a packet programmed in requested timeframe
+	TXAckCollisionBeacon TXAckErrorCode = "COLLISION_BEACON" // Rejected because there was already a beacon planned in requested timeframe
+	TXAckTXFreq          TXAckErrorCode = "TX_FREQ"          // Rejected because requested frequency is not supported by TX RF chain
+	TXAckTXPower         TXAckErrorCode = "TX_POWER"         // Rejected because requested power is not supported by gateway
+	TXAckGPSUnlocked     TXAckErrorCode = "GPS_UNLOCKED"     // Rejected because GPS is unlocked, so GPS timestamp cannot be used
+)
+
+// TX_ACK errors. Note that TX_FREQ and TX_POWER map to ErrTXFreq and
+// ErrTXPower, such that these can be handled the same way as the errors
+// returned by Capabilities.ValidateTXPK.
+var (
+	ErrTooLate         = errors.New("lorawan/semtechudp: too late to program the packet for downlink")
+	ErrTooEarly        = errors.New("lorawan/semtechudp: downlink timestamp is too much in advance")
+	ErrCollisionPacket = errors.New("lorawan/semtechudp: collision with a packet programmed in the requested timeframe")
+	ErrCollisionBeacon = errors.New("lorawan/semtechudp: collision with a beacon planned in the requested timeframe")
+	ErrGPSUnlocked     = errors.New("lorawan/semtechudp: gps is unlocked, gps timestamp can not be used")
+	ErrTXAckUnknown    = errors.New("lorawan/semtechudp: unknown tx_ack error")
+)
+
+var txAckErrors = map[TXAckErrorCode]error{
+	TXAckTooLate:         ErrTooLate,
+	TXAckTooEarly:        ErrTooEarly,
+	TXAckCollisionPacket: ErrCollisionPacket,
+	TXAckCollisionBeacon: ErrCollisionBeacon,
+	TXAckTXFreq:          ErrTXFreq,
+	TXAckTXPower:         ErrTXPower,
+	TXAckGPSUnlocked:     ErrGPSUnlocked,
+}
+
+// TXAckError implements the error of a downlink that was rejected by the
+// gateway. Use errors.Is to test for the specific error (e.g. ErrTooLate).
+type TXAckError struct {
+	// Token holds the token of the PULL_RESP (and TX_ACK) packet, which can
+	// be used to correlate the error with the pending downlink.
+	Token uint16
+
+	// Code holds the TX_ACK error code.
+	Code TXAckErrorCode
+}
+
+// NewTXAckError returns the error for the given token and TX_ACK error code.
+// It returns nil when the code is NONE (or empty), meaning that the packet
+// has been programmed for downlink.
+func NewTXAckError(token uint16, code TXAckErrorCode) error {
+	if code == "" || code == TXAckNone {
+		return nil
+	}
+	return &TXAckError{Token: token, Code: code}
+}
+
+// Error implements the error interface.
+func (e *TXAckError) Error() string {
+	return fmt.Sprintf("%s (token: %d, error: %s)", e.Unwrap(), e.Token, e.Code)
+}
+
+// Unwrap returns the underlying error for the error code.
+func (e *TXAckError) Unwrap() error {
+	if err, ok := txAckErrors[e.Code]; ok {
+		return err
+	}
+	return ErrTXAckUnknown
+}
+
+// TXACKPacket is used by the gateway to send a feedback to the server to
+// inform if a downlink request (PULL_RESP) has been accepted or rejected by
+// the gateway.
+type TXACKPacket struct {
+	ProtocolVersion uint8
+	RandomToken     uint16 // same token as the PULL_RESP packet
+	GatewayMAC      lorawan.GatewayEUI
+	Payload         *TXACKPayload // optional, no payload means no error
+}
+
+// TXACKPayload defines the payload of the TX_ACK packet.
+type TXACKPayload struct {
+	TXPKACK TXPKACK `json:"txpk_ack"`
+}
+
+// TXPKACK contains the status information of the downlink request.
+type TXPKACK struct {
+	Error TXAckErrorCode `json:"error,omitempty"`
+	Warn  TXAckErrorCode `json:"warn,omitempty"`  // e.g. TX_POWER when the power was adjusted by the gateway
+	Value *int           `json:"value,omitempty"` // the value related to the warning (e.g. the actual TX power)
+}
+
+// Err returns the TXAckError for the error reported by the gateway, or nil
+// when the downlink was accepted. Note that warnings are not considered as
+// errors.
+func (p TXACKPacket) Err() error {
+	if p.Payload == nil {
+		return nil
+	}
+	return NewTXAckError(p.RandomToken, p.Payload.TXPKACK.Error)
+}
+
+// MarshalBinary marshals the object in binary form.
+func (p TXACKPacket) MarshalBinary() ([]byte, error) {
+	out := marshalHeader(p.ProtocolVersion, p.RandomToken, TXACK)
+	out = append(out, p.GatewayMAC[:]...)
+
+	if p.Payload == nil {
+		return out, nil
+	}
+
+	b, err := json.Marshal(p.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, b...), nil
+}
+
+// UnmarshalBinary decodes the object from binary form.
+func (p *TXACKPacket) UnmarshalBinary(data []byte) error {
+	var err error
+	if p.ProtocolVersion, p.RandomToken, err = unmarshalHeader(data, TXACK, 12); err != nil {
+		return err
+	}
+	copy(p.GatewayMAC[:], data[4:12])
+	p.Payload = nil
+
+	// an empty payload (optionally containing NUL bytes) means no error
+	payload := bytes.TrimRight(data[12:], "\x00")
+	if len(payload) != 0 {
+		p.Payload = &TXACKPayload{}
+		if err := unmarshalJSONPayload(payload, p.Payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
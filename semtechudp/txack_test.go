@@ -0,0 +1,149 @@
+package semtechudp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brocaar/lorawan"
+)
+
+func TestTXAckError(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Code          TXAckErrorCode
+		ExpectedError error
+		ExpectedText  string
+	}{
+		{
+			Name: "none",
+			Code: TXAckNone,
+		},
+		{
+			Name: "empty",
+		},
+		{
+			Name:          "too late",
+			Code:          TXAckTooLate,
+			ExpectedError: ErrTooLate,
+			ExpectedText:  "lorawan/semtechudp: too late to program the packet for downlink (token: 1234, error: TOO_LATE)",
+		},
+		{
+			Name:          "tx freq",
+			Code:          TXAckTXFreq,
+			ExpectedError: ErrTXFreq,
+			ExpectedText:  "lorawan/semtechudp: frequency is not supported by the gateway (token: 1234, error: TX_FREQ)",
+		},
+		{
+			Name:          "unknown",
+			Code:          "FOO",
+			ExpectedError: ErrTXAckUnknown,
+			ExpectedText:  "lorawan/semtechudp: unknown tx_ack error (token: 1234, error: FOO)",
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			err := NewTXAckError(1234, tst.Code)
+			if tst.ExpectedError == nil {
+				assert.NoError(err)
+				return
+			}
+
+			assert.True(errors.Is(err, tst.ExpectedError))
+			assert.EqualError(err, tst.ExpectedText)
+
+			var txAckErr *TXAckError
+			assert.True(errors.As(err, &txAckErr))
+			assert.Equal(uint16(1234), txAckErr.Token)
+		})
+	}
+}
+
+func TestTXACKPacket(t *testing.T) {
+	value := 20
+
+	tests := []struct {
+		Name          string
+		Packet        TXACKPacket
+		Bytes         []byte
+		ExpectedError error
+	}{
+		{
+			Name: "without payload",
+			Packet: TXACKPacket{
+				ProtocolVersion: ProtocolVersion2,
+				RandomToken:     0x0201,
+				GatewayMAC:      lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8},
+			},
+			Bytes: []byte{0x02, 0x01, 0x02, 0x05, 1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			Name: "with error",
+			Packet: TXACKPacket{
+				ProtocolVersion: ProtocolVersion2,
+				RandomToken:     0x0201,
+				GatewayMAC:      lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8},
+				Payload: &TXACKPayload{
+					TXPKACK: TXPKACK{Error: TXAckCollisionBeacon},
+				},
+			},
+			Bytes:         append([]byte{0x02, 0x01, 0x02, 0x05, 1, 2, 3, 4, 5, 6, 7, 8}, []byte(`{"txpk_ack":{"error":"COLLISION_BEACON"}}`)...),
+			ExpectedError: ErrCollisionBeacon,
+		},
+		{
+			Name: "with warning",
+			Packet: TXACKPacket{
+				ProtocolVersion: ProtocolVersion2,
+				RandomToken:     0x0201,
+				GatewayMAC:      lorawan.GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8},
+				Payload: &TXACKPayload{
+					TXPKACK: TXPKACK{Warn: TXAckTXPower, Value: &value},
+				},
+			},
+			Bytes: append([]byte{0x02, 0x01, 0x02, 0x05, 1, 2, 3, 4, 5, 6, 7, 8}, []byte(`{"txpk_ack":{"warn":"TX_POWER","value":20}}`)...),
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.Name, func(t *testing.T) {
+			assert := require.New(t)
+
+			b, err := tst.Packet.MarshalBinary()
+			assert.NoError(err)
+			assert.Equal(tst.Bytes, b)
+
+			pt, err := GetPacketType(b)
+			assert.NoError(err)
+			assert.Equal(TXACK, pt)
+
+			var pkt TXACKPacket
+			assert.NoError(pkt.UnmarshalBinary(b))
+			assert.Equal(tst.Packet, pkt)
+
+			if tst.ExpectedError != nil {
+				assert.True(errors.Is(pkt.Err(), tst.ExpectedError))
+			} else {
+				assert.NoError(pkt.Err())
+			}
+		})
+	}
+
+	t.Run("trailing NUL byte", func(t *testing.T) {
+		assert := require.New(t)
+
+		var pkt TXACKPacket
+		assert.NoError(pkt.UnmarshalBinary(append([]byte{0x02, 0x01, 0x02, 0x05, 1, 2, 3, 4, 5, 6, 7, 8}, []byte("{\"txpk_ack\":{\"error\":\"NONE\"}}\x00")...)))
+		assert.NoError(pkt.Err())
+	})
+
+	t.Run("invalid identifier", func(t *testing.T) {
+		assert := require.New(t)
+
+		var pkt TXACKPacket
+		assert.EqualError(pkt.UnmarshalBinary([]byte{0x02, 0x01, 0x02, 0x04, 1, 2, 3, 4, 5, 6, 7, 8}), "lorawan/semtechudp: identifier mismatch (TX_ACK expected)")
+	})
+}
@@ -0,0 +1,99 @@
+package lorawan
+
+import "errors"
+
+// SessionKeys contains the session keys of a device. For LoRaWAN 1.0
+// sessions, the FNwkSIntKey, SNwkSIntKey and NwkSEncKey all contain the
+// NwkSKey.
+type SessionKeys struct {
+	FNwkSIntKey AES128Key `json:"fNwkSIntKey"`
+	SNwkSIntKey AES128Key `json:"sNwkSIntKey"`
+	NwkSEncKey  AES128Key `json:"nwkSEncKey"`
+	AppSKey     AES128Key `json:"appSKey"`
+}
+
+// Session contains the MAC version dependent (security) context of an
+// activated device.
+type Session struct {
+	MACVersion MACVersion  `json:"macVersion"`
+	DevAddr    DevAddr     `json:"devAddr"`
+	Keys       SessionKeys `json:"keys"`
+	FCntUp     uint32      `json:"fCntUp"`
+
+	// NFCntDown is used for mac-command only downlinks (FPort 0) for
+	// LoRaWAN 1.1 and for all downlinks for LoRaWAN 1.0.
+	NFCntDown uint32 `json:"nFCntDown"`
+
+	// AFCntDown is used for application downlinks (FPort > 0) for
+	// LoRaWAN 1.1 only.
+	AFCntDown uint32 `json:"aFCntDown"`
+
+	// RekeyIndPending is set for LoRaWAN 1.1 sessions created by a (re)join,
+	// until the device has confirmed the new security context by sending a
+	// RekeyInd mac-command.
+	RekeyIndPending bool `json:"rekeyIndPending"`
+
+	// Previous contains the previous session. For LoRaWAN 1.1, it must be
+	// kept until the RekeyInd mac-command has been received, as the device
+	// might still use the old security context.
+	Previous *Session `json:"previous,omitempty"`
+}
+
+// MigrateSession returns the new session after an OTAA (re)join. The MAC
+// version of the new session is LoRaWAN 1.1 when the OptNeg bit of the
+// join-accept is set, else LoRaWAN 1.0. This way a device which was
+// previously operating as LoRaWAN 1.0 device is upgraded to LoRaWAN 1.1
+// (and vice versa).
+//
+// All frame-counters are reset. For LoRaWAN 1.1, the new session expects a
+// RekeyInd mac-command and the current session (if any) is kept as Previous
+// session until then. For LoRaWAN 1.0, the FNwkSIntKey, SNwkSIntKey and
+// NwkSEncKey must be equal.
+func MigrateSession(current *Session, optNeg bool, devAddr DevAddr, keys SessionKeys) (Session, error) {
+	s := Session{
+		MACVersion: LoRaWAN1_0,
+		DevAddr:    devAddr,
+		Keys:       keys,
+	}
+
+	if !optNeg {
+		if keys.FNwkSIntKey != keys.SNwkSIntKey || keys.FNwkSIntKey != keys.NwkSEncKey {
+			return Session{}, errors.New("lorawan: FNwkSIntKey, SNwkSIntKey and NwkSEncKey must be equal for LoRaWAN 1.0")
+		}
+
+		return s, nil
+	}
+
+	s.MACVersion = LoRaWAN1_1
+	s.RekeyIndPending = true
+
+	if current != nil {
+		prev := *current
+		prev.Previous = nil
+		s.Previous = &prev
+	}
+
+	return s, nil
+}
+
+// HandleRekeyInd handles the RekeyInd mac-command. It confirms the new
+// security context (discarding the previous session) and returns the
+// RekeyConfPayload to send to the device.
+func (s *Session) HandleRekeyInd(pl RekeyIndPayload) (RekeyConfPayload, error) {
+	if s.MACVersion != LoRaWAN1_1 {
+		return RekeyConfPayload{}, errors.New("lorawan: RekeyInd is only expected for LoRaWAN 1.1 sessions")
+	}
+
+	// The server responds with the minimum of the device and server version.
+	minor := pl.DevLoRaWANVersion.Minor
+	if minor > 1 {
+		minor = 1
+	}
+
+	s.RekeyIndPending = false
+	s.Previous = nil
+
+	return RekeyConfPayload{
+		ServLoRaWANVersion: Version{Minor: minor},
+	}, nil
+}
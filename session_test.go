@@ -0,0 +1,94 @@
+package lorawan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateSession(t *testing.T) {
+	nwkSKey := AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+	appSKey := AES128Key{8, 7, 6, 5, 4, 3, 2, 1, 8, 7, 6, 5, 4, 3, 2, 1}
+
+	keys10 := SessionKeys{
+		FNwkSIntKey: nwkSKey,
+		SNwkSIntKey: nwkSKey,
+		NwkSEncKey:  nwkSKey,
+		AppSKey:     appSKey,
+	}
+
+	keys11 := SessionKeys{
+		FNwkSIntKey: AES128Key{1},
+		SNwkSIntKey: AES128Key{2},
+		NwkSEncKey:  AES128Key{3},
+		AppSKey:     AES128Key{4},
+	}
+
+	current := Session{
+		MACVersion: LoRaWAN1_0,
+		DevAddr:    DevAddr{1, 2, 3, 4},
+		Keys:       keys10,
+		FCntUp:     10,
+		NFCntDown:  5,
+	}
+
+	t.Run("1.0 join", func(t *testing.T) {
+		assert := require.New(t)
+
+		s, err := MigrateSession(&current, false, DevAddr{4, 3, 2, 1}, keys10)
+		assert.NoError(err)
+		assert.Equal(Session{
+			MACVersion: LoRaWAN1_0,
+			DevAddr:    DevAddr{4, 3, 2, 1},
+			Keys:       keys10,
+		}, s)
+	})
+
+	t.Run("1.0 join with different network session keys", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := MigrateSession(&current, false, DevAddr{4, 3, 2, 1}, keys11)
+		assert.EqualError(err, "lorawan: FNwkSIntKey, SNwkSIntKey and NwkSEncKey must be equal for LoRaWAN 1.0")
+	})
+
+	t.Run("1.0 to 1.1 upgrade", func(t *testing.T) {
+		assert := require.New(t)
+
+		s, err := MigrateSession(&current, true, DevAddr{4, 3, 2, 1}, keys11)
+		assert.NoError(err)
+		assert.Equal(Session{
+			MACVersion:      LoRaWAN1_1,
+			DevAddr:         DevAddr{4, 3, 2, 1},
+			Keys:            keys11,
+			RekeyIndPending: true,
+			Previous:        &current,
+		}, s)
+
+		t.Run("HandleRekeyInd", func(t *testing.T) {
+			assert := require.New(t)
+
+			ans, err := s.HandleRekeyInd(RekeyIndPayload{DevLoRaWANVersion: Version{Minor: 2}})
+			assert.NoError(err)
+			assert.Equal(RekeyConfPayload{ServLoRaWANVersion: Version{Minor: 1}}, ans)
+			assert.False(s.RekeyIndPending)
+			assert.Nil(s.Previous)
+		})
+	})
+
+	t.Run("first 1.1 join", func(t *testing.T) {
+		assert := require.New(t)
+
+		s, err := MigrateSession(nil, true, DevAddr{4, 3, 2, 1}, keys11)
+		assert.NoError(err)
+		assert.True(s.RekeyIndPending)
+		assert.Nil(s.Previous)
+	})
+
+	t.Run("RekeyInd for 1.0 session", func(t *testing.T) {
+		assert := require.New(t)
+
+		s := current
+		_, err := s.HandleRekeyInd(RekeyIndPayload{DevLoRaWANVersion: Version{Minor: 1}})
+		assert.Error(err)
+	})
+}
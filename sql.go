@@ -0,0 +1,103 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// This file contains the sql.Scanner and driver.Valuer implementations,
+// which are excluded from TinyGo builds.
+
+// Scan implements sql.Scanner.
+func (e *EUI64) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("lorawan: []byte type expected")
+	}
+	if len(b) != len(e) {
+		return fmt.Errorf("lorawan: []byte must have length %d", len(e))
+	}
+	copy(e[:], b)
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (e EUI64) Value() (driver.Value, error) {
+	return e[:], nil
+}
+
+// Scan implements sql.Scanner.
+func (a *DevAddr) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("lorawan: []byte type expected")
+	}
+	if len(b) != len(a) {
+		return fmt.Errorf("lorawan []byte must have length %d", len(a))
+	}
+	copy(a[:], b)
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (a DevAddr) Value() (driver.Value, error) {
+	return a[:], nil
+}
+
+// Value implements driver.Valuer.
+func (n NetID) Value() (driver.Value, error) {
+	return n[:], nil
+}
+
+// Scan implements sql.Scanner.
+func (n *NetID) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("lorawan: []byte type expected")
+	}
+	if len(b) != len(n) {
+		return fmt.Errorf("lorawan: []byte must have length %d", len(n))
+	}
+	copy(n[:], b)
+	return nil
+}
+
+// Scan implements sql.Scanner.
+func (k *AES128Key) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("lorawan: []byte type expected")
+	}
+	if len(b) != len(k) {
+		return fmt.Errorf("lorawan []byte must have length %d", len(k))
+	}
+	copy(k[:], b)
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (k AES128Key) Value() (driver.Value, error) {
+	return k[:], nil
+}
+
+// Scan implements sql.Scanner.
+func (e *GatewayEUI) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("lorawan: []byte type expected")
+	}
+	if len(b) != len(e) {
+		return fmt.Errorf("lorawan: []byte must have length %d", len(e))
+	}
+	copy(e[:], b)
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (e GatewayEUI) Value() (driver.Value, error) {
+	return e[:], nil
+}
@@ -0,0 +1,65 @@
+//go:build !tinygo
+// +build !tinygo
+
+package lorawan
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGatewayEUISQL(t *testing.T) {
+	Convey("Given GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8}", t, func() {
+		eui := GatewayEUI{1, 2, 3, 4, 5, 6, 7, 8}
+
+		Convey("Then Value returns the expected value", func() {
+			v, err := eui.Value()
+			So(err, ShouldBeNil)
+			So(v, ShouldResemble, driver.Value(eui[:]))
+		})
+
+		Convey("Then Scan scans the value correctly", func() {
+			var out GatewayEUI
+			So(out.Scan(eui[:]), ShouldBeNil)
+			So(out, ShouldEqual, eui)
+		})
+	})
+}
+
+func TestEUI64SQL(t *testing.T) {
+	Convey("Given EUI64{1, 2, 3, 4, 5, 6, 7, 8}", t, func() {
+		eui := EUI64{1, 2, 3, 4, 5, 6, 7, 8}
+
+		Convey("Then Value returns the expected value", func() {
+			v, err := eui.Value()
+			So(err, ShouldBeNil)
+			So(v, ShouldResemble, driver.Value(eui[:]))
+		})
+
+		Convey("Then Scan scans the value correctly", func() {
+			var out EUI64
+			So(out.Scan(eui[:]), ShouldBeNil)
+			So(out, ShouldEqual, eui)
+		})
+	})
+}
+
+func TestAES128KeySQL(t *testing.T) {
+	Convey("Given AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}", t, func() {
+		key := AES128Key{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+		Convey("Then Value returns the expected value", func() {
+			v, err := key.Value()
+			So(err, ShouldBeNil)
+			So(v, ShouldResemble, driver.Value(key[:]))
+		})
+
+		Convey("Then Scan scans the value correctly", func() {
+			var out AES128Key
+			So(out.Scan(key[:]), ShouldBeNil)
+			So(out, ShouldEqual, key)
+		})
+	})
+}
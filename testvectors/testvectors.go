@@ -0,0 +1,51 @@
+// Package testvectors exports the byte-level test-vectors which are used to
+// test the encoding and decoding of this library. These can be re-used by
+// other implementations (e.g. device firmware) to validate their encoding
+// against this library.
+package testvectors
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/brocaar/lorawan"
+)
+
+//go:embed mac_commands.json
+var macCommandsJSON []byte
+
+// MACCommand contains a mac-command test-vector.
+type MACCommand struct {
+	// Name contains the name of the mac-command.
+	Name string `json:"name"`
+
+	// Uplink is set to true for uplink mac-commands.
+	Uplink bool `json:"uplink"`
+
+	// CID contains the command identifier.
+	CID uint8 `json:"cid"`
+
+	// Payload contains the JSON representation of the mac-command payload
+	// (as encoded by the lorawan package), or null when the mac-command
+	// does not have a payload.
+	Payload json.RawMessage `json:"payload"`
+
+	// Bytes contains the encoded mac-command, including the CID.
+	Bytes lorawan.HEXBytes `json:"bytes"`
+}
+
+// MACCommands returns the mac-command test-vectors.
+func MACCommands() ([]MACCommand, error) {
+	var out []MACCommand
+	if err := json.Unmarshal(macCommandsJSON, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MACCommandsJSON returns the raw JSON encoded mac-command test-vectors.
+func MACCommandsJSON() []byte {
+	out := make([]byte, len(macCommandsJSON))
+	copy(out, macCommandsJSON)
+	return out
+}